@@ -2,14 +2,16 @@ package alias
 
 import (
 	"context"
-	"errors"
+	"sort"
 	"strings"
 
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/cache"
+	"github.com/artpar/rclone/fs/config"
 	"github.com/artpar/rclone/fs/config/configmap"
 	"github.com/artpar/rclone/fs/config/configstruct"
 	"github.com/artpar/rclone/fs/fspath"
+	"github.com/pkg/errors"
 )
 
 // Register with Fs
@@ -32,21 +34,112 @@ type Options struct {
 	Remote string `config:"remote"`
 }
 
+// maxChain is the maximum number of aliases which can be followed
+// when resolving a remote
+const maxChain = 32
+
 // NewFs constructs an Fs from the path.
 //
 // The returned Fs is the actual Fs, referenced by remote in the config
 func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, error) {
+	target, options, err := resolveTarget(name, root, m)
+	if err != nil {
+		return nil, err
+	}
+	// Check the chain of aliases ends somewhere
+	_, err = Resolve(target)
+	if err != nil {
+		return nil, err
+	}
+	if len(options) == 0 {
+		return cache.Get(ctx, target)
+	}
+	// Pass the options separately so any secrets in them don't
+	// appear in the name of the Fs
+	return fs.NewFsWithOptions(ctx, target, options)
+}
+
+// resolveTarget returns the remote the alias called name points to
+// with root added, along with any options set in the alias's config
+// section which should be passed through to it.
+func resolveTarget(name, root string, m configmap.Mapper) (target string, options configmap.Simple, err error) {
 	// Parse config into Options struct
 	opt := new(Options)
-	err := configstruct.Set(m, opt)
+	err = configstruct.Set(m, opt)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 	if opt.Remote == "" {
-		return nil, errors.New("alias can't point to an empty remote - check the value of the remote setting")
+		return "", nil, errors.New("alias can't point to an empty remote - check the value of the remote setting")
 	}
 	if strings.HasPrefix(opt.Remote, name+":") {
-		return nil, errors.New("can't point alias remote at itself - check the value of the remote setting")
+		return "", nil, errors.New("can't point alias remote at itself - check the value of the remote setting")
+	}
+	return fspath.JoinRootPath(opt.Remote, root), passThroughOptions(name, m), nil
+}
+
+// passThroughOptions returns the options in the config section for
+// the alias called name which aren't used by the alias itself. These
+// are passed through to the target as backend options.
+func passThroughOptions(name string, m configmap.Mapper) configmap.Simple {
+	var options configmap.Simple
+	for _, key := range config.Data.GetKeyList(name) {
+		if key == "type" || key == "remote" {
+			continue
+		}
+		value, ok := m.Get(key)
+		if !ok {
+			continue
+		}
+		if options == nil {
+			options = make(configmap.Simple)
+		}
+		options[key] = value
+	}
+	return options
+}
+
+// Link is one remote in the chain an alias resolves through
+type Link struct {
+	Remote  string   `json:"remote"`            // remote as passed to the backend
+	Type    string   `json:"type"`              // backend type
+	Options []string `json:"options,omitempty"` // names of the options passed through by the previous alias
+}
+
+// Resolve follows remote through any aliases it points at returning
+// each remote in the chain, ending with the one which isn't an alias.
+//
+// Only the names of the options passed through by the aliases are
+// returned, not their values, as they may be secret.
+//
+// It returns an error if the aliases form a loop or a remote in the
+// chain can't be found.
+func Resolve(remote string) (chain []Link, err error) {
+	seen := make(map[string]struct{})
+	var options []string
+	for {
+		fsInfo, configName, fsPath, connectionStringConfig, err := fs.ParseRemote(remote)
+		if err != nil {
+			return chain, errors.Wrapf(err, "failed to resolve %q", remote)
+		}
+		chain = append(chain, Link{Remote: remote, Type: fsInfo.Name, Options: options})
+		if fsInfo.Name != "alias" {
+			return chain, nil
+		}
+		if _, found := seen[configName]; found || len(chain) > maxChain {
+			return chain, errors.Errorf("alias %q is part of a loop of aliases", configName)
+		}
+		seen[configName] = struct{}{}
+		m := fs.ConfigMap(fsInfo, configName, connectionStringConfig)
+		var passThrough configmap.Simple
+		remote, passThrough, err = resolveTarget(configName, fsPath, m)
+		if err != nil {
+			return chain, err
+		}
+		options = nil
+		for key := range passThrough {
+			options = append(options, key)
+		}
+		sort.Strings(options)
 	}
-	return cache.Get(ctx, fspath.JoinRootPath(opt.Remote, root))
 }
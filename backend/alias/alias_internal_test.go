@@ -6,12 +6,14 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 
 	_ "github.com/artpar/rclone/backend/local" // pull in test backend
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/config"
 	"github.com/artpar/rclone/fs/config/configfile"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -105,3 +107,50 @@ func TestNewFSInvalidRemote(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, f)
 }
+
+func TestResolve(t *testing.T) {
+	remoteRoot, err := filepath.Abs("test/files")
+	require.NoError(t, err)
+	prepare(t, remoteRoot)
+	config.FileSet(remoteName, "case_sensitive", "true")
+	config.FileSet(remoteName, "description", "secret")
+	config.FileSet("TestAlias2", "type", "alias")
+	config.FileSet("TestAlias2", "remote", remoteName+":four")
+	config.FileSet("TestAliasLoop", "type", "alias")
+	config.FileSet("TestAliasLoop", "remote", "TestAliasLoop2:")
+	config.FileSet("TestAliasLoop2", "type", "alias")
+	config.FileSet("TestAliasLoop2", "remote", "TestAliasLoop:")
+	defer func() {
+		config.FileDeleteKey(remoteName, "case_sensitive")
+		config.FileDeleteKey(remoteName, "description")
+		for _, name := range []string{"TestAlias2", "TestAliasLoop", "TestAliasLoop2"} {
+			config.Data.DeleteSection(name)
+		}
+	}()
+
+	chain, err := Resolve("TestAlias2:five")
+	require.NoError(t, err)
+	assert.Equal(t, []Link{
+		{Remote: "TestAlias2:five", Type: "alias"},
+		{Remote: remoteName + ":four/five", Type: "alias"},
+		{Remote: filepath.ToSlash(remoteRoot) + "/four/five", Type: "local", Options: []string{"case_sensitive", "description"}},
+	}, chain)
+
+	// The options are passed through to the target without
+	// appearing in its name
+	f, err := fs.NewFs(context.Background(), "TestAlias2:five")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(f.Name(), "local{"), f.Name())
+	assert.NotContains(t, fs.ConfigString(f), "secret")
+	entries, err := f.List(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "underfive.txt", entries[0].Remote())
+
+	_, err = Resolve("TestAliasLoop:")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loop")
+	_, err = fs.NewFs(context.Background(), "TestAliasLoop:")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loop")
+}
@@ -30,12 +30,14 @@ import (
 	"github.com/artpar/rclone/fs/fserrors"
 	"github.com/artpar/rclone/fs/fshttp"
 	"github.com/artpar/rclone/fs/hash"
+	"github.com/artpar/rclone/fs/operations"
 	"github.com/artpar/rclone/fs/walk"
 	"github.com/artpar/rclone/lib/bucket"
 	"github.com/artpar/rclone/lib/encoder"
 	"github.com/artpar/rclone/lib/env"
 	"github.com/artpar/rclone/lib/pacer"
 	"github.com/artpar/rclone/lib/pool"
+	"github.com/artpar/rclone/lib/readers"
 )
 
 const (
@@ -71,6 +73,7 @@ func init() {
 		Name:        "azureblob",
 		Description: "Microsoft Azure Blob Storage",
 		NewFs:       NewFs,
+		CommandHelp: commandHelp,
 		Options: []fs.Option{{
 			Name: "account",
 			Help: "Storage Account Name (leave blank to use SAS URL or Emulator)",
@@ -185,6 +188,58 @@ replacement.  This has the potential for data loss if the upload fails
 archive tier blobs early may be chargable.
 `, errCantUpdateArchiveTierBlobs),
 			Advanced: true,
+		}, {
+			Name: "archive_tier_rehydrate",
+			Help: `Tier to rehydrate archive tier blobs to when they are read.
+
+Archive tier blobs can't be read until they have been rehydrated to
+the hot or cool tier which can take up to 15 hours.
+
+If this is set then when rclone tries to read an archive tier blob it
+starts rehydrating it to this tier and the read fails with an error
+saying so. Try again when it has finished. Use the "restore" backend
+command to start rehydrating lots of blobs at once and the
+"restore-status" backend command to see how they are getting on.
+
+Leave blank to fail reads of archive tier blobs without rehydrating
+them.`,
+			Examples: []fs.OptionExample{{
+				Value: "",
+				Help:  "Don't rehydrate archive tier blobs",
+			}, {
+				Value: string(azblob.AccessTierHot),
+				Help:  "Rehydrate to the hot tier",
+			}, {
+				Value: string(azblob.AccessTierCool),
+				Help:  "Rehydrate to the cool tier",
+			}},
+			Advanced: true,
+		}, {
+			Name:    "rehydrate_priority",
+			Default: string(azblob.RehydratePriorityStandard),
+			Help: `Priority to rehydrate archive tier blobs with.
+
+High priority rehydration may finish in under an hour for small blobs
+but costs more.`,
+			Examples: []fs.OptionExample{{
+				Value: string(azblob.RehydratePriorityStandard),
+				Help:  "Standard priority",
+			}, {
+				Value: string(azblob.RehydratePriorityHigh),
+				Help:  "High priority",
+			}},
+			Advanced: true,
+		}, {
+			Name: "upload_tags",
+			Help: `Blob index tags to set on uploaded blobs.
+
+This should be a comma separated list of key=value pairs, for example
+"project=potato,team=mash". Up to 10 tags can be set.
+
+The tags are set on blobs uploaded or copied by rclone and can be
+used to find blobs without listing the whole container with the
+"find-by-tag" backend command.`,
+			Advanced: true,
 		}, {
 			Name: "disable_checksum",
 			Help: `Don't store MD5 checksum with object metadata.
@@ -234,7 +289,7 @@ This option controls how often unused buffers will be removed from the pool.`,
 				},
 			},
 			Advanced: true,
-		}},
+		}, fs.ProxyOption, fs.APIBudgetOption},
 	})
 }
 
@@ -252,7 +307,10 @@ type Options struct {
 	ChunkSize            fs.SizeSuffix        `config:"chunk_size"`
 	ListChunkSize        uint                 `config:"list_chunk"`
 	AccessTier           string               `config:"access_tier"`
+	UploadTags           string               `config:"upload_tags"`
 	ArchiveTierDelete    bool                 `config:"archive_tier_delete"`
+	ArchiveTierRehydrate string               `config:"archive_tier_rehydrate"`
+	RehydratePriority    string               `config:"rehydrate_priority"`
 	UseEmulator          bool                 `config:"use_emulator"`
 	DisableCheckSum      bool                 `config:"disable_checksum"`
 	MemoryPoolFlushTime  fs.Duration          `config:"memory_pool_flush_time"`
@@ -281,6 +339,7 @@ type Fs struct {
 	uploadToken   *pacer.TokenDispenser           // control concurrency
 	pool          *pool.Pool                      // memory pool
 	publicAccess  azblob.PublicAccessType         // Container Public Access Level
+	uploadTags    azblob.BlobTagsMap              // blob index tags to set on upload
 }
 
 // Object describes an azure object
@@ -293,6 +352,8 @@ type Object struct {
 	mimeType   string                // Content-Type of the object
 	accessTier azblob.AccessTierType // Blob Access Tier
 	meta       map[string]string     // blob metadata
+	// rehydration status if in the archive tier
+	archiveStatus azblob.ArchiveStatusType
 }
 
 // ------------------------------------------------------------
@@ -341,6 +402,22 @@ func (o *Object) split() (container, containerPath string) {
 	return o.fs.split(o.remote)
 }
 
+// validateRehydrate checks the tier and priority to rehydrate
+// archive tier blobs with are valid
+func validateRehydrate(tier, priority string) error {
+	switch tier {
+	case "", string(azblob.AccessTierHot), string(azblob.AccessTierCool):
+	default:
+		return errors.Errorf("can only rehydrate to the %s or %s tier not %q", azblob.AccessTierHot, azblob.AccessTierCool, tier)
+	}
+	switch priority {
+	case string(azblob.RehydratePriorityStandard), string(azblob.RehydratePriorityHigh):
+	default:
+		return errors.Errorf("rehydrate priority must be %s or %s not %q", azblob.RehydratePriorityStandard, azblob.RehydratePriorityHigh, priority)
+	}
+	return nil
+}
+
 // validateAccessTier checks if azureblob supports user supplied tier
 func validateAccessTier(tier string) bool {
 	switch tier {
@@ -354,6 +431,32 @@ func validateAccessTier(tier string) bool {
 	}
 }
 
+// maxTags is the maximum number of blob index tags on a blob
+const maxTags = 10
+
+// parseTags parses a comma separated list of key=value blob index tags
+func parseTags(in string) (tags azblob.BlobTagsMap, err error) {
+	if strings.TrimSpace(in) == "" {
+		return nil, nil
+	}
+	tags = azblob.BlobTagsMap{}
+	for _, kv := range strings.Split(in, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		equals := strings.IndexRune(kv, '=')
+		if equals <= 0 {
+			return nil, errors.Errorf("tag %q must be in the form key=value", kv)
+		}
+		tags[strings.TrimSpace(kv[:equals])] = strings.TrimSpace(kv[equals+1:])
+	}
+	if len(tags) > maxTags {
+		return nil, errors.Errorf("can't set more than %d tags - got %d", maxTags, len(tags))
+	}
+	return tags, nil
+}
+
 // validatePublicAccess checks if azureblob supports use supplied public access level
 func validatePublicAccess(publicAccess string) bool {
 	switch publicAccess {
@@ -482,6 +585,36 @@ func newServicePrincipalTokenRefresher(ctx context.Context, credentialsData []by
 	return tokenRefresher, nil
 }
 
+// rehydratePriorityKey is the context key for the priority set with
+// withRehydratePriority
+type rehydratePriorityKey struct{}
+
+// withRehydratePriority returns a context which makes the Set Blob
+// Tier requests made with it rehydrate archive tier blobs with
+// priority.
+//
+// This is needed as azblob.BlobURL.SetTier can't set the priority.
+func withRehydratePriority(ctx context.Context, priority azblob.RehydratePriorityType) context.Context {
+	return context.WithValue(ctx, rehydratePriorityKey{}, priority)
+}
+
+// rehydratePriorityFactory creates a Factory object that adds the
+// priority from withRehydratePriority to Set Blob Tier requests.
+//
+// It must come before the credential in the pipeline as the header
+// is signed.
+func rehydratePriorityFactory() pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			priority, _ := ctx.Value(rehydratePriorityKey{}).(azblob.RehydratePriorityType)
+			if priority != azblob.RehydratePriorityNone && request.URL.Query().Get("comp") == "tier" {
+				request.Header.Set("x-ms-rehydrate-priority", string(priority))
+			}
+			return next.Do(ctx, request)
+		}
+	})
+}
+
 // newPipeline creates a Pipeline using the specified credentials and options.
 //
 // this code was copied from azblob.NewPipeline
@@ -492,6 +625,7 @@ func (f *Fs) newPipeline(c azblob.Credential, o azblob.PipelineOptions) pipeline
 	// Closest to API goes first; closest to the wire goes last
 	factories := []pipeline.Factory{
 		azblob.NewTelemetryPolicyFactory(o.Telemetry),
+		rehydratePriorityFactory(),
 		azblob.NewUniqueRequestIDPolicyFactory(),
 		azblob.NewRetryPolicyFactory(o.Retry),
 		c,
@@ -534,11 +668,21 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 			string(azblob.AccessTierHot), string(azblob.AccessTierCool), string(azblob.AccessTierArchive))
 	}
 
+	err = validateRehydrate(opt.ArchiveTierRehydrate, opt.RehydratePriority)
+	if err != nil {
+		return nil, errors.Wrap(err, "Azure Blob")
+	}
+
 	if !validatePublicAccess((opt.PublicAccess)) {
 		return nil, errors.Errorf("Azure Blob: Supported public access level are %s and %s",
 			string(azblob.PublicAccessBlob), string(azblob.PublicAccessContainer))
 	}
 
+	uploadTags, err := parseTags(opt.UploadTags)
+	if err != nil {
+		return nil, errors.Wrap(err, "Azure Blob: upload_tags")
+	}
+
 	ci := fs.GetConfig(ctx)
 	f := &Fs{
 		name:        name,
@@ -558,6 +702,7 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		),
 	}
 	f.publicAccess = azblob.PublicAccessType(opt.PublicAccess)
+	f.uploadTags = uploadTags
 	f.imdsPacer.SetRetries(5) // per IMDS documentation
 	f.setRoot(root)
 	f.features = (&fs.Features{
@@ -1220,6 +1365,152 @@ func (f *Fs) Purge(ctx context.Context, dir string) error {
 	return f.deleteContainer(ctx, container)
 }
 
+// listBlobNames calls fn with the name of every blob in container
+// starting with prefix, including blobs which only have uncommitted
+// blocks if uncommitted is set
+func (f *Fs) listBlobNames(ctx context.Context, container, prefix string, uncommitted bool, fn func(name string) error) error {
+	options := azblob.ListBlobsSegmentOptions{
+		Details: azblob.BlobListingDetails{
+			UncommittedBlobs: uncommitted,
+		},
+		Prefix:     prefix,
+		MaxResults: int32(f.opt.ListChunkSize),
+	}
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		var response *azblob.ListBlobsFlatSegmentResponse
+		err := f.pacer.Call(func() (bool, error) {
+			var err error
+			response, err = f.cntURL(container).ListBlobsFlatSegment(ctx, marker, options)
+			return f.shouldRetry(ctx, err)
+		})
+		if err != nil {
+			return err
+		}
+		marker = response.NextMarker
+		for i := range response.Segment.BlobItems {
+			err = fn(response.Segment.BlobItems[i].Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cleanUpContainer removes the blobs in container under prefix which
+// only consist of uncommitted blocks.
+//
+// These are left behind by interrupted multipart uploads and count
+// towards the storage used until Azure garbage collects them after a
+// week. Azure has no call to discard uncommitted blocks directly, so
+// an empty block list is committed, which discards them, and the
+// resulting empty blob is deleted.
+func (f *Fs) cleanUpContainer(ctx context.Context, container, prefix string) error {
+	committed := map[string]struct{}{}
+	err := f.listBlobNames(ctx, container, prefix, false, func(name string) error {
+		committed[name] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var uncommitted []string
+	err = f.listBlobNames(ctx, container, prefix, true, func(name string) error {
+		if _, found := committed[name]; !found {
+			uncommitted = append(uncommitted, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var errorCount int
+	for _, name := range uncommitted {
+		remote := path.Join(f.opt.Enc.ToStandardName(container), f.opt.Enc.ToStandardPath(name))
+		fs.Infof(f, "Removing uncommitted blocks of %q", remote)
+		err := f.removeUncommitted(ctx, f.cntURL(container).NewBlockBlobURL(name))
+		if err != nil {
+			fs.Errorf(f, "Failed to remove uncommitted blocks of %q: %v", remote, err)
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return errors.Errorf("failed to remove uncommitted blocks of %d blobs", errorCount)
+	}
+	return nil
+}
+
+// removeUncommitted discards the uncommitted blocks of blob by
+// committing an empty block list then deleting the empty blob.
+//
+// These are done in separate calls so a retry of the delete doesn't
+// retry the commit, which would fail as the blob now exists.
+func (f *Fs) removeUncommitted(ctx context.Context, blob azblob.BlockBlobURL) error {
+	var etag azblob.ETag
+	err := f.pacer.Call(func() (bool, error) {
+		// Don't overwrite the blob if an upload has committed it since the listing
+		ac := azblob.BlobAccessConditions{
+			ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfNoneMatch: azblob.ETagAny},
+		}
+		response, err := blob.CommitBlockList(ctx, []string{}, azblob.BlobHTTPHeaders{}, azblob.Metadata{}, ac, azblob.AccessTierNone, nil, azblob.ClientProvidedKeyOptions{})
+		if err != nil {
+			return f.shouldRetry(ctx, err)
+		}
+		etag = response.ETag()
+		return false, nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to commit empty block list")
+	}
+	err = f.pacer.Call(func() (bool, error) {
+		// Only delete the empty blob we made
+		ac := azblob.BlobAccessConditions{
+			ModifiedAccessConditions: azblob.ModifiedAccessConditions{IfMatch: etag},
+		}
+		_, err := blob.Delete(ctx, azblob.DeleteSnapshotsOptionNone, ac)
+		return f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to delete empty blob")
+	}
+	return nil
+}
+
+// CleanUp removes the uncommitted blocks left by interrupted uploads
+//
+// Note that this will also remove the blocks of any uploads of new
+// blobs which are in progress.
+func (f *Fs) CleanUp(ctx context.Context) error {
+	container, directory := f.split("")
+	if container != "" {
+		if !f.containerOK(container) {
+			return fs.ErrorDirNotFound
+		}
+		if directory != "" {
+			directory += "/"
+		}
+		return f.cleanUpContainer(ctx, container, directory)
+	}
+	if f.isLimited {
+		f.cntURLcacheMu.Lock()
+		var containers []string
+		for container := range f.cntURLcache {
+			containers = append(containers, container)
+		}
+		f.cntURLcacheMu.Unlock()
+		for _, container := range containers {
+			err := f.cleanUpContainer(ctx, container, "")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return f.listContainersToFn(func(container *azblob.ContainerItem) error {
+		return f.cleanUpContainer(ctx, container.Name, "")
+	})
+}
+
 // Copy src to this remote using server-side copy operations.
 //
 // This is stored with the remote path given
@@ -1252,7 +1543,7 @@ func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object,
 	var startCopy *azblob.BlobStartCopyFromURLResponse
 
 	err = f.pacer.Call(func() (bool, error) {
-		startCopy, err = dstBlobURL.StartCopyFromURL(ctx, *source, nil, azblob.ModifiedAccessConditions{}, options, azblob.AccessTierType(f.opt.AccessTier), nil)
+		startCopy, err = dstBlobURL.StartCopyFromURL(ctx, *source, nil, azblob.ModifiedAccessConditions{}, options, azblob.AccessTierType(f.opt.AccessTier), f.uploadTags)
 		return f.shouldRetry(ctx, err)
 	})
 	if err != nil {
@@ -1362,6 +1653,7 @@ func (o *Object) decodeMetaDataFromPropertiesResponse(info *azblob.BlobGetProper
 	o.size = size
 	o.modTime = info.LastModified()
 	o.accessTier = azblob.AccessTierType(info.AccessTier())
+	o.archiveStatus = azblob.ArchiveStatusType(info.ArchiveStatus())
 	o.setMetadata(metadata)
 
 	return nil
@@ -1380,6 +1672,7 @@ func (o *Object) decodeMetaDataFromBlob(info *azblob.BlobItemInternal) (err erro
 	o.size = size
 	o.modTime = info.Properties.LastModified
 	o.accessTier = info.Properties.AccessTier
+	o.archiveStatus = info.Properties.ArchiveStatus
 	o.setMetadata(metadata)
 	return nil
 }
@@ -1469,7 +1762,7 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.Read
 	var offset int64
 	var count int64
 	if o.AccessTier() == azblob.AccessTierArchive {
-		return nil, errors.Errorf("Blob in archive tier, you need to set tier to hot or cool first")
+		return nil, o.archiveError(ctx)
 	}
 	fs.FixRangeOption(options, o.size)
 	for _, option := range options {
@@ -1576,6 +1869,55 @@ func (pw *poolWrapper) Run(f func()) {
 func (pw *poolWrapper) Close() {
 }
 
+// applyUploadOptions sets the headers, metadata and blob index tags
+// from the upload options, eg from --header-upload.
+//
+// It returns the tags to use which are the options tags added to tags.
+func applyUploadOptions(o *Object, options []fs.OpenOption, httpHeaders *azblob.BlobHTTPHeaders, meta azblob.Metadata, tags azblob.BlobTagsMap) (azblob.BlobTagsMap, error) {
+	for _, option := range options {
+		key, value := option.Header()
+		lowerKey := strings.ToLower(key)
+		switch lowerKey {
+		case "":
+			// ignore
+		case "cache-control":
+			httpHeaders.CacheControl = value
+		case "content-disposition":
+			httpHeaders.ContentDisposition = value
+		case "content-encoding":
+			httpHeaders.ContentEncoding = value
+		case "content-language":
+			httpHeaders.ContentLanguage = value
+		case "content-type":
+			httpHeaders.ContentType = value
+		case "x-ms-tags":
+			values, err := url.ParseQuery(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse %q header", key)
+			}
+			newTags := make(azblob.BlobTagsMap, len(tags)+len(values))
+			for k, v := range tags {
+				newTags[k] = v
+			}
+			for k := range values {
+				newTags[k] = values.Get(k)
+			}
+			if len(newTags) > maxTags {
+				return nil, errors.Errorf("can't set more than %d tags - got %d", maxTags, len(newTags))
+			}
+			tags = newTags
+		default:
+			const msMetaPrefix = "x-ms-meta-"
+			if strings.HasPrefix(lowerKey, msMetaPrefix) {
+				meta[lowerKey[len(msMetaPrefix):]] = value
+			} else {
+				fs.Errorf(o, "Don't know how to set key %q on upload", key)
+			}
+		}
+	}
+	return tags, nil
+}
+
 // Update the object with the contents of the io.Reader, modTime and size
 //
 // The new object may have been created if an error is returned
@@ -1614,18 +1956,32 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 			sourceMD5bytes, err := hex.DecodeString(sourceMD5)
 			if err == nil {
 				httpHeaders.ContentMD5 = sourceMD5bytes
+				// Azure stores the Content-MD5 without checking it
+				// so check the data matches before the block list
+				// is committed
+				in, err = readers.NewVerifyingReader(in, map[hash.Type]string{hash.MD5: sourceMD5})
+				if err != nil {
+					return err
+				}
 			} else {
 				fs.Debugf(o, "Failed to decode %q as MD5: %v", sourceMD5, err)
 			}
 		}
 	}
 
+	// Apply upload options
+	tags, err := applyUploadOptions(o, options, &httpHeaders, o.meta, o.fs.uploadTags)
+	if err != nil {
+		return err
+	}
+
 	putBlobOptions := azblob.UploadStreamToBlockBlobOptions{
 		BufferSize:      int(o.fs.opt.ChunkSize),
 		MaxBuffers:      uploadConcurrency,
 		Metadata:        o.meta,
 		BlobHTTPHeaders: httpHeaders,
 		TransferManager: o.fs.newPoolWrapper(uploadConcurrency),
+		BlobTagsMap:     tags,
 	}
 
 	// Don't retry, return a retry error instead
@@ -1710,6 +2066,282 @@ func (o *Object) GetTier() string {
 	return string(o.accessTier)
 }
 
+// rehydrate starts rehydrating an archive tier blob to tier with
+// priority
+func (o *Object) rehydrate(ctx context.Context, tier azblob.AccessTierType, priority azblob.RehydratePriorityType) error {
+	blob := o.getBlobReference()
+	ctx = withRehydratePriority(ctx, priority)
+	err := o.fs.pacer.Call(func() (bool, error) {
+		_, err := blob.SetTier(ctx, tier, azblob.LeaseAccessConditions{})
+		return o.fs.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to rehydrate blob")
+	}
+	// The blob stays in the archive tier until it is rehydrated
+	o.archiveStatus = azblob.ArchiveStatusType("rehydrate-pending-to-" + strings.ToLower(string(tier)))
+	fs.Debugf(o, "Started rehydrating to %s tier with %s priority", tier, priority)
+	return nil
+}
+
+// archiveError returns the error for reading an archive tier blob.
+//
+// If --azureblob-archive-tier-rehydrate is set it starts rehydrating
+// the blob if it isn't being rehydrated already.
+func (o *Object) archiveError(ctx context.Context) error {
+	if o.archiveStatus != azblob.ArchiveStatusNone {
+		return fserrors.NoRetryError(errors.Errorf("blob in archive tier is being rehydrated (%s) - try again when it has finished", o.archiveStatus))
+	}
+	tier := azblob.AccessTierType(o.fs.opt.ArchiveTierRehydrate)
+	if tier == azblob.AccessTierNone {
+		return errors.Errorf("Blob in archive tier, you need to set tier to hot or cool first")
+	}
+	priority := azblob.RehydratePriorityType(o.fs.opt.RehydratePriority)
+	err := o.rehydrate(ctx, tier, priority)
+	if err != nil {
+		return err
+	}
+	return fserrors.NoRetryError(errors.Errorf("blob in archive tier - started rehydrating it to the %s tier with %s priority - try again when it has finished", tier, priority))
+}
+
+var commandHelp = []fs.CommandHelp{{
+	Name:  "find-by-tag",
+	Short: "Find blobs using their blob index tags",
+	Long: `This command finds blobs by their blob index tags using the Azure
+Find Blobs by Tags API, so the container doesn't have to be listed.
+This is much quicker than listing containers with millions of blobs
+in. Tags can be set on upload with the --azureblob-upload-tags flag.
+
+The argument is a where expression as described in the Azure
+documentation, for example
+
+    rclone backend find-by-tag azureblob:container "\"project\" = 'potato'"
+    rclone backend find-by-tag azureblob:container/dir "\"project\" = 'potato' AND \"team\" = 'mash'"
+
+Only blobs inside the remote's path are returned. The expression is
+limited to the container of the remote if it has one.
+
+It returns a list of paths relative to the remote, which can be used
+with --files-from to copy or sync just those blobs, for example
+
+    rclone backend find-by-tag azureblob:container "\"project\" = 'potato'" | jq -r '.[]' > files.txt
+    rclone copy --files-from files.txt azureblob:container /tmp/potato
+`,
+}, {
+	Name:  "restore",
+	Short: "Start rehydrating archive tier blobs",
+	Long: `This command starts rehydrating archive tier blobs to the hot or cool
+tier so they can be read.
+
+Usage Examples:
+
+    rclone backend restore azureblob:container/path/to/blob [-o tier=TIER] [-o priority=PRIORITY]
+    rclone backend restore azureblob:container/path/to/directory [-o tier=TIER] [-o priority=PRIORITY]
+
+The tier defaults to --azureblob-archive-tier-rehydrate or Hot if that
+isn't set and the priority to --azureblob-rehydrate-priority.
+
+This command obeys the filters. Test first with -i/--interactive or
+--dry-run flags
+
+    rclone -i backend restore --include "*.txt" azureblob:container/path -o priority=High
+
+Rehydrating can take up to 15 hours. Use the "restore-status" command
+to see how it is getting on.
+
+It returns a list of status dictionaries with Remote and Status
+keys. The Status will be OK if rehydrating was started, a message if
+the blob didn't need it or an error message.
+
+    [
+        {
+            "Status": "OK",
+            "Remote": "test.txt"
+        },
+        {
+            "Status": "Not in archive tier",
+            "Remote": "test/file4.txt"
+        }
+    ]
+`,
+	Opts: map[string]string{
+		"tier":     "Tier to rehydrate to: Hot|Cool",
+		"priority": "Priority of rehydration: Standard|High",
+	},
+}, {
+	Name:  "restore-status",
+	Short: "Show the archive tier blobs and how rehydrating them is getting on",
+	Long: `This command lists the archive tier blobs and whether they are being
+rehydrated.
+
+    rclone backend restore-status azureblob:container/path
+
+This command obeys the filters.
+
+It returns a list of dictionaries with Remote and Status keys. The
+Status is the archive status from Azure, for example
+"rehydrate-pending-to-hot", or "archived" if the blob isn't being
+rehydrated. Blobs disappear from the list when they have been
+rehydrated.
+
+    [
+        {
+            "Status": "rehydrate-pending-to-hot",
+            "Remote": "test.txt"
+        },
+        {
+            "Status": "archived",
+            "Remote": "test/file4.txt"
+        }
+    ]
+`,
+}}
+
+// Command the backend to run a named command
+//
+// The command run is name
+// args may be used to read arguments from
+// opts may be used to read optional arguments from
+//
+// The result should be capable of being JSON encoded
+// If it is a string or a []string it will be shown to the user
+// otherwise it will be JSON encoded and shown to the user like that
+func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[string]string) (out interface{}, err error) {
+	switch name {
+	case "find-by-tag":
+		if len(arg) != 1 {
+			return nil, errors.New("need exactly one argument - the where expression")
+		}
+		return f.findByTag(ctx, arg[0])
+	case "restore":
+		return f.restore(ctx, opt)
+	case "restore-status":
+		return f.restoreStatus(ctx)
+	default:
+		return nil, fs.ErrorCommandNotFound
+	}
+}
+
+// findByTag returns the paths relative to the root of the blobs
+// matching the blob index tag expression where
+func (f *Fs) findByTag(ctx context.Context, where string) (paths []string, err error) {
+	if f.rootContainer != "" {
+		where = fmt.Sprintf("@container = '%s' AND %s", f.opt.Enc.FromStandardName(f.rootContainer), where)
+	}
+	prefix := ""
+	if f.rootDirectory != "" {
+		prefix = f.rootDirectory + "/"
+	}
+	paths = []string{}
+	maxResults := int32(f.opt.ListChunkSize)
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		var response *azblob.FilterBlobSegment
+		err = f.pacer.Call(func() (bool, error) {
+			var err error
+			response, err = f.svcURL.FindBlobsByTags(ctx, nil, nil, &where, marker, &maxResults)
+			return f.shouldRetry(ctx, err)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "find blobs by tags failed")
+		}
+		for _, item := range response.Blobs {
+			container := f.opt.Enc.ToStandardName(item.ContainerName)
+			remote := f.opt.Enc.ToStandardPath(item.Name)
+			if f.rootContainer == "" {
+				remote = path.Join(container, remote)
+			} else if container != f.rootContainer || !strings.HasPrefix(remote, prefix) {
+				continue
+			} else {
+				remote = remote[len(prefix):]
+			}
+			paths = append(paths, remote)
+		}
+		if response.NextMarker == nil {
+			break
+		}
+		marker = azblob.Marker{Val: response.NextMarker}
+	}
+	return paths, nil
+}
+
+// restoreStatus is the result of the restore and restore-status
+// backend commands for one blob
+type restoreStatus struct {
+	Status string
+	Remote string
+}
+
+// restore starts rehydrating the archive tier blobs under the root
+func (f *Fs) restore(ctx context.Context, opt map[string]string) (out []restoreStatus, err error) {
+	tier := f.opt.ArchiveTierRehydrate
+	if tier == "" {
+		tier = string(azblob.AccessTierHot)
+	}
+	priority := f.opt.RehydratePriority
+	for key, value := range opt {
+		switch key {
+		case "tier":
+			tier = value
+		case "priority":
+			priority = value
+		default:
+			return nil, errors.Errorf("unknown option %q", key)
+		}
+	}
+	err = validateRehydrate(tier, priority)
+	if err != nil {
+		return nil, err
+	}
+	var outMu sync.Mutex
+	out = []restoreStatus{}
+	err = operations.ListFn(ctx, f, func(obj fs.Object) {
+		// Remember this is run --checkers times concurrently
+		o, ok := obj.(*Object)
+		st := restoreStatus{Status: "OK", Remote: obj.Remote()}
+		defer func() {
+			outMu.Lock()
+			out = append(out, st)
+			outMu.Unlock()
+		}()
+		switch {
+		case !ok:
+			st.Status = "Not an Azure blob"
+		case o.accessTier != azblob.AccessTierArchive:
+			st.Status = "Not in archive tier"
+		case o.archiveStatus != azblob.ArchiveStatusNone:
+			st.Status = "Already being rehydrated (" + string(o.archiveStatus) + ")"
+		case operations.SkipDestructive(ctx, obj, "restore"):
+		default:
+			err := o.rehydrate(ctx, azblob.AccessTierType(tier), azblob.RehydratePriorityType(priority))
+			if err != nil {
+				st.Status = err.Error()
+			}
+		}
+	})
+	return out, err
+}
+
+// restoreStatus lists the archive tier blobs under the root with
+// their rehydration status
+func (f *Fs) restoreStatus(ctx context.Context) (out []restoreStatus, err error) {
+	var outMu sync.Mutex
+	out = []restoreStatus{}
+	err = operations.ListFn(ctx, f, func(obj fs.Object) {
+		o, ok := obj.(*Object)
+		if !ok || o.accessTier != azblob.AccessTierArchive {
+			return
+		}
+		st := restoreStatus{Status: string(o.archiveStatus), Remote: o.remote}
+		if st.Status == "" {
+			st.Status = "archived"
+		}
+		outMu.Lock()
+		out = append(out, st)
+		outMu.Unlock()
+	})
+	return out, err
+}
+
 // Check the interfaces are satisfied
 var (
 	_ fs.Fs          = &Fs{}
@@ -1717,6 +2349,8 @@ var (
 	_ fs.PutStreamer = &Fs{}
 	_ fs.Purger      = &Fs{}
 	_ fs.ListRer     = &Fs{}
+	_ fs.CleanUpper  = &Fs{}
+	_ fs.Commander   = &Fs{}
 	_ fs.Object      = &Object{}
 	_ fs.MimeTyper   = &Object{}
 	_ fs.GetTierer   = &Object{}
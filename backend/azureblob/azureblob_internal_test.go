@@ -3,9 +3,21 @@
 package azureblob
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config/configmap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func (f *Fs) InternalTest(t *testing.T) {
@@ -33,3 +45,193 @@ func TestIncrement(t *testing.T) {
 		assert.Equal(t, test.want, test.in)
 	}
 }
+
+func TestParseTags(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    azblob.BlobTagsMap
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"  ", nil, false},
+		{"project=potato", azblob.BlobTagsMap{"project": "potato"}, false},
+		{" project = potato , team=mash,empty=,", azblob.BlobTagsMap{"project": "potato", "team": "mash", "empty": ""}, false},
+		{"project", nil, true},
+		{"=potato", nil, true},
+		{"a=1,b=2,c=3,d=4,e=5,f=6,g=7,h=8,i=9,j=10,k=11", nil, true},
+	} {
+		got, err := parseTags(test.in)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+		} else {
+			assert.NoError(t, err, test.in)
+			assert.Equal(t, test.want, got, test.in)
+		}
+	}
+}
+
+func TestApplyUploadOptions(t *testing.T) {
+	o := &Object{remote: "potato"}
+	uploadTags := azblob.BlobTagsMap{"project": "potato"}
+	var httpHeaders azblob.BlobHTTPHeaders
+	meta := azblob.Metadata{"mtime": "2021-01-01T00:00:00Z"}
+	tags, err := applyUploadOptions(o, []fs.OpenOption{
+		&fs.HTTPOption{Key: "Cache-Control", Value: "max-age=3600"},
+		&fs.HTTPOption{Key: "Content-Disposition", Value: "attachment"},
+		&fs.HTTPOption{Key: "Content-Encoding", Value: "gzip"},
+		&fs.HTTPOption{Key: "Content-Language", Value: "en"},
+		&fs.HTTPOption{Key: "Content-Type", Value: "text/plain"},
+		&fs.HTTPOption{Key: "X-Ms-Tags", Value: "team=mash&project=jersey"},
+		&fs.HTTPOption{Key: "X-Ms-Meta-Colour", Value: "purple"},
+		&fs.HTTPOption{Key: "X-Unknown", Value: "ignored"},
+		&fs.RangeOption{Start: 0, End: 1},
+	}, &httpHeaders, meta, uploadTags)
+	require.NoError(t, err)
+	assert.Equal(t, azblob.BlobHTTPHeaders{
+		CacheControl:       "max-age=3600",
+		ContentDisposition: "attachment",
+		ContentEncoding:    "gzip",
+		ContentLanguage:    "en",
+		ContentType:        "text/plain",
+	}, httpHeaders)
+	assert.Equal(t, azblob.Metadata{"mtime": "2021-01-01T00:00:00Z", "colour": "purple"}, meta)
+	assert.Equal(t, azblob.BlobTagsMap{"project": "jersey", "team": "mash"}, tags)
+	// the configured tags shouldn't be modified
+	assert.Equal(t, azblob.BlobTagsMap{"project": "potato"}, uploadTags)
+
+	// no tag options should return the configured tags
+	tags, err = applyUploadOptions(o, nil, &httpHeaders, meta, uploadTags)
+	require.NoError(t, err)
+	assert.Equal(t, uploadTags, tags)
+
+	// errors
+	_, err = applyUploadOptions(o, []fs.OpenOption{
+		&fs.HTTPOption{Key: "X-Ms-Tags", Value: "bad=%zz"},
+	}, &httpHeaders, meta, nil)
+	assert.Error(t, err)
+	_, err = applyUploadOptions(o, []fs.OpenOption{
+		&fs.HTTPOption{Key: "X-Ms-Tags", Value: "a=1&b=2&c=3&d=4&e=5&f=6&g=7&h=8&i=9&j=10"},
+	}, &httpHeaders, meta, uploadTags)
+	assert.Error(t, err)
+}
+
+// cleanUpServer is a fake Azure blob server with a container with
+// a committed blob "committed" and a blob "uncommitted" which only
+// has uncommitted blocks.
+type cleanUpServer struct {
+	mu             sync.Mutex
+	commits        []string // names of blobs committed
+	deletes        []string // names of blobs deleted
+	deleteFailures int      // number of times to fail the delete
+}
+
+func (s *cleanUpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := strings.TrimPrefix(r.URL.Path, "/container/")
+	query := r.URL.Query()
+	switch {
+	case r.Method == "GET" && query.Get("comp") == "list":
+		blobs := "<Blob><Name>committed</Name></Blob>"
+		if strings.Contains(query.Get("include"), "uncommittedblobs") {
+			blobs += "<Blob><Name>uncommitted</Name></Blob>"
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?><EnumerationResults ContainerName="container"><Blobs>%s</Blobs><NextMarker/></EnumerationResults>`, blobs)
+	case r.Method == "PUT" && query.Get("comp") == "blocklist":
+		if r.Header.Get("If-None-Match") != "*" {
+			http.Error(w, "missing If-None-Match", http.StatusBadRequest)
+			return
+		}
+		for _, committed := range s.commits {
+			if committed == name {
+				w.Header().Set("x-ms-error-code", "BlobAlreadyExists")
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+		}
+		s.commits = append(s.commits, name)
+		w.Header().Set("ETag", `"0x8D8"`)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "DELETE":
+		if r.Header.Get("If-Match") != `"0x8D8"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if s.deleteFailures > 0 {
+			s.deleteFailures--
+			w.Header().Set("x-ms-error-code", "ServerBusy")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		s.deletes = append(s.deletes, name)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.String(), http.StatusBadRequest)
+	}
+}
+
+func TestCleanUp(t *testing.T) {
+	ctx := context.Background()
+	s := &cleanUpServer{deleteFailures: 2}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	m := configmap.Simple{}
+	for _, opt := range fs.MustFind("azureblob").Options {
+		m.Set(opt.Name, opt.String())
+	}
+	m.Set("sas_url", server.URL+"/container?sv=2019-12-12&sig=potato")
+	f, err := NewFs(ctx, "TestCleanUp", "container", m)
+	require.NoError(t, err)
+
+	// The delete should be retried without redoing the commit
+	require.NoError(t, f.(*Fs).CleanUp(ctx))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Equal(t, []string{"uncommitted"}, s.commits)
+	assert.Equal(t, []string{"uncommitted"}, s.deletes)
+	assert.Equal(t, 0, s.deleteFailures)
+}
+
+func TestValidateRehydrate(t *testing.T) {
+	for _, test := range []struct {
+		tier     string
+		priority string
+		wantErr  bool
+	}{
+		{"", "Standard", false},
+		{"Hot", "Standard", false},
+		{"Cool", "High", false},
+		{"Archive", "Standard", true},
+		{"hot", "Standard", true},
+		{"Hot", "", true},
+		{"Hot", "Potato", true},
+	} {
+		err := validateRehydrate(test.tier, test.priority)
+		assert.Equal(t, test.wantErr, err != nil, fmt.Sprintf("%q %q", test.tier, test.priority))
+	}
+}
+
+func TestRehydratePriorityFactory(t *testing.T) {
+	var got string
+	policy := rehydratePriorityFactory().New(pipeline.PolicyFunc(func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+		got = request.Header.Get("x-ms-rehydrate-priority")
+		return nil, nil
+	}), nil)
+	do := func(ctx context.Context, rawurl string) string {
+		u, err := url.Parse(rawurl)
+		require.NoError(t, err)
+		request, err := pipeline.NewRequest("PUT", *u, nil)
+		require.NoError(t, err)
+		got = ""
+		_, err = policy.Do(ctx, request)
+		require.NoError(t, err)
+		return got
+	}
+	ctx := context.Background()
+	highCtx := withRehydratePriority(ctx, azblob.RehydratePriorityHigh)
+	assert.Equal(t, "High", do(highCtx, "https://account.blob.core.windows.net/container/blob?comp=tier"))
+	assert.Equal(t, "", do(highCtx, "https://account.blob.core.windows.net/container/blob"))
+	assert.Equal(t, "", do(ctx, "https://account.blob.core.windows.net/container/blob?comp=tier"))
+}
@@ -0,0 +1,124 @@
+// Block blob uploads driven a chunk at a time by OpenChunkWriter
+
+// +build !plan9,!solaris,!js,go1.14
+
+package azureblob
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// maxBlocks is the maximum number of blocks in a block blob
+const maxBlocks = 50000
+
+// OpenChunkWriter returns a writer which uploads remote as a block
+// blob a block at a time so the size needn't be known in advance.
+func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, err error) {
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	container, _ := o.split()
+	err = f.makeContainer(ctx, container)
+	if err != nil {
+		return info, nil, err
+	}
+	o.updateMetadataWithModTime(src.ModTime(ctx))
+	httpHeaders := azblob.BlobHTTPHeaders{
+		ContentType: fs.MimeType(ctx, src),
+	}
+	tags, err := applyUploadOptions(o, options, &httpHeaders, o.meta, f.uploadTags)
+	if err != nil {
+		return info, nil, err
+	}
+	info = fs.ChunkWriterInfo{
+		ChunkSize: int64(f.opt.ChunkSize),
+		MaxChunks: maxBlocks,
+	}
+	return info, &chunkWriter{
+		o:           o,
+		blob:        o.getBlobReference().ToBlockBlobURL(),
+		httpHeaders: httpHeaders,
+		tags:        tags,
+	}, nil
+}
+
+// chunkWriter stages each chunk it is given as a block of a block
+// blob, committing them all when it is closed
+type chunkWriter struct {
+	o           *Object
+	blob        azblob.BlockBlobURL
+	httpHeaders azblob.BlobHTTPHeaders
+	tags        azblob.BlobTagsMap
+	blockIDs    []string // base64 encoded IDs of the blocks staged so far
+}
+
+// WriteChunk stages the chunk as block number chunkNumber
+func (w *chunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	// create checksum of the chunk for integrity checking
+	hasher := md5.New()
+	size, err := io.Copy(hasher, reader)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read block")
+	}
+	md5sum := hasher.Sum(nil)
+
+	// All the block IDs in a blob must be the same length
+	var rawID [8]byte
+	binary.BigEndian.PutUint64(rawID[:], uint64(chunkNumber))
+	blockID := base64.StdEncoding.EncodeToString(rawID[:])
+
+	err = w.o.fs.pacer.Call(func() (bool, error) {
+		_, err := reader.Seek(0, io.SeekStart)
+		if err != nil {
+			return false, err
+		}
+		_, err = w.blob.StageBlock(ctx, blockID, reader, azblob.LeaseAccessConditions{}, md5sum, azblob.ClientProvidedKeyOptions{})
+		return w.o.fs.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to upload block")
+	}
+	w.blockIDs = append(w.blockIDs, blockID)
+	return size, nil
+}
+
+// Close commits the staged blocks as the contents of the blob
+func (w *chunkWriter) Close(ctx context.Context) error {
+	o := w.o
+	err := o.fs.pacer.Call(func() (bool, error) {
+		_, err := w.blob.CommitBlockList(ctx, w.blockIDs, w.httpHeaders, o.meta, azblob.BlobAccessConditions{}, azblob.AccessTierNone, w.tags, azblob.ClientProvidedKeyOptions{})
+		return o.fs.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to commit blocks")
+	}
+	if o.fs.opt.AccessTier == string(defaultAccessTier) {
+		return nil
+	}
+	err = o.readMetaData()
+	if err != nil {
+		return err
+	}
+	return o.SetTier(o.fs.opt.AccessTier)
+}
+
+// Abort does nothing as blocks which are never committed are
+// discarded by Azure after a week
+func (w *chunkWriter) Abort(ctx context.Context) error {
+	return nil
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.OpenChunkWriterer = &Fs{}
+	_ fs.ChunkWriter       = &chunkWriter{}
+)
@@ -33,10 +33,27 @@ var _ fserrors.Fataler = (*Error)(nil)
 
 // Bucket describes a B2 bucket
 type Bucket struct {
-	ID        string `json:"bucketId"`
-	AccountID string `json:"accountId"`
-	Name      string `json:"bucketName"`
-	Type      string `json:"bucketType"`
+	ID             string          `json:"bucketId"`
+	AccountID      string          `json:"accountId"`
+	Name           string          `json:"bucketName"`
+	Type           string          `json:"bucketType"`
+	LifecycleRules []LifecycleRule `json:"lifecycleRules,omitempty"`
+}
+
+// LifecycleRule is a rule for hiding and deleting the files in a
+// bucket whose names start with FileNamePrefix
+type LifecycleRule struct {
+	DaysFromHidingToDeleting  *int   `json:"daysFromHidingToDeleting,omitempty"`  // Delete hidden files after this many days
+	DaysFromUploadingToHiding *int   `json:"daysFromUploadingToHiding,omitempty"` // Hide files after this many days
+	FileNamePrefix            string `json:"fileNamePrefix"`                      // The rule applies to files whose names start with this
+}
+
+// FileRetention is the Object Lock retention setting of a file.
+//
+// Set both fields to nil to remove the retention.
+type FileRetention struct {
+	Mode                 *string    `json:"mode"`                 // "governance" or "compliance"
+	RetainUntilTimestamp *Timestamp `json:"retainUntilTimestamp"` // The file can't be deleted until this time
 }
 
 // Timestamp is a UTC time when this file was uploaded. It is a base
@@ -225,6 +242,13 @@ type CreateBucketRequest struct {
 	Type      string `json:"bucketType"`
 }
 
+// UpdateBucketRequest is used to change the lifecycle rules of a bucket
+type UpdateBucketRequest struct {
+	ID             string          `json:"bucketId"`
+	AccountID      string          `json:"accountId"`
+	LifecycleRules []LifecycleRule `json:"lifecycleRules"`
+}
+
 // DeleteBucketRequest is used to create a bucket
 type DeleteBucketRequest struct {
 	ID        string `json:"bucketId"`
@@ -265,10 +289,12 @@ type GetFileInfoRequest struct {
 //
 // Example: { "src_last_modified_millis" : "1452802803026", "large_file_sha1" : "a3195dc1e7b46a2ff5da4b3c179175b75671e80d", "color": "blue" }
 type StartLargeFileRequest struct {
-	BucketID    string            `json:"bucketId"`    //The ID of the bucket that the file will go in.
-	Name        string            `json:"fileName"`    // The name of the file. See Files for requirements on file names.
-	ContentType string            `json:"contentType"` // The MIME type of the content of the file, which will be returned in the Content-Type header when downloading the file. Use the Content-Type b2/x-auto to automatically set the stored Content-Type post upload. In the case where a file extension is absent or the lookup fails, the Content-Type is set to application/octet-stream.
-	Info        map[string]string `json:"fileInfo"`    // A JSON object holding the name/value pairs for the custom file info.
+	BucketID      string            `json:"bucketId"`                //The ID of the bucket that the file will go in.
+	Name          string            `json:"fileName"`                // The name of the file. See Files for requirements on file names.
+	ContentType   string            `json:"contentType"`             // The MIME type of the content of the file, which will be returned in the Content-Type header when downloading the file. Use the Content-Type b2/x-auto to automatically set the stored Content-Type post upload. In the case where a file extension is absent or the lookup fails, the Content-Type is set to application/octet-stream.
+	Info          map[string]string `json:"fileInfo"`                // A JSON object holding the name/value pairs for the custom file info.
+	LegalHold     string            `json:"legalHold,omitempty"`     // "on" to put a legal hold on the file
+	FileRetention *FileRetention    `json:"fileRetention,omitempty"` // The retention setting for the file
 }
 
 // StartLargeFileResponse is the response to StartLargeFileRequest
@@ -336,6 +362,37 @@ type CopyFileRequest struct {
 	ContentType       string            `json:"contentType,omitempty"`         // The MIME type of the content of the file (REPLACE only)
 	Info              map[string]string `json:"fileInfo,omitempty"`            // This field stores the metadata that will be stored with the file. (REPLACE only)
 	DestBucketID      string            `json:"destinationBucketId,omitempty"` // The destination ID of the bucket if set, if not the source bucket will be used
+	LegalHold         string            `json:"legalHold,omitempty"`           // "on" to put a legal hold on the new file
+	FileRetention     *FileRetention    `json:"fileRetention,omitempty"`       // The retention setting for the new file
+}
+
+// UpdateFileLegalHoldRequest is as passed to b2_update_file_legal_hold
+type UpdateFileLegalHoldRequest struct {
+	ID        string `json:"fileId"`    // The ID of the file version
+	Name      string `json:"fileName"`  // The name of the file
+	LegalHold string `json:"legalHold"` // "on" or "off"
+}
+
+// UpdateFileLegalHoldResponse is returned from b2_update_file_legal_hold
+type UpdateFileLegalHoldResponse struct {
+	ID        string `json:"fileId"`    // The ID of the file version
+	Name      string `json:"fileName"`  // The name of the file
+	LegalHold string `json:"legalHold"` // "on" or "off"
+}
+
+// UpdateFileRetentionRequest is as passed to b2_update_file_retention
+type UpdateFileRetentionRequest struct {
+	ID               string        `json:"fileId"`                     // The ID of the file version
+	Name             string        `json:"fileName"`                   // The name of the file
+	FileRetention    FileRetention `json:"fileRetention"`              // The new retention setting
+	BypassGovernance bool          `json:"bypassGovernance,omitempty"` // Needed to shorten or remove governance mode retention
+}
+
+// UpdateFileRetentionResponse is returned from b2_update_file_retention
+type UpdateFileRetentionResponse struct {
+	ID            string        `json:"fileId"`        // The ID of the file version
+	Name          string        `json:"fileName"`      // The name of the file
+	FileRetention FileRetention `json:"fileRetention"` // The new retention setting
 }
 
 // CopyPartRequest is the request for b2_copy_part - the response is UploadPartResponse
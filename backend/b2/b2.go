@@ -29,6 +29,7 @@ import (
 	"github.com/artpar/rclone/fs/fserrors"
 	"github.com/artpar/rclone/fs/fshttp"
 	"github.com/artpar/rclone/fs/hash"
+	"github.com/artpar/rclone/fs/operations"
 	"github.com/artpar/rclone/fs/walk"
 	"github.com/artpar/rclone/lib/bucket"
 	"github.com/artpar/rclone/lib/encoder"
@@ -48,6 +49,9 @@ const (
 	idHeader            = "X-Bz-File-Id"
 	nameHeader          = "X-Bz-File-Name"
 	timestampHeader     = "X-Bz-Upload-Timestamp"
+	legalHoldHeader     = "X-Bz-File-Legal-Hold"
+	retentionModeHeader = "X-Bz-File-Retention-Mode"
+	retainUntilHeader   = "X-Bz-File-Retention-Retain-Until-Timestamp"
 	retryAfterHeader    = "Retry-After"
 	minSleep            = 10 * time.Millisecond
 	maxSleep            = 5 * time.Minute
@@ -60,6 +64,7 @@ const (
 	largeFileCopyCutoff = 4 * fs.GibiByte          // 5E9 is the max
 	memoryPoolFlushTime = fs.Duration(time.Minute) // flush the cached buffers after this long
 	memoryPoolUseMmap   = false
+	maxDownloadAuth     = fs.Duration(7 * 24 * time.Hour) // longest a download authorization can last
 )
 
 // Globals
@@ -73,6 +78,7 @@ func init() {
 		Name:        "b2",
 		Description: "Backblaze B2",
 		NewFs:       NewFs,
+		CommandHelp: commandHelp,
 		Options: []fs.Option{{
 			Name:     "account",
 			Help:     "Account ID or Application Key ID",
@@ -165,8 +171,47 @@ Leave blank if you want to use the endpoint provided by Backblaze.`,
 			Help: `Time before the authorization token will expire in s or suffix ms|s|m|h|d.
 
 The duration before the download authorization token will expire.
-The minimum value is 1 second. The maximum value is one week.`,
-			Default:  fs.Duration(7 * 24 * time.Hour),
+The minimum value is 1 second. The maximum value is one week.
+
+This is used for the links made by "rclone link" on private buckets
+unless a shorter --expire is given.`,
+			Default:  maxDownloadAuth,
+			Advanced: true,
+		}, {
+			Name: "lock_legal_hold",
+			Help: `Put a legal hold on uploaded files.
+
+A file with a legal hold can't be deleted until the hold is removed,
+for example with the "legal-hold" backend command.
+
+This needs a bucket with File Lock enabled.`,
+			Default:  false,
+			Advanced: true,
+		}, {
+			Name: "lock_retention_mode",
+			Help: `File Lock retention mode to set on uploaded files.
+
+Uploaded files can't be deleted until --b2-lock-retention-period has
+passed. Keys with the bypassGovernance capability can get round this
+in "governance" mode but nothing can in "compliance" mode.
+
+This needs a bucket with File Lock enabled.`,
+			Default: "",
+			Examples: []fs.OptionExample{{
+				Value: "",
+				Help:  "Don't set a retention",
+			}, {
+				Value: "governance",
+				Help:  "Retain files unless the key has the bypassGovernance capability",
+			}, {
+				Value: "compliance",
+				Help:  "Retain files - this can't be undone",
+			}},
+			Advanced: true,
+		}, {
+			Name:     "lock_retention_period",
+			Help:     `How long to retain uploaded files for with --b2-lock-retention-mode.`,
+			Default:  fs.Duration(0),
 			Advanced: true,
 		}, {
 			Name:     "memory_pool_flush_time",
@@ -190,7 +235,7 @@ This option controls how often unused buffers will be removed from the pool.`,
 			Default: (encoder.Display |
 				encoder.EncodeBackSlash |
 				encoder.EncodeInvalidUtf8),
-		}},
+		}, fs.ProxyOption, fs.APIBudgetOption},
 	})
 }
 
@@ -208,6 +253,9 @@ type Options struct {
 	DisableCheckSum               bool                 `config:"disable_checksum"`
 	DownloadURL                   string               `config:"download_url"`
 	DownloadAuthorizationDuration fs.Duration          `config:"download_auth_duration"`
+	LockLegalHold                 bool                 `config:"lock_legal_hold"`
+	LockRetentionMode             string               `config:"lock_retention_mode"`
+	LockRetentionPeriod           fs.Duration          `config:"lock_retention_period"`
 	MemoryPoolFlushTime           fs.Duration          `config:"memory_pool_flush_time"`
 	MemoryPoolUseMmap             bool                 `config:"memory_pool_use_mmap"`
 	Enc                           encoder.MultiEncoder `config:"encoding"`
@@ -418,6 +466,10 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	if err != nil {
 		return nil, errors.Wrap(err, "b2: chunk size")
 	}
+	err = checkRetention(opt.LockRetentionMode, opt.LockRetentionPeriod)
+	if err != nil {
+		return nil, errors.Wrap(err, "b2: lock retention")
+	}
 	if opt.Account == "" {
 		return nil, errors.New("account not found")
 	}
@@ -1283,6 +1335,7 @@ func (f *Fs) copy(ctx context.Context, dstObj *Object, srcObj *Object, newInfo *
 		Name:         f.opt.Enc.FromStandardPath(dstPath),
 		DestBucketID: destBucketID,
 	}
+	request.LegalHold, request.FileRetention = f.fileLock()
 	if newInfo == nil {
 		request.MetadataDirective = "COPY"
 	} else {
@@ -1333,12 +1386,42 @@ func (f *Fs) Hashes() hash.Set {
 	return hash.Set(hash.SHA1)
 }
 
+// downloadURL returns the root URL to download files from
+func (f *Fs) downloadURL() string {
+	// Use downloadUrl from backblaze if downloadUrl is not set
+	// otherwise use the custom downloadUrl
+	if f.opt.DownloadURL == "" {
+		return f.info.DownloadURL
+	}
+	return f.opt.DownloadURL
+}
+
+// friendlyPath returns the path of the "friendly URL" to download
+// bucketPath in bucket by name relative to the download URL
+func (f *Fs) friendlyPath(bucket, bucketPath string) string {
+	return "/file/" + urlEncode(f.opt.Enc.FromStandardName(bucket)) + "/" + urlEncode(f.opt.Enc.FromStandardPath(bucketPath))
+}
+
+// linkDuration returns how long a download authorization made for a
+// link which should expire after expire should last.
+//
+// B2 authorizations can't last longer than a week so if expire is
+// longer than that (as it is by default) then the
+// --b2-download-auth-duration is used instead.
+func (f *Fs) linkDuration(expire fs.Duration) fs.Duration {
+	if expire > 0 && expire <= maxDownloadAuth {
+		return expire
+	}
+	fs.Debugf(f, "Public Link: using --b2-download-auth-duration %v as expiry %v is greater than the max time allowed", f.opt.DownloadAuthorizationDuration, expire)
+	return f.opt.DownloadAuthorizationDuration
+}
+
 // getDownloadAuthorization returns authorization token for downloading
-// without account.
-func (f *Fs) getDownloadAuthorization(ctx context.Context, bucket, remote string) (authorization string, err error) {
-	validDurationInSeconds := time.Duration(f.opt.DownloadAuthorizationDuration).Nanoseconds() / 1e9
-	if validDurationInSeconds <= 0 || validDurationInSeconds > 604800 {
-		return "", errors.New("--b2-download-auth-duration must be between 1 sec and 1 week")
+// without account the files whose names start with bucketPath.
+func (f *Fs) getDownloadAuthorization(ctx context.Context, bucket, bucketPath string, validity fs.Duration) (authorization string, err error) {
+	validDurationInSeconds := time.Duration(validity).Nanoseconds() / 1e9
+	if validDurationInSeconds <= 0 || validity > maxDownloadAuth {
+		return "", errors.New("--expire or --b2-download-auth-duration must be between 1 sec and 1 week")
 	}
 	if !f.hasPermission("shareFiles") {
 		return "", errors.New("sharing a file link requires the shareFiles permission")
@@ -1353,7 +1436,7 @@ func (f *Fs) getDownloadAuthorization(ctx context.Context, bucket, remote string
 	}
 	var request = api.GetDownloadAuthorizationRequest{
 		BucketID:               bucketID,
-		FileNamePrefix:         f.opt.Enc.FromStandardPath(path.Join(f.root, remote)),
+		FileNamePrefix:         f.opt.Enc.FromStandardPath(bucketPath),
 		ValidDurationInSeconds: validDurationInSeconds,
 	}
 	var response api.GetDownloadAuthorizationResponse
@@ -1368,14 +1451,16 @@ func (f *Fs) getDownloadAuthorization(ctx context.Context, bucket, remote string
 }
 
 // PublicLink returns a link for downloading without account
+//
+// This is the "friendly URL" of the file or directory. If the bucket
+// is private then it has an authorization token which lasts for
+// expire, or --b2-download-auth-duration if that is longer than B2
+// allows.
 func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration, unlink bool) (link string, err error) {
-	bucket, bucketPath := f.split(remote)
-	var RootURL string
-	if f.opt.DownloadURL == "" {
-		RootURL = f.info.DownloadURL
-	} else {
-		RootURL = f.opt.DownloadURL
+	if unlink {
+		return "", errors.New("can't remove B2 links - they stop working when they expire")
 	}
+	bucket, bucketPath := f.split(remote)
 	_, err = f.NewObject(ctx, remote)
 	if err == fs.ErrorObjectNotFound || err == fs.ErrorNotAFile {
 		err2 := f.list(ctx, bucket, bucketPath, f.rootDirectory, f.rootBucket == "", false, 1, f.opt.Versions, false, func(remote string, object *api.File, isDirectory bool) error {
@@ -1389,14 +1474,13 @@ func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration,
 	if err != nil {
 		return "", err
 	}
-	absPath := "/" + bucketPath
-	link = RootURL + "/file/" + urlEncode(bucket) + absPath
+	link = f.downloadURL() + f.friendlyPath(bucket, bucketPath)
 	bucketType, err := f.getbucketType(ctx, bucket)
 	if err != nil {
 		return "", err
 	}
 	if bucketType == "allPrivate" || bucketType == "snapshot" {
-		AuthorizationToken, err := f.getDownloadAuthorization(ctx, bucket, remote)
+		AuthorizationToken, err := f.getDownloadAuthorization(ctx, bucket, bucketPath, f.linkDuration(expire))
 		if err != nil {
 			return "", err
 		}
@@ -1578,6 +1662,38 @@ func timeString(modTime time.Time) string {
 	return strconv.FormatInt(modTime.UnixNano()/1e6, 10)
 }
 
+// checkRetention checks the retention mode and period are valid
+func checkRetention(mode string, period fs.Duration) error {
+	switch mode {
+	case "":
+		return nil
+	case "governance", "compliance":
+	default:
+		return errors.Errorf("unknown mode %q - need governance or compliance", mode)
+	}
+	if period <= 0 {
+		return errors.New("need a period")
+	}
+	return nil
+}
+
+// fileLock returns the legal hold and the retention to set on new
+// files from the options, "" and nil if they shouldn't be set
+func (f *Fs) fileLock() (legalHold string, retention *api.FileRetention) {
+	if f.opt.LockLegalHold {
+		legalHold = "on"
+	}
+	if f.opt.LockRetentionMode != "" {
+		mode := f.opt.LockRetentionMode
+		until := api.Timestamp(time.Now().Add(time.Duration(f.opt.LockRetentionPeriod)))
+		retention = &api.FileRetention{
+			Mode:                 &mode,
+			RetainUntilTimestamp: &until,
+		}
+	}
+	return legalHold, retention
+}
+
 // parseTimeString converts a decimal string number of milliseconds
 // elapsed since January 1, 1970 UTC into a time.Time and stores it in
 // the modTime variable.
@@ -1690,20 +1806,13 @@ func (o *Object) getOrHead(ctx context.Context, method string, options []fs.Open
 		NoResponse: method == "HEAD",
 	}
 
-	// Use downloadUrl from backblaze if downloadUrl is not set
-	// otherwise use the custom downloadUrl
-	if o.fs.opt.DownloadURL == "" {
-		opts.RootURL = o.fs.info.DownloadURL
-	} else {
-		opts.RootURL = o.fs.opt.DownloadURL
-	}
+	opts.RootURL = o.fs.downloadURL()
 
 	// Download by id if set and not using DownloadURL otherwise by name
 	if o.id != "" && o.fs.opt.DownloadURL == "" {
 		opts.Path += "/b2api/v1/b2_download_file_by_id?fileId=" + urlEncode(o.id)
 	} else {
-		bucket, bucketPath := o.split()
-		opts.Path += "/file/" + urlEncode(o.fs.opt.Enc.FromStandardName(bucket)) + "/" + urlEncode(o.fs.opt.Enc.FromStandardPath(bucketPath))
+		opts.Path += o.fs.friendlyPath(o.split())
 	}
 	err = o.fs.pacer.Call(func() (bool, error) {
 		resp, err = o.fs.srv.Call(ctx, &opts)
@@ -1949,6 +2058,14 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 		},
 		ContentLength: &size,
 	}
+	legalHold, retention := o.fs.fileLock()
+	if legalHold != "" {
+		opts.ExtraHeaders[legalHoldHeader] = legalHold
+	}
+	if retention != nil {
+		opts.ExtraHeaders[retentionModeHeader] = *retention.Mode
+		opts.ExtraHeaders[retainUntilHeader] = timeString(time.Time(*retention.RetainUntilTimestamp))
+	}
 	var response api.FileInfo
 	// Don't retry, return a retry error instead
 	err = o.fs.pacer.CallNoRetry(func() (bool, error) {
@@ -1989,9 +2106,392 @@ func (o *Object) ID() string {
 	return o.id
 }
 
+var commandHelp = []fs.CommandHelp{{
+	Name:  "lifecycle",
+	Short: "Read or set the lifecycle rules of a bucket",
+	Long: `This command reads or sets the lifecycle rules of a bucket.
+
+Usage Examples:
+
+    rclone backend lifecycle b2:bucket
+    rclone backend lifecycle b2:bucket -o daysFromHidingToDeleting=1
+    rclone backend lifecycle b2:bucket -o prefix=logs/ -o daysFromUploadingToHiding=30 -o daysFromHidingToDeleting=7
+    rclone backend lifecycle b2:bucket -o prefix=logs/ -o clear
+    rclone backend lifecycle b2:bucket -o clear
+
+With no options it shows the current rules. Otherwise it replaces the
+rule for the given prefix (the whole bucket if no prefix is given) or
+removes it with "-o clear". "-o clear" with no prefix removes all the
+rules.
+
+Setting daysFromHidingToDeleting=1 on its own means old versions of
+files are deleted one day after they are overwritten or deleted.
+
+It returns the rules of the bucket after any changes.
+
+    [
+        {
+            "daysFromHidingToDeleting": 1,
+            "fileNamePrefix": ""
+        }
+    ]
+
+See: https://www.backblaze.com/b2/docs/lifecycle_rules.html
+`,
+	Opts: map[string]string{
+		"prefix":                    "The file name prefix the rule applies to",
+		"daysFromHidingToDeleting":  "Delete hidden files after this many days",
+		"daysFromUploadingToHiding": "Hide files after this many days",
+		"clear":                     "Remove the rule for prefix, or all rules",
+	},
+}, {
+	Name:  "legal-hold",
+	Short: "Set or remove the legal hold on files",
+	Long: `This command puts a legal hold on files or removes it.
+
+Usage Examples:
+
+    rclone backend legal-hold b2:bucket/path/to/file -o on
+    rclone backend legal-hold b2:bucket/path file1 file2 -o off
+
+If no arguments are given it acts on the file the remote points to,
+otherwise on the paths given relative to the remote.
+
+A file with a legal hold can't be deleted until it is removed. This
+needs a bucket with File Lock enabled.
+
+It returns a dictionary of paths with the new legal hold.
+`,
+	Opts: map[string]string{
+		"on":  "Put a legal hold on the files",
+		"off": "Remove the legal hold from the files",
+	},
+}, {
+	Name:  "retention",
+	Short: "Set or remove the retention of files",
+	Long: `This command sets or removes the File Lock retention of files.
+
+Usage Examples:
+
+    rclone backend retention b2:bucket/path/to/file -o mode=governance -o period=30d
+    rclone backend retention b2:bucket/path file1 file2 -o mode=compliance -o until=2030-01-01T00:00:00Z
+    rclone backend retention b2:bucket/path/to/file -o clear -o bypass
+
+If no arguments are given it acts on the file the remote points to,
+otherwise on the paths given relative to the remote.
+
+The file can't be deleted until the retention has passed. A retention
+in "governance" mode can be shortened or removed with "-o bypass" by a
+key with the bypassGovernance capability but one in "compliance" mode
+can't be changed except to make it longer. This needs a bucket with
+File Lock enabled.
+
+It returns a dictionary of paths with the new retention.
+`,
+	Opts: map[string]string{
+		"mode":   "The retention mode: governance or compliance",
+		"until":  "Retain the files until this RFC 3339 time",
+		"period": "Retain the files for this long from now",
+		"clear":  "Remove the retention",
+		"bypass": "Bypass a governance mode retention",
+	},
+}}
+
+// Command the backend to run a named command
+//
+// The command run is name
+// args may be used to read arguments from
+// opts may be used to read optional arguments from
+//
+// The result should be capable of being JSON encoded
+// If it is a string or a []string it will be shown to the user
+// otherwise it will be JSON encoded and shown to the user like that
+func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[string]string) (out interface{}, err error) {
+	switch name {
+	case "lifecycle":
+		return f.lifecycleCommand(ctx, opt)
+	case "legal-hold":
+		return f.legalHoldCommand(ctx, arg, opt)
+	case "retention":
+		return f.retentionCommand(ctx, arg, opt)
+	default:
+		return nil, fs.ErrorCommandNotFound
+	}
+}
+
+// parseLifecycleRule parses the options passed to the lifecycle
+// backend command returning the rule to set or clear
+func parseLifecycleRule(opt map[string]string) (rule api.LifecycleRule, clear bool, err error) {
+	for key, value := range opt {
+		var days *int
+		switch key {
+		case "prefix":
+			rule.FileNamePrefix = value
+			continue
+		case "clear":
+			clear = true
+			continue
+		case "daysFromHidingToDeleting":
+			days = new(int)
+			rule.DaysFromHidingToDeleting = days
+		case "daysFromUploadingToHiding":
+			days = new(int)
+			rule.DaysFromUploadingToHiding = days
+		default:
+			return rule, false, errors.Errorf("unknown option %q", key)
+		}
+		*days, err = strconv.Atoi(value)
+		if err != nil || *days <= 0 {
+			return rule, false, errors.Errorf("bad %s %q - need a positive number of days", key, value)
+		}
+	}
+	if clear && (rule.DaysFromHidingToDeleting != nil || rule.DaysFromUploadingToHiding != nil) {
+		return rule, false, errors.New("can't set days with clear")
+	}
+	return rule, clear, nil
+}
+
+// updateLifecycleRules returns rules with the rule for rule.FileNamePrefix
+// replaced with rule, or removed if clear is set.
+//
+// If clear is set and prefix is "" then all the rules are removed.
+func updateLifecycleRules(rules []api.LifecycleRule, rule api.LifecycleRule, clear bool, prefixSet bool) []api.LifecycleRule {
+	newRules := []api.LifecycleRule{}
+	if clear && !prefixSet {
+		return newRules
+	}
+	for _, oldRule := range rules {
+		if oldRule.FileNamePrefix != rule.FileNamePrefix {
+			newRules = append(newRules, oldRule)
+		}
+	}
+	if !clear {
+		newRules = append(newRules, rule)
+	}
+	return newRules
+}
+
+// lifecycleCommand implements the lifecycle backend command
+func (f *Fs) lifecycleCommand(ctx context.Context, opt map[string]string) (out []api.LifecycleRule, err error) {
+	if f.rootBucket == "" {
+		return nil, errors.New("need a bucket")
+	}
+	rule, clear, err := parseLifecycleRule(opt)
+	if err != nil {
+		return nil, err
+	}
+	bucketName := f.opt.Enc.FromStandardName(f.rootBucket)
+	var (
+		account = api.ListBucketsRequest{
+			AccountID:  f.info.AccountID,
+			BucketName: bucketName,
+		}
+		response api.ListBucketsResponse
+	)
+	opts := rest.Opts{
+		Method: "POST",
+		Path:   "/b2_list_buckets",
+	}
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err := f.srv.CallJSON(ctx, &opts, &account, &response)
+		return f.shouldRetry(ctx, resp, err)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read bucket")
+	}
+	if len(response.Buckets) != 1 || response.Buckets[0].Name != bucketName {
+		return nil, fs.ErrorDirNotFound
+	}
+	bucket := response.Buckets[0]
+	if bucket.LifecycleRules == nil {
+		bucket.LifecycleRules = []api.LifecycleRule{}
+	}
+	if len(opt) == 0 {
+		return bucket.LifecycleRules, nil
+	}
+	_, prefixSet := opt["prefix"]
+	var request = api.UpdateBucketRequest{
+		ID:             bucket.ID,
+		AccountID:      f.info.AccountID,
+		LifecycleRules: updateLifecycleRules(bucket.LifecycleRules, rule, clear, prefixSet),
+	}
+	if operations.SkipDestructive(ctx, f.rootBucket, "update lifecycle rules") {
+		return request.LifecycleRules, nil
+	}
+	opts = rest.Opts{
+		Method: "POST",
+		Path:   "/b2_update_bucket",
+	}
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err := f.srv.CallJSON(ctx, &opts, &request, &bucket)
+		return f.shouldRetry(ctx, resp, err)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update lifecycle rules")
+	}
+	if bucket.LifecycleRules == nil {
+		bucket.LifecycleRules = []api.LifecycleRule{}
+	}
+	return bucket.LifecycleRules, nil
+}
+
+// lockObjects returns the objects to act on for the legal-hold and
+// retention backend commands
+func (f *Fs) lockObjects(ctx context.Context, args []string) (objects []*Object, err error) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	for _, remote := range args {
+		obj, err := f.NewObject(ctx, remote)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't find %q", remote)
+		}
+		objects = append(objects, obj.(*Object))
+	}
+	return objects, nil
+}
+
+// legalHoldCommand implements the legal-hold backend command
+func (f *Fs) legalHoldCommand(ctx context.Context, args []string, opt map[string]string) (out map[string]string, err error) {
+	_, on := opt["on"]
+	_, off := opt["off"]
+	if on == off {
+		return nil, errors.New("need exactly one of -o on or -o off")
+	}
+	var request = api.UpdateFileLegalHoldRequest{LegalHold: "off"}
+	if on {
+		request.LegalHold = "on"
+	}
+	objects, err := f.lockObjects(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	out = make(map[string]string, len(objects))
+	opts := rest.Opts{
+		Method: "POST",
+		Path:   "/b2_update_file_legal_hold",
+	}
+	for _, o := range objects {
+		if operations.SkipDestructive(ctx, o, "set legal hold "+request.LegalHold) {
+			continue
+		}
+		_, bucketPath := o.split()
+		request.ID = o.id
+		request.Name = f.opt.Enc.FromStandardPath(bucketPath)
+		var response api.UpdateFileLegalHoldResponse
+		err = f.pacer.Call(func() (bool, error) {
+			resp, err := f.srv.CallJSON(ctx, &opts, &request, &response)
+			return f.shouldRetry(ctx, resp, err)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to set legal hold on %q", o.remote)
+		}
+		out[o.remote] = response.LegalHold
+	}
+	return out, nil
+}
+
+// parseRetention parses the options passed to the retention backend
+// command returning the retention to set
+func parseRetention(opt map[string]string, now time.Time) (retention api.FileRetention, bypass bool, err error) {
+	var (
+		mode  string
+		until time.Time
+		clear bool
+	)
+	for key, value := range opt {
+		switch key {
+		case "mode":
+			mode = value
+		case "until":
+			until, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				return retention, false, errors.Wrap(err, "bad until")
+			}
+		case "period":
+			period, err := fs.ParseDuration(value)
+			if err != nil {
+				return retention, false, errors.Wrap(err, "bad period")
+			}
+			until = now.Add(period)
+		case "clear":
+			clear = true
+		case "bypass":
+			bypass = true
+		default:
+			return retention, false, errors.Errorf("unknown option %q", key)
+		}
+	}
+	if _, ok := opt["until"]; ok {
+		if _, ok := opt["period"]; ok {
+			return retention, false, errors.New("can't use both until and period")
+		}
+	}
+	if clear {
+		if mode != "" || !until.IsZero() {
+			return retention, false, errors.New("can't set mode, until or period with clear")
+		}
+		return retention, bypass, nil
+	}
+	if mode == "" {
+		return retention, false, errors.New("need mode or clear")
+	}
+	if until.IsZero() {
+		return retention, false, errors.New("need until or period")
+	}
+	err = checkRetention(mode, fs.Duration(until.Sub(now)))
+	if err != nil {
+		return retention, false, err
+	}
+	timestamp := api.Timestamp(until)
+	retention.Mode = &mode
+	retention.RetainUntilTimestamp = &timestamp
+	return retention, bypass, nil
+}
+
+// retentionCommand implements the retention backend command
+func (f *Fs) retentionCommand(ctx context.Context, args []string, opt map[string]string) (out map[string]api.FileRetention, err error) {
+	retention, bypass, err := parseRetention(opt, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	objects, err := f.lockObjects(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	out = make(map[string]api.FileRetention, len(objects))
+	opts := rest.Opts{
+		Method: "POST",
+		Path:   "/b2_update_file_retention",
+	}
+	for _, o := range objects {
+		if operations.SkipDestructive(ctx, o, "set retention") {
+			continue
+		}
+		_, bucketPath := o.split()
+		var request = api.UpdateFileRetentionRequest{
+			ID:               o.id,
+			Name:             f.opt.Enc.FromStandardPath(bucketPath),
+			FileRetention:    retention,
+			BypassGovernance: bypass,
+		}
+		var response api.UpdateFileRetentionResponse
+		err = f.pacer.Call(func() (bool, error) {
+			resp, err := f.srv.CallJSON(ctx, &opts, &request, &response)
+			return f.shouldRetry(ctx, resp, err)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to set retention on %q", o.remote)
+		}
+		out[o.remote] = response.FileRetention
+	}
+	return out, nil
+}
+
 // Check the interfaces are satisfied
 var (
 	_ fs.Fs           = &Fs{}
+	_ fs.Commander    = &Fs{}
 	_ fs.Purger       = &Fs{}
 	_ fs.Copier       = &Fs{}
 	_ fs.PutStreamer  = &Fs{}
@@ -1,10 +1,16 @@
 package b2
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/artpar/rclone/backend/b2/api"
+	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fstest"
+	"github.com/artpar/rclone/lib/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test b2 string encoding
@@ -168,3 +174,140 @@ func TestParseTimeString(t *testing.T) {
 	}
 
 }
+
+func TestCheckRetention(t *testing.T) {
+	for _, test := range []struct {
+		mode    string
+		period  fs.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"governance", fs.Duration(time.Hour), false},
+		{"compliance", fs.Duration(time.Hour), false},
+		{"governance", 0, true},
+		{"potato", fs.Duration(time.Hour), true},
+	} {
+		err := checkRetention(test.mode, test.period)
+		assert.Equal(t, test.wantErr, err != nil, test.mode)
+	}
+}
+
+func TestParseLifecycleRule(t *testing.T) {
+	one, seven := 1, 7
+	for _, test := range []struct {
+		opt       map[string]string
+		want      api.LifecycleRule
+		wantClear bool
+		wantErr   string
+	}{
+		{map[string]string{}, api.LifecycleRule{}, false, ""},
+		{map[string]string{"daysFromHidingToDeleting": "1"}, api.LifecycleRule{DaysFromHidingToDeleting: &one}, false, ""},
+		{map[string]string{"prefix": "logs/", "daysFromUploadingToHiding": "7"}, api.LifecycleRule{DaysFromUploadingToHiding: &seven, FileNamePrefix: "logs/"}, false, ""},
+		{map[string]string{"prefix": "logs/", "clear": ""}, api.LifecycleRule{FileNamePrefix: "logs/"}, true, ""},
+		{map[string]string{"daysFromHidingToDeleting": "0"}, api.LifecycleRule{}, false, `bad daysFromHidingToDeleting "0" - need a positive number of days`},
+		{map[string]string{"daysFromHidingToDeleting": "potato"}, api.LifecycleRule{}, false, `bad daysFromHidingToDeleting "potato" - need a positive number of days`},
+		{map[string]string{"daysFromHidingToDeleting": "1", "clear": ""}, api.LifecycleRule{}, false, "can't set days with clear"},
+		{map[string]string{"potato": ""}, api.LifecycleRule{}, false, `unknown option "potato"`},
+	} {
+		got, gotClear, err := parseLifecycleRule(test.opt)
+		if test.wantErr != "" {
+			assert.EqualError(t, err, test.wantErr, test.opt)
+			continue
+		}
+		require.NoError(t, err, test.opt)
+		assert.Equal(t, test.want, got, test.opt)
+		assert.Equal(t, test.wantClear, gotClear, test.opt)
+	}
+}
+
+func TestUpdateLifecycleRules(t *testing.T) {
+	one, seven := 1, 7
+	rules := []api.LifecycleRule{
+		{DaysFromHidingToDeleting: &one},
+		{DaysFromUploadingToHiding: &seven, FileNamePrefix: "logs/"},
+	}
+	newRule := api.LifecycleRule{DaysFromHidingToDeleting: &seven}
+
+	// Replace the rule for the whole bucket
+	got := updateLifecycleRules(rules, newRule, false, false)
+	assert.Equal(t, []api.LifecycleRule{rules[1], newRule}, got)
+
+	// Add a new prefix
+	newRule.FileNamePrefix = "tmp/"
+	got = updateLifecycleRules(rules, newRule, false, true)
+	assert.Equal(t, []api.LifecycleRule{rules[0], rules[1], newRule}, got)
+
+	// Clear one prefix
+	got = updateLifecycleRules(rules, api.LifecycleRule{FileNamePrefix: "logs/"}, true, true)
+	assert.Equal(t, []api.LifecycleRule{rules[0]}, got)
+
+	// Clear everything
+	got = updateLifecycleRules(rules, api.LifecycleRule{}, true, false)
+	assert.Equal(t, []api.LifecycleRule{}, got)
+}
+
+func TestParseRetention(t *testing.T) {
+	now := fstest.Time("2021-01-01T00:00:00Z")
+	for _, test := range []struct {
+		opt        map[string]string
+		wantMode   string
+		wantUntil  time.Time
+		wantClear  bool
+		wantBypass bool
+		wantErr    string
+	}{
+		{opt: map[string]string{"mode": "governance", "period": "1d"}, wantMode: "governance", wantUntil: fstest.Time("2021-01-02T00:00:00Z")},
+		{opt: map[string]string{"mode": "compliance", "until": "2030-01-01T00:00:00Z"}, wantMode: "compliance", wantUntil: fstest.Time("2030-01-01T00:00:00Z")},
+		{opt: map[string]string{"clear": "", "bypass": ""}, wantClear: true, wantBypass: true},
+		{opt: map[string]string{"clear": "", "mode": "governance"}, wantErr: "can't set mode, until or period with clear"},
+		{opt: map[string]string{"mode": "governance"}, wantErr: "need until or period"},
+		{opt: map[string]string{"period": "1d"}, wantErr: "need mode or clear"},
+		{opt: map[string]string{"mode": "governance", "until": "2020-01-01T00:00:00Z"}, wantErr: "need a period"},
+		{opt: map[string]string{"mode": "potato", "period": "1d"}, wantErr: `unknown mode "potato" - need governance or compliance`},
+		{opt: map[string]string{"mode": "governance", "period": "1d", "until": "2030-01-01T00:00:00Z"}, wantErr: "can't use both until and period"},
+		{opt: map[string]string{"potato": ""}, wantErr: `unknown option "potato"`},
+	} {
+		got, gotBypass, err := parseRetention(test.opt, now)
+		if test.wantErr != "" {
+			assert.EqualError(t, err, test.wantErr, test.opt)
+			continue
+		}
+		require.NoError(t, err, test.opt)
+		assert.Equal(t, test.wantBypass, gotBypass, test.opt)
+		if test.wantClear {
+			assert.Nil(t, got.Mode, test.opt)
+			assert.Nil(t, got.RetainUntilTimestamp, test.opt)
+			continue
+		}
+		require.NotNil(t, got.Mode, test.opt)
+		require.NotNil(t, got.RetainUntilTimestamp, test.opt)
+		assert.Equal(t, test.wantMode, *got.Mode, test.opt)
+		assert.True(t, test.wantUntil.Equal(time.Time(*got.RetainUntilTimestamp)), test.opt)
+	}
+}
+
+func TestFileRetentionJSON(t *testing.T) {
+	// Clearing the retention must send explicit nulls
+	data, err := json.Marshal(api.UpdateFileRetentionRequest{ID: "id", Name: "name"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"fileId":"id","fileName":"name","fileRetention":{"mode":null,"retainUntilTimestamp":null}}`, string(data))
+
+	// Removing all the rules must send an empty list
+	rules := updateLifecycleRules(nil, api.LifecycleRule{}, true, false)
+	data, err = json.Marshal(api.UpdateBucketRequest{ID: "id", AccountID: "account", LifecycleRules: rules})
+	require.NoError(t, err)
+	assert.Equal(t, `{"bucketId":"id","accountId":"account","lifecycleRules":[]}`, string(data))
+}
+
+func TestLinkDuration(t *testing.T) {
+	f := &Fs{opt: Options{DownloadAuthorizationDuration: fs.Duration(time.Hour)}}
+	assert.Equal(t, fs.Duration(time.Minute), f.linkDuration(fs.Duration(time.Minute)))
+	assert.Equal(t, maxDownloadAuth, f.linkDuration(maxDownloadAuth))
+	assert.Equal(t, fs.Duration(time.Hour), f.linkDuration(maxDownloadAuth+1))
+	assert.Equal(t, fs.Duration(time.Hour), f.linkDuration(0))
+}
+
+func TestFriendlyPath(t *testing.T) {
+	f := &Fs{opt: Options{Enc: encoder.Base}}
+	assert.Equal(t, "/file/bucket/path/to/file%20name.txt", f.friendlyPath("bucket", "path/to/file name.txt"))
+}
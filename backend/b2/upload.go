@@ -120,6 +120,7 @@ func (f *Fs) newLargeUpload(ctx context.Context, o *Object, in io.Reader, src fs
 		BucketID: bucketID,
 		Name:     f.opt.Enc.FromStandardPath(bucketPath),
 	}
+	request.LegalHold, request.FileRetention = f.fileLock()
 	if newInfo == nil {
 		modTime := src.ModTime(ctx)
 		request.ContentType = fs.MimeType(ctx, src)
@@ -152,11 +152,17 @@ type CopyFile struct {
 // CreateSharedLink is the request for Public Link
 type CreateSharedLink struct {
 	SharedLink struct {
-		URL    string `json:"url,omitempty"`
-		Access string `json:"access,omitempty"`
+		URL        string `json:"url,omitempty"`
+		Access     string `json:"access,omitempty"`
+		UnsharedAt *Time  `json:"unshared_at,omitempty"` // when the link expires - paid accounts only
 	} `json:"shared_link"`
 }
 
+// RemoveSharedLink is the request to remove a Public Link
+type RemoveSharedLink struct {
+	SharedLink *struct{} `json:"shared_link"` // always null
+}
+
 // UploadSessionRequest is uses in Create Upload Session
 type UploadSessionRequest struct {
 	FolderID string `json:"folder_id,omitempty"` // don't pass for update
@@ -151,7 +151,7 @@ func init() {
 				encoder.EncodeBackSlash |
 				encoder.EncodeRightSpace |
 				encoder.EncodeInvalidUtf8),
-		}}...),
+		}, fs.ProxyOption, fs.APIBudgetOption}...),
 	})
 }
 
@@ -982,6 +982,10 @@ func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string
 }
 
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
+//
+// If expire is set the link expires then, which needs a paid account.
+//
+// If unlink is set then the shared link is removed instead.
 func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration, unlink bool) (string, error) {
 	id, err := f.dirCache.FindDir(ctx, remote, false)
 	var opts rest.Opts
@@ -1000,7 +1004,7 @@ func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration,
 			return "", err
 		}
 
-		if o.(*Object).publicLink != "" {
+		if o.(*Object).publicLink != "" && !unlink && expire >= fs.LinkExpireNever {
 			return o.(*Object).publicLink, nil
 		}
 
@@ -1011,14 +1015,30 @@ func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration,
 		}
 	}
 
-	shareLink := api.CreateSharedLink{}
+	var request interface{}
+	if unlink {
+		request = &api.RemoveSharedLink{}
+	} else {
+		shareLink := api.CreateSharedLink{}
+		if expire < fs.LinkExpireNever {
+			unsharedAt := api.Time(time.Now().Add(time.Duration(expire)))
+			shareLink.SharedLink.UnsharedAt = &unsharedAt
+		}
+		request = &shareLink
+	}
 	var info api.Item
 	var resp *http.Response
 	err = f.pacer.Call(func() (bool, error) {
-		resp, err = f.srv.CallJSON(ctx, &opts, &shareLink, &info)
+		resp, err = f.srv.CallJSON(ctx, &opts, request, &info)
 		return shouldRetry(ctx, resp, err)
 	})
-	return info.SharedLink.URL, err
+	if err != nil {
+		return "", err
+	}
+	if unlink {
+		return "", nil
+	}
+	return info.SharedLink.URL, nil
 }
 
 // deletePermanently permanently deletes a trashed file
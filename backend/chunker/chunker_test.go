@@ -39,10 +39,13 @@ func TestIntegration(t *testing.T) {
 		UnimplementableFsMethods: []string{
 			"PublicLink",
 			"OpenWriterAt",
+			"OpenChunkWriter",
 			"MergeDirs",
 			"DirCacheFlush",
 			"UserInfo",
 			"Disconnect",
+			"DirSetModTime",
+			"HealthCheck",
 		},
 	}
 	if *fstest.RemoteName == "" {
@@ -5,10 +5,13 @@ import (
 	"context"
 	"crypto/aes"
 	gocipher "crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base32"
 	"fmt"
 	"io"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
@@ -34,6 +37,7 @@ const (
 	blockDataSize       = 64 * 1024
 	blockSize           = blockHeaderSize + blockDataSize
 	encryptedSuffix     = ".bin" // when file name encryption is off we add this suffix to make sure the cloud provider doesn't process the file
+	nameMACSize         = 8      // size of the truncated MAC added to names with NameEncryptionAuthenticated
 )
 
 // Errors returned by cipher
@@ -48,6 +52,7 @@ var (
 	ErrorEncryptedBadMagic       = errors.New("not an encrypted file - bad magic string")
 	ErrorEncryptedBadBlock       = errors.New("failed to authenticate decrypted block - bad password?")
 	ErrorBadBase32Encoding       = errors.New("bad base32 filename encoding")
+	ErrorBadNameMAC              = errors.New("filename failed authentication - tampered with or bad password?")
 	ErrorFileClosed              = errors.New("file already closed")
 	ErrorNotAnEncryptedFile      = errors.New("not an encrypted file - no \"" + encryptedSuffix + "\" suffix")
 	ErrorBadSeek                 = errors.New("Seek beyond end of file")
@@ -79,6 +84,7 @@ const (
 	NameEncryptionOff NameEncryptionMode = iota
 	NameEncryptionStandard
 	NameEncryptionObfuscated
+	NameEncryptionAuthenticated
 )
 
 // NewNameEncryptionMode turns a string into a NameEncryptionMode
@@ -91,6 +97,8 @@ func NewNameEncryptionMode(s string) (mode NameEncryptionMode, err error) {
 		mode = NameEncryptionStandard
 	case "obfuscate":
 		mode = NameEncryptionObfuscated
+	case "authenticated":
+		mode = NameEncryptionAuthenticated
 	default:
 		err = errors.Errorf("Unknown file name encryption mode %q", s)
 	}
@@ -106,6 +114,8 @@ func (mode NameEncryptionMode) String() (out string) {
 		out = "standard"
 	case NameEncryptionObfuscated:
 		out = "obfuscate"
+	case NameEncryptionAuthenticated:
+		out = "authenticated"
 	default:
 		out = fmt.Sprintf("Unknown mode #%d", mode)
 	}
@@ -117,11 +127,14 @@ type Cipher struct {
 	dataKey        [32]byte                  // Key for secretbox
 	nameKey        [32]byte                  // 16,24 or 32 bytes
 	nameTweak      [nameCipherBlockSize]byte // used to tweak the name crypto
+	nameMACKey     [32]byte                  // key for the name MAC
 	block          gocipher.Block
 	mode           NameEncryptionMode
 	buffers        sync.Pool // encrypt/decrypt buffers
 	cryptoRand     io.Reader // read crypto random numbers from here
 	dirNameEncrypt bool
+	previous       []*Cipher // ciphers for previous passwords, newest first
+	nameRoot       string    // encrypted path of the root names are authenticated relative to
 }
 
 // newCipher initialises the cipher.  If salt is "" then it uses a built in salt val
@@ -167,11 +180,42 @@ func (c *Cipher) Key(password, salt string) (err error) {
 	copy(c.dataKey[:], key)
 	copy(c.nameKey[:], key[len(c.dataKey):])
 	copy(c.nameTweak[:], key[len(c.dataKey)+len(c.nameKey):])
+	// Derive the name MAC key from the name key so the scrypt
+	// output, and hence the other keys, stay the same
+	mac := hmac.New(sha256.New, c.nameKey[:])
+	_, _ = mac.Write([]byte("rclone crypt name MAC"))
+	copy(c.nameMACKey[:], mac.Sum(nil))
 	// Key the name cipher
 	c.block, err = aes.NewCipher(c.nameKey[:])
 	return err
 }
 
+// addPreviousKey adds a cipher made from an older password which is
+// used to read names and data written before the password was
+// changed.
+func (c *Cipher) addPreviousKey(password, salt string) error {
+	if c.mode == NameEncryptionObfuscated {
+		// obfuscated names decode with any key so we can't tell
+		// which key was used
+		return errors.New("previous passwords can't be used with filename_encryption obfuscate")
+	}
+	previous, err := newCipher(c.mode, password, salt, c.dirNameEncrypt)
+	if err != nil {
+		return err
+	}
+	c.previous = append(c.previous, previous)
+	return nil
+}
+
+// keyCipher returns the cipher for the key index returned by the
+// decrypter - 0 is the current key and 1.. the previous keys.
+func (c *Cipher) keyCipher(i int) *Cipher {
+	if i <= 0 || i > len(c.previous) {
+		return c
+	}
+	return c.previous[i-1]
+}
+
 // getBlock gets a block from the pool of size blockSize
 func (c *Cipher) getBlock() []byte {
 	return c.buffers.Get().([]byte)
@@ -223,17 +267,38 @@ func decodeFileName(in string) ([]byte, error) {
 // This means that
 //  * filenames with the same name will encrypt the same
 //  * filenames which start the same won't have a common prefix
-func (c *Cipher) encryptSegment(plaintext string) string {
+//
+// parent is the encrypted path of the directory the segment is in
+// which is authenticated along with the segment in
+// NameEncryptionAuthenticated mode.
+func (c *Cipher) encryptSegment(parent, plaintext string) string {
 	if plaintext == "" {
 		return ""
 	}
 	paddedPlaintext := pkcs7.Pad(nameCipherBlockSize, []byte(plaintext))
 	ciphertext := eme.Transform(c.block, c.nameTweak[:], paddedPlaintext, eme.DirectionEncrypt)
+	if c.mode == NameEncryptionAuthenticated {
+		ciphertext = append(ciphertext, c.nameMAC(parent, ciphertext)...)
+	}
 	return encodeFileName(ciphertext)
 }
 
-// decryptSegment decrypts a path segment
-func (c *Cipher) decryptSegment(ciphertext string) (string, error) {
+// nameMAC returns the truncated MAC of an encrypted path segment in
+// the directory with encrypted path parent
+func (c *Cipher) nameMAC(parent string, ciphertext []byte) []byte {
+	mac := hmac.New(sha256.New, c.nameMACKey[:])
+	if parent != "" {
+		// the NUL can't appear in a path so separates the parent
+		_, _ = mac.Write([]byte(parent))
+		_, _ = mac.Write([]byte{0})
+	}
+	_, _ = mac.Write(ciphertext)
+	return mac.Sum(nil)[:nameMACSize]
+}
+
+// decryptSegment decrypts a path segment in the directory with
+// encrypted path parent
+func (c *Cipher) decryptSegment(parent, ciphertext string) (string, error) {
 	if ciphertext == "" {
 		return "", nil
 	}
@@ -241,6 +306,17 @@ func (c *Cipher) decryptSegment(ciphertext string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if c.mode == NameEncryptionAuthenticated {
+		// Check the MAC before decrypting anything
+		if len(rawCiphertext) < nameMACSize {
+			return "", ErrorBadNameMAC
+		}
+		macOffset := len(rawCiphertext) - nameMACSize
+		if !hmac.Equal(rawCiphertext[macOffset:], c.nameMAC(parent, rawCiphertext[:macOffset])) {
+			return "", ErrorBadNameMAC
+		}
+		rawCiphertext = rawCiphertext[:macOffset]
+	}
 	if len(rawCiphertext)%nameCipherBlockSize != 0 {
 		return "", ErrorNotAMultipleOfBlocksize
 	}
@@ -442,15 +518,39 @@ func (c *Cipher) encryptFileName(in string) string {
 		if !c.dirNameEncrypt && i != (len(segments)-1) {
 			continue
 		}
-		if c.mode == NameEncryptionStandard {
-			segments[i] = c.encryptSegment(segments[i])
-		} else {
+		if c.mode == NameEncryptionObfuscated {
 			segments[i] = c.obfuscateSegment(segments[i])
+		} else {
+			segments[i] = c.encryptSegment(c.parentPath(segments[:i]), segments[i])
 		}
 	}
 	return strings.Join(segments, "/")
 }
 
+// parentPath returns the encrypted path of the directory made of the
+// encrypted segments relative to the root names are authenticated
+// relative to.
+func (c *Cipher) parentPath(segments []string) string {
+	if c.mode != NameEncryptionAuthenticated {
+		return ""
+	}
+	return path.Join(c.nameRoot, strings.Join(segments, "/"))
+}
+
+// setNameRoot sets the directory which names are encrypted relative
+// to. In NameEncryptionAuthenticated mode the MAC of each name
+// includes the encrypted path of its parent directory from the root
+// of the remote being wrapped, so this must be set when the Fs isn't
+// at that root.
+func (c *Cipher) setNameRoot(dir string) {
+	for _, cipher := range append([]*Cipher{c}, c.previous...) {
+		cipher.nameRoot = ""
+		if dir != "" && dir != "." {
+			cipher.nameRoot = cipher.EncryptDirName(dir)
+		}
+	}
+}
+
 // EncryptFileName encrypts a file path
 func (c *Cipher) EncryptFileName(in string) string {
 	if c.mode == NameEncryptionOff {
@@ -467,9 +567,10 @@ func (c *Cipher) EncryptDirName(in string) string {
 	return c.encryptFileName(in)
 }
 
-// decryptFileName decrypts a file path
-func (c *Cipher) decryptFileName(in string) (string, error) {
+// decryptPath decrypts a file path with this cipher's key only
+func (c *Cipher) decryptPath(in string) (string, error) {
 	segments := strings.Split(in, "/")
+	encrypted := append([]string(nil), segments...)
 	for i := range segments {
 		var err error
 		// Skip directory name decryption if the user chose to
@@ -477,10 +578,10 @@ func (c *Cipher) decryptFileName(in string) (string, error) {
 		if !c.dirNameEncrypt && i != (len(segments)-1) {
 			continue
 		}
-		if c.mode == NameEncryptionStandard {
-			segments[i], err = c.decryptSegment(segments[i])
-		} else {
+		if c.mode == NameEncryptionObfuscated {
 			segments[i], err = c.deobfuscateSegment(segments[i])
+		} else {
+			segments[i], err = c.decryptSegment(c.parentPath(encrypted[:i]), segments[i])
 		}
 
 		if err != nil {
@@ -490,6 +591,48 @@ func (c *Cipher) decryptFileName(in string) (string, error) {
 	return strings.Join(segments, "/"), nil
 }
 
+// plausibleName returns true if a decrypted path looks like a real
+// name.
+//
+// A name decrypted with the wrong key fails the padding check most of
+// the time, but not always, so this is used to choose between the
+// keys when more than one of them decrypts a name.
+func plausibleName(name string) bool {
+	if !utf8.ValidString(name) {
+		return false
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7F {
+			return false
+		}
+	}
+	return true
+}
+
+// decryptFileName decrypts a file path with the current key or any
+// of the previous keys.
+func (c *Cipher) decryptFileName(in string) (string, error) {
+	out, err := c.decryptPath(in)
+	if len(c.previous) == 0 || (err == nil && plausibleName(out)) {
+		return out, err
+	}
+	for _, previous := range c.previous {
+		previousOut, previousErr := previous.decryptPath(in)
+		if previousErr != nil {
+			continue
+		}
+		if plausibleName(previousOut) {
+			return previousOut, nil
+		}
+		if err != nil {
+			// keep the first which decrypts in case no name is
+			// plausible
+			out, err = previousOut, nil
+		}
+	}
+	return out, err
+}
+
 // DecryptFileName decrypts a file path
 func (c *Cipher) DecryptFileName(in string) (string, error) {
 	if c.mode == NameEncryptionOff {
@@ -510,6 +653,35 @@ func (c *Cipher) DecryptDirName(in string) (string, error) {
 	return c.decryptFileName(in)
 }
 
+// encryptNames returns the distinct results of encrypting in with
+// encrypt using the current key then each of the previous keys.
+func (c *Cipher) encryptNames(in string, encrypt func(*Cipher, string) string) []string {
+	out := []string{encrypt(c, in)}
+outer:
+	for _, previous := range c.previous {
+		encrypted := encrypt(previous, in)
+		for _, seen := range out {
+			if seen == encrypted {
+				continue outer
+			}
+		}
+		out = append(out, encrypted)
+	}
+	return out
+}
+
+// encryptFileNames returns the distinct encryptions of the file path
+// in with each key, newest first.
+func (c *Cipher) encryptFileNames(in string) []string {
+	return c.encryptNames(in, (*Cipher).EncryptFileName)
+}
+
+// encryptDirNames returns the distinct encryptions of the directory
+// path in with each key, newest first.
+func (c *Cipher) encryptDirNames(in string) []string {
+	return c.encryptNames(in, (*Cipher).EncryptDirName)
+}
+
 // NameEncryptionMode returns the encryption mode in use for names
 func (c *Cipher) NameEncryptionMode() NameEncryptionMode {
 	return c.mode
@@ -680,6 +852,9 @@ type decrypter struct {
 	nonce        nonce
 	initialNonce nonce
 	c            *Cipher
+	dataKey      *[32]byte // key in use - may change when the first block is read
+	keyIndex     int       // index of dataKey as used by Cipher.keyCipher
+	keyFound     bool      // set once a block has been decrypted
 	buf          []byte
 	readBuf      []byte
 	bufIndex     int
@@ -694,6 +869,7 @@ func (c *Cipher) newDecrypter(rc io.ReadCloser) (*decrypter, error) {
 	fh := &decrypter{
 		rc:      rc,
 		c:       c,
+		dataKey: &c.dataKey,
 		buf:     c.getBlock(),
 		readBuf: c.getBlock(),
 		limit:   -1,
@@ -779,19 +955,38 @@ func (fh *decrypter) fillBuffer() (err error) {
 		return ErrorEncryptedFileBadHeader
 	}
 	// Decrypt the block using the nonce
-	_, ok := secretbox.Open(fh.buf[:0], readBuf[:n], fh.nonce.pointer(), &fh.c.dataKey)
+	_, ok := secretbox.Open(fh.buf[:0], readBuf[:n], fh.nonce.pointer(), fh.dataKey)
+	if !ok && !fh.keyFound {
+		ok = fh.tryPreviousKeys(readBuf[:n])
+	}
 	if !ok {
 		if err != nil {
 			return err // return pending error as it is likely more accurate
 		}
 		return ErrorEncryptedBadBlock
 	}
+	fh.keyFound = true
 	fh.bufIndex = 0
 	fh.bufSize = n - blockHeaderSize
 	fh.nonce.increment()
 	return nil
 }
 
+// tryPreviousKeys tries to decrypt the first block read with each of
+// the previous keys and if one works uses it for the rest of the file
+// - call with fh.mu held
+func (fh *decrypter) tryPreviousKeys(block []byte) bool {
+	for i, previous := range fh.c.previous {
+		_, ok := secretbox.Open(fh.buf[:0], block, fh.nonce.pointer(), &previous.dataKey)
+		if ok {
+			fh.dataKey = &previous.dataKey
+			fh.keyIndex = i + 1
+			return true
+		}
+	}
+	return false
+}
+
 // Read as per io.Reader
 func (fh *decrypter) Read(p []byte) (n int, err error) {
 	fh.mu.Lock()
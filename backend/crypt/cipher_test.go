@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"path"
 	"strings"
 	"testing"
 
@@ -26,6 +27,7 @@ func TestNewNameEncryptionMode(t *testing.T) {
 		{"off", NameEncryptionOff, ""},
 		{"standard", NameEncryptionStandard, ""},
 		{"obfuscate", NameEncryptionObfuscated, ""},
+		{"authenticated", NameEncryptionAuthenticated, ""},
 		{"potato", NameEncryptionOff, "Unknown file name encryption mode \"potato\""},
 	} {
 		actual, actualErr := NewNameEncryptionMode(test.in)
@@ -42,7 +44,8 @@ func TestNewNameEncryptionModeString(t *testing.T) {
 	assert.Equal(t, NameEncryptionOff.String(), "off")
 	assert.Equal(t, NameEncryptionStandard.String(), "standard")
 	assert.Equal(t, NameEncryptionObfuscated.String(), "obfuscate")
-	assert.Equal(t, NameEncryptionMode(3).String(), "Unknown mode #3")
+	assert.Equal(t, NameEncryptionAuthenticated.String(), "authenticated")
+	assert.Equal(t, NameEncryptionMode(4).String(), "Unknown mode #4")
 }
 
 func TestEncodeFileName(t *testing.T) {
@@ -119,13 +122,13 @@ func TestEncryptSegment(t *testing.T) {
 		{"123456789012345", "eeam3li4rnommi3a762h5n7meg"},
 		{"1234567890123456", "mijbj0frqf6ms7frcr6bd9h0env53jv96pjaaoirk7forcgpt70g"},
 	} {
-		actual := c.encryptSegment(test.in)
+		actual := c.encryptSegment("", test.in)
 		assert.Equal(t, test.expected, actual, fmt.Sprintf("Testing %q", test.in))
-		recovered, err := c.decryptSegment(test.expected)
+		recovered, err := c.decryptSegment("", test.expected)
 		assert.NoError(t, err, fmt.Sprintf("Testing reverse %q", test.expected))
 		assert.Equal(t, test.in, recovered, fmt.Sprintf("Testing reverse %q", test.expected))
 		in := strings.ToUpper(test.expected)
-		recovered, err = c.decryptSegment(in)
+		recovered, err = c.decryptSegment("", in)
 		assert.NoError(t, err, fmt.Sprintf("Testing reverse %q", in))
 		assert.Equal(t, test.in, recovered, fmt.Sprintf("Testing reverse %q", in))
 	}
@@ -149,7 +152,7 @@ func TestDecryptSegment(t *testing.T) {
 		{encodeFileName([]byte("123456789abcdef")), ErrorNotAMultipleOfBlocksize},
 		{encodeFileName([]byte("123456789abcdef0")), pkcs7.ErrorPaddingTooLong},
 	} {
-		actual, actualErr := c.decryptSegment(test.in)
+		actual, actualErr := c.decryptSegment("", test.in)
 		assert.Equal(t, test.expectedErr, actualErr, fmt.Sprintf("in=%q got actual=%q, err = %v %T", test.in, actual, actualErr, actualErr))
 	}
 }
@@ -178,6 +181,12 @@ func TestEncryptFileName(t *testing.T) {
 	assert.Equal(t, "1/12/123/53.!!lipps", c.EncryptFileName("1/12/123/!hello"))
 	assert.Equal(t, "161.\u00e4", c.EncryptFileName("\u00a1"))
 	assert.Equal(t, "160.\u03c2", c.EncryptFileName("\u03a0"))
+	// Authenticated mode
+	c, _ = newCipher(NameEncryptionAuthenticated, "", "", true)
+	assert.Equal(t, "p0e52nreeaj0a5ea7s64m4j72v12rsho3jfibhg/l42g6771hnv3an9cgc8cr2n1ngbr49tbccjqa10/qgm4avr35m5loi1th53ato71v2d54h9n9pf60k8", c.EncryptFileName("1/12/123"))
+	// Authenticated mode with directory name encryption off
+	c, _ = newCipher(NameEncryptionAuthenticated, "", "", false)
+	assert.Equal(t, "1/12/qgm4avr35m5loi1th53ato71v31fa5bc1sankmg", c.EncryptFileName("1/12/123"))
 }
 
 func TestDecryptFileName(t *testing.T) {
@@ -202,6 +211,14 @@ func TestDecryptFileName(t *testing.T) {
 		{NameEncryptionObfuscated, true, "161.\u00e4", "\u00a1", nil},
 		{NameEncryptionObfuscated, true, "160.\u03c2", "\u03a0", nil},
 		{NameEncryptionObfuscated, false, "1/12/123/53.!!lipps", "1/12/123/!hello", nil},
+		{NameEncryptionAuthenticated, true, "p0e52nreeaj0a5ea7s64m4j72v12rsho3jfibhg/l42g6771hnv3an9cgc8cr2n1ngbr49tbccjqa10", "1/12", nil},
+		{NameEncryptionAuthenticated, false, "1/12/qgm4avr35m5loi1th53ato71v31fa5bc1sankmg", "1/12/123", nil},
+		{NameEncryptionAuthenticated, false, "1/13/qgm4avr35m5loi1th53ato71v31fa5bc1sankmg", "", ErrorBadNameMAC},
+		{NameEncryptionAuthenticated, true, "l42g6771hnv3an9cgc8cr2n1ngbr49tbccjqa10", "", ErrorBadNameMAC},
+		{NameEncryptionAuthenticated, true, "p0e52nreeaj0a5ea7s64m4j72v12rsho3jfiahg", "", ErrorBadNameMAC},
+		{NameEncryptionAuthenticated, true, "q0e52nreeaj0a5ea7s64m4j72v12rsho3jfibhg", "", ErrorBadNameMAC},
+		{NameEncryptionAuthenticated, true, "p0e52nreeaj0a5ea7s64m4j72s", "", ErrorBadNameMAC},
+		{NameEncryptionAuthenticated, true, "p0e52nre", "", ErrorBadNameMAC},
 	} {
 		c, _ := newCipher(test.mode, "", "", test.dirNameEncrypt)
 		actual, actualErr := c.DecryptFileName(test.in)
@@ -211,6 +228,18 @@ func TestDecryptFileName(t *testing.T) {
 	}
 }
 
+func TestNameRoot(t *testing.T) {
+	c, _ := newCipher(NameEncryptionAuthenticated, "", "", true)
+	full := c.EncryptFileName("1/12/123")
+	c.setNameRoot("1/12")
+	assert.Equal(t, path.Base(full), c.EncryptFileName("123"))
+	out, err := c.DecryptFileName(path.Base(full))
+	require.NoError(t, err)
+	assert.Equal(t, "123", out)
+	c.setNameRoot(".")
+	assert.Equal(t, full, c.EncryptFileName("1/12/123"))
+}
+
 func TestEncDecMatches(t *testing.T) {
 	for _, test := range []struct {
 		mode NameEncryptionMode
@@ -220,6 +249,7 @@ func TestEncDecMatches(t *testing.T) {
 		{NameEncryptionOff, "1/2/3/4"},
 		{NameEncryptionObfuscated, "1/2/3/4/!hello\u03a0"},
 		{NameEncryptionObfuscated, "Avatar The Last Airbender"},
+		{NameEncryptionAuthenticated, "1/2/3/4"},
 	} {
 		c, _ := newCipher(test.mode, "", "", true)
 		out, err := c.DecryptFileName(c.EncryptFileName(test.in))
@@ -1222,3 +1252,47 @@ func TestKey(t *testing.T) {
 	assert.Equal(t, [32]byte{}, c.nameKey)
 	assert.Equal(t, [16]byte{}, c.nameTweak)
 }
+
+func TestPreviousKeys(t *testing.T) {
+	old, err := newCipher(NameEncryptionStandard, "old", "", true)
+	require.NoError(t, err)
+	c, err := newCipher(NameEncryptionStandard, "new", "", true)
+	require.NoError(t, err)
+
+	// Without the previous key old names and data can't be read
+	oldName := old.EncryptFileName("dir/file.txt")
+	_, err = c.DecryptFileName(oldName)
+	assert.Error(t, err)
+
+	require.NoError(t, c.addPreviousKey("old", ""))
+	got, err := c.DecryptFileName(oldName)
+	require.NoError(t, err)
+	assert.Equal(t, "dir/file.txt", got)
+	got, err = c.DecryptFileName(c.EncryptFileName("dir/file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "dir/file.txt", got)
+	assert.Equal(t, []string{c.EncryptDirName("dir"), oldName[:strings.IndexRune(oldName, '/')]}, c.encryptDirNames("dir"))
+	assert.Equal(t, []string{c.EncryptFileName("dir/file.txt"), oldName}, c.encryptFileNames("dir/file.txt"))
+
+	// Data written with any key can be read with the key found
+	// from the first block
+	plaintext := []byte(strings.Repeat("potato", 20000))
+	for i, writer := range []*Cipher{c, old} {
+		in, err := writer.EncryptData(bytes.NewReader(plaintext))
+		require.NoError(t, err)
+		ciphertext, err := ioutil.ReadAll(in)
+		require.NoError(t, err)
+		fh, err := c.newDecrypter(ioutil.NopCloser(bytes.NewReader(ciphertext)))
+		require.NoError(t, err)
+		out, err := ioutil.ReadAll(fh)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, out)
+		assert.Equal(t, i, fh.keyIndex)
+		assert.Equal(t, writer.dataKey, c.keyCipher(fh.keyIndex).dataKey)
+	}
+
+	// Obfuscated names can't tell which key was used
+	obfuscated, err := newCipher(NameEncryptionObfuscated, "new", "", true)
+	require.NoError(t, err)
+	assert.Error(t, obfuscated.addPreviousKey("old", ""))
+}
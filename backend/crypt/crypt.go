@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -46,6 +47,9 @@ func init() {
 				}, {
 					Value: "off",
 					Help:  "Don't encrypt the file names.  Adds a \".bin\" extension only.",
+				}, {
+					Value: "authenticated",
+					Help:  "Encrypt the filenames as standard and add a MAC so tampered names are detected.",
 				},
 			},
 		}, {
@@ -73,6 +77,21 @@ NB If filename_encryption is "off" then this option will do nothing.`,
 			Name:       "password2",
 			Help:       "Password or pass phrase for salt. Optional but recommended.\nShould be different to the previous password.",
 			IsPassword: true,
+		}, {
+			Name: "previous_passwords",
+			Help: `Passwords used before the current one, newest first.
+
+When the password is changed put the old password in here, comma
+separated from any older ones.  Files and names written with any of
+these can still be read, but everything new is written with the
+current password.  Use "rclone backend rekey" to re-encrypt the old
+files with the current password.
+
+These all use the same password2 (salt) as the current password.
+
+Can't be used with filename_encryption obfuscate.`,
+			IsPassword: true,
+			Advanced:   true,
 		}, {
 			Name:    "server_side_across_configs",
 			Default: false,
@@ -144,6 +163,23 @@ func newCipherForConfig(opt *Options) (*Cipher, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to make cipher")
 	}
+	if opt.PreviousPasswords != "" {
+		revealed, err := obscure.Reveal(opt.PreviousPasswords)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt previous_passwords")
+		}
+		var previous fs.CommaSepList
+		err = previous.Set(revealed)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse previous_passwords")
+		}
+		for _, password := range previous {
+			err = cipher.addPreviousKey(password, salt)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to make cipher for previous password")
+			}
+		}
+	}
 	return cipher, nil
 }
 
@@ -183,17 +219,29 @@ func NewFs(ctx context.Context, name, rpath string, m configmap.Mapper) (fs.Fs,
 	if rpath == "" {
 		wrappedFs, err = cache.Get(ctx, remote)
 	} else {
-		remotePath := fspath.JoinRootPath(remote, cipher.EncryptFileName(rpath))
-		wrappedFs, err = cache.Get(ctx, remotePath)
+		// the file may have been written with a previous key
+		for _, encryptedPath := range cipher.encryptFileNames(rpath) {
+			remotePath := fspath.JoinRootPath(remote, encryptedPath)
+			wrappedFs, err = cache.Get(ctx, remotePath)
+			if err == fs.ErrorIsFile {
+				break
+			}
+		}
 		// if that didn't produce a file, look for a directory
 		if err != fs.ErrorIsFile {
-			remotePath = fspath.JoinRootPath(remote, cipher.EncryptDirName(rpath))
+			remotePath := fspath.JoinRootPath(remote, cipher.EncryptDirName(rpath))
 			wrappedFs, err = cache.Get(ctx, remotePath)
 		}
 	}
 	if err != fs.ErrorIsFile && err != nil {
 		return nil, errors.Wrapf(err, "failed to make remote %q to wrap", remote)
 	}
+	// Names are now relative to the root of the wrapped Fs
+	if err == fs.ErrorIsFile {
+		cipher.setNameRoot(path.Dir(rpath))
+	} else {
+		cipher.setNameRoot(rpath)
+	}
 	f := &Fs{
 		Fs:     wrappedFs,
 		name:   name,
@@ -215,6 +263,11 @@ func NewFs(ctx context.Context, name, rpath string, m configmap.Mapper) (fs.Fs,
 		GetTier:                 true,
 		ServerSideAcrossConfigs: opt.ServerSideAcrossConfigs,
 	}).Fill(ctx, f).Mask(ctx, wrappedFs).WrapsFs(f, wrappedFs)
+	if cipher.NameEncryptionMode() == NameEncryptionAuthenticated {
+		// The names in a directory are authenticated with the path
+		// of the directory so they must be moved one by one
+		f.features.DirMove = nil
+	}
 
 	return f, err
 }
@@ -227,6 +280,7 @@ type Options struct {
 	NoDataEncryption        bool   `config:"no_data_encryption"`
 	Password                string `config:"password"`
 	Password2               string `config:"password2"`
+	PreviousPasswords       string `config:"previous_passwords"`
 	ServerSideAcrossConfigs bool   `config:"server_side_across_configs"`
 	ShowMapping             bool   `config:"show_mapping"`
 }
@@ -266,7 +320,10 @@ func (f *Fs) String() string {
 func (f *Fs) add(entries *fs.DirEntries, obj fs.Object) {
 	remote := obj.Remote()
 	decryptedRemote, err := f.cipher.DecryptFileName(remote)
-	if err != nil {
+	if err == ErrorBadNameMAC {
+		fs.Errorf(remote, "Skipping file name which failed authentication: %v", err)
+		return
+	} else if err != nil {
 		fs.Debugf(remote, "Skipping undecryptable file name: %v", err)
 		return
 	}
@@ -280,7 +337,10 @@ func (f *Fs) add(entries *fs.DirEntries, obj fs.Object) {
 func (f *Fs) addDir(ctx context.Context, entries *fs.DirEntries, dir fs.Directory) {
 	remote := dir.Remote()
 	decryptedRemote, err := f.cipher.DecryptDirName(remote)
-	if err != nil {
+	if err == ErrorBadNameMAC {
+		fs.Errorf(remote, "Skipping dir name which failed authentication: %v", err)
+		return
+	} else if err != nil {
 		fs.Debugf(remote, "Skipping undecryptable dir name: %v", err)
 		return
 	}
@@ -316,6 +376,9 @@ func (f *Fs) encryptEntries(ctx context.Context, entries fs.DirEntries) (newEntr
 // This should return ErrDirNotFound if the directory isn't
 // found.
 func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	if len(f.cipher.previous) > 0 {
+		return f.listMerged(ctx, f.cipher.encryptDirNames(dir))
+	}
 	entries, err = f.Fs.List(ctx, f.cipher.EncryptDirName(dir))
 	if err != nil {
 		return nil, err
@@ -323,6 +386,50 @@ func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err e
 	return f.encryptEntries(ctx, entries)
 }
 
+// listMerged lists the directory as encrypted with each of the keys
+// in encryptedDirs, newest first, returning the combined entries.
+//
+// An entry can be found under more than one key, either in different
+// directories or in the same one, so only the first one of each name
+// is returned.
+func (f *Fs) listMerged(ctx context.Context, encryptedDirs []string) (entries fs.DirEntries, err error) {
+	seen := make(map[string]struct{})
+	found := false
+	for _, encryptedDir := range encryptedDirs {
+		dirEntries, err := f.Fs.List(ctx, encryptedDir)
+		if err == fs.ErrorDirNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		found = true
+		dirEntries, err = f.encryptEntries(ctx, dirEntries)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, dedupeEntries(seen, dirEntries)...)
+	}
+	if !found {
+		return nil, fs.ErrorDirNotFound
+	}
+	return entries, nil
+}
+
+// dedupeEntries removes the entries whose remote is in seen from
+// entries and adds the remainder to seen.
+func dedupeEntries(seen map[string]struct{}, entries fs.DirEntries) fs.DirEntries {
+	newEntries := entries[:0] // in place filter
+	for _, entry := range entries {
+		remote := entry.Remote()
+		if _, ok := seen[remote]; ok {
+			continue
+		}
+		seen[remote] = struct{}{}
+		newEntries = append(newEntries, entry)
+	}
+	return newEntries
+}
+
 // ListR lists the objects and directories of the Fs starting
 // from dir recursively into out.
 //
@@ -340,18 +447,47 @@ func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err e
 // Don't implement this unless you have a more efficient way
 // of listing recursively that doing a directory traversal.
 func (f *Fs) ListR(ctx context.Context, dir string, callback fs.ListRCallback) (err error) {
-	return f.Fs.Features().ListR(ctx, f.cipher.EncryptDirName(dir), func(entries fs.DirEntries) error {
-		newEntries, err := f.encryptEntries(ctx, entries)
-		if err != nil {
+	var (
+		found = false
+		seen  map[string]struct{}
+	)
+	if len(f.cipher.previous) > 0 {
+		// entries may be found under more than one key
+		seen = make(map[string]struct{})
+	}
+	for _, encryptedDir := range f.cipher.encryptDirNames(dir) {
+		err = f.Fs.Features().ListR(ctx, encryptedDir, func(entries fs.DirEntries) error {
+			newEntries, err := f.encryptEntries(ctx, entries)
+			if err != nil {
+				return err
+			}
+			if seen != nil {
+				newEntries = dedupeEntries(seen, newEntries)
+			}
+			return callback(newEntries)
+		})
+		if err == fs.ErrorDirNotFound {
+			continue
+		} else if err != nil {
 			return err
 		}
-		return callback(newEntries)
-	})
+		found = true
+	}
+	if !found {
+		return fs.ErrorDirNotFound
+	}
+	return nil
 }
 
 // NewObject finds the Object at remote.
-func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
-	o, err := f.Fs.NewObject(ctx, f.cipher.EncryptFileName(remote))
+func (f *Fs) NewObject(ctx context.Context, remote string) (o fs.Object, err error) {
+	// look for the object as written with each key, newest first
+	for _, encryptedRemote := range f.cipher.encryptFileNames(remote) {
+		o, err = f.Fs.NewObject(ctx, encryptedRemote)
+		if err != fs.ErrorObjectNotFound {
+			break
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -426,12 +562,39 @@ func (f *Fs) put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options [
 // will return the object and the error, otherwise will return
 // nil and the error
 func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
-	return f.put(ctx, in, src, options, f.Fs.Put)
+	o, err := f.put(ctx, in, src, options, f.Fs.Put)
+	if err != nil {
+		return nil, err
+	}
+	return o, f.removePrevious(ctx, src.Remote())
 }
 
 // PutStream uploads to the remote path with the modTime given of indeterminate size
 func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
-	return f.put(ctx, in, src, options, f.Fs.Features().PutStream)
+	o, err := f.put(ctx, in, src, options, f.Fs.Features().PutStream)
+	if err != nil {
+		return nil, err
+	}
+	return o, f.removePrevious(ctx, src.Remote())
+}
+
+// removePrevious removes any copies of remote with names encrypted
+// with the previous keys. These would otherwise be hidden behind the
+// copy just written with the current key.
+func (f *Fs) removePrevious(ctx context.Context, remote string) error {
+	for _, encryptedRemote := range f.cipher.encryptFileNames(remote)[1:] {
+		o, err := f.Fs.NewObject(ctx, encryptedRemote)
+		if err == fs.ErrorObjectNotFound {
+			continue
+		} else if err != nil {
+			return errors.Wrap(err, "failed to look for copy written with previous key")
+		}
+		err = o.Remove(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to remove copy written with previous key")
+		}
+	}
+	return nil
 }
 
 // Hashes returns the supported hash sets.
@@ -450,7 +613,35 @@ func (f *Fs) Mkdir(ctx context.Context, dir string) error {
 //
 // Return an error if it doesn't exist or isn't empty
 func (f *Fs) Rmdir(ctx context.Context, dir string) error {
-	return f.Fs.Rmdir(ctx, f.cipher.EncryptDirName(dir))
+	encryptedDirs := f.cipher.encryptDirNames(dir)
+	if len(encryptedDirs) == 1 {
+		return f.Fs.Rmdir(ctx, encryptedDirs[0])
+	}
+	// The directory may have been written with any of the keys so
+	// check they are all empty before removing any of them
+	var existing []string
+	for _, encryptedDir := range encryptedDirs {
+		entries, err := f.Fs.List(ctx, encryptedDir)
+		if err == fs.ErrorDirNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if len(entries) != 0 {
+			return fs.ErrorDirectoryNotEmpty
+		}
+		existing = append(existing, encryptedDir)
+	}
+	if len(existing) == 0 {
+		return fs.ErrorDirNotFound
+	}
+	for _, encryptedDir := range existing {
+		err := f.Fs.Rmdir(ctx, encryptedDir)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Purge all files in the directory specified
@@ -464,7 +655,15 @@ func (f *Fs) Purge(ctx context.Context, dir string) error {
 	if do == nil {
 		return fs.ErrorCantPurge
 	}
-	return do(ctx, f.cipher.EncryptDirName(dir))
+	encryptedDirs := f.cipher.encryptDirNames(dir)
+	err := do(ctx, encryptedDirs[0])
+	// purge the directory as written with any previous keys too
+	for _, encryptedDir := range encryptedDirs[1:] {
+		if do(ctx, encryptedDir) == nil {
+			err = nil
+		}
+	}
+	return err
 }
 
 // Copy src to this remote using server-side copy operations.
@@ -608,7 +807,7 @@ func (f *Fs) DecryptFileName(encryptedFileName string) (string, error) {
 // src with it, and calculates the hash given by HashType on the fly
 //
 // Note that we break lots of encapsulation in this function.
-func (f *Fs) computeHashWithNonce(ctx context.Context, nonce nonce, src fs.Object, hashType hash.Type) (hashStr string, err error) {
+func (f *Fs) computeHashWithNonce(ctx context.Context, c *Cipher, nonce nonce, src fs.Object, hashType hash.Type) (hashStr string, err error) {
 	// Open the src for input
 	in, err := src.Open(ctx)
 	if err != nil {
@@ -617,7 +816,7 @@ func (f *Fs) computeHashWithNonce(ctx context.Context, nonce nonce, src fs.Objec
 	defer fs.CheckClose(in, &err)
 
 	// Now encrypt the src with the nonce
-	out, err := f.cipher.newEncrypter(in, &nonce)
+	out, err := c.newEncrypter(in, &nonce)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to make encrypter")
 	}
@@ -675,7 +874,17 @@ func (f *Fs) ComputeHash(ctx context.Context, o *Object, src fs.Object, hashType
 		return "", errors.Wrap(err, "failed to close nonce read")
 	}
 
-	return f.computeHashWithNonce(ctx, nonce, src, hashType)
+	// Find which key the object was written with
+	c := f.cipher
+	if len(f.cipher.previous) > 0 {
+		keyIndex, err := o.dataKeyIndex(ctx)
+		if err != nil {
+			return "", err
+		}
+		c = f.cipher.keyCipher(keyIndex)
+	}
+
+	return f.computeHashWithNonce(ctx, c, nonce, src, hashType)
 }
 
 // MergeDirs merges the contents of all the directories passed
@@ -692,6 +901,15 @@ func (f *Fs) MergeDirs(ctx context.Context, dirs []fs.Directory) error {
 	return do(ctx, out)
 }
 
+// DirSetModTime sets the modification time of the directory dir
+func (f *Fs) DirSetModTime(ctx context.Context, dir string, modTime time.Time) error {
+	do := f.Fs.Features().DirSetModTime
+	if do == nil {
+		return errors.New("DirSetModTime not supported")
+	}
+	return do(ctx, f.cipher.EncryptDirName(dir), modTime)
+}
+
 // DirCacheFlush resets the directory cache - used in testing
 // as an optional interface
 func (f *Fs) DirCacheFlush() {
@@ -773,6 +991,36 @@ Usage Example:
     rclone rc backend/command command=decode fs=crypt: encryptedfile1 [encryptedfile2...]
 `,
 	},
+	{
+		Name:  "rekey",
+		Short: "Re-encrypt files written with a previous password",
+		Long: `This finds the files written with one of the previous_passwords
+and re-encrypts their names and data with the current password.
+
+If only the name needs changing the file is moved server-side if
+possible, otherwise the file is downloaded and uploaded again.  Once
+all the files in a directory have been done the directory names made
+with the previous passwords are removed.
+
+Use the "max" option to only rekey that many files so the work can be
+spread over several runs. It respects --dry-run.
+
+Run this on the root of the crypt remote. If directory names are
+encrypted then pointing it at a sub directory only finds that
+directory as written with the current password.
+
+It returns a count of the files checked, rekeyed and remaining.
+
+Usage Examples:
+
+    rclone backend rekey crypt:
+    rclone backend rekey crypt: -o max=1000
+    rclone rc backend/command command=rekey fs=crypt: -o max=1000
+`,
+		Opts: map[string]string{
+			"max": "Maximum number of files to rekey - default unlimited",
+		},
+	},
 }
 
 // Command the backend to run a named command
@@ -803,6 +1051,15 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 			out = append(out, encryptedFileName)
 		}
 		return out, nil
+	case "rekey":
+		max := -1
+		if s, ok := opt["max"]; ok {
+			max, err = strconv.Atoi(s)
+			if err != nil {
+				return nil, errors.Wrap(err, "bad max")
+			}
+		}
+		return f.rekey(ctx, max)
 	default:
 		return nil, fs.ErrorCommandNotFound
 	}
@@ -917,8 +1174,21 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	update := func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
 		return o.Object, o.Object.Update(ctx, in, src, options...)
 	}
-	_, err := o.f.put(ctx, in, src, options, update)
-	return err
+	if o.Object.Remote() != o.f.cipher.EncryptFileName(o.Remote()) {
+		// The name was encrypted with a previous key so write a
+		// new object with the current key - removePrevious
+		// removes this one
+		update = o.f.Fs.Put
+	}
+	newO, err := o.f.put(ctx, in, src, options, update)
+	if err != nil {
+		return err
+	}
+	if newObj, ok := newO.(*Object); ok {
+		newO = newObj.Object
+	}
+	o.Object = newO
+	return o.f.removePrevious(ctx, o.Remote())
 }
 
 // newDir returns a dir with the Name decrypted
@@ -952,6 +1222,15 @@ func (f *Fs) Disconnect(ctx context.Context) error {
 	return do(ctx)
 }
 
+// HealthCheck checks the wrapped remote can be reached
+func (f *Fs) HealthCheck(ctx context.Context) error {
+	do := f.Fs.Features().HealthCheck
+	if do == nil {
+		return fs.ErrorNotImplemented
+	}
+	return do(ctx)
+}
+
 // Shutdown the backend, closing any background tasks and any
 // cached connections.
 func (f *Fs) Shutdown(ctx context.Context) error {
@@ -1016,7 +1295,7 @@ func (o *ObjectInfo) Hash(ctx context.Context, hash hash.Type) (string, error) {
 	if srcObj.Fs().Features().IsLocal {
 		// Read the data and encrypt it to calculate the hash
 		fs.Debugf(o, "Computing %v hash of encrypted source", hash)
-		return o.f.computeHashWithNonce(ctx, o.nonce, srcObj, hash)
+		return o.f.computeHashWithNonce(ctx, o.f.cipher, o.nonce, srcObj, hash)
 	}
 	return "", nil
 }
@@ -1066,10 +1345,12 @@ var (
 	_ fs.Wrapper         = (*Fs)(nil)
 	_ fs.MergeDirser     = (*Fs)(nil)
 	_ fs.DirCacheFlusher = (*Fs)(nil)
+	_ fs.DirSetModTimer  = (*Fs)(nil)
 	_ fs.ChangeNotifier  = (*Fs)(nil)
 	_ fs.PublicLinker    = (*Fs)(nil)
 	_ fs.UserInfoer      = (*Fs)(nil)
 	_ fs.Disconnecter    = (*Fs)(nil)
+	_ fs.HealthChecker   = (*Fs)(nil)
 	_ fs.Shutdowner      = (*Fs)(nil)
 	_ fs.ObjectInfo      = (*ObjectInfo)(nil)
 	_ fs.Object          = (*Object)(nil)
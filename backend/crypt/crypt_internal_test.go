@@ -6,10 +6,17 @@ import (
 	"crypto/md5"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config/configfile"
+	"github.com/artpar/rclone/fs/config/configmap"
+	"github.com/artpar/rclone/fs/config/obscure"
 	"github.com/artpar/rclone/fs/hash"
 	"github.com/artpar/rclone/fs/object"
 	"github.com/artpar/rclone/lib/random"
@@ -135,6 +142,111 @@ func testComputeHash(t *testing.T, f *Fs) {
 	assert.Equal(t, remoteObjHash, computedHash)
 }
 
+// newTestFs makes a crypt Fs on dir with password and previous passwords
+func newTestFs(t *testing.T, dir, password, previous string) *Fs {
+	m := configmap.Simple{
+		"remote":                    dir,
+		"password":                  obscure.MustObscure(password),
+		"filename_encryption":       "standard",
+		"directory_name_encryption": "true",
+	}
+	if previous != "" {
+		m["previous_passwords"] = obscure.MustObscure(previous)
+	}
+	f, err := NewFs(context.Background(), "crypt", "", m)
+	require.NoError(t, err)
+	return f.(*Fs)
+}
+
+// readFile reads the contents of remote in f
+func readFile(t *testing.T, f fs.Fs, remote string) string {
+	o, err := f.NewObject(context.Background(), remote)
+	require.NoError(t, err)
+	in, err := o.Open(context.Background())
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	return string(data)
+}
+
+func TestRekey(t *testing.T) {
+	ctx := context.Background()
+	configfile.LoadConfig(ctx)
+	dir, err := ioutil.TempDir("", "rclone-crypt-rekey")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	oldFs := newTestFs(t, dir, "old", "")
+	uploadFile(t, oldFs, "dir/file1.txt", "one")
+	uploadFile(t, oldFs, "file2.txt", "two")
+
+	// Files written with the previous password can be read
+	f := newTestFs(t, dir, "new", "older,old")
+	assert.Equal(t, "one", readFile(t, f, "dir/file1.txt"))
+	uploadFile(t, f, "dir/file3.txt", "three")
+	entries, err := f.List(ctx, "dir")
+	require.NoError(t, err)
+	sort.Sort(entries)
+	assert.Equal(t, "[dir/file1.txt dir/file3.txt]", fmt.Sprint(entries))
+	entries, err = f.List(ctx, "")
+	require.NoError(t, err)
+	sort.Sort(entries)
+	assert.Equal(t, "[dir file2.txt]", fmt.Sprint(entries))
+
+	// A directory with files written with a previous key isn't empty
+	require.NoError(t, oldFs.Mkdir(ctx, "dir"))
+	assert.Equal(t, fs.ErrorDirectoryNotEmpty, f.Rmdir(ctx, "dir"))
+
+	// Overwriting a file written with a previous key doesn't leave
+	// the old copy behind
+	underlyingCount := func(dir string) int {
+		entries, err := f.Fs.List(ctx, dir)
+		require.NoError(t, err)
+		return len(entries)
+	}
+	uploadFile(t, oldFs, "file4.txt", "four")
+	before := underlyingCount("")
+	uploadFile(t, f, "file4.txt", "FOUR")
+	assert.Equal(t, before, underlyingCount(""))
+	assert.Equal(t, "FOUR", readFile(t, f, "file4.txt"))
+	uploadFile(t, oldFs, "file5.txt", "five")
+	o, err := f.NewObject(ctx, "file5.txt")
+	require.NoError(t, err)
+	before = underlyingCount("")
+	require.NoError(t, o.Update(ctx, strings.NewReader("FIVE"), object.NewStaticObjectInfo("file5.txt", time.Now(), 4, true, nil, nil)))
+	assert.Equal(t, before, underlyingCount(""))
+	assert.Equal(t, "FIVE", readFile(t, f, "file5.txt"))
+	require.NoError(t, o.Remove(ctx))
+	o, err = f.NewObject(ctx, "file4.txt")
+	require.NoError(t, err)
+	require.NoError(t, o.Remove(ctx))
+
+	// Rekey in two steps
+	out, err := f.rekey(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"checked": 3, "rekeyed": 1, "remaining": 1}, out)
+	out, err = f.rekey(ctx, -1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"checked": 3, "rekeyed": 1, "remaining": 0}, out)
+
+	// Now everything can be read without the previous password
+	f = newTestFs(t, dir, "new", "")
+	assert.Equal(t, "one", readFile(t, f, "dir/file1.txt"))
+	assert.Equal(t, "two", readFile(t, f, "file2.txt"))
+	assert.Equal(t, "three", readFile(t, f, "dir/file3.txt"))
+	entries, err = oldFs.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	// the old directory has gone
+	underlying, err := f.Fs.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(underlying))
+}
+
 // InternalTest is called by fstests.Run to extra tests
 func (f *Fs) InternalTest(t *testing.T) {
 	t.Run("ObjectInfo", func(t *testing.T) { testObjectInfo(t, f, false) })
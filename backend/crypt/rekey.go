@@ -0,0 +1,181 @@
+package crypt
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/object"
+	"github.com/artpar/rclone/fs/walk"
+	"github.com/pkg/errors"
+)
+
+// rekeyTempSuffix is added to the name of a file while its data is
+// rekeyed when its encrypted name doesn't change
+const rekeyTempSuffix = ".rclone-rekey"
+
+// dataKeyIndex returns the index of the key the data of the object was
+// written with as used by Cipher.keyCipher.
+func (o *Object) dataKeyIndex(ctx context.Context) (keyIndex int, err error) {
+	if o.Object.Size() <= int64(fileHeaderSize) {
+		// no data blocks so any key will do
+		return 0, nil
+	}
+	// Reading the first byte decrypts the first block which finds the key
+	in, err := o.Open(ctx, &fs.RangeOption{Start: 0, End: 0})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to open object to find key")
+	}
+	defer fs.CheckClose(in, &err)
+	_, err = io.ReadFull(in, make([]byte, 1))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read object to find key")
+	}
+	fh, ok := in.(*decrypter)
+	if !ok {
+		return 0, errors.Errorf("unexpected reader type %T", in)
+	}
+	return fh.keyIndex, nil
+}
+
+// rekeyObject re-encrypts o with the current key so it is stored as
+// newName. If dataOK is set then only the name needs changing.
+func (f *Fs) rekeyObject(ctx context.Context, o *Object, newName string, dataOK bool) (err error) {
+	move := f.Fs.Features().Move
+	if dataOK && move != nil {
+		_, err = move(ctx, o.Object, newName)
+		if err != fs.ErrorCantMove {
+			return err
+		}
+	}
+	inPlace := newName == o.Object.Remote()
+	if inPlace && move == nil {
+		return errors.New("can't rekey data without changing the name unless the remote supports server-side move")
+	}
+	in, err := o.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open for rekey")
+	}
+	defer fs.CheckClose(in, &err)
+	remote := o.Remote()
+	if inPlace {
+		remote += rekeyTempSuffix
+	}
+	src := object.NewStaticObjectInfo(remote, o.ModTime(ctx), o.Size(), true, nil, f)
+	newObj, err := f.put(ctx, in, src, nil, f.Fs.Put)
+	if err != nil {
+		return errors.Wrap(err, "failed to upload rekeyed file")
+	}
+	// The new copy is safely stored so remove the old one
+	err = o.Object.Remove(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove file after rekey")
+	}
+	if inPlace {
+		_, err = move(ctx, newObj.(*Object).Object, newName)
+		if err != nil {
+			return errors.Wrapf(err, "failed to rename rekeyed file - it is stored as %q", remote)
+		}
+	}
+	return nil
+}
+
+// rekey re-encrypts all the files written with a previous key with
+// the current key stopping after max files if max >= 0.
+//
+// It then removes the directories named with the previous keys if
+// they are empty.
+func (f *Fs) rekey(ctx context.Context, max int) (out map[string]int, err error) {
+	if len(f.cipher.previous) == 0 {
+		return nil, errors.New("no previous_passwords are set so there is nothing to rekey")
+	}
+	var (
+		ci      = fs.GetConfig(ctx)
+		objects []*Object
+		dirs    []string
+	)
+	err = walk.ListR(ctx, f, "", true, -1, walk.ListAll, func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			switch x := entry.(type) {
+			case *Object:
+				objects = append(objects, x)
+			case fs.Directory:
+				dirs = append(dirs, x.Remote())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list for rekey")
+	}
+	out = map[string]int{
+		"checked":   0,
+		"rekeyed":   0,
+		"remaining": 0,
+	}
+	errorCount := 0
+	for _, o := range objects {
+		out["checked"]++
+		newName := f.cipher.EncryptFileName(o.Remote())
+		dataOK := true
+		if !f.opt.NoDataEncryption {
+			keyIndex, err := o.dataKeyIndex(ctx)
+			if err != nil {
+				fs.Errorf(o, "Failed to rekey: %v", err)
+				errorCount++
+				continue
+			}
+			dataOK = keyIndex == 0
+		}
+		if dataOK && newName == o.Object.Remote() {
+			continue
+		}
+		if max >= 0 && out["rekeyed"] >= max {
+			out["remaining"]++
+			continue
+		}
+		if ci.DryRun {
+			fs.Logf(o, "Not rekeying as --dry-run")
+		} else {
+			err = f.rekeyObject(ctx, o, newName, dataOK)
+			if err != nil {
+				fs.Errorf(o, "Failed to rekey: %v", err)
+				errorCount++
+				continue
+			}
+			fs.Infof(o, "Rekeyed")
+		}
+		out["rekeyed"]++
+	}
+	if errorCount > 0 {
+		return out, errors.Errorf("failed to rekey %d files", errorCount)
+	}
+	if ci.DryRun || out["remaining"] > 0 {
+		return out, nil
+	}
+	// Remove the directories named with previous keys deepest first
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/")
+	})
+	for _, dir := range dirs {
+		encryptedDirs := f.cipher.encryptDirNames(dir)
+		if len(encryptedDirs) == 1 {
+			continue
+		}
+		// make sure the directory still exists if it was empty
+		err = f.Fs.Mkdir(ctx, encryptedDirs[0])
+		if err != nil {
+			fs.Errorf(dir, "Failed to make directory for rekey: %v", err)
+			continue
+		}
+		for _, encryptedDir := range encryptedDirs[1:] {
+			err = f.Fs.Rmdir(ctx, encryptedDir)
+			if err != nil {
+				fs.Debugf(dir, "Not removing directory %q named with previous key: %v", encryptedDir, err)
+			}
+		}
+	}
+	return out, nil
+}
@@ -319,6 +319,20 @@ commands (copy, sync, etc.), and with all other commands too.`,
 			Default:  "",
 			Help:     "Comma separated list of preferred formats for uploading Google docs.",
 			Advanced: true,
+		}, {
+			Name:    "roundtrip_formats",
+			Default: "",
+			Help: `Comma separated list of formats to round trip Google docs with.
+
+Files with these extensions are converted to Google docs when
+uploaded, as with import_formats, and Google docs are downloaded in
+these formats in preference to the ones in export_formats.
+
+This means a file, e.g. "report.docx", can be uploaded as a Google doc
+and downloaded again with the same name, so syncs in either direction
+see the same files. For example use "docx,xlsx,pptx" to edit Office
+files as Google docs.`,
+			Advanced: true,
 		}, {
 			Name:     "allow_import_name_change",
 			Default:  false,
@@ -511,7 +525,7 @@ If this flag is set then rclone will ignore shortcut files completely.
 			// Encode invalid UTF-8 bytes as json doesn't handle them properly.
 			// Don't encode / as it's a valid name character in drive.
 			Default: encoder.EncodeInvalidUtf8,
-		}}...),
+		}, fs.ProxyOption, fs.APIBudgetOption}...),
 	})
 
 	// register duplicate MIME types first
@@ -545,6 +559,7 @@ type Options struct {
 	Extensions                string               `config:"formats"`
 	ExportExtensions          string               `config:"export_formats"`
 	ImportExtensions          string               `config:"import_formats"`
+	RoundtripExtensions       string               `config:"roundtrip_formats"`
 	AllowImportNameChange     bool                 `config:"allow_import_name_change"`
 	UseCreatedDate            bool                 `config:"use_created_date"`
 	UseSharedDate             bool                 `config:"use_shared_date"`
@@ -597,6 +612,7 @@ type baseObject struct {
 	mimeType     string   // The object MIME type
 	bytes        int64    // size of the object
 	parents      []string // IDs of the parent directories
+	readOnly     bool     // set if the user can't edit this object
 }
 type documentObject struct {
 	baseObject
@@ -949,6 +965,22 @@ func parseExtensions(extensionsIn ...string) (extensions, mimeTypes []string, er
 	return
 }
 
+// parseFormats returns the extensions to export Google docs as in
+// order of preference and the MIME types to import as Google docs.
+//
+// The round trip formats are both imported and preferred for export.
+func parseFormats(opt *Options) (exportExtensions, importMimeTypes []string, err error) {
+	exportExtensions, _, err = parseExtensions(opt.RoundtripExtensions, opt.ExportExtensions, defaultExportExtensions)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, importMimeTypes, err = parseExtensions(opt.ImportExtensions, opt.RoundtripExtensions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return exportExtensions, importMimeTypes, nil
+}
+
 // Figure out if the user wants to use a team drive
 func configTeamDrive(ctx context.Context, opt *Options, m configmap.Mapper, name string) error {
 	ci := fs.GetConfig(ctx)
@@ -1181,12 +1213,7 @@ func NewFs(ctx context.Context, name, path string, m configmap.Mapper) (fs.Fs, e
 		}
 		f.opt.Extensions, f.opt.ExportExtensions = "", f.opt.Extensions
 	}
-	f.exportExtensions, _, err = parseExtensions(f.opt.ExportExtensions, defaultExportExtensions)
-	if err != nil {
-		return nil, err
-	}
-
-	_, f.importMimeTypes, err = parseExtensions(f.opt.ImportExtensions)
+	f.exportExtensions, f.importMimeTypes, err = parseFormats(&f.opt)
 	if err != nil {
 		return nil, err
 	}
@@ -1240,12 +1267,13 @@ func (f *Fs) newBaseObject(remote string, info *drive.File) baseObject {
 		mimeType:     info.MimeType,
 		bytes:        size,
 		parents:      info.Parents,
+		readOnly:     info.Capabilities != nil && !info.Capabilities.CanEdit,
 	}
 }
 
 // getFileFields gets the fields for a normal file Get or List
 func (f *Fs) getFileFields() (fields googleapi.Field) {
-	fields = partialFields
+	fields = partialFields + ",capabilities(canEdit)"
 	if f.opt.AuthOwnerOnly {
 		fields += ",owners"
 	}
@@ -2585,6 +2613,8 @@ func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object,
 }
 
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
+//
+// If unlink is set then the "anyone" permissions are removed instead.
 func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration, unlink bool) (link string, err error) {
 	id, err := f.dirCache.FindDir(ctx, remote, false)
 	if err == nil {
@@ -2599,6 +2629,14 @@ func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration,
 		id = shortcutID(o.(fs.IDer).ID())
 	}
 
+	if unlink {
+		return "", f.removeAnyonePermissions(ctx, id)
+	}
+	if expire < fs.LinkExpireNever {
+		// Drive only allows expiry times on user and group permissions
+		fs.Logf(f, "Drive doesn't support expiring public links - ignoring --expire")
+	}
+
 	permission := &drive.Permission{
 		AllowFileDiscovery: false,
 		Role:               "reader",
@@ -3208,6 +3246,176 @@ attempted if possible.
 
 Use the -i flag to see what would be copied before copying.
 `,
+}, {
+	Name:  "labels",
+	Short: "List the Drive labels applied to files",
+	Long: `This command lists the Drive labels applied to the files or
+directories passed in, or to the root if none are.
+
+Usage:
+
+    rclone backend labels drive: [path...]
+
+This will return a JSON object of paths to lists of labels like this
+
+    {
+        "file.txt": [
+            {
+                "id": "abcdefghijklmnopqrstuvwxyz0123456789",
+                "revisionId": "2",
+                "fields": {
+                    "62BB395EC6": {
+                        "id": "62BB395EC6",
+                        "valueType": "selection",
+                        "selection": ["68E9987509"]
+                    }
+                }
+            }
+        ]
+    }
+
+Labels are only available for Google Workspace accounts with Drive
+labels enabled.
+`,
+}, {
+	Name:  "setlabel",
+	Short: "Set a Drive label on files",
+	Long: `This command applies a Drive label to the files or directories
+passed in and optionally sets one of the label's fields.
+
+Usage:
+
+    rclone backend setlabel drive: path... -o label=ID
+    rclone backend setlabel drive: path... -o label=ID -o field=ID -o text=value
+    rclone backend setlabel drive: path... -o label=ID -o field=ID -o selection=ID1,ID2
+    rclone backend setlabel drive: path... -o label=ID -o field=ID -o unset
+    rclone backend setlabel drive: path... -o label=ID -o remove
+
+The label and field IDs can be found with the "labels" command or in
+the Drive labels manager. Exactly one of "text", "integer", "date"
+(YYYY-MM-DD), "selection", "user" (email addresses) or "unset" must be
+given with "field". Apart from "text", multiple values can be given
+separated by commas.
+
+It returns the changed labels in the same format as the "labels"
+command.
+
+Use the -i flag to see what would be changed before changing it.
+`,
+	Opts: map[string]string{
+		"label":     "ID of the label to set",
+		"field":     "ID of the field of the label to set",
+		"text":      "set the field to this text",
+		"integer":   "set the field to these integers",
+		"date":      "set the field to these dates",
+		"selection": "set the field to these choice IDs",
+		"user":      "set the field to these users",
+		"unset":     "unset the field",
+		"remove":    "remove the label from the files",
+	},
+}, {
+	Name:  "list-permissions",
+	Short: "List the permissions of files",
+	Long: `This command lists the permissions of the files or directories
+passed in, or of the root if none are.
+
+Usage:
+
+    rclone backend list-permissions drive: [path...]
+
+This will return a JSON object of paths to lists of permissions like
+this
+
+    {
+        "file.txt": [
+            {
+                "id": "anyoneWithLink",
+                "type": "anyone",
+                "role": "reader"
+            },
+            {
+                "id": "01234567890123456789",
+                "type": "user",
+                "role": "owner",
+                "emailAddress": "user@example.com",
+                "displayName": "A User"
+            }
+        ]
+    }
+`,
+}, {
+	Name:  "share",
+	Short: "Share files and directories",
+	Long: `This command adds a permission to each of the files or
+directories passed in. By default it shares them with anyone who has
+the link so they can read them.
+
+Usage:
+
+    rclone backend share drive: path...
+    rclone backend share drive: path... -o role=writer
+    rclone backend share drive: path... -o type=user -o email=user@example.com [-o notify] [-o message=text]
+    rclone backend share drive: path... -o type=domain -o domain=example.com [-o discoverable]
+
+The "type" can be "anyone" (the default), "user", "group" or "domain"
+and the "role" can be "reader" (the default), "commenter", "writer",
+"fileOrganizer" or "organizer". Users and groups need "email" and
+domains need "domain". Sharing a directory shares everything in it.
+
+It returns the link to each path and the permission which was made
+
+    {
+        "file.txt": {
+            "link": "https://drive.google.com/open?id=0ABCDEFabcdefghijkl",
+            "permission": {
+                "id": "anyoneWithLink",
+                "type": "anyone",
+                "role": "reader"
+            }
+        }
+    }
+
+Use the -i flag to see what would be shared before sharing it.
+`,
+	Opts: map[string]string{
+		"type":         "who to share with: anyone, user, group or domain",
+		"role":         "the role to give: reader, commenter, writer, fileOrganizer or organizer",
+		"email":        "email address of the user or group to share with",
+		"domain":       "the domain to share with",
+		"discoverable": "allow the files to be found by searching (anyone and domain only)",
+		"expire":       "RFC 3339 time the permission expires",
+		"notify":       "send a notification email to the user or group",
+		"message":      "message to put in the notification email",
+	},
+}, {
+	Name:  "unshare",
+	Short: "Remove permissions from files and directories",
+	Long: `This command removes the permissions matching the options from
+each of the files or directories passed in. With no options it removes
+the permissions for anyone as made by default by the "share" command.
+The owner is never removed.
+
+Usage:
+
+    rclone backend unshare drive: path...
+    rclone backend unshare drive: path... -o email=user@example.com
+    rclone backend unshare drive: path... -o id=01234567890123456789
+
+Permissions inherited from a parent directory must be removed from
+that directory.
+
+It returns the permissions which were removed from each path in the
+same format as the "list-permissions" command.
+
+Use the -i flag to see what would be removed before removing it.
+`,
+	Opts: map[string]string{
+		"id":     "only remove the permission with this ID",
+		"type":   "only remove permissions of this type",
+		"role":   "only remove permissions with this role",
+		"email":  "only remove permissions for this email address",
+		"domain": "only remove permissions for this domain",
+	},
 }}
 
 // Command the backend to run a named command
@@ -3292,6 +3500,16 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 			}
 		}
 		return nil, nil
+	case "labels":
+		return f.labelsCommand(ctx, arg)
+	case "setlabel":
+		return f.setLabelCommand(ctx, arg, opt)
+	case "list-permissions":
+		return f.listPermissionsCommand(ctx, arg)
+	case "share":
+		return f.shareCommand(ctx, arg, opt)
+	case "unshare":
+		return f.unshareCommand(ctx, arg, opt)
 	default:
 		return nil, fs.ErrorCommandNotFound
 	}
@@ -3753,6 +3971,12 @@ func (o *baseObject) ParentID() string {
 	return ""
 }
 
+// ReadOnly returns true if the user doesn't have permission to edit
+// the Object as read from its capabilities when it was listed
+func (o *baseObject) ReadOnly(ctx context.Context, fetch bool) (bool, error) {
+	return o.readOnly, nil
+}
+
 func (o *documentObject) ext() string {
 	return o.baseObject.remote[len(o.baseObject.remote)-o.extLen:]
 }
@@ -3814,12 +4038,15 @@ var (
 	_ fs.MimeTyper       = (*Object)(nil)
 	_ fs.IDer            = (*Object)(nil)
 	_ fs.ParentIDer      = (*Object)(nil)
+	_ fs.ReadOnlyer      = (*Object)(nil)
 	_ fs.Object          = (*documentObject)(nil)
 	_ fs.MimeTyper       = (*documentObject)(nil)
 	_ fs.IDer            = (*documentObject)(nil)
 	_ fs.ParentIDer      = (*documentObject)(nil)
+	_ fs.ReadOnlyer      = (*documentObject)(nil)
 	_ fs.Object          = (*linkObject)(nil)
 	_ fs.MimeTyper       = (*linkObject)(nil)
 	_ fs.IDer            = (*linkObject)(nil)
 	_ fs.ParentIDer      = (*linkObject)(nil)
+	_ fs.ReadOnlyer      = (*linkObject)(nil)
 )
@@ -7,6 +7,8 @@ import (
 	"io"
 	"io/ioutil"
 	"mime"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
@@ -21,6 +23,7 @@ import (
 	"github.com/artpar/rclone/fs/operations"
 	"github.com/artpar/rclone/fstest"
 	"github.com/artpar/rclone/fstest/fstests"
+	"github.com/artpar/rclone/lib/pacer"
 	"github.com/artpar/rclone/lib/random"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -110,6 +113,35 @@ func TestInternalParseExtensions(t *testing.T) {
 	assert.Equal(t, []string{".docx", ".svg", ".xlsx"}, extensions)
 }
 
+func TestInternalParseFormats(t *testing.T) {
+	for _, test := range []struct {
+		export, imp, roundtrip string
+		wantExport             []string
+		wantImport             []string
+	}{
+		{"", "", "", []string{".docx", ".xlsx", ".pptx", ".svg"}, nil},
+		{"pdf", "odt", "", []string{".pdf", ".docx", ".xlsx", ".pptx", ".svg"}, []string{"application/vnd.oasis.opendocument.text"}},
+		{defaultExportExtensions, "", "odt", []string{".odt", ".docx", ".xlsx", ".pptx", ".svg"}, []string{"application/vnd.oasis.opendocument.text"}},
+		{"pdf", "xlsx", "odt,xlsx", []string{".odt", ".xlsx", ".pdf", ".docx", ".pptx", ".svg"}, []string{
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			"application/vnd.oasis.opendocument.text",
+		}},
+	} {
+		opt := &Options{
+			ExportExtensions:    test.export,
+			ImportExtensions:    test.imp,
+			RoundtripExtensions: test.roundtrip,
+		}
+		gotExport, gotImport, err := parseFormats(opt)
+		require.NoError(t, err)
+		assert.Equal(t, test.wantExport, gotExport, "export")
+		assert.Equal(t, test.wantImport, gotImport, "import")
+	}
+
+	_, _, err := parseFormats(&Options{RoundtripExtensions: "potato"})
+	assert.EqualError(t, err, `couldn't find MIME type for extension ".potato"`)
+}
+
 func TestInternalFindExportFormat(t *testing.T) {
 	ctx := context.Background()
 	item := &drive.File{
@@ -481,3 +513,195 @@ func (f *Fs) InternalTest(t *testing.T) {
 }
 
 var _ fstests.InternalTester = (*Fs)(nil)
+
+func TestParseLabelModification(t *testing.T) {
+	for _, test := range []struct {
+		opt     map[string]string
+		want    *labelModification
+		wantErr string
+	}{
+		{map[string]string{}, nil, "need -o label=ID"},
+		{map[string]string{"label": "L"}, &labelModification{LabelID: "L"}, ""},
+		{map[string]string{"label": "L", "remove": ""}, &labelModification{LabelID: "L", RemoveLabel: true}, ""},
+		{map[string]string{"label": "L", "field": "F", "text": "a,b"}, &labelModification{LabelID: "L", FieldModifications: []*labelFieldModification{
+			{FieldID: "F", SetTextValues: []string{"a,b"}},
+		}}, ""},
+		{map[string]string{"label": "L", "field": "F", "selection": "a,b"}, &labelModification{LabelID: "L", FieldModifications: []*labelFieldModification{
+			{FieldID: "F", SetSelectionValues: []string{"a", "b"}},
+		}}, ""},
+		{map[string]string{"label": "L", "field": "F", "unset": ""}, &labelModification{LabelID: "L", FieldModifications: []*labelFieldModification{
+			{FieldID: "F", UnsetValues: true},
+		}}, ""},
+		{map[string]string{"label": "L", "text": "a"}, nil, "need -o field=ID"},
+		{map[string]string{"label": "L", "field": "F"}, nil, "need a value"},
+		{map[string]string{"label": "L", "field": "F", "text": "a", "date": "2021-01-01"}, nil, "exactly one value"},
+		{map[string]string{"label": "L", "potato": ""}, nil, "unknown option"},
+	} {
+		got, err := parseLabelModification(test.opt)
+		if test.wantErr != "" {
+			require.Error(t, err, test.opt)
+			assert.Contains(t, err.Error(), test.wantErr, test.opt)
+			continue
+		}
+		require.NoError(t, err, test.opt)
+		assert.Equal(t, test.want, got, test.opt)
+	}
+}
+
+func TestLabelsCall(t *testing.T) {
+	ctx := context.Background()
+	var gotModify modifyLabelsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/files/ID/listLabels":
+			assert.Equal(t, "GET", r.Method)
+			if r.URL.Query().Get("pageToken") == "" {
+				_, _ = io.WriteString(w, `{"labels":[{"id":"L1"}],"nextPageToken":"page2"}`)
+			} else {
+				_, _ = io.WriteString(w, `{"labels":[{"id":"L2","fields":{"F":{"id":"F","valueType":"text","text":["hello"]}}}]}`)
+			}
+		case "/files/ID/modifyLabels":
+			assert.Equal(t, "POST", r.Method)
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&gotModify))
+			_, _ = io.WriteString(w, `{"modifiedLabels":[{"id":"L1"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	svc, err := drive.New(server.Client())
+	require.NoError(t, err)
+	svc.BasePath = server.URL + "/"
+	f := &Fs{
+		svc:    svc,
+		client: server.Client(),
+		pacer:  fs.NewPacer(ctx, pacer.NewDefault()),
+	}
+
+	labels, err := f.listLabels(ctx, "ID")
+	require.NoError(t, err)
+	require.Equal(t, 2, len(labels))
+	assert.Equal(t, "L1", labels[0].ID)
+	assert.Equal(t, "L2", labels[1].ID)
+	assert.Equal(t, []string{"hello"}, labels[1].Fields["F"].Text)
+
+	mod := &labelModification{LabelID: "L1", FieldModifications: []*labelFieldModification{
+		{FieldID: "F", SetTextValues: []string{"potato"}},
+	}}
+	labels, err = f.modifyLabels(ctx, "ID", []*labelModification{mod})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(labels))
+	assert.Equal(t, "drive#modifyLabelsRequest", gotModify.Kind)
+	assert.Equal(t, []*labelModification{mod}, gotModify.LabelModifications)
+
+	_, err = f.listLabels(ctx, "missing")
+	require.Error(t, err)
+}
+
+func TestParseSharePermission(t *testing.T) {
+	for _, test := range []struct {
+		opt     map[string]string
+		want    *drive.Permission
+		wantErr string
+	}{
+		{map[string]string{}, &drive.Permission{Type: "anyone", Role: "reader"}, ""},
+		{map[string]string{"role": "writer", "discoverable": ""}, &drive.Permission{Type: "anyone", Role: "writer", AllowFileDiscovery: true}, ""},
+		{map[string]string{"type": "user", "email": "a@example.com", "notify": ""}, &drive.Permission{Type: "user", Role: "reader", EmailAddress: "a@example.com"}, ""},
+		{map[string]string{"type": "domain", "domain": "example.com"}, &drive.Permission{Type: "domain", Role: "reader", Domain: "example.com"}, ""},
+		{map[string]string{"type": "group"}, nil, "need -o email"},
+		{map[string]string{"type": "domain"}, nil, "need -o domain"},
+		{map[string]string{"type": "potato"}, nil, "unknown type"},
+		{map[string]string{"role": "owner"}, nil, "ownership"},
+		{map[string]string{"potato": ""}, nil, "unknown option"},
+	} {
+		got, err := parseSharePermission(test.opt)
+		if test.wantErr != "" {
+			require.Error(t, err, test.opt)
+			assert.Contains(t, err.Error(), test.wantErr, test.opt)
+			continue
+		}
+		require.NoError(t, err, test.opt)
+		assert.Equal(t, test.want, got, test.opt)
+	}
+}
+
+func TestParseUnsharePermission(t *testing.T) {
+	anyone := &drive.Permission{Id: "anyoneWithLink", Type: "anyone", Role: "reader"}
+	user := &drive.Permission{Id: "1", Type: "user", Role: "writer", EmailAddress: "a@example.com"}
+	owner := &drive.Permission{Id: "2", Type: "user", Role: "owner", EmailAddress: "b@example.com"}
+	for _, test := range []struct {
+		opt  map[string]string
+		want []bool // matches anyone, user, owner
+	}{
+		{map[string]string{}, []bool{true, false, false}},
+		{map[string]string{"type": "user"}, []bool{false, true, false}},
+		{map[string]string{"email": "b@example.com"}, []bool{false, false, false}},
+		{map[string]string{"id": "1", "role": "writer"}, []bool{false, true, false}},
+		{map[string]string{"id": "1", "role": "reader"}, []bool{false, false, false}},
+	} {
+		match, err := parseUnsharePermission(test.opt)
+		require.NoError(t, err, test.opt)
+		assert.Equal(t, test.want, []bool{match(anyone), match(user), match(owner)}, test.opt)
+	}
+
+	_, err := parseUnsharePermission(map[string]string{"potato": ""})
+	assert.EqualError(t, err, `unknown option "potato"`)
+}
+
+func TestPermissionsCall(t *testing.T) {
+	ctx := context.Background()
+	var gotCreate drive.Permission
+	var gotNotify string
+	deleted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/files/ID/permissions" && r.Method == "GET":
+			if r.URL.Query().Get("pageToken") == "" {
+				_, _ = io.WriteString(w, `{"permissions":[{"id":"P1","type":"anyone","role":"reader"}],"nextPageToken":"page2"}`)
+			} else {
+				_, _ = io.WriteString(w, `{"permissions":[{"id":"P2","type":"user","role":"owner","emailAddress":"a@example.com"}]}`)
+			}
+		case r.URL.Path == "/files/ID/permissions" && r.Method == "POST":
+			gotNotify = r.URL.Query().Get("sendNotificationEmail")
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&gotCreate))
+			_, _ = io.WriteString(w, `{"id":"P3","type":"user","role":"writer","emailAddress":"c@example.com"}`)
+		case strings.HasPrefix(r.URL.Path, "/files/ID/permissions/") && r.Method == "DELETE":
+			deleted = append(deleted, path.Base(r.URL.Path))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	svc, err := drive.New(server.Client())
+	require.NoError(t, err)
+	svc.BasePath = server.URL + "/"
+	f := &Fs{
+		svc:    svc,
+		client: server.Client(),
+		pacer:  fs.NewPacer(ctx, pacer.NewDefault()),
+	}
+
+	permissions, err := f.listPermissions(ctx, "ID")
+	require.NoError(t, err)
+	require.Equal(t, 2, len(permissions))
+	assert.Equal(t, "P1", permissions[0].Id)
+	assert.Equal(t, "a@example.com", permissions[1].EmailAddress)
+
+	permission := &drive.Permission{Type: "user", Role: "writer", EmailAddress: "c@example.com"}
+	created, err := f.createPermission(ctx, "ID", permission, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, "P3", created.Id)
+	assert.Equal(t, *permission, gotCreate)
+	assert.Equal(t, "false", gotNotify)
+
+	require.NoError(t, f.deletePermission(ctx, "ID", "P1"))
+	assert.Equal(t, []string{"P1"}, deleted)
+
+	_, err = f.listPermissions(ctx, "missing")
+	require.Error(t, err)
+}
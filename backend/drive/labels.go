@@ -0,0 +1,282 @@
+// Labels for drive
+//
+// Docs
+// List labels: https://developers.google.com/drive/api/reference/rest/v3/files/listLabels
+// Modify labels: https://developers.google.com/drive/api/reference/rest/v3/files/modifyLabels
+//
+// The version of google.golang.org/api we use doesn't know about
+// labels so these call the REST API directly.
+
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/operations"
+	"github.com/pkg/errors"
+	"google.golang.org/api/googleapi"
+)
+
+// Label is a Drive label applied to a file
+type Label struct {
+	ID         string                 `json:"id"`
+	RevisionID string                 `json:"revisionId,omitempty"`
+	Fields     map[string]*LabelField `json:"fields,omitempty"`
+}
+
+// LabelField is the value of a field of a Label applied to a file
+type LabelField struct {
+	ID         string      `json:"id"`
+	ValueType  string      `json:"valueType,omitempty"`
+	Text       []string    `json:"text,omitempty"`
+	Integer    []string    `json:"integer,omitempty"`
+	DateString []string    `json:"dateString,omitempty"`
+	Selection  []string    `json:"selection,omitempty"`
+	User       []LabelUser `json:"user,omitempty"`
+}
+
+// LabelUser is a user stored in a LabelField
+type LabelUser struct {
+	EmailAddress string `json:"emailAddress,omitempty"`
+	DisplayName  string `json:"displayName,omitempty"`
+}
+
+// labelList is the response from listLabels
+type labelList struct {
+	Labels        []*Label `json:"labels"`
+	NextPageToken string   `json:"nextPageToken,omitempty"`
+}
+
+// labelModification is a change to a label on a file
+type labelModification struct {
+	LabelID            string                    `json:"labelId"`
+	RemoveLabel        bool                      `json:"removeLabel,omitempty"`
+	FieldModifications []*labelFieldModification `json:"fieldModifications,omitempty"`
+}
+
+// labelFieldModification is a change to a field of a label on a file
+type labelFieldModification struct {
+	FieldID            string   `json:"fieldId"`
+	SetTextValues      []string `json:"setTextValues,omitempty"`
+	SetIntegerValues   []string `json:"setIntegerValues,omitempty"`
+	SetDateValues      []string `json:"setDateValues,omitempty"`
+	SetSelectionValues []string `json:"setSelectionValues,omitempty"`
+	SetUserValues      []string `json:"setUserValues,omitempty"`
+	UnsetValues        bool     `json:"unsetValues,omitempty"`
+}
+
+// modifyLabelsRequest is the request to modifyLabels
+type modifyLabelsRequest struct {
+	Kind               string               `json:"kind"`
+	LabelModifications []*labelModification `json:"labelModifications"`
+}
+
+// modifyLabelsResponse is the response from modifyLabels
+type modifyLabelsResponse struct {
+	ModifiedLabels []*Label `json:"modifiedLabels"`
+}
+
+// labelsCall does a call to the labels API for fileID with method and
+// suffix, reading the response into result
+func (f *Fs) labelsCall(ctx context.Context, method, fileID, suffix string, params url.Values, request, result interface{}) (err error) {
+	params.Set("alt", "json")
+	urls := f.svc.BasePath + "files/{fileId}/" + suffix + "?" + params.Encode()
+	var res *http.Response
+	return f.pacer.Call(func() (bool, error) {
+		var req *http.Request
+		if request != nil {
+			body, err := googleapi.WithoutDataWrapper.JSONReader(request)
+			if err != nil {
+				return false, err
+			}
+			req, err = http.NewRequestWithContext(ctx, method, urls, body)
+			if err != nil {
+				return false, err
+			}
+			req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+		} else {
+			req, err = http.NewRequestWithContext(ctx, method, urls, nil)
+			if err != nil {
+				return false, err
+			}
+		}
+		googleapi.Expand(req.URL, map[string]string{
+			"fileId": fileID,
+		})
+		res, err = f.client.Do(req)
+		if err == nil {
+			defer googleapi.CloseBody(res)
+			err = googleapi.CheckResponse(res)
+			if err == nil {
+				err = json.NewDecoder(res.Body).Decode(result)
+			}
+		}
+		return f.shouldRetry(ctx, err)
+	})
+}
+
+// listLabels returns the labels applied to fileID
+func (f *Fs) listLabels(ctx context.Context, fileID string) (labels []*Label, err error) {
+	params := url.Values{}
+	for {
+		var result labelList
+		err = f.labelsCall(ctx, "GET", fileID, "listLabels", params, nil, &result)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, result.Labels...)
+		if result.NextPageToken == "" {
+			break
+		}
+		params.Set("pageToken", result.NextPageToken)
+	}
+	return labels, nil
+}
+
+// modifyLabels applies the modifications to fileID returning the
+// labels which were changed
+func (f *Fs) modifyLabels(ctx context.Context, fileID string, mods []*labelModification) (labels []*Label, err error) {
+	request := modifyLabelsRequest{
+		Kind:               "drive#modifyLabelsRequest",
+		LabelModifications: mods,
+	}
+	var result modifyLabelsResponse
+	err = f.labelsCall(ctx, "POST", fileID, "modifyLabels", url.Values{}, &request, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result.ModifiedLabels, nil
+}
+
+// findID returns the ID of the file or directory at remote
+func (f *Fs) findID(ctx context.Context, remote string) (ID string, err error) {
+	remote = strings.Trim(remote, "/")
+	if remote == "" {
+		ID, err = f.dirCache.RootID(ctx, false)
+	} else {
+		var o fs.Object
+		o, err = f.NewObject(ctx, remote)
+		if err == nil {
+			ID = o.(*Object).id
+		} else if err == fs.ErrorNotAFile {
+			ID, err = f.dirCache.FindDir(ctx, remote, false)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return actualID(ID), nil
+}
+
+// labelsCommand implements the labels backend command
+func (f *Fs) labelsCommand(ctx context.Context, args []string) (out map[string][]*Label, err error) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	out = make(map[string][]*Label, len(args))
+	for _, remote := range args {
+		ID, err := f.findID(ctx, remote)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't find %q", remote)
+		}
+		labels, err := f.listLabels(ctx, ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't list labels for %q", remote)
+		}
+		if labels == nil {
+			labels = []*Label{}
+		}
+		out[remote] = labels
+	}
+	return out, nil
+}
+
+// parseLabelModification makes a labelModification from the options
+// passed to the setlabel backend command
+func parseLabelModification(opt map[string]string) (*labelModification, error) {
+	mod := &labelModification{
+		LabelID: opt["label"],
+	}
+	if mod.LabelID == "" {
+		return nil, errors.New("need -o label=ID")
+	}
+	if _, ok := opt["remove"]; ok {
+		mod.RemoveLabel = true
+		return mod, nil
+	}
+	fieldID := opt["field"]
+	split := func(s string) []string {
+		return strings.Split(s, ",")
+	}
+	field := &labelFieldModification{FieldID: fieldID}
+	values := 0
+	for key, value := range opt {
+		switch key {
+		case "text":
+			field.SetTextValues = []string{value}
+		case "integer":
+			field.SetIntegerValues = split(value)
+		case "date":
+			field.SetDateValues = split(value)
+		case "selection":
+			field.SetSelectionValues = split(value)
+		case "user":
+			field.SetUserValues = split(value)
+		case "unset":
+			field.UnsetValues = true
+		case "label", "field":
+			continue
+		default:
+			return nil, errors.Errorf("unknown option %q", key)
+		}
+		values++
+	}
+	switch {
+	case fieldID == "" && values == 0:
+		// just apply the label
+	case fieldID == "":
+		return nil, errors.New("need -o field=ID to set a value")
+	case values == 0:
+		return nil, errors.Errorf("need a value for field %q", fieldID)
+	case values > 1:
+		return nil, errors.Errorf("need exactly one value for field %q", fieldID)
+	default:
+		mod.FieldModifications = []*labelFieldModification{field}
+	}
+	return mod, nil
+}
+
+// setLabelCommand implements the setlabel backend command
+func (f *Fs) setLabelCommand(ctx context.Context, args []string, opt map[string]string) (out map[string][]*Label, err error) {
+	if len(args) == 0 {
+		return nil, errors.New("need at least one path")
+	}
+	mod, err := parseLabelModification(opt)
+	if err != nil {
+		return nil, err
+	}
+	out = make(map[string][]*Label, len(args))
+	for _, remote := range args {
+		ID, err := f.findID(ctx, remote)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't find %q", remote)
+		}
+		if operations.SkipDestructive(ctx, remote, "set label") {
+			continue
+		}
+		labels, err := f.modifyLabels(ctx, ID, []*labelModification{mod})
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't set label on %q", remote)
+		}
+		if labels == nil {
+			labels = []*Label{}
+		}
+		out[remote] = labels
+	}
+	return out, nil
+}
@@ -0,0 +1,286 @@
+// Permissions for drive
+//
+// Docs: https://developers.google.com/drive/api/v3/reference/permissions
+
+package drive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/artpar/rclone/fs/operations"
+	"github.com/pkg/errors"
+	drive "google.golang.org/api/drive/v3"
+)
+
+// permissionFields are the fields of the permissions returned
+const permissionFields = "id,type,role,emailAddress,domain,displayName,allowFileDiscovery,expirationTime,deleted"
+
+// Share is the result of sharing a file with the share backend command
+type Share struct {
+	Link       string            `json:"link"`
+	Permission *drive.Permission `json:"permission"`
+}
+
+// listPermissions returns the permissions of fileID
+func (f *Fs) listPermissions(ctx context.Context, fileID string) (permissions []*drive.Permission, err error) {
+	pageToken := ""
+	for {
+		var result *drive.PermissionList
+		err = f.pacer.Call(func() (bool, error) {
+			result, err = f.svc.Permissions.List(fileID).
+				Fields("nextPageToken,permissions(" + permissionFields + ")").
+				PageToken(pageToken).
+				SupportsAllDrives(true).
+				Context(ctx).Do()
+			return f.shouldRetry(ctx, err)
+		})
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, result.Permissions...)
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+	return permissions, nil
+}
+
+// createPermission adds permission to fileID, sending a notification
+// email with message if notify is set
+func (f *Fs) createPermission(ctx context.Context, fileID string, permission *drive.Permission, notify bool, message string) (created *drive.Permission, err error) {
+	err = f.pacer.Call(func() (bool, error) {
+		call := f.svc.Permissions.Create(fileID, permission).
+			Fields(permissionFields).
+			SupportsAllDrives(true)
+		// Notification emails can only be sent to users and groups
+		if permission.Type == "user" || permission.Type == "group" {
+			call.SendNotificationEmail(notify)
+			if notify && message != "" {
+				call.EmailMessage(message)
+			}
+		}
+		created, err = call.Context(ctx).Do()
+		return f.shouldRetry(ctx, err)
+	})
+	return created, err
+}
+
+// deletePermission removes permissionID from fileID
+func (f *Fs) deletePermission(ctx context.Context, fileID, permissionID string) (err error) {
+	return f.pacer.Call(func() (bool, error) {
+		err = f.svc.Permissions.Delete(fileID, permissionID).
+			SupportsAllDrives(true).
+			Context(ctx).Do()
+		return f.shouldRetry(ctx, err)
+	})
+}
+
+// removeAnyonePermissions removes the "anyone with the link"
+// permissions from fileID which are made by PublicLink
+func (f *Fs) removeAnyonePermissions(ctx context.Context, fileID string) error {
+	permissions, err := f.listPermissions(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, permission := range permissions {
+		if permission.Type != "anyone" {
+			continue
+		}
+		err = f.deletePermission(ctx, fileID, permission.Id)
+		if err != nil {
+			return err
+		}
+		removed++
+	}
+	if removed == 0 {
+		return errors.New("no public link found to remove")
+	}
+	return nil
+}
+
+// parseSharePermission makes the permission to create from the
+// options passed to the share backend command
+func parseSharePermission(opt map[string]string) (*drive.Permission, error) {
+	permission := &drive.Permission{
+		Type: "anyone",
+		Role: "reader",
+	}
+	for key, value := range opt {
+		switch key {
+		case "type":
+			permission.Type = value
+		case "role":
+			permission.Role = value
+		case "email":
+			permission.EmailAddress = value
+		case "domain":
+			permission.Domain = value
+		case "discoverable":
+			permission.AllowFileDiscovery = true
+		case "expire":
+			permission.ExpirationTime = value
+		case "notify", "message":
+			continue
+		default:
+			return nil, errors.Errorf("unknown option %q", key)
+		}
+	}
+	switch permission.Type {
+	case "anyone":
+	case "user", "group":
+		if permission.EmailAddress == "" {
+			return nil, errors.Errorf("need -o email=ADDRESS to share with a %s", permission.Type)
+		}
+	case "domain":
+		if permission.Domain == "" {
+			return nil, errors.New("need -o domain=DOMAIN to share with a domain")
+		}
+	default:
+		return nil, errors.Errorf("unknown type %q - need anyone, user, group or domain", permission.Type)
+	}
+	if permission.Role == "owner" {
+		return nil, errors.New("can't transfer ownership with share")
+	}
+	return permission, nil
+}
+
+// parseUnsharePermission makes a function to match the permissions to
+// remove from the options passed to the unshare backend command.
+//
+// With no options it matches the permissions of type anyone as
+// created by default by the share command.
+func parseUnsharePermission(opt map[string]string) (match func(*drive.Permission) bool, err error) {
+	filter := map[string]string{}
+	for key, value := range opt {
+		switch key {
+		case "id", "type", "role", "email", "domain":
+			filter[key] = value
+		default:
+			return nil, errors.Errorf("unknown option %q", key)
+		}
+	}
+	if len(filter) == 0 {
+		filter["type"] = "anyone"
+	}
+	return func(p *drive.Permission) bool {
+		// Never remove the owner
+		if p.Role == "owner" {
+			return false
+		}
+		for key, value := range filter {
+			var got string
+			switch key {
+			case "id":
+				got = p.Id
+			case "type":
+				got = p.Type
+			case "role":
+				got = p.Role
+			case "email":
+				got = p.EmailAddress
+			case "domain":
+				got = p.Domain
+			}
+			if got != value {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// listPermissionsCommand implements the list-permissions backend command
+func (f *Fs) listPermissionsCommand(ctx context.Context, args []string) (out map[string][]*drive.Permission, err error) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	out = make(map[string][]*drive.Permission, len(args))
+	for _, remote := range args {
+		ID, err := f.findID(ctx, remote)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't find %q", remote)
+		}
+		permissions, err := f.listPermissions(ctx, ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't list permissions for %q", remote)
+		}
+		if permissions == nil {
+			permissions = []*drive.Permission{}
+		}
+		out[remote] = permissions
+	}
+	return out, nil
+}
+
+// shareCommand implements the share backend command
+func (f *Fs) shareCommand(ctx context.Context, args []string, opt map[string]string) (out map[string]*Share, err error) {
+	if len(args) == 0 {
+		return nil, errors.New("need at least one path")
+	}
+	permission, err := parseSharePermission(opt)
+	if err != nil {
+		return nil, err
+	}
+	_, notify := opt["notify"]
+	out = make(map[string]*Share, len(args))
+	for _, remote := range args {
+		ID, err := f.findID(ctx, remote)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't find %q", remote)
+		}
+		if operations.SkipDestructive(ctx, remote, "share") {
+			continue
+		}
+		created, err := f.createPermission(ctx, ID, permission, notify, opt["message"])
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't share %q", remote)
+		}
+		out[remote] = &Share{
+			Link:       fmt.Sprintf("https://drive.google.com/open?id=%s", ID),
+			Permission: created,
+		}
+	}
+	return out, nil
+}
+
+// unshareCommand implements the unshare backend command
+func (f *Fs) unshareCommand(ctx context.Context, args []string, opt map[string]string) (out map[string][]*drive.Permission, err error) {
+	if len(args) == 0 {
+		return nil, errors.New("need at least one path")
+	}
+	match, err := parseUnsharePermission(opt)
+	if err != nil {
+		return nil, err
+	}
+	out = make(map[string][]*drive.Permission, len(args))
+	for _, remote := range args {
+		ID, err := f.findID(ctx, remote)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't find %q", remote)
+		}
+		permissions, err := f.listPermissions(ctx, ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't list permissions for %q", remote)
+		}
+		removed := []*drive.Permission{}
+		for _, permission := range permissions {
+			if !match(permission) {
+				continue
+			}
+			what := fmt.Sprintf("remove %s permission %q", permission.Type, permission.Id)
+			if operations.SkipDestructive(ctx, remote, what) {
+				continue
+			}
+			err = f.deletePermission(ctx, ID, permission.Id)
+			if err != nil {
+				return nil, errors.Wrapf(err, "couldn't remove permission %q from %q", permission.Id, remote)
+			}
+			removed = append(removed, permission)
+		}
+		out[remote] = removed
+	}
+	return out, nil
+}
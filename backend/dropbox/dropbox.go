@@ -224,7 +224,7 @@ shared folder.`,
 				encoder.EncodeDel |
 				encoder.EncodeRightSpace |
 				encoder.EncodeInvalidUtf8,
-		}}...),
+		}, fs.ProxyOption, fs.APIBudgetOption}...),
 	})
 }
 
@@ -1078,18 +1078,53 @@ func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object,
 	return dstObj, nil
 }
 
+// sharedLinkURL returns the URL of the shared link in linkRes
+func sharedLinkURL(linkRes sharing.IsSharedLinkMetadata) (string, error) {
+	switch res := linkRes.(type) {
+	case *sharing.FileLinkMetadata:
+		return res.Url, nil
+	case *sharing.FolderLinkMetadata:
+		return res.Url, nil
+	}
+	return "", fmt.Errorf("Don't know how to extract link, response has unknown format: %T", linkRes)
+}
+
+// listSharedLinks lists the shared links of the item at absPath
+func (f *Fs) listSharedLinks(ctx context.Context, absPath string) (links []sharing.IsSharedLinkMetadata, err error) {
+	listArg := sharing.ListSharedLinksArg{
+		Path:       absPath,
+		DirectOnly: true,
+	}
+	var listRes *sharing.ListSharedLinksResult
+	err = f.pacer.Call(func() (bool, error) {
+		listRes, err = f.sharing.ListSharedLinks(&listArg)
+		return shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return listRes.Links, nil
+}
+
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
+//
+// If expire is set the link expires then, which needs a paid account.
+//
+// If unlink is set then the shared links to the item are revoked
+// instead.
 func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration, unlink bool) (link string, err error) {
 	absPath := f.opt.Enc.FromStandardPath(path.Join(f.slashRoot, remote))
 	fs.Debugf(f, "attempting to share '%s' (absolute path: %s)", remote, absPath)
+	if unlink {
+		return "", f.revokeSharedLinks(ctx, absPath)
+	}
 	createArg := sharing.CreateSharedLinkWithSettingsArg{
 		Path: absPath,
-		// FIXME this gives settings_error/not_authorized/.. errors
-		// and the expires setting isn't in the documentation so remove
-		// for now.
-		// Settings: &sharing.SharedLinkSettings{
-		// 	Expires: time.Now().Add(time.Duration(expire)).UTC().Round(time.Second),
-		// },
+	}
+	if expire < fs.LinkExpireNever {
+		createArg.Settings = &sharing.SharedLinkSettings{
+			Expires: time.Now().Add(time.Duration(expire)).UTC().Round(time.Second),
+		}
 	}
 	var linkRes sharing.IsSharedLinkMetadata
 	err = f.pacer.Call(func() (bool, error) {
@@ -1100,35 +1135,61 @@ func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration,
 	if err != nil && strings.Contains(err.Error(),
 		sharing.CreateSharedLinkWithSettingsErrorSharedLinkAlreadyExists) {
 		fs.Debugf(absPath, "has a public link already, attempting to retrieve it")
-		listArg := sharing.ListSharedLinksArg{
-			Path:       absPath,
-			DirectOnly: true,
+		var links []sharing.IsSharedLinkMetadata
+		links, err = f.listSharedLinks(ctx, absPath)
+		if err != nil {
+			return "", err
+		}
+		if len(links) == 0 {
+			return "", errors.New("Dropbox says the sharing link already exists, but list came back empty")
+		}
+		linkRes = links[0]
+		if createArg.Settings != nil {
+			// Set the expiry on the existing link
+			link, err = sharedLinkURL(linkRes)
+			if err != nil {
+				return "", err
+			}
+			modifyArg := sharing.NewModifySharedLinkSettingsArgs(link, createArg.Settings)
+			err = f.pacer.Call(func() (bool, error) {
+				linkRes, err = f.sharing.ModifySharedLinkSettings(modifyArg)
+				return shouldRetry(ctx, err)
+			})
+		}
+	}
+	if err != nil {
+		if createArg.Settings != nil && strings.Contains(err.Error(), "not_authorized") {
+			return "", errors.Wrap(err, "setting the expiry time of links needs a paid Dropbox account")
 		}
-		var listRes *sharing.ListSharedLinksResult
+		return "", err
+	}
+	return sharedLinkURL(linkRes)
+}
+
+// revokeSharedLinks revokes the shared links of the item at absPath
+func (f *Fs) revokeSharedLinks(ctx context.Context, absPath string) error {
+	links, err := f.listSharedLinks(ctx, absPath)
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		return errors.New("no public link found to remove")
+	}
+	for _, linkRes := range links {
+		link, err := sharedLinkURL(linkRes)
+		if err != nil {
+			return err
+		}
+		revokeArg := sharing.NewRevokeSharedLinkArg(link)
 		err = f.pacer.Call(func() (bool, error) {
-			listRes, err = f.sharing.ListSharedLinks(&listArg)
+			err = f.sharing.RevokeSharedLink(revokeArg)
 			return shouldRetry(ctx, err)
 		})
 		if err != nil {
-			return
-		}
-		if len(listRes.Links) == 0 {
-			err = errors.New("Dropbox says the sharing link already exists, but list came back empty")
-			return
+			return errors.Wrap(err, "failed to revoke link")
 		}
-		linkRes = listRes.Links[0]
 	}
-	if err == nil {
-		switch res := linkRes.(type) {
-		case *sharing.FileLinkMetadata:
-			link = res.Url
-		case *sharing.FolderLinkMetadata:
-			link = res.Url
-		default:
-			err = fmt.Errorf("Don't know how to extract link, response has unknown format: %T", res)
-		}
-	}
-	return
+	return nil
 }
 
 // DirMove moves src, srcRemote to this remote at dstRemote
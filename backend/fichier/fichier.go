@@ -71,7 +71,7 @@ func init() {
 				encoder.EncodeLeftSpace |
 				encoder.EncodeRightSpace |
 				encoder.EncodeInvalidUtf8),
-		}},
+		}, fs.ProxyOption, fs.APIBudgetOption},
 	})
 }
 
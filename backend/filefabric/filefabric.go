@@ -127,7 +127,7 @@ Don't set this value - rclone will set it automatically.
 			Advanced: true,
 			Default: (encoder.Display |
 				encoder.EncodeInvalidUtf8),
-		}},
+		}, fs.ProxyOption, fs.APIBudgetOption},
 	})
 }
 
@@ -127,7 +127,7 @@ Set to 0 to keep connections indefinitely.
 			// pureftpd can't handle '[', ']' or '*'
 			Default: (encoder.Display |
 				encoder.EncodeRightSpace),
-		}},
+		}, fs.APIBudgetOption},
 	})
 }
 
@@ -165,6 +165,12 @@ type Fs struct {
 	tokens   *pacer.TokenDispenser
 	tlsConf  *tls.Config
 	pacer    *fs.Pacer // pacer for FTP connections
+	fGetTime bool      // true if the server supports MDTM
+	fSetTime bool      // true if the server supports MFMT
+	fLstTime bool      // true if MLSD is used so listings have precise times
+
+	controlMu    sync.Mutex
+	controlConns map[*ftp.ServerConn]net.Conn // control connections to send raw commands on
 }
 
 // Object describes an FTP file
@@ -179,6 +185,7 @@ type FileInfo struct {
 	Name    string
 	Size    uint64
 	ModTime time.Time
+	precise bool // true if the time is precise
 	IsDir   bool
 }
 
@@ -242,8 +249,9 @@ func (dl *debugLog) Write(p []byte) (n int, err error) {
 }
 
 type dialCtx struct {
-	f   *Fs
-	ctx context.Context
+	f       *Fs
+	ctx     context.Context
+	control net.Conn // the first connection dialled which is the control connection
 }
 
 // dial a new connection with fshttp dialer
@@ -255,6 +263,12 @@ func (d *dialCtx) dial(network, address string) (net.Conn, error) {
 	if d.f.tlsConf != nil {
 		conn = tls.Client(conn, d.f.tlsConf)
 	}
+	// With explicit TLS the control connection isn't dialled
+	// here and is wrapped in TLS by the library so raw commands
+	// can't be sent on it
+	if d.control == nil && !d.f.opt.ExplicitTLS {
+		d.control = conn
+	}
 	return conn, err
 }
 
@@ -277,7 +291,7 @@ func shouldRetry(ctx context.Context, err error) (bool, error) {
 // Open a new connection to the FTP server.
 func (f *Fs) ftpConnection(ctx context.Context) (c *ftp.ServerConn, err error) {
 	fs.Debugf(f, "Connecting to FTP server")
-	dCtx := dialCtx{f, ctx}
+	dCtx := dialCtx{f: f, ctx: ctx}
 	ftpConfig := []ftp.DialOption{ftp.DialWithDialFunc(dCtx.dial)}
 	if f.opt.ExplicitTLS {
 		ftpConfig = append(ftpConfig, ftp.DialWithExplicitTLS(f.tlsConf))
@@ -298,6 +312,7 @@ func (f *Fs) ftpConnection(ctx context.Context) (c *ftp.ServerConn, err error) {
 		ftpConfig = append(ftpConfig, ftp.DialWithDebugOutput(&debugLog{auth: f.ci.Dump&fs.DumpAuth != 0}))
 	}
 	err = f.pacer.Call(func() (bool, error) {
+		dCtx.control = nil
 		c, err = ftp.Dial(f.dialAddr, ftpConfig...)
 		if err != nil {
 			return shouldRetry(ctx, err)
@@ -311,6 +326,8 @@ func (f *Fs) ftpConnection(ctx context.Context) (c *ftp.ServerConn, err error) {
 	})
 	if err != nil {
 		err = errors.Wrapf(err, "failed to make FTP connection to %q", f.dialAddr)
+	} else if dCtx.control != nil {
+		f.setControlConn(c, dCtx.control)
 	}
 	return c, err
 }
@@ -362,7 +379,7 @@ func (f *Fs) putFtpConnection(pc **ftp.ServerConn, err error) {
 			nopErr := c.NoOp()
 			if nopErr != nil {
 				fs.Debugf(f, "Connection failed, closing: %v", nopErr)
-				_ = c.Quit()
+				_ = f.quit(c)
 				return
 			}
 		}
@@ -386,7 +403,7 @@ func (f *Fs) drainPool(ctx context.Context) (err error) {
 		fs.Debugf(f, "closing %d unused connections", len(f.pool))
 	}
 	for i, c := range f.pool {
-		if cErr := c.Quit(); cErr != nil {
+		if cErr := f.quit(c); cErr != nil {
 			err = cErr
 		}
 		f.pool[i] = nil
@@ -459,6 +476,7 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (ff fs.Fs
 	if err != nil {
 		return nil, errors.Wrap(err, "NewFs")
 	}
+	f.probeFeatures(c)
 	f.putFtpConnection(&c, nil)
 	if root != "" {
 		// Check to see if the root actually an existing file
@@ -581,6 +599,7 @@ func (f *Fs) NewObject(ctx context.Context, remote string) (o fs.Object, err err
 			Name:    remote,
 			Size:    entry.Size,
 			ModTime: entry.Time,
+			precise: f.fLstTime,
 		}
 		o.info = info
 
@@ -678,6 +697,7 @@ func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err e
 				Name:    newremote,
 				Size:    object.Size,
 				ModTime: object.Time,
+				precise: f.fLstTime,
 			}
 			o.info = info
 			entries = append(entries, o)
@@ -691,8 +711,14 @@ func (f *Fs) Hashes() hash.Set {
 	return 0
 }
 
-// Precision shows Modified Time not supported
+// Precision returns the precision of the modification times
+//
+// Modification times are only supported if the server can set them
+// with MFMT and read them precisely with MLSD or MDTM.
 func (f *Fs) Precision() time.Duration {
+	if f.fSetTime && (f.fLstTime || f.fGetTime) {
+		return time.Second
+	}
 	return fs.ModTimeNotSupported
 }
 
@@ -744,6 +770,7 @@ func (f *Fs) getInfo(ctx context.Context, remote string) (fi *FileInfo, err erro
 				Name:    remote,
 				Size:    file.Size,
 				ModTime: file.Time,
+				precise: f.fLstTime,
 				IsDir:   file.Type == ftp.EntryTypeFolder,
 			}
 			return info, nil
@@ -928,12 +955,46 @@ func (o *Object) Size() int64 {
 }
 
 // ModTime returns the modification time of the object
+//
+// If the time from the listing isn't precise then it is read with
+// MDTM if the server supports it.
 func (o *Object) ModTime(ctx context.Context) time.Time {
+	if !o.info.precise && o.fs.fGetTime {
+		c, err := o.fs.getFtpConnection(ctx)
+		if err != nil {
+			fs.Debugf(o, "Failed to read precise modification time: %v", err)
+			return o.info.ModTime
+		}
+		modTime, err := o.fs.getTime(c, o.fs.opt.Enc.FromStandardPath(path.Join(o.fs.root, o.remote)))
+		o.fs.putFtpConnection(&c, err)
+		if err != nil {
+			fs.Debugf(o, "Failed to read precise modification time: %v", err)
+			return o.info.ModTime
+		}
+		o.info.ModTime = modTime
+		o.info.precise = true
+	}
 	return o.info.ModTime
 }
 
 // SetModTime sets the modification time of the object
+//
+// This uses MFMT if the server supports it and does nothing otherwise.
 func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	if !o.fs.fSetTime {
+		return nil
+	}
+	c, err := o.fs.getFtpConnection(ctx)
+	if err != nil {
+		return errors.Wrap(err, "SetModTime")
+	}
+	err = o.fs.setTime(c, o.fs.opt.Enc.FromStandardPath(path.Join(o.fs.root, o.remote)), modTime)
+	o.fs.putFtpConnection(&c, err)
+	if err != nil {
+		return errors.Wrap(err, "SetModTime")
+	}
+	o.info.ModTime = modTime.Truncate(time.Second)
+	o.info.precise = true
 	return nil
 }
 
@@ -979,7 +1040,7 @@ func (f *ftpReadCloser) Close() error {
 	}
 	// if errors while reading or closing, dump the connection
 	if err != nil || f.err != nil {
-		_ = f.c.Quit()
+		_ = f.f.quit(f.c)
 		f.f.putFtpConnection(nil, nil)
 	} else {
 		f.f.putFtpConnection(&f.c, nil)
@@ -1055,7 +1116,7 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	}
 	err = c.Stor(o.fs.opt.Enc.FromStandardPath(path), in)
 	if err != nil {
-		_ = c.Quit() // toss this connection to avoid sync errors
+		_ = o.fs.quit(c) // toss this connection to avoid sync errors
 		remove()
 		o.fs.putFtpConnection(nil, err)
 		return errors.Wrap(err, "update stor")
@@ -1065,6 +1126,12 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	if err != nil {
 		return errors.Wrap(err, "update getinfo")
 	}
+	if o.fs.fSetTime {
+		err = o.SetModTime(ctx, src.ModTime(ctx))
+		if err != nil {
+			return errors.Wrap(err, "update")
+		}
+	}
 	return nil
 }
 
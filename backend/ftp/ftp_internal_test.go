@@ -0,0 +1,84 @@
+package ftp
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/jlaffaye/ftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFeatures(t *testing.T) {
+	features := parseFeatures("Extensions supported:\n MDTM\n MFMT\n MLST type*;size*;modify*;\n SIZE\nEnd")
+	assert.Equal(t, map[string]bool{"MDTM": true, "MFMT": true, "MLST": true, "SIZE": true}, features)
+}
+
+// newTestFs returns an Fs with a control connection for c which is
+// served by replying with replies in turn, returning the commands
+// received on commands
+func newTestFs(t *testing.T, c *ftp.ServerConn, replies ...string) (*Fs, <-chan string) {
+	client, server := net.Pipe()
+	commands := make(chan string, len(replies))
+	go func() {
+		defer func() { _ = server.Close() }()
+		in := bufio.NewReader(server)
+		for _, reply := range replies {
+			command, err := in.ReadString('\n')
+			if err != nil {
+				return
+			}
+			commands <- strings.TrimSuffix(command, "\r\n")
+			_, _ = server.Write([]byte(reply))
+		}
+	}()
+	t.Cleanup(func() { _ = client.Close() })
+	f := &Fs{ci: fs.GetConfig(context.Background())}
+	f.setControlConn(c, client)
+	return f, commands
+}
+
+func TestProbeFeatures(t *testing.T) {
+	c := &ftp.ServerConn{}
+	f, commands := newTestFs(t, c, "211-Extensions supported:\r\n MDTM\r\n MFMT\r\n MLST modify*;\r\n211 End\r\n")
+	f.probeFeatures(c)
+	assert.Equal(t, "FEAT", <-commands)
+	assert.True(t, f.fGetTime)
+	assert.True(t, f.fSetTime)
+	assert.True(t, f.fLstTime)
+	assert.Equal(t, time.Second, f.Precision())
+
+	// Without a control connection nothing is supported
+	f = &Fs{ci: fs.GetConfig(context.Background())}
+	f.probeFeatures(c)
+	assert.False(t, f.fGetTime)
+	assert.Equal(t, fs.ModTimeNotSupported, f.Precision())
+}
+
+func TestGetSetTime(t *testing.T) {
+	c := &ftp.ServerConn{}
+	f, commands := newTestFs(t, c,
+		"213 20210315123456.789\r\n",
+		"213 Modify=20200102030405; /dir/file.txt\r\n",
+		"550 Permission denied\r\n",
+	)
+
+	modTime, err := f.getTime(c, "/dir/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "MDTM /dir/file.txt", <-commands)
+	assert.Equal(t, time.Date(2021, 3, 15, 12, 34, 56, 0, time.UTC), modTime)
+
+	err = f.setTime(c, "/dir/file.txt", time.Date(2020, 1, 2, 4, 4, 5, 0, time.FixedZone("CET", 3600)))
+	require.NoError(t, err)
+	assert.Equal(t, "MFMT 20200102030405 /dir/file.txt", <-commands)
+
+	err = f.setTime(c, "/dir/file.txt", time.Now())
+	require.Error(t, err)
+	assert.Equal(t, 550, err.(*textproto.Error).Code)
+}
@@ -0,0 +1,181 @@
+package ftp
+
+// The FTP library doesn't have methods for the FEAT, MDTM and MFMT
+// commands so these are sent directly on the control connection while
+// it isn't being used by the library.
+
+import (
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/jlaffaye/ftp"
+	"github.com/pkg/errors"
+)
+
+// timeFormat is the format of the times used by MDTM and MFMT
+const timeFormat = "20060102150405"
+
+// setControlConn records the control connection of c so raw commands
+// can be sent on it
+func (f *Fs) setControlConn(c *ftp.ServerConn, conn net.Conn) {
+	f.controlMu.Lock()
+	defer f.controlMu.Unlock()
+	if f.controlConns == nil {
+		f.controlConns = make(map[*ftp.ServerConn]net.Conn)
+	}
+	f.controlConns[c] = conn
+}
+
+// quit closes c forgetting its control connection
+func (f *Fs) quit(c *ftp.ServerConn) error {
+	f.controlMu.Lock()
+	delete(f.controlConns, c)
+	f.controlMu.Unlock()
+	return c.Quit()
+}
+
+// rawCommand sends a command on the control connection of c and reads
+// the reply, returning a *textproto.Error if its code isn't expected.
+//
+// c must not be in use by anything else.
+func (f *Fs) rawCommand(c *ftp.ServerConn, expected int, format string, args ...interface{}) (msg string, err error) {
+	f.controlMu.Lock()
+	conn := f.controlConns[c]
+	f.controlMu.Unlock()
+	if conn == nil {
+		return "", errors.New("can't send commands directly on this FTP connection")
+	}
+	command := fmt.Sprintf(format, args...)
+	if f.ci.Dump&(fs.DumpHeaders|fs.DumpBodies|fs.DumpRequests|fs.DumpResponses) != 0 {
+		fs.Debugf("FTP Tx", "%q", command)
+	}
+	_, err = conn.Write([]byte(command + "\r\n"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to send FTP command")
+	}
+	code, msg, err := readReply(conn)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read FTP reply")
+	}
+	if f.ci.Dump&(fs.DumpHeaders|fs.DumpBodies|fs.DumpRequests|fs.DumpResponses) != 0 {
+		fs.Debugf("FTP Rx", "%d %q", code, msg)
+	}
+	if code != expected {
+		return "", &textproto.Error{Code: code, Msg: msg}
+	}
+	return msg, nil
+}
+
+// readLine reads a line ending in "\n" from conn a byte at a time so
+// nothing after it is consumed, returning it without the line ending
+func readLine(conn net.Conn) (string, error) {
+	var (
+		line []byte
+		b    [1]byte
+	)
+	for {
+		n, err := conn.Read(b[:])
+		if n > 0 {
+			if b[0] == '\n' {
+				return strings.TrimSuffix(string(line), "\r"), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// readReply reads a possibly multi-line reply from conn returning
+// its code and the message with the codes removed
+func readReply(conn net.Conn) (code int, msg string, err error) {
+	line, err := readLine(conn)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(line) < 4 || (line[3] != ' ' && line[3] != '-') {
+		return 0, "", errors.Errorf("invalid FTP reply %q", line)
+	}
+	code, err = strconv.Atoi(line[:3])
+	if err != nil {
+		return 0, "", errors.Errorf("invalid FTP reply %q", line)
+	}
+	msg = line[4:]
+	if line[3] == ' ' {
+		return code, msg, nil
+	}
+	// Multi-line reply which ends with the code followed by a space
+	end := line[:3] + " "
+	for {
+		line, err = readLine(conn)
+		if err != nil {
+			return 0, "", err
+		}
+		if strings.HasPrefix(line, end) {
+			return code, msg + "\n" + line[4:], nil
+		}
+		msg += "\n" + line
+	}
+}
+
+// parseFeatures parses the reply to FEAT returning the features the
+// server supports in upper case
+func parseFeatures(msg string) map[string]bool {
+	features := make(map[string]bool)
+	for _, line := range strings.Split(msg, "\n") {
+		// Features are indented by a space
+		if !strings.HasPrefix(line, " ") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			features[strings.ToUpper(fields[0])] = true
+		}
+	}
+	return features
+}
+
+// probeFeatures reads the features of the server with FEAT to find
+// out whether modification times can be read and set
+func (f *Fs) probeFeatures(c *ftp.ServerConn) {
+	msg, err := f.rawCommand(c, ftp.StatusSystem, "FEAT")
+	if err != nil {
+		fs.Debugf(f, "Not using MDTM or MFMT: %v", err)
+		return
+	}
+	features := parseFeatures(msg)
+	f.fGetTime = features["MDTM"]
+	f.fSetTime = features["MFMT"]
+	f.fLstTime = features["MLST"] && !f.opt.DisableMLSD
+	fs.Debugf(f, "Server supports MDTM %v, MFMT %v, MLSD %v", f.fGetTime, f.fSetTime, f.fLstTime)
+}
+
+// getTime reads the modification time of path with MDTM
+func (f *Fs) getTime(c *ftp.ServerConn, path string) (time.Time, error) {
+	msg, err := f.rawCommand(c, ftp.StatusFile, "MDTM %s", path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// The time may have fractional seconds after a "."
+	msg = strings.TrimSpace(msg)
+	if i := strings.IndexByte(msg, '.'); i >= 0 {
+		msg = msg[:i]
+	}
+	t, err := time.ParseInLocation(timeFormat, msg, time.UTC)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to parse MDTM reply")
+	}
+	return t, nil
+}
+
+// setTime sets the modification time of path with MFMT
+func (f *Fs) setTime(c *ftp.ServerConn, path string, t time.Time) error {
+	_, err := f.rawCommand(c, ftp.StatusFile, "MFMT %s %s", t.UTC().Format(timeFormat), path)
+	return err
+}
@@ -249,6 +249,21 @@ Docs: https://cloud.google.com/storage/docs/bucket-policy-only
 				Value: "DURABLE_REDUCED_AVAILABILITY",
 				Help:  "Durable reduced availability storage class",
 			}},
+		}, {
+			Name: "kms_key_name",
+			Help: `Cloud KMS key to encrypt new objects with.
+
+Set this to use a customer-managed encryption key (CMEK) for objects
+which are uploaded, copied or modified instead of the bucket's default
+encryption. It is the resource name of the key, like
+
+    projects/PROJECT/locations/LOCATION/keyRings/RING/cryptoKeys/KEY
+
+The Cloud Storage service account of the project must be allowed to
+use the key.
+
+Docs: https://cloud.google.com/storage/docs/encryption/customer-managed-keys`,
+			Advanced: true,
 		}, {
 			Name:     config.ConfigEncoding,
 			Help:     config.ConfigEncodingHelp,
@@ -256,7 +271,7 @@ Docs: https://cloud.google.com/storage/docs/bucket-policy-only
 			Default: (encoder.Base |
 				encoder.EncodeCrLf |
 				encoder.EncodeInvalidUtf8),
-		}}...),
+		}, fs.ProxyOption, fs.APIBudgetOption}...),
 	})
 }
 
@@ -271,6 +286,7 @@ type Options struct {
 	BucketPolicyOnly          bool                 `config:"bucket_policy_only"`
 	Location                  string               `config:"location"`
 	StorageClass              string               `config:"storage_class"`
+	KMSKeyName                string               `config:"kms_key_name"`
 	Enc                       encoder.MultiEncoder `config:"encoding"`
 }
 
@@ -292,13 +308,14 @@ type Fs struct {
 //
 // Will definitely have info but maybe not meta
 type Object struct {
-	fs       *Fs       // what this object is part of
-	remote   string    // The remote path
-	url      string    // download path
-	md5sum   string    // The MD5Sum of the object
-	bytes    int64     // Bytes in the object
-	modTime  time.Time // Modified time of the object
-	mimeType string
+	fs           *Fs       // what this object is part of
+	remote       string    // The remote path
+	url          string    // download path
+	md5sum       string    // The MD5Sum of the object
+	bytes        int64     // Bytes in the object
+	modTime      time.Time // Modified time of the object
+	mimeType     string
+	storageClass string // e.g. NEARLINE
 }
 
 // ------------------------------------------------------------
@@ -405,6 +422,10 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	if opt.BucketACL == "" {
 		opt.BucketACL = "private"
 	}
+	err = checkKMSKeyName(opt.KMSKeyName)
+	if err != nil {
+		return nil, err
+	}
 
 	// try loading service account credentials from env variable, then from a file
 	if opt.ServiceAccountCredentials == "" && opt.ServiceAccountFile != "" {
@@ -445,6 +466,8 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		WriteMimeType:     true,
 		BucketBased:       true,
 		BucketBasedRootOK: true,
+		SetTier:           true,
+		GetTier:           true,
 	}).Fill(ctx, f)
 
 	// Create a new authorized Drive client.
@@ -474,6 +497,24 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	return f, nil
 }
 
+// checkKMSKeyName checks the kms_key_name option looks like the
+// resource name of a Cloud KMS key
+func checkKMSKeyName(name string) error {
+	if name == "" {
+		return nil
+	}
+	parts := strings.Split(name, "/")
+	if len(parts) != 8 || parts[0] != "projects" || parts[2] != "locations" || parts[4] != "keyRings" || parts[6] != "cryptoKeys" {
+		return errors.Errorf("kms_key_name %q should look like projects/PROJECT/locations/LOCATION/keyRings/RING/cryptoKeys/KEY", name)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return errors.Errorf("kms_key_name %q has an empty part", name)
+		}
+	}
+	return nil
+}
+
 // Return an Object from a path
 //
 // If it can't be found it returns the error fs.ErrorObjectNotFound.
@@ -826,7 +867,7 @@ func (f *Fs) Precision() time.Duration {
 //
 // If it isn't possible then return fs.ErrorCantCopy
 func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
-	dstBucket, dstPath := f.split(remote)
+	dstBucket, _ := f.split(remote)
 	err := f.makeBucket(ctx, dstBucket)
 	if err != nil {
 		return nil, err
@@ -844,28 +885,53 @@ func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object,
 		remote: remote,
 	}
 
-	rewriteRequest := f.svc.Objects.Rewrite(srcBucket, srcPath, dstBucket, dstPath, nil)
-	if !f.opt.BucketPolicyOnly {
-		rewriteRequest.DestinationPredefinedAcl(f.opt.ObjectACL)
+	// Without an object the destination gets the source metadata
+	// and the default storage class of the bucket
+	var object *storage.Object
+	if f.opt.StorageClass != "" {
+		object, err = srcObj.readObjectInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		object.StorageClass = f.opt.StorageClass
+	}
+	err = dstObj.rewrite(ctx, srcBucket, srcPath, object)
+	if err != nil {
+		return nil, err
+	}
+	return dstObj, nil
+}
+
+// rewrite server-side copies srcBucket/srcPath to o using object as
+// the metadata if it isn't nil, setting the metadata of o from the
+// result.
+func (o *Object) rewrite(ctx context.Context, srcBucket, srcPath string, object *storage.Object) (err error) {
+	dstBucket, dstPath := o.split()
+	rewriteRequest := o.fs.svc.Objects.Rewrite(srcBucket, srcPath, dstBucket, dstPath, object)
+	if !o.fs.opt.BucketPolicyOnly {
+		rewriteRequest.DestinationPredefinedAcl(o.fs.opt.ObjectACL)
+	}
+	if o.fs.opt.KMSKeyName != "" {
+		rewriteRequest.DestinationKmsKeyName(o.fs.opt.KMSKeyName)
 	}
 	var rewriteResponse *storage.RewriteResponse
 	for {
-		err = f.pacer.Call(func() (bool, error) {
+		err = o.fs.pacer.Call(func() (bool, error) {
 			rewriteResponse, err = rewriteRequest.Context(ctx).Do()
 			return shouldRetry(ctx, err)
 		})
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if rewriteResponse.Done {
 			break
 		}
 		rewriteRequest.RewriteToken(rewriteResponse.RewriteToken)
-		fs.Debugf(dstObj, "Continuing rewrite %d bytes done", rewriteResponse.TotalBytesRewritten)
+		fs.Debugf(o, "Continuing rewrite %d bytes done", rewriteResponse.TotalBytesRewritten)
 	}
 	// Set the metadata for the new object while we have it
-	dstObj.setMetaData(rewriteResponse.Resource)
-	return dstObj, nil
+	o.setMetaData(rewriteResponse.Resource)
+	return nil
 }
 
 // Hashes returns the supported hash sets.
@@ -911,6 +977,7 @@ func (o *Object) setMetaData(info *storage.Object) {
 	o.url = info.MediaLink
 	o.bytes = int64(info.Size)
 	o.mimeType = info.ContentType
+	o.storageClass = info.StorageClass
 
 	// Read md5sum
 	md5sumData, err := base64.StdEncoding.DecodeString(info.Md5Hash)
@@ -1015,6 +1082,9 @@ func (o *Object) SetModTime(ctx context.Context, modTime time.Time) (err error)
 		if !o.fs.opt.BucketPolicyOnly {
 			copyObject.DestinationPredefinedAcl(o.fs.opt.ObjectACL)
 		}
+		if o.fs.opt.KMSKeyName != "" {
+			copyObject.DestinationKmsKeyName(o.fs.opt.KMSKeyName)
+		}
 		newObject, err = copyObject.Context(ctx).Do()
 		return shouldRetry(ctx, err)
 	})
@@ -1072,10 +1142,11 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	modTime := src.ModTime(ctx)
 
 	object := storage.Object{
-		Bucket:      bucket,
-		Name:        bucketPath,
-		ContentType: fs.MimeType(ctx, src),
-		Metadata:    metadataFromModTime(modTime),
+		Bucket:       bucket,
+		Name:         bucketPath,
+		ContentType:  fs.MimeType(ctx, src),
+		Metadata:     metadataFromModTime(modTime),
+		StorageClass: o.fs.opt.StorageClass,
 	}
 	// Apply upload options
 	for _, option := range options {
@@ -1112,6 +1183,9 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 		if !o.fs.opt.BucketPolicyOnly {
 			insertObject.PredefinedAcl(o.fs.opt.ObjectACL)
 		}
+		if o.fs.opt.KMSKeyName != "" {
+			insertObject.KmsKeyName(o.fs.opt.KMSKeyName)
+		}
 		newObject, err = insertObject.Context(ctx).Do()
 		return shouldRetry(ctx, err)
 	})
@@ -1138,6 +1212,27 @@ func (o *Object) MimeType(ctx context.Context) string {
 	return o.mimeType
 }
 
+// SetTier changes the storage class of the object by rewriting it
+func (o *Object) SetTier(tier string) (err error) {
+	ctx := context.TODO()
+	tier = strings.ToUpper(tier)
+	object, err := o.readObjectInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if object.StorageClass == tier {
+		return nil
+	}
+	object.StorageClass = tier
+	bucket, bucketPath := o.split()
+	return o.rewrite(ctx, bucket, bucketPath, object)
+}
+
+// GetTier returns the storage class of the object
+func (o *Object) GetTier() string {
+	return o.storageClass
+}
+
 // Check the interfaces are satisfied
 var (
 	_ fs.Fs          = &Fs{}
@@ -1146,4 +1241,6 @@ var (
 	_ fs.ListRer     = &Fs{}
 	_ fs.Object      = &Object{}
 	_ fs.MimeTyper   = &Object{}
+	_ fs.GetTierer   = &Object{}
+	_ fs.SetTierer   = &Object{}
 )
@@ -0,0 +1,24 @@
+package googlecloudstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckKMSKeyName(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		wantErr bool
+	}{
+		{"", false},
+		{"projects/p/locations/europe-west2/keyRings/ring/cryptoKeys/key", false},
+		{"projects/p/locations/europe-west2/keyRings/ring/cryptoKeys/key/cryptoKeyVersions/1", true},
+		{"projects/p/locations/europe-west2/keyRings/ring/cryptoKeys/", true},
+		{"projects/p/locations//keyRings/ring/cryptoKeys/key", true},
+		{"key", true},
+	} {
+		err := checkKMSKeyName(test.in)
+		assert.Equal(t, test.wantErr, err != nil, test.in)
+	}
+}
@@ -12,7 +12,8 @@ import (
 // TestIntegration runs integration tests against the remote
 func TestIntegration(t *testing.T) {
 	fstests.Run(t, &fstests.Opt{
-		RemoteName: "TestGoogleCloudStorage:",
-		NilObject:  (*googlecloudstorage.Object)(nil),
+		RemoteName:  "TestGoogleCloudStorage:",
+		NilObject:   (*googlecloudstorage.Object)(nil),
+		TiersToTest: []string{"STANDARD", "NEARLINE"},
 	})
 }
@@ -150,7 +150,7 @@ listings and transferred.
 Without this flag, archived media will not be visible in directory
 listings and won't be transferred.`,
 			Advanced: true,
-		}}...),
+		}, fs.ProxyOption, fs.APIBudgetOption}...),
 	})
 }
 
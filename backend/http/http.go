@@ -98,7 +98,7 @@ If you set this option, rclone will not do the HEAD request.  This will mean
 `,
 			Default:  false,
 			Advanced: true,
-		}},
+		}, fs.ProxyOption},
 	}
 	fs.Register(fsi)
 }
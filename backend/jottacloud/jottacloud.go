@@ -3,9 +3,7 @@ package jottacloud
 import (
 	"bytes"
 	"context"
-	"crypto/md5"
 	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -35,6 +33,7 @@ import (
 	"github.com/artpar/rclone/lib/encoder"
 	"github.com/artpar/rclone/lib/oauthutil"
 	"github.com/artpar/rclone/lib/pacer"
+	"github.com/artpar/rclone/lib/readers"
 	"github.com/artpar/rclone/lib/rest"
 	"golang.org/x/oauth2"
 )
@@ -154,7 +153,7 @@ func init() {
 			Default: (encoder.Display |
 				encoder.EncodeWin | // :?"*<>|
 				encoder.EncodeInvalidUtf8),
-		}},
+		}, fs.ProxyOption, fs.APIBudgetOption},
 	})
 }
 
@@ -1471,14 +1470,15 @@ func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.Read
 // The cleanup function should be called when out is finished with
 // regardless of whether this function returned an error or not.
 func readMD5(in io.Reader, size, threshold int64) (md5sum string, out io.Reader, cleanup func(), err error) {
-	// we need an MD5
-	md5Hasher := md5.New()
-	// use the teeReader to write to the local file AND calculate the MD5 while doing so
-	teeReader := io.TeeReader(in, md5Hasher)
-
 	// nothing to clean up by default
 	cleanup = func() {}
 
+	// use the hashingIn to write to the local file AND calculate the MD5 while doing so
+	hashingIn, err := readers.NewHashingReader(in, hash.NewHashSet(hash.MD5))
+	if err != nil {
+		return
+	}
+
 	// don't cache small files on disk to reduce wear of the disk
 	if size > threshold {
 		var tempFile *os.File
@@ -1499,7 +1499,7 @@ func readMD5(in io.Reader, size, threshold int64) (md5sum string, out io.Reader,
 		}
 
 		// copy the ENTIRE file to disc and calculate the MD5 in the process
-		if _, err = io.Copy(tempFile, teeReader); err != nil {
+		if _, err = io.Copy(tempFile, hashingIn); err != nil {
 			return
 		}
 		// jump to the start of the local file so we can pass it along
@@ -1512,7 +1512,7 @@ func readMD5(in io.Reader, size, threshold int64) (md5sum string, out io.Reader,
 	} else {
 		// that's a small file, just read it into memory
 		var inData []byte
-		inData, err = ioutil.ReadAll(teeReader)
+		inData, err = ioutil.ReadAll(hashingIn)
 		if err != nil {
 			return
 		}
@@ -1520,7 +1520,7 @@ func readMD5(in io.Reader, size, threshold int64) (md5sum string, out io.Reader,
 		// set the reader to our read memory block
 		out = bytes.NewReader(inData)
 	}
-	return hex.EncodeToString(md5Hasher.Sum(nil)), out, cleanup, nil
+	return hashingIn.Sums()[hash.MD5], out, cleanup, nil
 }
 
 // Update the object with the contents of the io.Reader, modTime and size
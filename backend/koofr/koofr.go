@@ -65,7 +65,7 @@ func init() {
 			Default: (encoder.Display |
 				encoder.EncodeBackSlash |
 				encoder.EncodeInvalidUtf8),
-		}},
+		}, fs.ProxyOption},
 	})
 }
 
@@ -480,7 +480,14 @@ func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 }
 
 // Copy copies a remote Object to the given path
+//
+// The copy is done by Koofr so the data isn't downloaded.
 func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		fs.Debugf(src, "Can't copy - not same remote type")
+		return nil, fs.ErrorCantCopy
+	}
 	dstFullPath := f.fullPath(remote)
 	dstDir := dir(dstFullPath)
 	err := f.mkdir(dstDir)
@@ -488,10 +495,11 @@ func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object,
 		return nil, fs.ErrorCantCopy
 	}
 	mtime := src.ModTime(ctx).UnixNano() / 1000 / 1000
-	err = f.client.FilesCopy((src.(*Object)).fs.mountID,
-		(src.(*Object)).fs.fullPath((src.(*Object)).remote),
+	err = f.client.FilesCopy(srcObj.fs.mountID,
+		srcObj.fs.fullPath(srcObj.remote),
 		f.mountID, dstFullPath, koofrclient.CopyOptions{SetModified: &mtime})
 	if err != nil {
+		fs.Debugf(src, "Can't copy: %v", err)
 		return nil, fs.ErrorCantCopy
 	}
 	return f.NewObject(ctx, remote)
@@ -578,6 +586,43 @@ type link struct {
 	PasswordRequired bool   `json:"passwordRequired"`
 }
 
+// linkList is a Koofr API response to listing the public links
+type linkList struct {
+	Links []link `json:"links"`
+}
+
+// listLinks makes a Koofr API call to list the public links of a mount
+func listLinks(c *koofrclient.KoofrClient, mountID string) ([]link, error) {
+	linkData := linkList{}
+
+	request := httpclient.RequestData{
+		Method:         "GET",
+		Path:           "/api/v2/mounts/" + mountID + "/links",
+		ExpectedStatus: []int{http.StatusOK},
+		RespEncoding:   httpclient.EncodingJSON,
+		RespValue:      &linkData,
+	}
+
+	_, err := c.Request(&request)
+	if err != nil {
+		return nil, err
+	}
+	return linkData.Links, nil
+}
+
+// deleteLink makes a Koofr API call to remove a public link
+func deleteLink(c *koofrclient.KoofrClient, mountID string, linkID string) error {
+	request := httpclient.RequestData{
+		Method:         "DELETE",
+		Path:           "/api/v2/mounts/" + mountID + "/links/" + linkID,
+		ExpectedStatus: []int{http.StatusOK, http.StatusNoContent},
+		RespConsume:    true,
+	}
+
+	_, err := c.Request(&request)
+	return err
+}
+
 // createLink makes a Koofr API call to create a public link
 func createLink(c *koofrclient.KoofrClient, mountID string, path string) (*link, error) {
 	linkCreate := linkCreate{
@@ -602,9 +647,39 @@ func createLink(c *koofrclient.KoofrClient, mountID string, path string) (*link,
 	return &linkData, nil
 }
 
-// PublicLink creates a public link to the remote path
+// PublicLink creates a public link to the remote path, returning an
+// existing one if there is one.
+//
+// If unlink is set then all the public links to the path are removed.
 func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration, unlink bool) (string, error) {
-	linkData, err := createLink(f.client, f.mountID, f.fullPath(remote))
+	fullPath := f.fullPath(remote)
+	existing, err := listLinks(f.client, f.mountID)
+	if err != nil {
+		return "", translateErrorsDir(err)
+	}
+	if unlink {
+		removed := 0
+		for _, linkData := range existing {
+			if linkData.Path != fullPath {
+				continue
+			}
+			err = deleteLink(f.client, f.mountID, linkData.ID)
+			if err != nil {
+				return "", err
+			}
+			removed++
+		}
+		if removed == 0 {
+			return "", errors.New("no public link found to remove")
+		}
+		return "", nil
+	}
+	for _, linkData := range existing {
+		if linkData.Path == fullPath {
+			return linkData.ShortURL, nil
+		}
+	}
+	linkData, err := createLink(f.client, f.mountID, fullPath)
 	if err != nil {
 		return "", translateErrorsDir(err)
 	}
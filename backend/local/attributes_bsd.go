@@ -0,0 +1,25 @@
+// File attribute reading functions
+
+// +build darwin freebsd
+
+package local
+
+import (
+	"os"
+	"syscall"
+)
+
+// ufHidden is the UF_HIDDEN file flag set with "chflags hidden"
+const ufHidden = 0x8000
+
+// readAttributes returns whether the file has the hidden and system
+// attributes set.
+//
+// Only the hidden flag is supported on this OS.
+func readAttributes(fi os.FileInfo) (hidden, system bool) {
+	statT, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false, false
+	}
+	return statT.Flags&ufHidden != 0, false
+}
@@ -0,0 +1,15 @@
+// File attribute reading functions
+
+// +build !windows,!darwin,!freebsd
+
+package local
+
+import "os"
+
+// readAttributes returns whether the file has the hidden and system
+// attributes set.
+//
+// This OS doesn't have these attributes so it always returns false.
+func readAttributes(fi os.FileInfo) (hidden, system bool) {
+	return false, false
+}
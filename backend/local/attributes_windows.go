@@ -0,0 +1,20 @@
+// File attribute reading functions
+
+// +build windows
+
+package local
+
+import (
+	"os"
+	"syscall"
+)
+
+// readAttributes returns whether the file has the hidden and system
+// attributes set.
+func readAttributes(fi os.FileInfo) (hidden, system bool) {
+	attr, ok := fi.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return false, false
+	}
+	return attr.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0, attr.FileAttributes&syscall.FILE_ATTRIBUTE_SYSTEM != 0
+}
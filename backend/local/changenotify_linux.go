@@ -0,0 +1,204 @@
+// Change notification using inotify
+
+// +build linux
+
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask is the events watched for in each directory
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_CLOSE_WRITE | unix.IN_ATTRIB |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ONLYDIR | unix.IN_DONT_FOLLOW
+
+// watcher watches the directories under the root of an Fs with
+// inotify calling notifyFunc with the changes
+type watcher struct {
+	f          *Fs
+	notifyFunc func(string, fs.EntryType)
+	fd         int      // the inotify instance
+	in         *os.File // fd for reading
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	dirs       map[int]string // remote directory for each watch descriptor
+	full       bool           // set if we have run out of watches
+	closed     bool           // set if the watcher has been closed
+}
+
+// newWatcher starts watching the directories under the root of f
+func newWatcher(f *Fs, notifyFunc func(string, fs.EntryType)) (*watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start inotify")
+	}
+	w := &watcher{
+		f:          f,
+		notifyFunc: notifyFunc,
+		fd:         fd,
+		in:         os.NewFile(uintptr(fd), "inotify"),
+		dirs:       map[int]string{},
+	}
+	w.addTree(f.root)
+	fs.Debugf(f, "Watching %d directories for changes", len(w.dirs))
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+// remote returns the remote for the OS path p under the root
+func (w *watcher) remote(p string) string {
+	rel, err := filepath.Rel(w.f.root, p)
+	if err != nil || rel == "." {
+		return ""
+	}
+	return w.f.opt.Enc.ToStandardPath(filepath.ToSlash(rel))
+}
+
+// addTree adds watches for the directory at the OS path root and all
+// the directories under it
+func (w *watcher) addTree(root string) {
+	_ = filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil || !fi.IsDir() {
+			return nil
+		}
+		if w.f.dev != devUnset && readDevice(fi, w.f.opt.OneFileSystem) != w.f.dev {
+			return filepath.SkipDir
+		}
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.closed {
+			return errors.New("watcher closed")
+		}
+		if w.full {
+			return filepath.SkipDir
+		}
+		wd, err := unix.InotifyAddWatch(w.fd, p, inotifyMask)
+		if err == unix.ENOSPC {
+			w.full = true
+			fs.Errorf(w.f, "Not watching all directories for changes as out of inotify watches - raise the fs.inotify.max_user_watches sysctl")
+			return filepath.SkipDir
+		} else if err != nil {
+			fs.Debugf(w.f, "Failed to watch %q for changes: %v", p, err)
+			return nil
+		}
+		w.dirs[wd] = w.remote(p)
+		return nil
+	})
+}
+
+// run reads the events from inotify until it is closed
+func (w *watcher) run() {
+	defer w.wg.Done()
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := w.in.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				fs.Errorf(w.f, "Stopped watching for changes: %v", err)
+			}
+			return
+		}
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			offset = nameStart + int(event.Len)
+			name := strings.TrimRight(string(buf[nameStart:offset]), "\x00")
+			w.event(int(event.Wd), event.Mask, name)
+		}
+	}
+}
+
+// event processes a single inotify event
+func (w *watcher) event(wd int, mask uint32, name string) {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		// Events were lost so everything may have changed
+		w.notifyFunc("", fs.EntryDirectory)
+		return
+	}
+	w.mu.Lock()
+	dir, ok := w.dirs[wd]
+	if mask&unix.IN_IGNORED != 0 {
+		// The directory was removed so the watch was too
+		delete(w.dirs, wd)
+		w.full = false
+	}
+	w.mu.Unlock()
+	if !ok || name == "" {
+		return
+	}
+	remote := w.f.cleanRemote(dir, name)
+	if mask&unix.IN_ISDIR != 0 {
+		if mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+			w.addTree(w.f.localPath(remote))
+		}
+		w.notifyFunc(remote, fs.EntryDirectory)
+		return
+	}
+	w.notifyFunc(remote, fs.EntryObject)
+}
+
+// close stops watching for changes
+func (w *watcher) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	_ = w.in.Close()
+	w.wg.Wait()
+}
+
+// ChangeNotify calls the passed function with a path that has had
+// changes.
+//
+// This uses inotify to watch the directories under the root so
+// changes are notified as soon as they happen rather than every poll
+// interval. A poll interval of 0 stops watching.
+//
+// Close the returned channel to stop being notified.
+func (f *Fs) ChangeNotify(ctx context.Context, notifyFunc func(string, fs.EntryType), pollIntervalChan <-chan time.Duration) {
+	go func() {
+		var w *watcher
+		stop := func() {
+			if w != nil {
+				w.close()
+				w = nil
+			}
+		}
+		for {
+			select {
+			case pollInterval, ok := <-pollIntervalChan:
+				if !ok {
+					stop()
+					return
+				}
+				if pollInterval == 0 {
+					stop()
+				} else if w == nil {
+					var err error
+					w, err = newWatcher(f, notifyFunc)
+					if err != nil {
+						fs.Errorf(f, "Failed to watch for changes: %v", err)
+					}
+				}
+			case <-ctx.Done():
+				stop()
+				return
+			}
+		}
+	}()
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.ChangeNotifier = &Fs{}
+)
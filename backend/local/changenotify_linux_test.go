@@ -0,0 +1,92 @@
+// +build linux
+
+package local
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config/configmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeNotify(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir, err := ioutil.TempDir("", "rclone-change-notify")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0777))
+
+	// ChangeNotify is only available if enabled
+	f, err := NewFs(ctx, "local", dir, configmap.Simple{})
+	require.NoError(t, err)
+	assert.Nil(t, f.Features().ChangeNotify)
+	f, err = NewFs(ctx, "local", dir, configmap.Simple{"change_notify": "true"})
+	require.NoError(t, err)
+	require.NotNil(t, f.Features().ChangeNotify)
+
+	type change struct {
+		remote    string
+		entryType fs.EntryType
+	}
+	changes := make(chan change, 100)
+	pollInterval := make(chan time.Duration)
+	f.Features().ChangeNotify(ctx, func(remote string, entryType fs.EntryType) {
+		changes <- change{remote, entryType}
+	}, pollInterval)
+	defer close(pollInterval)
+	pollInterval <- time.Minute
+
+	// wait for a change to remote ignoring any others
+	waitFor := func(want change) {
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case got := <-changes:
+				if got == want {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for %v", want)
+			}
+		}
+	}
+
+	// The watches are added in the background so keep writing the
+	// file until the first change is seen
+loop:
+	for i := 0; ; i++ {
+		require.Less(t, i, 100, "no change notifications")
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello"), 0666))
+		select {
+		case got := <-changes:
+			if got == (change{"file.txt", fs.EntryObject}) {
+				break loop
+			}
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sub", "file2.txt"), []byte("hello"), 0666))
+	waitFor(change{"sub/file2.txt", fs.EntryObject})
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub", "new"), 0777))
+	waitFor(change{"sub/new", fs.EntryDirectory})
+
+	// New directories are watched too
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sub", "new", "file3.txt"), []byte("hello"), 0666))
+	waitFor(change{"sub/new/file3.txt", fs.EntryObject})
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "file.txt")))
+	waitFor(change{"file.txt", fs.EntryObject})
+}
@@ -0,0 +1,26 @@
+// File cloning functions
+
+// +build darwin
+
+package local
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile makes dst a copy of src which shares its storage using
+// clonefile(2). This works on APFS when src and dst are on the same
+// file system.
+//
+// dst must not exist.
+func cloneFile(src, dst string) error {
+	return unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+}
+
+// cloneUnsupported returns true if err shows the file system
+// doesn't support cloning at all rather than just for these files
+func cloneUnsupported(err error) bool {
+	err = errors.Cause(err)
+	return err == unix.ENOTSUP || err == unix.ENOSYS
+}
@@ -0,0 +1,47 @@
+// File cloning functions
+
+// +build linux
+
+package local
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile makes dst a copy of src which shares its storage using
+// the FICLONE ioctl. This works on file systems such as btrfs and XFS
+// when src and dst are on the same file system.
+//
+// dst must not exist.
+func cloneFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	err = unix.IoctlFileClone(int(out.Fd()), int(in.Fd()))
+	closeErr := out.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return err
+}
+
+// cloneUnsupported returns true if err shows the file system
+// doesn't support cloning at all rather than just for these files
+func cloneUnsupported(err error) bool {
+	err = errors.Cause(err)
+	return err == unix.EOPNOTSUPP || err == unix.ENOTTY || err == unix.ENOSYS
+}
@@ -0,0 +1,24 @@
+// File cloning functions
+
+// +build !darwin,!linux
+
+package local
+
+import "github.com/pkg/errors"
+
+// errCloneUnsupported is returned by cloneFile on OSes which can't
+// clone files
+var errCloneUnsupported = errors.New("cloning files isn't supported on this OS")
+
+// cloneFile makes dst a copy of src which shares its storage.
+//
+// This isn't supported on this OS so it always returns an error.
+func cloneFile(src, dst string) error {
+	return errCloneUnsupported
+}
+
+// cloneUnsupported returns true if err shows the file system
+// doesn't support cloning at all rather than just for these files
+func cloneUnsupported(err error) bool {
+	return true
+}
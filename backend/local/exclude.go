@@ -0,0 +1,96 @@
+package local
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+)
+
+// appleDoubleMagic is at the start of the AppleDouble files macOS
+// writes, named "._" + the file name, to store resource forks and
+// extended attributes on filesystems which don't support them.
+var appleDoubleMagic = []byte{0x00, 0x05, 0x16, 0x07}
+
+// dsStoreMagic is at the start of the .DS_Store files written by the
+// macOS Finder.
+var dsStoreMagic = []byte{0x00, 0x00, 0x00, 0x01, 'B', 'u', 'd', '1'}
+
+// isMacOSMetadata returns true if the file fi at localPath is a
+// .DS_Store or an AppleDouble file.
+//
+// The contents are checked so files which just happen to have these
+// names aren't skipped.
+func isMacOSMetadata(localPath string, fi os.FileInfo) bool {
+	name := fi.Name()
+	var magic []byte
+	switch {
+	case name == ".DS_Store":
+		magic = dsStoreMagic
+	case strings.HasPrefix(name, "._"):
+		magic = appleDoubleMagic
+	default:
+		return false
+	}
+	if !fi.Mode().IsRegular() || fi.Size() < int64(len(magic)) {
+		return false
+	}
+	fd, err := os.Open(localPath)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		_ = fd.Close()
+	}()
+	buf := make([]byte, len(magic))
+	_, err = io.ReadFull(fd, buf)
+	return err == nil && bytes.Equal(buf, magic)
+}
+
+// skip returns true if the file fi at localPath shouldn't be listed
+// because of the skip_hidden, skip_system or skip_macos_metadata
+// options.
+func (f *Fs) skip(localPath string, fi os.FileInfo) bool {
+	if f.opt.SkipHidden || f.opt.SkipSystem {
+		hidden, system := readAttributes(fi)
+		if (f.opt.SkipHidden && hidden) || (f.opt.SkipSystem && system) {
+			fs.Debugf(localPath, "Skipping file with hidden or system attribute")
+			return true
+		}
+	}
+	if f.opt.SkipMacOSMetadata && isMacOSMetadata(localPath, fi) {
+		fs.Debugf(localPath, "Skipping macOS metadata file")
+		return true
+	}
+	return false
+}
+
+// isSymlinkCycle returns true if the symlink to a directory at
+// localPath in dir points to dir or to any of the directories above it
+// in the listing so following it would recurse forever.
+//
+// This catches loops through several symlinks and Windows junction
+// points which the OS doesn't detect.
+func (f *Fs) isSymlinkCycle(dir, localPath string) bool {
+	target, err := filepath.EvalSymlinks(localPath)
+	if err != nil {
+		return false
+	}
+	for {
+		parent, err := filepath.EvalSymlinks(f.localPath(dir))
+		if err == nil && parent == target {
+			return true
+		}
+		if dir == "" {
+			return false
+		}
+		dir = path.Dir(dir)
+		if dir == "." || dir == "/" {
+			dir = ""
+		}
+	}
+}
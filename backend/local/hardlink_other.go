@@ -0,0 +1,14 @@
+// Hard link reading functions
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package local
+
+import "os"
+
+// readHardLinkID turns a valid os.FileInfo into an identifier shared
+// by all the names of the same inode, returning "" if the file only
+// has one name or the information can't be read.
+func readHardLinkID(fi os.FileInfo) string {
+	return ""
+}
@@ -0,0 +1,22 @@
+// Hard link reading functions
+
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package local
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// readHardLinkID turns a valid os.FileInfo into an identifier shared
+// by all the names of the same inode, returning "" if the file only
+// has one name or the information can't be read.
+func readHardLinkID(fi os.FileInfo) string {
+	statT, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || statT.Nlink <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("%x:%x", uint64(statT.Dev), uint64(statT.Ino)) // nolint: unconvert
+}
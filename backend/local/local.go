@@ -13,6 +13,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/artpar/rclone/fs/hash"
 	"github.com/artpar/rclone/lib/encoder"
 	"github.com/artpar/rclone/lib/file"
+	"github.com/artpar/rclone/lib/random"
 	"github.com/artpar/rclone/lib/readers"
 )
 
@@ -181,6 +183,50 @@ when copying to a CIFS mount owned by another user. If this option is
 enabled, rclone will no longer update the modtime after copying a file.`,
 			Default:  false,
 			Advanced: true,
+		}, {
+			Name: "skip_hidden",
+			Help: `Don't list files and directories with the hidden attribute
+
+This reads the hidden attribute on Windows and the hidden flag (as set
+with "chflags hidden") on macOS and FreeBSD. Other OSes don't have a
+hidden attribute so nothing is skipped there - use a filter such as
+--exclude ".*" if you want to skip dot files.`,
+			Default:  false,
+			Advanced: true,
+		}, {
+			Name: "skip_system",
+			Help: `Don't list files and directories with the system attribute (Windows only)
+
+This skips files such as "desktop.ini" and "Thumbs.db" which Windows
+marks as system files.`,
+			Default:  false,
+			Advanced: true,
+		}, {
+			Name: "skip_macos_metadata",
+			Help: `Don't list the metadata files macOS leaves behind
+
+This skips the ".DS_Store" files written by the Finder and the "._"
+AppleDouble files macOS writes to store resource forks and extended
+attributes on filesystems which don't support them, eg on USB drives
+or network shares.
+
+The contents of the files are checked so files which just happen to
+have these names aren't skipped.`,
+			Default:  false,
+			Advanced: true,
+		}, {
+			Name: "change_notify",
+			Help: `Watch for changes to the files (Linux only)
+
+If this is set then rclone uses inotify to watch all the directories
+under the root for changes so "rclone mount" and the other users of
+the VFS see them straight away rather than when the directory cache
+expires.
+
+This needs one inotify watch per directory so for large directory
+trees you may need to raise the fs.inotify.max_user_watches sysctl.`,
+			Default:  false,
+			Advanced: true,
 		}, {
 			Name:     config.ConfigEncoding,
 			Help:     config.ConfigEncodingHelp,
@@ -206,6 +252,10 @@ type Options struct {
 	NoPreAllocate     bool                 `config:"no_preallocate"`
 	NoSparse          bool                 `config:"no_sparse"`
 	NoSetModTime      bool                 `config:"no_set_modtime"`
+	SkipHidden        bool                 `config:"skip_hidden"`
+	SkipSystem        bool                 `config:"skip_system"`
+	SkipMacOSMetadata bool                 `config:"skip_macos_metadata"`
+	ChangeNotify      bool                 `config:"change_notify"`
 	Enc               encoder.MultiEncoder `config:"encoding"`
 }
 
@@ -224,6 +274,7 @@ type Fs struct {
 	// do os.Lstat or os.Stat
 	lstat        func(name string) (os.FileInfo, error)
 	objectMetaMu sync.RWMutex // global lock for Object metadata
+	noClone      int32        // set atomically if the file system can't clone files
 }
 
 // Object represents a local filesystem object
@@ -236,6 +287,7 @@ type Object struct {
 	mode    os.FileMode
 	modTime time.Time
 	hashes  map[hash.Type]string // Hashes
+	linkID  string               // hard link identity or "" if not hard linked
 	// these are read only and don't need the mutex held
 	translatedLink bool // Is this object a translated link
 }
@@ -271,9 +323,14 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	f.features = (&fs.Features{
 		CaseInsensitive:         f.caseInsensitive(),
 		CanHaveEmptyDirectories: true,
+		ReadMetadata:            true,
+		WriteMetadata:           true,
 		IsLocal:                 true,
 		SlowHash:                true,
 	}).Fill(ctx, f)
+	if !opt.ChangeNotify {
+		f.features.ChangeNotify = nil
+	}
 	if opt.FollowSymlinks {
 		f.lstat = os.Stat
 	}
@@ -495,6 +552,14 @@ func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err e
 					return nil, err
 				}
 				mode = fi.Mode()
+				if fi.IsDir() && f.isSymlinkCycle(dir, localPath) {
+					// Skip symlinks which point to a directory above them
+					fs.Logf(newRemote, "Skipping symlink to a directory above it as following it would loop forever")
+					continue
+				}
+			}
+			if f.skip(filepath.Join(fsDirPath, name), fi) {
+				continue
 			}
 			if fi.IsDir() {
 				// Ignore directories which are symlinks.  These are junction points under windows which
@@ -580,6 +645,18 @@ func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 	return os.Remove(f.localPath(dir))
 }
 
+// DirSetModTime sets the modification time of the directory dir
+func (f *Fs) DirSetModTime(ctx context.Context, dir string, modTime time.Time) error {
+	if f.opt.NoSetModTime {
+		return nil
+	}
+	err := os.Chtimes(f.localPath(dir), modTime, modTime)
+	if os.IsNotExist(err) {
+		return fs.ErrorDirNotFound
+	}
+	return err
+}
+
 // Precision of the file system
 func (f *Fs) Precision() (precision time.Duration) {
 	if f.opt.NoSetModTime {
@@ -775,6 +852,158 @@ func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string
 	return nil
 }
 
+// HardLink makes remote a hard link to the existing object src
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantHardLink
+func (f *Fs) HardLink(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		fs.Debugf(src, "Can't hard link - not same remote type")
+		return nil, fs.ErrorCantHardLink
+	}
+	if srcObj.translatedLink {
+		fs.Debugf(src, "Can't hard link - source is a translated symlink")
+		return nil, fs.ErrorCantHardLink
+	}
+
+	// Temporary Object under construction
+	dstObj := f.newObject(remote)
+
+	// Check it is a file if it exists and remove it
+	err := dstObj.lstat()
+	if os.IsNotExist(err) {
+		// OK
+	} else if err != nil {
+		return nil, err
+	} else {
+		dstObj.fs.objectMetaMu.RLock()
+		dstObjMode := dstObj.mode
+		dstObj.fs.objectMetaMu.RUnlock()
+		if !dstObj.fs.isRegular(dstObjMode) {
+			// It isn't a file
+			return nil, errors.New("can't hard link onto non-file")
+		}
+		if os.SameFile(readFileInfo(srcObj), readFileInfo(dstObj)) {
+			return dstObj, nil
+		}
+		err = remove(dstObj.path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Create destination
+	err = dstObj.mkdirAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Do the link
+	err = os.Link(srcObj.path, dstObj.path)
+	if err != nil {
+		// probably trying to link across file system boundaries
+		// or on a file system which doesn't support hard links.
+		fs.Debugf(src, "Can't hard link: %v", err)
+		return nil, fs.ErrorCantHardLink
+	}
+
+	// Update the info
+	err = dstObj.lstat()
+	if err != nil {
+		return nil, err
+	}
+	return dstObj, nil
+}
+
+// Copy src to this remote by cloning it so the copy shares the
+// storage of src. This only works on file systems which support it
+// (e.g. btrfs, XFS and APFS) when src and remote are on the same file
+// system, but it is instant even for large files.
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantCopy
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		fs.Debugf(src, "Can't clone - not same remote type")
+		return nil, fs.ErrorCantCopy
+	}
+	if srcObj.translatedLink {
+		fs.Debugf(src, "Can't clone - source is a translated symlink")
+		return nil, fs.ErrorCantCopy
+	}
+	if atomic.LoadInt32(&f.noClone) != 0 {
+		return nil, fs.ErrorCantCopy
+	}
+
+	// Temporary Object under construction
+	dstObj := f.newObject(remote)
+
+	// Check it is a file if it exists
+	err := dstObj.lstat()
+	if os.IsNotExist(err) {
+		// OK
+	} else if err != nil {
+		return nil, err
+	} else {
+		dstObj.fs.objectMetaMu.RLock()
+		dstObjMode := dstObj.mode
+		dstObj.fs.objectMetaMu.RUnlock()
+		if !dstObj.fs.isRegular(dstObjMode) {
+			// It isn't a file
+			return nil, errors.New("can't copy onto non-file")
+		}
+	}
+
+	// Create destination
+	err = dstObj.mkdirAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Clone to a temporary name then rename it into place so any
+	// existing file is only replaced if the clone works
+	tmpPath := dstObj.path + "." + random.String(8) + ".rclone-clone"
+	err = cloneFile(srcObj.path, tmpPath)
+	if err != nil {
+		if cloneUnsupported(err) {
+			atomic.StoreInt32(&f.noClone, 1)
+		}
+		fs.Debugf(src, "Can't clone: %v", err)
+		return nil, fs.ErrorCantCopy
+	}
+	err = os.Rename(tmpPath, dstObj.path)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, err
+	}
+
+	// The clone has the modification time of when it was made
+	err = dstObj.SetModTime(ctx, src.ModTime(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the info
+	err = dstObj.lstat()
+	if err != nil {
+		return nil, err
+	}
+	return dstObj, nil
+}
+
+// readFileInfo returns the os.FileInfo for o or nil if it can't be read
+func readFileInfo(o *Object) os.FileInfo {
+	fi, err := o.fs.lstat(o.path)
+	if err != nil {
+		return nil
+	}
+	return fi
+}
+
 // Hashes returns the supported hash sets.
 func (f *Fs) Hashes() hash.Set {
 	return hash.Supported()
@@ -943,6 +1172,14 @@ func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
 	return o.lstat()
 }
 
+// HardLinkID returns an identifier shared by all the names of this
+// file, or "" if it isn't hard linked
+func (o *Object) HardLinkID() string {
+	o.fs.objectMetaMu.RLock()
+	defer o.fs.objectMetaMu.RUnlock()
+	return o.linkID
+}
+
 // Storable returns a boolean showing if this object is storable
 func (o *Object) Storable() bool {
 	o.fs.objectMetaMu.RLock()
@@ -1211,6 +1448,38 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	return o.lstat()
 }
 
+// localPatcher is returned by OpenPatch
+type localPatcher struct {
+	*os.File
+	o *Object
+}
+
+// Close the file and read the metadata back
+func (p *localPatcher) Close() error {
+	err := p.File.Close()
+	if err != nil {
+		return err
+	}
+	return p.o.lstat()
+}
+
+// OpenPatch opens the object for writing in place without truncating
+// it so just the changed parts can be rewritten
+func (o *Object) OpenPatch(ctx context.Context) (fs.PatchWriter, error) {
+	if o.translatedLink {
+		return nil, errors.New("can't patch a symlink")
+	}
+	out, err := file.OpenFile(o.path, os.O_WRONLY, 0666)
+	if err != nil {
+		return nil, err
+	}
+	// Clear the hash cache since we are about to update the object
+	o.fs.objectMetaMu.Lock()
+	o.hashes = nil
+	o.fs.objectMetaMu.Unlock()
+	return &localPatcher{File: out, o: o}, nil
+}
+
 var sparseWarning sync.Once
 
 // OpenWriterAt opens with a handle for random access writes
@@ -1266,6 +1535,7 @@ func (o *Object) setMetadata(info os.FileInfo) {
 	o.size = info.Size()
 	o.modTime = info.ModTime()
 	o.mode = info.Mode()
+	o.linkID = readHardLinkID(info)
 	o.fs.objectMetaMu.Unlock()
 	// On Windows links read as 0 size so set the correct size here
 	// Optionally, users can turn this feature on with the zero_size_links flag
@@ -1328,8 +1598,13 @@ var (
 	_ fs.Purger         = &Fs{}
 	_ fs.PutStreamer    = &Fs{}
 	_ fs.Mover          = &Fs{}
+	_ fs.Copier         = &Fs{}
 	_ fs.DirMover       = &Fs{}
 	_ fs.Commander      = &Fs{}
 	_ fs.OpenWriterAter = &Fs{}
+	_ fs.HardLinker     = &Fs{}
+	_ fs.DirSetModTimer = &Fs{}
 	_ fs.Object         = &Object{}
+	_ fs.HardLinkIDer   = &Object{}
+	_ fs.Patcher        = &Object{}
 )
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -166,3 +167,130 @@ func TestSymlinkError(t *testing.T) {
 	_, err := NewFs(context.Background(), "local", "/", m)
 	assert.Equal(t, errLinksAndCopyLinks, err)
 }
+
+func TestHardLink(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("hard link IDs not supported on " + runtime.GOOS)
+	}
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	f := r.Flocal.(*Fs)
+	dir := f.root
+
+	// Write a file and a hard link to it
+	modTime1 := fstest.Time("2001-02-03T04:05:10.123123123Z")
+	file1 := r.WriteFile("file.txt", "hello", modTime1)
+	require.NoError(t, os.Link(filepath.Join(dir, "file.txt"), filepath.Join(dir, "link.txt")))
+	file2 := fstest.NewItem("link.txt", "hello", modTime1)
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+
+	o1, err := f.NewObject(ctx, "file.txt")
+	require.NoError(t, err)
+	o2, err := f.NewObject(ctx, "link.txt")
+	require.NoError(t, err)
+	id := o1.(fs.HardLinkIDer).HardLinkID()
+	assert.NotEqual(t, "", id)
+	assert.Equal(t, id, o2.(fs.HardLinkIDer).HardLinkID())
+
+	// Make a new hard link with the Fs
+	o3, err := f.HardLink(ctx, o1, "sub/link2.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "sub/link2.txt", o3.Remote())
+	assert.Equal(t, id, o3.(fs.HardLinkIDer).HardLinkID())
+	file3 := fstest.NewItem("sub/link2.txt", "hello", modTime1)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3)
+
+	// Linking over an existing file replaces it
+	r.WriteFile("other.txt", "potato", modTime1)
+	_, err = f.HardLink(ctx, o1, "other.txt")
+	require.NoError(t, err)
+	file4 := fstest.NewItem("other.txt", "hello", modTime1)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3, file4)
+
+	// An unlinked file has no ID
+	r.WriteFile("single.txt", "single", modTime1)
+	o5, err := f.NewObject(ctx, "single.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "", o5.(fs.HardLinkIDer).HardLinkID())
+}
+
+func TestCopyClone(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	f := r.Flocal.(*Fs)
+
+	modTime1 := fstest.Time("2001-02-03T04:05:10.123123123Z")
+	file1 := r.WriteFile("file.txt", "hello", modTime1)
+	o1, err := f.NewObject(ctx, "file.txt")
+	require.NoError(t, err)
+
+	o2, err := f.Copy(ctx, o1, "sub/copy.txt")
+	if err == fs.ErrorCantCopy {
+		// Nothing should have been left behind
+		fstest.CheckItems(t, r.Flocal, file1)
+		t.Skip("file system can't clone files")
+	}
+	require.NoError(t, err)
+	assert.Equal(t, "sub/copy.txt", o2.Remote())
+	file2 := fstest.NewItem("sub/copy.txt", "hello", modTime1)
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+
+	// Copying over an existing file replaces it
+	r.WriteFile("other.txt", "potato", modTime1)
+	_, err = f.Copy(ctx, o1, "other.txt")
+	require.NoError(t, err)
+	file3 := fstest.NewItem("other.txt", "hello", modTime1)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3)
+
+	// Changing the copy doesn't change the original
+	file2 = r.WriteFile("sub/copy.txt", "changed", modTime1)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3)
+}
+
+func TestSkipMacOSMetadata(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	f := r.Flocal.(*Fs)
+
+	modTime1 := fstest.Time("2001-02-03T04:05:10.123123123Z")
+	file1 := r.WriteFile("file.txt", "hello", modTime1)
+	appleDouble := r.WriteFile("._file.txt", string(appleDoubleMagic)+"resource fork", modTime1)
+	dsStore := r.WriteFile("sub/.DS_Store", string(dsStoreMagic)+"finder info", modTime1)
+	// These have the names but not the contents so shouldn't be skipped
+	notAppleDouble := r.WriteFile("._notes.txt", "just a file", modTime1)
+	notDSStore := r.WriteFile(".DS_Store", "potato", modTime1)
+
+	fstest.CheckItems(t, r.Flocal, file1, appleDouble, dsStore, notAppleDouble, notDSStore)
+
+	f.opt.SkipMacOSMetadata = true
+	fstest.CheckListingWithPrecision(t, r.Flocal, []fstest.Item{file1, notAppleDouble, notDSStore}, []string{"sub"}, fs.GetModifyWindow(context.Background(), r.Flocal))
+}
+
+func TestSymlinkCycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need special permissions on " + runtime.GOOS)
+	}
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	f := r.Flocal.(*Fs)
+	dir := f.root
+
+	modTime1 := fstest.Time("2001-02-03T04:05:10.123123123Z")
+	file1 := r.WriteFile("a/file.txt", "hello", modTime1)
+	file2 := r.WriteFile("b/file2.txt", "hello2", modTime1)
+	// a/up points to the root, a/b points to b which points back to a
+	require.NoError(t, os.Symlink("..", filepath.Join(dir, "a", "up")))
+	require.NoError(t, os.Symlink(filepath.Join("..", "b"), filepath.Join(dir, "a", "b")))
+	require.NoError(t, os.Symlink(filepath.Join("..", "a"), filepath.Join(dir, "b", "a")))
+
+	// Set fs into "-L" mode
+	f.opt.FollowSymlinks = true
+	f.lstat = os.Stat
+
+	// The symlinks to b should be followed once but the cycles skipped
+	file3 := fstest.NewItem("a/b/file2.txt", "hello2", modTime1)
+	file4 := fstest.NewItem("b/a/file.txt", "hello", modTime1)
+	fstest.CheckListingWithPrecision(t, r.Flocal, []fstest.Item{file1, file2, file3, file4}, []string{"a", "a/b", "b", "b/a"}, fs.GetModifyWindow(context.Background(), r.Flocal))
+}
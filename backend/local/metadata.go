@@ -0,0 +1,83 @@
+// Metadata reading and writing
+
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// Metadata keys used by the local backend
+const (
+	metadataMode   = "mode"   // Unix file type and permissions in octal, e.g. "100644"
+	metadataUID    = "uid"    // user ID of the owner
+	metadataGID    = "gid"    // group ID of the owner
+	metadataXattrs = "xattr-" // prefix for extended attributes with base64 encoded values
+)
+
+// Metadata returns the Unix permissions, ownership and extended
+// attributes of the file where the OS supports them.
+func (o *Object) Metadata(ctx context.Context) (metadata fs.Metadata, err error) {
+	if o.translatedLink {
+		return nil, nil
+	}
+	fi, err := o.fs.lstat(o.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read metadata")
+	}
+	metadata = readMetadata(fi)
+	xattrs, err := readXattrs(o.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read extended attributes")
+	}
+	for name, value := range xattrs {
+		if metadata == nil {
+			metadata = fs.Metadata{}
+		}
+		metadata[metadataXattrs+name] = base64.StdEncoding.EncodeToString(value)
+	}
+	return metadata, nil
+}
+
+// SetMetadata sets the Unix permissions, ownership and extended
+// attributes of the file from metadata where the OS supports them.
+//
+// Ownership and extended attributes which the user isn't permitted
+// to set are skipped.
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	if o.translatedLink {
+		return nil
+	}
+	xattrs := map[string][]byte{}
+	for key, value := range metadata {
+		if !strings.HasPrefix(key, metadataXattrs) {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for metadata %q", key)
+		}
+		xattrs[key[len(metadataXattrs):]] = decoded
+	}
+	err := writeMetadata(o.path, metadata)
+	if err != nil {
+		return err
+	}
+	if len(xattrs) > 0 {
+		err = writeXattrs(o.path, xattrs)
+		if err != nil {
+			return errors.Wrap(err, "failed to set extended attributes")
+		}
+	}
+	return o.lstat()
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Metadataer    = &Object{}
+	_ fs.SetMetadataer = &Object{}
+)
@@ -0,0 +1,27 @@
+// Unix permission and ownership functions
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package local
+
+import (
+	"os"
+
+	"github.com/artpar/rclone/fs"
+)
+
+// readMetadata returns the Unix permissions and ownership from a
+// valid os.FileInfo or nil if they can't be read.
+//
+// This OS doesn't have these so it always returns nil.
+func readMetadata(fi os.FileInfo) fs.Metadata {
+	return nil
+}
+
+// writeMetadata sets the Unix permissions and ownership of the file
+// at path from any found in metadata.
+//
+// This OS doesn't have these so it does nothing.
+func writeMetadata(path string, metadata fs.Metadata) error {
+	return nil
+}
@@ -0,0 +1,57 @@
+// +build darwin linux
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestMetadata(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	f := r.Flocal.(*Fs)
+
+	modTime1 := fstest.Time("2001-02-03T04:05:10.123123123Z")
+	r.WriteFile("file.txt", "hello", modTime1)
+	r.WriteFile("copy.txt", "hello", modTime1)
+	path := filepath.Join(f.root, "file.txt")
+	require.NoError(t, os.Chmod(path, 0640))
+	haveXattrs := unix.Setxattr(path, "user.rclone-test", []byte("potato\x00"), 0) == nil
+
+	o, err := f.NewObject(ctx, "file.txt")
+	require.NoError(t, err)
+	metadata, err := o.(fs.Metadataer).Metadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "100640", metadata["mode"])
+	assert.Equal(t, fmt.Sprint(os.Getuid()), metadata["uid"])
+	assert.Equal(t, fmt.Sprint(os.Getgid()), metadata["gid"])
+	if haveXattrs {
+		assert.Equal(t, "cG90YXRvAA==", metadata["xattr-user.rclone-test"])
+	}
+
+	// Set the metadata on another file and read it back
+	o2, err := f.NewObject(ctx, "copy.txt")
+	require.NoError(t, err)
+	require.NoError(t, o2.(fs.SetMetadataer).SetMetadata(ctx, metadata))
+	metadata2, err := o2.(fs.Metadataer).Metadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, metadata, metadata2)
+	fi, err := os.Stat(filepath.Join(f.root, "copy.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+
+	// Invalid values are rejected
+	err = o2.(fs.SetMetadataer).SetMetadata(ctx, fs.Metadata{"mode": "potato"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+// Unix permission and ownership functions
+
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package local
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// readMetadata returns the Unix permissions and ownership from a
+// valid os.FileInfo or nil if they can't be read.
+func readMetadata(fi os.FileInfo) fs.Metadata {
+	statT, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	return fs.Metadata{
+		metadataMode: strconv.FormatUint(uint64(statT.Mode), 8), // nolint: unconvert
+		metadataUID:  strconv.FormatUint(uint64(statT.Uid), 10),
+		metadataGID:  strconv.FormatUint(uint64(statT.Gid), 10),
+	}
+}
+
+// writeMetadata sets the Unix permissions and ownership of the file
+// at path from any found in metadata.
+//
+// The ownership is skipped if the user isn't permitted to change it.
+func writeMetadata(path string, metadata fs.Metadata) error {
+	uid, gid := -1, -1
+	for _, id := range []struct {
+		key string
+		id  *int
+	}{{metadataUID, &uid}, {metadataGID, &gid}} {
+		value, ok := metadata[id.key]
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return errors.Errorf("invalid value %q for metadata %q", value, id.key)
+		}
+		*id.id = int(n)
+	}
+	// Set the owner first as this can clear the setuid bits
+	if uid >= 0 || gid >= 0 {
+		err := os.Lchown(path, uid, gid)
+		if os.IsPermission(err) {
+			fs.Debugf(path, "Not permitted to set the owner to %d:%d", uid, gid)
+		} else if err != nil {
+			return errors.Wrap(err, "failed to set owner")
+		}
+	}
+	if value, ok := metadata[metadataMode]; ok {
+		mode, err := strconv.ParseUint(value, 8, 32)
+		if err != nil {
+			return errors.Errorf("invalid value %q for metadata %q", value, metadataMode)
+		}
+		err = syscall.Chmod(path, uint32(mode&07777))
+		if err != nil {
+			return errors.Wrap(err, "failed to set permissions")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+// Extended attribute functions
+
+// +build !darwin,!linux
+
+package local
+
+// readXattrs returns the extended attributes of the file at path
+//
+// These aren't supported on this OS so it always returns nil.
+func readXattrs(path string) (xattrs map[string][]byte, err error) {
+	return nil, nil
+}
+
+// writeXattrs sets the extended attributes of the file at path.
+//
+// These aren't supported on this OS so it does nothing.
+func writeXattrs(path string, xattrs map[string][]byte) error {
+	return nil
+}
@@ -0,0 +1,69 @@
+// Extended attribute functions
+
+// +build darwin linux
+
+package local
+
+import (
+	"os"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+	"golang.org/x/sys/unix"
+)
+
+// xattrUnsupported returns true if err shows the file system doesn't
+// support extended attributes
+func xattrUnsupported(err error) bool {
+	return err == unix.ENOTSUP || err == unix.EOPNOTSUPP
+}
+
+// readXattrs returns the extended attributes of the file at path
+func readXattrs(path string) (xattrs map[string][]byte, err error) {
+	size, err := unix.Listxattr(path, nil)
+	if xattrUnsupported(err) || size == 0 {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Listxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+	xattrs = map[string][]byte{}
+	for _, name := range strings.Split(string(buf[:size]), "\x00") {
+		if name == "" {
+			continue
+		}
+		size, err := unix.Getxattr(path, name, nil)
+		if err == unix.ENODATA {
+			continue // removed since it was listed
+		} else if err != nil {
+			return nil, err
+		}
+		value := make([]byte, size)
+		size, err = unix.Getxattr(path, name, value)
+		if err != nil {
+			return nil, err
+		}
+		xattrs[name] = value[:size]
+	}
+	return xattrs, nil
+}
+
+// writeXattrs sets the extended attributes of the file at path.
+//
+// Attributes which the file system doesn't support or the user isn't
+// permitted to set are skipped.
+func writeXattrs(path string, xattrs map[string][]byte) error {
+	for name, value := range xattrs {
+		err := unix.Setxattr(path, name, value, 0)
+		if xattrUnsupported(err) || os.IsPermission(err) {
+			fs.Debugf(path, "Skipping extended attribute %q: %v", name, err)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
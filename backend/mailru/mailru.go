@@ -203,7 +203,7 @@ Supported quirks: atomicmkdir binlist unknowndirs`,
 				encoder.EncodeWin | // :?"*<>|
 				encoder.EncodeBackSlash |
 				encoder.EncodeInvalidUtf8),
-		}},
+		}, fs.ProxyOption, fs.APIBudgetOption},
 	})
 }
 
@@ -488,6 +488,91 @@ func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object,
 	return f.NewObject(ctx, remote)
 }
 
+// Move src to this remote using server-side move operations.
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantMove
+func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		fs.Debugf(src, "Can't move - not same remote type")
+		return nil, fs.ErrorCantMove
+	}
+	dstBucket, dstPath := f.split(remote)
+	srcBucket, srcPath := srcObj.split()
+	od := buckets.getObjectData(srcBucket, srcPath)
+	if od == nil {
+		return nil, fs.ErrorObjectNotFound
+	}
+	buckets.updateObjectData(dstBucket, dstPath, od)
+	if srcBucket != dstBucket || srcPath != dstPath {
+		_ = buckets.removeObjectData(srcBucket, srcPath)
+	}
+	return f.NewObject(ctx, remote)
+}
+
+// Purge deletes all the files in the directory
+//
+// Implement this if you have a way of deleting all the files
+// quicker than just running Remove() on the result of List()
+func (f *Fs) Purge(ctx context.Context, dir string) error {
+	bucket, directory := f.split(dir)
+	if bucket == "" {
+		return errors.New("can't purge from root")
+	}
+	b := buckets.getBucket(bucket)
+	if b == nil {
+		return fs.ErrorDirNotFound
+	}
+	prefix := ""
+	if directory != "" {
+		prefix = directory + "/"
+	}
+	removed := false
+	b.mu.Lock()
+	for bucketPath := range b.objects {
+		if strings.HasPrefix(bucketPath, prefix) {
+			delete(b.objects, bucketPath)
+			removed = true
+		}
+	}
+	b.mu.Unlock()
+	if directory == "" {
+		return buckets.deleteBucket(bucket)
+	}
+	if !removed {
+		return fs.ErrorDirNotFound
+	}
+	return nil
+}
+
+// About gets quota information
+//
+// As the storage is shared by all memory remotes this reports the
+// usage of all of them.
+func (f *Fs) About(ctx context.Context) (*fs.Usage, error) {
+	var used, objects int64
+	buckets.mu.RLock()
+	for _, b := range buckets.buckets {
+		b.mu.RLock()
+		for _, od := range b.objects {
+			used += int64(len(od.data))
+			objects++
+		}
+		b.mu.RUnlock()
+	}
+	buckets.mu.RUnlock()
+	return &fs.Usage{
+		Used:    fs.NewUsageValue(used),
+		Objects: fs.NewUsageValue(objects),
+	}, nil
+}
+
 // Hashes returns the supported hash sets.
 func (f *Fs) Hashes() hash.Set {
 	return hash.Set(hashType)
@@ -617,6 +702,9 @@ func (o *Object) MimeType(ctx context.Context) string {
 var (
 	_ fs.Fs          = &Fs{}
 	_ fs.Copier      = &Fs{}
+	_ fs.Mover       = &Fs{}
+	_ fs.Purger      = &Fs{}
+	_ fs.Abouter     = &Fs{}
 	_ fs.PutStreamer = &Fs{}
 	_ fs.ListRer     = &Fs{}
 	_ fs.Object      = &Object{}
@@ -274,6 +274,24 @@ type CreateShareLinkResponse struct {
 	} `json:"link"`
 }
 
+// PermissionsResponse is the response to the list permissions method
+type PermissionsResponse struct {
+	Value []PermissionsType `json:"value"` // An array of permissions
+}
+
+// PermissionsType provides information about a sharing permission
+// granted for an item
+type PermissionsType struct {
+	ID    string   `json:"id"`    // The unique identifier of the permission among all permissions on the item
+	Roles []string `json:"roles"` // The type of permission, e.g. read
+	Link  *struct {
+		Type   string `json:"type"`   // The type of the link: view, edit or embed
+		Scope  string `json:"scope"`  // The scope of the link: anonymous or organization
+		WebURL string `json:"webUrl"` // A URL that opens the item in the browser
+	} `json:"link,omitempty"` // Set if the permission is a sharing link
+	InheritedFrom *ItemReference `json:"inheritedFrom,omitempty"` // Set if the permission is inherited from an ancestor
+}
+
 // AsyncOperationStatus provides information on the status of an asynchronous job progress.
 //
 // The following API calls return AsyncOperationStatus resources:
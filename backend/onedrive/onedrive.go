@@ -381,6 +381,19 @@ modification time and removes all but the last version.
 
 **NB** Onedrive personal can't currently delete versions so don't use
 this flag there.
+`,
+			Advanced: true,
+		}, {
+			Name:    "no_upload_check",
+			Default: false,
+			Help: `Don't check the QuickXorHash of uploads
+
+Normally rclone calculates the QuickXorHash of the data as it uploads
+it and checks it against the QuickXorHash the server returns once the
+upload has finished. If they differ the upload is retried.
+
+Set this flag to skip the check, for example if the server is slow to
+return hashes.
 `,
 			Advanced: true,
 		}, {
@@ -461,7 +474,7 @@ At the time of writing this only works with OneDrive personal paid accounts.
 				encoder.EncodeRightSpace |
 				encoder.EncodeWin |
 				encoder.EncodeInvalidUtf8),
-		}}...),
+		}, fs.ProxyOption, fs.APIBudgetOption}...),
 	})
 }
 
@@ -475,6 +488,7 @@ type Options struct {
 	ServerSideAcrossConfigs bool                 `config:"server_side_across_configs"`
 	ListChunk               int64                `config:"list_chunk"`
 	NoVersions              bool                 `config:"no_versions"`
+	NoUploadCheck           bool                 `config:"no_upload_check"`
 	LinkScope               string               `config:"link_scope"`
 	LinkType                string               `config:"link_type"`
 	LinkPassword            string               `config:"link_password"`
@@ -1416,11 +1430,17 @@ func (f *Fs) Hashes() hash.Set {
 }
 
 // PublicLink returns a link for downloading without account.
+//
+// If unlink is set then the sharing links to the item are removed
+// instead.
 func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration, unlink bool) (link string, err error) {
 	info, _, err := f.readMetaDataForPath(ctx, f.rootPath(remote))
 	if err != nil {
 		return "", err
 	}
+	if unlink {
+		return "", f.removeLinks(ctx, info.GetID())
+	}
 	opts := f.newOptsCall(info.GetID(), "POST", "/createLink")
 
 	share := api.CreateShareLinkRequest{
@@ -1429,7 +1449,7 @@ func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration,
 		Password: f.opt.LinkPassword,
 	}
 
-	if expire < fs.Duration(time.Hour*24*365*100) {
+	if expire < fs.LinkExpireNever {
 		expiry := time.Now().Add(time.Duration(expire))
 		share.Expiry = &expiry
 	}
@@ -1447,6 +1467,41 @@ func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration,
 	return result.Link.WebURL, nil
 }
 
+// removeLinks removes the sharing links granted on the item with id,
+// but not those inherited from its parents.
+func (f *Fs) removeLinks(ctx context.Context, id string) (err error) {
+	opts := f.newOptsCall(id, "GET", "/permissions")
+	var resp *http.Response
+	var permissions api.PermissionsResponse
+	err = f.pacer.Call(func() (bool, error) {
+		resp, err = f.srv.CallJSON(ctx, &opts, nil, &permissions)
+		return shouldRetry(ctx, resp, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list permissions")
+	}
+	removed := 0
+	for _, permission := range permissions.Value {
+		if permission.Link == nil || permission.InheritedFrom != nil {
+			continue
+		}
+		opts := f.newOptsCall(id, "DELETE", "/permissions/"+permission.ID)
+		opts.NoResponse = true
+		err = f.pacer.Call(func() (bool, error) {
+			resp, err = f.srv.Call(ctx, &opts)
+			return shouldRetry(ctx, resp, err)
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to remove link")
+		}
+		removed++
+	}
+	if removed == 0 {
+		return errors.New("no public link found to remove")
+	}
+	return nil
+}
+
 // CleanUp deletes all the hidden files.
 func (f *Fs) CleanUp(ctx context.Context) error {
 	token := make(chan struct{}, f.ci.Checkers)
@@ -1932,6 +1987,14 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	size := src.Size()
 	modTime := src.ModTime(ctx)
 
+	// Hash the data as it is uploaded to check the upload
+	hasher := quickxorhash.New()
+	if !o.fs.opt.NoUploadCheck {
+		in = io.TeeReader(in, hasher)
+	}
+	// Clear the hashes so they are only set from the upload
+	o.sha1, o.quickxorhash = "", ""
+
 	var info *api.Item
 	if size > 0 {
 		info, err = o.uploadMultipart(ctx, in, size, modTime, options...)
@@ -1952,7 +2015,28 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 		}
 	}
 
-	return o.setMetaData(info)
+	err = o.setMetaData(info)
+	if err != nil {
+		return err
+	}
+	if !o.fs.opt.NoUploadCheck {
+		return o.checkUploadHash(hex.EncodeToString(hasher.Sum(nil)))
+	}
+	return nil
+}
+
+// checkUploadHash checks the QuickXorHash the server returned for the
+// object against sum, the QuickXorHash of the data uploaded
+func (o *Object) checkUploadHash(sum string) error {
+	if o.quickxorhash == "" {
+		fs.Debugf(o, "Can't check upload as the server didn't return a QuickXorHash")
+		return nil
+	}
+	if o.quickxorhash != sum {
+		return fserrors.RetryErrorf("corrupted on transfer: QuickXorHash differ %q vs %q", sum, o.quickxorhash)
+	}
+	fs.Debugf(o, "QuickXorHash = %s OK", sum)
+	return nil
 }
 
 // Remove an object
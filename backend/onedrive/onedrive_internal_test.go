@@ -0,0 +1,23 @@
+package onedrive
+
+import (
+	"testing"
+
+	"github.com/artpar/rclone/fs/fserrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckUploadHash(t *testing.T) {
+	const sum = "0123456789abcdef0123456789abcdef01234567"
+	o := &Object{remote: "file.txt"}
+
+	// No hash from the server so can't check
+	assert.NoError(t, o.checkUploadHash(sum))
+
+	o.quickxorhash = sum
+	assert.NoError(t, o.checkUploadHash(sum))
+
+	err := o.checkUploadHash("76543210fedcba9876543210fedcba9876543210")
+	assert.Error(t, err)
+	assert.True(t, fserrors.IsRetryError(err))
+}
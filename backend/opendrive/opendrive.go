@@ -90,7 +90,7 @@ Note that these chunks are buffered in memory so increasing them will
 increase memory use.`,
 			Default:  10 * fs.MebiByte,
 			Advanced: true,
-		}},
+		}, fs.ProxyOption, fs.APIBudgetOption},
 	})
 }
 
@@ -119,7 +119,7 @@ this may help to speed up the transfers.`,
 			Default: (encoder.EncodeInvalidUtf8 |
 				encoder.EncodeCtl |
 				encoder.EncodeSlash),
-		}},
+		}, fs.ProxyOption},
 	})
 }
 
@@ -428,6 +428,38 @@ func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options .
 	return fsObj, fsObj.Update(ctx, in, src, options...)
 }
 
+// OpenChunkWriter returns a writer which uploads remote as a
+// multipart upload a chunk at a time so the size needn't be known in
+// advance.
+func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, err error) {
+	bucket, bucketPath := f.split(remote)
+	err = f.makeBucket(ctx, bucket)
+	if err != nil {
+		return info, nil, err
+	}
+	mu := &multiUploader{
+		uploader: newUploader(&uploadInput{
+			qsSvc:    f.svc,
+			bucket:   bucket,
+			zone:     f.zone,
+			key:      bucketPath,
+			mimeType: fs.MimeType(ctx, src),
+			partSize: int64(f.opt.ChunkSize),
+		}),
+	}
+	mu.init()
+	err = mu.initiate()
+	if err != nil {
+		return info, nil, err
+	}
+	info = fs.ChunkWriterInfo{
+		ChunkSize:    mu.cfg.partSize,
+		MinChunkSize: minMultiPartSize,
+		MaxChunks:    maxMultiParts,
+	}
+	return info, &chunkWriter{mu: mu}, nil
+}
+
 // Copy src to this remote using server-side copy operations.
 //
 // This is stored with the remote path given
@@ -1162,10 +1194,11 @@ func (o *Object) MimeType(ctx context.Context) string {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs         = &Fs{}
-	_ fs.CleanUpper = &Fs{}
-	_ fs.Copier     = &Fs{}
-	_ fs.Object     = &Object{}
-	_ fs.ListRer    = &Fs{}
-	_ fs.MimeTyper  = &Object{}
+	_ fs.Fs                = &Fs{}
+	_ fs.CleanUpper        = &Fs{}
+	_ fs.Copier            = &Fs{}
+	_ fs.Object            = &Object{}
+	_ fs.ListRer           = &Fs{}
+	_ fs.OpenChunkWriterer = &Fs{}
+	_ fs.MimeTyper         = &Object{}
 )
@@ -6,6 +6,7 @@ package qingstor
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"fmt"
 	"hash"
@@ -411,3 +412,36 @@ func (mu *multiUploader) multiPartUpload(firstBuf io.ReadSeeker) (err error) {
 	// Complete Multipart Upload
 	return mu.complete()
 }
+
+// chunkWriter uploads each chunk it is given as a part of a multipart
+// upload
+type chunkWriter struct {
+	mu *multiUploader
+}
+
+// WriteChunk uploads the chunk as part number chunkNumber
+func (w *chunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	size, err := reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = reader.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+	err = w.mu.send(chunk{buffer: reader, partNumber: chunkNumber, size: size})
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// Close completes the multipart upload
+func (w *chunkWriter) Close(ctx context.Context) error {
+	return w.mu.complete()
+}
+
+// Abort aborts the multipart upload
+func (w *chunkWriter) Abort(ctx context.Context) error {
+	return w.mu.abort()
+}
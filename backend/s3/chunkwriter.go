@@ -0,0 +1,168 @@
+// Multipart uploads driven a chunk at a time by OpenChunkWriter
+
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/lib/structs"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// OpenChunkWriter returns a writer which uploads remote as a
+// multipart upload a chunk at a time so the size needn't be known in
+// advance.
+func (f *Fs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (info fs.ChunkWriterInfo, writer fs.ChunkWriter, err error) {
+	o := &Object{
+		fs:     f,
+		remote: remote,
+	}
+	err = o.checkModify()
+	if err != nil {
+		return info, nil, err
+	}
+	bucket, bucketPath := o.split()
+	f.touchInventory(bucket, bucketPath)
+	err = f.makeBucket(ctx, bucket)
+	if err != nil {
+		return info, nil, err
+	}
+	req, _ := o.prepareUpload(ctx, src, options, true)
+
+	var mReq s3.CreateMultipartUploadInput
+	structs.SetFrom(&mReq, &req)
+	var cout *s3.CreateMultipartUploadOutput
+	err = f.pacer.Call(func() (bool, error) {
+		var err error
+		cout, err = f.c.CreateMultipartUploadWithContext(ctx, &mReq)
+		return f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return info, nil, errors.Wrap(err, "multipart upload failed to initialise")
+	}
+
+	uploadParts := f.opt.MaxUploadParts
+	if uploadParts < 1 {
+		uploadParts = 1
+	} else if uploadParts > maxUploadParts {
+		uploadParts = maxUploadParts
+	}
+	info = fs.ChunkWriterInfo{
+		ChunkSize:    int64(f.opt.ChunkSize),
+		MinChunkSize: int64(minChunkSize),
+		MaxChunks:    int(uploadParts),
+	}
+	return info, &chunkWriter{
+		o:        o,
+		req:      &req,
+		uploadID: cout.UploadId,
+	}, nil
+}
+
+// chunkWriter uploads each chunk it is given as a part of a multipart
+// upload
+type chunkWriter struct {
+	o        *Object
+	req      *s3.PutObjectInput // the request the upload was made from
+	uploadID *string
+	parts    []*s3.CompletedPart
+}
+
+// WriteChunk uploads the chunk as part number chunkNumber+1
+func (w *chunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	f := w.o.fs
+
+	// create checksum of the chunk for integrity checking
+	hasher := md5.New()
+	size, err := io.Copy(hasher, reader)
+	if err != nil {
+		return 0, errors.Wrap(err, "multipart upload failed to read chunk")
+	}
+	md5sum := base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+
+	partNum := int64(chunkNumber + 1)
+	var uout *s3.UploadPartOutput
+	err = f.pacer.Call(func() (bool, error) {
+		_, err := reader.Seek(0, io.SeekStart)
+		if err != nil {
+			return false, err
+		}
+		uout, err = f.c.UploadPartWithContext(ctx, &s3.UploadPartInput{
+			Body:                 reader,
+			Bucket:               w.req.Bucket,
+			Key:                  w.req.Key,
+			PartNumber:           &partNum,
+			UploadId:             w.uploadID,
+			ContentMD5:           &md5sum,
+			ContentLength:        &size,
+			RequestPayer:         w.req.RequestPayer,
+			SSECustomerAlgorithm: w.req.SSECustomerAlgorithm,
+			SSECustomerKey:       w.req.SSECustomerKey,
+			SSECustomerKeyMD5:    w.req.SSECustomerKeyMD5,
+		})
+		return f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "multipart upload failed to upload part")
+	}
+	w.parts = append(w.parts, &s3.CompletedPart{
+		PartNumber: &partNum,
+		ETag:       uout.ETag,
+	})
+	return size, nil
+}
+
+// Close completes the multipart upload
+func (w *chunkWriter) Close(ctx context.Context) error {
+	f := w.o.fs
+	err := f.pacer.Call(func() (bool, error) {
+		_, err := f.c.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket: w.req.Bucket,
+			Key:    w.req.Key,
+			MultipartUpload: &s3.CompletedMultipartUpload{
+				Parts: w.parts,
+			},
+			RequestPayer: w.req.RequestPayer,
+			UploadId:     w.uploadID,
+		})
+		return f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "multipart upload failed to finalise")
+	}
+	return nil
+}
+
+// Abort cancels the multipart upload unless --s3-leave-parts-on-error
+// is set
+func (w *chunkWriter) Abort(ctx context.Context) error {
+	f := w.o.fs
+	if f.opt.LeavePartsOnError {
+		return nil
+	}
+	fs.Debugf(w.o, "Cancelling multipart upload")
+	err := f.pacer.Call(func() (bool, error) {
+		_, err := f.c.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:       w.req.Bucket,
+			Key:          w.req.Key,
+			UploadId:     w.uploadID,
+			RequestPayer: w.req.RequestPayer,
+		})
+		return f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to cancel multipart upload")
+	}
+	return nil
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.OpenChunkWriterer = &Fs{}
+	_ fs.ChunkWriter       = &chunkWriter{}
+)
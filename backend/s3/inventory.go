@@ -0,0 +1,421 @@
+// List objects from an S3 Inventory report
+
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/lib/bucket"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// inventoryManifest is the manifest.json written with each S3
+// Inventory report
+//
+// See: https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-inventory-location.html
+type inventoryManifest struct {
+	SourceBucket      string `json:"sourceBucket"`
+	DestinationBucket string `json:"destinationBucket"`
+	CreationTimestamp string `json:"creationTimestamp"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	Files             []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+}
+
+// inventory holds the objects read from an S3 Inventory report and
+// the directories which have been changed since it was made
+//
+// Reports can list billions of objects so rather than keeping an
+// s3.Object for each, the objects are packed into a single buffer
+// which is limited to maxMemory bytes.
+type inventory struct {
+	manifest  string // bucket/path of the manifest.json
+	maxMemory int64  // maximum size of data
+
+	loadOnce       sync.Once
+	loadErr        error
+	bucket         string   // the bucket the report is for
+	data           []byte   // the packed objects
+	offsets        []int    // offset in data of each object, sorted by key once loaded
+	storageClasses []string // storage classes indexed by the packed class - 1
+
+	mu      sync.Mutex
+	touched map[string]struct{} // bucket/dir of changed directories and their parents
+}
+
+// newInventory makes an inventory reading the manifest at
+// bucket/path using at most maxMemory bytes to store the objects
+func newInventory(manifest string, maxMemory int64) *inventory {
+	return &inventory{
+		manifest:  manifest,
+		maxMemory: maxMemory,
+		touched:   make(map[string]struct{}),
+	}
+}
+
+// Flags for the packed objects
+const (
+	inventoryHasSize    = 1 << iota // size is valid
+	inventoryHasModTime             // modification time is valid
+)
+
+// add packs the object into the inventory
+//
+// Each object is stored as
+//
+//	key length (uvarint), key, flags (byte), size (varint),
+//	modification time in ns (varint), storage class (byte),
+//	ETag length (byte), ETag
+func (inv *inventory) add(key string, size int64, hasSize bool, modTime time.Time, hasModTime bool, etag, storageClass string) error {
+	var flags byte
+	if hasSize {
+		flags |= inventoryHasSize
+	}
+	if hasModTime {
+		flags |= inventoryHasModTime
+	}
+	class := 0
+	if storageClass != "" {
+		for i, name := range inv.storageClasses {
+			if name == storageClass {
+				class = i + 1
+				break
+			}
+		}
+		if class == 0 {
+			if len(inv.storageClasses) >= 255 {
+				return errors.Errorf("too many storage classes in inventory")
+			}
+			inv.storageClasses = append(inv.storageClasses, storageClass)
+			class = len(inv.storageClasses)
+		}
+	}
+	if len(etag) > 255 {
+		etag = ""
+	}
+	var buf [binary.MaxVarintLen64]byte
+	start := len(inv.data)
+	inv.data = append(inv.data, buf[:binary.PutUvarint(buf[:], uint64(len(key)))]...)
+	inv.data = append(inv.data, key...)
+	inv.data = append(inv.data, flags)
+	inv.data = append(inv.data, buf[:binary.PutVarint(buf[:], size)]...)
+	inv.data = append(inv.data, buf[:binary.PutVarint(buf[:], modTime.UnixNano())]...)
+	inv.data = append(inv.data, byte(class), byte(len(etag)))
+	inv.data = append(inv.data, etag...)
+	inv.offsets = append(inv.offsets, start)
+	if used := int64(cap(inv.data)) + int64(cap(inv.offsets))*8; used > inv.maxMemory {
+		return errors.Errorf("inventory needs more than --s3-inventory-max-memory %v", fs.SizeSuffix(inv.maxMemory))
+	}
+	return nil
+}
+
+// len returns the number of objects in the inventory
+func (inv *inventory) len() int {
+	return len(inv.offsets)
+}
+
+// key returns the key of object i without copying it
+func (inv *inventory) key(i int) []byte {
+	data := inv.data[inv.offsets[i]:]
+	n, l := binary.Uvarint(data)
+	return data[l : l+int(n)]
+}
+
+// object unpacks object i
+func (inv *inventory) object(i int) *s3.Object {
+	data := inv.data[inv.offsets[i]:]
+	n, l := binary.Uvarint(data)
+	data = data[l:]
+	object := &s3.Object{
+		Key: aws.String(string(data[:n])),
+	}
+	data = data[n:]
+	flags := data[0]
+	data = data[1:]
+	size, l := binary.Varint(data)
+	data = data[l:]
+	modTime, l := binary.Varint(data)
+	data = data[l:]
+	class, etagLen := data[0], int(data[1])
+	etag := data[2 : 2+etagLen]
+	if flags&inventoryHasSize != 0 {
+		object.Size = aws.Int64(size)
+	}
+	if flags&inventoryHasModTime != 0 {
+		object.LastModified = aws.Time(time.Unix(0, modTime).UTC())
+	}
+	if class != 0 {
+		object.StorageClass = aws.String(inv.storageClasses[class-1])
+	}
+	if etagLen > 0 {
+		object.ETag = aws.String(`"` + string(etag) + `"`)
+	}
+	return object
+}
+
+// touch records that an object in bucket has changed so listings of
+// its directory, or any parent directory, can no longer come from the
+// report.
+func (inv *inventory) touch(bucket, bucketPath string) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	dir := path.Dir(bucketPath)
+	for {
+		if dir == "." || dir == "/" {
+			dir = ""
+		}
+		inv.touched[path.Join(bucket, dir)] = struct{}{}
+		if dir == "" {
+			break
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// touchInventory records that the object at bucketPath in bucket is
+// being changed if listing from an inventory
+func (f *Fs) touchInventory(bucket, bucketPath string) {
+	if f.inventory != nil {
+		f.inventory.touch(bucket, bucketPath)
+	}
+}
+
+// isTouched returns true if directory in bucket or anything under it
+// has changed since the report was made
+func (inv *inventory) isTouched(bucket, directory string) bool {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	_, found := inv.touched[path.Join(bucket, directory)]
+	return found
+}
+
+// canListInventory returns true if the listing of directory in
+// bucket can be read from the inventory
+func (f *Fs) canListInventory(ctx context.Context, bucket, directory string) bool {
+	inv := f.inventory
+	// The report only has the latest versions of the objects
+	if inv == nil || f.opt.Versions || !f.versionAt.IsZero() {
+		return false
+	}
+	inv.loadOnce.Do(func() {
+		inv.loadErr = inv.load(ctx, f)
+		if inv.loadErr != nil {
+			inv.data, inv.offsets = nil, nil
+			fs.Errorf(f, "Failed to read S3 Inventory report - using live listings: %v", inv.loadErr)
+		} else {
+			fs.Infof(f, "Read %d objects in bucket %q from S3 Inventory report using %v", inv.len(), inv.bucket, fs.SizeSuffix(len(inv.data)))
+		}
+	})
+	if inv.loadErr != nil || bucket != inv.bucket {
+		return false
+	}
+	if inv.isTouched(bucket, directory) {
+		fs.Debugf(f, "Using live listing for %q as it has changed since the S3 Inventory report", path.Join(bucket, directory))
+		return false
+	}
+	return true
+}
+
+// getObject opens bucket/key for reading
+func (f *Fs) getObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	req := s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	}
+	if f.opt.RequesterPays {
+		req.RequestPayer = aws.String(s3.RequestPayerRequester)
+	}
+	var resp *s3.GetObjectOutput
+	err := f.pacer.Call(func() (bool, error) {
+		var err error
+		resp, err = f.c.GetObjectWithContext(ctx, &req)
+		return f.shouldRetry(ctx, err)
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q", path.Join(bucket, key))
+	}
+	return resp.Body, nil
+}
+
+// load reads the manifest and all the files of the report
+func (inv *inventory) load(ctx context.Context, f *Fs) (err error) {
+	manifestBucket, manifestKey := bucket.Split(inv.manifest)
+	if manifestBucket == "" || manifestKey == "" {
+		return errors.Errorf("inventory manifest %q should be in the form bucket/path/manifest.json", inv.manifest)
+	}
+	in, err := f.getObject(ctx, manifestBucket, manifestKey)
+	if err != nil {
+		return err
+	}
+	var manifest inventoryManifest
+	err = json.NewDecoder(in).Decode(&manifest)
+	_ = in.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to decode inventory manifest")
+	}
+	if !strings.EqualFold(manifest.FileFormat, "CSV") {
+		return errors.Errorf("inventory file format %q not supported - only CSV is, not Parquet or ORC", manifest.FileFormat)
+	}
+	if ms, err := strconv.ParseInt(manifest.CreationTimestamp, 10, 64); err == nil {
+		fs.Debugf(f, "S3 Inventory report for %q was made at %v", manifest.SourceBucket, time.Unix(0, ms*int64(time.Millisecond)))
+	}
+	inv.bucket = manifest.SourceBucket
+	dataBucket := strings.TrimPrefix(manifest.DestinationBucket, "arn:aws:s3:::")
+	for _, file := range manifest.Files {
+		err = inv.loadFile(ctx, f, manifest.FileSchema, dataBucket, file.Key)
+		if err != nil {
+			return err
+		}
+	}
+	inv.sort()
+	return nil
+}
+
+// sort the objects by key so they can be searched
+func (inv *inventory) sort() {
+	sort.Slice(inv.offsets, func(i, j int) bool {
+		return bytes.Compare(inv.key(i), inv.key(j)) < 0
+	})
+}
+
+// loadFile reads a gzipped CSV file of the report described by schema
+func (inv *inventory) loadFile(ctx context.Context, f *Fs, schema, dataBucket, key string) (err error) {
+	in, err := f.getObject(ctx, dataBucket, key)
+	if err != nil {
+		return err
+	}
+	defer fs.CheckClose(in, &err)
+	var r io.Reader = in
+	if strings.HasSuffix(key, ".gz") {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decompress %q", key)
+		}
+		defer fs.CheckClose(gz, &err)
+		r = gz
+	}
+	return inv.readCSV(r, schema)
+}
+
+// readCSV reads the objects from the CSV in r described by schema
+func (inv *inventory) readCSV(r io.Reader, schema string) error {
+	columns := map[string]int{}
+	for i, name := range strings.Split(schema, ",") {
+		columns[strings.TrimSpace(name)] = i
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	if _, ok := columns["Key"]; !ok {
+		return errors.Errorf("inventory schema %q has no Key field", schema)
+	}
+	in := csv.NewReader(r)
+	in.FieldsPerRecord = -1
+	for {
+		record, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read inventory CSV")
+		}
+		// Skip old versions and delete markers in versioned reports
+		if field(record, "IsLatest") == "false" || field(record, "IsDeleteMarker") == "true" {
+			continue
+		}
+		// Keys are URL encoded in CSV reports
+		key, err := url.QueryUnescape(field(record, "Key"))
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode inventory key %q", field(record, "Key"))
+		}
+		size, sizeErr := strconv.ParseInt(field(record, "Size"), 10, 64)
+		modTime, modTimeErr := time.Parse(time.RFC3339Nano, field(record, "LastModifiedDate"))
+		err = inv.add(key, size, sizeErr == nil, modTime, modTimeErr == nil, field(record, "ETag"), field(record, "StorageClass"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listInventory lists the objects from the inventory into the
+// function supplied in the same way as list
+func (f *Fs) listInventory(bucket, directory, prefix string, addBucket bool, recurse bool, fn listFn) error {
+	if prefix != "" {
+		prefix += "/"
+	}
+	if directory != "" {
+		directory += "/"
+	}
+	inv := f.inventory
+	i := sort.Search(inv.len(), func(i int) bool {
+		return string(inv.key(i)) >= directory
+	})
+	lastDir := ""
+	for ; i < inv.len() && bytes.HasPrefix(inv.key(i), []byte(directory)); i++ {
+		key := string(inv.key(i))
+		isDirectory := false
+		if !recurse {
+			// Send the first object in each sub directory as a directory
+			if slash := strings.IndexRune(key[len(directory):], '/'); slash >= 0 {
+				key = key[:len(directory)+slash+1]
+				if key == lastDir {
+					continue
+				}
+				lastDir = key
+				isDirectory = true
+			}
+		}
+		remote := f.opt.Enc.ToStandardPath(key)
+		if !strings.HasPrefix(remote, prefix) {
+			fs.Logf(f, "Odd name received %q", remote)
+			continue
+		}
+		remote = remote[len(prefix):]
+		if isDirectory {
+			if addBucket {
+				remote = path.Join(bucket, remote)
+			}
+			remote = strings.TrimSuffix(remote, "/")
+			err := fn(remote, &s3.Object{Key: &remote}, nil, true)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		object := inv.object(i)
+		// is this a directory marker?
+		if (remote == "" || strings.HasSuffix(remote, "/")) && aws.Int64Value(object.Size) == 0 {
+			continue
+		}
+		if addBucket {
+			remote = path.Join(bucket, remote)
+		}
+		err := fn(remote, object, nil, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
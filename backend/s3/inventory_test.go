@@ -0,0 +1,93 @@
+package s3
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/lib/encoder"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInventoryCSV = `"bucket","dir/file%201.txt","1","2021-06-01T10:00:00.000Z","d41d8cd98f00b204e9800998ecf8427e","STANDARD","true","false"
+"bucket","dir/sub/file2.txt","2","2021-06-01T10:00:00.000Z","d41d8cd98f00b204e9800998ecf8427e","STANDARD","true","false"
+"bucket","dir/old.txt","3","2021-06-01T10:00:00.000Z","d41d8cd98f00b204e9800998ecf8427e","STANDARD","false","false"
+"bucket","dir/deleted.txt","0","2021-06-01T10:00:00.000Z","","STANDARD","true","true"
+"bucket","dir/marker/","0","2021-06-01T10:00:00.000Z","d41d8cd98f00b204e9800998ecf8427e","STANDARD","true","false"
+"bucket","top.txt","4","2021-06-01T10:00:00.000Z","d41d8cd98f00b204e9800998ecf8427e","GLACIER","true","false"
+`
+
+func TestInventoryList(t *testing.T) {
+	inv := newInventory("inventory/manifest.json", 1024*1024)
+	err := inv.readCSV(strings.NewReader(testInventoryCSV), "Bucket, Key, Size, LastModifiedDate, ETag, StorageClass, IsLatest, IsDeleteMarker")
+	require.NoError(t, err)
+	inv.bucket = "bucket"
+	require.Equal(t, 4, inv.len())
+	inv.sort()
+	f := &Fs{inventory: inv}
+	f.opt.Enc = encoder.Display
+
+	type entry struct {
+		remote string
+		isDir  bool
+	}
+	list := func(directory, prefix string, addBucket, recurse bool) (entries []entry) {
+		err := f.listInventory("bucket", directory, prefix, addBucket, recurse, func(remote string, object *s3.Object, versionID *string, isDirectory bool) error {
+			entries = append(entries, entry{remote, isDirectory})
+			return nil
+		})
+		require.NoError(t, err)
+		return entries
+	}
+
+	assert.Equal(t, []entry{{"dir", true}, {"top.txt", false}}, list("", "", false, false))
+	assert.Equal(t, []entry{{"bucket/dir", true}, {"bucket/top.txt", false}}, list("", "", true, false))
+	assert.Equal(t, []entry{{"file 1.txt", false}, {"marker", true}, {"sub", true}}, list("dir", "dir", false, false))
+	assert.Equal(t, []entry{{"file 1.txt", false}, {"sub/file2.txt", false}}, list("dir", "dir", false, true))
+
+	o := inv.object(0)
+	assert.Equal(t, "dir/file 1.txt", aws.StringValue(o.Key))
+	assert.Equal(t, int64(1), aws.Int64Value(o.Size))
+	assert.Equal(t, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC), aws.TimeValue(o.LastModified))
+	assert.Equal(t, `"d41d8cd98f00b204e9800998ecf8427e"`, aws.StringValue(o.ETag))
+	assert.Equal(t, "STANDARD", aws.StringValue(o.StorageClass))
+	assert.Equal(t, "GLACIER", aws.StringValue(inv.object(3).StorageClass))
+
+	// Check touched directories
+	assert.False(t, inv.isTouched("bucket", "dir"))
+	inv.touch("bucket", "dir/sub/new.txt")
+	assert.True(t, inv.isTouched("bucket", "dir/sub"))
+	assert.True(t, inv.isTouched("bucket", "dir"))
+	assert.True(t, inv.isTouched("bucket", ""))
+	assert.False(t, inv.isTouched("bucket", "other"))
+}
+
+func TestInventoryPacking(t *testing.T) {
+	inv := newInventory("inventory/manifest.json", 1024*1024)
+	modTime := time.Date(2021, 6, 1, 10, 0, 0, 123456789, time.UTC)
+	require.NoError(t, inv.add("b", 42, true, modTime, true, "etag-2", "GLACIER"))
+	require.NoError(t, inv.add("a", 0, false, time.Time{}, false, "", ""))
+	require.NoError(t, inv.add("c", 1, true, modTime, true, "", "GLACIER"))
+	inv.sort()
+	require.Equal(t, 3, inv.len())
+	assert.Equal(t, &s3.Object{Key: aws.String("a")}, inv.object(0))
+	assert.Equal(t, &s3.Object{
+		Key:          aws.String("b"),
+		Size:         aws.Int64(42),
+		LastModified: aws.Time(modTime),
+		ETag:         aws.String(`"etag-2"`),
+		StorageClass: aws.String("GLACIER"),
+	}, inv.object(1))
+	assert.Equal(t, "c", string(inv.key(2)))
+	assert.Equal(t, []string{"GLACIER"}, inv.storageClasses)
+}
+
+func TestInventoryMaxMemory(t *testing.T) {
+	inv := newInventory("inventory/manifest.json", 100)
+	err := inv.readCSV(strings.NewReader(testInventoryCSV), "Bucket, Key, Size, LastModifiedDate, ETag, StorageClass, IsLatest, IsDeleteMarker")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "inventory-max-memory")
+}
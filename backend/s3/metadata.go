@@ -0,0 +1,84 @@
+// Metadata reading and writing
+
+package s3
+
+import (
+	"context"
+	"net/textproto"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+// isInternalMeta returns true if key is user metadata that rclone
+// uses itself
+func isInternalMeta(key string) bool {
+	key = textproto.CanonicalMIMEHeaderKey(key)
+	return key == metaMtime || key == metaMD5Hash
+}
+
+// Metadata returns the user metadata of the object with the keys in
+// lower case, leaving out the keys rclone uses to store the
+// modification time and MD5.
+func (o *Object) Metadata(ctx context.Context) (fs.Metadata, error) {
+	err := o.readMetaData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata := make(fs.Metadata, len(o.meta))
+	for key, value := range o.meta {
+		if value == nil || isInternalMeta(key) {
+			continue
+		}
+		metadata[strings.ToLower(key)] = *value
+	}
+	return metadata, nil
+}
+
+// SetMetadata stores metadata as user metadata on the object.
+//
+// S3 can't change the metadata of an existing object so the object is
+// copied onto itself, as with SetModTime.
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	err := o.checkModify()
+	if err != nil {
+		return err
+	}
+	err = o.readMetaData(ctx)
+	if err != nil {
+		return err
+	}
+	if o.storageClass == "GLACIER" || o.storageClass == "DEEP_ARCHIVE" {
+		return errors.Errorf("can't set metadata on objects with storage class %s", o.storageClass)
+	}
+	if o.meta == nil {
+		o.meta = make(map[string]*string, len(metadata))
+	}
+	for key, value := range metadata {
+		if isInternalMeta(key) {
+			continue
+		}
+		o.meta[textproto.CanonicalMIMEHeaderKey(key)] = aws.String(value)
+	}
+
+	// Copy the object to itself to update the metadata
+	bucket, bucketPath := o.split()
+	req := s3.CopyObjectInput{
+		ContentType:       aws.String(fs.MimeType(ctx, o)), // Guess the content type
+		Metadata:          o.meta,
+		MetadataDirective: aws.String(s3.MetadataDirectiveReplace), // replace metadata with that passed in
+	}
+	if o.fs.opt.RequesterPays {
+		req.RequestPayer = aws.String(s3.RequestPayerRequester)
+	}
+	return o.fs.copy(ctx, &req, bucket, bucketPath, bucket, bucketPath, o)
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Metadataer    = &Object{}
+	_ fs.SetMetadataer = &Object{}
+)
@@ -52,6 +52,7 @@ import (
 	"github.com/artpar/rclone/lib/readers"
 	"github.com/artpar/rclone/lib/rest"
 	"github.com/artpar/rclone/lib/structs"
+	"github.com/artpar/rclone/lib/version"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -920,6 +921,17 @@ isn't set then "acl" is used instead.`,
 				Value: "",
 				Help:  "None",
 			}},
+		}, {
+			Name: "sse_customer_key_base64",
+			Help: `If using SSE-C you must provide the secret encryption key encoded in base64 format to encrypt/decrypt your data.
+
+Alternatively you can provide --s3-sse-customer-key.`,
+			Provider: "AWS,Ceph,Minio",
+			Advanced: true,
+			Examples: []fs.OptionExample{{
+				Value: "",
+				Help:  "None",
+			}},
 		}, {
 			Name: "sse_customer_key_md5",
 			Help: `If using SSE-C you may provide the secret encryption key MD5 checksum (optional).
@@ -1250,6 +1262,62 @@ This option controls how often unused buffers will be removed from the pool.`,
 			Default:  memoryPoolUseMmap,
 			Advanced: true,
 			Help:     `Whether to use mmap buffers in internal memory pool.`,
+		}, {
+			Name:     "inventory_manifest",
+			Advanced: true,
+			Help: `Read listings from this S3 Inventory report rather than LIST calls
+
+This should be the bucket and path of the manifest.json of an S3
+Inventory report in CSV format, e.g.
+
+    inventory-bucket/source-bucket/daily/2021-06-01T01-00Z/manifest.json
+
+Listings of the bucket the report was made for will be read from the
+report instead of making LIST calls. This is much quicker and cheaper
+for enormous buckets, but the listings will be as old as the report.
+
+Once rclone has changed an object in a directory that directory, and
+its parents, will be listed live again for the rest of the run.
+
+Only reports in CSV format can be read - Parquet and ORC reports
+aren't supported and the live listings will be used instead.
+`,
+		}, {
+			Name:     "inventory_max_memory",
+			Default:  fs.SizeSuffix(1024 * 1024 * 1024),
+			Advanced: true,
+			Help: `Maximum memory to use to store the S3 Inventory report
+
+The objects in the report are stored compactly in memory (roughly
+the length of the key plus 30 bytes each). If the report needs more
+than this then rclone will use live listings instead.`,
+		}, {
+			Name:     "versions",
+			Default:  false,
+			Advanced: true,
+			Help: `Include old versions in directory listings.
+
+In a bucket with versioning enabled the old versions of files are
+shown with their modification time added to their names, like
+"file-v2006-01-02-150405-000.txt". These can be read, copied or
+removed - removing an old version deletes it permanently.
+
+Note that files which have been deleted only have old versions so
+they are listed with versions in their names too.`,
+		}, {
+			Name:     "version_at",
+			Default:  "",
+			Advanced: true,
+			Help: `Show the files as they were at the time specified.
+
+This shows the bucket as it was at the time given, using the old
+versions of files from a bucket with versioning enabled. It can be
+a date "2006-01-02", a datetime "2006-01-02 15:04:05" or
+"2006-01-02T15:04:05Z07:00" or a duration ago like "1d" or "2h30m".
+Times without a time zone are in local time.
+
+Only reading is allowed in this mode - files can't be uploaded,
+modified or deleted. This can't be used with --s3-versions.`,
 		}, {
 			Name:     "disable_http2",
 			Default:  false,
@@ -1263,7 +1331,7 @@ disabled here.  When the issue is solved this flag will be removed.
 See: https://github.com/artpar/rclone/issues/4673, https://github.com/artpar/rclone/issues/3631
 
 `,
-		},
+		}, fs.ProxyOption, fs.APIBudgetOption,
 		}})
 }
 
@@ -1301,6 +1369,7 @@ type Options struct {
 	SSEKMSKeyID           string               `config:"sse_kms_key_id"`
 	SSECustomerAlgorithm  string               `config:"sse_customer_algorithm"`
 	SSECustomerKey        string               `config:"sse_customer_key"`
+	SSECustomerKeyBase64  string               `config:"sse_customer_key_base64"`
 	SSECustomerKeyMD5     string               `config:"sse_customer_key_md5"`
 	StorageClass          string               `config:"storage_class"`
 	UploadCutoff          fs.SizeSuffix        `config:"upload_cutoff"`
@@ -1323,6 +1392,10 @@ type Options struct {
 	MemoryPoolFlushTime   fs.Duration          `config:"memory_pool_flush_time"`
 	MemoryPoolUseMmap     bool                 `config:"memory_pool_use_mmap"`
 	DisableHTTP2          bool                 `config:"disable_http2"`
+	InventoryManifest     string               `config:"inventory_manifest"`
+	InventoryMaxMemory    fs.SizeSuffix        `config:"inventory_max_memory"`
+	Versions              bool                 `config:"versions"`
+	VersionAt             string               `config:"version_at"`
 }
 
 // Fs represents a remote s3 server
@@ -1342,6 +1415,8 @@ type Fs struct {
 	srv           *http.Client     // a plain http client
 	pool          *pool.Pool       // memory pool
 	etagIsNotMD5  bool             // if set ETags are not MD5s
+	inventory     *inventory       // S3 Inventory report to list from if set
+	versionAt     time.Time        // show the bucket as it was at this time if set
 }
 
 // Object describes a s3 object
@@ -1358,6 +1433,9 @@ type Object struct {
 	meta         map[string]*string // The object metadata if known - may be nil
 	mimeType     string             // MimeType of object - may be ""
 	storageClass string             // e.g. GLACIER
+	versionID    *string            // version of the object to read if set
+	lockedUntil  time.Time          // object lock retention date if set - read with meta
+	legalHold    bool               // set if the object is under a legal hold - read with meta
 }
 
 // ------------------------------------------------------------
@@ -1448,7 +1526,11 @@ func (f *Fs) split(rootRelativePath string) (bucketName, bucketPath string) {
 
 // split returns bucket and bucketPath from the object
 func (o *Object) split() (bucket, bucketPath string) {
-	return o.fs.split(o.remote)
+	remote := o.remote
+	if o.versionID != nil && o.fs.opt.Versions {
+		_, remote = version.Remove(remote)
+	}
+	return o.fs.split(remote)
 }
 
 // getClient makes an http client according to the options
@@ -1622,6 +1704,45 @@ func (f *Fs) setRoot(root string) {
 	f.rootBucket, f.rootDirectory = bucket.Split(f.root)
 }
 
+// checkSSE checks the server-side encryption options are consistent
+// and fills in the SSE-C key and its MD5 from the other options.
+func checkSSE(opt *Options) error {
+	if opt.SSECustomerKeyBase64 != "" {
+		if opt.SSECustomerKey != "" {
+			return errors.New("sse_customer_key and sse_customer_key_base64 can't both be set")
+		}
+		key, err := base64.StdEncoding.DecodeString(opt.SSECustomerKeyBase64)
+		if err != nil {
+			return errors.Wrap(err, "bad sse_customer_key_base64")
+		}
+		opt.SSECustomerKey = string(key)
+		opt.SSECustomerKeyBase64 = ""
+	}
+	if (opt.SSECustomerKey == "") != (opt.SSECustomerAlgorithm == "") {
+		return errors.New("sse_customer_algorithm and sse_customer_key must be set together for SSE-C")
+	}
+	if opt.SSECustomerKey != "" {
+		if opt.ServerSideEncryption != "" {
+			return errors.New("server_side_encryption can't be used with SSE-C")
+		}
+		if opt.SSEKMSKeyID != "" {
+			return errors.New("sse_kms_key_id can't be used with SSE-C")
+		}
+		if len(opt.SSECustomerKey) != 32 {
+			return errors.Errorf("SSE-C key must be 32 bytes long not %d", len(opt.SSECustomerKey))
+		}
+		if opt.SSECustomerKeyMD5 == "" {
+			// calculate CustomerKeyMD5 if not supplied
+			md5sumBinary := md5.Sum([]byte(opt.SSECustomerKey))
+			opt.SSECustomerKeyMD5 = base64.StdEncoding.EncodeToString(md5sumBinary[:])
+		}
+	}
+	if opt.SSEKMSKeyID != "" && opt.ServerSideEncryption != "aws:kms" {
+		return errors.New("sse_kms_key_id needs server_side_encryption set to aws:kms")
+	}
+	return nil
+}
+
 // NewFs constructs an Fs from the path, bucket:path
 func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, error) {
 	// Parse config into Options struct
@@ -1644,10 +1765,19 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	if opt.BucketACL == "" {
 		opt.BucketACL = opt.ACL
 	}
-	if opt.SSECustomerKey != "" && opt.SSECustomerKeyMD5 == "" {
-		// calculate CustomerKeyMD5 if not supplied
-		md5sumBinary := md5.Sum([]byte(opt.SSECustomerKey))
-		opt.SSECustomerKeyMD5 = base64.StdEncoding.EncodeToString(md5sumBinary[:])
+	err = checkSSE(opt)
+	if err != nil {
+		return nil, errors.Wrap(err, "s3")
+	}
+	var versionAt time.Time
+	if opt.VersionAt != "" {
+		if opt.Versions {
+			return nil, errors.New("s3: can't use --s3-versions and --s3-version-at together")
+		}
+		versionAt, err = parseVersionAt(opt.VersionAt, time.Now())
+		if err != nil {
+			return nil, errors.Wrap(err, "s3: version at")
+		}
 	}
 	srv := getClient(ctx, opt)
 	c, ses, err := s3Connection(ctx, opt, srv)
@@ -1683,6 +1813,10 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		// MD5 digest of their object data.
 		f.etagIsNotMD5 = true
 	}
+	f.versionAt = versionAt
+	if opt.InventoryManifest != "" {
+		f.inventory = newInventory(opt.InventoryManifest, int64(opt.InventoryMaxMemory))
+	}
 	f.setRoot(root)
 	f.features = (&fs.Features{
 		ReadMimeType:      true,
@@ -1692,6 +1826,8 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		SetTier:           true,
 		GetTier:           true,
 		SlowModTime:       true,
+		ReadMetadata:      true,
+		WriteMetadata:     true,
 	}).Fill(ctx, f)
 	if f.rootBucket != "" && f.rootDirectory != "" {
 		// Check to see if the (bucket,directory) is actually an existing file
@@ -1714,10 +1850,11 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 // Return an Object from a path
 //
 //If it can't be found it returns the error ErrorObjectNotFound.
-func (f *Fs) newObjectWithInfo(ctx context.Context, remote string, info *s3.Object) (fs.Object, error) {
+func (f *Fs) newObjectWithInfo(ctx context.Context, remote string, info *s3.Object, versionID *string) (fs.Object, error) {
 	o := &Object{
-		fs:     f,
-		remote: remote,
+		fs:        f,
+		remote:    remote,
+		versionID: versionID,
 	}
 	if info != nil {
 		// Set info but not meta
@@ -1742,7 +1879,14 @@ func (f *Fs) newObjectWithInfo(ctx context.Context, remote string, info *s3.Obje
 // NewObject finds the Object at remote.  If it can't be found
 // it returns the error fs.ErrorObjectNotFound.
 func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
-	return f.newObjectWithInfo(ctx, remote, nil)
+	if !f.versionAt.IsZero() {
+		return f.newObjectVersion(ctx, remote)
+	}
+	o, err := f.newObjectWithInfo(ctx, remote, nil, nil)
+	if err == fs.ErrorObjectNotFound && f.opt.Versions && version.Match(remote) {
+		return f.newObjectVersion(ctx, remote)
+	}
+	return o, err
 }
 
 // Gets the bucket location
@@ -1791,7 +1935,9 @@ func (f *Fs) updateRegionForBucket(ctx context.Context, bucket string) error {
 }
 
 // listFn is called from list to handle an object.
-type listFn func(remote string, object *s3.Object, isDirectory bool) error
+//
+// versionID is set if the object is a particular version of the file.
+type listFn func(remote string, object *s3.Object, versionID *string, isDirectory bool) error
 
 // list lists the objects into the function supplied from
 // the bucket and directory supplied.  The remote has prefix
@@ -1806,6 +1952,9 @@ func (f *Fs) list(ctx context.Context, bucket, directory, prefix string, addBuck
 	if directory != "" {
 		directory += "/"
 	}
+	if f.opt.Versions || !f.versionAt.IsZero() {
+		return f.listVersions(ctx, bucket, directory, prefix, addBucket, recurse, fn)
+	}
 	delimiter := ""
 	if !recurse {
 		delimiter = "/"
@@ -1906,7 +2055,7 @@ func (f *Fs) list(ctx context.Context, bucket, directory, prefix string, addBuck
 				if strings.HasSuffix(remote, "/") {
 					remote = remote[:len(remote)-1]
 				}
-				err = fn(remote, &s3.Object{Key: &remote}, true)
+				err = fn(remote, &s3.Object{Key: &remote}, nil, true)
 				if err != nil {
 					return err
 				}
@@ -1935,7 +2084,7 @@ func (f *Fs) list(ctx context.Context, bucket, directory, prefix string, addBuck
 			if isDirectory && object.Size != nil && *object.Size == 0 {
 				continue // skip directory marker
 			}
-			err = fn(remote, object, false)
+			err = fn(remote, object, nil, false)
 			if err != nil {
 				return err
 			}
@@ -1963,7 +2112,7 @@ func (f *Fs) list(ctx context.Context, bucket, directory, prefix string, addBuck
 }
 
 // Convert a list item into a DirEntry
-func (f *Fs) itemToDirEntry(ctx context.Context, remote string, object *s3.Object, isDirectory bool) (fs.DirEntry, error) {
+func (f *Fs) itemToDirEntry(ctx context.Context, remote string, object *s3.Object, versionID *string, isDirectory bool) (fs.DirEntry, error) {
 	if isDirectory {
 		size := int64(0)
 		if object.Size != nil {
@@ -1972,7 +2121,7 @@ func (f *Fs) itemToDirEntry(ctx context.Context, remote string, object *s3.Objec
 		d := fs.NewDir(remote, time.Time{}).SetSize(size)
 		return d, nil
 	}
-	o, err := f.newObjectWithInfo(ctx, remote, object)
+	o, err := f.newObjectWithInfo(ctx, remote, object, versionID)
 	if err != nil {
 		return nil, err
 	}
@@ -1982,8 +2131,14 @@ func (f *Fs) itemToDirEntry(ctx context.Context, remote string, object *s3.Objec
 // listDir lists files and directories to out
 func (f *Fs) listDir(ctx context.Context, bucket, directory, prefix string, addBucket bool) (entries fs.DirEntries, err error) {
 	// List the objects and directories
-	err = f.list(ctx, bucket, directory, prefix, addBucket, false, func(remote string, object *s3.Object, isDirectory bool) error {
-		entry, err := f.itemToDirEntry(ctx, remote, object, isDirectory)
+	list := f.list
+	if f.canListInventory(ctx, bucket, directory) {
+		list = func(ctx context.Context, bucket, directory, prefix string, addBucket bool, recurse bool, fn listFn) error {
+			return f.listInventory(bucket, directory, prefix, addBucket, recurse, fn)
+		}
+	}
+	err = list(ctx, bucket, directory, prefix, addBucket, false, func(remote string, object *s3.Object, versionID *string, isDirectory bool) error {
+		entry, err := f.itemToDirEntry(ctx, remote, object, versionID, isDirectory)
 		if err != nil {
 			return err
 		}
@@ -2060,13 +2215,17 @@ func (f *Fs) ListR(ctx context.Context, dir string, callback fs.ListRCallback) (
 	bucket, directory := f.split(dir)
 	list := walk.NewListRHelper(callback)
 	listR := func(bucket, directory, prefix string, addBucket bool) error {
-		return f.list(ctx, bucket, directory, prefix, addBucket, true, func(remote string, object *s3.Object, isDirectory bool) error {
-			entry, err := f.itemToDirEntry(ctx, remote, object, isDirectory)
+		fn := func(remote string, object *s3.Object, versionID *string, isDirectory bool) error {
+			entry, err := f.itemToDirEntry(ctx, remote, object, versionID, isDirectory)
 			if err != nil {
 				return err
 			}
 			return list.Add(entry)
-		})
+		}
+		if f.canListInventory(ctx, bucket, directory) {
+			return f.listInventory(bucket, directory, prefix, addBucket, true, fn)
+		}
+		return f.list(ctx, bucket, directory, prefix, addBucket, true, fn)
 	}
 	if bucket == "" {
 		entries, err := f.listBuckets(ctx)
@@ -2134,8 +2293,31 @@ func (f *Fs) bucketExists(ctx context.Context, bucket string) (bool, error) {
 	return false, err
 }
 
+// HealthCheck checks the credentials are valid by looking at the
+// root bucket if there is one, or listing the buckets if not.
+func (f *Fs) HealthCheck(ctx context.Context) error {
+	if f.rootBucket == "" {
+		req := s3.ListBucketsInput{}
+		return f.pacer.Call(func() (bool, error) {
+			_, err := f.c.ListBucketsWithContext(ctx, &req)
+			return f.shouldRetry(ctx, err)
+		})
+	}
+	exists, err := f.bucketExists(ctx, f.rootBucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fs.ErrorDirNotFound
+	}
+	return nil
+}
+
 // Mkdir creates the bucket if it doesn't exist
 func (f *Fs) Mkdir(ctx context.Context, dir string) error {
+	if !f.versionAt.IsZero() {
+		return errNotWithVersionAt
+	}
 	bucket, _ := f.split(dir)
 	return f.makeBucket(ctx, bucket)
 }
@@ -2177,6 +2359,9 @@ func (f *Fs) makeBucket(ctx context.Context, bucket string) error {
 //
 // Returns an error if it isn't empty
 func (f *Fs) Rmdir(ctx context.Context, dir string) error {
+	if !f.versionAt.IsZero() {
+		return errNotWithVersionAt
+	}
 	bucket, directory := f.split(dir)
 	if bucket == "" || directory != "" {
 		return nil
@@ -2212,10 +2397,14 @@ func pathEscape(s string) string {
 // It adds the boiler plate to the req passed in and calls the s3
 // method
 func (f *Fs) copy(ctx context.Context, req *s3.CopyObjectInput, dstBucket, dstPath, srcBucket, srcPath string, src *Object) error {
+	f.touchInventory(dstBucket, dstPath)
 	req.Bucket = &dstBucket
 	req.ACL = &f.opt.ACL
 	req.Key = &dstPath
 	source := pathEscape(path.Join(srcBucket, srcPath))
+	if src.versionID != nil {
+		source += "?versionId=" + url.QueryEscape(*src.versionID)
+	}
 	req.CopySource = &source
 	if f.opt.RequesterPays {
 		req.RequestPayer = aws.String(s3.RequestPayerRequester)
@@ -2366,6 +2555,9 @@ func (f *Fs) copyMultipart(ctx context.Context, copyReq *s3.CopyObjectInput, dst
 //
 // If it isn't possible then return fs.ErrorCantCopy
 func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	if !f.versionAt.IsZero() {
+		return nil, errNotWithVersionAt
+	}
 	dstBucket, dstPath := f.split(remote)
 	err := f.makeBucket(ctx, dstBucket)
 	if err != nil {
@@ -2410,17 +2602,19 @@ func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration,
 	if strings.HasSuffix(remote, "/") {
 		return "", fs.ErrorCantShareDirectories
 	}
-	if _, err := f.NewObject(ctx, remote); err != nil {
+	o, err := f.NewObject(ctx, remote)
+	if err != nil {
 		return "", err
 	}
 	if expire > maxExpireDuration {
 		fs.Logf(f, "Public Link: Reducing expiry to %v as %v is greater than the max time allowed", maxExpireDuration, expire)
 		expire = maxExpireDuration
 	}
-	bucket, bucketPath := f.split(remote)
+	bucket, bucketPath := o.(*Object).split()
 	httpReq, _ := f.c.GetObjectRequest(&s3.GetObjectInput{
-		Bucket: &bucket,
-		Key:    &bucketPath,
+		Bucket:    &bucket,
+		Key:       &bucketPath,
+		VersionId: o.(*Object).versionID,
 	})
 
 	return httpReq.Presign(time.Duration(expire))
@@ -2446,6 +2640,9 @@ All the objects shown will be marked for restore, then
 
     rclone backend restore --include "*.txt" s3:bucket/path -o priority=Standard
 
+Use the "restore-status" command to see when the objects have been
+restored.
+
 It returns a list of status dictionaries with Remote and Status
 keys. The Status will be OK if it was successful or an error message
 if not.
@@ -2467,6 +2664,46 @@ if not.
 		"lifetime":    "Lifetime of the active copy in days",
 		"description": "The optional description for the job.",
 	},
+}, {
+	Name:  "restore-status",
+	Short: "Show the restore status of objects in GLACIER",
+	Long: `This command shows the restore status of objects in GLACIER or
+DEEP_ARCHIVE so you can see when a restore started with the "restore"
+command has finished.
+
+Usage Examples:
+
+    rclone backend restore-status s3:bucket/path/to/object
+    rclone backend restore-status s3:bucket/path/to/directory
+    rclone backend restore-status -o all s3:bucket/path/to/directory
+
+This command obeys the filters and needs a HEAD request for each
+object.
+
+It returns a list of status dictionaries. The Status is "archived" if
+the object hasn't been restored, "restoring" if a restore is in
+progress or "restored" if the object can be read until RestoreExpiry.
+With "-o all" objects which don't need restoring are shown as
+"available".
+
+    [
+        {
+            "Remote": "file.txt",
+            "StorageClass": "GLACIER",
+            "Status": "restored",
+            "RestoreExpiry": "2021-06-09T00:00:00Z"
+        },
+        {
+            "Remote": "test/file4.txt",
+            "StorageClass": "DEEP_ARCHIVE",
+            "Status": "restoring"
+        }
+    ]
+
+`,
+	Opts: map[string]string{
+		"all": "if set then show all objects, not just ones in GLACIER",
+	},
 }, {
 	Name:  "list-multipart-uploads",
 	Short: "List the unfinished multipart uploads",
@@ -2581,8 +2818,8 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 			reqCopy := req
 			reqCopy.Bucket = &bucket
 			reqCopy.Key = &bucketPath
-			err = f.pacer.Call(func() (bool, error) {
-				_, err = f.c.RestoreObject(&reqCopy)
+			err := f.pacer.Call(func() (bool, error) {
+				_, err := f.c.RestoreObject(&reqCopy)
 				return f.shouldRetry(ctx, err)
 			})
 			if err != nil {
@@ -2593,6 +2830,9 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 			return out, err
 		}
 		return out, nil
+	case "restore-status":
+		_, all := opt["all"]
+		return f.restoreStatus(ctx, all)
 	case "list-multipart-uploads":
 		return f.listMultipartUploadsAll(ctx)
 	case "cleanup":
@@ -2609,6 +2849,94 @@ func (f *Fs) Command(ctx context.Context, name string, arg []string, opt map[str
 	}
 }
 
+// restoreStatus is the result of the restore-status backend command
+// for one object
+type restoreStatus struct {
+	Remote        string
+	StorageClass  string
+	Status        string
+	RestoreExpiry *time.Time `json:",omitempty"`
+}
+
+// isGlacier returns true if storageClass needs restoring before it
+// can be read
+func isGlacier(storageClass string) bool {
+	return storageClass == "GLACIER" || storageClass == "DEEP_ARCHIVE"
+}
+
+// restoreHeaderRe matches the key="value" pairs in the x-amz-restore
+// header
+var restoreHeaderRe = regexp.MustCompile(`([\w-]+)="([^"]*)"`)
+
+// parseRestore parses the x-amz-restore header returned by HEAD into
+// the Status and RestoreExpiry of st
+//
+// It looks like
+//
+//	ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"
+func (st *restoreStatus) parseRestore(header string) error {
+	if header == "" {
+		st.Status = "archived"
+		return nil
+	}
+	for _, match := range restoreHeaderRe.FindAllStringSubmatch(header, -1) {
+		value := match[2]
+		switch match[1] {
+		case "ongoing-request":
+			if value == "true" {
+				st.Status = "restoring"
+			} else {
+				st.Status = "restored"
+			}
+		case "expiry-date":
+			expiry, err := http.ParseTime(value)
+			if err != nil {
+				return errors.Wrapf(err, "bad expiry-date in %q", header)
+			}
+			expiry = expiry.UTC()
+			st.RestoreExpiry = &expiry
+		}
+	}
+	if st.Status == "" {
+		return errors.Errorf("can't parse restore status %q", header)
+	}
+	return nil
+}
+
+// restoreStatus reads the restore status of the objects in GLACIER,
+// or all the objects if all is set
+func (f *Fs) restoreStatus(ctx context.Context, all bool) (out []restoreStatus, err error) {
+	var outMu sync.Mutex
+	out = []restoreStatus{}
+	err = operations.ListFn(ctx, f, func(obj fs.Object) {
+		// Remember this is run --checkers times concurrently
+		o, ok := obj.(*Object)
+		if !ok || !(all || isGlacier(o.storageClass)) {
+			return
+		}
+		st := restoreStatus{Remote: o.remote, StorageClass: o.storageClass}
+		resp, err := o.headObject(ctx)
+		if err == nil {
+			st.StorageClass = aws.StringValue(resp.StorageClass)
+			if st.StorageClass == "" {
+				st.StorageClass = "STANDARD"
+			}
+			if isGlacier(st.StorageClass) {
+				err = st.parseRestore(aws.StringValue(resp.Restore))
+			} else {
+				st.Status = "available"
+			}
+		}
+		if err != nil {
+			st.Status = err.Error()
+		}
+		outMu.Lock()
+		out = append(out, st)
+		outMu.Unlock()
+	})
+	return out, err
+}
+
 // listMultipartUploads lists all outstanding multipart uploads for (bucket, key)
 //
 // Note that rather lazily we treat key as a prefix so it matches
@@ -2788,8 +3116,9 @@ func (o *Object) Size() int64 {
 func (o *Object) headObject(ctx context.Context) (resp *s3.HeadObjectOutput, err error) {
 	bucket, bucketPath := o.split()
 	req := s3.HeadObjectInput{
-		Bucket: &bucket,
-		Key:    &bucketPath,
+		Bucket:    &bucket,
+		Key:       &bucketPath,
+		VersionId: o.versionID,
 	}
 	if o.fs.opt.RequesterPays {
 		req.RequestPayer = aws.String(s3.RequestPayerRequester)
@@ -2862,9 +3191,28 @@ func (o *Object) readMetaData(ctx context.Context) (err error) {
 		o.lastModified = *resp.LastModified
 	}
 	o.mimeType = aws.StringValue(resp.ContentType)
+	o.lockedUntil = aws.TimeValue(resp.ObjectLockRetainUntilDate)
+	o.legalHold = aws.StringValue(resp.ObjectLockLegalHoldStatus) == s3.ObjectLockLegalHoldStatusOn
 	return nil
 }
 
+// ReadOnly returns true if the object is under an object lock
+// retention period or legal hold so can't be overwritten or deleted.
+//
+// This needs the object's metadata so if fetch is set it is read
+// with a HEAD request if it hasn't been already.
+func (o *Object) ReadOnly(ctx context.Context, fetch bool) (bool, error) {
+	if o.meta == nil {
+		if !fetch {
+			return false, nil
+		}
+		if err := o.readMetaData(ctx); err != nil {
+			return false, err
+		}
+	}
+	return o.legalHold || o.lockedUntil.After(time.Now()), nil
+}
+
 // ModTime returns the modification time of the object
 //
 // It attempts to read the objects mtime and if that isn't present the
@@ -2894,7 +3242,11 @@ func (o *Object) ModTime(ctx context.Context) time.Time {
 
 // SetModTime sets the modification time of the local fs object
 func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
-	err := o.readMetaData(ctx)
+	err := o.checkModify()
+	if err != nil {
+		return err
+	}
+	err = o.readMetaData(ctx)
 	if err != nil {
 		return err
 	}
@@ -2927,8 +3279,9 @@ func (o *Object) Storable() bool {
 func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.ReadCloser, err error) {
 	bucket, bucketPath := o.split()
 	req := s3.GetObjectInput{
-		Bucket: &bucket,
-		Key:    &bucketPath,
+		Bucket:    &bucket,
+		Key:       &bucketPath,
+		VersionId: o.versionID,
 	}
 	if o.fs.opt.RequesterPays {
 		req.RequestPayer = aws.String(s3.RequestPayerRequester)
@@ -3164,17 +3517,13 @@ func (o *Object) uploadMultipart(ctx context.Context, req *s3.PutObjectInput, si
 	return nil
 }
 
-// Update the Object from in with modTime and size
-func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+// prepareUpload makes the request to upload src to o, returning it
+// and the base64 encoded MD5 of src if known.
+//
+// multipart should be set if the upload will be done in parts.
+func (o *Object) prepareUpload(ctx context.Context, src fs.ObjectInfo, options []fs.OpenOption, multipart bool) (req s3.PutObjectInput, md5sum string) {
 	bucket, bucketPath := o.split()
-	err := o.fs.makeBucket(ctx, bucket)
-	if err != nil {
-		return err
-	}
 	modTime := src.ModTime(ctx)
-	size := src.Size()
-
-	multipart := size < 0 || size >= int64(o.fs.opt.UploadCutoff)
 
 	// Set the mtime in the meta data
 	metadata := map[string]*string{
@@ -3187,7 +3536,6 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 	//    - so we can add the md5sum in the metadata as metaMD5Hash if using SSE/SSE-C
 	// - for multipart provided checksums aren't disabled
 	//    - so we can add the md5sum in the metadata as metaMD5Hash
-	var md5sum string
 	if !multipart || !o.fs.opt.DisableChecksum {
 		hash, err := src.Hash(ctx, hash.MD5)
 		if err == nil && matchMd5.MatchString(hash) {
@@ -3207,7 +3555,7 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 
 	// Guess the content type
 	mimeType := fs.MimeType(ctx, src)
-	req := s3.PutObjectInput{
+	req = s3.PutObjectInput{
 		Bucket:      &bucket,
 		ACL:         &o.fs.opt.ACL,
 		Key:         &bucketPath,
@@ -3267,6 +3615,24 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 			}
 		}
 	}
+	return req, md5sum
+}
+
+// Update the Object from in with modTime and size
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	err := o.checkModify()
+	if err != nil {
+		return err
+	}
+	bucket, bucketPath := o.split()
+	o.fs.touchInventory(bucket, bucketPath)
+	err = o.fs.makeBucket(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	size := src.Size()
+	multipart := size < 0 || size >= int64(o.fs.opt.UploadCutoff)
+	req, md5sum := o.prepareUpload(ctx, src, options, multipart)
 
 	var resp *http.Response // response from PUT
 	if multipart {
@@ -3355,11 +3721,18 @@ func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, op
 }
 
 // Remove an object
+//
+// If the object is an old version then it is deleted permanently.
 func (o *Object) Remove(ctx context.Context) error {
+	if !o.fs.versionAt.IsZero() {
+		return errNotWithVersionAt
+	}
 	bucket, bucketPath := o.split()
+	o.fs.touchInventory(bucket, bucketPath)
 	req := s3.DeleteObjectInput{
-		Bucket: &bucket,
-		Key:    &bucketPath,
+		Bucket:    &bucket,
+		Key:       &bucketPath,
+		VersionId: o.versionID,
 	}
 	if o.fs.opt.RequesterPays {
 		req.RequestPayer = aws.String(s3.RequestPayerRequester)
@@ -3383,6 +3756,10 @@ func (o *Object) MimeType(ctx context.Context) string {
 
 // SetTier performs changing storage class
 func (o *Object) SetTier(tier string) (err error) {
+	err = o.checkModify()
+	if err != nil {
+		return err
+	}
 	ctx := context.TODO()
 	tier = strings.ToUpper(tier)
 	bucket, bucketPath := o.split()
@@ -3408,14 +3785,16 @@ func (o *Object) GetTier() string {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.Copier      = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.ListRer     = &Fs{}
-	_ fs.Commander   = &Fs{}
-	_ fs.CleanUpper  = &Fs{}
-	_ fs.Object      = &Object{}
-	_ fs.MimeTyper   = &Object{}
-	_ fs.GetTierer   = &Object{}
-	_ fs.SetTierer   = &Object{}
+	_ fs.Fs            = &Fs{}
+	_ fs.Copier        = &Fs{}
+	_ fs.PutStreamer   = &Fs{}
+	_ fs.ListRer       = &Fs{}
+	_ fs.Commander     = &Fs{}
+	_ fs.CleanUpper    = &Fs{}
+	_ fs.HealthChecker = &Fs{}
+	_ fs.Object        = &Object{}
+	_ fs.MimeTyper     = &Object{}
+	_ fs.GetTierer     = &Object{}
+	_ fs.SetTierer     = &Object{}
+	_ fs.ReadOnlyer    = &Object{}
 )
@@ -0,0 +1,168 @@
+package s3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRestore(t *testing.T) {
+	expiry := time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		in         string
+		wantStatus string
+		wantExpiry *time.Time
+		wantErr    bool
+	}{
+		{``, "archived", nil, false},
+		{`ongoing-request="true"`, "restoring", nil, false},
+		{`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`, "restored", &expiry, false},
+		{`ongoing-request="false", expiry-date="potato"`, "", nil, true},
+		{`potato`, "", nil, true},
+	} {
+		var st restoreStatus
+		err := st.parseRestore(test.in)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.wantStatus, st.Status, test.in)
+		assert.Equal(t, test.wantExpiry, st.RestoreExpiry, test.in)
+	}
+}
+
+func TestCheckSSE(t *testing.T) {
+	const key = "01234567890123456789012345678901"
+	const keyBase64 = "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="
+	const keyMD5 = "KYvwGXoFFJ42a2u2GDWhwQ=="
+	for _, test := range []struct {
+		name    string
+		in      Options
+		want    Options
+		wantErr string
+	}{{
+		name: "none",
+	}, {
+		name: "kms",
+		in:   Options{ServerSideEncryption: "aws:kms", SSEKMSKeyID: "arn:aws:kms:us-east-1:key"},
+		want: Options{ServerSideEncryption: "aws:kms", SSEKMSKeyID: "arn:aws:kms:us-east-1:key"},
+	}, {
+		name:    "kms key without aws:kms",
+		in:      Options{ServerSideEncryption: "AES256", SSEKMSKeyID: "arn:aws:kms:us-east-1:key"},
+		wantErr: "sse_kms_key_id needs server_side_encryption set to aws:kms",
+	}, {
+		name: "sse-c",
+		in:   Options{SSECustomerAlgorithm: "AES256", SSECustomerKey: key},
+		want: Options{SSECustomerAlgorithm: "AES256", SSECustomerKey: key, SSECustomerKeyMD5: keyMD5},
+	}, {
+		name: "sse-c base64",
+		in:   Options{SSECustomerAlgorithm: "AES256", SSECustomerKeyBase64: keyBase64},
+		want: Options{SSECustomerAlgorithm: "AES256", SSECustomerKey: key, SSECustomerKeyMD5: keyMD5},
+	}, {
+		name:    "sse-c both keys",
+		in:      Options{SSECustomerAlgorithm: "AES256", SSECustomerKey: key, SSECustomerKeyBase64: keyBase64},
+		wantErr: "sse_customer_key and sse_customer_key_base64 can't both be set",
+	}, {
+		name:    "sse-c bad base64",
+		in:      Options{SSECustomerAlgorithm: "AES256", SSECustomerKeyBase64: "!!!"},
+		wantErr: "bad sse_customer_key_base64: illegal base64 data at input byte 0",
+	}, {
+		name:    "sse-c no algorithm",
+		in:      Options{SSECustomerKey: key},
+		wantErr: "sse_customer_algorithm and sse_customer_key must be set together for SSE-C",
+	}, {
+		name:    "sse-c short key",
+		in:      Options{SSECustomerAlgorithm: "AES256", SSECustomerKey: "potato"},
+		wantErr: "SSE-C key must be 32 bytes long not 6",
+	}, {
+		name:    "sse-c and sse",
+		in:      Options{ServerSideEncryption: "AES256", SSECustomerAlgorithm: "AES256", SSECustomerKey: key},
+		wantErr: "server_side_encryption can't be used with SSE-C",
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			opt := test.in
+			err := checkSSE(&opt)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, opt)
+		})
+	}
+}
+
+func TestParseVersionAt(t *testing.T) {
+	now := time.Date(2021, 3, 4, 5, 6, 7, 0, time.Local)
+	for _, test := range []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"1h", now.Add(-time.Hour), false},
+		{"2d", now.Add(-48 * time.Hour), false},
+		{"2020-01-02", time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local), false},
+		{"2020-01-02 03:04:05", time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local), false},
+		{"2020-01-02T03:04:05", time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local), false},
+		{"2020-01-02T03:04:05Z", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"potato", time.Time{}, true},
+	} {
+		got, err := parseVersionAt(test.in, now)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.True(t, test.want.Equal(got), "%s: want %v got %v", test.in, test.want, got)
+	}
+}
+
+func TestVersionFilter(t *testing.T) {
+	t0 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := t0.Add(time.Duration(days) * 24 * time.Hour)
+		return &t
+	}
+	resp := &s3.ListObjectVersionsOutput{
+		Versions: []*s3.ObjectVersion{
+			{Key: aws.String("a"), VersionId: aws.String("a1"), LastModified: at(1)},
+			{Key: aws.String("a"), VersionId: aws.String("a3"), LastModified: at(3), IsLatest: aws.Bool(true)},
+			{Key: aws.String("b"), VersionId: aws.String("b1"), LastModified: at(1)},
+			{Key: aws.String("c"), VersionId: aws.String("c4"), LastModified: at(4), IsLatest: aws.Bool(true)},
+		},
+		DeleteMarkers: []*s3.DeleteMarkerEntry{
+			{Key: aws.String("b"), VersionId: aws.String("b2"), LastModified: at(2), IsLatest: aws.Bool(true)},
+		},
+	}
+	versions := mergeVersions(resp)
+	var order []string
+	for _, v := range versions {
+		order = append(order, aws.StringValue(v.versionID))
+	}
+	assert.Equal(t, []string{"a3", "a1", "b2", "b1", "c4"}, order)
+
+	choose := func(vf versionFilter) (got []string) {
+		for _, v := range versions {
+			show, addVersion := vf.choose(v)
+			if !show {
+				continue
+			}
+			id := aws.StringValue(v.versionID)
+			if addVersion {
+				id += "+version"
+			}
+			got = append(got, id)
+		}
+		return got
+	}
+	assert.Equal(t, []string{"a3", "a1+version", "b1+version", "c4"}, choose(versionFilter{}))
+	assert.Equal(t, []string{"a1", "b1"}, choose(versionFilter{versionAt: *at(1)}))
+	assert.Equal(t, []string{"a1"}, choose(versionFilter{versionAt: *at(2)}))
+	assert.Equal(t, []string{"a3", "c4"}, choose(versionFilter{versionAt: *at(5)}))
+	assert.Equal(t, []string(nil), choose(versionFilter{versionAt: t0}))
+}
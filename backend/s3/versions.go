@@ -0,0 +1,303 @@
+// Listing old versions of objects for --s3-versions and --s3-version-at
+
+package s3
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/lib/version"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+var (
+	errNotWithVersionAt = errors.New("can't modify or delete files in --s3-version-at mode")
+	errNotWithVersions  = errors.New("can't modify old versions of files")
+)
+
+// parseVersionAt parses the --s3-version-at option which may be a
+// duration before now or a date or datetime in the local time zone
+// unless one is given.
+func parseVersionAt(s string, now time.Time) (time.Time, error) {
+	// Parse dates here as fs.ParseDuration would read them as UTC
+	for _, layout := range []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	} {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	d, err := fs.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("can't parse %q as a duration or a date", s)
+	}
+	return now.Add(-d), nil
+}
+
+// objectVersion is a version of an object or a delete marker from a
+// versions listing
+type objectVersion struct {
+	object       *s3.Object
+	versionID    *string
+	isLatest     bool
+	deleteMarker bool
+}
+
+// mergeVersions merges the versions and the delete markers in resp
+// sorted by key and then with the newest versions first.
+func mergeVersions(resp *s3.ListObjectVersionsOutput) []objectVersion {
+	versions := make([]objectVersion, 0, len(resp.Versions)+len(resp.DeleteMarkers))
+	for _, v := range resp.Versions {
+		versions = append(versions, objectVersion{
+			object: &s3.Object{
+				Key:          v.Key,
+				ETag:         v.ETag,
+				LastModified: v.LastModified,
+				Size:         v.Size,
+				StorageClass: v.StorageClass,
+			},
+			versionID: v.VersionId,
+			isLatest:  aws.BoolValue(v.IsLatest),
+		})
+	}
+	for _, m := range resp.DeleteMarkers {
+		versions = append(versions, objectVersion{
+			object: &s3.Object{
+				Key:          m.Key,
+				LastModified: m.LastModified,
+			},
+			versionID:    m.VersionId,
+			isLatest:     aws.BoolValue(m.IsLatest),
+			deleteMarker: true,
+		})
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i].object, versions[j].object
+		if aws.StringValue(a.Key) != aws.StringValue(b.Key) {
+			return aws.StringValue(a.Key) < aws.StringValue(b.Key)
+		}
+		return aws.TimeValue(a.LastModified).After(aws.TimeValue(b.LastModified))
+	})
+	return versions
+}
+
+// versionFilter chooses which versions from a versions listing are
+// shown.
+type versionFilter struct {
+	versionAt time.Time // if set show the versions current at this time
+	lastKey   string    // the last key seen in versionAt mode
+	done      bool      // set if the version of lastKey has been chosen
+}
+
+// choose returns whether v should be shown and whether its name
+// should have the version added.
+//
+// It must be called with the versions in the order returned by
+// mergeVersions.
+func (vf *versionFilter) choose(v objectVersion) (show bool, addVersion bool) {
+	if vf.versionAt.IsZero() {
+		if v.deleteMarker {
+			return false, false
+		}
+		return true, !v.isLatest
+	}
+	key := aws.StringValue(v.object.Key)
+	if key != vf.lastKey {
+		vf.lastKey = key
+		vf.done = false
+	}
+	if vf.done || aws.TimeValue(v.object.LastModified).After(vf.versionAt) {
+		return false, false
+	}
+	// This is the newest version at versionAt so ignore the rest
+	vf.done = true
+	return !v.deleteMarker, false
+}
+
+// listVersions lists the versions of the objects into the function
+// supplied in the same way as list in --s3-versions or --s3-version-at
+// mode.
+//
+// directory and prefix should already have a trailing "/" if not
+// empty.
+func (f *Fs) listVersions(ctx context.Context, bucket, directory, prefix string, addBucket bool, recurse bool, fn listFn) error {
+	delimiter := ""
+	if !recurse {
+		delimiter = "/"
+	}
+	// See list for the providers which support URL encoded listings
+	urlEncodeListings := (f.opt.Provider == "AWS" || f.opt.Provider == "Wasabi" || f.opt.Provider == "Alibaba" || f.opt.Provider == "Minio" || f.opt.Provider == "TencentCOS")
+	decode := func(key string) (string, error) {
+		if urlEncodeListings {
+			return url.QueryUnescape(key)
+		}
+		return key, nil
+	}
+	vf := versionFilter{versionAt: f.versionAt}
+	var keyMarker, versionIDMarker *string
+	for {
+		req := s3.ListObjectVersionsInput{
+			Bucket:          &bucket,
+			Delimiter:       &delimiter,
+			Prefix:          &directory,
+			MaxKeys:         &f.opt.ListChunk,
+			KeyMarker:       keyMarker,
+			VersionIdMarker: versionIDMarker,
+		}
+		if urlEncodeListings {
+			req.EncodingType = aws.String(s3.EncodingTypeUrl)
+		}
+		var resp *s3.ListObjectVersionsOutput
+		err := f.pacer.Call(func() (bool, error) {
+			var err error
+			resp, err = f.c.ListObjectVersionsWithContext(ctx, &req)
+			return f.shouldRetry(ctx, err)
+		})
+		if err != nil {
+			if awsErr, ok := err.(awserr.RequestFailure); ok {
+				if awsErr.StatusCode() == http.StatusNotFound {
+					err = fs.ErrorDirNotFound
+				}
+			}
+			return err
+		}
+		if !recurse {
+			for _, commonPrefix := range resp.CommonPrefixes {
+				if commonPrefix.Prefix == nil {
+					fs.Logf(f, "Nil common prefix received")
+					continue
+				}
+				remote, err := decode(*commonPrefix.Prefix)
+				if err != nil {
+					fs.Logf(f, "failed to URL decode %q in listing common prefix: %v", *commonPrefix.Prefix, err)
+					continue
+				}
+				remote = f.opt.Enc.ToStandardPath(remote)
+				if !strings.HasPrefix(remote, prefix) {
+					fs.Logf(f, "Odd name received %q", remote)
+					continue
+				}
+				remote = remote[len(prefix):]
+				if addBucket {
+					remote = path.Join(bucket, remote)
+				}
+				remote = strings.TrimSuffix(remote, "/")
+				err = fn(remote, &s3.Object{Key: &remote}, nil, true)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		for _, v := range mergeVersions(resp) {
+			show, addVersion := vf.choose(v)
+			if !show {
+				continue
+			}
+			remote, err := decode(aws.StringValue(v.object.Key))
+			if err != nil {
+				fs.Logf(f, "failed to URL decode %q in listing: %v", aws.StringValue(v.object.Key), err)
+				continue
+			}
+			remote = f.opt.Enc.ToStandardPath(remote)
+			if !strings.HasPrefix(remote, prefix) {
+				fs.Logf(f, "Odd name received %q", remote)
+				continue
+			}
+			remote = remote[len(prefix):]
+			isDirectory := remote == "" || strings.HasSuffix(remote, "/")
+			if addBucket {
+				remote = path.Join(bucket, remote)
+			}
+			// is this a directory marker?
+			if isDirectory && aws.Int64Value(v.object.Size) == 0 {
+				continue // skip directory marker
+			}
+			versionID := v.versionID
+			if addVersion {
+				remote = version.Add(remote, aws.TimeValue(v.object.LastModified))
+			} else if vf.versionAt.IsZero() {
+				// The latest version is read without a version ID
+				versionID = nil
+			}
+			err = fn(remote, v.object, versionID, false)
+			if err != nil {
+				return err
+			}
+		}
+		if !aws.BoolValue(resp.IsTruncated) {
+			break
+		}
+		if aws.StringValue(resp.NextKeyMarker) == "" {
+			return errors.New("s3 protocol error: received versions listing with IsTruncated set and no NextKeyMarker")
+		}
+		nextKeyMarker, err := decode(*resp.NextKeyMarker)
+		if err != nil {
+			return errors.Wrapf(err, "failed to URL decode NextKeyMarker %q", *resp.NextKeyMarker)
+		}
+		keyMarker = &nextKeyMarker
+		versionIDMarker = resp.NextVersionIdMarker
+	}
+	return nil
+}
+
+// newObjectVersion finds the object at remote by listing the
+// versions of its key in --s3-versions or --s3-version-at mode.
+//
+// If it can't be found it returns the error ErrorObjectNotFound.
+func (f *Fs) newObjectVersion(ctx context.Context, remote string) (o fs.Object, err error) {
+	key := remote
+	if f.opt.Versions {
+		_, key = version.Remove(remote)
+	}
+	bucket, bucketPath := f.split(key)
+	if bucket == "" || bucketPath == "" {
+		return nil, fs.ErrorObjectNotFound
+	}
+	prefix := f.rootDirectory
+	if prefix != "" {
+		prefix += "/"
+	}
+	// The listing will include any other keys starting with the key
+	// so choose the version whose name matches exactly
+	err = f.listVersions(ctx, bucket, bucketPath, prefix, f.rootBucket == "", true, func(gotRemote string, object *s3.Object, versionID *string, isDirectory bool) error {
+		if isDirectory || gotRemote != remote || o != nil {
+			return nil
+		}
+		o, err = f.newObjectWithInfo(ctx, remote, object, versionID)
+		return err
+	})
+	if err == fs.ErrorDirNotFound {
+		return nil, fs.ErrorObjectNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if o == nil {
+		return nil, fs.ErrorObjectNotFound
+	}
+	return o, nil
+}
+
+// checkModify returns an error if o can't be modified as it is an
+// old version of the file
+func (o *Object) checkModify() error {
+	if !o.fs.versionAt.IsZero() {
+		return errNotWithVersionAt
+	}
+	if o.versionID != nil {
+		return errNotWithVersions
+	}
+	return nil
+}
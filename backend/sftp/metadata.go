@@ -0,0 +1,127 @@
+// Metadata reading and writing
+
+package sftp
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+)
+
+// Metadata keys used by the sftp backend
+//
+// These are the same as the local backend's so the Unix attributes
+// of files can be copied between them.
+const (
+	metadataMode = "mode" // Unix file type and permissions in octal, e.g. "100644"
+	metadataUID  = "uid"  // user ID of the owner
+	metadataGID  = "gid"  // group ID of the owner
+)
+
+// Metadata returns the Unix permissions and ownership of the file if
+// the server returns them.
+func (o *Object) Metadata(ctx context.Context) (fs.Metadata, error) {
+	info, err := o.fs.stat(ctx, o.remote)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read metadata")
+	}
+	stat, ok := info.Sys().(*sftp.FileStat)
+	if !ok {
+		return nil, nil
+	}
+	return fs.Metadata{
+		metadataMode: strconv.FormatUint(uint64(stat.Mode), 8),
+		metadataUID:  strconv.FormatUint(uint64(stat.UID), 10),
+		metadataGID:  strconv.FormatUint(uint64(stat.GID), 10),
+	}, nil
+}
+
+// isPermissionDenied returns true if err is the server refusing
+// permission
+func isPermissionDenied(err error) bool {
+	statusErr, ok := errors.Cause(err).(*sftp.StatusError)
+	return (ok && statusErr.FxCode() == sftp.ErrSSHFxPermissionDenied) || os.IsPermission(err)
+}
+
+// SetMetadata sets the Unix permissions and ownership of the file
+// from metadata.
+//
+// The ownership is skipped if the user isn't permitted to change it.
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) (err error) {
+	uid, gid := -1, -1
+	for _, id := range []struct {
+		key string
+		id  *int
+	}{{metadataUID, &uid}, {metadataGID, &gid}} {
+		value, ok := metadata[id.key]
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return errors.Errorf("invalid value %q for metadata %q", value, id.key)
+		}
+		*id.id = int(n)
+	}
+	var mode *uint64
+	if value, ok := metadata[metadataMode]; ok {
+		n, err := strconv.ParseUint(value, 8, 32)
+		if err != nil {
+			return errors.Errorf("invalid value %q for metadata %q", value, metadataMode)
+		}
+		mode = &n
+	}
+	if uid < 0 && gid < 0 && mode == nil {
+		return nil
+	}
+	c, err := o.fs.getSftpConnection(ctx)
+	if err != nil {
+		return errors.Wrap(err, "SetMetadata")
+	}
+	defer func() {
+		o.fs.putSftpConnection(&c, err)
+	}()
+	// Set the owner first as this can clear the setuid bits. The
+	// protocol needs both IDs so any missing are read first.
+	if uid >= 0 || gid >= 0 {
+		if uid < 0 || gid < 0 {
+			var info os.FileInfo
+			info, err = c.sftpClient.Stat(o.path())
+			if err != nil {
+				return errors.Wrap(err, "failed to read owner")
+			}
+			if stat, ok := info.Sys().(*sftp.FileStat); ok {
+				if uid < 0 {
+					uid = int(stat.UID)
+				}
+				if gid < 0 {
+					gid = int(stat.GID)
+				}
+			}
+		}
+		err = c.sftpClient.Chown(o.path(), uid, gid)
+		if isPermissionDenied(err) {
+			fs.Debugf(o, "Not permitted to set the owner to %d:%d", uid, gid)
+			err = nil
+		} else if err != nil {
+			return errors.Wrap(err, "failed to set owner")
+		}
+	}
+	if mode != nil {
+		err = c.sftpClient.Chmod(o.path(), os.FileMode(*mode&07777))
+		if err != nil {
+			return errors.Wrap(err, "failed to set permissions")
+		}
+	}
+	return nil
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Metadataer    = &Object{}
+	_ fs.SetMetadataer = &Object{}
+)
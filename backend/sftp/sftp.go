@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"os"
 	"path"
 	"regexp"
@@ -34,6 +35,7 @@ import (
 	"github.com/artpar/rclone/lib/readers"
 	sshagent "github.com/xanzy/ssh-agent"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 	"golang.org/x/crypto/ssh/knownhosts"
 )
 
@@ -88,7 +90,10 @@ in the new OpenSSH format can't be used.`,
 			Name: "pubkey_file",
 			Help: `Optional path to public key file.
 
-Set this if you have a signed certificate you want to use for authentication.` + env.ShellExpandHelp,
+Set this if you have a signed certificate you want to use for authentication.
+
+The certificate can be used with the key in key_file or key_pem, or
+with the matching key in the ssh-agent.` + env.ShellExpandHelp,
 		}, {
 			Name: "known_hosts_file",
 			Help: `Optional path to known_hosts file.
@@ -107,6 +112,16 @@ When key-file is also set, the ".pub" file of the specified key-file is read and
 requested from the ssh-agent. This allows to avoid ` + "`Too many authentication failures for *username*`" + ` errors
 when the ssh-agent contains many keys.`,
 			Default: false,
+		}, {
+			Name: "agent_socket",
+			Help: `Path to the ssh-agent socket to use.
+
+Leave blank to use the ssh-agent found from the SSH_AUTH_SOCK
+environment variable (or Pageant on Windows).
+
+Setting this forces the usage of the ssh-agent as if key_use_agent
+was set.` + env.ShellExpandHelp,
+			Advanced: true,
 		}, {
 			Name: "use_insecure_cipher",
 			Help: `Enable the use of insecure ciphers and key exchange methods. 
@@ -237,7 +252,7 @@ given, rclone will empty the connection pool.
 Set to 0 to keep connections indefinitely.
 `,
 			Advanced: true,
-		}},
+		}, fs.APIBudgetOption},
 	}
 	fs.Register(fsi)
 }
@@ -254,6 +269,7 @@ type Options struct {
 	PubKeyFile             string      `config:"pubkey_file"`
 	KnownHostsFile         string      `config:"known_hosts_file"`
 	KeyUseAgent            bool        `config:"key_use_agent"`
+	AgentSocket            string      `config:"agent_socket"`
 	UseInsecureCipher      bool        `config:"use_insecure_cipher"`
 	DisableHashCheck       bool        `config:"disable_hashcheck"`
 	AskPassword            bool        `config:"ask_password"`
@@ -570,18 +586,24 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	keyFile := env.ShellExpand(opt.KeyFile)
 	pubkeyFile := env.ShellExpand(opt.PubKeyFile)
 	//keyPem := env.ShellExpand(opt.KeyPem)
+	agentSocket := env.ShellExpand(opt.AgentSocket)
 	// Add ssh agent-auth if no password or file or key PEM specified
-	if (opt.Pass == "" && keyFile == "" && !opt.AskPassword && opt.KeyPem == "") || opt.KeyUseAgent {
-		sshAgentClient, _, err := sshagent.New()
+	if (opt.Pass == "" && keyFile == "" && !opt.AskPassword && opt.KeyPem == "") || opt.KeyUseAgent || agentSocket != "" {
+		sshAgentClient, err := newAgentClient(agentSocket)
 		if err != nil {
-			return nil, errors.Wrap(err, "couldn't connect to ssh-agent")
+			return nil, err
 		}
 		signers, err := sshAgentClient.Signers()
 		if err != nil {
 			return nil, errors.Wrap(err, "couldn't read ssh agent signers")
 		}
-		if keyFile != "" {
-			pubBytes, err := ioutil.ReadFile(keyFile + ".pub")
+		if keyFile != "" || pubkeyFile != "" {
+			// Use the certificate if set, otherwise the public key of the key file
+			pubFile := pubkeyFile
+			if pubFile == "" {
+				pubFile = keyFile + ".pub"
+			}
+			pubBytes, err := ioutil.ReadFile(pubFile)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to read public key file")
 			}
@@ -589,18 +611,11 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to parse public key file")
 			}
-			pubM := pub.Marshal()
-			found := false
-			for _, s := range signers {
-				if bytes.Equal(pubM, s.PublicKey().Marshal()) {
-					sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeys(s))
-					found = true
-					break
-				}
-			}
-			if !found {
-				return nil, errors.New("private key not found in the ssh-agent")
+			signer, err := findAgentSigner(signers, pub)
+			if err != nil {
+				return nil, err
 			}
+			sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeys(signer))
 		} else {
 			sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeys(signers...))
 		}
@@ -701,6 +716,54 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 	return NewFsWithConnection(ctx, f, name, root, m, opt, sshConfig)
 }
 
+// newAgentClient connects to the ssh-agent listening on socket or the
+// default ssh-agent if socket is empty.
+//
+// The connection is left open as the signers use it.
+func newAgentClient(socket string) (agent.Agent, error) {
+	if socket == "" {
+		sshAgentClient, _, err := sshagent.New()
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't connect to ssh-agent")
+		}
+		return sshAgentClient, nil
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't connect to ssh-agent at %q", socket)
+	}
+	return agent.NewClient(conn), nil
+}
+
+// findAgentSigner returns the signer from the ssh-agent signers for
+// the public key pub.
+//
+// If pub is a certificate then it may either be in the ssh-agent
+// itself or the ssh-agent may just hold the key it certifies, in
+// which case the returned signer presents the certificate.
+func findAgentSigner(signers []ssh.Signer, pub ssh.PublicKey) (ssh.Signer, error) {
+	pubM := pub.Marshal()
+	for _, s := range signers {
+		if bytes.Equal(pubM, s.PublicKey().Marshal()) {
+			return s, nil
+		}
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if ok {
+		keyM := cert.Key.Marshal()
+		for _, s := range signers {
+			if bytes.Equal(keyM, s.PublicKey().Marshal()) {
+				certSigner, err := ssh.NewCertSigner(cert, s)
+				if err != nil {
+					return nil, errors.Wrap(err, "error generating cert signer")
+				}
+				return certSigner, nil
+			}
+		}
+	}
+	return nil, errors.New("private key not found in the ssh-agent")
+}
+
 // Do the keyboard interactive challenge
 //
 // Just send the password back for all questions
@@ -749,6 +812,8 @@ func NewFsWithConnection(ctx context.Context, f *Fs, name string, root string, m
 
 	f.features = (&fs.Features{
 		CanHaveEmptyDirectories: true,
+		ReadMetadata:            true,
+		WriteMetadata:           true,
 		SlowHash:                true,
 	}).Fill(ctx, f)
 	// Make a connection and pool it to return errors early
@@ -1004,6 +1069,26 @@ func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 	return err
 }
 
+// DirSetModTime sets the modification time of the directory dir
+func (f *Fs) DirSetModTime(ctx context.Context, dir string, modTime time.Time) error {
+	if !f.opt.SetModTime {
+		return nil
+	}
+	c, err := f.getSftpConnection(ctx)
+	if err != nil {
+		return errors.Wrap(err, "DirSetModTime")
+	}
+	err = c.sftpClient.Chtimes(path.Join(f.absRoot, dir), modTime, modTime)
+	f.putSftpConnection(&c, err)
+	if os.IsNotExist(err) {
+		return fs.ErrorDirNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "DirSetModTime failed")
+	}
+	return nil
+}
+
 // Move renames a remote sftp file object
 func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
 	srcObj, ok := src.(*Object)
@@ -1573,11 +1658,12 @@ func (o *Object) Remove(ctx context.Context) error {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.Mover       = &Fs{}
-	_ fs.DirMover    = &Fs{}
-	_ fs.Abouter     = &Fs{}
-	_ fs.Shutdowner  = &Fs{}
-	_ fs.Object      = &Object{}
+	_ fs.Fs             = &Fs{}
+	_ fs.PutStreamer    = &Fs{}
+	_ fs.Mover          = &Fs{}
+	_ fs.DirMover       = &Fs{}
+	_ fs.Abouter        = &Fs{}
+	_ fs.Shutdowner     = &Fs{}
+	_ fs.DirSetModTimer = &Fs{}
+	_ fs.Object         = &Object{}
 )
@@ -3,10 +3,20 @@
 package sftp
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 func TestShellEscape(t *testing.T) {
@@ -49,3 +59,99 @@ func TestParseUsage(t *testing.T) {
 		assert.Equal(t, test.usage, [3]int64{gotSpaceTotal, gotSpaceUsed, gotSpaceAvail}, fmt.Sprintf("Test %d sshOutput = %q", i, test.sshOutput))
 	}
 }
+
+// newTestKey makes a new private key and its signer
+func newTestKey(t *testing.T) (ed25519.PrivateKey, ssh.Signer) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return key, signer
+}
+
+// newTestCert makes a certificate for signer signed by a new CA
+func newTestCert(t *testing.T, signer ssh.Signer) *ssh.Certificate {
+	_, ca := newTestKey(t)
+	cert := &ssh.Certificate{
+		Key:             signer.PublicKey(),
+		CertType:        ssh.UserCert,
+		ValidPrincipals: []string{"user"},
+		ValidBefore:     ssh.CertTimeInfinity,
+	}
+	require.NoError(t, cert.SignCert(rand.Reader, ca))
+	return cert
+}
+
+func TestFindAgentSigner(t *testing.T) {
+	key, signer := newTestKey(t)
+	_, other := newTestKey(t)
+	cert := newTestCert(t, signer)
+	certM := cert.Marshal()
+
+	// The agent only has the key
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: key}))
+	signers, err := keyring.Signers()
+	require.NoError(t, err)
+
+	s, err := findAgentSigner(signers, signer.PublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, signer.PublicKey().Marshal(), s.PublicKey().Marshal())
+
+	s, err = findAgentSigner(signers, cert)
+	require.NoError(t, err)
+	assert.Equal(t, certM, s.PublicKey().Marshal())
+
+	_, err = findAgentSigner(signers, other.PublicKey())
+	assert.EqualError(t, err, "private key not found in the ssh-agent")
+	_, err = findAgentSigner(signers, newTestCert(t, other))
+	assert.EqualError(t, err, "private key not found in the ssh-agent")
+
+	// The agent has the certificate too
+	keyring = agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: key, Certificate: cert}))
+	signers, err = keyring.Signers()
+	require.NoError(t, err)
+
+	s, err = findAgentSigner(signers, cert)
+	require.NoError(t, err)
+	assert.Equal(t, certM, s.PublicKey().Marshal())
+}
+
+func TestNewAgentClientSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets not supported on " + runtime.GOOS)
+	}
+	dir, err := ioutil.TempDir("", "rclone-sftp-agent")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	socket := filepath.Join(dir, "agent.sock")
+
+	key, signer := newTestKey(t)
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: key}))
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		_ = agent.ServeAgent(keyring, conn)
+	}()
+
+	client, err := newAgentClient(socket)
+	require.NoError(t, err)
+	signers, err := client.Signers()
+	require.NoError(t, err)
+	require.Len(t, signers, 1)
+	assert.Equal(t, signer.PublicKey().Marshal(), signers[0].PublicKey().Marshal())
+
+	_, err = newAgentClient(filepath.Join(dir, "missing.sock"))
+	assert.Error(t, err)
+}
@@ -163,7 +163,7 @@ func init() {
 			Default: (encoder.Base |
 				encoder.EncodeCtl |
 				encoder.EncodeInvalidUtf8),
-		}},
+		}, fs.ProxyOption, fs.APIBudgetOption},
 	})
 }
 
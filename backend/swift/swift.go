@@ -69,7 +69,7 @@ copy operations.`,
 	Advanced: true,
 	Default: (encoder.EncodeInvalidUtf8 |
 		encoder.EncodeSlash),
-}}
+}, fs.ProxyOption, fs.APIBudgetOption}
 
 // Register with Fs
 func init() {
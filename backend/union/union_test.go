@@ -21,7 +21,7 @@ func TestIntegration(t *testing.T) {
 	}
 	fstests.Run(t, &fstests.Opt{
 		RemoteName:                   *fstest.RemoteName,
-		UnimplementableFsMethods:     []string{"OpenWriterAt", "DuplicateFiles"},
+		UnimplementableFsMethods:     []string{"OpenWriterAt", "OpenChunkWriter", "DuplicateFiles", "DirSetModTime", "HealthCheck"},
 		UnimplementableObjectMethods: []string{"MimeType"},
 	})
 }
@@ -58,7 +58,7 @@ func TestStandard(t *testing.T) {
 			{Name: name, Key: "create_policy", Value: "epmfs"},
 			{Name: name, Key: "search_policy", Value: "ff"},
 		},
-		UnimplementableFsMethods:     []string{"OpenWriterAt", "DuplicateFiles"},
+		UnimplementableFsMethods:     []string{"OpenWriterAt", "OpenChunkWriter", "DuplicateFiles", "DirSetModTime", "HealthCheck"},
 		UnimplementableObjectMethods: []string{"MimeType"},
 	})
 }
@@ -80,7 +80,7 @@ func TestRO(t *testing.T) {
 			{Name: name, Key: "create_policy", Value: "epmfs"},
 			{Name: name, Key: "search_policy", Value: "ff"},
 		},
-		UnimplementableFsMethods:     []string{"OpenWriterAt", "DuplicateFiles"},
+		UnimplementableFsMethods:     []string{"OpenWriterAt", "OpenChunkWriter", "DuplicateFiles", "DirSetModTime", "HealthCheck"},
 		UnimplementableObjectMethods: []string{"MimeType"},
 	})
 }
@@ -102,7 +102,7 @@ func TestNC(t *testing.T) {
 			{Name: name, Key: "create_policy", Value: "epmfs"},
 			{Name: name, Key: "search_policy", Value: "ff"},
 		},
-		UnimplementableFsMethods:     []string{"OpenWriterAt", "DuplicateFiles"},
+		UnimplementableFsMethods:     []string{"OpenWriterAt", "OpenChunkWriter", "DuplicateFiles", "DirSetModTime", "HealthCheck"},
 		UnimplementableObjectMethods: []string{"MimeType"},
 	})
 }
@@ -124,7 +124,7 @@ func TestPolicy1(t *testing.T) {
 			{Name: name, Key: "create_policy", Value: "lus"},
 			{Name: name, Key: "search_policy", Value: "all"},
 		},
-		UnimplementableFsMethods:     []string{"OpenWriterAt", "DuplicateFiles"},
+		UnimplementableFsMethods:     []string{"OpenWriterAt", "OpenChunkWriter", "DuplicateFiles", "DirSetModTime", "HealthCheck"},
 		UnimplementableObjectMethods: []string{"MimeType"},
 	})
 }
@@ -146,7 +146,7 @@ func TestPolicy2(t *testing.T) {
 			{Name: name, Key: "create_policy", Value: "rand"},
 			{Name: name, Key: "search_policy", Value: "ff"},
 		},
-		UnimplementableFsMethods:     []string{"OpenWriterAt", "DuplicateFiles"},
+		UnimplementableFsMethods:     []string{"OpenWriterAt", "OpenChunkWriter", "DuplicateFiles", "DirSetModTime", "HealthCheck"},
 		UnimplementableObjectMethods: []string{"MimeType"},
 	})
 }
@@ -168,7 +168,7 @@ func TestPolicy3(t *testing.T) {
 			{Name: name, Key: "create_policy", Value: "all"},
 			{Name: name, Key: "search_policy", Value: "all"},
 		},
-		UnimplementableFsMethods:     []string{"OpenWriterAt", "DuplicateFiles"},
+		UnimplementableFsMethods:     []string{"OpenWriterAt", "OpenChunkWriter", "DuplicateFiles", "DirSetModTime", "HealthCheck"},
 		UnimplementableObjectMethods: []string{"MimeType"},
 	})
 }
@@ -27,9 +27,30 @@ type Multistatus struct {
 }
 
 // Response contains an Href the response it about and its properties
+//
+// Responses to methods other than PROPFIND, such as the failures of a
+// DELETE, have a Status rather than properties.
 type Response struct {
-	Href  string `xml:"href"`
-	Props Prop   `xml:"propstat"`
+	Href   string `xml:"href"`
+	Status string `xml:"status"`
+	Props  Prop   `xml:"propstat"`
+}
+
+// StatusOK examines the Status of the response, or of its properties
+// if it doesn't have one, and returns an OK flag
+func (r *Response) StatusOK() bool {
+	if r.Status != "" {
+		return statusOK(r.Status)
+	}
+	return r.Props.StatusOK()
+}
+
+// StatusString returns the status of the response for messages
+func (r *Response) StatusString() string {
+	if r.Status != "" || len(r.Props.Status) == 0 {
+		return r.Status
+	}
+	return r.Props.Status[0]
 }
 
 // Prop is the properties of a response
@@ -84,7 +105,13 @@ func (p *Prop) StatusOK() bool {
 	if len(p.Status) == 0 {
 		return true
 	}
-	match := parseStatus.FindStringSubmatch(p.Status[0])
+	return statusOK(p.Status[0])
+}
+
+// statusOK returns whether a status of the form "HTTP/1.1 200 OK" is
+// a 2xx status
+func statusOK(status string) bool {
+	match := parseStatus.FindStringSubmatch(status)
 	if len(match) < 2 {
 		return false
 	}
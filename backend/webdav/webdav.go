@@ -78,7 +78,13 @@ func init() {
 			}},
 		}, {
 			Name: "vendor",
-			Help: "Name of the Webdav site/service/software you are using",
+			Help: `Name of the Webdav site/service/software you are using
+
+Leave blank to have rclone detect this by probing the server with the
+configured credentials when it starts. The detection only recognises
+Nextcloud, ownCloud and IIS based servers and never changes the
+authentication used, so SharePoint must always be set explicitly. Set
+this to "other" to disable the detection.`,
 			Examples: []fs.OptionExample{{
 				Value: "nextcloud",
 				Help:  "Nextcloud",
@@ -113,7 +119,7 @@ func init() {
 			Name:     config.ConfigEncoding,
 			Help:     configEncodingHelp,
 			Advanced: true,
-		}},
+		}, fs.ProxyOption, fs.APIBudgetOption},
 	})
 }
 
@@ -372,9 +378,6 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 			return nil, errors.Wrap(err, "couldn't decrypt password")
 		}
 	}
-	if opt.Vendor == "" {
-		opt.Vendor = "other"
-	}
 	root = strings.Trim(root, "/")
 
 	if opt.Enc == encoder.EncodeZero && opt.Vendor == "sharepoint-ntlm" {
@@ -429,7 +432,15 @@ func NewFs(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, e
 		}
 	}
 	f.srv.SetErrorHandler(errorHandler)
-	err = f.setQuirks(ctx, opt.Vendor)
+	vendor := opt.Vendor
+	if vendor == "" {
+		var iis bool
+		vendor, iis = f.detectVendor(ctx)
+		if iis {
+			f.setIISQuirks()
+		}
+	}
+	err = f.setQuirks(ctx, vendor)
 	if err != nil {
 		return nil, err
 	}
@@ -500,6 +511,103 @@ func (f *Fs) fetchAndSetBearerToken() error {
 	return nil
 }
 
+// detected is the result of detectVendor
+type detected struct {
+	vendor string
+	iis    bool
+}
+
+// detectedCache caches the result of detectVendor for each endpoint
+// and user so the server is only probed once per run
+var (
+	detectedCacheMu sync.Mutex
+	detectedCache   = map[string]detected{}
+)
+
+// detectVendor probes the server using the configured authentication
+// to work out which vendor's software it is running so the right
+// quirks can be used when the vendor isn't configured.
+//
+// It only detects vendors whose quirks don't change the
+// authentication, so it returns "nextcloud", "owncloud" or "other".
+// Instead of detecting SharePoint it sets iis if the server is running
+// IIS, which SharePoint on-premises uses, so its listing and purge
+// quirks can be used.
+func (f *Fs) detectVendor(ctx context.Context) (vendor string, iis bool) {
+	key := f.endpointURL + "\x00" + f.opt.User
+	detectedCacheMu.Lock()
+	d, found := detectedCache[key]
+	detectedCacheMu.Unlock()
+	if found {
+		return d.vendor, d.iis
+	}
+	d.vendor, d.iis = f.probeVendor(ctx)
+	fs.Debugf(f, "Detected vendor %q (IIS %v)", d.vendor, d.iis)
+	if ctx.Err() == nil {
+		detectedCacheMu.Lock()
+		detectedCache[key] = d
+		detectedCacheMu.Unlock()
+	}
+	return d.vendor, d.iis
+}
+
+// probeVendor does the work for detectVendor
+func (f *Fs) probeVendor(ctx context.Context) (vendor string, iis bool) {
+	// Nextcloud and ownCloud serve a status.php at the root of the
+	// installation naming the product
+	if i := strings.Index(f.endpoint.Path, "/remote.php/"); i >= 0 {
+		statusURL := *f.endpoint
+		statusURL.Path = f.endpoint.Path[:i] + "/status.php"
+		statusURL.RawPath = ""
+		statusURL.RawQuery = ""
+		var status struct {
+			Installed   bool   `json:"installed"`
+			ProductName string `json:"productname"`
+		}
+		opts := rest.Opts{
+			Method:  "GET",
+			RootURL: statusURL.String(),
+		}
+		_, err := f.srv.CallJSON(ctx, &opts, nil, &status)
+		if err != nil {
+			fs.Debugf(f, "Failed to read %q: %v", statusURL.String(), err)
+		} else if status.Installed {
+			switch strings.ToLower(status.ProductName) {
+			case "nextcloud":
+				return "nextcloud", false
+			case "owncloud":
+				return "owncloud", false
+			}
+		}
+	}
+
+	// Otherwise look at the headers the server returns to OPTIONS
+	opts := rest.Opts{
+		Method:     "OPTIONS",
+		NoResponse: true,
+	}
+	resp, err := f.srv.Call(ctx, &opts)
+	if resp == nil {
+		fs.Debugf(f, "OPTIONS failed: %v", err)
+		return "other", false
+	}
+	h := resp.Header
+	iis = strings.Contains(h.Get("Server"), "Microsoft-IIS") || h.Get("MicrosoftSharePointTeamServices") != ""
+	if strings.Contains(strings.ToLower(h.Get("DAV")), "nextcloud") {
+		return "nextcloud", iis
+	}
+	return "other", iis
+}
+
+// setIISQuirks sets the quirks needed for IIS based servers such as
+// SharePoint on-premises which don't depend on the authentication
+func (f *Fs) setIISQuirks() {
+	// IIS only lists files if the depth header is set to 0
+	f.retryWithZeroDepth = true
+	// IIS returns 204 to a purge of a non existent directory
+	f.checkBeforePurge = true
+}
+
 // setQuirks adjusts the Fs for the vendor passed in
 func (f *Fs) setQuirks(ctx context.Context, vendor string) error {
 	switch vendor {
@@ -543,13 +651,12 @@ func (f *Fs) setQuirks(ctx context.Context, vendor string) error {
 	case "sharepoint-ntlm":
 		// Sharepoint with NTLM authentication
 		// See comment above
-		f.retryWithZeroDepth = true
-
+		//
 		// Sharepoint 2016 returns status 204 to the purge request
 		// even if the directory to purge does not really exist
 		// so we must perform an extra check to detect this
 		// condition and return a proper error code.
-		f.checkBeforePurge = true
+		f.setIISQuirks()
 	case "other":
 	default:
 		fs.Debugf(f, "Unknown vendor %q", vendor)
@@ -905,24 +1012,112 @@ func (f *Fs) purgeCheck(ctx context.Context, dir string, check bool) error {
 			return fs.ErrorDirNotFound
 		}
 	}
+	err := f.deleteCollection(ctx, dir)
+	if err != nil && !check && refusedCollectionDelete(err) {
+		fs.Debugf(f, "Server refused to delete directory %q - deleting its contents one at a time: %v", dir, err)
+		err = f.purgeRecursive(ctx, dir)
+	}
+	if err != nil {
+		return errors.Wrap(err, "rmdir failed")
+	}
+	return nil
+}
+
+// deleteCollection deletes the directory dir and everything in it.
+//
+// If the server couldn't delete some of the items it returns a
+// Multistatus response listing them. These are logged and returned
+// as an error.
+func (f *Fs) deleteCollection(ctx context.Context, dir string) error {
 	opts := rest.Opts{
-		Method:     "DELETE",
-		Path:       f.dirPath(dir),
-		NoResponse: true,
+		Method: "DELETE",
+		Path:   f.dirPath(dir),
+		ExtraHeaders: map[string]string{
+			"Depth": "infinity",
+		},
 	}
 	var resp *http.Response
 	var err error
 	err = f.pacer.Call(func() (bool, error) {
-		resp, err = f.srv.CallXML(ctx, &opts, nil, nil)
+		resp, err = f.srv.Call(ctx, &opts)
 		return f.shouldRetry(ctx, resp, err)
 	})
 	if err != nil {
-		return errors.Wrap(err, "rmdir failed")
+		return err
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return resp.Body.Close()
+	}
+	var result api.Multistatus
+	err = rest.DecodeXML(resp, &result)
+	if err != nil {
+		return errors.Wrap(err, "failed to read multistatus response")
+	}
+	var failed []string
+	for i := range result.Responses {
+		item := &result.Responses[i]
+		if item.StatusOK() {
+			continue
+		}
+		href, unescapeErr := url.PathUnescape(item.Href)
+		if unescapeErr != nil {
+			href = item.Href
+		}
+		fs.Errorf(f, "Failed to delete %q: %s", href, item.StatusString())
+		failed = append(failed, fmt.Sprintf("%q: %s", href, item.StatusString()))
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("failed to delete %d items, first %s", len(failed), failed[0])
 	}
-	// FIXME parse Multistatus response
 	return nil
 }
 
+// refusedCollectionDelete returns true if err shows the server won't
+// delete a directory which isn't empty
+func refusedCollectionDelete(err error) bool {
+	apiErr, ok := err.(*api.Error)
+	if !ok {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusForbidden, http.StatusMethodNotAllowed, http.StatusConflict, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// purgeRecursive deletes the contents of dir one item at a time and
+// then dir itself, for servers which won't delete a directory which
+// isn't empty
+func (f *Fs) purgeRecursive(ctx context.Context, dir string) error {
+	var files, dirs []string
+	_, err := f.listAll(ctx, dir, false, false, defaultDepth, func(remote string, isDir bool, info *api.Prop) bool {
+		if isDir {
+			dirs = append(dirs, remote)
+		} else {
+			files = append(files, remote)
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	for _, remote := range files {
+		o := &Object{fs: f, remote: remote}
+		err = o.Remove(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete %q", remote)
+		}
+	}
+	for _, subDir := range dirs {
+		err = f.purgeRecursive(ctx, subDir)
+		if err != nil {
+			return err
+		}
+	}
+	return f.deleteCollection(ctx, dir)
+}
+
 // Rmdir deletes the root folder
 //
 // Returns an error if it isn't empty
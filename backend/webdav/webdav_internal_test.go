@@ -0,0 +1,231 @@
+package webdav
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config/configmap"
+	"github.com/artpar/rclone/fs/config/obscure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectVendor(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name      string
+		path      string
+		handler   http.HandlerFunc
+		wantMtime bool // whether the ownCloud/Nextcloud mtime quirk should be set
+		wantMD5   bool
+		wantSHA1  bool
+		wantIIS   bool
+	}{
+		{
+			name: "nextcloud",
+			path: "/remote.php/dav/files/user",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/status.php" {
+					_, _ = w.Write([]byte(`{"installed":true,"productname":"Nextcloud"}`))
+					return
+				}
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			wantMtime: true,
+			wantSHA1:  true,
+		},
+		{
+			name: "owncloud",
+			path: "/owncloud/remote.php/webdav/",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/owncloud/status.php" {
+					_, _ = w.Write([]byte(`{"installed":true,"productname":"ownCloud"}`))
+					return
+				}
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			wantMtime: true,
+			wantMD5:   true,
+			wantSHA1:  true,
+		},
+		{
+			name: "nextcloud DAV header",
+			path: "/dav",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("DAV", "1, 3, extended-mkcol, nextcloud-checksum-update")
+			},
+			wantMtime: true,
+			wantSHA1:  true,
+		},
+		{
+			name: "sharepoint",
+			path: "/sites/test",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Server", "Microsoft-IIS/10.0")
+				w.Header().Set("MicrosoftSharePointTeamServices", "16.0.0.4327")
+				w.Header().Add("WWW-Authenticate", "Negotiate")
+				w.Header().Add("WWW-Authenticate", "NTLM")
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			// NewFs would fail trying to log in to SharePoint Online
+			// if this was detected as sharepoint
+			wantIIS: true,
+		},
+		{
+			name: "other",
+			path: "/",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("DAV", "1, 2")
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			probes := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				probes++
+				user, pass, ok := r.BasicAuth()
+				assert.True(t, ok, "probe should use the configured auth")
+				assert.Equal(t, "user", user)
+				assert.Equal(t, "pass", pass)
+				test.handler(w, r)
+			}))
+			defer ts.Close()
+			m := configmap.Simple{
+				"url":  ts.URL + test.path,
+				"user": "user",
+				"pass": obscure.MustObscure("pass"),
+			}
+			fsys, err := NewFs(ctx, "TestDetectVendor", "", m)
+			require.NoError(t, err)
+			f := fsys.(*Fs)
+			assert.Equal(t, test.wantMtime, f.useOCMtime)
+			assert.Equal(t, test.wantMD5, f.hasMD5)
+			assert.Equal(t, test.wantSHA1, f.hasSHA1)
+			assert.Equal(t, test.wantIIS, f.retryWithZeroDepth)
+			assert.Equal(t, test.wantIIS, f.checkBeforePurge)
+
+			// A second NewFs should use the cached result
+			n := probes
+			_, err = NewFs(ctx, "TestDetectVendor", "", m)
+			require.NoError(t, err)
+			assert.Equal(t, n, probes)
+		})
+	}
+}
+
+func TestDetectVendorExplicit(t *testing.T) {
+	ctx := context.Background()
+	probes := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes++
+	}))
+	defer ts.Close()
+	_, err := NewFs(ctx, "TestDetectVendorExplicit", "", configmap.Simple{
+		"url":    ts.URL,
+		"vendor": "other",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, probes, "shouldn't probe when vendor is set")
+}
+
+// newPurgeTestFs makes an Fs pointing at a test server with handler
+func newPurgeTestFs(t *testing.T, handler http.HandlerFunc) (*Fs, func()) {
+	ts := httptest.NewServer(handler)
+	fsys, err := NewFs(context.Background(), "TestPurge", "", configmap.Simple{
+		"url":    ts.URL,
+		"vendor": "other",
+	})
+	require.NoError(t, err)
+	return fsys.(*Fs), ts.Close
+}
+
+func TestPurgeMultistatus(t *testing.T) {
+	ctx := context.Background()
+	f, cleanup := newPurgeTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/dir/", r.URL.Path)
+		assert.Equal(t, "infinity", r.Header.Get("Depth"))
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8" ?>
+<d:multistatus xmlns:d="DAV:">
+  <d:response>
+    <d:href>/dir/locked%20file</d:href>
+    <d:status>HTTP/1.1 423 Locked</d:status>
+  </d:response>
+  <d:response>
+    <d:href>/dir/deleted</d:href>
+    <d:status>HTTP/1.1 204 No Content</d:status>
+  </d:response>
+</d:multistatus>`))
+	})
+	defer cleanup()
+
+	err := f.Purge(ctx, "dir")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to delete 1 items, first "/dir/locked file": HTTP/1.1 423 Locked`)
+}
+
+func TestPurgeRecursiveFallback(t *testing.T) {
+	ctx := context.Background()
+	// the items on the server, directories end in /
+	items := map[string]bool{
+		"/dir/":      true,
+		"/dir/a":     true,
+		"/dir/sub/":  true,
+		"/dir/sub/b": true,
+	}
+	children := func(dir string) (out []string) {
+		for item := range items {
+			if item != dir && strings.HasPrefix(item, dir) && !strings.Contains(strings.TrimSuffix(item[len(dir):], "/"), "/") {
+				out = append(out, item)
+			}
+		}
+		return out
+	}
+	f, cleanup := newPurgeTestFs(t, func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Path
+		switch r.Method {
+		case "PROPFIND":
+			w.WriteHeader(http.StatusMultiStatus)
+			_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8" ?><d:multistatus xmlns:d="DAV:">`)
+			for _, item := range append([]string{p}, children(p)...) {
+				resourceType := ""
+				if strings.HasSuffix(item, "/") {
+					resourceType = "<d:collection/>"
+				}
+				_, _ = fmt.Fprintf(w, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype>%s</d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, item, resourceType)
+			}
+			_, _ = fmt.Fprintf(w, `</d:multistatus>`)
+		case "DELETE":
+			if !items[p] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			// refuse to delete directories which aren't empty
+			if len(children(p)) > 0 {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			delete(items, p)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	defer cleanup()
+
+	require.NoError(t, f.Purge(ctx, "dir"))
+	assert.Empty(t, items)
+
+	// Rmdir never deletes the contents
+	items["/dir/"] = true
+	items["/dir/a"] = true
+	err := f.Rmdir(ctx, "dir")
+	assert.Equal(t, fs.ErrorDirectoryNotEmpty, err)
+	assert.Len(t, items, 2)
+}
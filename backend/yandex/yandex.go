@@ -75,7 +75,7 @@ func init() {
 			// it doesn't seem worth making an exception for this
 			Default: (encoder.Display |
 				encoder.EncodeInvalidUtf8),
-		}}...),
+		}, fs.ProxyOption, fs.APIBudgetOption}...),
 	})
 }
 
@@ -884,10 +884,16 @@ func (f *Fs) About(ctx context.Context) (*fs.Usage, error) {
 		return nil, err
 	}
 
+	// The used space includes the trash
+	free := info.TotalSpace - info.UsedSpace
+	if free < 0 {
+		free = 0
+	}
 	usage := &fs.Usage{
-		Total: fs.NewUsageValue(info.TotalSpace),
-		Used:  fs.NewUsageValue(info.UsedSpace),
-		Free:  fs.NewUsageValue(info.TotalSpace - info.UsedSpace),
+		Total:   fs.NewUsageValue(info.TotalSpace),
+		Used:    fs.NewUsageValue(info.UsedSpace),
+		Trashed: fs.NewUsageValue(info.TrashSize),
+		Free:    fs.NewUsageValue(free),
 	}
 	return usage, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/artpar/rclone/cmd"
@@ -14,8 +15,9 @@ import (
 )
 
 var (
-	jsonOutput bool
-	fullOutput bool
+	jsonOutput      bool
+	fullOutput      bool
+	porcelainOutput bool
 )
 
 func init() {
@@ -23,6 +25,7 @@ func init() {
 	cmdFlags := commandDefinition.Flags()
 	flags.BoolVarP(cmdFlags, &jsonOutput, "json", "", false, "Format output as JSON")
 	flags.BoolVarP(cmdFlags, &fullOutput, "full", "", false, "Full numbers instead of SI units")
+	flags.BoolVarP(cmdFlags, &porcelainOutput, "porcelain", "", false, "Output stable tab separated columns for scripts")
 }
 
 // printValue formats uv to be output
@@ -40,6 +43,25 @@ func printValue(what string, uv *int64) {
 	fmt.Printf("%-9s%v\n", what, val)
 }
 
+// printPorcelain writes u to w in --porcelain format
+func printPorcelain(w io.Writer, u *fs.Usage) {
+	for _, field := range []struct {
+		name string
+		uv   *int64
+	}{
+		{"total", u.Total},
+		{"used", u.Used},
+		{"free", u.Free},
+		{"trashed", u.Trashed},
+		{"other", u.Other},
+		{"objects", u.Objects},
+	} {
+		if field.uv != nil {
+			_, _ = fmt.Fprintf(w, "%s\t%d\n", field.name, *field.uv)
+		}
+	}
+}
+
 var commandDefinition = &cobra.Command{
 	Use:   "about remote:",
 	Short: `Get quota information from the remote.`,
@@ -85,6 +107,19 @@ A ` + "`--json`" + `flag generates conveniently computer readable output, e.g.
         "free": 1411001220
     }
 
+A ` + "`--porcelain`" + ` flag generates output for scripts which is
+guaranteed not to change between releases. Each field is one line
+with the field name and the value in bytes separated by a tab, e.g.
+
+    total	18253611008
+    used	7993453766
+    free	1411001220
+    trashed	104857602
+    other	8849156022
+
+The field names are the same as the ` + "`--json`" + ` output and are
+always in this order. Fields the backend doesn't provide are omitted.
+
 Not all backends support the ` + "`rclone about`" + ` command.
 
 See [List of backends that do not support about](https://rclone.org/overview/#optional-features)
@@ -109,6 +144,10 @@ See [List of backends that do not support about](https://rclone.org/overview/#op
 				out.SetIndent("", "\t")
 				return out.Encode(u)
 			}
+			if porcelainOutput {
+				printPorcelain(os.Stdout, u)
+				return nil
+			}
 			printValue("Total", u.Total)
 			printValue("Used", u.Used)
 			printValue("Free", u.Free)
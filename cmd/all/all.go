@@ -10,7 +10,9 @@ import (
 	_ "github.com/artpar/rclone/cmd/cachestats"
 	_ "github.com/artpar/rclone/cmd/cat"
 	_ "github.com/artpar/rclone/cmd/check"
+	_ "github.com/artpar/rclone/cmd/checksum"
 	_ "github.com/artpar/rclone/cmd/cleanup"
+	_ "github.com/artpar/rclone/cmd/cleanuppartials"
 	_ "github.com/artpar/rclone/cmd/cmount"
 	_ "github.com/artpar/rclone/cmd/config"
 	_ "github.com/artpar/rclone/cmd/copy"
@@ -33,6 +35,7 @@ import (
 	_ "github.com/artpar/rclone/cmd/lsjson"
 	_ "github.com/artpar/rclone/cmd/lsl"
 	_ "github.com/artpar/rclone/cmd/md5sum"
+	_ "github.com/artpar/rclone/cmd/mergedirs"
 	_ "github.com/artpar/rclone/cmd/mkdir"
 	_ "github.com/artpar/rclone/cmd/mount"
 	_ "github.com/artpar/rclone/cmd/mount2"
@@ -40,6 +43,7 @@ import (
 	_ "github.com/artpar/rclone/cmd/moveto"
 	_ "github.com/artpar/rclone/cmd/ncdu"
 	_ "github.com/artpar/rclone/cmd/obscure"
+	_ "github.com/artpar/rclone/cmd/pin"
 	_ "github.com/artpar/rclone/cmd/purge"
 	_ "github.com/artpar/rclone/cmd/rc"
 	_ "github.com/artpar/rclone/cmd/rcat"
@@ -58,6 +62,7 @@ import (
 	_ "github.com/artpar/rclone/cmd/test/info"
 	_ "github.com/artpar/rclone/cmd/test/makefiles"
 	_ "github.com/artpar/rclone/cmd/test/memory"
+	_ "github.com/artpar/rclone/cmd/test/speed"
 	_ "github.com/artpar/rclone/cmd/touch"
 	_ "github.com/artpar/rclone/cmd/tree"
 	_ "github.com/artpar/rclone/cmd/version"
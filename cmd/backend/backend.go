@@ -33,8 +33,8 @@ var commandDefinition = &cobra.Command{
 	Short: `Run a backend specific command.`,
 	Long: `
 This runs a backend specific command. The commands themselves (except
-for "help" and "features") are defined by the backends and you should
-see the backend docs for definitions.
+for "help", "features" and "health") are defined by the backends and you
+should see the backend docs for definitions.
 
 You can discover what commands a backend implements by using
 
@@ -47,6 +47,15 @@ for more info).
 
     rclone backend features remote:
 
+To check the credentials of a remote are valid and it can be reached,
+without listing it where possible, use
+
+    rclone backend health remote:
+
+This prints a JSON blob (see [operations/health](/rc/#operations/health)
+in the remote control docs) and exits with an error if the check fails,
+so is suitable for monitoring.
+
 Pass options to the backend command with -o. This should be key=value or key, e.g.:
 
     rclone backend stats remote:path stats -o format=json -o long
@@ -79,12 +88,22 @@ Note to run these commands on a running backend then see
 				return err
 			}
 			// Run the command
-			var out interface{}
+			var (
+				out       interface{}
+				healthErr error
+			)
 			switch name {
 			case "help":
 				return showHelp(fsInfo)
 			case "features":
 				out = operations.GetFsInfo(f)
+			case "health":
+				status := operations.HealthCheck(context.Background(), f)
+				out = status
+				if !status.OK {
+					// show the status before returning the error
+					healthErr = errors.Errorf("health check failed: %s", status.Error)
+				}
 			default:
 				doCommand := f.Features().Command
 				if doCommand == nil {
@@ -124,7 +143,7 @@ Note to run these commands on a running backend then see
 					return errors.Wrap(err, "failed to write JSON")
 				}
 			}
-			return nil
+			return healthErr
 		})
 		return nil
 	},
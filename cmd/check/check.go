@@ -10,6 +10,7 @@ import (
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/config/flags"
 	"github.com/artpar/rclone/fs/operations"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -24,12 +25,14 @@ var (
 	match        = ""
 	differ       = ""
 	errFile      = ""
+	manifest     = ""
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlags := commandDefinition.Flags()
 	flags.BoolVarP(cmdFlags, &download, "download", "", download, "Check by downloading rather than with hash.")
+	flags.StringVarP(cmdFlags, &manifest, "manifest", "", manifest, "Check the remote against this manifest made by lsjson instead of a source")
 	AddFlags(cmdFlags)
 }
 
@@ -129,7 +132,7 @@ func GetCheckOpt(fsrc, fdst fs.Fs) (opt *operations.CheckOpt, close func(), err
 }
 
 var commandDefinition = &cobra.Command{
-	Use:   "check source:path dest:path",
+	Use:   "check source:path dest:path | --manifest manifest.json dest:path",
 	Short: `Checks the files in the source and destination match.`,
 	Long: strings.ReplaceAll(`
 Checks the files in the source and destination match.  It compares
@@ -143,8 +146,44 @@ If you supply the |--download| flag, it will download the data from
 both remotes and check them against each other on the fly.  This can
 be useful for remotes that don't support hashes or if you really want
 to check all the data.
+
+If you supply the |--manifest| flag then only a destination is
+needed. It will be checked against a manifest of the files, their
+sizes, modification times and hashes made earlier, rather than against
+another remote. This can be used to audit the integrity of a remote
+without needing access to the original files. Make the manifest with
+|rclone lsjson|, for example
+
+    rclone lsjson -R --hash --files-only remote:path > manifest.json
+    rclone check --manifest manifest.json remote:path
+
+Sizes are always compared, and modification times and hashes are
+compared if they are in the manifest and supported by the remote. Files
+only in the manifest are reported as missing on the destination and
+files only on the remote as missing on the source.
 `, "|", "`") + FlagsHelp,
 	Run: func(command *cobra.Command, args []string) {
+		if manifest != "" {
+			cmd.CheckArgs(1, 1, command, args)
+			fdst := cmd.NewFsDir(args)
+			cmd.Run(false, true, command, func() (err error) {
+				if download {
+					return errors.New("can't use --download with --manifest")
+				}
+				in, err := os.Open(manifest)
+				if err != nil {
+					return err
+				}
+				defer fs.CheckClose(in, &err)
+				opt, close, err := GetCheckOpt(nil, fdst)
+				if err != nil {
+					return err
+				}
+				defer close()
+				return operations.CheckManifest(context.Background(), opt, in)
+			})
+			return
+		}
 		cmd.CheckArgs(2, 2, command, args)
 		fsrc, fdst := cmd.NewFsSrcDst(args)
 		cmd.Run(false, true, command, func() error {
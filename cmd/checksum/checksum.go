@@ -0,0 +1,84 @@
+package checksum
+
+import (
+	"context"
+	"strings"
+
+	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/cmd/check"
+	"github.com/artpar/rclone/fs/config/flags"
+	"github.com/artpar/rclone/fs/hash"
+	"github.com/artpar/rclone/fs/operations"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Globals
+var (
+	download = false
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.BoolVarP(cmdFlags, &download, "download", "", download, "Check by hashing the contents.")
+	check.AddFlags(cmdFlags)
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "checksum <hash> sumfile dst:path",
+	Short: `Checks the files in the destination against a SUM file.`,
+	Long: strings.ReplaceAll(`
+Checks that hashsums of the destination files match the SUM file. It
+compares hashes (MD5, SHA-1, etc) and logs a report of files which
+don't match. It doesn't alter the file system.
+
+The SUM file should be in the format written by md5sum, sha1sum,
+sha256sum or |rclone hashsum|, that is the hash, two spaces (or a
+space and a |*|) and then the path of the file relative to |dst:path|.
+
+The SUM file can be local or on any remote, including the one being
+checked, so integrity checks can be done entirely from the remote.
+It is streamed and parsed as it is read. If the SUM file is inside
+|dst:path| it is left out of the check. For example to check the files
+in a bucket against an MD5 SUM file stored alongside them
+
+    rclone checksum MD5 s3:bucket/MD5SUMS s3:bucket
+
+The hash type must be supported by the destination unless the
+|--download| flag is supplied, in which case the files will be
+downloaded and hashed locally. Use |rclone hashsum| to see the hash
+types available.
+
+The SUM file is treated as the source so files only in the SUM file
+are reported as missing on the destination and files only on the
+destination are reported as missing on the source.
+`, "|", "`") + check.FlagsHelp,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(3, 3, command, args)
+		var ht hash.Type
+		err := ht.Set(args[0])
+		if err != nil {
+			cmd.Run(false, false, command, func() error {
+				return errors.Errorf("%v - supported hashes are %v", err, hash.Supported())
+			})
+			return
+		}
+		fsum, sumFile := cmd.NewFsFile(args[1])
+		if sumFile == "" {
+			cmd.Run(false, false, command, func() error {
+				return errors.Errorf("%q is not a file", args[1])
+			})
+			return
+		}
+		fdst := cmd.NewFsDir(args[2:])
+		cmd.Run(false, true, command, func() error {
+			opt, close, err := check.GetCheckOpt(nil, fdst)
+			if err != nil {
+				return err
+			}
+			defer close()
+			return operations.CheckSum(context.Background(), fsum, sumFile, ht, opt, download)
+		})
+	},
+}
@@ -0,0 +1,46 @@
+package cleanuppartials
+
+import (
+	"context"
+
+	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/operations"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "cleanup-partials remote:path",
+	Short: `Remove stale partial uploads from the remote.`,
+	Long: `
+Remove partial uploads left behind in remote:path by rclone runs which
+were interrupted while using ` + "`--partial-suffix`" + `.
+
+With ` + "`--partial-suffix`" + ` set rclone uploads each file to a temporary
+name, eg ` + "`file.txt.5f8a1b2c.partial`" + `, then renames it into place when
+the upload is complete. The hex number is the time the upload started
+and only partial uploads started longer ago than ` + "`--partial-max-age`" + `
+(default 24h) are removed, so uploads in progress are left alone.
+
+If ` + "`--partial-suffix`" + ` isn't set then files with the suffix ` + "`.partial`" + `
+are removed.
+
+Stale partial uploads are also removed from the destination as they are
+found during ` + "`rclone sync`" + `, ` + "`copy`" + ` and ` + "`move`" + ` when ` + "`--partial-suffix`" + `
+is set.
+
+Use ` + "`--dry-run`" + ` or ` + "`--interactive`" + `/` + "`-i`" + ` to see what would be removed.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		fsrc := cmd.NewFsSrc(args)
+		cmd.Run(true, false, command, func() error {
+			ctx := context.Background()
+			return operations.CleanupPartials(ctx, fsrc, fs.GetConfig(ctx).PartialMaxAge)
+		})
+	},
+}
@@ -72,8 +72,9 @@ const (
 	exitCodeNoFilesTransferred
 )
 
-// ShowVersion prints the version to stdout
-func ShowVersion() {
+// VersionInfo returns the information shown by ShowVersion as a list
+// of key, value pairs in display order
+func VersionInfo() [][2]string {
 	osVersion, osKernel := buildinfo.GetOSVersion()
 	if osVersion == "" {
 		osVersion = "unknown"
@@ -84,14 +85,25 @@ func ShowVersion() {
 
 	linking, tagString := buildinfo.GetLinkingAndTags()
 
-	fmt.Printf("rclone %s\n", fs.Version)
-	fmt.Printf("- os/version: %s\n", osVersion)
-	fmt.Printf("- os/kernel: %s\n", osKernel)
-	fmt.Printf("- os/type: %s\n", runtime.GOOS)
-	fmt.Printf("- os/arch: %s\n", runtime.GOARCH)
-	fmt.Printf("- go/version: %s\n", runtime.Version())
-	fmt.Printf("- go/linking: %s\n", linking)
-	fmt.Printf("- go/tags: %s\n", tagString)
+	return [][2]string{
+		{"rclone/version", fs.Version},
+		{"os/version", osVersion},
+		{"os/kernel", osKernel},
+		{"os/type", runtime.GOOS},
+		{"os/arch", runtime.GOARCH},
+		{"go/version", runtime.Version()},
+		{"go/linking", linking},
+		{"go/tags", tagString},
+	}
+}
+
+// ShowVersion prints the version to stdout
+func ShowVersion() {
+	info := VersionInfo()
+	fmt.Printf("rclone %s\n", info[0][1])
+	for _, kv := range info[1:] {
+		fmt.Printf("- %s: %s\n", kv[0], kv[1])
+	}
 }
 
 // NewFsFile creates an Fs from a name but may point to a file.
@@ -306,6 +318,9 @@ func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
 	if showStats && (accounting.GlobalStats().Errored() || *statsInterval > 0) {
 		accounting.GlobalStats().Log()
 	}
+	if showStats {
+		accounting.GlobalStats().LogRetries()
+	}
 	fs.Debugf(nil, "%d go routines active\n", runtime.NumGoroutine())
 
 	if ci.Progress && ci.ProgressTerminalTitle {
@@ -398,6 +413,11 @@ func initConfig() {
 	// Finish parsing any command line flags
 	configflags.SetFlags(ci)
 
+	// Don't ask for the config password when completing the command line
+	if isCompleting() {
+		ci.AskPassword = false
+	}
+
 	// Load the config
 	configfile.LoadConfig(ctx)
 
@@ -532,6 +552,7 @@ func Main() {
 	}
 	setupRootCommand(Root)
 	AddBackendFlags()
+	addRemoteCompletion(Root)
 	if err := Root.Execute(); err != nil {
 		if strings.HasPrefix(err.Error(), "unknown command") && selfupdateEnabled {
 			Root.PrintErrf("You could use '%s selfupdate' to get latest features.\n\n", Root.CommandPath())
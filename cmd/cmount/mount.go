@@ -9,10 +9,8 @@
 package cmount
 
 import (
-	"fmt"
 	"os"
 	"runtime"
-	"strings"
 	"sync/atomic"
 	"time"
 
@@ -39,98 +37,6 @@ func init() {
 	buildinfo.Tags = append(buildinfo.Tags, "cmount")
 }
 
-// Find the option string in the current options
-func findOption(name string, options []string) (found bool) {
-	for _, option := range options {
-		if option == "-o" {
-			continue
-		}
-		if strings.Contains(option, name) {
-			return true
-		}
-	}
-	return false
-}
-
-// mountOptions configures the options from the command line flags
-func mountOptions(VFS *vfs.VFS, device string, mountpoint string, opt *mountlib.Options) (options []string) {
-	// Options
-	options = []string{
-		"-o", fmt.Sprintf("attr_timeout=%g", opt.AttrTimeout.Seconds()),
-	}
-	if opt.DebugFUSE {
-		options = append(options, "-o", "debug")
-	}
-
-	if runtime.GOOS == "windows" {
-		options = append(options, "-o", "uid=-1")
-		options = append(options, "-o", "gid=-1")
-		options = append(options, "--FileSystemName=rclone")
-		if opt.VolumeName != "" {
-			if opt.NetworkMode {
-				options = append(options, "--VolumePrefix="+opt.VolumeName)
-			} else {
-				options = append(options, "-o", "volname="+opt.VolumeName)
-			}
-		}
-	} else {
-		options = append(options, "-o", "fsname="+device)
-		options = append(options, "-o", "subtype=rclone")
-		options = append(options, "-o", fmt.Sprintf("max_readahead=%d", opt.MaxReadAhead))
-		// This causes FUSE to supply O_TRUNC with the Open
-		// call which is more efficient for cmount.  However
-		// it does not work with cgofuse on Windows with
-		// WinFSP so cmount must work with or without it.
-		options = append(options, "-o", "atomic_o_trunc")
-		if opt.DaemonTimeout != 0 {
-			options = append(options, "-o", fmt.Sprintf("daemon_timeout=%d", int(opt.DaemonTimeout.Seconds())))
-		}
-		if opt.AllowNonEmpty {
-			options = append(options, "-o", "nonempty")
-		}
-		if opt.AllowOther {
-			options = append(options, "-o", "allow_other")
-		}
-		if opt.AllowRoot {
-			options = append(options, "-o", "allow_root")
-		}
-		if opt.DefaultPermissions {
-			options = append(options, "-o", "default_permissions")
-		}
-		if VFS.Opt.ReadOnly {
-			options = append(options, "-o", "ro")
-		}
-		if opt.WritebackCache {
-			// FIXME? options = append(options, "-o", WritebackCache())
-		}
-		if runtime.GOOS == "darwin" {
-			if opt.VolumeName != "" {
-				options = append(options, "-o", "volname="+opt.VolumeName)
-			}
-			if opt.NoAppleDouble {
-				options = append(options, "-o", "noappledouble")
-			}
-			if opt.NoAppleXattr {
-				options = append(options, "-o", "noapplexattr")
-			}
-		}
-	}
-	for _, option := range opt.ExtraOptions {
-		options = append(options, "-o", option)
-	}
-	for _, option := range opt.ExtraFlags {
-		options = append(options, option)
-	}
-	if runtime.GOOS == "darwin" {
-		if !findOption("modules=iconv", options) {
-			iconv := "modules=iconv,from_code=UTF-8,to_code=UTF-8-MAC"
-			options = append(options, "-o", iconv)
-			fs.Debugf(nil, "Adding \"-o %s\" for macOS", iconv)
-		}
-	}
-	return options
-}
-
 // waitFor runs fn() until it returns true or the timeout expires
 func waitFor(fn func() bool) (ok bool) {
 	const totalWait = 10 * time.Second
@@ -152,6 +58,11 @@ func waitFor(fn func() bool) (ok bool) {
 // returns an error, and an error channel for the serve process to
 // report an error when fusermount is called.
 func mount(VFS *vfs.VFS, mountPath string, opt *mountlib.Options) (<-chan error, func() error, error) {
+	// Refuse rather than mount without the access control asked for
+	if len(opt.AllowUID) > 0 || len(opt.AllowGID) > 0 {
+		return nil, nil, errors.New("--allow-uid and --allow-gid are not supported by cmount")
+	}
+
 	// Get mountpoint using OS specific logic
 	mountpoint, err := getMountpoint(mountPath, opt)
 	if err != nil {
@@ -0,0 +1,182 @@
+package cmount
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/artpar/rclone/cmd/mountlib"
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/vfs"
+)
+
+// goos is the OS the mount options are made for - it is a variable so
+// the tests can check the options for other OSes
+var goos = runtime.GOOS
+
+// Find the option string in the current options
+func findOption(name string, options []string) (found bool) {
+	for _, option := range options {
+		if option == "-o" {
+			continue
+		}
+		if strings.Contains(option, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuseType is the FUSE implementation installed on macOS
+type fuseType byte
+
+// FUSE implementations
+const (
+	fuseTypeNone fuseType = iota
+	fuseTypeMacFUSE
+	fuseTypeFUSET
+)
+
+// String turns a fuseType into a string
+func (t fuseType) String() string {
+	switch t {
+	case fuseTypeMacFUSE:
+		return "macFUSE"
+	case fuseTypeFUSET:
+		return "FUSE-T"
+	}
+	return "no FUSE"
+}
+
+// Paths whose presence shows which FUSE implementation is installed
+var (
+	macFUSEPaths = []string{
+		"/Library/Filesystems/macfuse.fs",
+		"/Library/Filesystems/osxfuse.fs",
+	}
+	fuseTPaths = []string{
+		"/usr/local/lib/libfuse-t.dylib",
+		"/Library/Application Support/fuse-t",
+	}
+)
+
+// anyExists returns true if any of paths exist
+func anyExists(paths []string) bool {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// detectFuseType works out which FUSE implementation is installed
+// on macOS, preferring macFUSE if both are.
+func detectFuseType() fuseType {
+	if anyExists(macFUSEPaths) {
+		return fuseTypeMacFUSE
+	}
+	if anyExists(fuseTPaths) {
+		return fuseTypeFUSET
+	}
+	return fuseTypeNone
+}
+
+// mountOptions configures the options from the command line flags
+func mountOptions(VFS *vfs.VFS, device string, mountpoint string, opt *mountlib.Options) (options []string) {
+	// Options
+	options = []string{
+		"-o", fmt.Sprintf("attr_timeout=%g", opt.AttrTimeout.Seconds()),
+	}
+	var fuseImpl fuseType
+	if goos == "darwin" {
+		fuseImpl = detectFuseType()
+		fs.Debugf(nil, "Detected %v for macOS", fuseImpl)
+	}
+	if opt.DebugFUSE {
+		options = append(options, "-o", "debug")
+	}
+
+	if goos == "windows" {
+		options = append(options, "-o", "uid=-1")
+		options = append(options, "-o", "gid=-1")
+		options = append(options, "--FileSystemName=rclone")
+		if opt.VolumeName != "" {
+			if opt.NetworkMode {
+				options = append(options, "--VolumePrefix="+opt.VolumeName)
+			} else {
+				options = append(options, "-o", "volname="+opt.VolumeName)
+			}
+		}
+	} else {
+		options = append(options, "-o", "fsname="+device)
+		options = append(options, "-o", "subtype=rclone")
+		options = append(options, "-o", fmt.Sprintf("max_readahead=%d", opt.MaxReadAhead))
+		// This causes FUSE to supply O_TRUNC with the Open
+		// call which is more efficient for cmount.  However
+		// it does not work with cgofuse on Windows with
+		// WinFSP so cmount must work with or without it.
+		options = append(options, "-o", "atomic_o_trunc")
+		if opt.DaemonTimeout != 0 && fuseImpl != fuseTypeFUSET {
+			options = append(options, "-o", fmt.Sprintf("daemon_timeout=%d", int(opt.DaemonTimeout.Seconds())))
+		}
+		if opt.AllowNonEmpty {
+			options = append(options, "-o", "nonempty")
+		}
+		if opt.AllowOther {
+			options = append(options, "-o", "allow_other")
+		}
+		if opt.AllowRoot {
+			options = append(options, "-o", "allow_root")
+		}
+		if opt.DefaultPermissions {
+			options = append(options, "-o", "default_permissions")
+		}
+		if VFS.Opt.ReadOnly {
+			options = append(options, "-o", "ro")
+		}
+		if opt.WritebackCache {
+			// FIXME? options = append(options, "-o", WritebackCache())
+		}
+		if goos == "darwin" {
+			if opt.VolumeName != "" {
+				options = append(options, "-o", "volname="+opt.VolumeName)
+			}
+			if opt.VolumeIcon != "" {
+				if fuseImpl == fuseTypeFUSET {
+					fs.Logf(nil, "--volicon is not supported by %v - ignoring", fuseImpl)
+				} else {
+					options = append(options, "-o", "volicon="+opt.VolumeIcon)
+				}
+			}
+			if opt.LocalVolume {
+				if fuseImpl == fuseTypeFUSET {
+					fs.Logf(nil, "--local-volume is not supported by %v - ignoring", fuseImpl)
+				} else {
+					options = append(options, "-o", "local")
+				}
+			}
+			if opt.NoAppleDouble {
+				options = append(options, "-o", "noappledouble")
+			}
+			if opt.NoAppleXattr && fuseImpl != fuseTypeFUSET {
+				options = append(options, "-o", "noapplexattr")
+			}
+		}
+	}
+	for _, option := range opt.ExtraOptions {
+		options = append(options, "-o", option)
+	}
+	for _, option := range opt.ExtraFlags {
+		options = append(options, option)
+	}
+	if goos == "darwin" && fuseImpl != fuseTypeFUSET {
+		if !findOption("modules=iconv", options) {
+			iconv := "modules=iconv,from_code=UTF-8,to_code=UTF-8-MAC"
+			options = append(options, "-o", iconv)
+			fs.Debugf(nil, "Adding \"-o %s\" for macOS", iconv)
+		}
+	}
+	return options
+}
@@ -0,0 +1,160 @@
+package cmount
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/cmd/mountlib"
+	"github.com/artpar/rclone/fstest/mockfs"
+	"github.com/artpar/rclone/vfs"
+	"github.com/artpar/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+)
+
+// optionsFor returns the mount options for os with opt
+func optionsFor(t *testing.T, os string, opt mountlib.Options, readOnly bool) []string {
+	oldGoos := goos
+	goos = os
+	defer func() {
+		goos = oldGoos
+	}()
+	vfsOpt := vfscommon.DefaultOpt
+	vfsOpt.ReadOnly = readOnly
+	VFS := vfs.New(mockfs.NewFs(context.Background(), "test", "root"), &vfsOpt)
+	defer VFS.Shutdown()
+	return mountOptions(VFS, "test:root", "/mnt", &opt)
+}
+
+// hasOption returns true if the option is in options after a "-o"
+func hasOption(options []string, option string) bool {
+	for i := 1; i < len(options); i++ {
+		if options[i-1] == "-o" && options[i] == option {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMountOptionsLinux(t *testing.T) {
+	opt := mountlib.DefaultOpt
+	opt.AllowOther = true
+	opt.DaemonTimeout = 0
+	opt.VolumeName = "potato"
+	opt.VolumeIcon = "/icon.icns"
+	opt.LocalVolume = true
+	opt.ExtraOptions = []string{"extra"}
+	opt.ExtraFlags = []string{"--flag"}
+	options := optionsFor(t, "linux", opt, true)
+	assert.True(t, hasOption(options, "attr_timeout=1"))
+	assert.True(t, hasOption(options, "fsname=test:root"))
+	assert.True(t, hasOption(options, "subtype=rclone"))
+	assert.True(t, hasOption(options, "max_readahead=131072"))
+	assert.True(t, hasOption(options, "atomic_o_trunc"))
+	assert.True(t, hasOption(options, "allow_other"))
+	assert.True(t, hasOption(options, "ro"))
+	assert.True(t, hasOption(options, "extra"))
+	assert.Contains(t, options, "--flag")
+	// macOS only options
+	assert.False(t, hasOption(options, "volname=potato"))
+	assert.False(t, hasOption(options, "volicon=/icon.icns"))
+	assert.False(t, hasOption(options, "local"))
+	assert.False(t, hasOption(options, "noappledouble"))
+	assert.False(t, findOption("modules=iconv", options))
+}
+
+func TestMountOptionsDarwin(t *testing.T) {
+	setFuseType(t, fuseTypeMacFUSE)
+	opt := mountlib.DefaultOpt
+	opt.VolumeName = "potato"
+	opt.DaemonTimeout = 0
+	options := optionsFor(t, "darwin", opt, false)
+	assert.True(t, hasOption(options, "volname=potato"))
+	assert.True(t, hasOption(options, "noappledouble"))
+	assert.False(t, hasOption(options, "noapplexattr"))
+	assert.False(t, hasOption(options, "local"))
+	assert.False(t, findOption("volicon", options))
+	assert.False(t, findOption("daemon_timeout", options))
+	assert.False(t, hasOption(options, "ro"))
+	assert.True(t, hasOption(options, "modules=iconv,from_code=UTF-8,to_code=UTF-8-MAC"))
+
+	opt.VolumeIcon = "/icon.icns"
+	opt.LocalVolume = true
+	opt.NoAppleDouble = false
+	opt.NoAppleXattr = true
+	opt.DaemonTimeout = 60 * time.Second
+	opt.ExtraOptions = []string{"modules=iconv,from_code=UTF-8,to_code=UTF-8"}
+	options = optionsFor(t, "darwin", opt, false)
+	assert.True(t, hasOption(options, "volicon=/icon.icns"))
+	assert.True(t, hasOption(options, "local"))
+	assert.False(t, hasOption(options, "noappledouble"))
+	assert.True(t, hasOption(options, "noapplexattr"))
+	assert.True(t, hasOption(options, "daemon_timeout=60"))
+	// shouldn't add iconv if the user has set it
+	assert.False(t, hasOption(options, "modules=iconv,from_code=UTF-8,to_code=UTF-8-MAC"))
+}
+
+// setFuseType makes detectFuseType find ft for the duration of the test
+func setFuseType(t *testing.T, ft fuseType) {
+	oldMacFUSEPaths, oldFuseTPaths := macFUSEPaths, fuseTPaths
+	t.Cleanup(func() {
+		macFUSEPaths, fuseTPaths = oldMacFUSEPaths, oldFuseTPaths
+	})
+	installed := []string{t.TempDir()}
+	missing := []string{filepath.Join(t.TempDir(), "missing")}
+	macFUSEPaths, fuseTPaths = missing, missing
+	switch ft {
+	case fuseTypeMacFUSE:
+		macFUSEPaths = installed
+	case fuseTypeFUSET:
+		fuseTPaths = installed
+	}
+}
+
+func TestDetectFuseType(t *testing.T) {
+	for _, ft := range []fuseType{fuseTypeNone, fuseTypeMacFUSE, fuseTypeFUSET} {
+		setFuseType(t, ft)
+		assert.Equal(t, ft, detectFuseType(), ft.String())
+	}
+
+	// macFUSE is preferred if both are installed
+	setFuseType(t, fuseTypeMacFUSE)
+	fuseTPaths = macFUSEPaths
+	assert.Equal(t, fuseTypeMacFUSE, detectFuseType())
+}
+
+func TestMountOptionsDarwinFUSET(t *testing.T) {
+	setFuseType(t, fuseTypeFUSET)
+	opt := mountlib.DefaultOpt
+	opt.VolumeName = "potato"
+	opt.VolumeIcon = "/icon.icns"
+	opt.LocalVolume = true
+	opt.NoAppleXattr = true
+	opt.DaemonTimeout = 60 * time.Second
+	options := optionsFor(t, "darwin", opt, false)
+	assert.True(t, hasOption(options, "volname=potato"))
+	assert.True(t, hasOption(options, "noappledouble"))
+	// options FUSE-T doesn't understand
+	assert.False(t, findOption("volicon", options))
+	assert.False(t, hasOption(options, "local"))
+	assert.False(t, hasOption(options, "noapplexattr"))
+	assert.False(t, findOption("daemon_timeout", options))
+	assert.False(t, findOption("modules=iconv", options))
+}
+
+func TestMountOptionsWindows(t *testing.T) {
+	opt := mountlib.DefaultOpt
+	opt.VolumeName = "potato"
+	options := optionsFor(t, "windows", opt, false)
+	assert.True(t, hasOption(options, "uid=-1"))
+	assert.True(t, hasOption(options, "gid=-1"))
+	assert.Contains(t, options, "--FileSystemName=rclone")
+	assert.True(t, hasOption(options, "volname=potato"))
+	assert.False(t, findOption("fsname", options))
+
+	opt.NetworkMode = true
+	options = optionsFor(t, "windows", opt, false)
+	assert.Contains(t, options, "--VolumePrefix=potato")
+	assert.False(t, hasOption(options, "volname=potato"))
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/cache"
+	"github.com/artpar/rclone/fs/config"
+	"github.com/spf13/cobra"
+)
+
+// isCompleting returns true if rclone was called by a shell to
+// complete the command line.
+func isCompleting() bool {
+	for _, arg := range os.Args[1:] {
+		if arg == cobra.ShellCompRequestCmd || arg == cobra.ShellCompNoDescRequestCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// addRemoteCompletion makes the arguments of command and all its
+// subcommands complete remote names and remote paths unless they
+// already have their own completion.
+func addRemoteCompletion(command *cobra.Command) {
+	for _, subCommand := range command.Commands() {
+		addRemoteCompletion(subCommand)
+	}
+	if command.HasSubCommands() || command.ValidArgsFunction != nil || len(command.ValidArgs) > 0 || command.Name() == "help" {
+		return
+	}
+	command.ValidArgsFunction = completeRemotePath
+}
+
+// completeRemotePath completes toComplete as a remote name if it has
+// no ":" or as a path on the remote if it does.
+//
+// Remote paths are completed one directory at a time so only the
+// directory being completed is listed.
+func completeRemotePath(command *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	colon := strings.IndexRune(toComplete, ':')
+	if colon < 0 {
+		completions := completeRemoteNames(toComplete)
+		if len(completions) == 0 {
+			// let the shell complete local paths
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return completions, cobra.ShellCompDirectiveNoSpace
+	}
+	return completeRemoteDir(context.Background(), toComplete[:colon+1], toComplete[colon+1:]), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRemoteNames returns the configured remotes starting with
+// prefix as "remote:" with the type of the remote as the description
+func completeRemoteNames(prefix string) (completions []string) {
+	remotes := config.FileSections()
+	sort.Strings(remotes)
+	for _, remote := range remotes {
+		if strings.HasPrefix(remote+":", prefix) {
+			completions = append(completions, remote+":\t"+config.FileGet(remote, "type"))
+		}
+	}
+	return completions
+}
+
+// completeRemoteDir lists the directory path is in on remote
+// returning the entries which start with path with a "/" on the end
+// of directories.
+func completeRemoteDir(ctx context.Context, remote, path string) (completions []string) {
+	dir, leaf := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir, leaf = path[:i+1], path[i+1:]
+	}
+	f, err := cache.Get(ctx, remote+dir)
+	if err != nil {
+		fs.Debugf(nil, "Completion: failed to create remote %q: %v", remote+dir, err)
+		return nil
+	}
+	entries, err := f.List(ctx, "")
+	if err != nil {
+		fs.Debugf(f, "Completion: failed to list: %v", err)
+		return nil
+	}
+	for _, entry := range entries {
+		name := entry.Remote()
+		if !strings.HasPrefix(name, leaf) {
+			continue
+		}
+		if _, isDir := entry.(fs.Directory); isDir {
+			name += "/"
+		}
+		completions = append(completions, remote+dir+name)
+	}
+	sort.Strings(completions)
+	return completions
+}
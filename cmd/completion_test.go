@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/artpar/rclone/backend/local"
+	"github.com/artpar/rclone/fs/config/configfile"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteRemotePath(t *testing.T) {
+	configfile.LoadConfig(context.Background())
+	dir, err := ioutil.TempDir("", "rclone-completion")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dir1", "sub"), 0777))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dir2"), 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "dir1", "file.txt"), []byte("hello"), 0666))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file"), []byte("hello"), 0666))
+	dir = filepath.ToSlash(dir)
+
+	require.NoError(t, os.Setenv("RCLONE_CONFIG_COMPLETETEST_TYPE", "local"))
+	defer func() {
+		_ = os.Unsetenv("RCLONE_CONFIG_COMPLETETEST_TYPE")
+	}()
+
+	for _, test := range []struct {
+		toComplete string
+		want       []string
+		directive  cobra.ShellCompDirective
+	}{
+		{"completet", []string{"completetest:\tlocal"}, cobra.ShellCompDirectiveNoSpace},
+		{"completetest:" + dir + "/d", []string{"completetest:" + dir + "/dir1/", "completetest:" + dir + "/dir2/"}, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp},
+		{"completetest:" + dir + "/dir1/", []string{"completetest:" + dir + "/dir1/file.txt", "completetest:" + dir + "/dir1/sub/"}, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp},
+		{"completetest:" + dir + "/potato/", nil, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp},
+		{"not-a-remote-for-sure", nil, cobra.ShellCompDirectiveDefault},
+	} {
+		got, directive := completeRemotePath(nil, nil, test.toComplete)
+		assert.Equal(t, test.want, got, test.toComplete)
+		assert.Equal(t, test.directive, directive, test.toComplete)
+	}
+
+	// Listing a file gives nothing
+	assert.Nil(t, completeRemoteDir(context.Background(), "completetest:", dir+"/file/"))
+}
+
+func TestAddRemoteCompletion(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	leaf := &cobra.Command{Use: "leaf", Run: func(*cobra.Command, []string) {}}
+	own := &cobra.Command{Use: "own", ValidArgs: []string{"a", "b"}, Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(leaf, own)
+	addRemoteCompletion(root)
+	assert.Nil(t, root.ValidArgsFunction)
+	assert.NotNil(t, leaf.ValidArgsFunction)
+	assert.Nil(t, own.ValidArgsFunction)
+}
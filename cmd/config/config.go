@@ -9,10 +9,12 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/artpar/rclone/backend/alias"
 	"github.com/artpar/rclone/cmd"
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/config"
 	"github.com/artpar/rclone/fs/config/flags"
+	"github.com/artpar/rclone/fs/operations"
 	"github.com/artpar/rclone/fs/rc"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -32,6 +34,9 @@ func init() {
 	configCommand.AddCommand(configReconnectCommand)
 	configCommand.AddCommand(configDisconnectCommand)
 	configCommand.AddCommand(configUserInfoCommand)
+	configCommand.AddCommand(configAuditCommand)
+	configCommand.AddCommand(configResolveCommand)
+	configCommand.AddCommand(configRedactedCommand)
 }
 
 var configCommand = &cobra.Command{
@@ -251,11 +256,14 @@ This reconnects remote: passed in to the cloud storage system.
 To disconnect the remote use "rclone config disconnect".
 
 This normally means going through the interactive oauth flow again.
+
+Afterwards rclone checks the new credentials work with a health check
+(see "rclone backend health") and returns an error if they don't.
 `,
 	RunE: func(command *cobra.Command, args []string) error {
 		ctx := context.Background()
 		cmd.CheckArgs(1, 1, command, args)
-		fsInfo, configName, _, config, err := fs.ConfigFs(args[0])
+		fsInfo, configName, fsPath, config, err := fs.ConfigFs(args[0])
 		if err != nil {
 			return err
 		}
@@ -263,6 +271,15 @@ This normally means going through the interactive oauth flow again.
 			return errors.Errorf("%s: doesn't support Reconnect", configName)
 		}
 		fsInfo.Config(ctx, configName, config)
+		f, err := fsInfo.NewFs(ctx, configName, fsPath, config)
+		if err != nil && err != fs.ErrorIsFile {
+			return errors.Wrap(err, "failed to make remote after reconnecting")
+		}
+		status := operations.HealthCheck(ctx, f)
+		if !status.OK {
+			return errors.Errorf("reconnected but the health check failed: %s", status.Error)
+		}
+		fs.Logf(f, "Reconnected and checked the credentials work")
 		return nil
 	},
 }
@@ -338,3 +355,199 @@ system.
 		return nil
 	},
 }
+
+var auditOpt = config.DefaultAuditOpt
+
+func init() {
+	cmdFlags := configAuditCommand.Flags()
+	flags.BoolVarP(cmdFlags, &jsonOutput, "json", "", false, "Format output as JSON")
+	flags.DurationVarP(cmdFlags, &auditOpt.ExpiryWindow, "expiry-window", "", auditOpt.ExpiryWindow, "Report tokens which expire within this time")
+	flags.DurationVarP(cmdFlags, &auditOpt.UnusedAge, "unused-age", "", auditOpt.UnusedAge, "Report remotes not used for this long")
+}
+
+var configAuditCommand = &cobra.Command{
+	Use:   "audit",
+	Short: `Report secrets and stale remotes in the config file.`,
+	Long: `
+This checks the config file and reports things which could be done to
+harden it. It doesn't change the config file or contact any remotes.
+
+It reports
+
+- ` + "`plaintext`" + ` - passwords which aren't obscured and other secrets
+  such as access keys which are stored in plaintext.
+- ` + "`obscured`" + ` - passwords which are obscured. Obscuring only
+  protects against casual viewing so consider config file encryption
+  if the config file isn't otherwise protected.
+- ` + "`token-expiring`" + ` and ` + "`token-expired`" + ` - oauth tokens
+  without a refresh token which expire within ` + "`--expiry-window`" + ` or have
+  expired. Use "rclone config reconnect" to renew them.
+- ` + "`unused`" + ` - remotes whose oauth token hasn't been refreshed for
+  ` + "`--unused-age`" + `. rclone refreshes the token when the remote is used
+  so these remotes probably aren't in use any more.
+- ` + "`unknown-type`" + ` - remotes with a backend type this rclone
+  doesn't know about.
+
+Each finding is printed on a line as "remote: kind: key: message".
+Use ` + "`--json`" + ` to output a JSON list instead.
+`,
+	RunE: func(command *cobra.Command, args []string) error {
+		cmd.CheckArgs(0, 0, command, args)
+		items := config.Audit(auditOpt)
+		if jsonOutput {
+			if items == nil {
+				items = []config.AuditItem{}
+			}
+			out := json.NewEncoder(os.Stdout)
+			out.SetIndent("", "\t")
+			return out.Encode(items)
+		}
+		for _, item := range items {
+			fmt.Println(item)
+		}
+		return nil
+	},
+}
+
+func init() {
+	flags.BoolVarP(configResolveCommand.Flags(), &jsonOutput, "json", "", false, "Format output as JSON")
+}
+
+var configResolveCommand = &cobra.Command{
+	Use:   "resolve remote:",
+	Short: `Print the chain of remotes an alias resolves to.`,
+	Long: `
+This follows remote: through any alias remotes it points to and prints
+each remote in the chain with its backend type, ending with the remote
+rclone actually uses. The names of any options passed through by the
+aliases are shown with the next remote, but not their values as they
+may be secret.
+
+For example
+
+    $ rclone config resolve photos:2021
+    photos:2021 (alias)
+    drive-photos:2021 (alias)
+    drive:Photos/2021 (drive) with root_folder_id
+
+An error is returned if the aliases form a loop or a remote in the
+chain doesn't exist.
+
+Use ` + "`--json`" + ` to output a JSON list instead.
+`,
+	RunE: func(command *cobra.Command, args []string) error {
+		cmd.CheckArgs(1, 1, command, args)
+		chain, err := alias.Resolve(args[0])
+		if jsonOutput {
+			if chain == nil {
+				chain = []alias.Link{}
+			}
+			out := json.NewEncoder(os.Stdout)
+			out.SetIndent("", "\t")
+			if encodeErr := out.Encode(chain); encodeErr != nil {
+				return encodeErr
+			}
+		} else {
+			for _, link := range chain {
+				if len(link.Options) > 0 {
+					fmt.Printf("%s (%s) with %s\n", link.Remote, link.Type, strings.Join(link.Options, ", "))
+				} else {
+					fmt.Printf("%s (%s)\n", link.Remote, link.Type)
+				}
+			}
+		}
+		return err
+	},
+}
+
+func init() {
+	flags.BoolVarP(configRedactedCommand.Flags(), &jsonOutput, "json", "", false, "Format output as JSON")
+}
+
+var configRedactedCommand = &cobra.Command{
+	Use:   "redacted [<remote>...]",
+	Short: `Print the config with secrets redacted for use in bug reports.`,
+	Long: `
+This prints the config for the remotes named, or all remotes if none
+are named, in config file format with passwords, tokens and other
+secrets replaced with ` + "`" + config.RedactedValue + "`" + `. This makes it safe to
+attach to a bug report or forum post.
+
+The structure of the config is kept intact - values which are empty
+stay empty and only the secret parts of oauth tokens are removed so
+their expiry can still be seen. Passwords embedded in URLs are
+replaced too. Values of keys which rclone doesn't recognise as options
+of the backend are redacted in case they are secret.
+
+After the config for each remote, any backend options which are set
+by command line flags or environment variables are shown as comments
+with their effective values.
+
+For example
+
+    $ rclone config redacted s3
+    [s3]
+    type = s3
+    access_key_id = XXX
+    provider = AWS
+    region = eu-west-2
+    secret_access_key = XXX
+    # effective options set by flags or environment variables
+    # chunk_size = 64M
+
+Use ` + "`--json`" + ` to output JSON instead. This includes the effective
+value of every backend option and where it was set - "config",
+"override" (a flag or environment variable) or "default".
+
+Always check the output before posting it publicly.
+`,
+	RunE: func(command *cobra.Command, args []string) error {
+		names := make([]string, len(args))
+		for i, arg := range args {
+			names[i] = strings.TrimRight(arg, ":")
+		}
+		remotes := config.Redacted(names...)
+		if jsonOutput {
+			if remotes == nil {
+				remotes = []config.RedactedRemote{}
+			}
+			out := json.NewEncoder(os.Stdout)
+			out.SetIndent("", "\t")
+			return out.Encode(remotes)
+		}
+		for i, remote := range remotes {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("[%s]\n", remote.Name)
+			keys := make([]string, 0, len(remote.Config))
+			for key := range remote.Config {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			// type first as in the config file
+			if value, ok := remote.Config["type"]; ok {
+				fmt.Printf("type = %s\n", value)
+			}
+			for _, key := range keys {
+				if key != "type" {
+					fmt.Printf("%s = %s\n", key, remote.Config[key])
+				}
+			}
+			var overrides []string
+			for key, option := range remote.Options {
+				if option.Source == config.SourceOverride {
+					overrides = append(overrides, key)
+				}
+			}
+			if len(overrides) > 0 {
+				sort.Strings(overrides)
+				fmt.Println("# effective options set by flags or environment variables")
+				for _, key := range overrides {
+					fmt.Printf("# %s = %s\n", key, remote.Options[key].Value)
+				}
+			}
+		}
+		return nil
+	},
+}
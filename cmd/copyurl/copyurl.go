@@ -1,10 +1,14 @@
 package copyurl
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/artpar/rclone/cmd"
 	"github.com/artpar/rclone/fs"
@@ -18,6 +22,7 @@ var (
 	printFilename = false
 	stdout        = false
 	noClobber     = false
+	urlsFrom      = ""
 )
 
 func init() {
@@ -27,10 +32,45 @@ func init() {
 	flags.BoolVarP(cmdFlags, &printFilename, "print-filename", "p", printFilename, "Print the resulting name from --auto-filename")
 	flags.BoolVarP(cmdFlags, &noClobber, "no-clobber", "", noClobber, "Prevent overwriting file with same name")
 	flags.BoolVarP(cmdFlags, &stdout, "stdout", "", stdout, "Write the output to stdout rather than a file")
+	flags.StringVarP(cmdFlags, &urlsFrom, "urls-from", "", urlsFrom, "Read URLs to copy to dest:path from this file (use - for stdin)")
+}
+
+// readURLs reads the URLs and optional destination paths, one per
+// line, from in
+func readURLs(in io.Reader) (items []operations.CopyURLItem, err error) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var item operations.CopyURLItem
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			item.URL, item.DstFileName = line[:i], strings.TrimSpace(line[i:])
+		} else {
+			item.URL = line
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// readURLsFrom reads the URLs from the file name or stdin if it is "-"
+func readURLsFrom(name string) (items []operations.CopyURLItem, err error) {
+	if name == "-" {
+		return readURLs(os.Stdin)
+	}
+	in, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.CheckClose(in, &err)
+	return readURLs(in)
 }
 
 var commandDefinition = &cobra.Command{
-	Use:   "copyurl https://example.com dest:path",
+	Use:   "copyurl https://example.com dest:path | --urls-from list.txt dest:path",
 	Short: `Copy url content to dest.`,
 	Long: `
 Download a URL's content and copy it to the destination without saving
@@ -46,8 +86,59 @@ destination if there is one with the same name.
 
 Setting ` + "`--stdout`" + ` or making the output file name ` + "`-`" + `
 will cause the output to be written to standard output.
+
+Setting ` + "`--urls-from file`" + ` will read URLs from the file (or standard
+input if it is ` + "`-`" + `), one per line, and copy them all to the directory
+dest:path, downloading ` + "`--transfers`" + ` of them at once. The file name
+is read from each URL as with ` + "`--auto-filename`" + ` unless it is followed
+by whitespace and the path to copy it to, relative to dest:path. If
+the path ends in ` + "`/`" + ` then the file name is read from the URL and the
+file put in that directory. Paths which would be outside dest:path,
+such as ` + "`../file`" + `, are refused. Blank lines and lines starting
+with ` + "`#`" + ` are ignored. For example
+
+    https://example.com/a.zip
+    https://example.com/download?id=2 b.zip
+    https://example.com/c.zip archive/
+
+copies the files to a.zip, b.zip and archive/c.zip.
+
+If the download is interrupted and the server supports it, rclone will
+resume it from where it stopped with a Range request, up to
+` + "`--low-level-retries`" + ` times. The server must send a strong ETag or a
+Last-Modified header so rclone can check the file hasn't changed in
+the meantime.
+
+When the download completes rclone checks that its size matches the
+Content-Length and its MD5 matches the Content-MD5 if the server sent
+them. If either differs the copy fails rather than leaving a truncated
+or corrupted file. If the ETag looks like an MD5 hash, as it does on
+S3 and many other servers, it is checked too, but as not all servers
+use an MD5 for the ETag a mismatch is only logged.
 `,
 	RunE: func(command *cobra.Command, args []string) (err error) {
+		if urlsFrom != "" {
+			cmd.CheckArgs(1, 1, command, args)
+			if stdout {
+				return errors.New("can't use --stdout with --urls-from")
+			}
+			fsdst := cmd.NewFsDir(args)
+			cmd.Run(false, true, command, func() error {
+				items, err := readURLsFrom(urlsFrom)
+				if err != nil {
+					return err
+				}
+				var mu sync.Mutex
+				return operations.CopyURLs(context.Background(), fsdst, items, noClobber, func(dst fs.Object) {
+					if printFilename {
+						mu.Lock()
+						fmt.Println(dst.Remote())
+						mu.Unlock()
+					}
+				})
+			})
+			return nil
+		}
 		cmd.CheckArgs(1, 2, command, args)
 
 		var dstFileName string
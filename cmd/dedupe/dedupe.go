@@ -19,7 +19,7 @@ var (
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlag := commandDefinition.Flags()
-	flags.FVarP(cmdFlag, &dedupeMode, "dedupe-mode", "", "Dedupe mode interactive|skip|first|newest|oldest|largest|smallest|rename.")
+	flags.FVarP(cmdFlag, &dedupeMode, "dedupe-mode", "", "Dedupe mode interactive|skip|first|newest|oldest|largest|smallest|rename|link.")
 	flags.BoolVarP(cmdFlag, &byHash, "by-hash", "", false, "Find indentical hashes rather than names")
 }
 
@@ -125,6 +125,18 @@ Dedupe can be run non interactively using the ` + "`" + `--dedupe-mode` + "`" +
   * ` + "`" + `--dedupe-mode smallest` + "`" + ` - removes identical files then keeps the smallest one.
   * ` + "`" + `--dedupe-mode rename` + "`" + ` - removes identical files then renames the rest to be different.
   * ` + "`" + `--dedupe-mode list` + "`" + ` - lists duplicate dirs and files only and changes nothing.
+  * ` + "`" + `--dedupe-mode link` + "`" + ` - keeps the first one and replaces the rest with hard links to it (` + "`--by-hash`" + ` only).
+
+When deduping by hash on a backend which supports hard links (e.g. the
+local backend) the interactive mode also offers to link all the copies
+to one of them. This keeps every path but stores the content only
+once. Files which are already hard links to each other are not
+reported as duplicates.
+
+For example to find all the files with the same content anywhere under
+a local directory and replace the copies with hard links, do
+
+    rclone dedupe --by-hash --dedupe-mode link /path/to/dir
 
 For example to rename all the identically named photos in your Google Photos directory, do
 
@@ -15,5 +15,12 @@ var completionDefinition = &cobra.Command{
 	Long: `
 Generates a shell completion script for rclone.
 Run with --help to list the supported shells.
+
+As well as the commands and flags, the scripts complete the names of
+the configured remotes and paths on remotes, e.g. ` + "`remote:path/to/`" + `,
+by calling rclone. Only the directory being completed is listed, so
+completing deep paths on slow remotes is quick. If the config file is
+encrypted the password isn't asked for, so set ` + "`RCLONE_CONFIG_PASS`" + `
+to use this.
 `,
 }
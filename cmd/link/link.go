@@ -3,7 +3,6 @@ package link
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/artpar/rclone/cmd"
 	"github.com/artpar/rclone/fs"
@@ -13,7 +12,7 @@ import (
 )
 
 var (
-	expire = fs.Duration(time.Hour * 24 * 365 * 100)
+	expire = fs.LinkExpireNever
 	unlink = false
 )
 
@@ -38,11 +37,13 @@ file or folder.
 If you supply the --expire flag, it will set the expiration time
 otherwise it will use the default (100 years). **Note** not all
 backends support the --expire flag - if the backend doesn't support it
-then the link returned won't expire.
+then the link returned won't expire. For example box and dropbox
+support it for paid accounts and onedrive supports it, but drive
+doesn't.
 
 Use the --unlink flag to remove existing public links to the file or
-folder. **Note** not all backends support "--unlink" flag - those that
-don't will just ignore it.
+folder, e.g. on box, drive, dropbox and onedrive. **Note** not all
+backends support "--unlink" flag - those that don't will just ignore it.
 
 If successful, the last line of the output will contain the
 link. Exact capabilities depend on the remote, but the link will
@@ -1,8 +1,9 @@
 package ls
 
 import (
+	"encoding/json"
 	"fmt"
-	"sort"
+	"os"
 
 	"github.com/artpar/rclone/cmd"
 	"github.com/artpar/rclone/fs/config"
@@ -12,40 +13,71 @@ import (
 
 // Globals
 var (
-	listLong bool
+	listLong   bool
+	jsonOutput bool
+	filterType string
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlags := commandDefinition.Flags()
 	flags.BoolVarP(cmdFlags, &listLong, "long", "", listLong, "Show the type as well as names.")
+	flags.BoolVarP(cmdFlags, &jsonOutput, "json", "", jsonOutput, "Format output as JSON.")
+	flags.StringVarP(cmdFlags, &filterType, "type", "", filterType, "Only list remotes of this backend type.")
 }
 
 var commandDefinition = &cobra.Command{
 	Use:   "listremotes",
 	Short: `List all the remotes in the config file.`,
 	Long: `
-rclone listremotes lists all the available remotes from the config file.
+rclone listremotes lists all the available remotes from the config file
+and those defined by environment variables.
 
 When uses with the -l flag it lists the types too.
+
+Use --type to only list remotes of a given backend type, eg
+
+    rclone listremotes --type s3
+
+Use --json to output a JSON list with the name, type, description and
+source of each remote. The source is "config" for remotes in the config
+file and "env" for remotes defined only by environment variables, eg
+
+    [
+        {
+            "name": "s3",
+            "type": "s3",
+            "description": "",
+            "source": "config"
+        }
+    ]
 `,
-	Run: func(command *cobra.Command, args []string) {
+	RunE: func(command *cobra.Command, args []string) error {
 		cmd.CheckArgs(0, 0, command, args)
-		remotes := config.FileSections()
-		sort.Strings(remotes)
+		remotes := []config.Remote{}
+		for _, remote := range config.Remotes() {
+			if filterType == "" || remote.Type == filterType {
+				remotes = append(remotes, remote)
+			}
+		}
+		if jsonOutput {
+			out := json.NewEncoder(os.Stdout)
+			out.SetIndent("", "    ")
+			return out.Encode(remotes)
+		}
 		maxlen := 1
 		for _, remote := range remotes {
-			if len(remote) > maxlen {
-				maxlen = len(remote)
+			if len(remote.Name) > maxlen {
+				maxlen = len(remote.Name)
 			}
 		}
 		for _, remote := range remotes {
 			if listLong {
-				remoteType := config.FileGet(remote, "type")
-				fmt.Printf("%-*s %s\n", maxlen+1, remote+":", remoteType)
+				fmt.Printf("%-*s %s\n", maxlen+1, remote.Name+":", remote.Type)
 			} else {
-				fmt.Printf("%s:\n", remote)
+				fmt.Printf("%s:\n", remote.Name)
 			}
 		}
+		return nil
 	},
 }
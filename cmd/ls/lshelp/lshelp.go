@@ -2,6 +2,9 @@ package lshelp
 
 import (
 	"strings"
+
+	"github.com/artpar/rclone/fs/config/flags"
+	"github.com/spf13/pflag"
 )
 
 // Help describes the common help for all the list commands
@@ -28,4 +31,24 @@ The other list commands |lsd|,|lsf|,|lsjson| do not recurse by default - use |-R
 Listing a non existent directory will produce an error except for
 remotes which can't have empty directories (e.g. s3, swift, or gcs -
 the bucket based remotes).
+
+|ls| and |lsl| take a |--porcelain| flag which produces output for
+scripts which is guaranteed not to change between releases. Each
+object is one line with these tab separated columns
+
+  * |ls|: size in bytes, path
+  * |lsl|: size in bytes, modification time, path
+
+The modification time is always in UTC in the format
+|2006-01-02T15:04:05.000000000Z|. Any backslash, tab, carriage return
+or newline in the path is escaped as |\\|, |\t|, |\r| or |\n|.
+New columns will only ever be added to the end of the line.
 `, "|", "`")
+
+// Porcelain is set by the --porcelain flag
+var Porcelain bool
+
+// AddPorcelainFlag adds the --porcelain flag to cmdFlags
+func AddPorcelainFlag(cmdFlags *pflag.FlagSet) {
+	flags.BoolVarP(cmdFlags, &Porcelain, "porcelain", "", false, "Output stable tab separated columns for scripts")
+}
@@ -9,13 +9,16 @@ import (
 	"github.com/pkg/errors"
 	"github.com/artpar/rclone/cmd"
 	"github.com/artpar/rclone/cmd/ls/lshelp"
+	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/config/flags"
 	"github.com/artpar/rclone/fs/operations"
 	"github.com/spf13/cobra"
 )
 
 var (
-	opt operations.ListJSONOpt
+	opt    operations.ListJSONOpt
+	stat   bool
+	stream bool
 )
 
 func init() {
@@ -30,6 +33,8 @@ func init() {
 	flags.BoolVarP(cmdFlags, &opt.FilesOnly, "files-only", "", false, "Show only files in the listing.")
 	flags.BoolVarP(cmdFlags, &opt.DirsOnly, "dirs-only", "", false, "Show only directories in the listing.")
 	flags.StringArrayVarP(cmdFlags, &opt.HashTypes, "hash-type", "", nil, "Show only this hash type (may be repeated).")
+	flags.BoolVarP(cmdFlags, &stat, "stat", "", false, "Just return the info for the pointed to file.")
+	flags.BoolVarP(cmdFlags, &stream, "stream", "", false, "Output one JSON object per line without the enclosing array.")
 }
 
 var commandDefinition = &cobra.Command{
@@ -73,6 +78,10 @@ request (e.g. s3, swift).
 
 If --encrypted is not specified the Encrypted won't be emitted.
 
+If the global --metadata flag is set then the metadata of files on
+backends which support it will be shown as "Metadata", a map of keys
+to values.
+
 If --dirs-only is not specified files in addition to directories are
 returned
 
@@ -98,19 +107,63 @@ will be shown ("2017-05-31T16:15:57+01:00").
 
 The whole output can be processed as a JSON blob, or alternatively it
 can be processed line by line as each item is written one to a line.
+
+If --stream is set then the enclosing array and the commas are left
+out so each line is a complete JSON object. This makes it easy for
+tools to process very large listings an item at a time. Items are
+written as soon as they are read so nothing is held in memory. Use
+--files-only, --no-mimetype and --no-modtime to make the listing as
+quick as possible - on remotes which can list recursively
+(--fast-list) --files-only means directories aren't even read.
+
+If --stat is set then the path given is looked up directly and a
+single JSON object describing it is output instead of a listing. This
+doesn't list the parent directory so is quick even in huge
+directories. If the path isn't found an error is returned. Note that
+directories won't have a ModTime when using --stat.
 ` + lshelp.Help,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
-		fsrc := cmd.NewFsSrc(args)
+		var fsrc fs.Fs
+		var remote string
+		if stat {
+			fsrc, remote = cmd.NewFsFile(args[0])
+		} else {
+			fsrc = cmd.NewFsSrc(args)
+		}
 		cmd.Run(false, false, command, func() error {
-			fmt.Println("[")
+			ctx := context.Background()
+			if stat {
+				item, err := operations.StatJSON(ctx, fsrc, remote, &opt)
+				if err != nil {
+					return err
+				}
+				if item == nil {
+					return fs.ErrorObjectNotFound
+				}
+				out, err := json.MarshalIndent(item, "", "\t")
+				if err != nil {
+					return errors.Wrap(err, "failed to marshal list object")
+				}
+				_, err = os.Stdout.Write(out)
+				if err != nil {
+					return errors.Wrap(err, "failed to write to output")
+				}
+				fmt.Println()
+				return nil
+			}
+			if !stream {
+				fmt.Println("[")
+			}
 			first := true
-			err := operations.ListJSON(context.Background(), fsrc, "", &opt, func(item *operations.ListJSONItem) error {
+			err := operations.ListJSON(ctx, fsrc, remote, &opt, func(item *operations.ListJSONItem) error {
 				out, err := json.Marshal(item)
 				if err != nil {
 					return errors.Wrap(err, "failed to marshal list object")
 				}
-				if first {
+				if stream {
+					out = append(out, '\n')
+				} else if first {
 					first = false
 				} else {
 					fmt.Print(",\n")
@@ -121,10 +174,12 @@ can be processed line by line as each item is written one to a line.
 				}
 				return nil
 			})
-			if !first {
-				fmt.Println()
+			if !stream {
+				if !first {
+					fmt.Println()
+				}
+				fmt.Println("]")
 			}
-			fmt.Println("]")
 			return err
 		})
 	},
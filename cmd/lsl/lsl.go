@@ -12,6 +12,7 @@ import (
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
+	lshelp.AddPorcelainFlag(commandDefinition.Flags())
 }
 
 var commandDefinition = &cobra.Command{
@@ -34,6 +35,9 @@ Eg
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
+			if lshelp.Porcelain {
+				return operations.ListLongPorcelain(context.Background(), fsrc, os.Stdout)
+			}
 			return operations.ListLong(context.Background(), fsrc, os.Stdout)
 		})
 	},
@@ -0,0 +1,44 @@
+package mergedirs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/fs/operations"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "mergedirs remote:path",
+	Short: `Merge directories with duplicate names.`,
+	Long: strings.ReplaceAll(`
+Some remotes, for example Google Drive, can have more than one
+directory with the same name. This command finds them in the path and
+merges the contents of each set of duplicates into the directory with
+the most entries, moving the files and directories in the others
+server-side and then deleting them once they are empty.
+
+Only the directories are merged. Files with the same name which end up
+in the same directory can be fixed afterwards with |rclone dedupe|.
+
+Use |--dry-run| to report the duplicate directories, the ID of each
+and how many entries they contain without changing anything:
+
+    rclone mergedirs --dry-run drive:path
+
+This is only supported by remotes which can merge directories
+server-side.
+`, "|", "`"),
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		fdst := cmd.NewFsSrc(args)
+		cmd.Run(false, false, command, func() error {
+			return operations.MergeDuplicateDirs(context.Background(), fdst)
+		})
+	},
+}
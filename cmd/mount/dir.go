@@ -49,6 +49,9 @@ var _ fusefs.NodeSetattrer = (*Dir)(nil)
 // Setattr handles attribute changes from FUSE. Currently supports ModTime only.
 func (d *Dir) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) (err error) {
 	defer log.Trace(d, "stat=%+v", req)("err=%v", &err)
+	if err = d.fsys.checkAccess(req.Hdr()); err != nil {
+		return err
+	}
 	if d.VFS().Opt.NoModTime {
 		return nil
 	}
@@ -62,6 +65,18 @@ func (d *Dir) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.
 	return translateError(err)
 }
 
+// Check interface satisfied
+var _ fusefs.NodeOpener = (*Dir)(nil)
+
+// Open the directory for reading, checking the caller is allowed to
+func (d *Dir) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenResponse) (fh fusefs.Handle, err error) {
+	defer log.Trace(d, "flags=%v", req.Flags)("fh=%v, err=%v", &fh, &err)
+	if err = d.fsys.checkAccess(req.Hdr()); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
 // Check interface satisfied
 var _ fusefs.NodeRequestLookuper = (*Dir)(nil)
 
@@ -73,6 +88,9 @@ var _ fusefs.NodeRequestLookuper = (*Dir)(nil)
 // Lookup need not to handle the names "." and "..".
 func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.LookupResponse) (node fusefs.Node, err error) {
 	defer log.Trace(d, "name=%q", req.Name)("node=%+v, err=%v", &node, &err)
+	if err = d.fsys.checkAccess(req.Hdr()); err != nil {
+		return nil, err
+	}
 	mnode, err := d.Dir.Stat(req.Name)
 	if err != nil {
 		return nil, translateError(err)
@@ -90,7 +108,7 @@ func (d *Dir) Lookup(ctx context.Context, req *fuse.LookupRequest, resp *fuse.Lo
 	case *vfs.Dir:
 		node = &Dir{x, d.fsys}
 	default:
-		return nil, errors.new("panic(\"bad type\")")
+		panic("bad type")
 	}
 	// Cache the node for later
 	mnode.SetSys(node)
@@ -140,6 +158,9 @@ var _ fusefs.NodeCreater = (*Dir)(nil)
 // Create makes a new file
 func (d *Dir) Create(ctx context.Context, req *fuse.CreateRequest, resp *fuse.CreateResponse) (node fusefs.Node, handle fusefs.Handle, err error) {
 	defer log.Trace(d, "name=%q", req.Name)("node=%v, handle=%v, err=%v", &node, &handle, &err)
+	if err = d.fsys.checkAccess(req.Hdr()); err != nil {
+		return nil, nil, err
+	}
 	file, err := d.Dir.Create(req.Name, int(req.Flags))
 	if err != nil {
 		return nil, nil, translateError(err)
@@ -158,6 +179,9 @@ var _ fusefs.NodeMkdirer = (*Dir)(nil)
 // Mkdir creates a new directory
 func (d *Dir) Mkdir(ctx context.Context, req *fuse.MkdirRequest) (node fusefs.Node, err error) {
 	defer log.Trace(d, "name=%q", req.Name)("node=%+v, err=%v", &node, &err)
+	if err = d.fsys.checkAccess(req.Hdr()); err != nil {
+		return nil, err
+	}
 	dir, err := d.Dir.Mkdir(req.Name)
 	if err != nil {
 		return nil, translateError(err)
@@ -174,6 +198,9 @@ var _ fusefs.NodeRemover = (*Dir)(nil)
 // may correspond to a file (unlink) or to a directory (rmdir).
 func (d *Dir) Remove(ctx context.Context, req *fuse.RemoveRequest) (err error) {
 	defer log.Trace(d, "name=%q", req.Name)("err=%v", &err)
+	if err = d.fsys.checkAccess(req.Hdr()); err != nil {
+		return err
+	}
 	err = d.Dir.RemoveName(req.Name)
 	if err != nil {
 		return translateError(err)
@@ -196,6 +223,9 @@ var _ fusefs.NodeRenamer = (*Dir)(nil)
 // Rename the file
 func (d *Dir) Rename(ctx context.Context, req *fuse.RenameRequest, newDir fusefs.Node) (err error) {
 	defer log.Trace(d, "oldName=%q, newName=%q, newDir=%+v", req.OldName, req.NewName, newDir)("err=%v", &err)
+	if err = d.fsys.checkAccess(req.Hdr()); err != nil {
+		return err
+	}
 	destDir, ok := newDir.(*Dir)
 	if !ok {
 		return errors.Errorf("Unknown Dir type %T", newDir)
@@ -247,6 +277,9 @@ var _ fusefs.NodeMknoder = (*Dir)(nil)
 // reason. We don't actually create a file here just the Node.
 func (d *Dir) Mknod(ctx context.Context, req *fuse.MknodRequest) (node fusefs.Node, err error) {
 	defer log.Trace(d, "name=%v, mode=%d, rdev=%d", req.Name, req.Mode, req.Rdev)("node=%v, err=%v", &node, &err)
+	if err = d.fsys.checkAccess(req.Hdr()); err != nil {
+		return nil, err
+	}
 	if req.Rdev != 0 {
 		fs.Errorf(d, "Can't create device node %q", req.Name)
 		return nil, fuse.EIO
@@ -46,6 +46,9 @@ var _ fusefs.NodeSetattrer = (*File)(nil)
 // Setattr handles attribute changes from FUSE. Currently supports ModTime and Size only
 func (f *File) Setattr(ctx context.Context, req *fuse.SetattrRequest, resp *fuse.SetattrResponse) (err error) {
 	defer log.Trace(f, "a=%+v", req)("err=%v", &err)
+	if err = f.fsys.checkAccess(req.Hdr()); err != nil {
+		return err
+	}
 	if !f.VFS().Opt.NoModTime {
 		if req.Valid.Mtime() {
 			err = f.File.SetModTime(req.Mtime)
@@ -65,6 +68,9 @@ var _ fusefs.NodeOpener = (*File)(nil)
 // Open the file for read or write
 func (f *File) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenResponse) (fh fusefs.Handle, err error) {
 	defer log.Trace(f, "flags=%v", req.Flags)("fh=%v, err=%v", &fh, &err)
+	if err = f.fsys.checkAccess(req.Hdr()); err != nil {
+		return nil, err
+	}
 
 	// fuse flags are based off syscall flags as are os flags, so
 	// should be compatible
@@ -22,6 +22,7 @@ type FS struct {
 	*vfs.VFS
 	f      fs.Fs
 	opt    *mountlib.Options
+	access *mountlib.AccessControl // nil if no --allow-uid/--allow-gid
 	server *fusefs.Server
 }
 
@@ -71,6 +72,16 @@ func (f *FS) Statfs(ctx context.Context, req *fuse.StatfsRequest, resp *fuse.Sta
 	return nil
 }
 
+// checkAccess returns an error if the process making the request
+// isn't allowed to use the mount by --allow-uid and --allow-gid
+func (f *FS) checkAccess(h *fuse.Header) error {
+	if !f.access.Allowed(h.Uid, h.Gid) {
+		fs.Debugf(nil, "Denying access to pid %d with uid %d gid %d", h.Pid, h.Uid, h.Gid)
+		return fuse.Errno(syscall.EACCES)
+	}
+	return nil
+}
+
 // Translate errors from mountlib
 func translateError(err error) error {
 	if err == nil {
@@ -95,6 +106,8 @@ func translateError(err error) error {
 		return fuse.Errno(syscall.EBADF)
 	case vfs.EROFS:
 		return fuse.Errno(syscall.EROFS)
+	case vfs.EACCES:
+		return fuse.Errno(syscall.EACCES)
 	case vfs.ENOSYS, fs.ErrorNotImplemented:
 		return fuse.ENOSYS
 	case vfs.EINVAL:
@@ -83,6 +83,11 @@ func mount(VFS *vfs.VFS, mountpoint string, opt *mountlib.Options) (<-chan error
 		}
 	}
 
+	access, err := mountlib.NewAccessControl(opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	f := VFS.Fs()
 	fs.Debugf(f, "Mounting on %q", mountpoint)
 	c, err := fuse.Mount(mountpoint, mountOptions(VFS, f.Name()+":"+f.Root(), opt)...)
@@ -91,6 +96,7 @@ func mount(VFS *vfs.VFS, mountpoint string, opt *mountlib.Options) (<-chan error
 	}
 
 	filesys := NewFS(VFS, opt)
+	filesys.access = access
 	filesys.server = fusefs.New(c, nil)
 
 	// Serve the mount point in the background returning error to errChan
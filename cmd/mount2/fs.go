@@ -122,6 +122,8 @@ func translateError(err error) syscall.Errno {
 		return syscall.EBADF
 	case vfs.EROFS:
 		return syscall.EROFS
+	case vfs.EACCES:
+		return syscall.EACCES
 	case vfs.ENOSYS, fs.ErrorNotImplemented:
 		return syscall.ENOSYS
 	case vfs.EINVAL:
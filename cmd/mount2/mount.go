@@ -14,6 +14,7 @@ import (
 	"github.com/artpar/rclone/cmd/mountlib"
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/vfs"
+	"github.com/pkg/errors"
 )
 
 func init() {
@@ -148,6 +149,11 @@ func mountOptions(fsys *FS, f fs.Fs) (mountOpts *fuse.MountOptions) {
 // returns an error, and an error channel for the serve process to
 // report an error when fusermount is called.
 func mount(VFS *vfs.VFS, mountpoint string, opt *mountlib.Options) (<-chan error, func() error, error) {
+	// Refuse rather than mount without the access control asked for
+	if len(opt.AllowUID) > 0 || len(opt.AllowGID) > 0 {
+		return nil, nil, errors.New("--allow-uid and --allow-gid are not supported by mount2")
+	}
+
 	f := VFS.Fs()
 	fs.Debugf(f, "Mounting on %q", mountpoint)
 
@@ -0,0 +1,65 @@
+package mountlib
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AccessControl restricts the use of the mount to processes running
+// with the user and group IDs set with --allow-uid and --allow-gid.
+//
+// This is checked as well as the file permissions so a mount with
+// --allow-other can be shared with some users but not others.
+type AccessControl struct {
+	uids map[uint32]struct{}
+	gids map[uint32]struct{}
+}
+
+// parseIDs parses a list of numeric user or group IDs
+func parseIDs(what string, list []string) (ids map[uint32]struct{}, err error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+	ids = make(map[uint32]struct{}, len(list))
+	for _, item := range list {
+		id, err := strconv.ParseUint(strings.TrimSpace(item), 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad %s %q", what, item)
+		}
+		ids[uint32(id)] = struct{}{}
+	}
+	return ids, nil
+}
+
+// NewAccessControl makes an AccessControl from the options or returns
+// nil if there are no restrictions
+func NewAccessControl(opt *Options) (ac *AccessControl, err error) {
+	if len(opt.AllowUID) == 0 && len(opt.AllowGID) == 0 {
+		return nil, nil
+	}
+	ac = &AccessControl{}
+	ac.uids, err = parseIDs("--allow-uid", opt.AllowUID)
+	if err != nil {
+		return nil, err
+	}
+	ac.gids, err = parseIDs("--allow-gid", opt.AllowGID)
+	if err != nil {
+		return nil, err
+	}
+	return ac, nil
+}
+
+// Allowed returns true if a process running with uid and gid may use
+// the mount. It returns true if ac is nil.
+func (ac *AccessControl) Allowed(uid, gid uint32) bool {
+	if ac == nil {
+		return true
+	}
+	if _, ok := ac.uids[uid]; ok {
+		return true
+	}
+	_, ok := ac.gids[gid]
+	return ok
+}
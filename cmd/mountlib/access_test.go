@@ -0,0 +1,31 @@
+package mountlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessControl(t *testing.T) {
+	ac, err := NewAccessControl(&Options{})
+	require.NoError(t, err)
+	assert.Nil(t, ac)
+	assert.True(t, ac.Allowed(1000, 1000))
+
+	ac, err = NewAccessControl(&Options{AllowUID: []string{"1000", " 1001"}, AllowGID: []string{"50"}})
+	require.NoError(t, err)
+	assert.True(t, ac.Allowed(1000, 1000))
+	assert.True(t, ac.Allowed(1001, 1000))
+	assert.True(t, ac.Allowed(1002, 50))
+	assert.False(t, ac.Allowed(1002, 1002))
+	assert.False(t, ac.Allowed(0, 0))
+
+	ac, err = NewAccessControl(&Options{AllowGID: []string{"50"}})
+	require.NoError(t, err)
+	assert.True(t, ac.Allowed(1000, 50))
+	assert.False(t, ac.Allowed(1000, 1000))
+
+	_, err = NewAccessControl(&Options{AllowUID: []string{"potato"}})
+	assert.EqualError(t, err, `bad --allow-uid "potato": strconv.ParseUint: parsing "potato": invalid syntax`)
+}
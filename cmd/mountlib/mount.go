@@ -32,6 +32,8 @@ type Options struct {
 	AllowNonEmpty      bool
 	AllowRoot          bool
 	AllowOther         bool
+	AllowUID           fs.CommaSepList // user IDs allowed to use the mount
+	AllowGID           fs.CommaSepList // group IDs allowed to use the mount
 	DefaultPermissions bool
 	WritebackCache     bool
 	Daemon             bool
@@ -40,6 +42,8 @@ type Options struct {
 	ExtraFlags         []string
 	AttrTimeout        time.Duration // how long the kernel caches attribute for
 	VolumeName         string
+	VolumeIcon         string // macOS only
+	LocalVolume        bool   // macOS only
 	NoAppleDouble      bool
 	NoAppleXattr       bool
 	DaemonTimeout      time.Duration // OSXFUSE only
@@ -94,12 +98,16 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &Opt.AllowNonEmpty, "allow-non-empty", "", Opt.AllowNonEmpty, "Allow mounting over a non-empty directory. Not supported on Windows.")
 	flags.BoolVarP(flagSet, &Opt.AllowRoot, "allow-root", "", Opt.AllowRoot, "Allow access to root user. Not supported on Windows.")
 	flags.BoolVarP(flagSet, &Opt.AllowOther, "allow-other", "", Opt.AllowOther, "Allow access to other users. Not supported on Windows.")
+	flags.FVarP(flagSet, &Opt.AllowUID, "allow-uid", "", "Only allow processes with these comma separated user IDs to use the mount. Not supported on Windows.")
+	flags.FVarP(flagSet, &Opt.AllowGID, "allow-gid", "", "Only allow processes with these comma separated group IDs to use the mount. Not supported on Windows.")
 	flags.BoolVarP(flagSet, &Opt.AsyncRead, "async-read", "", Opt.AsyncRead, "Use asynchronous reads. Not supported on Windows.")
 	flags.FVarP(flagSet, &Opt.MaxReadAhead, "max-read-ahead", "", "The number of bytes that can be prefetched for sequential reads. Not supported on Windows.")
 	flags.BoolVarP(flagSet, &Opt.WritebackCache, "write-back-cache", "", Opt.WritebackCache, "Makes kernel buffer writes before sending them to rclone. Without this, writethrough caching is used. Not supported on Windows.")
 	// Windows and OSX
 	flags.StringVarP(flagSet, &Opt.VolumeName, "volname", "", Opt.VolumeName, "Set the volume name. Supported on Windows and OSX only.")
 	// OSX only
+	flags.StringVarP(flagSet, &Opt.VolumeIcon, "volicon", "", Opt.VolumeIcon, "Path to an .icns file to use as the volume icon. Supported on OSX with macFUSE only.")
+	flags.BoolVarP(flagSet, &Opt.LocalVolume, "local-volume", "", Opt.LocalVolume, "Show the volume in Finder as a local disk which can be ejected. Supported on OSX with macFUSE only.")
 	flags.BoolVarP(flagSet, &Opt.NoAppleDouble, "noappledouble", "", Opt.NoAppleDouble, "Ignore Apple Double (._) and .DS_Store files. Supported on OSX only.")
 	flags.BoolVarP(flagSet, &Opt.NoAppleXattr, "noapplexattr", "", Opt.NoAppleXattr, "Ignore all \"com.apple.*\" extended attributes. Supported on OSX only.")
 	// Windows only
@@ -203,6 +211,11 @@ When running in background mode the user will have to stop the mount manually:
 The umount operation can fail, for example when the mountpoint is busy.
 When that happens, it is the user's responsibility to stop the mount manually.
 
+If rclone is killed or crashes the mount is left behind and using the
+mountpoint gives "Transport endpoint is not connected" errors. On
+Linux |rclone mount| detects this when it starts and unmounts the
+stale mount with |fusermount -uz| before mounting again.
+
 The size of the mounted file system will be set according to information retrieved
 from the remote, the same as returned by the [rclone about](https://rclone.org/commands/rclone_about/)
 command. Remotes with unlimited storage may report the used size only,
@@ -213,6 +226,26 @@ at all, then 1PB is set as both the total and the free size.
 **Note**: As of |rclone| 1.52.2, |rclone mount| now requires Go version 1.13
 or newer on some platforms depending on the underlying FUSE library in use.
 
+### Installing on macOS
+
+To run rclone @ on macOS you will need to install either
+[macFUSE](https://osxfuse.github.io/) or [FUSE-T](https://www.fuse-t.org/).
+Rclone detects which of these is installed when mounting and only
+passes options the installed one understands. If both are installed
+macFUSE is used.
+
+The volume shown in Finder is named after the remote unless
+|--volname| is given. With macFUSE you can also give the volume an
+icon with |--volicon /path/to/icon.icns|, and as macFUSE volumes are
+shown as network volumes by default, use |--local-volume| to show the
+volume in Finder as a local disk which can be ejected. FUSE-T supports
+neither of these so they are ignored with a warning when using it.
+
+Finder stores its metadata in Apple Double (|._|) and |.DS_Store|
+files which rclone stops it creating by default. Use
+|--noappledouble=false| to allow them and |--noapplexattr| to stop
+Finder and other applications using |com.apple.*| extended attributes.
+
 ### Installing on Windows
 
 To run rclone @ on Windows, you will need to
@@ -356,6 +389,30 @@ infrastructure](https://github.com/billziss-gh/winfsp/wiki/WinFsp-Service-Archit
 which creates drives accessible for everyone on the system or
 alternatively using [the nssm service manager](https://nssm.cc/usage).
 
+### Restricting access to the mount
+
+By default only the user who ran rclone can use the mount. With
+|--allow-other| any user can use it, subject to the file permissions
+if |--default-permissions| is also set.
+
+On Linux |--allow-other| needs |user_allow_other| to be set in
+|/etc/fuse.conf| unless rclone is run as root, and rclone will refuse
+to start the mount if it isn't.
+
+On a shared machine this may not be enough as the mount has the
+credentials for the remote. The |--allow-uid| and |--allow-gid| flags
+take comma separated lists of numeric user and group IDs and rclone
+will refuse to look up, open, create, rename or remove anything in the
+mount for processes which aren't running with one of those user IDs or
+with one of those groups as their primary group. Note that the user
+running rclone must be in the list if it is to use the mount too.
+
+    rclone mount remote: /mnt/remote --allow-other --allow-uid 1000,1001 --allow-gid 100
+
+These flags are supported by |rclone mount| on Linux and FreeBSD only.
+|rclone cmount| and |rclone mount2| will refuse to mount if they are
+set.
+
 ### Limitations
 
 Without the use of |--vfs-cache-mode| this can only write files
@@ -440,6 +497,13 @@ With |--vfs-read-chunk-size 100M| and |--vfs-read-chunk-size-limit 0|
 the following parts will be downloaded: 0-100M, 100M-200M, 200M-300M, 300M-400M and so on.
 When |--vfs-read-chunk-size-limit 500M| is specified, the result would be
 0-100M, 100M-300M, 300M-700M, 700M-1200M, 1200M-1700M and so on.
+
+When the file is read from somewhere other than where the last read
+finished, the chunk size is halved until it reaches
+|--vfs-read-chunk-size-min|, which defaults to |--vfs-read-chunk-size|.
+Setting it lower, for example |--vfs-read-chunk-size-min 1M|, means
+random access to large files doesn't download more than it needs,
+while sequential reads still grow the chunk size.
 `, "|", "`"), "@", commandName) + vfs.Help,
 		Run: func(command *cobra.Command, args []string) {
 			cmd.CheckArgs(2, 2, command, args)
@@ -464,9 +528,10 @@ When |--vfs-read-chunk-size-limit 500M| is specified, the result would be
 				defer cmd.StartStats()()
 			}
 
-			// Inform about ignored flags on Windows,
-			// and if not on Windows and not --allow-non-empty flag is used
-			// verify that mountpoint is empty.
+			// Inform about ignored flags on Windows, and if not on
+			// Windows clean up any stale mount, check --allow-other
+			// can be used and, unless the --allow-non-empty flag is
+			// used, verify that mountpoint is empty.
 			if runtime.GOOS == "windows" {
 				if opt.AllowNonEmpty {
 					fs.Logf(nil, "--allow-non-empty flag does nothing on Windows")
@@ -477,11 +542,28 @@ When |--vfs-read-chunk-size-limit 500M| is specified, the result would be
 				if opt.AllowOther {
 					fs.Logf(nil, "--allow-other flag does nothing on Windows")
 				}
-			} else if !opt.AllowNonEmpty {
-				err := checkMountEmpty(mountpoint)
-				if err != nil {
-					log.Printf("Fatal error: %v", err)
-					return
+			} else {
+				if isStaleMount(mountpoint) {
+					fs.Logf(nil, "Unmounting stale mount at %q left by a previous mount", mountpoint)
+					err := cleanStaleMount(mountpoint)
+					if err != nil {
+						log.Printf("Fatal error: %v", err)
+						return
+					}
+				}
+				if opt.AllowOther {
+					err := checkAllowOther()
+					if err != nil {
+						log.Printf("Fatal error: %v", err)
+						return
+					}
+				}
+				if !opt.AllowNonEmpty {
+					err := checkMountEmpty(mountpoint)
+					if err != nil {
+						log.Printf("Fatal error: %v", err)
+						return
+					}
 				}
 			}
 
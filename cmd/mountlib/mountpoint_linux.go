@@ -0,0 +1,73 @@
+// Stale mount and --allow-other checks for Linux only
+
+// +build linux
+
+package mountlib
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// fuseConf is the FUSE config file which must contain
+// user_allow_other for non root users to use --allow-other
+const fuseConf = "/etc/fuse.conf"
+
+// isStaleMount returns true if mountpoint is a FUSE mount whose
+// server has gone away, for example because rclone crashed.
+func isStaleMount(mountpoint string) bool {
+	_, err := os.Stat(mountpoint)
+	pathErr, ok := err.(*os.PathError)
+	return ok && pathErr.Err == syscall.ENOTCONN
+}
+
+// cleanStaleMount lazily unmounts the stale mount at mountpoint
+func cleanStaleMount(mountpoint string) (err error) {
+	for _, command := range []string{"fusermount", "fusermount3"} {
+		out, cmdErr := exec.Command(command, "-uz", mountpoint).CombinedOutput()
+		if cmdErr == nil {
+			return nil
+		}
+		fs.Debugf(nil, "%s -uz %q failed: %v: %s", command, mountpoint, cmdErr, bytes.TrimSpace(out))
+		if err == nil {
+			err = errors.Wrapf(cmdErr, "failed to unmount stale mount %q: %s", mountpoint, bytes.TrimSpace(out))
+		}
+	}
+	return err
+}
+
+// checkAllowOther checks that --allow-other will be accepted by
+// fusermount which needs user_allow_other in /etc/fuse.conf unless
+// running as root.
+func checkAllowOther() error {
+	if os.Geteuid() == 0 {
+		return nil
+	}
+	return checkFuseConf(fuseConf)
+}
+
+// checkFuseConf checks user_allow_other is set in the FUSE config
+// file at path
+func checkFuseConf(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		fs.Debugf(nil, "Can't check %q for user_allow_other: %v", path, err)
+		return nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "user_allow_other" {
+			return nil
+		}
+	}
+	return errors.Errorf("--allow-other needs user_allow_other set in %s: add it (as root) or run rclone as root", path)
+}
@@ -0,0 +1,53 @@
+// +build linux
+
+package mountlib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFuseConf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-fuse-conf")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "fuse.conf")
+
+	// missing file
+	assert.Error(t, checkFuseConf(path))
+
+	for _, test := range []struct {
+		conf string
+		ok   bool
+	}{
+		{"", false},
+		{"# mount_max = 1000\n#user_allow_other\n", false},
+		{"# mount_max = 1000\nuser_allow_other\n", true},
+		{"  user_allow_other  ", true},
+	} {
+		require.NoError(t, ioutil.WriteFile(path, []byte(test.conf), 0600))
+		err := checkFuseConf(path)
+		if test.ok {
+			assert.NoError(t, err, test.conf)
+		} else {
+			assert.Error(t, err, test.conf)
+		}
+	}
+}
+
+func TestIsStaleMount(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-stale-mount")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	assert.False(t, isStaleMount(dir))
+	assert.False(t, isStaleMount(filepath.Join(dir, "notfound")))
+}
@@ -0,0 +1,22 @@
+// Stale mount and --allow-other checks for non Linux platforms
+
+// +build !linux
+
+package mountlib
+
+// isStaleMount returns true if mountpoint is a FUSE mount whose
+// server has gone away. This is only detected on Linux.
+func isStaleMount(mountpoint string) bool {
+	return false
+}
+
+// cleanStaleMount lazily unmounts the stale mount at mountpoint
+func cleanStaleMount(mountpoint string) error {
+	return nil
+}
+
+// checkAllowOther checks that --allow-other will be accepted. This is
+// only checked on Linux.
+func checkAllowOther() error {
+	return nil
+}
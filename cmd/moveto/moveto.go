@@ -39,6 +39,12 @@ This will:
         move it to dst, overwriting existing files if they exist
         see move command for full details
 
+If src and dst are on the same remote and it supports server-side
+moves then the move is done as a rename without copying any data, even
+if src and dst are in different directories. A directory is moved with
+a single rename if dst doesn't exist, otherwise its contents are
+moved into dst one file at a time.
+
 This doesn't transfer unchanged files, testing by size and
 modification time or MD5SUM.  src will be deleted on successful
 transfer.
@@ -54,7 +60,7 @@ transfer.
 
 		cmd.Run(true, true, command, func() error {
 			if srcFileName == "" {
-				return sync.MoveDir(context.Background(), fdst, fsrc, false, false)
+				return sync.MoveDirTo(context.Background(), fdst, fsrc, false, false)
 			}
 			return operations.MoveFile(context.Background(), fdst, fsrc, dstFileName, srcFileName)
 		})
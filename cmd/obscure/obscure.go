@@ -2,17 +2,29 @@ package obscure
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 
 	"os"
 
 	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config"
+	"github.com/artpar/rclone/fs/config/flags"
 	"github.com/artpar/rclone/fs/config/obscure"
+	"github.com/artpar/rclone/lib/terminal"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var (
+	reveal = false
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.BoolVarP(cmdFlags, &reveal, "reveal", "", reveal, "Reveal an obscured password instead")
 }
 
 var commandDefinition = &cobra.Command{
@@ -40,7 +52,18 @@ obfuscating the hyphen itself.
 
 If you want to encrypt the config file then please use config file
 encryption - see [rclone config](/commands/rclone_config/) for more
-info.`,
+info.
+
+Use the ` + "`--reveal`" + ` flag to do the reverse and reveal an obscured
+password. As this prints the password, rclone will ask for
+confirmation first if it is run from a terminal unless the
+` + "`--auto-confirm`" + ` flag is set.
+
+    rclone obscure --reveal <obscured password>
+
+To see which passwords in the config file are obscured and which other
+secrets are stored in plaintext use
+[rclone config audit](/commands/rclone_config_audit/).`,
 	RunE: func(command *cobra.Command, args []string) error {
 		cmd.CheckArgs(1, 1, command, args)
 		var password string
@@ -57,6 +80,9 @@ info.`,
 			password = args[0]
 		}
 		cmd.Run(false, false, command, func() error {
+			if reveal {
+				return revealPassword(context.Background(), password)
+			}
 			obscured := obscure.MustObscure(password)
 			fmt.Println(obscured)
 			return nil
@@ -64,3 +90,20 @@ info.`,
 		return nil
 	},
 }
+
+// revealPassword prints the revealed password, asking for
+// confirmation first if run interactively
+func revealPassword(ctx context.Context, password string) error {
+	revealed, err := obscure.Reveal(password)
+	if err != nil {
+		return err
+	}
+	if terminal.IsTerminal(int(os.Stdin.Fd())) && !fs.GetConfig(ctx).AutoConfirm {
+		fmt.Println("This will print the password in plaintext on the terminal. Continue?")
+		if !config.Confirm(false) {
+			return errors.New("reveal cancelled")
+		}
+	}
+	fmt.Println(revealed)
+	return nil
+}
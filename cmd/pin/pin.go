@@ -0,0 +1,82 @@
+package pin
+
+import (
+	"fmt"
+
+	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/vfs/vfscache"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(pinDefinition)
+	cmd.Root.AddCommand(unpinDefinition)
+}
+
+// run pins or unpins the paths in args[1:] in the VFS cache of the
+// remote in args[0] and prints the paths pinned afterwards
+func run(args []string, pin bool) error {
+	fsrc := cmd.NewFsDir(args)
+	pinned, err := vfscache.EditPins(fsrc, args[1:], pin)
+	if err != nil {
+		return err
+	}
+	for _, name := range pinned {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+var pinDefinition = &cobra.Command{
+	Use:   "pin remote:path [path...]",
+	Short: `Pin files or directories in the VFS cache.`,
+	Long: `
+This stops the files and directories passed in, and everything in the
+directories, from being removed from the VFS cache of remote:path by
+the cache cleaner because of ` + "`--vfs-cache-max-age`" + ` or
+` + "`--vfs-cache-max-size`" + `. Files which aren't in the cache yet are pinned
+when they are read.
+
+remote:path should be the same as given to ` + "`rclone mount`" + ` or
+` + "`rclone serve`" + ` and the paths are relative to it, eg
+
+    rclone pin remote:media films/favourite.mkv music
+
+The pins are kept in the ` + "`--cache-dir`" + ` and apply the next time
+remote:path is mounted or served. An rclone which is already using
+the cache picks them up the next time it cleans the cache, or use
+` + "`rclone rc vfs/pin`" + ` to pin paths in it straight away.
+
+It prints all the pinned paths afterwards. Use it with no paths to
+list them.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1e6, command, args)
+		cmd.Run(false, false, command, func() error {
+			return run(args, true)
+		})
+	},
+}
+
+var unpinDefinition = &cobra.Command{
+	Use:   "unpin remote:path path...",
+	Short: `Unpin files or directories in the VFS cache.`,
+	Long: `
+This allows files and directories pinned with ` + "`rclone pin`" + ` or
+` + "`rclone rc vfs/pin`" + ` to be removed from the VFS cache of remote:path
+again, eg
+
+    rclone unpin remote:media films/favourite.mkv
+
+It is an error to unpin a path which isn't pinned. Note that a file in
+a pinned directory can't be unpinned on its own.
+
+It prints the paths which are still pinned afterwards.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 1e6, command, args)
+		cmd.Run(false, false, command, func() error {
+			return run(args, false)
+		})
+	},
+}
@@ -7,12 +7,19 @@ import (
 	"time"
 
 	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/fs/config/flags"
 	"github.com/artpar/rclone/fs/operations"
 	"github.com/spf13/cobra"
 )
 
+var (
+	size = int64(-1)
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.Int64VarP(cmdFlags, &size, "size", "", size, "Size of the data on stdin in bytes if known")
 }
 
 var commandDefinition = &cobra.Command{
@@ -37,10 +44,31 @@ must fit into RAM. The cutoff needs to be small enough to adhere
 the limits of your remote, please see there. Generally speaking,
 setting this cutoff too high will decrease your performance.
 
+Remotes which can't stream uploads but can do chunked (multipart)
+uploads are sent the data in chunks of ` + "`--streaming-chunk-size`" + `
+(by default the chunk size of the remote) with only one chunk held in
+memory at a time. Other remotes which can't stream have the data
+spooled to a temporary file on local disk first.
+
 Note that the upload can also not be retried because the data is
 not kept around until the upload succeeds. If you need to transfer
 a lot of data, you're better off caching locally and then
-` + "`rclone move`" + ` it to the destination.`,
+` + "`rclone move`" + ` it to the destination.
+
+If you know the size of the data in advance, pass it with
+` + "`--size`" + `, e.g.
+
+    rclone rcat --size 1048576 remote:path/to/file < file
+
+This lets backends which need to know the length in advance do a
+single part upload without spooling the data to disk or RAM first. If
+the data piped in isn't exactly this size the upload will fail and
+the remote file will be removed.
+
+Unless ` + "`--ignore-checksum`" + ` is set rclone calculates a hash of
+the data as it is uploaded and checks it against the hash of the
+remote file afterwards, so a corrupted upload is reported as an error.
+This is only possible if the remote supports hashes.`,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 
@@ -51,6 +79,10 @@ a lot of data, you're better off caching locally and then
 
 		fdst, dstFileName := cmd.NewFsDstFile(args)
 		cmd.Run(false, false, command, func() error {
+			if size >= 0 {
+				_, err := operations.RcatSize(context.Background(), fdst, dstFileName, os.Stdin, size, time.Now())
+				return err
+			}
 			_, err := operations.Rcat(context.Background(), fdst, dstFileName, os.Stdin, time.Now())
 			return err
 		})
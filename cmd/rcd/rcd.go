@@ -6,7 +6,9 @@ import (
 	"sync"
 
 	sysdnotify "github.com/iguanesolutions/go-systemd/v5/notify"
+	sysdwatchdog "github.com/iguanesolutions/go-systemd/v5/notify/watchdog"
 	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/rc/rcflags"
 	"github.com/artpar/rclone/fs/rc/rcserver"
 	"github.com/artpar/rclone/lib/atexit"
@@ -30,6 +32,35 @@ for GET requests on the URL passed in.  It will also open the URL in
 the browser when rclone is run.
 
 See the [rc documentation](/rc/) for more info on the rc flags.
+
+### Running under systemd
+
+rclone rcd tells systemd when it is ready to accept commands so it
+can be run as a ` + "`Type=notify`" + ` service. If ` + "`WatchdogSec=`" + ` is set
+in the unit then rclone pings the systemd watchdog at half that
+interval for as long as the rc server is running.
+
+It also supports systemd socket activation. If rclone is started from
+a ` + "`.socket`" + ` unit then the rc server listens on the socket systemd
+passes in rather than opening the ` + "`--rc-addr`" + ` itself. This means
+rclone doesn't need permission to bind the address and that requests
+made while rclone is starting are queued rather than refused. For
+example, with an ` + "`rclone-rcd.socket`" + ` containing
+
+    [Socket]
+    ListenStream=127.0.0.1:5572
+
+    [Install]
+    WantedBy=sockets.target
+
+and an ` + "`rclone-rcd.service`" + ` containing
+
+    [Service]
+    Type=notify
+    ExecStart=/usr/bin/rclone rcd --rc-user admin --rc-pass secret
+    WatchdogSec=30
+
+Only the first socket passed in is used.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(0, 1, command, args)
@@ -66,7 +97,34 @@ See the [rc documentation](/rc/) for more info on the rc flags.
 			log.Fatalf("failed to notify ready to systemd: %v", err)
 		}
 
+		// Ping the systemd watchdog while the server is running
+		stopWatchdog := make(chan struct{})
+		go watchdog(stopWatchdog)
+		defer close(stopWatchdog)
+
 		s.Wait()
 		finalise()
 	},
 }
+
+// watchdog pings the systemd watchdog, if it is enabled, until stop
+// is closed
+func watchdog(stop <-chan struct{}) {
+	wd, err := sysdwatchdog.New()
+	if err != nil {
+		// watchdog not enabled
+		return
+	}
+	ticker := wd.NewTicker()
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := wd.SendHeartbeat(); err != nil {
+				fs.Errorf(nil, "Failed to ping systemd watchdog: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
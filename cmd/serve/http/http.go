@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"os"
@@ -15,19 +16,24 @@ import (
 	"github.com/artpar/rclone/cmd/serve/httplib/serve"
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/accounting"
+	"github.com/artpar/rclone/fs/config/flags"
 	"github.com/artpar/rclone/vfs"
 	"github.com/artpar/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 )
 
+// vhostsFile is the config file for serving several remotes
+var vhostsFile string
+
 func init() {
 	httpflags.AddFlags(Command.Flags())
 	vfsflags.AddFlags(Command.Flags())
+	flags.StringVarP(Command.Flags(), &vhostsFile, "vhosts", "", "", "File mapping hostnames or path prefixes to the remotes to serve")
 }
 
 // Command definition for cobra
 var Command = &cobra.Command{
-	Use:   "http remote:path",
+	Use:   "http [remote:path]",
 	Short: `Serve the remote over HTTP.`,
 	Long: `rclone serve http implements a basic web server to serve the remote
 over HTTP.  This can be viewed in a web browser or you can make a
@@ -40,12 +46,50 @@ The server will log errors.  Use -v to see access logs.
 
 --bwlimit will be respected for file transfers.  Use --stats to
 control the stats printing.
+
+### Serving several remotes
+
+Use ` + "`--vhosts file`" + ` to serve several remotes from one listener.
+Each line of the file is a hostname or a path prefix starting with
+` + "`/`" + ` followed by the remote to serve for it, for example
+
+    # Serve by Host header
+    www.example.com    s3:example-www
+    files.example.com  drive:public
+    # Serve by path prefix
+    /docs              /srv/docs
+
+Requests are matched against the hostnames first (ignoring any port)
+and then the longest matching path prefix, which is removed from the
+path before it is looked up in the remote. Requests which don't match
+are served from the remote:path argument if given, otherwise they get
+a 404 error. Blank lines and lines starting with ` + "`#`" + ` are ignored.
+
+The file is checked for changes every second and reloaded in the
+background if it has changed. If the new file can't be read the old
+one continues to be used. Remotes which are no longer in the file are
+shut down once the requests using them have finished. The VFS flags
+apply to each of the remotes.
 ` + httplib.Help + vfs.Help,
 	Run: func(command *cobra.Command, args []string) {
-		cmd.CheckArgs(1, 1, command, args)
-		f := cmd.NewFsSrc(args)
+		if vhostsFile != "" {
+			cmd.CheckArgs(0, 1, command, args)
+		} else {
+			cmd.CheckArgs(1, 1, command, args)
+		}
+		var f fs.Fs
+		if len(args) > 0 {
+			f = cmd.NewFsSrc(args)
+		}
 		cmd.Run(false, true, command, func() error {
 			s := newServer(f, &httpflags.Opt)
+			if vhostsFile != "" {
+				var err error
+				s.vhosts, err = newVHosts(context.Background(), vhostsFile)
+				if err != nil {
+					return err
+				}
+			}
 			err := s.Serve()
 			if err != nil {
 				return err
@@ -59,8 +103,9 @@ control the stats printing.
 // server contains everything to run the server
 type server struct {
 	*httplib.Server
-	f   fs.Fs
-	vfs *vfs.VFS
+	f      fs.Fs
+	vfs    *vfs.VFS // VFS to serve, nil if f is nil
+	vhosts *vhosts  // if set the VFS to serve for each host or path prefix
 }
 
 func newServer(f fs.Fs, opt *httplib.Options) *server {
@@ -68,7 +113,9 @@ func newServer(f fs.Fs, opt *httplib.Options) *server {
 	s := &server{
 		Server: httplib.NewServer(mux, opt),
 		f:      f,
-		vfs:    vfs.New(f, &vfsflags.Opt),
+	}
+	if f != nil {
+		s.vfs = vfs.New(f, &vfsflags.Opt)
 	}
 	mux.HandleFunc(s.Opt.BaseURL+"/", s.handler)
 	return s
@@ -99,19 +146,35 @@ func (s *server) handler(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	VFS := s.vfs
+	if s.vhosts != nil {
+		if hostVFS, prefix, release := s.vhosts.find(r.Host, urlPath); hostVFS != nil {
+			defer release()
+			if urlPath == prefix {
+				http.Redirect(w, r, s.Opt.BaseURL+prefix+"/", http.StatusPermanentRedirect)
+				return
+			}
+			VFS = hostVFS
+			urlPath = urlPath[len(prefix):]
+		}
+	}
+	if VFS == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
 	isDir := strings.HasSuffix(urlPath, "/")
 	remote := strings.Trim(urlPath, "/")
 	if isDir {
-		s.serveDir(w, r, remote)
+		s.serveDir(w, r, VFS, remote)
 	} else {
-		s.serveFile(w, r, remote)
+		s.serveFile(w, r, VFS, remote)
 	}
 }
 
 // serveDir serves a directory index at dirRemote
-func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote string) {
+func (s *server) serveDir(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, dirRemote string) {
 	// List the directory
-	node, err := s.vfs.Stat(dirRemote)
+	node, err := VFS.Stat(dirRemote)
 	if err == vfs.ENOENT {
 		http.Error(w, "Directory not found", http.StatusNotFound)
 		return
@@ -151,8 +214,8 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 }
 
 // serveFile serves a file object at remote
-func (s *server) serveFile(w http.ResponseWriter, r *http.Request, remote string) {
-	node, err := s.vfs.Stat(remote)
+func (s *server) serveFile(w http.ResponseWriter, r *http.Request, VFS *vfs.VFS, remote string) {
+	node, err := VFS.Stat(remote)
 	if err == vfs.ENOENT {
 		fs.Infof(remote, "%s: File not found", r.RemoteAddr)
 		http.Error(w, "File not found", http.StatusNotFound)
@@ -0,0 +1,286 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/cache"
+	"github.com/artpar/rclone/vfs"
+	"github.com/artpar/rclone/vfs/vfsflags"
+	"github.com/pkg/errors"
+)
+
+// vhostsCheckInterval is how often the vhosts file is checked for
+// changes
+var vhostsCheckInterval = time.Second
+
+// vhost maps a hostname or a path prefix to a remote
+type vhost struct {
+	match  string // lower case hostname or path prefix starting with /
+	remote string // remote to serve
+}
+
+// vhostVFS is a VFS being served. Once it is no longer in the config
+// it is shut down when the last request using it has finished.
+type vhostVFS struct {
+	vfs      *vfs.VFS
+	users    int  // number of requests using the VFS
+	retired  bool // set if the VFS is no longer in the config
+	shutdown bool // set once the VFS has been shut down
+}
+
+// vhostPrefix is a path prefix and the VFS it serves
+type vhostPrefix struct {
+	prefix string
+	vfs    *vhostVFS
+}
+
+// vhostsConfig is a loaded config file. It isn't modified once loaded.
+type vhostsConfig struct {
+	modTime  time.Time            // modification time of the file when read
+	size     int64                // size of the file when read
+	hosts    map[string]*vhostVFS // VFS to serve by hostname
+	prefixes []vhostPrefix        // VFS to serve by path prefix, longest first
+	vfses    map[string]*vhostVFS // all the VFS in use by remote
+}
+
+// vhosts serves different remotes depending on the Host header or
+// the path prefix of the request as read from a config file which is
+// reloaded in the background when it changes.
+type vhosts struct {
+	ctx       context.Context
+	path      string // path of the config file
+	mu        sync.Mutex
+	checked   time.Time     // when the file was last checked
+	reloading bool          // set while the file is being reloaded
+	config    *vhostsConfig // the config in use
+}
+
+// parseVHosts parses the vhosts config from in.
+//
+// Each line is a hostname or a path prefix starting with "/" followed
+// by the remote to serve for it. Blank lines and lines starting with
+// "#" are ignored.
+func parseVHosts(in io.Reader) (hosts []vhost, err error) {
+	seen := map[string]struct{}{}
+	scanner := bufio.NewScanner(in)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d: expecting \"host remote:path\" or \"/prefix remote:path\" but got %q", lineNumber, line)
+		}
+		match := fields[0]
+		if strings.HasPrefix(match, "/") {
+			match = strings.TrimRight(match, "/")
+			if match == "" {
+				return nil, errors.Errorf("line %d: path prefix can't be \"/\"", lineNumber)
+			}
+		} else {
+			match = strings.ToLower(match)
+		}
+		if _, found := seen[match]; found {
+			return nil, errors.Errorf("line %d: duplicate entry for %q", lineNumber, match)
+		}
+		seen[match] = struct{}{}
+		hosts = append(hosts, vhost{match: match, remote: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+// newVHosts reads the vhosts config file at path
+func newVHosts(ctx context.Context, path string) (*vhosts, error) {
+	v := &vhosts{
+		ctx:    ctx,
+		path:   path,
+		config: &vhostsConfig{},
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read vhosts")
+	}
+	err = v.load(fi)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// load reads the config file making a VFS for any new remotes and
+// swapping it for the config in use. The VFS which are no longer used
+// are retired.
+//
+// If there is an error the old config is kept. Only one load may run
+// at once.
+func (v *vhosts) load(fi os.FileInfo) (err error) {
+	in, err := os.Open(v.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read vhosts")
+	}
+	defer fs.CheckClose(in, &err)
+	entries, err := parseVHosts(in)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse vhosts %q", v.path)
+	}
+	v.mu.Lock()
+	old := v.config
+	v.mu.Unlock()
+	config := &vhostsConfig{
+		modTime: fi.ModTime(),
+		size:    fi.Size(),
+		hosts:   map[string]*vhostVFS{},
+		vfses:   map[string]*vhostVFS{},
+	}
+	var made []*vfs.VFS
+	defer func() {
+		// Shut down the VFS made for the new config if it failed
+		if err != nil {
+			for _, VFS := range made {
+				VFS.Shutdown()
+			}
+		}
+	}()
+	for _, entry := range entries {
+		VFS := config.vfses[entry.remote]
+		if VFS == nil {
+			VFS = old.vfses[entry.remote]
+		}
+		if VFS == nil {
+			f, err := cache.Get(v.ctx, entry.remote)
+			if err != nil {
+				return errors.Wrapf(err, "failed to make remote %q for %q", entry.remote, entry.match)
+			}
+			VFS = &vhostVFS{vfs: vfs.New(f, &vfsflags.Opt)}
+			made = append(made, VFS.vfs)
+		}
+		config.vfses[entry.remote] = VFS
+		if strings.HasPrefix(entry.match, "/") {
+			config.prefixes = append(config.prefixes, vhostPrefix{prefix: entry.match, vfs: VFS})
+		} else {
+			config.hosts[entry.match] = VFS
+		}
+	}
+	sort.Slice(config.prefixes, func(i, j int) bool {
+		return len(config.prefixes[i].prefix) > len(config.prefixes[j].prefix)
+	})
+	v.mu.Lock()
+	v.config = config
+	var shutdown []*vhostVFS
+	for remote, VFS := range old.vfses {
+		if _, found := config.vfses[remote]; !found {
+			fs.Debugf(nil, "vhosts: no longer serving %q", remote)
+			VFS.retired = true
+			if VFS.users == 0 {
+				VFS.shutdown = true
+				shutdown = append(shutdown, VFS)
+			}
+		}
+	}
+	v.mu.Unlock()
+	for _, VFS := range shutdown {
+		VFS.vfs.Shutdown()
+	}
+	fs.Infof(nil, "vhosts: serving %d remotes from %q", len(config.vfses), v.path)
+	return nil
+}
+
+// reload reloads the config file if it has changed since it was last
+// read
+func (v *vhosts) reload() {
+	fi, err := os.Stat(v.path)
+	if err != nil {
+		fs.Errorf(nil, "vhosts: failed to check %q - using old config: %v", v.path, err)
+		return
+	}
+	v.mu.Lock()
+	config := v.config
+	v.mu.Unlock()
+	if fi.ModTime().Equal(config.modTime) && fi.Size() == config.size {
+		return
+	}
+	err = v.load(fi)
+	if err != nil {
+		fs.Errorf(nil, "vhosts: failed to reload - using old config: %v", err)
+	}
+}
+
+// checkReload starts reloading the config file in the background if
+// it hasn't been checked for vhostsCheckInterval. Requests carry on
+// using the old config until the new one is loaded. Call with the
+// mutex held.
+func (v *vhosts) checkReload() {
+	now := time.Now()
+	if v.reloading || now.Sub(v.checked) < vhostsCheckInterval {
+		return
+	}
+	v.checked = now
+	v.reloading = true
+	go func() {
+		v.reload()
+		v.mu.Lock()
+		v.reloading = false
+		v.mu.Unlock()
+	}()
+}
+
+// release is called when a request has finished with VFS, shutting
+// it down if it has been retired and this was the last user
+func (v *vhosts) release(VFS *vhostVFS) {
+	v.mu.Lock()
+	VFS.users--
+	shutdown := VFS.retired && VFS.users == 0 && !VFS.shutdown
+	if shutdown {
+		VFS.shutdown = true
+	}
+	v.mu.Unlock()
+	if shutdown {
+		VFS.vfs.Shutdown()
+	}
+}
+
+// find returns the VFS to serve the request for host and urlPath
+// along with the path prefix which matched, if any.
+//
+// Hostnames are checked before path prefixes. It returns a nil VFS
+// if nothing matched. Otherwise release must be called when the
+// request has finished with the VFS.
+func (v *vhosts) find(host, urlPath string) (VFS *vfs.VFS, prefix string, release func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.checkReload()
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	matched := v.config.hosts[strings.ToLower(host)]
+	if matched == nil {
+		for _, p := range v.config.prefixes {
+			if urlPath == p.prefix || strings.HasPrefix(urlPath, p.prefix+"/") {
+				matched, prefix = p.vfs, p.prefix
+				break
+			}
+		}
+	}
+	if matched == nil {
+		return nil, "", nil
+	}
+	matched.users++
+	return matched.vfs, prefix, func() {
+		v.release(matched)
+	}
+}
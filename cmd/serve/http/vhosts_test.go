@@ -0,0 +1,159 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/cmd/serve/httplib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVHosts(t *testing.T) {
+	got, err := parseVHosts(strings.NewReader(`
+# comment
+Example.COM   remote:bucket
+/docs/        /srv/docs
+
+/a/b          other:
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []vhost{
+		{match: "example.com", remote: "remote:bucket"},
+		{match: "/docs", remote: "/srv/docs"},
+		{match: "/a/b", remote: "other:"},
+	}, got)
+
+	for _, in := range []string{
+		"example.com",
+		"example.com remote: extra",
+		"/ remote:",
+		"example.com a:\nEXAMPLE.com b:",
+	} {
+		_, err := parseVHosts(strings.NewReader(in))
+		assert.Error(t, err, in)
+	}
+}
+
+func TestVHosts(t *testing.T) {
+	oldInterval := vhostsCheckInterval
+	vhostsCheckInterval = 0
+	defer func() {
+		vhostsCheckInterval = oldInterval
+	}()
+
+	dir, err := ioutil.TempDir("", "rclone-vhosts")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	for _, site := range []string{"one", "two"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, site), 0777))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, site, "file.txt"), []byte("site "+site), 0666))
+	}
+	config := filepath.Join(dir, "vhosts")
+	writeConfig := func(s string) {
+		require.NoError(t, ioutil.WriteFile(config, []byte(s), 0666))
+	}
+	writeConfig("one.example.com " + filepath.Join(dir, "one") + "\n/two " + filepath.Join(dir, "two") + "\n")
+
+	opt := httplib.DefaultOpt
+	opt.ListenAddr = testBindAddress
+	s := newServer(nil, &opt)
+	s.vhosts, err = newVHosts(context.Background(), config)
+	require.NoError(t, err)
+	require.NoError(t, s.Serve())
+	defer func() {
+		s.Close()
+		s.Wait()
+	}()
+	URL := s.Server.URL()
+
+	get := func(host, path string) (int, string) {
+		req, err := http.NewRequest("GET", URL+path, nil)
+		require.NoError(t, err)
+		req.Host = host
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("one.example.com:8080", "/file.txt")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "site one", body)
+
+	status, body = get("localhost", "/two/file.txt")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "site two", body)
+
+	status, body = get("localhost", "/two/")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "file.txt")
+
+	// no default remote
+	status, _ = get("localhost", "/file.txt")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// change the config and check it is reloaded
+	writeConfig("two.example.com " + filepath.Join(dir, "two") + "\n")
+	// make sure the modification time changes
+	require.NoError(t, os.Chtimes(config, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+	// the config is reloaded in the background
+	assert.Eventually(t, func() bool {
+		status, _ := get("two.example.com", "/file.txt")
+		return status == http.StatusOK
+	}, 10*time.Second, 10*time.Millisecond)
+	status, body = get("two.example.com", "/file.txt")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "site two", body)
+	status, _ = get("one.example.com", "/file.txt")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// a broken config keeps the old one
+	writeConfig("broken")
+	s.vhosts.reload()
+	status, _ = get("two.example.com", "/file.txt")
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestVHostsRetire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-vhosts")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	config := filepath.Join(dir, "vhosts")
+	writeConfig := func(s string, modTime time.Time) {
+		require.NoError(t, ioutil.WriteFile(config, []byte(s), 0666))
+		require.NoError(t, os.Chtimes(config, modTime, modTime))
+	}
+	now := time.Now()
+	writeConfig("one.example.com "+dir+"\n", now)
+	v, err := newVHosts(context.Background(), config)
+	require.NoError(t, err)
+	one := v.config.hosts["one.example.com"]
+	require.NotNil(t, one)
+
+	// a request is using the VFS when it is removed from the config
+	VFS, _, release := v.find("one.example.com", "/")
+	require.NotNil(t, VFS)
+	writeConfig("two.example.com "+dir+"/\n", now.Add(time.Minute))
+	v.reload()
+	VFS, _, _ = v.find("one.example.com", "/")
+	assert.Nil(t, VFS)
+	assert.True(t, one.retired)
+	assert.False(t, one.shutdown)
+
+	// it is shut down once the request finishes
+	release()
+	assert.True(t, one.shutdown)
+}
@@ -0,0 +1,253 @@
+package httplib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// Access log formats
+const (
+	AccessLogCommon   = "common"
+	AccessLogCombined = "combined"
+	AccessLogJSON     = "json"
+)
+
+// clfTimeFormat is the time format used in the Common Log Format
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// accessLog writes a line for each request to a file, rotating the
+// file when it gets too big
+type accessLog struct {
+	mu         sync.Mutex
+	path       string   // path of the log file
+	format     string   // one of the AccessLog* formats
+	maxSize    int64    // rotate the file when it reaches this size if > 0
+	maxBackups int      // number of rotated files to keep
+	file       *os.File // current log file
+	size       int64    // size of the current log file
+}
+
+// newAccessLog opens the access log described by opt
+func newAccessLog(opt *Options) (*accessLog, error) {
+	l := &accessLog{
+		path:       opt.AccessLog,
+		format:     opt.AccessLogFormat,
+		maxSize:    int64(opt.AccessLogMaxSize),
+		maxBackups: opt.AccessLogMaxBackups,
+	}
+	switch l.format {
+	case "":
+		l.format = AccessLogCommon
+	case AccessLogCommon, AccessLogCombined, AccessLogJSON:
+	default:
+		return nil, errors.Errorf("unknown access log format %q - use %q, %q or %q", l.format, AccessLogCommon, AccessLogCombined, AccessLogJSON)
+	}
+	err := l.open()
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// open the log file for appending
+func (l *accessLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return errors.Wrap(err, "failed to open access log")
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "failed to stat access log")
+	}
+	l.file = file
+	l.size = fi.Size()
+	return nil
+}
+
+// backupName returns the name of the nth rotated log file
+func (l *accessLog) backupName(n int) string {
+	return l.path + "." + strconv.Itoa(n)
+}
+
+// rotate renames the log file to .1, .1 to .2 and so on, removing
+// the oldest, then opens a new log file
+//
+// Call with the lock held
+func (l *accessLog) rotate() error {
+	err := l.file.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to close access log")
+	}
+	l.file = nil
+	if l.maxBackups <= 0 {
+		err = os.Remove(l.path)
+	} else {
+		_ = os.Remove(l.backupName(l.maxBackups))
+		for n := l.maxBackups - 1; n >= 1; n-- {
+			_ = os.Rename(l.backupName(n), l.backupName(n+1))
+		}
+		err = os.Rename(l.path, l.backupName(1))
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to rotate access log")
+	}
+	return l.open()
+}
+
+// write a line to the log, rotating it first if necessary
+func (l *accessLog) write(line []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return
+	}
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		err := l.rotate()
+		if err != nil {
+			fs.Errorf(l.path, "Access log: %v", err)
+			if l.file == nil {
+				return
+			}
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		fs.Errorf(l.path, "Access log: failed to write: %v", err)
+	}
+}
+
+// Close the log file
+func (l *accessLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// accessLogEntry is a line in the JSON access log
+type accessLogEntry struct {
+	Time       string  `json:"time"`
+	RemoteAddr string  `json:"remote_addr"`
+	User       string  `json:"user,omitempty"`
+	Method     string  `json:"method"`
+	URI        string  `json:"uri"`
+	Proto      string  `json:"proto"`
+	Status     int     `json:"status"`
+	Size       int64   `json:"size"`
+	Referer    string  `json:"referer,omitempty"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+	Duration   float64 `json:"duration"` // in seconds
+}
+
+// clfString quotes s for the Common Log Format or returns "-" if empty
+func clfString(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strconv.Quote(s)
+}
+
+// formatLine makes the log line for the request
+func (l *accessLog) formatLine(r *http.Request, user string, start time.Time, status int, size int64) []byte {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if l.format == AccessLogJSON {
+		line, err := json.Marshal(accessLogEntry{
+			Time:       start.Format(time.RFC3339Nano),
+			RemoteAddr: host,
+			User:       user,
+			Method:     r.Method,
+			URI:        r.RequestURI,
+			Proto:      r.Proto,
+			Status:     status,
+			Size:       size,
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+			Duration:   time.Since(start).Seconds(),
+		})
+		if err != nil {
+			return nil
+		}
+		return append(line, '\n')
+	}
+	if user == "" {
+		user = "-"
+	}
+	sizeString := "-"
+	if size > 0 {
+		sizeString = strconv.FormatInt(size, 10)
+	}
+	line := fmt.Sprintf("%s - %s [%s] %s %d %s", host, user, start.Format(clfTimeFormat), strconv.Quote(r.Method+" "+r.RequestURI+" "+r.Proto), status, sizeString)
+	if l.format == AccessLogCombined {
+		line += " " + clfString(r.Referer()) + " " + clfString(r.UserAgent())
+	}
+	return []byte(line + "\n")
+}
+
+// loggingResponseWriter records the status and size of the response
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// WriteHeader records the status
+func (w *loggingResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the size
+func (w *loggingResponseWriter) Write(p []byte) (n int, err error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err = w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush flushes the underlying ResponseWriter if possible
+func (w *loggingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// handler wraps next logging each request
+func (l *accessLog) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		lw := &loggingResponseWriter{ResponseWriter: w}
+		// The user is logged whether or not they authenticated
+		// successfully as with other web servers
+		user, _, _ := parseAuthorization(r)
+		defer func() {
+			status := lw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			l.write(l.formatLine(r, user, start, status, lw.size))
+		}()
+		next.ServeHTTP(lw, r)
+	})
+}
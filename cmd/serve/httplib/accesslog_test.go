@@ -0,0 +1,100 @@
+package httplib
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doRequest makes a request through the access log handler
+func doRequest(t *testing.T, l *accessLog, url string) {
+	handler := l.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	req := httptest.NewRequest("GET", url, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.SetBasicAuth("frank", "secret")
+	req.Header.Set("User-Agent", "test/1.0")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestAccessLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-access-log")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	readLog := func(name string) string {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("Common", func(t *testing.T) {
+		l, err := newAccessLog(&Options{AccessLog: filepath.Join(dir, "common.log")})
+		require.NoError(t, err)
+		doRequest(t, l, "/file.txt")
+		doRequest(t, l, "/missing")
+		require.NoError(t, l.Close())
+		lines := strings.Split(strings.TrimSpace(readLog("common.log")), "\n")
+		require.Equal(t, 2, len(lines))
+		assert.Regexp(t, regexp.MustCompile(`^192\.0\.2\.1 - frank \[\d\d/\w\w\w/\d{4}:\d\d:\d\d:\d\d [-+]\d{4}\] "GET /file.txt HTTP/1.1" 200 5$`), lines[0])
+		assert.Regexp(t, regexp.MustCompile(`"GET /missing HTTP/1.1" 404 10$`), lines[1])
+	})
+
+	t.Run("Combined", func(t *testing.T) {
+		l, err := newAccessLog(&Options{AccessLog: filepath.Join(dir, "combined.log"), AccessLogFormat: AccessLogCombined})
+		require.NoError(t, err)
+		doRequest(t, l, "/file.txt")
+		require.NoError(t, l.Close())
+		assert.True(t, strings.HasSuffix(readLog("combined.log"), `" 200 5 - "test/1.0"`+"\n"))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		l, err := newAccessLog(&Options{AccessLog: filepath.Join(dir, "json.log"), AccessLogFormat: AccessLogJSON})
+		require.NoError(t, err)
+		doRequest(t, l, "/file.txt?x=1")
+		require.NoError(t, l.Close())
+		var entry accessLogEntry
+		require.NoError(t, json.Unmarshal([]byte(readLog("json.log")), &entry))
+		assert.Equal(t, "192.0.2.1", entry.RemoteAddr)
+		assert.Equal(t, "frank", entry.User)
+		assert.Equal(t, "/file.txt?x=1", entry.URI)
+		assert.Equal(t, 200, entry.Status)
+		assert.Equal(t, int64(5), entry.Size)
+		assert.Equal(t, "test/1.0", entry.UserAgent)
+	})
+
+	t.Run("BadFormat", func(t *testing.T) {
+		_, err := newAccessLog(&Options{AccessLog: filepath.Join(dir, "bad.log"), AccessLogFormat: "potato"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		l, err := newAccessLog(&Options{AccessLog: filepath.Join(dir, "rotate.log"), AccessLogMaxSize: 100, AccessLogMaxBackups: 2})
+		require.NoError(t, err)
+		for i := 0; i < 5; i++ {
+			doRequest(t, l, "/file.txt")
+		}
+		require.NoError(t, l.Close())
+		for _, name := range []string{"rotate.log", "rotate.log.1", "rotate.log.2"} {
+			assert.Equal(t, 1, strings.Count(readLog(name), "\n"), name)
+		}
+		_, err = os.Stat(filepath.Join(dir, "rotate.log.3"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
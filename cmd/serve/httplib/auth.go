@@ -0,0 +1,196 @@
+package httplib
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"strings"
+
+	auth "github.com/abbot/go-http-auth"
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+type contextUserType struct{}
+
+// ContextUserKey is a simple context key for storing the username of the request
+var ContextUserKey = &contextUserType{}
+
+type contextAuthType struct{}
+
+// ContextAuthKey is a simple context key for storing info returned by AuthFn
+var ContextAuthKey = &contextAuthType{}
+
+// singleUserProvider provides the encrypted password for a single user
+func (s *Server) singleUserProvider(user, realm string) string {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	if user == s.Opt.BasicUser {
+		return s.basicPassHashed
+	}
+	return ""
+}
+
+// hashBasicPass hashes the password for singleUserProvider
+func hashBasicPass(pass string) string {
+	return string(auth.MD5Crypt([]byte(pass), []byte("dlPL2MqE"), []byte("$1$")))
+}
+
+// SetBasicAuth changes the user and password of a running server.
+//
+// This can only be used if the server was started with --user and
+// --pass, not with --htpasswd (edit the htpasswd file instead which is
+// re-read when it changes) or a custom Auth.
+func (s *Server) SetBasicAuth(user, pass string) error {
+	s.authMu.Lock()
+	singleUser := s.basicPassHashed != ""
+	s.authMu.Unlock()
+	if !singleUser {
+		return errors.New("can only change the user and password if the server was started with --user and --pass")
+	}
+	if user == "" || pass == "" {
+		return errors.New("user and password must not be empty")
+	}
+	hashed := hashBasicPass(pass)
+	s.authMu.Lock()
+	s.Opt.BasicUser, s.Opt.BasicPass = user, pass
+	s.basicPassHashed = hashed
+	s.authMu.Unlock()
+	fs.Infof(nil, "Changed authenticated user to %s", user)
+	return nil
+}
+
+// parseAuthorization parses the Authorization header into user, pass
+// it returns a boolean as to whether the parse was successful
+func parseAuthorization(r *http.Request) (user, pass string, ok bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader != "" {
+		s := strings.SplitN(authHeader, " ", 2)
+		if len(s) == 2 && s[0] == "Basic" {
+			b, err := base64.StdEncoding.DecodeString(s[1])
+			if err == nil {
+				parts := strings.SplitN(string(b), ":", 2)
+				user = parts[0]
+				if len(parts) > 1 {
+					pass = parts[1]
+					ok = true
+				}
+			}
+		}
+	}
+	return
+}
+
+// bearerToken returns the token from an "Authorization: Bearer"
+// header if there is one
+func bearerToken(r *http.Request) (token string, ok bool) {
+	s := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(s) == 2 && strings.EqualFold(s[0], "Bearer") {
+		return strings.TrimSpace(s[1]), true
+	}
+	return "", false
+}
+
+// authHandler wraps handler with the authentication configured in
+// s.Opt, if any, returning the new handler.
+//
+// Basic authentication is done with an htpasswd file, a single user
+// or a custom AuthFn, and bearer tokens are checked against a JWKS.
+func (s *Server) authHandler(handler http.Handler) http.Handler {
+	useBasic := s.Opt.HtPasswd != "" || s.Opt.BasicUser != "" || s.Opt.Auth != nil
+	var jwks *jwksKeys
+	if s.Opt.BearerJWKS != "" {
+		if s.Opt.Auth != nil {
+			log.Fatalf("Failed to start server: bearer token authentication can't be used with a custom authenticator such as --auth-proxy")
+		}
+		if s.Opt.BearerAudience == "" {
+			log.Fatalf("Failed to start server: --auth-bearer-audience must be set to use --auth-bearer-jwks")
+		}
+		fs.Infof(nil, "Checking bearer tokens with the keys from %q", s.Opt.BearerJWKS)
+		var err error
+		// fshttp can't be used here as it imports this package
+		jwks, err = newJWKSKeys(s.Opt.BearerJWKS, &http.Client{Timeout: jwksTimeout})
+		if err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}
+	if !useBasic && jwks == nil {
+		return handler
+	}
+	var authenticator *auth.BasicAuth
+	if useBasic && s.Opt.Auth == nil {
+		var secretProvider auth.SecretProvider
+		if s.Opt.HtPasswd != "" {
+			fs.Infof(nil, "Using %q as htpasswd storage", s.Opt.HtPasswd)
+			htpasswd, err := newHtpasswdFile(s.Opt.HtPasswd)
+			if err != nil {
+				log.Fatalf("Failed to start server: %v", err)
+			}
+			secretProvider = htpasswd.secretProvider
+		} else {
+			fs.Infof(nil, "Using --user %s --pass XXXX as authenticated user", s.Opt.BasicUser)
+			s.basicPassHashed = hashBasicPass(s.Opt.BasicPass)
+			secretProvider = s.singleUserProvider
+		}
+		authenticator = auth.NewBasicAuthenticator(s.Opt.Realm, secretProvider)
+	}
+	s.usingAuth = true
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// No auth wanted for OPTIONS method
+		if r.Method == "OPTIONS" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		unauthorized := func() {
+			w.Header().Set("Content-Type", "text/plain")
+			if useBasic {
+				w.Header().Add("WWW-Authenticate", `Basic realm="`+s.Opt.Realm+`"`)
+			}
+			if jwks != nil {
+				w.Header().Add("WWW-Authenticate", `Bearer realm="`+s.Opt.Realm+`"`)
+			}
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		}
+		if token, isBearer := bearerToken(r); isBearer && jwks != nil {
+			user, err := jwks.validate(token, s.Opt.BearerAudience, s.Opt.BearerIssuer)
+			if err != nil {
+				fs.Infof(r.URL.Path, "%s: Unauthorized bearer token: %v", r.RemoteAddr, err)
+				unauthorized()
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), ContextUserKey, user))
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if !useBasic {
+			unauthorized()
+			return
+		}
+		user, pass, authValid := parseAuthorization(r)
+		if !authValid {
+			unauthorized()
+			return
+		}
+		if s.Opt.Auth == nil {
+			if username := authenticator.CheckAuth(r); username == "" {
+				fs.Infof(r.URL.Path, "%s: Unauthorized request from %s", r.RemoteAddr, user)
+				unauthorized()
+				return
+			}
+		} else {
+			// Custom Auth
+			value, err := s.Opt.Auth(user, pass)
+			if err != nil {
+				fs.Infof(r.URL.Path, "%s: Auth failed from %s: %v", r.RemoteAddr, user, err)
+				unauthorized()
+				return
+			}
+			if value != nil {
+				r = r.WithContext(context.WithValue(r.Context(), ContextAuthKey, value))
+			}
+		}
+		r = r.WithContext(context.WithValue(r.Context(), ContextUserKey, user))
+		handler.ServeHTTP(w, r)
+	})
+}
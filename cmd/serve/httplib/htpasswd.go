@@ -0,0 +1,114 @@
+package httplib
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// htpasswdFile is an htpasswd file which is re-read when it changes.
+//
+// Unlike auth.HtpasswdFileProvider this doesn't panic if the file
+// can't be read or parsed when it is reloaded - it logs an error and
+// carries on with the users it had.
+type htpasswdFile struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time         // modification time of the file when read
+	size    int64             // size of the file when read
+	users   map[string]string // user to hashed password
+}
+
+// newHtpasswdFile reads the htpasswd file at path
+func newHtpasswdFile(path string) (*htpasswdFile, error) {
+	h := &htpasswdFile{path: path}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read htpasswd file")
+	}
+	err = h.load(fi)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// knownHash returns true if hash is in a format auth.CheckSecret knows
+func knownHash(hash string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2x$", "$2y$", "$apr1$", "$1$", "{SHA}"} {
+		if strings.HasPrefix(hash, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads the file which has info fi - call with the lock held
+func (h *htpasswdFile) load(fi os.FileInfo) (err error) {
+	in, err := os.Open(h.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read htpasswd file")
+	}
+	defer fs.CheckClose(in, &err)
+	users := make(map[string]string)
+	scanner := bufio.NewScanner(in)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexRune(line, ':')
+		if i <= 0 {
+			return errors.Errorf("htpasswd file %q line %d: expecting user:password", h.path, lineNumber)
+		}
+		user, hash := line[:i], line[i+1:]
+		if !knownHash(hash) {
+			fs.Errorf(nil, "htpasswd file %q: ignoring user %q as the password isn't hashed with bcrypt, MD5 or SHA1", h.path, user)
+			continue
+		}
+		users[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read htpasswd file")
+	}
+	h.users = users
+	h.modTime = fi.ModTime()
+	h.size = fi.Size()
+	fs.Debugf(nil, "Read %d users from htpasswd file %q", len(users), h.path)
+	return nil
+}
+
+// reloadIfChanged re-reads the file if it has changed - call with
+// the lock held
+func (h *htpasswdFile) reloadIfChanged() {
+	fi, err := os.Stat(h.path)
+	if err != nil {
+		fs.Errorf(nil, "Failed to check htpasswd file for changes: %v", err)
+		return
+	}
+	if fi.ModTime().Equal(h.modTime) && fi.Size() == h.size {
+		return
+	}
+	err = h.load(fi)
+	if err != nil {
+		fs.Errorf(nil, "Failed to reload htpasswd file - using previous users: %v", err)
+		return
+	}
+	fs.Infof(nil, "Reloaded htpasswd file %q", h.path)
+}
+
+// secretProvider returns the hashed password for user for
+// auth.NewBasicAuthenticator
+func (h *htpasswdFile) secretProvider(user, realm string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reloadIfChanged()
+	return h.users[user]
+}
@@ -0,0 +1,72 @@
+package httplib
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	auth "github.com/abbot/go-http-auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHtpasswdFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-htpasswd")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	path := filepath.Join(dir, "htpasswd")
+
+	_, err = newHtpasswdFile(path)
+	assert.Error(t, err)
+
+	hash := func(pass string) string {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
+		require.NoError(t, err)
+		return string(hashed)
+	}
+	write := func(contents string, modTime time.Time) {
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	check := func(h *htpasswdFile, user, pass string, want bool) {
+		r, err := http.NewRequest("GET", "http://example.com/", nil)
+		require.NoError(t, err)
+		r.SetBasicAuth(user, pass)
+		got := auth.NewBasicAuthenticator("rclone", h.secretProvider).CheckAuth(r) == user
+		assert.Equal(t, want, got, "%s:%s", user, pass)
+	}
+
+	t0 := time.Now().Add(-time.Hour)
+	write("# comment\n\nuser1:"+hash("pass1")+"\nuser2:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=\nplain:plaintext\n", t0)
+	h, err := newHtpasswdFile(path)
+	require.NoError(t, err)
+	check(h, "user1", "pass1", true)
+	check(h, "user1", "wrong", false)
+	check(h, "user2", "password", true)
+	check(h, "plain", "plaintext", false)
+	check(h, "nobody", "", false)
+
+	// Changes are picked up
+	write("user1:"+hash("new1")+"\nuser3:"+hash("pass3")+"\n", t0.Add(time.Minute))
+	check(h, "user1", "pass1", false)
+	check(h, "user1", "new1", true)
+	check(h, "user2", "password", false)
+	check(h, "user3", "pass3", true)
+
+	// A broken file keeps the old users
+	write("this is not an htpasswd file\n", t0.Add(2*time.Minute))
+	check(h, "user3", "pass3", true)
+	require.NoError(t, os.Remove(path))
+	check(h, "user3", "pass3", true)
+
+	// Bad file at the start is an error
+	write("broken\n", t0)
+	_, err = newHtpasswdFile(path)
+	assert.Error(t, err)
+}
@@ -23,11 +23,19 @@ func AddFlagsPrefix(flagSet *pflag.FlagSet, prefix string, Opt *httplib.Options)
 	flags.StringVarP(flagSet, &Opt.SslKey, prefix+"key", "", Opt.SslKey, "SSL PEM Private key")
 	flags.StringVarP(flagSet, &Opt.ClientCA, prefix+"client-ca", "", Opt.ClientCA, "Client certificate authority to verify clients with")
 	flags.StringVarP(flagSet, &Opt.HtPasswd, prefix+"htpasswd", "", Opt.HtPasswd, "htpasswd file - if not provided no authentication is done")
+	flags.StringVarP(flagSet, &Opt.HtPasswd, prefix+"auth-basic-file", "", Opt.HtPasswd, "htpasswd file for basic authentication - same as --"+prefix+"htpasswd")
+	flags.StringVarP(flagSet, &Opt.BearerJWKS, prefix+"auth-bearer-jwks", "", Opt.BearerJWKS, "URL of a JWKS to check JWT bearer tokens with")
+	flags.StringVarP(flagSet, &Opt.BearerAudience, prefix+"auth-bearer-audience", "", Opt.BearerAudience, "Audience JWT bearer tokens must be issued for")
+	flags.StringVarP(flagSet, &Opt.BearerIssuer, prefix+"auth-bearer-issuer", "", Opt.BearerIssuer, "If set JWT bearer tokens must be issued by this issuer")
 	flags.StringVarP(flagSet, &Opt.Realm, prefix+"realm", "", Opt.Realm, "realm for authentication")
 	flags.StringVarP(flagSet, &Opt.BasicUser, prefix+"user", "", Opt.BasicUser, "User name for authentication.")
 	flags.StringVarP(flagSet, &Opt.BasicPass, prefix+"pass", "", Opt.BasicPass, "Password for authentication.")
 	flags.StringVarP(flagSet, &Opt.BaseURL, prefix+"baseurl", "", Opt.BaseURL, "Prefix for URLs - leave blank for root.")
 	flags.StringVarP(flagSet, &Opt.Template, prefix+"template", "", Opt.Template, "User Specified Template.")
+	flags.StringVarP(flagSet, &Opt.AccessLog, prefix+"access-log", "", Opt.AccessLog, "Write an access log of requests to this file.")
+	flags.StringVarP(flagSet, &Opt.AccessLogFormat, prefix+"access-log-format", "", Opt.AccessLogFormat, "Format of the access log: common, combined or json.")
+	flags.FVarP(flagSet, &Opt.AccessLogMaxSize, prefix+"access-log-max-size", "", "Rotate the access log when it reaches this size.")
+	flags.IntVarP(flagSet, &Opt.AccessLogMaxBackups, prefix+"access-log-max-backups", "", Opt.AccessLogMaxBackups, "Number of rotated access logs to keep.")
 
 }
 
@@ -2,10 +2,8 @@
 package httplib
 
 import (
-	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/base64"
 	"fmt"
 	"html/template"
 	"io/ioutil"
@@ -13,9 +11,9 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
-	auth "github.com/abbot/go-http-auth"
 	"github.com/artpar/rclone/cmd/serve/httplib/serve/data"
 	"github.com/artpar/rclone/fs"
 	"github.com/pkg/errors"
@@ -75,6 +73,46 @@ to be used within the template to server pages:
 |-- .Size     | Size in Bytes of the entry. |
 |-- .ModTime  | The UTC timestamp of an entry. |
 
+The template is a Go [html/template](https://golang.org/pkg/html/template/)
+so it can contain any markup and scripts needed for a custom file
+browser.
+
+Directory listings can also be fetched as JSON by adding ?format=json
+to the URL of a directory, e.g. /path/to/dir/?format=json, which is
+convenient for file browsers written in Javascript. The ?sort= and
+?order= parameters work as for the HTML listing. This returns
+
+    {
+      "name": "/path/to/dir",
+      "entries": [
+        {
+          "name": "file.txt",
+          "path": "path/to/dir/file.txt",
+          "url": "file.txt",
+          "isDir": false,
+          "size": 1234,
+          "modTime": "2021-02-03T04:05:06Z"
+        }
+      ]
+    }
+
+where "modTime" is left out if modification times aren't in use.
+
+#### Access logs
+
+Use --access-log /path/to/access.log to write a line to that file for
+each request, including ones which fail authentication, so the traffic
+can be analysed by tools such as fail2ban or log analysers.
+
+--access-log-format sets the format of the lines.  This can be
+"common" for the Common Log Format (the default), "combined" for the
+Combined Log Format which adds the referer and user agent, or "json"
+for one JSON object per line.
+
+If --access-log-max-size is set then the log will be rotated when it
+reaches that size.  The old logs are renamed with a .1, .2, etc suffix
+and --access-log-max-backups of them are kept (default 3).
+
 #### Authentication
 
 By default this will serve files without needing a login.
@@ -82,9 +120,10 @@ By default this will serve files without needing a login.
 You can either use an htpasswd file which can take lots of users, or
 set a single username and password with the --user and --pass flags.
 
-Use --htpasswd /path/to/htpasswd to provide an htpasswd file.  This is
-in standard apache format and supports MD5, SHA1 and BCrypt for basic
-authentication.  Bcrypt is recommended.
+Use --htpasswd /path/to/htpasswd (or its alias --auth-basic-file) to
+provide an htpasswd file.  This is in standard apache format and
+supports MD5, SHA1 and BCrypt for basic authentication.  Bcrypt is
+recommended.
 
 To create an htpasswd file:
 
@@ -92,7 +131,32 @@ To create an htpasswd file:
     htpasswd -B htpasswd user
     htpasswd -B htpasswd anotherUser
 
-The password file can be updated while rclone is running.
+The password file can be updated while rclone is running - it is
+re-read when it changes, so users can be added, removed or have their
+passwords changed without restarting.  If the file can't be read when
+it has changed, rclone logs an error and carries on with the users it
+had.  Lines with passwords which aren't hashed with one of the formats
+above are ignored.
+
+Use --auth-bearer-jwks URL to accept JSON Web Tokens (JWT) sent as
+"Authorization: Bearer <token>".  The URL should point to the JSON Web
+Key Set (JWKS) of the identity provider which issues the tokens, for
+example "https://example.com/.well-known/jwks.json", and must use
+https.  Tokens signed
+with RSA (RS256, RS384, RS512, PS256, PS384, PS512) or ECDSA (ES256,
+ES384, ES512) keys are supported.  A token must have an expiry time
+("exp") and a subject ("sub") which is used as the user name.  The key
+set is re-read every hour or when a token signed with an unknown key
+arrives.
+
+Use --auth-bearer-audience to set the audience ("aud") the tokens must
+be issued for.  This is required when using --auth-bearer-jwks so
+tokens the identity provider issues for other services aren't
+accepted.  Use --auth-bearer-issuer to only accept tokens issued
+("iss") by that issuer.
+
+Bearer tokens can be used on their own or together with basic
+authentication in which case clients may use either.
 
 Use --realm to set the authentication realm.
 
@@ -111,20 +175,27 @@ certificate authority certificate.
 
 // Options contains options for the http Server
 type Options struct {
-	ListenAddr         string        // Port to listen on
-	BaseURL            string        // prefix to strip from URLs
-	ServerReadTimeout  time.Duration // Timeout for server reading data
-	ServerWriteTimeout time.Duration // Timeout for server writing data
-	MaxHeaderBytes     int           // Maximum size of request header
-	SslCert            string        // SSL PEM key (concatenation of certificate and CA certificate)
-	SslKey             string        // SSL PEM Private key
-	ClientCA           string        // Client certificate authority to verify clients with
-	HtPasswd           string        // htpasswd file - if not provided no authentication is done
-	Realm              string        // realm for authentication
-	BasicUser          string        // single username for basic auth if not using Htpasswd
-	BasicPass          string        // password for BasicUser
-	Auth               AuthFn        `json:"-"` // custom Auth (not set by command line flags)
-	Template           string        // User specified template
+	ListenAddr          string        // Port to listen on
+	BaseURL             string        // prefix to strip from URLs
+	ServerReadTimeout   time.Duration // Timeout for server reading data
+	ServerWriteTimeout  time.Duration // Timeout for server writing data
+	MaxHeaderBytes      int           // Maximum size of request header
+	SslCert             string        // SSL PEM key (concatenation of certificate and CA certificate)
+	SslKey              string        // SSL PEM Private key
+	ClientCA            string        // Client certificate authority to verify clients with
+	HtPasswd            string        // htpasswd file - if not provided no authentication is done
+	Realm               string        // realm for authentication
+	BasicUser           string        // single username for basic auth if not using Htpasswd
+	BasicPass           string        // password for BasicUser
+	Auth                AuthFn        `json:"-"` // custom Auth (not set by command line flags)
+	BearerJWKS          string        // URL of a JWKS to check bearer tokens with if set
+	BearerAudience      string        // bearer tokens must be issued for this audience
+	BearerIssuer        string        // if set bearer tokens must be issued by this issuer
+	Template            string        // User specified template
+	AccessLog           string        // file to write an access log to if set
+	AccessLogFormat     string        // format of the access log: common, combined or json
+	AccessLogMaxSize    fs.SizeSuffix // rotate the access log when it reaches this size if set
+	AccessLogMaxBackups int           // number of rotated access logs to keep
 }
 
 // AuthFn if used will be used to authenticate user, pass. If an error
@@ -135,11 +206,13 @@ type AuthFn func(user, pass string) (value interface{}, err error)
 
 // DefaultOpt is the default values used for Options
 var DefaultOpt = Options{
-	ListenAddr:         "localhost:8080",
-	Realm:              "rclone",
-	ServerReadTimeout:  1 * time.Hour,
-	ServerWriteTimeout: 1 * time.Hour,
-	MaxHeaderBytes:     4096,
+	ListenAddr:          "localhost:8080",
+	Realm:               "rclone",
+	ServerReadTimeout:   1 * time.Hour,
+	ServerWriteTimeout:  1 * time.Hour,
+	MaxHeaderBytes:      4096,
+	AccessLogFormat:     AccessLogCommon,
+	AccessLogMaxBackups: 3,
 }
 
 // Server contains info about the running http server
@@ -149,49 +222,12 @@ type Server struct {
 	listener        net.Listener
 	waitChan        chan struct{} // for waiting on the listener to close
 	httpServer      *http.Server
+	authMu          sync.Mutex // protects Opt.BasicUser and basicPassHashed after start
 	basicPassHashed string
 	useSSL          bool               // if server is configured for SSL/TLS
 	usingAuth       bool               // set if authentication is configured
 	HTMLTemplate    *template.Template // HTML template for web interface
-}
-
-type contextUserType struct{}
-
-// ContextUserKey is a simple context key for storing the username of the request
-var ContextUserKey = &contextUserType{}
-
-type contextAuthType struct{}
-
-// ContextAuthKey is a simple context key for storing info returned by AuthFn
-var ContextAuthKey = &contextAuthType{}
-
-// singleUserProvider provides the encrypted password for a single user
-func (s *Server) singleUserProvider(user, realm string) string {
-	if user == s.Opt.BasicUser {
-		return s.basicPassHashed
-	}
-	return ""
-}
-
-// parseAuthorization parses the Authorization header into user, pass
-// it returns a boolean as to whether the parse was successful
-func parseAuthorization(r *http.Request) (user, pass string, ok bool) {
-	authHeader := r.Header.Get("Authorization")
-	if authHeader != "" {
-		s := strings.SplitN(authHeader, " ", 2)
-		if len(s) == 2 && s[0] == "Basic" {
-			b, err := base64.StdEncoding.DecodeString(s[1])
-			if err == nil {
-				parts := strings.SplitN(string(b), ":", 2)
-				user = parts[0]
-				if len(parts) > 1 {
-					pass = parts[1]
-					ok = true
-				}
-			}
-		}
-	}
-	return
+	accessLog       *accessLog         // access log if configured
 }
 
 // NewServer creates an http server.  The opt can be nil in which case
@@ -208,60 +244,17 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 		s.Opt = DefaultOpt
 	}
 
-	// Use htpasswd if required on everything
-	if s.Opt.HtPasswd != "" || s.Opt.BasicUser != "" || s.Opt.Auth != nil {
-		var authenticator *auth.BasicAuth
-		if s.Opt.Auth == nil {
-			var secretProvider auth.SecretProvider
-			if s.Opt.HtPasswd != "" {
-				fs.Infof(nil, "Using %q as htpasswd storage", s.Opt.HtPasswd)
-				secretProvider = auth.HtpasswdFileProvider(s.Opt.HtPasswd)
-			} else {
-				fs.Infof(nil, "Using --user %s --pass XXXX as authenticated user", s.Opt.BasicUser)
-				s.basicPassHashed = string(auth.MD5Crypt([]byte(s.Opt.BasicPass), []byte("dlPL2MqE"), []byte("$1$")))
-				secretProvider = s.singleUserProvider
-			}
-			authenticator = auth.NewBasicAuthenticator(s.Opt.Realm, secretProvider)
+	// Add authentication if required
+	handler = s.authHandler(handler)
+
+	// Log all requests, including unauthorized ones, if required
+	if s.Opt.AccessLog != "" {
+		var err error
+		s.accessLog, err = newAccessLog(&s.Opt)
+		if err != nil {
+			log.Fatalf("Failed to start access log: %v", err)
 		}
-		oldHandler := handler
-		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// No auth wanted for OPTIONS method
-			if r.Method == "OPTIONS" {
-				oldHandler.ServeHTTP(w, r)
-				return
-			}
-			unauthorized := func() {
-				w.Header().Set("Content-Type", "text/plain")
-				w.Header().Set("WWW-Authenticate", `Basic realm="`+s.Opt.Realm+`"`)
-				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-			}
-			user, pass, authValid := parseAuthorization(r)
-			if !authValid {
-				unauthorized()
-				return
-			}
-			if s.Opt.Auth == nil {
-				if username := authenticator.CheckAuth(r); username == "" {
-					fs.Infof(r.URL.Path, "%s: Unauthorized request from %s", r.RemoteAddr, user)
-					unauthorized()
-					return
-				}
-			} else {
-				// Custom Auth
-				value, err := s.Opt.Auth(user, pass)
-				if err != nil {
-					fs.Infof(r.URL.Path, "%s: Auth failed from %s: %v", r.RemoteAddr, user, err)
-					unauthorized()
-					return
-				}
-				if value != nil {
-					r = r.WithContext(context.WithValue(r.Context(), ContextAuthKey, value))
-				}
-			}
-			r = r.WithContext(context.WithValue(r.Context(), ContextUserKey, user))
-			oldHandler.ServeHTTP(w, r)
-		})
-		s.usingAuth = true
+		handler = s.accessLog.handler(handler)
 	}
 
 	s.useSSL = s.Opt.SslKey != ""
@@ -318,9 +311,13 @@ func NewServer(handler http.Handler, opt *Options) *Server {
 // the listener was not started; does not block, so
 // use s.Wait() to block on the listener indefinitely.
 func (s *Server) Serve() error {
-	ln, err := net.Listen("tcp", s.httpServer.Addr)
-	if err != nil {
-		return errors.Wrapf(err, "start server failed")
+	ln := s.listener
+	if ln == nil {
+		var err error
+		ln, err = net.Listen("tcp", s.httpServer.Addr)
+		if err != nil {
+			return errors.Wrapf(err, "start server failed")
+		}
 	}
 	s.listener = ln
 	s.waitChan = make(chan struct{})
@@ -357,6 +354,13 @@ func (s *Server) Serve() error {
 	return nil
 }
 
+// UseListener makes Serve use ln, which must already be listening,
+// instead of listening on ListenAddr. It must be called before Serve.
+func (s *Server) UseListener(ln net.Listener) {
+	s.listener = ln
+	s.Opt.ListenAddr = ln.Addr().String()
+}
+
 // Wait blocks while the listener is open.
 func (s *Server) Wait() {
 	<-s.waitChan
@@ -369,6 +373,12 @@ func (s *Server) Close() {
 		log.Printf("Error on closing HTTP server: %v", err)
 		return
 	}
+	if s.accessLog != nil {
+		err = s.accessLog.Close()
+		if err != nil {
+			log.Printf("Error on closing access log: %v", err)
+		}
+	}
 	close(s.waitChan)
 }
 
@@ -0,0 +1,331 @@
+package httplib
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+const (
+	jwksRefresh    = time.Hour        // re-read the JWKS this often
+	jwksMinRefresh = time.Minute      // but no more often than this on an unknown key id
+	jwtLeeway      = 60 * time.Second // allowed clock skew when checking exp and nbf
+	jwksTimeout    = time.Minute      // timeout reading the JWKS
+)
+
+// jwksKeys are the public keys from a JSON Web Key Set (RFC 7517)
+// used to check the signatures of JSON Web Tokens (RFC 7519).
+type jwksKeys struct {
+	url     string
+	client  *http.Client
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey // keys indexed by key id
+	fetched time.Time                   // when the keys were last read
+}
+
+// newJWKSKeys reads the key set from jwksURL using client.
+//
+// jwksURL must be an https:// URL as the keys read from it are
+// trusted to sign tokens.
+func newJWKSKeys(jwksURL string, client *http.Client) (*jwksKeys, error) {
+	u, err := url.Parse(jwksURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad JWKS URL")
+	}
+	if u.Scheme != "https" {
+		return nil, errors.Errorf("JWKS URL %q must use https://", jwksURL)
+	}
+	j := &jwksKeys{
+		url:    jwksURL,
+		client: client,
+	}
+	j.fetched = time.Now()
+	j.keys, err = j.fetch()
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// jwk is a single JSON Web Key - only the fields we use
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// decodeBigInt decodes a base64url encoded big endian integer
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// publicKey returns the public key in k
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad RSA modulus")
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad RSA exponent")
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad EC x coordinate")
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad EC y coordinate")
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, errors.Errorf("unsupported key type %q", k.Kty)
+}
+
+// fetch reads the key set and returns the usable keys in it
+//
+// This doesn't use j.keys or j.fetched so should be called without
+// the lock held.
+func (j *jwksKeys) fetch() (keys map[string]crypto.PublicKey, err error) {
+	resp, err := j.client.Get(j.url)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read JWKS")
+	}
+	defer fs.CheckClose(resp.Body, &err)
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to read JWKS: HTTP status %s", resp.Status)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode JWKS")
+	}
+	keys = make(map[string]crypto.PublicKey, len(set.Keys))
+	for i := range set.Keys {
+		k := &set.Keys[i]
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			fs.Debugf(nil, "JWKS: ignoring key %q: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.Errorf("no usable signing keys found in JWKS %q", j.url)
+	}
+	return keys, nil
+}
+
+// getKey returns the key with id kid re-reading the key set if it
+// is out of date or the key isn't found.
+//
+// The key set is read without the lock held so a slow identity
+// provider doesn't hold up requests using keys we already have.
+func (j *jwksKeys) getKey(kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	key, found := j.keys[kid]
+	sinceFetch := time.Since(j.fetched)
+	refresh := (!found && sinceFetch > jwksMinRefresh) || sinceFetch > jwksRefresh
+	if refresh {
+		// mark the keys as fetched now so only one request re-reads them
+		j.fetched = time.Now()
+	}
+	j.mu.Unlock()
+	if refresh {
+		keys, err := j.fetch()
+		j.mu.Lock()
+		if err != nil {
+			fs.Errorf(nil, "Failed to refresh JWKS - using previous keys: %v", err)
+		} else {
+			j.keys = keys
+		}
+		key, found = j.keys[kid]
+		j.mu.Unlock()
+	}
+	if !found {
+		return nil, errors.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// verifySignature checks sig is the signature of signed with key
+// using the JWS algorithm alg
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256", "PS256":
+		hash = crypto.SHA256
+	case "RS384", "ES384", "PS384":
+		hash = crypto.SHA384
+	case "RS512", "ES512", "PS512":
+		hash = crypto.SHA512
+	default:
+		return errors.Errorf("unsupported signing algorithm %q", alg)
+	}
+	h := hash.New()
+	_, _ = h.Write(signed)
+	digest := h.Sum(nil)
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[0] {
+		case 'R':
+			return rsa.VerifyPKCS1v15(key, hash, digest, sig)
+		case 'P':
+			return rsa.VerifyPSS(key, hash, digest, sig, nil)
+		}
+	case *ecdsa.PublicKey:
+		if alg[0] != 'E' {
+			break
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("bad ECDSA signature length")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("bad ECDSA signature")
+		}
+		return nil
+	}
+	return errors.Errorf("signing algorithm %q doesn't match the key type", alg)
+}
+
+// jwtClaims are the registered claims of a JWT which are checked
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+}
+
+// hasAudience returns true if the aud claim is or contains audience
+func (c *jwtClaims) hasAudience(audience string) bool {
+	var one string
+	if json.Unmarshal(c.Audience, &one) == nil {
+		return one == audience
+	}
+	var many []string
+	if json.Unmarshal(c.Audience, &many) == nil {
+		for _, aud := range many {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validate checks the signature and claims of the JWT in token
+// returning the subject as the user name.
+//
+// The token must be issued for audience. If issuer is set then the
+// token must be issued by it.
+func (j *jwksKeys) validate(token, audience, issuer string) (user string, err error) {
+	if audience == "" {
+		return "", errors.New("no audience to check token against")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", errors.Wrap(err, "malformed token header")
+	}
+	if err = json.Unmarshal(headerJSON, &header); err != nil {
+		return "", errors.Wrap(err, "malformed token header")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.Wrap(err, "malformed token signature")
+	}
+	key, err := j.getKey(header.Kid)
+	if err != nil {
+		return "", err
+	}
+	err = verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig)
+	if err != nil {
+		return "", errors.Wrap(err, "bad token signature")
+	}
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, "malformed token claims")
+	}
+	var claims jwtClaims
+	if err = json.Unmarshal(claimsJSON, &claims); err != nil {
+		return "", errors.Wrap(err, "malformed token claims")
+	}
+	now := time.Now()
+	if claims.ExpiresAt == nil {
+		return "", errors.New("token has no expiry time")
+	}
+	if now.After(time.Unix(int64(*claims.ExpiresAt), 0).Add(jwtLeeway)) {
+		return "", errors.New("token has expired")
+	}
+	if claims.NotBefore != nil && now.Add(jwtLeeway).Before(time.Unix(int64(*claims.NotBefore), 0)) {
+		return "", errors.New("token not valid yet")
+	}
+	if !claims.hasAudience(audience) {
+		return "", errors.Errorf("token not issued for audience %q", audience)
+	}
+	if issuer != "" && claims.Issuer != issuer {
+		return "", errors.Errorf("token not issued by %q", issuer)
+	}
+	if claims.Subject == "" {
+		return "", errors.New("token has no subject")
+	}
+	return claims.Subject, nil
+}
@@ -0,0 +1,304 @@
+package httplib
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// b64 base64url encodes b without padding
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// makeToken makes a JWT with header and claims signed by sign
+func makeToken(t *testing.T, header, claims map[string]interface{}, sign func(digest []byte) []byte) string {
+	headerJSON, err := json.Marshal(header)
+	require.NoError(t, err)
+	claimsJSON, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(headerJSON) + "." + b64(claimsJSON)
+	digest := sha256.Sum256([]byte(signed))
+	return signed + "." + b64(sign(digest[:]))
+}
+
+func TestJWKSValidate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwksJSON, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa1",
+				"use": "sig",
+				"n":   b64(rsaKey.N.Bytes()),
+				"e":   b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec1",
+				"crv": "P-256",
+				"x":   b64(ecKey.X.Bytes()),
+				"y":   b64(ecKey.Y.Bytes()),
+			},
+			{
+				"kty": "oct",
+				"kid": "ignored",
+			},
+		},
+	})
+	require.NoError(t, err)
+	fetches := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_, _ = w.Write(jwksJSON)
+	}))
+	defer ts.Close()
+
+	_, err = newJWKSKeys("http"+strings.TrimPrefix(ts.URL, "https"), ts.Client())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "https")
+	assert.Equal(t, 0, fetches)
+
+	jwks, err := newJWKSKeys(ts.URL, ts.Client())
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+	assert.Len(t, jwks.keys, 2)
+
+	signRSA := func(digest []byte) []byte {
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest)
+		require.NoError(t, err)
+		return sig
+	}
+	signEC := func(digest []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest)
+		require.NoError(t, err)
+		sig := make([]byte, 64)
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		copy(sig[32-len(rBytes):32], rBytes)
+		copy(sig[64-len(sBytes):], sBytes)
+		return sig
+	}
+	signBad := func(digest []byte) []byte {
+		return signRSA(append([]byte{0}, digest[1:]...))
+	}
+	now := time.Now().Unix()
+
+	for _, test := range []struct {
+		name    string
+		header  map[string]interface{}
+		claims  map[string]interface{}
+		sign    func([]byte) []byte
+		issuer  string
+		wantErr string
+	}{
+		{
+			name:   "RSA",
+			header: map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims: map[string]interface{}{"sub": "alice", "exp": now + 60, "aud": "rclone"},
+			sign:   signRSA,
+		},
+		{
+			name:   "ECDSA with audience list",
+			header: map[string]interface{}{"alg": "ES256", "kid": "ec1"},
+			claims: map[string]interface{}{"sub": "alice", "exp": now + 60, "aud": []string{"other", "rclone"}},
+			sign:   signEC,
+		},
+		{
+			name:    "wrong audience",
+			header:  map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims:  map[string]interface{}{"sub": "alice", "exp": now + 60, "aud": "other"},
+			sign:    signRSA,
+			wantErr: "audience",
+		},
+		{
+			name:    "no audience",
+			header:  map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims:  map[string]interface{}{"sub": "alice", "exp": now + 60},
+			sign:    signRSA,
+			wantErr: "audience",
+		},
+		{
+			name:   "issuer",
+			header: map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims: map[string]interface{}{"sub": "alice", "exp": now + 60, "aud": "rclone", "iss": "https://idp.example.com/"},
+			sign:   signRSA,
+			issuer: "https://idp.example.com/",
+		},
+		{
+			name:    "wrong issuer",
+			header:  map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims:  map[string]interface{}{"sub": "alice", "exp": now + 60, "aud": "rclone", "iss": "https://evil.example.com/"},
+			sign:    signRSA,
+			issuer:  "https://idp.example.com/",
+			wantErr: "issued by",
+		},
+		{
+			name:    "expired",
+			header:  map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims:  map[string]interface{}{"sub": "alice", "exp": now - 3600},
+			sign:    signRSA,
+			wantErr: "expired",
+		},
+		{
+			name:    "no expiry",
+			header:  map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims:  map[string]interface{}{"sub": "alice"},
+			sign:    signRSA,
+			wantErr: "expiry",
+		},
+		{
+			name:    "not valid yet",
+			header:  map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims:  map[string]interface{}{"sub": "alice", "exp": now + 7200, "nbf": now + 3600},
+			sign:    signRSA,
+			wantErr: "not valid yet",
+		},
+		{
+			name:    "no subject",
+			header:  map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims:  map[string]interface{}{"exp": now + 60, "aud": "rclone"},
+			sign:    signRSA,
+			wantErr: "subject",
+		},
+		{
+			name:    "bad signature",
+			header:  map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+			claims:  map[string]interface{}{"sub": "alice", "exp": now + 60},
+			sign:    signBad,
+			wantErr: "signature",
+		},
+		{
+			name:    "algorithm doesn't match key",
+			header:  map[string]interface{}{"alg": "ES256", "kid": "rsa1"},
+			claims:  map[string]interface{}{"sub": "alice", "exp": now + 60},
+			sign:    signRSA,
+			wantErr: "signature",
+		},
+		{
+			name:    "unsigned",
+			header:  map[string]interface{}{"alg": "none", "kid": "rsa1"},
+			claims:  map[string]interface{}{"sub": "alice", "exp": now + 60},
+			sign:    func([]byte) []byte { return nil },
+			wantErr: "unsupported signing algorithm",
+		},
+		{
+			name:    "unknown key",
+			header:  map[string]interface{}{"alg": "RS256", "kid": "potato"},
+			claims:  map[string]interface{}{"sub": "alice", "exp": now + 60},
+			sign:    signRSA,
+			wantErr: "unknown key id",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			user, err := jwks.validate(makeToken(t, test.header, test.claims, test.sign), "rclone", test.issuer)
+			if test.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.wantErr)
+				assert.Equal(t, "", user)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "alice", user)
+			}
+		})
+	}
+
+	_, err = jwks.validate("not.a-token", "rclone", "")
+	assert.Error(t, err)
+
+	// unknown keys only cause a re-read of the key set once it is
+	// older than jwksMinRefresh
+	assert.Equal(t, 1, fetches)
+	jwks.fetched = time.Now().Add(-2 * jwksMinRefresh)
+	_, err = jwks.getKey("potato")
+	assert.Error(t, err)
+	assert.Equal(t, 2, fetches)
+}
+
+func TestAuthHandlerBearer(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwksJSON, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "rsa1",
+			"n":   b64(rsaKey.N.Bytes()),
+			"e":   b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(jwksJSON)
+	}))
+	defer ts.Close()
+	// trust the test server's certificate
+	oldTransport := http.DefaultTransport
+	http.DefaultTransport = ts.Client().Transport
+	defer func() {
+		http.DefaultTransport = oldTransport
+	}()
+
+	opt := DefaultOpt
+	opt.BearerJWKS = ts.URL
+	opt.BearerAudience = "rclone"
+	opt.BasicUser = "bob"
+	opt.BasicPass = "secret"
+	s := &Server{Opt: opt}
+	handler := s.authHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := r.Context().Value(ContextUserKey).(string)
+		_, _ = w.Write([]byte(user))
+	}))
+	assert.True(t, s.usingAuth)
+
+	token := makeToken(t,
+		map[string]interface{}{"alg": "RS256", "kid": "rsa1"},
+		map[string]interface{}{"sub": "alice", "exp": time.Now().Unix() + 60, "aud": "rclone"},
+		func(digest []byte) []byte {
+			sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest)
+			require.NoError(t, err)
+			return sig
+		})
+
+	do := func(setAuth func(r *http.Request)) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		setAuth(r)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := do(func(r *http.Request) {})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, []string{`Basic realm="rclone"`, `Bearer realm="rclone"`}, w.Header()["Www-Authenticate"])
+
+	w = do(func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) })
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "alice", w.Body.String())
+
+	w = do(func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token+"x") })
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = do(func(r *http.Request) { r.SetBasicAuth("bob", "secret") })
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "bob", w.Body.String())
+
+	w = do(func(r *http.Request) { r.SetBasicAuth("bob", "wrong") })
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -2,6 +2,7 @@ package serve
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -229,6 +230,11 @@ func (d *Directory) Serve(w http.ResponseWriter, r *http.Request) {
 
 	fs.Infof(d.DirRemote, "%s: Serving directory", r.RemoteAddr)
 
+	if r.URL.Query().Get("format") == "json" {
+		d.serveJSON(w)
+		return
+	}
+
 	buf := &bytes.Buffer{}
 	err := d.HTMLTemplate.Execute(buf, d)
 	if err != nil {
@@ -240,3 +246,46 @@ func (d *Directory) Serve(w http.ResponseWriter, r *http.Request) {
 		Error(d.DirRemote, nil, "Failed to drain template buffer", err)
 	}
 }
+
+// jsonDirEntry is a directory entry as returned by ?format=json
+type jsonDirEntry struct {
+	Name    string     `json:"name"`
+	Path    string     `json:"path"`
+	URL     string     `json:"url"`
+	IsDir   bool       `json:"isDir"`
+	Size    int64      `json:"size"`
+	ModTime *time.Time `json:"modTime,omitempty"`
+}
+
+// jsonDirectory is a directory as returned by ?format=json
+type jsonDirectory struct {
+	Name    string         `json:"name"`
+	Entries []jsonDirEntry `json:"entries"`
+}
+
+// serveJSON serves the directory listing as JSON
+func (d *Directory) serveJSON(w http.ResponseWriter) {
+	out := jsonDirectory{
+		Name:    d.Name,
+		Entries: make([]jsonDirEntry, 0, len(d.Entries)),
+	}
+	for i := range d.Entries {
+		entry := &d.Entries[i]
+		jsonEntry := jsonDirEntry{
+			Name:  strings.TrimSuffix(entry.Leaf, "/"),
+			Path:  entry.remote,
+			URL:   entry.URL,
+			IsDir: entry.IsDir,
+			Size:  entry.Size,
+		}
+		if !entry.ModTime.IsZero() {
+			jsonEntry.ModTime = &entry.ModTime
+		}
+		out.Entries = append(out.Entries, jsonEntry)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(&out)
+	if err != nil {
+		Error(d.DirRemote, nil, "Failed to write JSON listing", err)
+	}
+}
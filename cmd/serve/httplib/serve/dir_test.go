@@ -122,3 +122,21 @@ func TestServe(t *testing.T) {
 </html>
 `, string(body))
 }
+
+func TestServeJSON(t *testing.T) {
+	d := NewDirectory("aDirectory", GetTemplate(t))
+	modTime := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+	d.AddHTMLEntry("aDirectory/file", false, 1234, modTime)
+	d.AddHTMLEntry("aDirectory/dir", true, 0, time.Time{})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/aDirectory/?format=json", nil)
+	d.Serve(w, r)
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, `{"name":"/aDirectory","entries":[`+
+		`{"name":"file","path":"aDirectory/file","url":"file","isDir":false,"size":1234,"modTime":"2021-02-03T04:05:06Z"},`+
+		`{"name":"dir","path":"aDirectory/dir","url":"dir/","isDir":true,"size":0}]}`+"\n", string(body))
+}
@@ -51,6 +51,13 @@ This config generated must have this extra parameter
 And it may have this parameter
 - |_obscure| - comma separated strings for parameters to obscure
 
+Alternatively the program can map the user onto a remote which is
+already in the config file by returning just
+- |_remote| - the remote and path to use, eg |files:users/me|
+
+in which case all the other parameters are ignored. This is a simple
+way of giving each user their own directory or remote.
+
 If password authentication was used by the client, input to the proxy
 process (on STDIN) would look similar to this:
 
@@ -200,30 +207,35 @@ func (p *Proxy) call(user, auth string, isPublicKey bool) (value interface{}, er
 		return nil, err
 	}
 
-	// Look for required fields in the answer
-	fsName, ok := config.Get("type")
-	if !ok {
-		return nil, errors.New("proxy: type not set in result")
-	}
-	root, ok := config.Get("_root")
-	if !ok {
-		return nil, errors.New("proxy: _root not set in result")
-	}
-
-	// Find the backend
-	fsInfo, err := fs.Find(fsName)
-	if err != nil {
-		return nil, errors.Wrapf(err, "proxy: couldn't find backend for %q", fsName)
-	}
+	var (
+		fsString string
+		newFs    func(ctx context.Context, fsString string) (fs.Fs, error)
+	)
+	if remote, ok := config.Get("_remote"); ok {
+		// Use an existing remote from the config file
+		fsString = remote
+		newFs = fs.NewFs
+	} else {
+		// Look for required fields in the answer
+		fsName, ok := config.Get("type")
+		if !ok {
+			return nil, errors.New("proxy: type not set in result")
+		}
+		root, ok := config.Get("_root")
+		if !ok {
+			return nil, errors.New("proxy: _root not set in result")
+		}
 
-	// base name of config on user name.  This may appear in logs
-	name := "proxy-" + user
-	fsString := name + ":" + root
+		// Find the backend
+		fsInfo, err := fs.Find(fsName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "proxy: couldn't find backend for %q", fsName)
+		}
 
-	// Look for fs in the VFS cache
-	value, err = p.vfsCache.Get(user, func(key string) (value interface{}, ok bool, err error) {
-		// Create the Fs from the cache
-		f, err := cache.GetFn(p.ctx, fsString, func(ctx context.Context, fsString string) (fs.Fs, error) {
+		// base name of config on user name.  This may appear in logs
+		name := "proxy-" + user
+		fsString = name + ":" + root
+		newFs = func(ctx context.Context, fsString string) (fs.Fs, error) {
 			// Update the config with the default values
 			for i := range fsInfo.Options {
 				o := &fsInfo.Options[i]
@@ -232,7 +244,13 @@ func (p *Proxy) call(user, auth string, isPublicKey bool) (value interface{}, er
 				}
 			}
 			return fsInfo.NewFs(ctx, name, root, config)
-		})
+		}
+	}
+
+	// Look for fs in the VFS cache
+	value, err = p.vfsCache.Get(user, func(key string) (value interface{}, ok bool, err error) {
+		// Create the Fs from the cache
+		f, err := cache.GetFn(p.ctx, fsString, newFs)
 		if err != nil {
 			return nil, false, err
 		}
@@ -28,10 +28,14 @@ func main() {
 		}
 		out[k] = v
 	}
-	if out["type"] == "" {
+	// Map this user onto an existing remote
+	if in["user"] == "remote-user" {
+		out = map[string]string{"_remote": "proxytest:" + os.Getenv("RCLONE_TEST_PROXY_DIR")}
+	}
+	if out["type"] == "" && out["_remote"] == "" {
 		out["type"] = "local"
 	}
-	if out["_root"] == "" {
+	if out["_root"] == "" && out["_remote"] == "" {
 		out["_root"] = ""
 	}
 	json.NewEncoder(os.Stdout).Encode(&out)
@@ -6,11 +6,16 @@ import (
 	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/base64"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	_ "github.com/artpar/rclone/backend/local"
+	"github.com/artpar/rclone/fs/config"
+	"github.com/artpar/rclone/fs/config/configfile"
 	"github.com/artpar/rclone/fs/config/configmap"
 	"github.com/artpar/rclone/fs/config/obscure"
 	"github.com/stretchr/testify/assert"
@@ -232,4 +237,36 @@ func TestRun(t *testing.T) {
 		// check cache is at the same level
 		assert.Equal(t, 1, p.vfsCache.Entries())
 	})
+
+	t.Run("Call w/Remote", func(t *testing.T) {
+		// check cache empty
+		assert.Equal(t, 0, p.vfsCache.Entries())
+		defer p.vfsCache.Clear()
+
+		// Make a config file with the remote the proxy maps onto
+		dir, err := ioutil.TempDir("", "rclone-proxy-test")
+		require.NoError(t, err)
+		defer func() {
+			_ = os.RemoveAll(dir)
+		}()
+		configPath := filepath.Join(dir, "rclone.conf")
+		require.NoError(t, ioutil.WriteFile(configPath, []byte("[proxytest]\ntype = local\n"), 0600))
+		oldConfigPath := config.ConfigPath
+		config.ConfigPath = configPath
+		defer func() {
+			config.ConfigPath = oldConfigPath
+		}()
+		configfile.LoadConfig(context.Background())
+		require.NoError(t, os.Setenv("RCLONE_TEST_PROXY_DIR", dir))
+		defer func() {
+			_ = os.Unsetenv("RCLONE_TEST_PROXY_DIR")
+		}()
+
+		vfs, vfsKey, err := p.Call("remote-user", testPass, false)
+		require.NoError(t, err)
+		require.NotNil(t, vfs)
+		assert.Equal(t, "proxytest", vfs.Fs().Name())
+		assert.Equal(t, filepath.Clean(dir), filepath.Clean(vfs.Fs().Root()))
+		assert.Equal(t, "remote-user", vfsKey)
+	})
 }
@@ -0,0 +1,88 @@
+package restic
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "rclone_restic_"
+
+// Labels used on the restic metrics
+//
+// repo is the URL path of the repository, eg "/" or "/user1repo/"
+// and type is the restic file type, eg "data", "index" or "locks"
+var metricsLabels = []string{"repo", "type"}
+
+var (
+	// blobsStored counts the objects successfully POSTed
+	blobsStored = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: metricsNamespace + "blobs_stored_total",
+		Help: "Number of objects stored in the repository",
+	}, metricsLabels)
+
+	// bytesStored counts the bytes successfully POSTed
+	bytesStored = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: metricsNamespace + "bytes_stored_total",
+		Help: "Number of bytes stored in the repository",
+	}, metricsLabels)
+
+	// deleteAttempts counts DELETE requests by their result
+	deleteAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: metricsNamespace + "delete_attempts_total",
+		Help: "Number of attempts to delete objects from the repository by result",
+	}, append(metricsLabels, "result"))
+
+	// repoSize is the size of the repository as seen by the server
+	repoSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: metricsNamespace + "repo_size_bytes",
+		Help: "Size of the repository in bytes, measured on listing and updated on store and delete",
+	}, metricsLabels)
+)
+
+// Results for the deleteAttempts metric
+const (
+	deleteOK        = "ok"
+	deleteForbidden = "forbidden"
+	deleteNotFound  = "not_found"
+	deleteError     = "error"
+)
+
+func init() {
+	prometheus.MustRegister(blobsStored, bytesStored, deleteAttempts, repoSize)
+}
+
+// resticTypes are the names of the files and directories in a restic
+// repository
+var resticTypes = map[string]struct{}{
+	"config":    {},
+	"data":      {},
+	"index":     {},
+	"keys":      {},
+	"locks":     {},
+	"snapshots": {},
+}
+
+// repoType splits the URL path of a request into the repository it
+// refers to and the restic file type.
+//
+// For example "/user1repo/data/2159dd48" returns "/user1repo/" and
+// "data". If the type can't be found then type will be returned as "".
+func repoType(urlPath string) (repo, typ string) {
+	parts := strings.Split(strings.Trim(urlPath, "/"), "/")
+	// the type is either the last element (config, lists) or
+	// the one before it (objects)
+	for i := len(parts) - 1; i >= 0 && i >= len(parts)-2; i-- {
+		if _, ok := resticTypes[parts[i]]; ok {
+			repo, typ = strings.Join(parts[:i], "/"), parts[i]
+			break
+		}
+	}
+	if typ == "" {
+		repo = strings.Join(parts, "/")
+	}
+	if repo == "" {
+		return "/", typ
+	}
+	return "/" + repo + "/", typ
+}
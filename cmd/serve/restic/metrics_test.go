@@ -0,0 +1,109 @@
+package restic
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/cmd/serve/httplib/httpflags"
+	"github.com/artpar/rclone/fs/config/configfile"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoType(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		repo string
+		typ  string
+	}{
+		{"/", "/", ""},
+		{"/config", "/", "config"},
+		{"/data/", "/", "data"},
+		{"/data/2159dd48", "/", "data"},
+		{"/locks/2159dd48", "/", "locks"},
+		{"/user1repo/", "/user1repo/", ""},
+		{"/user1repo/config", "/user1repo/", "config"},
+		{"/user1repo/keys/", "/user1repo/", "keys"},
+		{"/user1/repo/snapshots/2159dd48", "/user1/repo/", "snapshots"},
+		{"/data/repo/index/2159dd48", "/data/repo/", "index"},
+	} {
+		repo, typ := repoType(test.in)
+		assert.Equal(t, test.repo, repo, test.in)
+		assert.Equal(t, test.typ, typ, test.in)
+	}
+}
+
+func TestResticMetrics(t *testing.T) {
+	ctx := context.Background()
+	configfile.LoadConfig(ctx)
+
+	tempdir, err := ioutil.TempDir("", "rclone-restic-test-")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tempdir))
+	}()
+
+	f := cmd.NewFsSrc([]string{tempdir})
+	srv := NewServer(f, &httpflags.Opt)
+
+	const repo = "/metricsrepo/"
+	value := func(typ string) (stored, bytes, size float64) {
+		return testutil.ToFloat64(blobsStored.WithLabelValues(repo, typ)),
+			testutil.ToFloat64(bytesStored.WithLabelValues(repo, typ)),
+			testutil.ToFloat64(repoSize.WithLabelValues(repo, typ))
+	}
+	deletes := func(typ, result string) float64 {
+		return testutil.ToFloat64(deleteAttempts.WithLabelValues(repo, typ, result))
+	}
+
+	checkRequest(t, srv.ServeHTTP,
+		newRequest(t, "POST", repo+"?create=true", nil),
+		[]wantFunc{wantCode(http.StatusOK)})
+
+	for _, name := range []string{"aa0001", "aa0002"} {
+		checkRequest(t, srv.ServeHTTP,
+			newRequest(t, "POST", repo+"data/"+name, strings.NewReader("0123456789")),
+			[]wantFunc{wantCode(http.StatusOK)})
+	}
+	stored, bytes, size := value("data")
+	assert.Equal(t, 2.0, stored)
+	assert.Equal(t, 20.0, bytes)
+	assert.Equal(t, 20.0, size)
+
+	// deleting an object reduces the size but not the stored counters
+	checkRequest(t, srv.ServeHTTP,
+		newRequest(t, "DELETE", repo+"data/aa0001", nil),
+		[]wantFunc{wantCode(http.StatusOK)})
+	checkRequest(t, srv.ServeHTTP,
+		newRequest(t, "DELETE", repo+"data/aa0001", nil),
+		[]wantFunc{wantCode(http.StatusNotFound)})
+	stored, bytes, size = value("data")
+	assert.Equal(t, 2.0, stored)
+	assert.Equal(t, 20.0, bytes)
+	assert.Equal(t, 10.0, size)
+	assert.Equal(t, 1.0, deletes("data", deleteOK))
+	assert.Equal(t, 1.0, deletes("data", deleteNotFound))
+
+	// deletes refused in append only mode are counted
+	prev := appendOnly
+	appendOnly = true
+	checkRequest(t, srv.ServeHTTP,
+		newRequest(t, "DELETE", repo+"data/aa0002", nil),
+		[]wantFunc{wantCode(http.StatusForbidden)})
+	appendOnly = prev
+	assert.Equal(t, 1.0, deletes("data", deleteForbidden))
+
+	// listing resets the size to what is in the repository
+	repoSize.WithLabelValues(repo, "data").Set(0)
+	req := newRequest(t, "GET", repo+"data/", nil)
+	req.Header.Set("Accept", resticAPIV2)
+	checkRequest(t, srv.ServeHTTP, req, []wantFunc{wantCode(http.StatusOK)})
+	_, _, size = value("data")
+	assert.Equal(t, 10.0, size)
+}
@@ -128,6 +128,20 @@ these **must** end with /.  Eg
 
 The "--private-repos" flag can be used to limit users to repositories starting
 with a path of ` + "`/<username>/`" + `.
+
+#### Metrics ####
+
+rclone keeps per repository counters of the objects stored, the bytes
+stored and the attempts to delete objects (labelled by whether they
+succeeded, were forbidden by "--append-only", or failed), along with
+an estimate of the size of each repository.  The size is measured
+when restic lists a type of file and is updated as objects are stored
+and deleted.
+
+These are labelled with the repository path (eg "/user1repo/") and
+the restic file type (eg "data") and can be scraped by Prometheus
+from the remote control metrics endpoint by running with
+"--rc --rc-enable-metrics".
 ` + httplib.Help,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
@@ -237,7 +251,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if strings.HasSuffix(path, "/") {
 		switch r.Method {
 		case "GET":
-			s.listObjects(w, r, remote)
+			s.listObjects(w, r, remote, path)
 		case "POST":
 			s.createRepo(w, r, remote)
 		default:
@@ -248,9 +262,9 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		case "GET", "HEAD":
 			s.serveObject(w, r, remote)
 		case "POST":
-			s.postObject(w, r, remote)
+			s.postObject(w, r, remote, path)
 		case "DELETE":
-			s.deleteObject(w, r, remote)
+			s.deleteObject(w, r, remote, path)
 		default:
 			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 		}
@@ -284,7 +298,7 @@ func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, remote stri
 }
 
 // postObject posts an object to the repository
-func (s *Server) postObject(w http.ResponseWriter, r *http.Request, remote string) {
+func (s *Server) postObject(w http.ResponseWriter, r *http.Request, remote, urlPath string) {
 	if appendOnly {
 		// make sure the file does not exist yet
 		_, err := s.newObject(r.Context(), remote)
@@ -305,17 +319,28 @@ func (s *Server) postObject(w http.ResponseWriter, r *http.Request, remote strin
 		return
 	}
 
+	// update the metrics, allowing for an overwritten object
+	repo, typ := repoType(urlPath)
+	if old := s.cache.find(remote); old != nil {
+		repoSize.WithLabelValues(repo, typ).Sub(float64(old.Size()))
+	}
+	blobsStored.WithLabelValues(repo, typ).Inc()
+	bytesStored.WithLabelValues(repo, typ).Add(float64(o.Size()))
+	repoSize.WithLabelValues(repo, typ).Add(float64(o.Size()))
+
 	// if successfully uploaded add to cache
 	s.cache.add(remote, o)
 }
 
 // delete the remote
-func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, remote string) {
+func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, remote, urlPath string) {
+	repo, typ := repoType(urlPath)
 	if appendOnly {
 		parts := strings.Split(r.URL.Path, "/")
 
 		// if path doesn't end in "/locks/:name", disallow the operation
 		if len(parts) < 2 || parts[len(parts)-2] != "locks" {
+			deleteAttempts.WithLabelValues(repo, typ, deleteForbidden).Inc()
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 			return
 		}
@@ -324,6 +349,7 @@ func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, remote str
 	o, err := s.newObject(r.Context(), remote)
 	if err != nil {
 		fs.Debugf(remote, "Delete request error: %v", err)
+		deleteAttempts.WithLabelValues(repo, typ, deleteNotFound).Inc()
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		return
 	}
@@ -331,12 +357,16 @@ func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, remote str
 	if err := o.Remove(r.Context()); err != nil {
 		fs.Errorf(remote, "Delete request remove error: %v", err)
 		if err == fs.ErrorObjectNotFound {
+			deleteAttempts.WithLabelValues(repo, typ, deleteNotFound).Inc()
 			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		} else {
+			deleteAttempts.WithLabelValues(repo, typ, deleteError).Inc()
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		}
 		return
 	}
+	deleteAttempts.WithLabelValues(repo, typ, deleteOK).Inc()
+	repoSize.WithLabelValues(repo, typ).Sub(float64(o.Size()))
 
 	// remove object from cache
 	s.cache.remove(remote)
@@ -360,7 +390,7 @@ func (ls *listItems) add(o fs.Object) {
 }
 
 // listObjects lists all Objects of a given type in an arbitrary order.
-func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, remote string) {
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, remote, urlPath string) {
 	fs.Debugf(remote, "list request")
 
 	if r.Header.Get("Accept") != resticAPIV2 {
@@ -394,6 +424,15 @@ func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, remote stri
 		}
 	}
 
+	// a full listing of a type gives its size in the repository
+	if repo, typ := repoType(urlPath); typ != "" {
+		var size int64
+		for _, item := range ls {
+			size += item.Size
+		}
+		repoSize.WithLabelValues(repo, typ).Set(float64(size))
+	}
+
 	w.Header().Set("Content-Type", "application/vnd.x.restic.rest.v2")
 	enc := json.NewEncoder(w)
 	err = enc.Encode(ls)
@@ -73,6 +73,9 @@ Use "rclone hashsum" to see the full list.
 		}
 		hashType = hash.None
 		if hashName == "auto" {
+			if f == nil {
+				return errors.New("can't use --etag-hash auto with --auth-proxy - name the hash instead")
+			}
 			hashType = f.Hashes().GetOne()
 		} else if hashName != "" {
 			err := hashType.Set(hashName)
@@ -13,36 +13,60 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var jsonOutput bool
+var (
+	jsonOutput bool
+	depth      int
+)
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlags := commandDefinition.Flags()
 	flags.BoolVarP(cmdFlags, &jsonOutput, "json", "", false, "format output as JSON")
+	flags.IntVarP(cmdFlags, &depth, "depth", "", 0, "show the totals for each directory down to this depth")
 }
 
 var commandDefinition = &cobra.Command{
 	Use:   "size remote:path",
 	Short: `Prints the total size and number of objects in remote:path.`,
+	Long: `
+Prints the total size and number of objects in remote:path.
+
+Use --depth N to also print the totals for each directory down to N
+levels below remote:path, including everything in its subdirectories.
+For example --depth 1 shows how much space each top level directory is
+using. Directories with no objects in are not shown.
+
+Use --json to output the totals as JSON. With --depth the totals for
+each directory are in the "dirs" list, for example
+
+    {"count":3,"bytes":1536,"dirs":[{"path":"a","count":2,"bytes":1024},{"path":"b","count":1,"bytes":512}]}
+`,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
-			var err error
 			var results struct {
-				Count int64 `json:"count"`
-				Bytes int64 `json:"bytes"`
+				Count int64                `json:"count"`
+				Bytes int64                `json:"bytes"`
+				Dirs  []operations.DirSize `json:"dirs,omitempty"`
 			}
 
-			results.Count, results.Bytes, err = operations.Count(context.Background(), fsrc)
+			dirs, err := operations.CountDirs(context.Background(), fsrc, depth)
 			if err != nil {
 				return err
 			}
+			results.Count, results.Bytes = dirs[0].Count, dirs[0].Bytes
+			if depth > 0 {
+				results.Dirs = dirs[1:]
+			}
 
 			if jsonOutput {
 				return json.NewEncoder(os.Stdout).Encode(results)
 			}
 
+			for _, dir := range results.Dirs {
+				fmt.Printf("%s: %d objects, %s (%d Bytes)\n", dir.Path, dir.Count, fs.SizeSuffix(dir.Bytes).Unit("Bytes"), dir.Bytes)
+			}
 			fmt.Printf("Total objects: %d\n", results.Count)
 			fmt.Printf("Total size: %s (%d Bytes)\n", fs.SizeSuffix(results.Bytes).Unit("Bytes"), results.Bytes)
 
@@ -3,19 +3,23 @@
 package makefiles
 
 import (
-	cryptrand "crypto/rand"
+	"context"
 	"io"
-	"log"
+	"io/ioutil"
+	"math"
 	"math/rand"
-	"os"
-	"path/filepath"
+	"path"
+	"time"
 
 	"github.com/artpar/rclone/cmd"
 	"github.com/artpar/rclone/cmd/test"
 	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/accounting"
 	"github.com/artpar/rclone/fs/config/flags"
-	"github.com/artpar/rclone/lib/random"
+	"github.com/artpar/rclone/fs/object"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
@@ -27,11 +31,15 @@ var (
 	maxFileSize              = fs.SizeSuffix(100)
 	minFileNameLength        = 4
 	maxFileNameLength        = 12
+	sizeDistribution         = "uniform"
+	nameChars                = "readable"
+	seed                     = int64(0)
+)
 
-	// Globals
-	directoriesToCreate int
-	totalDirectories    int
-	fileNames           = map[string]struct{}{} // keep a note of which file name we've used already
+// Character sets for the names selected with --chars
+const (
+	asciiChars   = " !\"#$%&'()*+,-.0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+	unicodeChars = "abcxyzABCXYZ0129 -_.éüñçøßÆŒłžΩπЖжשׁعربي中文字日本語한국어€✓★😀🚀 ​́"
 )
 
 func init() {
@@ -44,43 +52,193 @@ func init() {
 	flags.FVarP(cmdFlags, &maxFileSize, "max-file-size", "", "Maximum size of files to create")
 	flags.IntVarP(cmdFlags, &minFileNameLength, "min-name-length", "", minFileNameLength, "Minimum size of file names")
 	flags.IntVarP(cmdFlags, &maxFileNameLength, "max-name-length", "", maxFileNameLength, "Maximum size of file names")
+	flags.StringVarP(cmdFlags, &sizeDistribution, "size-distribution", "", sizeDistribution, "Distribution of file sizes: uniform or log")
+	flags.StringVarP(cmdFlags, &nameChars, "chars", "", nameChars, "Characters for names: readable, ascii, unicode or the characters to use")
+	flags.Int64VarP(cmdFlags, &seed, "seed", "", seed, "Seed for the random number generator (0 for random)")
 }
 
 var commandDefinition = &cobra.Command{
-	Use:   "makefiles <dir>",
-	Short: `Make a random file hierarchy in <dir>`,
+	Use:   "makefiles <remote:path>",
+	Short: `Make a random file hierarchy in <remote:path>`,
+	Long: `This makes a random hierarchy of directories containing random
+files directly on the remote. It is useful for benchmarking backends
+and for reproducing problems which only happen with lots of files.
+
+The files are uploaded in parallel using --transfers.
+
+The sizes of the files are chosen between --min-file-size and
+--max-file-size. With --size-distribution uniform (the default) each
+size is equally likely, and with --size-distribution log the sizes are
+spread evenly over the orders of magnitude, so there are as many files
+between 1k and 10k as there are between 10M and 100M, which is closer
+to real data.
+
+The names are made from the characters chosen with --chars:
+
+- readable - easy to read names of lower case letters and digits
+- ascii - all the printable ASCII characters except "/"
+- unicode - a mix of accented, non Latin, emoji and unusual characters
+- anything else is used as the set of characters to use
+
+Use --seed to make the same hierarchy each time, for instance to
+reproduce a problem.
+`,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
-		outputDirectory := args[0]
-		directoriesToCreate = numberOfFiles / averageFilesPerDirectory
-		averageSize := (minFileSize + maxFileSize) / 2
-		log.Printf("Creating %d files of average size %v in %d directories in %q.", numberOfFiles, averageSize, directoriesToCreate, outputDirectory)
-		root := &dir{name: outputDirectory, depth: 1}
-		for totalDirectories < directoriesToCreate {
-			root.createDirectories()
+		fdst := cmd.NewFsDir(args)
+		cmd.Run(false, false, command, func() error {
+			ctx := context.Background()
+			if seed == 0 {
+				seed = time.Now().UnixNano()
+			}
+			m, err := newMaker(seed)
+			if err != nil {
+				return err
+			}
+			files := m.makeFiles()
+			fs.Logf(fdst, "Creating %d files of average size %v in %d directories with --seed %d", len(files), (minFileSize+maxFileSize)/2, m.totalDirectories, seed)
+			err = writeFiles(ctx, fdst, files)
+			if err != nil {
+				return err
+			}
+			fs.Logf(fdst, "Done.")
+			return nil
+		})
+	},
+}
+
+// file is a file in the directory hierarchy to be written
+type file struct {
+	remote string
+	size   int64
+	seed   int64 // seed for the random data
+}
+
+// maker makes a random directory hierarchy
+type maker struct {
+	rand                *rand.Rand
+	chars               []rune // characters for names or nil for readable names
+	directoriesToCreate int
+	totalDirectories    int
+	fileNames           map[string]struct{} // keep a note of which file name we've used already
+}
+
+// newMaker checks the flags and returns a maker using seed
+func newMaker(seed int64) (*maker, error) {
+	if maxFileSize < minFileSize {
+		return nil, errors.New("--max-file-size must be at least --min-file-size")
+	}
+	if minFileNameLength < 1 || maxFileNameLength < minFileNameLength {
+		return nil, errors.New("--min-name-length must be at least 1 and no more than --max-name-length")
+	}
+	if averageFilesPerDirectory < 1 {
+		return nil, errors.New("--files-per-directory must be at least 1")
+	}
+	switch sizeDistribution {
+	case "uniform", "log":
+	default:
+		return nil, errors.Errorf("unknown --size-distribution %q - need uniform or log", sizeDistribution)
+	}
+	m := &maker{
+		rand:      rand.New(rand.NewSource(seed)),
+		fileNames: map[string]struct{}{},
+	}
+	switch nameChars {
+	case "readable":
+		m.chars = nil
+	case "ascii":
+		m.chars = []rune(asciiChars)
+	case "unicode":
+		m.chars = []rune(unicodeChars)
+	default:
+		for _, c := range nameChars {
+			if c != '/' {
+				m.chars = append(m.chars, c)
+			}
 		}
-		dirs := root.list("", []string{})
-		for i := 0; i < numberOfFiles; i++ {
-			dir := dirs[rand.Intn(len(dirs))]
-			writeFile(dir, fileName())
+		if len(m.chars) == 0 {
+			return nil, errors.New("--chars must have some characters other than /")
 		}
-		log.Printf("Done.")
-	},
+	}
+	return m, nil
+}
+
+// makeFiles makes the directory hierarchy returning the files to
+// write in it
+func (m *maker) makeFiles() []file {
+	m.directoriesToCreate = numberOfFiles / averageFilesPerDirectory
+	root := &dir{depth: 1}
+	for m.totalDirectories < m.directoriesToCreate {
+		m.createDirectories(root)
+	}
+	dirs := root.list("", []string{})
+	files := make([]file, numberOfFiles)
+	for i := range files {
+		dir := dirs[m.rand.Intn(len(dirs))]
+		files[i] = file{
+			remote: path.Join(dir, m.fileName()),
+			size:   m.fileSize(),
+			seed:   m.rand.Int63(),
+		}
+	}
+	return files
+}
+
+// name makes a random name of length characters
+func (m *maker) name(length int) string {
+	const (
+		vowel     = "aeiou"
+		consonant = "bcdfghjklmnpqrstvwxyz"
+		digit     = "0123456789"
+	)
+	out := make([]rune, length)
+	if m.chars == nil {
+		pattern := []string{consonant, vowel, consonant, vowel, consonant, vowel, consonant, digit}
+		for i := range out {
+			source := pattern[i%len(pattern)]
+			out[i] = rune(source[m.rand.Intn(len(source))])
+		}
+	} else {
+		for i := range out {
+			out[i] = m.chars[m.rand.Intn(len(m.chars))]
+		}
+	}
+	return string(out)
 }
 
 // fileName creates a unique random file or directory name
-func fileName() (name string) {
+func (m *maker) fileName() (name string) {
 	for {
-		length := rand.Intn(maxFileNameLength-minFileNameLength) + minFileNameLength
-		name = random.String(length)
-		if _, found := fileNames[name]; !found {
+		length := m.rand.Intn(maxFileNameLength-minFileNameLength+1) + minFileNameLength
+		name = m.name(length)
+		if _, found := m.fileNames[name]; !found && name != "." && name != ".." {
 			break
 		}
 	}
-	fileNames[name] = struct{}{}
+	m.fileNames[name] = struct{}{}
 	return name
 }
 
+// fileSize returns a random file size using the --size-distribution
+func (m *maker) fileSize() int64 {
+	min, max := int64(minFileSize), int64(maxFileSize)
+	if max <= min {
+		return min
+	}
+	if sizeDistribution == "log" {
+		// Choose the size so log(size+1) is uniform
+		lo, hi := math.Log(float64(min+1)), math.Log(float64(max+1))
+		size := int64(math.Exp(lo+m.rand.Float64()*(hi-lo))) - 1
+		if size < min {
+			size = min
+		} else if size > max {
+			size = max
+		}
+		return size
+	}
+	return m.rand.Int63n(max-min+1) + min
+}
+
 // dir is a directory in the directory hierarchy being built up
 type dir struct {
 	name     string
@@ -90,30 +248,29 @@ type dir struct {
 }
 
 // Create a random directory hierarchy under d
-func (d *dir) createDirectories() {
-	for totalDirectories < directoriesToCreate {
+func (m *maker) createDirectories(d *dir) {
+	for m.totalDirectories < m.directoriesToCreate {
 		newDir := &dir{
-			name:   fileName(),
+			name:   m.fileName(),
 			depth:  d.depth + 1,
 			parent: d,
 		}
 		d.children = append(d.children, newDir)
-		totalDirectories++
-		switch rand.Intn(4) {
+		m.totalDirectories++
+		switch m.rand.Intn(4) {
 		case 0:
 			if d.depth < maxDepth {
-				newDir.createDirectories()
+				m.createDirectories(newDir)
 			}
 		case 1:
 			return
 		}
 	}
-	return
 }
 
 // list the directory hierarchy
-func (d *dir) list(path string, output []string) []string {
-	dirPath := filepath.Join(path, d.name)
+func (d *dir) list(dirPath string, output []string) []string {
+	dirPath = path.Join(dirPath, d.name)
 	output = append(output, dirPath)
 	for _, subDir := range d.children {
 		output = subDir.list(dirPath, output)
@@ -121,24 +278,48 @@ func (d *dir) list(path string, output []string) []string {
 	return output
 }
 
-// writeFile writes a random file at dir/name
-func writeFile(dir, name string) {
-	err := os.MkdirAll(dir, 0777)
-	if err != nil {
-		log.Fatalf("Failed to make directory %q: %v", dir, err)
-	}
-	path := filepath.Join(dir, name)
-	fd, err := os.Create(path)
-	if err != nil {
-		log.Fatalf("Failed to open file %q: %v", path, err)
-	}
-	size := rand.Int63n(int64(maxFileSize-minFileSize)) + int64(minFileSize)
-	_, err = io.CopyN(fd, cryptrand.Reader, size)
-	if err != nil {
-		log.Fatalf("Failed to write %v bytes to file %q: %v", size, path, err)
+// writeFiles writes the files to f using --transfers uploads at once
+func writeFiles(ctx context.Context, f fs.Fs, files []file) error {
+	ci := fs.GetConfig(ctx)
+	g, gCtx := errgroup.WithContext(ctx)
+	in := make(chan file)
+	g.Go(func() error {
+		defer close(in)
+		for _, file := range files {
+			select {
+			case in <- file:
+			case <-gCtx.Done():
+				return gCtx.Err()
+			}
+		}
+		return nil
+	})
+	for i := 0; i < ci.Transfers; i++ {
+		g.Go(func() error {
+			for file := range in {
+				err := writeFile(gCtx, f, file)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	}
-	err = fd.Close()
+	return g.Wait()
+}
+
+// writeFile writes a random file to f
+func writeFile(ctx context.Context, f fs.Fs, file file) (err error) {
+	tr := accounting.Stats(ctx).NewTransferRemoteSize(file.remote, file.size)
+	defer func() {
+		tr.Done(ctx, err)
+	}()
+	data := io.LimitReader(rand.New(rand.NewSource(file.seed)), file.size)
+	in := tr.Account(ctx, ioutil.NopCloser(data))
+	info := object.NewStaticObjectInfo(file.remote, time.Now(), file.size, true, nil, f)
+	_, err = f.Put(ctx, in, info)
 	if err != nil {
-		log.Fatalf("Failed to close file %q: %v", path, err)
+		return errors.Wrapf(err, "failed to write %q", file.remote)
 	}
+	return nil
 }
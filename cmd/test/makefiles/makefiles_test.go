@@ -0,0 +1,57 @@
+package makefiles
+
+import (
+	"path"
+	"testing"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeFiles(t *testing.T) {
+	oldMin, oldMax, oldDistribution, oldChars := minFileSize, maxFileSize, sizeDistribution, nameChars
+	defer func() {
+		minFileSize, maxFileSize, sizeDistribution, nameChars = oldMin, oldMax, oldDistribution, oldChars
+	}()
+	minFileSize, maxFileSize = 10, fs.SizeSuffix(1024*1024)
+	for _, distribution := range []string{"uniform", "log"} {
+		for _, chars := range []string{"readable", "ascii", "unicode", "xyz"} {
+			sizeDistribution, nameChars = distribution, chars
+			m, err := newMaker(1)
+			require.NoError(t, err)
+			files := m.makeFiles()
+			assert.Len(t, files, numberOfFiles)
+			seen := map[string]struct{}{}
+			for _, file := range files {
+				assert.True(t, file.size >= int64(minFileSize) && file.size <= int64(maxFileSize), file.size)
+				_, found := seen[file.remote]
+				assert.False(t, found, file.remote)
+				seen[file.remote] = struct{}{}
+				leaf := path.Base(file.remote)
+				assert.True(t, len([]rune(leaf)) >= minFileNameLength && len([]rune(leaf)) <= maxFileNameLength, leaf)
+				if chars == "xyz" {
+					assert.Regexp(t, `^[xyz/]+$`, file.remote)
+				}
+			}
+
+			// The same seed makes the same files
+			m, err = newMaker(1)
+			require.NoError(t, err)
+			assert.Equal(t, files, m.makeFiles())
+		}
+	}
+}
+
+func TestNewMakerErrors(t *testing.T) {
+	oldDistribution, oldChars := sizeDistribution, nameChars
+	defer func() {
+		sizeDistribution, nameChars = oldDistribution, oldChars
+	}()
+	sizeDistribution = "potato"
+	_, err := newMaker(1)
+	assert.Error(t, err)
+	sizeDistribution, nameChars = "uniform", "/"
+	_, err = newMaker(1)
+	assert.Error(t, err)
+}
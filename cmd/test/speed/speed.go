@@ -0,0 +1,283 @@
+// Package speed benchmarks uploads and downloads to a remote
+package speed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/cmd/test"
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/accounting"
+	"github.com/artpar/rclone/fs/config/flags"
+	"github.com/artpar/rclone/fs/object"
+	"github.com/artpar/rclone/fs/operations"
+	"github.com/artpar/rclone/lib/random"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	// Flags
+	sizes      = "256k,4M,32M"
+	count      = 8
+	jsonOutput = false
+)
+
+func init() {
+	test.Command.AddCommand(commandDefinition)
+	cmdFlags := commandDefinition.Flags()
+	flags.StringVarP(cmdFlags, &sizes, "sizes", "", sizes, "Comma separated list of object sizes to test")
+	flags.IntVarP(cmdFlags, &count, "count", "", count, "Number of objects of each size to upload and download")
+	flags.BoolVarP(cmdFlags, &jsonOutput, "json", "", jsonOutput, "Output the results as JSON")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "speed remote:path",
+	Short: `Measure the upload and download speed of a remote.`,
+	Long: `This uploads --count temporary objects of each of the --sizes given
+to a new directory in remote:path, downloads them again and then
+deletes them, reporting the throughput and the percentiles of the
+time taken for each object.
+
+The objects are transferred --transfers at a time, so run it with
+different values of --transfers or backend flags like chunk sizes to
+see which work best, for example
+
+    rclone test speed --sizes 1M,64M --transfers 8 remote:bucket
+    rclone test speed --sizes 1M,64M --transfers 8 --s3-chunk-size 16M remote:bucket
+
+The throughput is the total bytes transferred divided by the time
+taken for all the transfers of that size. The objects are filled with
+random data so compression doesn't affect the results.
+
+Use --json to output the results as JSON.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		f := cmd.NewFsDir(args)
+		cmd.Run(false, false, command, func() error {
+			ctx := context.Background()
+			testSizes, err := parseSizes(sizes)
+			if err != nil {
+				return err
+			}
+			if count < 1 {
+				return errors.New("--count must be at least 1")
+			}
+			results, err := run(ctx, f, testSizes, count)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "\t")
+				return enc.Encode(results)
+			}
+			for _, result := range results {
+				fmt.Println(result)
+			}
+			return nil
+		})
+	},
+}
+
+// Result is the result of transferring the objects of one size in one
+// direction
+type Result struct {
+	Direction string        `json:"direction"` // upload or download
+	Size      fs.SizeSuffix `json:"size"`      // size of each object
+	Objects   int           `json:"objects"`   // number of objects transferred
+	Bytes     int64         `json:"bytes"`     // total bytes transferred
+	Seconds   float64       `json:"seconds"`   // time taken for all the transfers
+	Speed     float64       `json:"speed"`     // bytes per second
+	P50       float64       `json:"p50"`       // seconds taken by the median transfer
+	P90       float64       `json:"p90"`       // 90th percentile seconds per transfer
+	P99       float64       `json:"p99"`       // 99th percentile seconds per transfer
+	Max       float64       `json:"max"`       // seconds taken by the slowest transfer
+}
+
+// String formats the result for humans
+func (r Result) String() string {
+	return fmt.Sprintf("%-8s %6v x %-4d %14s  latency p50 %.3fs p90 %.3fs p99 %.3fs max %.3fs",
+		r.Direction, r.Size, r.Objects, fs.SizeSuffix(r.Speed).Unit("Byte/s"), r.P50, r.P90, r.P99, r.Max)
+}
+
+// parseSizes parses a comma separated list of sizes
+func parseSizes(s string) (out []fs.SizeSuffix, err error) {
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var size fs.SizeSuffix
+		err = size.Set(part)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad size %q in --sizes", part)
+		}
+		if size < 0 {
+			return nil, errors.Errorf("bad size %q in --sizes", part)
+		}
+		out = append(out, size)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("need at least one size in --sizes")
+	}
+	return out, nil
+}
+
+// percentile returns the p-th percentile of the sorted durations
+// using the nearest rank method
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	} else if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// newResult makes a Result from the time taken by each transfer and
+// the time taken by all of them
+func newResult(direction string, size fs.SizeSuffix, durations []time.Duration, elapsed time.Duration) Result {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	r := Result{
+		Direction: direction,
+		Size:      size,
+		Objects:   len(sorted),
+		Bytes:     int64(size) * int64(len(sorted)),
+		Seconds:   elapsed.Seconds(),
+		P50:       percentile(sorted, 50).Seconds(),
+		P90:       percentile(sorted, 90).Seconds(),
+		P99:       percentile(sorted, 99).Seconds(),
+		Max:       percentile(sorted, 100).Seconds(),
+	}
+	if r.Seconds > 0 {
+		r.Speed = float64(r.Bytes) / r.Seconds
+	}
+	return r
+}
+
+// run uploads and downloads n objects of each size in a temporary
+// directory in f which is removed afterwards
+func run(ctx context.Context, f fs.Fs, testSizes []fs.SizeSuffix, n int) (results []Result, err error) {
+	dir := "rclone-test-speed-" + random.String(8)
+	fs.Logf(f, "Using temporary directory %q", dir)
+	defer func() {
+		purgeErr := operations.Purge(ctx, f, dir)
+		if purgeErr != nil && purgeErr != fs.ErrorDirNotFound {
+			fs.Errorf(f, "Failed to remove temporary directory %q: %v", dir, purgeErr)
+		}
+	}()
+	for _, size := range testSizes {
+		remotes := make([]string, n)
+		for i := range remotes {
+			remotes[i] = path.Join(dir, fmt.Sprintf("%v-%03d.bin", size, i))
+		}
+		objects := make([]fs.Object, n)
+		durations, elapsed, err := transferAll(ctx, n, func(i int) (err error) {
+			objects[i], err = upload(ctx, f, remotes[i], int64(size))
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, newResult("upload", size, durations, elapsed))
+		durations, elapsed, err = transferAll(ctx, n, func(i int) error {
+			return download(ctx, objects[i])
+		})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, newResult("download", size, durations, elapsed))
+	}
+	return results, nil
+}
+
+// transferAll calls fn for 0..n-1 using --transfers goroutines,
+// returning the time each call took and the time all of them took
+func transferAll(ctx context.Context, n int, fn func(i int) error) (durations []time.Duration, elapsed time.Duration, err error) {
+	ci := fs.GetConfig(ctx)
+	durations = make([]time.Duration, n)
+	var mu sync.Mutex
+	next := 0
+	g, gCtx := errgroup.WithContext(ctx)
+	start := time.Now()
+	for t := 0; t < ci.Transfers; t++ {
+		g.Go(func() error {
+			for gCtx.Err() == nil {
+				mu.Lock()
+				i := next
+				next++
+				mu.Unlock()
+				if i >= n {
+					return nil
+				}
+				transferStart := time.Now()
+				err := fn(i)
+				if err != nil {
+					return err
+				}
+				durations[i] = time.Since(transferStart)
+			}
+			return gCtx.Err()
+		})
+	}
+	err = g.Wait()
+	return durations, time.Since(start), err
+}
+
+// upload writes size bytes of random data to remote in f
+func upload(ctx context.Context, f fs.Fs, remote string, size int64) (o fs.Object, err error) {
+	tr := accounting.Stats(ctx).NewTransferRemoteSize(remote, size)
+	defer func() {
+		tr.Done(ctx, err)
+	}()
+	data := io.LimitReader(rand.New(rand.NewSource(time.Now().UnixNano())), size)
+	in := tr.Account(ctx, ioutil.NopCloser(data))
+	info := object.NewStaticObjectInfo(remote, time.Now(), size, true, nil, f)
+	o, err = f.Put(ctx, in, info)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to upload %q", remote)
+	}
+	return o, nil
+}
+
+// download reads o discarding the data
+func download(ctx context.Context, o fs.Object) (err error) {
+	tr := accounting.Stats(ctx).NewTransfer(o)
+	defer func() {
+		tr.Done(ctx, err)
+	}()
+	rc, err := o.Open(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %q", o.Remote())
+	}
+	in := tr.Account(ctx, rc)
+	_, err = io.Copy(ioutil.Discard, in)
+	closeErr := in.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to download %q", o.Remote())
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package speed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSizes(t *testing.T) {
+	got, err := parseSizes("1k, 2M,,0")
+	require.NoError(t, err)
+	assert.Equal(t, []fs.SizeSuffix{1024, 2 * 1024 * 1024, 0}, got)
+	for _, in := range []string{"", ",", "potato", "off"} {
+		_, err = parseSizes(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	assert.Equal(t, time.Duration(0), percentile(sorted, 50))
+	for i := 1; i <= 10; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Second)
+	}
+	assert.Equal(t, 5*time.Second, percentile(sorted, 50))
+	assert.Equal(t, 9*time.Second, percentile(sorted, 90))
+	assert.Equal(t, 10*time.Second, percentile(sorted, 99))
+	assert.Equal(t, 10*time.Second, percentile(sorted, 100))
+	assert.Equal(t, 1*time.Second, percentile(sorted, 0))
+}
+
+func TestNewResult(t *testing.T) {
+	durations := []time.Duration{3 * time.Second, 1 * time.Second, 2 * time.Second, 4 * time.Second}
+	r := newResult("upload", 1024, durations, 2*time.Second)
+	assert.Equal(t, Result{
+		Direction: "upload",
+		Size:      1024,
+		Objects:   4,
+		Bytes:     4096,
+		Seconds:   2,
+		Speed:     2048,
+		P50:       2,
+		P90:       4,
+		P99:       4,
+		Max:       4,
+	}, r)
+	// check the durations weren't sorted in place
+	assert.Equal(t, 3*time.Second, durations[0])
+}
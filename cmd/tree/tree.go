@@ -2,11 +2,13 @@ package tree
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
+	gosort "sort"
 	"strings"
 	"time"
 
@@ -26,6 +28,7 @@ var (
 	outFileName string
 	noReport    bool
 	sort        string
+	jsonOutput  bool
 )
 
 func init() {
@@ -42,6 +45,7 @@ func init() {
 	// flags.StringVarP(cmdFlags, &opts.Pattern, "pattern", "P", "", "List only those files that match the pattern given.")
 	// flags.StringVarP(cmdFlags, &opts.IPattern, "exclude", "", "", "Do not list files that match the given pattern.")
 	flags.StringVarP(cmdFlags, &outFileName, "output", "o", "", "Output to file instead of stdout.")
+	flags.BoolVarP(cmdFlags, &jsonOutput, "json", "J", false, "Print the tree as JSON.")
 	// Files
 	flags.BoolVarP(cmdFlags, &opts.ByteSize, "size", "s", false, "Print the size in bytes of each file.")
 	flags.BoolVarP(cmdFlags, &opts.UnitSize, "human", "", false, "Print the size in a more human readable way.")
@@ -91,6 +95,38 @@ You can use any of the filtering options with the tree command (e.g.
 The tree command has many options for controlling the listing which
 are compatible with the tree command.  Note that not all of them have
 short options as they conflict with rclone's short options.
+
+Use --size (or --human for sizes like 1.2M) to show the size of each
+file and the total size of the files in each directory, and --modtime
+to show the modification time of each entry.
+
+Use --json to print the tree as JSON in the same format as tree -J,
+for example
+
+    $ rclone tree --json --size --modtime remote:path
+    [
+      {
+        "type": "directory",
+        "name": "/",
+        "size": 5,
+        "contents": [
+          {
+            "type": "file",
+            "name": "file1",
+            "size": 5,
+            "time": "2021-06-01T10:00:00Z"
+          }
+        ]
+      },
+      {
+        "type": "report",
+        "directories": 0,
+        "files": 1
+      }
+    ]
+
+Sizes are always in bytes and times are in RFC3339 format in the
+JSON. The size of a directory is the total size of the files in it.
 `,
 	RunE: func(command *cobra.Command, args []string) error {
 		cmd.CheckArgs(1, 1, command, args)
@@ -125,6 +161,9 @@ func Tree(fsrc fs.Fs, outFile io.Writer, opts *tree.Options) error {
 	if err != nil {
 		return err
 	}
+	if jsonOutput {
+		return treeJSON(dirs, outFile, opts)
+	}
 	opts.Fs = NewFs(dirs)
 	opts.OutFile = outFile
 	inf := tree.New("/")
@@ -144,6 +183,130 @@ func Tree(fsrc fs.Fs, outFile io.Writer, opts *tree.Options) error {
 	return nil
 }
 
+// jsonNode is a file or directory in the JSON output
+type jsonNode struct {
+	Type     string      `json:"type"`
+	Name     string      `json:"name"`
+	Size     *int64      `json:"size,omitempty"`
+	Time     string      `json:"time,omitempty"`
+	Contents []*jsonNode `json:"contents,omitempty"`
+}
+
+// jsonReport is the footer of the JSON output
+type jsonReport struct {
+	Type        string `json:"type"`
+	Directories int    `json:"directories"`
+	Files       *int   `json:"files,omitempty"`
+}
+
+// sortFunc returns the tree sort function selected by opts or nil
+// for unsorted
+func sortFunc(opts *tree.Options) tree.SortFunc {
+	switch {
+	case opts.NoSort:
+		return nil
+	case opts.ModSort, opts.CTimeSort:
+		return tree.ModSort
+	case opts.DirSort:
+		return tree.DirSort
+	case opts.VerSort:
+		return tree.VerSort
+	case opts.SizeSort:
+		return tree.SizeSort
+	}
+	return tree.NameSort
+}
+
+// treeJSON outputs dirs to outFile as JSON in the same format as
+// tree -J using the options in opts
+func treeJSON(dirs dirtree.DirTree, outFile io.Writer, opts *tree.Options) error {
+	ctx := context.Background()
+	showSize := opts.ByteSize || opts.UnitSize
+	less := sortFunc(opts)
+	var nd, nf int
+	// add the contents of dir at depth to node returning its total size
+	var add func(node *jsonNode, dir string, depth int) int64
+	add = func(node *jsonNode, dir string, depth int) (total int64) {
+		if opts.DeepLevel > 0 && depth >= opts.DeepLevel {
+			return 0
+		}
+		entries := make([]os.FileInfo, 0, len(dirs[dir]))
+		for _, entry := range dirs[dir] {
+			entries = append(entries, &FileInfo{entry})
+		}
+		if less != nil {
+			sortSlice(entries, less, opts.ReverSort)
+		}
+		for _, fi := range entries {
+			entry := fi.(*FileInfo).entry
+			if !opts.All && strings.HasPrefix(fi.Name(), ".") {
+				continue
+			}
+			child := &jsonNode{
+				Name: fi.Name(),
+			}
+			if opts.FullPath {
+				child.Name = "/" + entry.Remote()
+			}
+			var size int64
+			if fi.IsDir() {
+				nd++
+				child.Type = "directory"
+				size = add(child, entry.Remote(), depth+1)
+			} else {
+				if opts.DirsOnly {
+					continue
+				}
+				nf++
+				child.Type = "file"
+				size = fi.Size()
+			}
+			total += size
+			if showSize {
+				child.Size = &size
+			}
+			if opts.LastMod {
+				child.Time = entry.ModTime(ctx).Format(time.RFC3339)
+			}
+			node.Contents = append(node.Contents, child)
+		}
+		return total
+	}
+	root := &jsonNode{
+		Type: "directory",
+		Name: "/",
+	}
+	size := add(root, "", 0)
+	if showSize {
+		root.Size = &size
+	}
+	out := []interface{}{root}
+	if !noReport {
+		report := jsonReport{
+			Type:        "report",
+			Directories: nd,
+		}
+		if !opts.DirsOnly {
+			report.Files = &nf
+		}
+		out = append(out, report)
+	}
+	enc := json.NewEncoder(outFile)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// sortSlice sorts entries with less, reversing the order if reverse
+// is set
+func sortSlice(entries []os.FileInfo, less tree.SortFunc, reverse bool) {
+	gosort.SliceStable(entries, func(i, j int) bool {
+		if reverse {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+}
+
 // FileInfo maps an fs.DirEntry into an os.FileInfo
 type FileInfo struct {
 	entry fs.DirEntry
@@ -33,3 +33,87 @@ func TestTree(t *testing.T) {
 1 directories, 5 files
 `, buf.String())
 }
+
+func TestTreeJSON(t *testing.T) {
+	fstest.Initialise()
+
+	jsonOutput = true
+	defer func() {
+		jsonOutput = false
+	}()
+
+	f, err := fs.NewFs(context.Background(), "testfiles")
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	err = Tree(f, buf, &tree.Options{ByteSize: true, ReverSort: true})
+	require.NoError(t, err)
+	assert.Equal(t, `[
+  {
+    "type": "directory",
+    "name": "/",
+    "size": 0,
+    "contents": [
+      {
+        "type": "directory",
+        "name": "subdir",
+        "size": 0,
+        "contents": [
+          {
+            "type": "file",
+            "name": "file5",
+            "size": 0
+          },
+          {
+            "type": "file",
+            "name": "file4",
+            "size": 0
+          }
+        ]
+      },
+      {
+        "type": "file",
+        "name": "file3",
+        "size": 0
+      },
+      {
+        "type": "file",
+        "name": "file2",
+        "size": 0
+      },
+      {
+        "type": "file",
+        "name": "file1",
+        "size": 0
+      }
+    ]
+  },
+  {
+    "type": "report",
+    "directories": 1,
+    "files": 5
+  }
+]
+`, buf.String())
+
+	buf.Reset()
+	err = Tree(f, buf, &tree.Options{DirsOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, `[
+  {
+    "type": "directory",
+    "name": "/",
+    "contents": [
+      {
+        "type": "directory",
+        "name": "subdir"
+      }
+    ]
+  },
+  {
+    "type": "report",
+    "directories": 1
+  }
+]
+`, buf.String())
+}
@@ -2,8 +2,10 @@ package version
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -12,17 +14,20 @@ import (
 	"github.com/artpar/rclone/cmd"
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/config/flags"
+	"github.com/artpar/rclone/fs/operations"
 	"github.com/spf13/cobra"
 )
 
 var (
-	check = false
+	check     = false
+	porcelain = false
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
 	cmdFlags := commandDefinition.Flags()
 	flags.BoolVarP(cmdFlags, &check, "check", "", false, "Check for new version.")
+	flags.BoolVarP(cmdFlags, &porcelain, "porcelain", "", false, "Output stable tab separated columns for scripts.")
 }
 
 var commandDefinition = &cobra.Command{
@@ -48,6 +53,22 @@ For example:
 Note: before rclone version 1.55 the os/type and os/arch lines were merged,
       and the "go/version" line was tagged as "go version".
 
+If you supply the --porcelain flag then the same information is
+printed in a format for scripts which is guaranteed not to change
+between releases. Each line is a key and a value separated by a tab.
+
+    $ rclone version --porcelain
+    rclone/version	v1.55.0
+    os/version	ubuntu 18.04 (64 bit)
+    os/kernel	4.15.0-136-generic (x86_64)
+    os/type	linux
+    os/arch	amd64
+    go/version	go1.16
+    go/linking	static
+    go/tags	none
+
+Keys are never renamed or removed, but new ones may be added.
+
 If you supply the --check flag, then it will do an online check to
 compare your version with the latest release and the latest beta.
 
@@ -70,12 +91,22 @@ Or
 		cmd.CheckArgs(0, 0, command, args)
 		if check {
 			CheckVersion()
+		} else if porcelain {
+			showVersionPorcelain(os.Stdout)
 		} else {
 			cmd.ShowVersion()
 		}
 	},
 }
 
+// showVersionPorcelain writes the version info to w in --porcelain
+// format
+func showVersionPorcelain(w io.Writer) {
+	for _, kv := range cmd.VersionInfo() {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", kv[0], operations.PorcelainEscape(kv[1]))
+	}
+}
+
 // strip a leading v off the string
 func stripV(s string) string {
 	if len(s) > 0 && s[0] == 'v' {
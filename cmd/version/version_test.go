@@ -1,14 +1,18 @@
 package version
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/artpar/rclone/cmd"
+	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestVersionWorksWithoutAccessibleConfigFile(t *testing.T) {
@@ -45,3 +49,14 @@ func TestVersionWorksWithoutAccessibleConfigFile(t *testing.T) {
 	// 	assert.NoError(t, cmd.Root.Execute())
 	// })
 }
+
+func TestShowVersionPorcelain(t *testing.T) {
+	var buf bytes.Buffer
+	showVersionPorcelain(&buf)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Equal(t, len(cmd.VersionInfo()), len(lines))
+	assert.Equal(t, "rclone/version\t"+fs.Version, lines[0])
+	for _, line := range lines {
+		assert.Equal(t, 2, len(strings.Split(line, "\t")), line)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"sync"
 	"time"
 	"unicode/utf8"
@@ -15,6 +16,7 @@ import (
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/asyncreader"
 	"github.com/artpar/rclone/fs/fserrors"
+	"golang.org/x/time/rate"
 )
 
 // ErrorMaxTransferLimitReached defines error when transfer limit is reached.
@@ -61,7 +63,8 @@ type Account struct {
 	exit    chan struct{} // channel that will be closed when transfer is finished
 	withBuf bool          // is using a buffered in
 
-	tokenBucket buckets // per file bandwidth limiter (may be nil)
+	tokenBucket buckets       // per file bandwidth limiter (may be nil)
+	ctxLimiter  *rate.Limiter // bandwidth limiter from the context (may be nil)
 
 	values accountValues
 }
@@ -77,7 +80,6 @@ type accountValues struct {
 	avg     float64    // Moving average of last few measurements in bytes/s
 }
 
-const averagePeriod = 16 // period to do exponentially weighted averages over
 
 // newAccountSizeName makes an Account reader for an io.ReadCloser of
 // the given size and name
@@ -101,6 +103,7 @@ func newAccountSizeName(ctx context.Context, stats *StatsInfo, in io.ReadCloser,
 	if acc.ci.CutoffMode == fs.CutoffModeHard {
 		acc.values.max = int64((acc.ci.MaxTransfer))
 	}
+	acc.ctxLimiter = getBwLimit(ctx)
 	currLimit := acc.ci.BwLimitFile.LimitAt(time.Now())
 	if currLimit.Bandwidth.IsSet() {
 		fs.Debugf(acc.name, "Limiting file transfer to %v", currLimit.Bandwidth)
@@ -207,6 +210,8 @@ func (acc *Account) UpdateReader(ctx context.Context, in io.ReadCloser) {
 func (acc *Account) averageLoop() {
 	tick := time.NewTicker(time.Second)
 	var period float64
+	// period to do exponentially weighted averages over
+	averagePeriod := math.Max(1, acc.ci.StatsAverageWindow.Seconds())
 	defer tick.Stop()
 	for {
 		select {
@@ -314,6 +319,26 @@ func (acc *Account) limitPerFileBandwidth(n int) {
 	}
 }
 
+// Account for n bytes from the bandwidth limit from the context (if any)
+func (acc *Account) limitContextBandwidth(n int) {
+	if acc.ctxLimiter == nil {
+		return
+	}
+	// WriteTo may account for more than the burst size at once
+	for n > 0 {
+		chunk := n
+		if chunk > maxBurstSize {
+			chunk = maxBurstSize
+		}
+		err := acc.ctxLimiter.WaitN(context.Background(), chunk)
+		if err != nil {
+			fs.Errorf(nil, "Token bucket error: %v", err)
+			return
+		}
+		n -= chunk
+	}
+}
+
 // Account the read and limit bandwidth
 func (acc *Account) accountRead(n int) {
 	// Update Stats
@@ -326,6 +351,7 @@ func (acc *Account) accountRead(n int) {
 
 	TokenBucket.LimitBandwidth(TokenBucketSlotAccounting, n)
 	acc.limitPerFileBandwidth(n)
+	acc.limitContextBandwidth(n)
 }
 
 // read bytes from the io.Reader passed in and account them
@@ -461,6 +487,12 @@ func (acc *Account) eta() (etaDuration time.Duration, ok bool) {
 	if acc == nil {
 		return 0, false
 	}
+	if acc.ci.StatsAverageWindow <= 0 {
+		bps, _ := acc.speed()
+		acc.values.mu.Lock()
+		defer acc.values.mu.Unlock()
+		return eta(acc.values.bytes, acc.size, bps)
+	}
 	acc.values.mu.Lock()
 	defer acc.values.mu.Unlock()
 	return eta(acc.values.bytes, acc.size, acc.values.avg)
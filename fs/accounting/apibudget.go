@@ -0,0 +1,129 @@
+package accounting
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/rc"
+)
+
+// apiBudgetThresholds are the fractions of the budget at which
+// warnings are logged
+var apiBudgetThresholds = []float64{0.8, 0.9, 1.0}
+
+// APIBudget is the API call usage of a remote with an api_budget set
+type APIBudget struct {
+	Remote string `json:"remote"` // name of the remote
+	Budget int64  `json:"budget"` // daily budget of calls
+	Day    string `json:"day"`    // the day (UTC) the calls were made in YYYY-MM-DD
+	Calls  int64  `json:"calls"`  // the number of calls made in Day
+	warned int    // the number of apiBudgetThresholds passed
+}
+
+// apiBudgets tracks the API calls for each remote
+type apiBudgets struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	remotes map[string]*APIBudget
+}
+
+var budgets = newAPIBudgets()
+
+func newAPIBudgets() *apiBudgets {
+	return &apiBudgets{
+		now:     time.Now,
+		remotes: map[string]*APIBudget{},
+	}
+}
+
+func init() {
+	// Set the function pointer up in fs
+	fs.CountAPICall = budgets.count
+}
+
+// count records an API call for remote with a daily budget, logging
+// when the thresholds are passed
+func (b *apiBudgets) count(remote string, budget int64) {
+	day := b.now().UTC().Format("2006-01-02")
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	u := b.remotes[remote]
+	if u == nil || u.Day != day {
+		u = &APIBudget{Remote: remote, Day: day}
+		b.remotes[remote] = u
+	}
+	u.Budget = budget
+	u.Calls++
+	for u.warned < len(apiBudgetThresholds) && float64(u.Calls) > apiBudgetThresholds[u.warned]*float64(budget) {
+		threshold := apiBudgetThresholds[u.warned]
+		u.warned++
+		if threshold >= 1 {
+			fs.Errorf(remote+":", "API call budget exceeded: %d calls made today with a budget of %d", u.Calls, budget)
+		} else {
+			fs.Logf(remote+":", "Over %.0f%% of the API call budget used: %d calls made today with a budget of %d", threshold*100, u.Calls, budget)
+		}
+	}
+}
+
+// list returns a copy of the usage for all the remotes sorted by
+// remote name
+func (b *apiBudgets) list() []APIBudget {
+	day := b.now().UTC().Format("2006-01-02")
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]APIBudget, 0, len(b.remotes))
+	for _, u := range b.remotes {
+		u := *u
+		if u.Day != day {
+			u.Day, u.Calls = day, 0
+		}
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Remote < out[j].Remote
+	})
+	return out
+}
+
+// APIBudgets returns the API call usage for each remote with an
+// api_budget set which has made calls
+func APIBudgets() []APIBudget {
+	return budgets.list()
+}
+
+func rcAPIBudget(ctx context.Context, in rc.Params) (rc.Params, error) {
+	return rc.Params{
+		"remotes": APIBudgets(),
+	}, nil
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "core/api-budget",
+		Fn:    rcAPIBudget,
+		Title: "Returns the API calls made by remotes with an api_budget.",
+		Help: `
+This returns the number of API calls made today (UTC) by each remote
+with the api_budget config option set.
+
+Returns the following values:
+` + "```" + `
+{
+	"remotes": [
+		{
+			"remote": "name of the remote",
+			"budget": daily budget of calls,
+			"day": "the day the calls were made in YYYY-MM-DD",
+			"calls": number of calls made today
+		},
+		...
+	]
+}
+` + "```" + `
+The counts are kept in memory so are only for this rclone process.
+`,
+	})
+}
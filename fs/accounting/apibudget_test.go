@@ -0,0 +1,75 @@
+package accounting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIBudgetCount(t *testing.T) {
+	b := newAPIBudgets()
+	now := time.Date(2021, 3, 4, 23, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	assert.Equal(t, []APIBudget{}, b.list())
+
+	for i := 0; i < 9; i++ {
+		b.count("remote", 10)
+	}
+	b.count("another", 100)
+	got := b.list()
+	require.Equal(t, 2, len(got))
+	assert.Equal(t, "another", got[0].Remote)
+	assert.Equal(t, int64(1), got[0].Calls)
+	assert.Equal(t, "remote", got[1].Remote)
+	assert.Equal(t, int64(9), got[1].Calls)
+	assert.Equal(t, int64(10), got[1].Budget)
+	assert.Equal(t, "2021-03-04", got[1].Day)
+	assert.Equal(t, 1, b.remotes["remote"].warned) // passed 80%
+
+	b.count("remote", 10)
+	assert.Equal(t, 2, b.remotes["remote"].warned) // passed 90%
+	b.count("remote", 10)
+	assert.Equal(t, 3, b.remotes["remote"].warned) // exceeded
+
+	// Check the counts reset the next day
+	now = now.Add(2 * time.Hour)
+	got = b.list()
+	assert.Equal(t, int64(0), got[1].Calls)
+	assert.Equal(t, "2021-03-05", got[1].Day)
+	b.count("remote", 10)
+	assert.Equal(t, int64(1), b.remotes["remote"].Calls)
+	assert.Equal(t, 0, b.remotes["remote"].warned)
+}
+
+func TestAPIBudgetPrometheus(t *testing.T) {
+	oldBudgets := budgets
+	defer func() {
+		budgets = oldBudgets
+	}()
+	budgets = newAPIBudgets()
+	budgets.count("remote", 1000)
+	budgets.count("remote", 1000)
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(NewRcloneCollector(context.Background())))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	found := map[string]float64{}
+	for _, family := range families {
+		switch family.GetName() {
+		case namespace + "api_calls", namespace + "api_budget":
+			metric := family.GetMetric()[0]
+			assert.Equal(t, "remote", metric.GetLabel()[0].GetValue())
+			found[family.GetName()] = metric.GetGauge().GetValue()
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		namespace + "api_calls":  2,
+		namespace + "api_budget": 1000,
+	}, found)
+}
@@ -2,12 +2,31 @@ package accounting
 
 import (
 	"context"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 var namespace = "rclone_"
 
+// transferSpeeds is a histogram of the speeds of completed transfers.
+//
+// The buckets go from 1 KiB/s to 4 GiB/s in multiples of 4.
+var transferSpeeds = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Name:    namespace + "transfer_speed_bytes_per_second",
+	Help:    "Average speed in bytes/sec of each completed transfer",
+	Buckets: prometheus.ExponentialBuckets(1024, 4, 12),
+})
+
+// observeTransferSpeed records a completed transfer of bytes which
+// took duration in the transferSpeeds histogram
+func observeTransferSpeed(bytes int64, duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+	transferSpeeds.Observe(float64(bytes) / duration.Seconds())
+}
+
 // RcloneCollector is a Prometheus collector for Rclone
 type RcloneCollector struct {
 	ctx              context.Context
@@ -21,6 +40,8 @@ type RcloneCollector struct {
 	renames          *prometheus.Desc
 	fatalError       *prometheus.Desc
 	retryError       *prometheus.Desc
+	apiCalls         *prometheus.Desc
+	apiBudget        *prometheus.Desc
 }
 
 // NewRcloneCollector make a new RcloneCollector
@@ -67,6 +88,14 @@ func NewRcloneCollector(ctx context.Context) *RcloneCollector {
 			"Whether there has been an error that will be retried",
 			nil, nil,
 		),
+		apiCalls: prometheus.NewDesc(namespace+"api_calls",
+			"Number of API calls made today (UTC) by remotes with an api_budget",
+			[]string{"remote"}, nil,
+		),
+		apiBudget: prometheus.NewDesc(namespace+"api_budget",
+			"Daily budget of API calls for remotes with an api_budget",
+			[]string{"remote"}, nil,
+		),
 	}
 }
 
@@ -82,6 +111,9 @@ func (c *RcloneCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.renames
 	ch <- c.fatalError
 	ch <- c.retryError
+	ch <- c.apiCalls
+	ch <- c.apiBudget
+	transferSpeeds.Describe(ch)
 }
 
 // Collect is part of the Collector interface: https://godoc.org/github.com/prometheus/client_golang/prometheus#Collector
@@ -101,6 +133,13 @@ func (c *RcloneCollector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.retryError, prometheus.GaugeValue, bool2Float(s.retryError))
 
 	s.mu.RUnlock()
+
+	for _, u := range APIBudgets() {
+		ch <- prometheus.MustNewConstMetric(c.apiCalls, prometheus.GaugeValue, float64(u.Calls), u.Remote)
+		ch <- prometheus.MustNewConstMetric(c.apiBudget, prometheus.GaugeValue, float64(u.Budget), u.Remote)
+	}
+
+	transferSpeeds.Collect(ch)
 }
 
 // bool2Float is a small function to convert a boolean into a float64 value that can be used for Prometheus
@@ -0,0 +1,32 @@
+package accounting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferSpeedHistogram(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(NewRcloneCollector(context.Background())))
+	count := func() uint64 {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		for _, family := range families {
+			if family.GetName() == namespace+"transfer_speed_bytes_per_second" {
+				return family.GetMetric()[0].GetHistogram().GetSampleCount()
+			}
+		}
+		t.Fatal("histogram not found")
+		return 0
+	}
+	before := count()
+	observeTransferSpeed(1024, 0)
+	assert.Equal(t, before, count(), "zero duration should be ignored")
+	observeTransferSpeed(1024, time.Second)
+	assert.Equal(t, before+1, count())
+}
@@ -0,0 +1,130 @@
+package accounting
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+)
+
+const (
+	// maxRetriedFiles is the number of files the retry history is kept for
+	maxRetriedFiles = 100
+	// maxRetryReasons is the number of reasons kept for each file
+	maxRetryReasons = 5
+)
+
+// FileRetries is the low level retry history of a single file
+type FileRetries struct {
+	Name    string   `json:"name"`
+	Retries int64    `json:"retries"`
+	Reasons []string `json:"reasons"` // errors causing the most recent retries, oldest first
+}
+
+// addReason records a retry of the file because of err
+func (fr *FileRetries) addReason(err error) {
+	fr.Retries++
+	reason := "unknown error"
+	if err != nil {
+		reason = err.Error()
+	}
+	fr.Reasons = append(fr.Reasons, reason)
+	if len(fr.Reasons) > maxRetryReasons {
+		fr.Reasons = fr.Reasons[len(fr.Reasons)-maxRetryReasons:]
+	}
+}
+
+// String formats the history on one line
+func (fr *FileRetries) String() string {
+	return fmt.Sprintf("%s: %d retries: %s", fr.Name, fr.Retries, strings.Join(fr.Reasons, "; "))
+}
+
+// Retry records that a low level retry of the transfer was needed
+// because of err
+func (tr *Transfer) Retry(err error) {
+	tr.mu.Lock()
+	tr.retries++
+	firstRetry := tr.retries == 1
+	tr.mu.Unlock()
+	tr.stats.retry(tr.remote, firstRetry, err)
+}
+
+// GetRetries returns the number of low level retries of the transfer
+func (tr *Transfer) GetRetries() int64 {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return tr.retries
+}
+
+// retry records a low level retry of remote because of err
+//
+// firstRetry should be set if this is the first retry of this file
+func (s *StatsInfo) retry(remote string, firstRetry bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries++
+	if firstRetry {
+		s.retriedFiles++
+	}
+	fr := s.retried[remote]
+	if fr == nil {
+		if len(s.retried) >= maxRetriedFiles {
+			return
+		}
+		if s.retried == nil {
+			s.retried = make(map[string]*FileRetries)
+		}
+		fr = &FileRetries{Name: remote}
+		s.retried[remote] = fr
+		s.retriedOrder = append(s.retriedOrder, remote)
+	}
+	fr.addReason(err)
+}
+
+// GetRetries returns the number of low level retries and the number
+// of files which needed them
+func (s *StatsInfo) GetRetries() (retries, files int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.retries, s.retriedFiles
+}
+
+// RetryHistory returns the retry history of the first files which
+// needed low level retries in the order they were first retried.
+//
+// Note only the first 100 files are returned.
+func (s *StatsInfo) RetryHistory() []FileRetries {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s._retryHistory()
+}
+
+// _retryHistory returns the retry history - call with lock held
+func (s *StatsInfo) _retryHistory() []FileRetries {
+	history := make([]FileRetries, 0, len(s.retriedOrder))
+	for _, remote := range s.retriedOrder {
+		fr := *s.retried[remote]
+		fr.Reasons = append([]string(nil), fr.Reasons...)
+		history = append(history, fr)
+	}
+	return history
+}
+
+// LogRetries outputs the retry history, if any, to the log
+func (s *StatsInfo) LogRetries() {
+	retries, files := s.GetRetries()
+	if retries == 0 {
+		return
+	}
+	history := s.RetryHistory()
+	var buf strings.Builder
+	_, _ = fmt.Fprintf(&buf, "%d low level retries were needed for %d files", retries, files)
+	if int64(len(history)) < files {
+		_, _ = fmt.Fprintf(&buf, " - showing the first %d", len(history))
+	}
+	_, _ = buf.WriteString(":\n")
+	for i := range history {
+		_, _ = fmt.Fprintf(&buf, " * %v\n", &history[i])
+	}
+	fs.LogLevelPrintf(s.ci.StatsLogLevel, nil, "%s", buf.String())
+}
@@ -0,0 +1,87 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferRetry(t *testing.T) {
+	ctx := context.Background()
+	s := NewStats(ctx)
+
+	tr1 := newTransferRemoteSize(s, "one", 100, false)
+	tr2 := newTransferRemoteSize(s, "two", 100, false)
+	for i := 0; i < maxRetryReasons+2; i++ {
+		tr1.Retry(errors.Errorf("error %d", i))
+	}
+	tr2.Retry(errors.New("boom"))
+	tr2.Retry(nil)
+
+	assert.Equal(t, int64(maxRetryReasons+2), tr1.GetRetries())
+	assert.Equal(t, int64(2), tr2.GetRetries())
+	retries, files := s.GetRetries()
+	assert.Equal(t, int64(maxRetryReasons+4), retries)
+	assert.Equal(t, int64(2), files)
+
+	history := s.RetryHistory()
+	require.Len(t, history, 2)
+	assert.Equal(t, "one", history[0].Name)
+	assert.Equal(t, int64(maxRetryReasons+2), history[0].Retries)
+	assert.Equal(t, []string{"error 2", "error 3", "error 4", "error 5", "error 6"}, history[0].Reasons)
+	assert.Equal(t, FileRetries{Name: "two", Retries: 2, Reasons: []string{"boom", "unknown error"}}, history[1])
+	assert.Equal(t, "two: 2 retries: boom; unknown error", history[1].String())
+
+	out, err := s.RemoteStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxRetryReasons+4), out["retries"])
+	assert.Equal(t, history, out["retried"])
+	assert.Contains(t, s.String(), "Retries:                9 (2 files)")
+
+	tr2.Done(ctx, nil)
+	assert.Equal(t, int64(2), tr2.Snapshot().Retries)
+
+	s.ResetCounters()
+	retries, files = s.GetRetries()
+	assert.Equal(t, int64(0), retries)
+	assert.Equal(t, int64(0), files)
+	assert.Len(t, s.RetryHistory(), 0)
+	out, err = s.RemoteStats()
+	require.NoError(t, err)
+	assert.Nil(t, out["retried"])
+}
+
+func TestRetryHistoryLimit(t *testing.T) {
+	s := NewStats(context.Background())
+	for i := 0; i < maxRetriedFiles+10; i++ {
+		tr := newTransferRemoteSize(s, fmt.Sprintf("file%d", i), 100, false)
+		tr.Retry(errors.New("boom"))
+	}
+	retries, files := s.GetRetries()
+	assert.Equal(t, int64(maxRetriedFiles+10), retries)
+	assert.Equal(t, int64(maxRetriedFiles+10), files)
+	history := s.RetryHistory()
+	assert.Len(t, history, maxRetriedFiles)
+	assert.Equal(t, "file0", history[0].Name)
+	s.LogRetries()
+}
+
+func TestRetryStatsGroupsSum(t *testing.T) {
+	ctx := context.Background()
+	sg := newStatsGroups()
+	s1, s2 := NewStats(ctx), NewStats(ctx)
+	sg.set(ctx, "group1", s1)
+	sg.set(ctx, "group2", s2)
+	newTransferRemoteSize(s1, "a", 1, false).Retry(errors.New("one"))
+	newTransferRemoteSize(s2, "b", 1, false).Retry(errors.New("two"))
+
+	sum := sg.sum(ctx)
+	retries, files := sum.GetRetries()
+	assert.Equal(t, int64(2), retries)
+	assert.Equal(t, int64(2), files)
+	assert.Len(t, sum.RetryHistory(), 2)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"sync"
@@ -49,6 +50,11 @@ type StatsInfo struct {
 	oldTimeRanges     timeRanges    // a merged list of time ranges for the transfers
 	oldDuration       time.Duration // duration of transfers we have culled
 	group             string
+	average           averageSpeed            // recent speed used for the ETA
+	retries           int64                   // number of low level retries of transfers
+	retriedFiles      int64                   // number of files which needed low level retries
+	retried           map[string]*FileRetries // retry history of the first maxRetriedFiles files retried
+	retriedOrder      []string                // the keys of retried in the order they were added
 }
 
 // NewStats creates an initialised StatsInfo
@@ -87,8 +93,12 @@ func (s *StatsInfo) RemoteStats() (out rc.Params, err error) {
 	out["deletes"] = s.deletes
 	out["deletedDirs"] = s.deletedDirs
 	out["renames"] = s.renames
+	out["retries"] = s.retries
+	if len(s.retriedOrder) > 0 {
+		out["retried"] = s._retryHistory()
+	}
 	out["elapsedTime"] = time.Since(startTime).Seconds()
-	eta, etaOK := eta(s.bytes, ts.totalBytes, ts.speed)
+	eta, etaOK := eta(s.bytes, ts.totalBytes, ts.etaSpeed)
 	if etaOK {
 		out["eta"] = eta.Seconds()
 	} else {
@@ -256,6 +266,7 @@ type transferStats struct {
 	totalBytes     int64
 	transferTime   float64
 	speed          float64
+	etaSpeed       float64 // speed to calculate the ETA with
 }
 
 // calculateTransferStats calculates some addtional transfer stats not
@@ -281,10 +292,68 @@ func (s *StatsInfo) calculateTransferStats() (ts transferStats) {
 	if dt > 0 {
 		ts.speed = float64(s.bytes) / ts.transferTime
 	}
+	ts.etaSpeed = ts.speed
+	if s.ci.StatsAverageWindow > 0 {
+		if speed, ok := s.average.update(time.Now(), s.bytes, s.ci.StatsAverageWindow); ok {
+			ts.etaSpeed = speed
+		}
+	}
 
 	return ts
 }
 
+// averageSpeed is an exponentially weighted moving average of the
+// transfer speed over a time window.
+//
+// It is updated whenever the stats are read so the samples aren't
+// evenly spaced - the weight of each sample depends on how long it
+// covers.
+type averageSpeed struct {
+	mu        sync.Mutex
+	lpTime    time.Time // time of the last sample
+	lpBytes   int64     // bytes transferred at the last sample
+	speed     float64   // moving average in bytes/s
+	haveSpeed bool      // set if speed is valid
+}
+
+// minAverageSample is the shortest time a sample may cover
+const minAverageSample = 100 * time.Millisecond
+
+// reset the average so it starts again from the next sample
+func (a *averageSpeed) reset() {
+	a.mu.Lock()
+	a.lpTime, a.lpBytes, a.speed, a.haveSpeed = time.Time{}, 0, 0, false
+	a.mu.Unlock()
+}
+
+// update adds bytes, the total transferred at now, into the average
+// with the given window.
+//
+// It returns the average and whether it is valid yet.
+func (a *averageSpeed) update(now time.Time, bytes int64, window time.Duration) (speed float64, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.lpTime.IsZero() || bytes < a.lpBytes {
+		// first sample or counters reset
+		a.lpTime, a.lpBytes, a.speed, a.haveSpeed = now, bytes, 0, false
+		return 0, false
+	}
+	dt := now.Sub(a.lpTime)
+	if dt < minAverageSample {
+		return a.speed, a.haveSpeed
+	}
+	current := float64(bytes-a.lpBytes) / dt.Seconds()
+	if a.haveSpeed {
+		alpha := 1 - math.Exp(-dt.Seconds()/window.Seconds())
+		a.speed += alpha * (current - a.speed)
+	} else {
+		a.speed = current
+		a.haveSpeed = true
+	}
+	a.lpTime, a.lpBytes = now, bytes
+	return a.speed, true
+}
+
 // String convert the StatsInfo to a string for printing
 func (s *StatsInfo) String() string {
 	// NB if adding more stats in here, remember to add them into
@@ -333,13 +402,13 @@ func (s *StatsInfo) String() string {
 		fs.SizeSuffix(ts.totalBytes).Unit("Bytes"),
 		percent(s.bytes, ts.totalBytes),
 		fs.SizeSuffix(displaySpeed).Unit(strings.Title(s.ci.DataRateUnit)+"/s"),
-		etaString(s.bytes, ts.totalBytes, ts.speed),
+		etaString(s.bytes, ts.totalBytes, ts.etaSpeed),
 		xfrchkString,
 	)
 
 	if s.ci.ProgressTerminalTitle {
 		// Writes ETA to the terminal title
-		terminal.WriteTerminalTitle("ETA: " + etaString(s.bytes, ts.totalBytes, ts.speed))
+		terminal.WriteTerminalTitle("ETA: " + etaString(s.bytes, ts.totalBytes, ts.etaSpeed))
 	}
 
 	if !s.ci.StatsOneLine {
@@ -360,6 +429,9 @@ func (s *StatsInfo) String() string {
 			_, _ = fmt.Fprintf(buf, "Errors:        %10d%s\n",
 				s.errors, errorDetails)
 		}
+		if s.retries != 0 {
+			_, _ = fmt.Fprintf(buf, "Retries:       %10d (%d files)\n", s.retries, s.retriedFiles)
+		}
 		if s.checks != 0 || ts.totalChecks != 0 {
 			_, _ = fmt.Fprintf(buf, "Checks:        %10d / %d, %s\n",
 				s.checks, ts.totalChecks, percent(s.checks, ts.totalChecks))
@@ -548,6 +620,11 @@ func (s *StatsInfo) ResetCounters() {
 	s.renames = 0
 	s.startedTransfers = nil
 	s.oldDuration = 0
+	s.average.reset()
+	s.retries = 0
+	s.retriedFiles = 0
+	s.retried = nil
+	s.retriedOrder = nil
 }
 
 // ResetErrors sets the errors count to 0 and resets lastError, fatalError and retryError
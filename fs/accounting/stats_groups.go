@@ -96,6 +96,15 @@ Returns the following values:
 	"lastError": last error string,
 	"renames" : number of files renamed,
 	"retryError": boolean showing whether there has been at least one non-NoRetryError,
+	"retries": number of low level retries of file transfers,
+	"retried": an array of the files which needed low level retries (only the first 100):
+		[
+			{
+				"name": name of the file,
+				"retries": number of low level retries,
+				"reasons": array of the errors causing the last 5 retries, oldest first
+			}
+		],
 	"speed": average speed in bytes/sec since start of the group,
 	"totalBytes": total number of bytes in the group,
 	"totalChecks": total number of checks in the group,
@@ -109,6 +118,7 @@ Returns the following values:
 				"eta": estimated time in seconds until file transfer completion
 				"name": name of the file,
 				"percentage": progress of the file transfer in percent,
+				"retries": number of low level retries of the file so far,
 				"speed": average speed over the whole transfer in bytes/sec,
 				"speedAvg": current speed in bytes/sec as an exponentially weighted moving average,
 				"size": size of the file in bytes
@@ -118,7 +128,7 @@ Returns the following values:
 		[]
 }
 ` + "```" + `
-Values for "transferring", "checking" and "lastError" are only assigned if data is available.
+Values for "transferring", "checking", "retried" and "lastError" are only assigned if data is available.
 The value for "eta" is null if an eta cannot be determined.
 `,
 	})
@@ -172,6 +182,7 @@ Returns the following values:
 				"checked": if the transfer is only checked (skipped, deleted),
 				"timestamp": integer representing millisecond unix epoch,
 				"error": string description of the error (empty if successful),
+				"retries": number of low level retries needed,
 				"jobid": id of the job that this transfer belongs to
 			}
 		]
@@ -365,6 +376,19 @@ func (sg *statsGroups) sum(ctx context.Context) *StatsInfo {
 			sum.deletes += stats.deletes
 			sum.deletedDirs += stats.deletedDirs
 			sum.renames += stats.renames
+			sum.retries += stats.retries
+			sum.retriedFiles += stats.retriedFiles
+			for _, remote := range stats.retriedOrder {
+				if _, found := sum.retried[remote]; !found && len(sum.retriedOrder) < maxRetriedFiles {
+					if sum.retried == nil {
+						sum.retried = make(map[string]*FileRetries)
+					}
+					fr := *stats.retried[remote]
+					fr.Reasons = append([]string(nil), fr.Reasons...)
+					sum.retried[remote] = &fr
+					sum.retriedOrder = append(sum.retriedOrder, remote)
+				}
+			}
 			sum.checking.merge(stats.checking)
 			sum.transferring.merge(stats.transferring)
 			sum.inProgress.merge(stats.inProgress)
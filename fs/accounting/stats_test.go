@@ -53,6 +53,48 @@ func TestETA(t *testing.T) {
 	}
 }
 
+func TestAverageSpeed(t *testing.T) {
+	const window = 10 * time.Second
+	var a averageSpeed
+	t0 := time.Now()
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+
+	// First sample isn't valid
+	speed, ok := a.update(at(0), 0, window)
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, speed)
+
+	// Samples too close together are ignored
+	speed, ok = a.update(at(time.Millisecond), 1000, window)
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, speed)
+
+	// Second sample gives the speed directly - slow start
+	speed, ok = a.update(at(10*time.Second), 1000, window)
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, speed)
+
+	// The speed picks up, the average follows it within a few windows
+	bytes := int64(1000)
+	for i := 1; i <= 6; i++ {
+		bytes += 10000
+		speed, ok = a.update(at(time.Duration(10+10*i)*time.Second), bytes, window)
+		assert.True(t, ok)
+	}
+	assert.InDelta(t, 1000.0, speed, 5)
+
+	// Resetting counters starts again
+	speed, ok = a.update(at(90*time.Second), 0, window)
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, speed)
+	speed, ok = a.update(at(100*time.Second), 500, window)
+	assert.True(t, ok)
+	assert.Equal(t, 50.0, speed)
+	a.reset()
+	_, ok = a.update(at(110*time.Second), 1000, window)
+	assert.False(t, ok)
+}
+
 func TestPercentage(t *testing.T) {
 	assert.Equal(t, percent(0, 1000), "0%")
 	assert.Equal(t, percent(1, 1000), "0%")
@@ -166,6 +166,36 @@ func (tb *tokenBucket) LimitBandwidth(i TokenBucketSlot, n int) {
 	tb.mu.RUnlock()
 }
 
+// bwLimitKey is the context key for the limiter set by WithBwLimit
+type bwLimitKey struct{}
+
+// WithBwLimit returns a copy of ctx with a bandwidth limit which is
+// shared by all the transfers made with it. This is applied as well as
+// the global and per file limits and is used to give an rc job its
+// own limit.
+//
+// The limit is the larger of the upload and download bandwidth as the
+// transfers aren't split by direction.
+func WithBwLimit(ctx context.Context, bandwidth fs.BwPair) context.Context {
+	if !bandwidth.IsSet() {
+		return ctx
+	}
+	limit := bandwidth.Tx
+	if bandwidth.Rx > limit {
+		limit = bandwidth.Rx
+	}
+	tb := rate.NewLimiter(rate.Limit(limit), maxBurstSize)
+	// empty the bucket
+	tb.AllowN(time.Now(), maxBurstSize)
+	return context.WithValue(ctx, bwLimitKey{}, tb)
+}
+
+// getBwLimit returns the limiter set by WithBwLimit or nil
+func getBwLimit(ctx context.Context) *rate.Limiter {
+	tb, _ := ctx.Value(bwLimitKey{}).(*rate.Limiter)
+	return tb
+}
+
 // SetBwLimit sets the current bandwidth limit
 func (tb *tokenBucket) SetBwLimit(bandwidth fs.BwPair) {
 	tb.mu.Lock()
@@ -1,9 +1,12 @@
 package accounting
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
 	"testing"
 
+	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/rc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -90,3 +93,25 @@ func TestRcBwLimit(t *testing.T) {
 	}, out)
 
 }
+
+func TestWithBwLimit(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, getBwLimit(ctx))
+
+	// Not set so ctx is returned unchanged
+	assert.Equal(t, ctx, WithBwLimit(ctx, fs.BwPair{Tx: -1, Rx: -1}))
+
+	// Uses the larger of the limits
+	tb := getBwLimit(WithBwLimit(ctx, fs.BwPair{Tx: fs.MebiByte, Rx: 2 * fs.MebiByte}))
+	require.NotNil(t, tb)
+	assert.Equal(t, rate.Limit(2*fs.MebiByte), tb.Limit())
+	tb = getBwLimit(WithBwLimit(ctx, fs.BwPair{Tx: fs.MebiByte, Rx: -1}))
+	require.NotNil(t, tb)
+	assert.Equal(t, rate.Limit(fs.MebiByte), tb.Limit())
+
+	// Accounts pick the limit up from the context
+	ctx = WithBwLimit(ctx, fs.BwPair{Tx: fs.MebiByte, Rx: fs.MebiByte})
+	acc := newAccountSizeName(ctx, NewStats(ctx), ioutil.NopCloser(bytes.NewBuffer(nil)), 0, "test")
+	assert.Equal(t, getBwLimit(ctx), acc.ctxLimiter)
+	require.NoError(t, acc.Close())
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/rc"
+	"github.com/artpar/rclone/fs/tracing"
 )
 
 // TransferSnapshot represents state of an account at point in time.
@@ -21,6 +22,7 @@ type TransferSnapshot struct {
 	CompletedAt time.Time `json:"completed_at,omitempty"`
 	Error       error     `json:"-"`
 	Group       string    `json:"group"`
+	Retries     int64     `json:"retries"`
 }
 
 // MarshalJSON implements json.Marshaler interface.
@@ -50,6 +52,7 @@ type Transfer struct {
 	size      int64
 	startedAt time.Time
 	checking  bool
+	span      *tracing.Span // nil if not tracing
 
 	// Protects all below
 	//
@@ -60,6 +63,7 @@ type Transfer struct {
 	acc         *Account
 	err         error
 	completedAt time.Time
+	retries     int64 // number of low level retries
 }
 
 // newCheckingTransfer instantiates new checking of the object.
@@ -80,6 +84,11 @@ func newTransferRemoteSize(stats *StatsInfo, remote string, size int64, checking
 		startedAt: time.Now(),
 		checking:  checking,
 	}
+	if !checking {
+		_, tr.span = tracing.Start(stats.ctx, "transfer")
+		tr.span.SetAttribute("remote", remote)
+		tr.span.SetAttribute("size", size)
+	}
 	stats.AddTransfer(tr)
 	return tr
 }
@@ -100,7 +109,9 @@ func (tr *Transfer) Done(ctx context.Context, err error) {
 	tr.mu.RUnlock()
 
 	ci := fs.GetConfig(ctx)
+	var bytes int64
 	if acc != nil {
+		bytes, _ = acc.progress()
 		// Close the file if it is still open
 		if err := acc.Close(); err != nil {
 			fs.LogLevelPrintf(ci.StatsLogLevel, nil, "can't close account: %+v\n", err)
@@ -115,6 +126,14 @@ func (tr *Transfer) Done(ctx context.Context, err error) {
 	tr.completedAt = time.Now()
 	tr.mu.Unlock()
 
+	if !tr.checking {
+		tr.span.SetAttribute("bytes", bytes)
+		tr.span.End(err)
+		if err == nil && bytes > 0 {
+			observeTransferSpeed(bytes, tr.completedAt.Sub(tr.startedAt))
+		}
+	}
+
 	if tr.checking {
 		tr.stats.DoneChecking(tr.remote)
 	} else {
@@ -183,6 +202,7 @@ func (tr *Transfer) Snapshot() TransferSnapshot {
 		CompletedAt: tr.completedAt,
 		Error:       tr.err,
 		Group:       tr.stats.group,
+		Retries:     tr.retries,
 	}
 }
 
@@ -149,11 +149,14 @@ func (tm *transferMap) rcStats(progress *inProgress) (t []rc.Params) {
 	tm.mu.RLock()
 	defer tm.mu.RUnlock()
 	for _, tr := range tm._sortedSlice() {
+		var out rc.Params
 		if acc := progress.get(tr.remote); acc != nil {
-			t = append(t, acc.rcStats())
+			out = acc.rcStats()
 		} else {
-			t = append(t, tr.rcStats())
+			out = tr.rcStats()
 		}
+		out["retries"] = tr.GetRetries()
+		t = append(t, out)
 	}
 	return t
 }
@@ -0,0 +1,75 @@
+package fs
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/artpar/rclone/lib/pacer"
+	"github.com/pkg/errors"
+)
+
+// ConfigAPIBudget is the config key for the per remote daily API call
+// budget
+const ConfigAPIBudget = "api_budget"
+
+// APIBudgetOption is the option for the per remote daily API call
+// budget. Backends which make their API calls through a Pacer should
+// add it to their options.
+var APIBudgetOption = Option{
+	Name: ConfigAPIBudget,
+	Help: `Daily budget of API calls for this remote
+
+If this is set then rclone counts the API calls made through the
+pacer for this remote each day (UTC) and logs a warning when 80% and
+90% of the budget have been used and an error when it is exceeded.
+Calls are not blocked when the budget is exceeded.
+
+The counts can be read with the core/api-budget rc call and the
+rclone_api_calls metric. Set to 0 to disable.`,
+	Default:  0,
+	Advanced: true,
+}
+
+// CountAPICall is called by the Pacer for each API call made for the
+// remote with a daily API call budget of budget.
+//
+// This is overridden by the accounting module.
+var CountAPICall = func(remote string, budget int64) {}
+
+// apiBudgetKey is the context key for apiBudget
+type apiBudgetKey struct{}
+
+// apiBudget is the API call budget for a remote
+type apiBudget struct {
+	remote string
+	budget int64
+}
+
+// parseAPIBudget parses the api_budget config value
+func parseAPIBudget(value string) (int64, error) {
+	budget, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || budget < 0 {
+		return 0, errors.Errorf("invalid %s %q: must be a number of calls >= 0", ConfigAPIBudget, value)
+	}
+	return budget, nil
+}
+
+// withAPIBudget returns a context which makes any Pacer created with
+// it count its calls against budget for remote
+func withAPIBudget(ctx context.Context, remote string, budget int64) context.Context {
+	return context.WithValue(ctx, apiBudgetKey{}, apiBudget{remote: remote, budget: budget})
+}
+
+// apiBudgetInvoker returns a pacer invoker which counts each call
+// against the budget in ctx before calling invoker, or invoker if
+// there isn't one
+func apiBudgetInvoker(ctx context.Context, invoker pacer.InvokerFunc) pacer.InvokerFunc {
+	b, ok := ctx.Value(apiBudgetKey{}).(apiBudget)
+	if !ok {
+		return invoker
+	}
+	return func(try, tries int, f pacer.Paced) (bool, error) {
+		CountAPICall(b.remote, b.budget)
+		return invoker(try, tries, f)
+	}
+}
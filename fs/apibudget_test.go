@@ -0,0 +1,93 @@
+package fs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artpar/rclone/fs/config/configmap"
+	"github.com/artpar/rclone/lib/pacer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIBudget(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"100000", 100000, false},
+		{"-1", 0, true},
+		{"potato", 0, true},
+	} {
+		got, err := parseAPIBudget(test.in)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.want, got, test.in)
+	}
+}
+
+func TestNewFsAPIBudget(t *testing.T) {
+	var gotBudget apiBudget
+	newFs := func(ctx context.Context, name, root string, m configmap.Mapper) (Fs, error) {
+		gotBudget, _ = ctx.Value(apiBudgetKey{}).(apiBudget)
+		return nil, ErrorNotImplemented
+	}
+	Register(&RegInfo{Name: "budgettest", NewFs: newFs, Options: Options{APIBudgetOption}})
+	Register(&RegInfo{Name: "nobudgettest", NewFs: newFs})
+
+	_, _ = NewFs(context.Background(), ":budgettest,api_budget=100:")
+	assert.Equal(t, apiBudget{remote: ":budgettest", budget: 100}, gotBudget)
+
+	// Backends without the option ignore it
+	gotBudget = apiBudget{}
+	_, _ = NewFs(context.Background(), ":nobudgettest,api_budget=100:")
+	assert.Equal(t, apiBudget{}, gotBudget)
+
+	_, err := NewFs(context.Background(), ":budgettest,api_budget=potato:")
+	assert.Error(t, err)
+}
+
+func TestAPIBudgetInvoker(t *testing.T) {
+	oldCountAPICall := CountAPICall
+	defer func() {
+		CountAPICall = oldCountAPICall
+	}()
+	var calls []apiBudget
+	CountAPICall = func(remote string, budget int64) {
+		calls = append(calls, apiBudget{remote: remote, budget: budget})
+	}
+	invoked := 0
+	invoker := func(try, tries int, f pacer.Paced) (bool, error) {
+		invoked++
+		return f()
+	}
+	paced := func() (bool, error) {
+		return false, nil
+	}
+
+	// no budget in the context so no counting
+	_, err := apiBudgetInvoker(context.Background(), invoker)(1, 1, paced)
+	require.NoError(t, err)
+	assert.Equal(t, 1, invoked)
+	assert.Equal(t, 0, len(calls))
+
+	ctx := withAPIBudget(context.Background(), "remote", 1000)
+	wrapped := apiBudgetInvoker(ctx, invoker)
+	for i := 0; i < 3; i++ {
+		_, err = wrapped(1, 1, paced)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 4, invoked)
+	assert.Equal(t, []apiBudget{{"remote", 1000}, {"remote", 1000}, {"remote", 1000}}, calls)
+
+	// Check a Pacer made with the context counts calls
+	calls = nil
+	p := NewPacer(ctx, pacer.NewDefault())
+	require.NoError(t, p.Call(paced))
+	assert.Equal(t, 1, len(calls))
+}
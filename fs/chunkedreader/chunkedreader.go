@@ -8,6 +8,7 @@ import (
 
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/hash"
+	"github.com/artpar/rclone/fs/tracing"
 )
 
 // io related errors returned by ChunkedReader
@@ -19,41 +20,76 @@ var (
 // ChunkedReader is a reader for an Object with the possibility
 // of reading the source in chunks of given size
 //
+// The chunk size adapts to the read pattern. It doubles after each
+// chunk which is read sequentially and halves after each seek, keeping
+// between minChunkSize and maxChunkSize.
+//
 // An initialChunkSize of <= 0 will disable chunked reading.
 type ChunkedReader struct {
-	ctx              context.Context
-	mu               sync.Mutex    // protects following fields
-	o                fs.Object     // source to read from
-	rc               io.ReadCloser // reader for the current open chunk
-	offset           int64         // offset the next Read will start. -1 forces a reopen of o
-	chunkOffset      int64         // beginning of the current or next chunk
-	chunkSize        int64         // length of the current or next chunk. -1 will open o from chunkOffset to the end
-	initialChunkSize int64         // default chunkSize after the chunk specified by RangeSeek is complete
-	maxChunkSize     int64         // consecutive read chunks will double in size until reached. -1 means no limit
-	customChunkSize  bool          // is the current chunkSize set by RangeSeek?
-	closed           bool          // has Close been called?
+	ctx             context.Context
+	mu              sync.Mutex    // protects following fields
+	o               fs.Object     // source to read from
+	rc              io.ReadCloser // reader for the current open chunk
+	span            *tracing.Span // trace span for the current open chunk
+	offset          int64         // offset the next Read will start. -1 forces a reopen of o
+	chunkOffset     int64         // beginning of the current or next chunk
+	chunkSize       int64         // length of the current or next chunk. -1 will open o from chunkOffset to the end
+	baseChunkSize   int64         // chunkSize chosen from the read pattern, used after the chunk specified by RangeSeek is complete
+	minChunkSize    int64         // seeks will halve baseChunkSize until reached
+	maxChunkSize    int64         // consecutive read chunks will double in size until reached. -1 means no limit
+	customChunkSize bool          // is the current chunkSize set by RangeSeek?
+	closed          bool          // has Close been called?
 }
 
 // New returns a ChunkedReader for the Object.
 //
 // An initialChunkSize of <= 0 will disable chunked reading.
 // If maxChunkSize is greater than initialChunkSize, the chunk size will be
-// doubled after each chunk read with a maximum of maxChunkSize.
-// A Seek or RangeSeek will reset the chunk size to it's initial value
-func New(ctx context.Context, o fs.Object, initialChunkSize int64, maxChunkSize int64) *ChunkedReader {
+// doubled after each chunk read sequentially with a maximum of maxChunkSize.
+// A Seek or RangeSeek to a different position will halve the chunk size
+// with a minimum of minChunkSize. A minChunkSize of <= 0 or greater than
+// initialChunkSize will use initialChunkSize.
+func New(ctx context.Context, o fs.Object, initialChunkSize int64, minChunkSize int64, maxChunkSize int64) *ChunkedReader {
 	if initialChunkSize <= 0 {
 		initialChunkSize = -1
 	}
+	if minChunkSize <= 0 || minChunkSize > initialChunkSize {
+		minChunkSize = initialChunkSize
+	}
 	if maxChunkSize != -1 && maxChunkSize < initialChunkSize {
 		maxChunkSize = initialChunkSize
 	}
 	return &ChunkedReader{
-		ctx:              ctx,
-		o:                o,
-		offset:           -1,
-		chunkSize:        initialChunkSize,
-		initialChunkSize: initialChunkSize,
-		maxChunkSize:     maxChunkSize,
+		ctx:           ctx,
+		o:             o,
+		offset:        -1,
+		chunkSize:     initialChunkSize,
+		baseChunkSize: initialChunkSize,
+		minChunkSize:  minChunkSize,
+		maxChunkSize:  maxChunkSize,
+	}
+}
+
+// grow doubles the baseChunkSize after a chunk has been read
+// sequentially, up to maxChunkSize
+func (cr *ChunkedReader) grow() {
+	if cr.baseChunkSize <= 0 {
+		return
+	}
+	cr.baseChunkSize *= 2
+	if cr.baseChunkSize > cr.maxChunkSize && cr.maxChunkSize != -1 {
+		cr.baseChunkSize = cr.maxChunkSize
+	}
+}
+
+// shrink halves the baseChunkSize after a seek, down to minChunkSize
+func (cr *ChunkedReader) shrink() {
+	if cr.baseChunkSize <= 0 {
+		return
+	}
+	cr.baseChunkSize /= 2
+	if cr.baseChunkSize < cr.minChunkSize {
+		cr.baseChunkSize = cr.minChunkSize
 	}
 }
 
@@ -77,13 +113,10 @@ func (cr *ChunkedReader) Read(p []byte) (n int, err error) {
 			cr.chunkOffset = cr.offset
 			if cr.customChunkSize { // last chunkSize was set by RangeSeek
 				cr.customChunkSize = false
-				cr.chunkSize = cr.initialChunkSize
 			} else {
-				cr.chunkSize *= 2
-				if cr.chunkSize > cr.maxChunkSize && cr.maxChunkSize != -1 {
-					cr.chunkSize = cr.maxChunkSize
-				}
+				cr.grow()
 			}
+			cr.chunkSize = cr.baseChunkSize
 			// recalculate the chunk boundary. valid only when chunkSize > 0
 			chunkEnd = cr.chunkOffset + cr.chunkSize
 			fallthrough
@@ -150,6 +183,12 @@ func (cr *ChunkedReader) RangeSeek(ctx context.Context, offset int64, whence int
 		return 0, ErrorFileClosed
 	}
 
+	// the position the next Read would have started at
+	pos := cr.offset
+	if pos == -1 {
+		pos = cr.chunkOffset
+	}
+
 	size := cr.o.Size()
 	switch whence {
 	case io.SeekStart:
@@ -161,11 +200,16 @@ func (cr *ChunkedReader) RangeSeek(ctx context.Context, offset int64, whence int
 	cr.chunkOffset = cr.offset + offset
 	// force reopen on next Read
 	cr.offset = -1
+	// a seek elsewhere means the reads aren't sequential
+	if cr.chunkOffset != pos {
+		cr.shrink()
+	}
 	if length > 0 {
 		cr.customChunkSize = true
 		cr.chunkSize = length
 	} else {
-		cr.chunkSize = cr.initialChunkSize
+		cr.customChunkSize = false
+		cr.chunkSize = cr.baseChunkSize
 	}
 	if cr.chunkOffset < 0 || cr.chunkOffset >= size {
 		cr.chunkOffset = 0
@@ -212,26 +256,36 @@ func (cr *ChunkedReader) openRange() error {
 		}
 	}
 
+	ctx, span := tracing.Start(cr.ctx, "chunk")
+	span.SetAttribute("remote", cr.o.Remote())
+	span.SetAttribute("offset", offset)
+	span.SetAttribute("length", length)
 	var rc io.ReadCloser
 	var err error
 	if length <= 0 {
 		if offset == 0 {
-			rc, err = cr.o.Open(cr.ctx, &fs.HashesOption{Hashes: hash.Set(hash.None)})
+			rc, err = cr.o.Open(ctx, &fs.HashesOption{Hashes: hash.Set(hash.None)})
 		} else {
-			rc, err = cr.o.Open(cr.ctx, &fs.HashesOption{Hashes: hash.Set(hash.None)}, &fs.RangeOption{Start: offset, End: -1})
+			rc, err = cr.o.Open(ctx, &fs.HashesOption{Hashes: hash.Set(hash.None)}, &fs.RangeOption{Start: offset, End: -1})
 		}
 	} else {
-		rc, err = cr.o.Open(cr.ctx, &fs.HashesOption{Hashes: hash.Set(hash.None)}, &fs.RangeOption{Start: offset, End: offset + length - 1})
+		rc, err = cr.o.Open(ctx, &fs.HashesOption{Hashes: hash.Set(hash.None)}, &fs.RangeOption{Start: offset, End: offset + length - 1})
 	}
 	if err != nil {
+		span.End(err)
 		return err
 	}
-	return cr.resetReader(rc, offset)
+	err = cr.resetReader(rc, offset)
+	cr.span = span
+	return err
 }
 
 // resetReader switches the current reader to the given reader.
 // The old reader will be Close'd before setting the new reader.
 func (cr *ChunkedReader) resetReader(rc io.ReadCloser, offset int64) error {
+	// The span for a chunk lasts until it is replaced or closed
+	cr.span.End(nil)
+	cr.span = nil
 	if cr.rc != nil {
 		if err := cr.rc.Close(); err != nil {
 			return err
@@ -26,6 +26,7 @@ func testRead(content []byte, mode mockobject.SeekMode) func(*testing.T) {
 		offsets := []int64{0, 1, 2, 3, 4, 5, 7, 8, 9, 15, 16, 17, 31, 32, 33,
 			63, 64, 65, 511, 512, 513, 1023, 1024, 1025}
 		limits := []int64{-1, 0, 1, 31, 32, 33, 1023, 1024, 1025}
+		minChunkSizes := []int64{0, 1, 16}
 		cl := int64(len(content))
 		bl := 32
 		buf := make([]byte, bl)
@@ -38,37 +39,39 @@ func testRead(content []byte, mode mockobject.SeekMode) func(*testing.T) {
 					continue
 				}
 
-				t.Run(fmt.Sprintf("Chunksize_%d_%d", cs, csMax), func(t *testing.T) {
-					cr := New(context.Background(), o, cs, csMax)
+				for _, csMin := range minChunkSizes {
+					t.Run(fmt.Sprintf("Chunksize_%d_%d_%d", cs, csMin, csMax), func(t *testing.T) {
+						cr := New(context.Background(), o, cs, csMin, csMax)
 
-					for _, offset := range offsets {
-						for _, limit := range limits {
-							what := fmt.Sprintf("offset %d, limit %d", offset, limit)
+						for _, offset := range offsets {
+							for _, limit := range limits {
+								what := fmt.Sprintf("offset %d, limit %d", offset, limit)
 
-							p, err := cr.RangeSeek(context.Background(), offset, io.SeekStart, limit)
-							if offset >= cl {
-								require.Error(t, err, what)
-								return
-							}
-							require.NoError(t, err, what)
-							require.Equal(t, offset, p, what)
-
-							n, err := cr.Read(buf)
-							end := offset + int64(bl)
-							if end > cl {
-								end = cl
-							}
-							l := int(end - offset)
-							if l < bl {
-								require.Equal(t, io.EOF, err, what)
-							} else {
+								p, err := cr.RangeSeek(context.Background(), offset, io.SeekStart, limit)
+								if offset >= cl {
+									require.Error(t, err, what)
+									return
+								}
 								require.NoError(t, err, what)
+								require.Equal(t, offset, p, what)
+
+								n, err := cr.Read(buf)
+								end := offset + int64(bl)
+								if end > cl {
+									end = cl
+								}
+								l := int(end - offset)
+								if l < bl {
+									require.Equal(t, io.EOF, err, what)
+								} else {
+									require.NoError(t, err, what)
+								}
+								require.Equal(t, l, n, what)
+								require.Equal(t, content[offset:end], buf[:n], what)
 							}
-							require.Equal(t, l, n, what)
-							require.Equal(t, content[offset:end], buf[:n], what)
 						}
-					}
-				})
+					})
+				}
 			}
 		}
 	}
@@ -79,30 +82,74 @@ func TestErrorAfterClose(t *testing.T) {
 	o := mockobject.New("test.bin").WithContent(content, mockobject.SeekModeNone)
 
 	// Close
-	cr := New(context.Background(), o, 0, 0)
+	cr := New(context.Background(), o, 0, 0, 0)
 	require.NoError(t, cr.Close())
 	require.Error(t, cr.Close())
 
 	// Read
-	cr = New(context.Background(), o, 0, 0)
+	cr = New(context.Background(), o, 0, 0, 0)
 	require.NoError(t, cr.Close())
 	var buf [1]byte
 	_, err := cr.Read(buf[:])
 	require.Error(t, err)
 
 	// Seek
-	cr = New(context.Background(), o, 0, 0)
+	cr = New(context.Background(), o, 0, 0, 0)
 	require.NoError(t, cr.Close())
 	_, err = cr.Seek(1, io.SeekCurrent)
 	require.Error(t, err)
 
 	// RangeSeek
-	cr = New(context.Background(), o, 0, 0)
+	cr = New(context.Background(), o, 0, 0, 0)
 	require.NoError(t, cr.Close())
 	_, err = cr.RangeSeek(context.Background(), 1, io.SeekCurrent, 0)
 	require.Error(t, err)
 }
 
+func TestChunkedReaderAdaptive(t *testing.T) {
+	content := makeContent(t, 1024)
+	o := mockobject.New("test.bin").WithContent(content, mockobject.SeekModeNone)
+	cr := New(context.Background(), o, 16, 4, 64)
+	buf := make([]byte, 1024)
+
+	read := func(n int, wantChunkSize int64) {
+		_, err := io.ReadFull(cr, buf[:n])
+		require.NoError(t, err)
+		assert.Equal(t, wantChunkSize, cr.chunkSize)
+	}
+	seek := func(offset, length int64, wantChunkSize int64) {
+		_, err := cr.RangeSeek(context.Background(), offset, io.SeekStart, length)
+		require.NoError(t, err)
+		assert.Equal(t, wantChunkSize, cr.chunkSize)
+	}
+
+	// sequential reads double the chunk size up to the maximum
+	read(16, 16)
+	read(16, 32)
+	read(16, 32)
+	read(16, 64)
+	read(48, 64)
+	read(16, 64)
+
+	// seeks halve the chunk size down to the minimum
+	seek(500, -1, 32)
+	seek(500, -1, 32) // seeking to the same place isn't a seek
+	seek(10, -1, 16)
+	seek(100, -1, 8)
+	seek(200, -1, 4)
+	seek(300, -1, 4)
+
+	// and sequential reads grow it again
+	read(4, 4)
+	read(4, 8)
+
+	// a chunk with a length set by RangeSeek goes back to the
+	// adaptive size when it is finished
+	seek(600, 100, 100)
+	read(100, 100)
+	read(4, 4)
+}
+
 func makeContent(t *testing.T, size int) []byte {
 	content := make([]byte, size)
 	r := rand.New(rand.NewSource(42))
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/artpar/rclone/fs/hash"
 	"github.com/pkg/errors"
 )
 
@@ -42,6 +43,7 @@ var (
 // ConfigInfo is filesystem config options
 type ConfigInfo struct {
 	LogLevel               LogLevel
+	LogLevelRules          LogLevelRules
 	StatsLogLevel          LogLevel
 	UseJSONLog             bool
 	DryRun                 bool
@@ -57,12 +59,21 @@ type ConfigInfo struct {
 	ConnectTimeout         time.Duration // Connect timeout
 	Timeout                time.Duration // Data channel timeout
 	ExpectContinueTimeout  time.Duration
+	FirstByteTimeout       time.Duration // cancel API calls with no response in this time after sending, 0 for off
+	MaxResponseBodySize    SizeSuffix    // max size of an API response body to decode, -1 for unlimited
 	Dump                   DumpFlags
 	InsecureSkipVerify     bool // Skip server certificate verification
 	DeleteMode             DeleteMode
-	MaxDelete              int64
+	MaxDelete              CountThreshold
+	MaxDeleteSize          SizeThreshold
+	MaxErrors              int64
+	MaxErrorRate           float64
+	MaxErrorWindow         int
 	TrackRenames           bool   // Track file renames.
 	TrackRenamesStrategy   string // Comma separated list of strategies used to track renames
+	HardLinks              bool   // Preserve hard links where possible
+	WriteChangedBlocks     bool   // Only write the changed blocks of updated files where possible
+	Metadata               bool   // Copy the metadata of objects where possible
 	LowLevelRetries        int
 	UpdateOlder            bool // Skip files that are newer on the destination
 	NoGzip                 bool // Disable compression
@@ -75,6 +86,7 @@ type ConfigInfo struct {
 	NoCheckDest            bool
 	NoUnicodeNormalization bool
 	NoUpdateModTime        bool
+	NoUpdateDirModTime     bool // Don't set the modification time of directories
 	DataRateUnit           string
 	CompareDest            []string
 	CopyDest               []string
@@ -82,6 +94,7 @@ type ConfigInfo struct {
 	Suffix                 string
 	SuffixKeepExtension    bool
 	UseListR               bool
+	FastListSpill          SizeSuffix // spill the directories found by --fast-list to disk above this size
 	BufferSize             SizeSuffix
 	BwLimit                BwTimetable
 	BwLimitFile            BwTimetable
@@ -91,11 +104,16 @@ type ConfigInfo struct {
 	DisableFeatures        []string
 	UserAgent              string
 	Immutable              bool
+	ImmutableVerify        bool
 	AutoConfirm            bool
 	StreamingUploadCutoff  SizeSuffix
+	StreamingChunkSize     SizeSuffix // chunk size for streaming to backends with OpenChunkWriter, 0 for the backend's choice
 	StatsFileNameLength    int
 	AskPassword            bool
 	PasswordCommand        SpaceSepList
+	TokenStore             string       // where OAuth tokens are kept: config, keyring or command
+	TokenCommand           SpaceSepList // command to get and set OAuth tokens with if TokenStore is command
+	StrictPaths            bool         // reject ambiguous remote specs such as one letter remote names
 	UseServerModTime       bool
 	MaxTransfer            SizeSuffix
 	MaxDuration            time.Duration
@@ -103,9 +121,10 @@ type ConfigInfo struct {
 	MaxBacklog             int
 	MaxStatsGroups         int
 	StatsOneLine           bool
-	StatsOneLineDate       bool   // If we want a date prefix at all
-	StatsOneLineDateFormat string // If we want to customize the prefix
-	ErrorOnNoTransfer      bool   // Set appropriate exit code if no files transferred
+	StatsOneLineDate       bool          // If we want a date prefix at all
+	StatsOneLineDateFormat string        // If we want to customize the prefix
+	StatsAverageWindow     time.Duration // time window the speeds used for the ETA are averaged over
+	ErrorOnNoTransfer      bool          // Set appropriate exit code if no files transferred
 	Progress               bool
 	ProgressTerminalTitle  bool
 	Cookie                 bool
@@ -125,6 +144,13 @@ type ConfigInfo struct {
 	TrafficClass           uint8
 	FsCacheExpireDuration  time.Duration
 	FsCacheExpireInterval  time.Duration
+	PartialSuffix          string        // if set upload to a temporary name with this suffix then rename
+	PartialMaxAge          time.Duration // remove partial uploads older than this
+	SidecarHash            hash.Type     // write a checksum file of this type next to each file uploaded
+	TraceEndpoint          string        // OTLP/HTTP endpoint to send trace spans to
+	TraceParent            string        // W3C traceparent to make the spans children of
+	Socks5Proxy            string        // SOCKS5 proxy to use for HTTP connections
+	Proxy                  string        // proxy URL for HTTP connections set by the per remote "proxy" config
 }
 
 // NewConfig creates a new config with everything set to the default
@@ -142,8 +168,12 @@ func NewConfig() *ConfigInfo {
 	c.ConnectTimeout = 60 * time.Second
 	c.Timeout = 5 * 60 * time.Second
 	c.ExpectContinueTimeout = 1 * time.Second
+	c.MaxResponseBodySize = 256 * MebiByte
 	c.DeleteMode = DeleteModeDefault
-	c.MaxDelete = -1
+	c.MaxDelete = CountThresholdOff
+	c.MaxDeleteSize = SizeThresholdOff
+	c.MaxErrors = -1
+	c.MaxErrorWindow = 100
 	c.LowLevelRetries = 10
 	c.MaxDepth = -1
 	c.DataRateUnit = "bytes"
@@ -152,9 +182,12 @@ func NewConfig() *ConfigInfo {
 	c.StreamingUploadCutoff = SizeSuffix(100 * 1024)
 	c.MaxStatsGroups = 1000
 	c.StatsFileNameLength = 45
+	c.StatsAverageWindow = 16 * time.Second
 	c.AskPassword = true
+	c.TokenStore = "config"
 	c.TPSLimitBurst = 1
 	c.MaxTransfer = -1
+	c.FastListSpill = -1
 	c.MaxBacklog = 10000
 	// We do not want to set the default here. We use this variable being empty as part of the fall-through of options.
 	//	c.StatsOneLineDateFormat = "2006/01/02 15:04:05 - "
@@ -164,6 +197,7 @@ func NewConfig() *ConfigInfo {
 	c.TrackRenamesStrategy = "hash"
 	c.FsCacheExpireDuration = 300 * time.Second
 	c.FsCacheExpireInterval = 60 * time.Second
+	c.PartialMaxAge = 24 * time.Hour
 
 	return c
 }
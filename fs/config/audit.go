@@ -0,0 +1,139 @@
+// Audit the config file for secrets and stale remotes
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config/obscure"
+	"golang.org/x/oauth2"
+)
+
+// Kinds of AuditItem
+const (
+	AuditPlaintext     = "plaintext"      // a secret stored in plaintext
+	AuditObscured      = "obscured"       // a password stored obscured
+	AuditTokenExpiring = "token-expiring" // a token which can't be refreshed expires soon
+	AuditTokenExpired  = "token-expired"  // a token which can't be refreshed has expired
+	AuditUnused        = "unused"         // the token hasn't been refreshed for a long time
+	AuditUnknownType   = "unknown-type"   // the remote has a backend type rclone doesn't know
+)
+
+// AuditOpt controls Audit
+type AuditOpt struct {
+	ExpiryWindow time.Duration // report tokens which expire within this time
+	UnusedAge    time.Duration // report remotes whose token hasn't been refreshed for this long
+	Now          time.Time     // the time to use as now - if zero uses time.Now()
+}
+
+// DefaultAuditOpt is the default options for Audit
+var DefaultAuditOpt = AuditOpt{
+	ExpiryWindow: 7 * 24 * time.Hour,
+	UnusedAge:    90 * 24 * time.Hour,
+}
+
+// AuditItem is a single finding from Audit
+type AuditItem struct {
+	Remote  string `json:"remote"`
+	Key     string `json:"key,omitempty"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// String turns an AuditItem into a line for output
+func (item AuditItem) String() string {
+	if item.Key == "" {
+		return fmt.Sprintf("%s: %s: %s", item.Remote, item.Kind, item.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", item.Remote, item.Kind, item.Key, item.Message)
+}
+
+// matchSecretKey matches config keys which aren't marked IsPassword
+// but are likely to contain secrets
+var matchSecretKey = regexp.MustCompile(`^(key|pass|password|secret|client_secret|credentials|sas_url|service_account_credentials)$|_(secret|secret_key|secret_access_key|access_key|account_key|api_key|private_key|customer_key)$`)
+
+// Audit checks the config file and returns a list of findings sorted
+// by remote.
+//
+// It reports passwords which are stored obscured, other secrets
+// stored in plaintext, oauth tokens without a refresh token which
+// have expired or will expire within opt.ExpiryWindow and remotes
+// whose token hasn't been refreshed (which rclone does when the
+// remote is used) for opt.UnusedAge.
+func Audit(opt AuditOpt) (items []AuditItem) {
+	now := opt.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	remotes := Data.GetSectionList()
+	sort.Strings(remotes)
+	for _, name := range remotes {
+		add := func(key, kind, format string, args ...interface{}) {
+			items = append(items, AuditItem{
+				Remote:  name,
+				Key:     key,
+				Kind:    kind,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+		ri, err := fs.Find(FileGet(name, "type"))
+		if err != nil {
+			add("", AuditUnknownType, "%v", err)
+		}
+		keys := Data.GetKeyList(name)
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := FileGet(name, key)
+			if value == "" {
+				continue
+			}
+			if key == "token" {
+				auditToken(add, key, value, now, opt)
+				continue
+			}
+			var o *fs.Option
+			if ri != nil {
+				o = ri.Options.Get(key)
+			}
+			switch {
+			case o != nil && o.IsPassword:
+				if _, err := obscure.Reveal(value); err != nil {
+					add(key, AuditPlaintext, "password isn't obscured - set it again with \"rclone config password\"")
+				} else {
+					add(key, AuditObscured, "password is obscured which only protects it from casual viewing")
+				}
+			case matchSecretKey.MatchString(key):
+				add(key, AuditPlaintext, "secret is stored in plaintext")
+			}
+		}
+	}
+	return items
+}
+
+// auditToken checks the oauth token in value calling add with any
+// findings
+func auditToken(add func(key, kind, format string, args ...interface{}), key, value string, now time.Time, opt AuditOpt) {
+	var token oauth2.Token
+	if err := json.Unmarshal([]byte(value), &token); err != nil || token.Expiry.IsZero() {
+		return
+	}
+	if token.RefreshToken == "" {
+		switch {
+		case token.Expiry.Before(now):
+			add(key, AuditTokenExpired, "token expired at %s - use \"rclone config reconnect\" to renew it", token.Expiry.Format(time.RFC3339))
+		case token.Expiry.Before(now.Add(opt.ExpiryWindow)):
+			add(key, AuditTokenExpiring, "token expires at %s - use \"rclone config reconnect\" to renew it", token.Expiry.Format(time.RFC3339))
+		}
+		return
+	}
+	// Tokens with a refresh token are refreshed and saved when the
+	// remote is used so the expiry shows when it was last used.
+	if age := now.Sub(token.Expiry); opt.UnusedAge > 0 && age > opt.UnusedAge {
+		add("", AuditUnused, "not used since about %s - consider removing it with \"rclone config delete\"", token.Expiry.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,58 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/fs/config"
+	"github.com/artpar/rclone/fs/config/obscure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	defer testConfigFile(t, "audit.conf")()
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	config.FileSet("obscured", "type", "config_test_remote")
+	config.FileSet("obscured", "pass", obscure.MustObscure("potato"))
+
+	config.FileSet("plain", "type", "config_test_remote")
+	config.FileSet("plain", "pass", "potato")
+	config.FileSet("plain", "secret_access_key", "sausage")
+	config.FileSet("plain", "bool", "true")
+
+	config.FileSet("expiring", "type", "config_test_remote")
+	config.FileSet("expiring", "token", `{"access_token":"a","expiry":"2021-06-03T12:00:00Z"}`)
+
+	config.FileSet("expired", "type", "config_test_remote")
+	config.FileSet("expired", "token", `{"access_token":"a","expiry":"2021-05-01T12:00:00Z"}`)
+
+	config.FileSet("fresh", "type", "config_test_remote")
+	config.FileSet("fresh", "token", `{"access_token":"a","refresh_token":"r","expiry":"2021-05-01T12:00:00Z"}`)
+
+	config.FileSet("unused", "type", "config_test_remote")
+	config.FileSet("unused", "token", `{"access_token":"a","refresh_token":"r","expiry":"2020-01-01T12:00:00Z"}`)
+
+	config.FileSet("unknown", "type", "potato")
+
+	opt := config.DefaultAuditOpt
+	opt.Now = now
+	var got []string
+	for _, item := range config.Audit(opt) {
+		got = append(got, item.Remote+"/"+item.Key+"/"+item.Kind)
+	}
+	assert.Equal(t, []string{
+		"expired/token/token-expired",
+		"expiring/token/token-expiring",
+		"obscured/pass/obscured",
+		"plain/pass/plaintext",
+		"plain/secret_access_key/plaintext",
+		"unknown//unknown-type",
+		"unused//unused",
+	}, got)
+
+	item := config.AuditItem{Remote: "remote", Key: "key", Kind: config.AuditPlaintext, Message: "message"}
+	assert.Equal(t, "remote: plaintext: key: message", item.String())
+	item.Key = ""
+	assert.Equal(t, "remote: plaintext: message", item.String())
+}
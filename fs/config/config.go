@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -433,6 +434,47 @@ func FileSections() []string {
 	return sections
 }
 
+// Remote describes a remote returned by Remotes
+type Remote struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Source      string `json:"source"` // SourceConfig or SourceEnv
+}
+
+// Remotes returns the remotes in the config file and those defined
+// by environment variables sorted by name.
+//
+// Remotes in both are only returned once with Source set to
+// SourceConfig.
+func Remotes() (remotes []Remote) {
+	inFile := make(map[string]struct{})
+	for _, name := range Data.GetSectionList() {
+		inFile[name] = struct{}{}
+	}
+	seen := make(map[string]struct{})
+	for _, name := range FileSections() {
+		if _, found := seen[name]; found {
+			continue
+		}
+		seen[name] = struct{}{}
+		source := SourceEnv
+		if _, found := inFile[name]; found {
+			source = SourceConfig
+		}
+		remotes = append(remotes, Remote{
+			Name:        name,
+			Type:        FileGet(name, "type"),
+			Description: FileGet(name, "description"),
+			Source:      source,
+		})
+	}
+	sort.Slice(remotes, func(i, j int) bool {
+		return remotes[i].Name < remotes[j].Name
+	})
+	return remotes
+}
+
 // DumpRcRemote dumps the config for a single remote
 func DumpRcRemote(name string) (dump rc.Params) {
 	params := rc.Params{}
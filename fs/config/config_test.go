@@ -4,11 +4,13 @@ package config_test
 
 import (
 	"context"
+	"os"
 	"testing"
 
 	"github.com/artpar/rclone/fs/config"
 	"github.com/artpar/rclone/fs/config/configfile"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfigLoad(t *testing.T) {
@@ -27,3 +29,24 @@ func TestConfigLoad(t *testing.T) {
 	expect = []string{"type", "nounc"}
 	assert.Equal(t, expect, keys)
 }
+
+func TestRemotes(t *testing.T) {
+	defer testConfigFile(t, "remotes.conf")()
+
+	config.FileSet("one", "type", "config_test_remote")
+	config.FileSet("one", "description", "the first remote")
+	config.FileSet("both", "type", "local")
+
+	for _, env := range []string{"RCLONE_CONFIG_BOTH_TYPE", "RCLONE_CONFIG_ENV_TYPE"} {
+		require.NoError(t, os.Setenv(env, "local"))
+		defer func(env string) {
+			_ = os.Unsetenv(env)
+		}(env)
+	}
+
+	assert.Equal(t, []config.Remote{
+		{Name: "both", Type: "local", Source: config.SourceConfig},
+		{Name: "env", Type: "local", Source: config.SourceEnv},
+		{Name: "one", Type: "config_test_remote", Description: "the first remote", Source: config.SourceConfig},
+	}, config.Remotes())
+}
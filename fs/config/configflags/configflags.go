@@ -57,17 +57,30 @@ func AddFlags(ci *fs.ConfigInfo, flagSet *pflag.FlagSet) {
 	flags.DurationVarP(flagSet, &ci.ConnectTimeout, "contimeout", "", ci.ConnectTimeout, "Connect timeout")
 	flags.DurationVarP(flagSet, &ci.Timeout, "timeout", "", ci.Timeout, "IO idle timeout")
 	flags.DurationVarP(flagSet, &ci.ExpectContinueTimeout, "expect-continue-timeout", "", ci.ExpectContinueTimeout, "Timeout when using expect / 100-continue in HTTP")
+	flags.DurationVarP(flagSet, &ci.FirstByteTimeout, "first-byte-timeout", "", ci.FirstByteTimeout, "Timeout for the response to an API call to start after it has been sent, 0 for off")
+	flags.FVarP(flagSet, &ci.MaxResponseBodySize, "max-response-body-size", "", "Max size of an API response or error body to read. Use off for unlimited.")
 	flags.BoolVarP(flagSet, &dumpHeaders, "dump-headers", "", false, "Dump HTTP headers - may contain sensitive info")
 	flags.BoolVarP(flagSet, &dumpBodies, "dump-bodies", "", false, "Dump HTTP headers and bodies - may contain sensitive info")
 	flags.BoolVarP(flagSet, &ci.InsecureSkipVerify, "no-check-certificate", "", ci.InsecureSkipVerify, "Do not verify the server SSL certificate. Insecure.")
 	flags.BoolVarP(flagSet, &ci.AskPassword, "ask-password", "", ci.AskPassword, "Allow prompt for password for encrypted configuration.")
 	flags.FVarP(flagSet, &ci.PasswordCommand, "password-command", "", "Command for supplying password for encrypted configuration.")
+	flags.StringVarP(flagSet, &ci.TokenStore, "token-store", "", ci.TokenStore, "Where to keep OAuth tokens: config, keyring or command.")
+	flags.FVarP(flagSet, &ci.TokenCommand, "token-command", "", "Command to get and set OAuth tokens with if --token-store is command.")
+	flags.BoolVarP(flagSet, &ci.StrictPaths, "strict-paths", "", ci.StrictPaths, "Reject ambiguous remote names such as one letter names which look like drive letters.")
 	flags.BoolVarP(flagSet, &deleteBefore, "delete-before", "", false, "When synchronizing, delete files on destination before transferring")
 	flags.BoolVarP(flagSet, &deleteDuring, "delete-during", "", false, "When synchronizing, delete files during transfer")
 	flags.BoolVarP(flagSet, &deleteAfter, "delete-after", "", false, "When synchronizing, delete files on destination after transferring (default)")
-	flags.Int64VarP(flagSet, &ci.MaxDelete, "max-delete", "", -1, "When synchronizing, limit the number of deletes")
+	flags.FVarP(flagSet, &ci.MaxDelete, "max-delete", "", "When synchronizing, limit the number of deletes, or the percentage of destination files deleted with eg 5%")
+	flags.FVarP(flagSet, &ci.MaxDeleteSize, "max-delete-size", "", "When synchronizing, limit the total size of deletes, or the percentage of destination bytes deleted with eg 5%")
+	flags.Int64VarP(flagSet, &ci.MaxErrors, "max-errors", "", ci.MaxErrors, "When synchronizing, abort if there are more than this many errors")
+	flags.Float64VarP(flagSet, &ci.MaxErrorRate, "max-error-rate", "", ci.MaxErrorRate, "When synchronizing, abort if more than this fraction of the last --max-error-window operations failed")
+	flags.IntVarP(flagSet, &ci.MaxErrorWindow, "max-error-window", "", ci.MaxErrorWindow, "Number of recent operations --max-error-rate is measured over")
 	flags.BoolVarP(flagSet, &ci.TrackRenames, "track-renames", "", ci.TrackRenames, "When synchronizing, track file renames and do a server-side move if possible")
 	flags.StringVarP(flagSet, &ci.TrackRenamesStrategy, "track-renames-strategy", "", ci.TrackRenamesStrategy, "Strategies to use when synchronizing using track-renames hash|modtime|leaf")
+	flags.BoolVarP(flagSet, &ci.HardLinks, "hard-links", "", ci.HardLinks, "Preserve hard links on the source if the destination supports them.")
+	flags.FVarP(flagSet, &ci.SidecarHash, "sidecar-hash", "", "Write a checksum file of this hash type, e.g. SHA-256, next to each file uploaded and use it to verify the file.")
+	flags.BoolVarP(flagSet, &ci.Metadata, "metadata", "", ci.Metadata, "Copy the metadata of files if the source and destination support it.")
+	flags.BoolVarP(flagSet, &ci.WriteChangedBlocks, "write-changed-blocks", "", ci.WriteChangedBlocks, "Only write the changed blocks of updated files if the destination supports it.")
 	flags.IntVarP(flagSet, &ci.LowLevelRetries, "low-level-retries", "", ci.LowLevelRetries, "Number of low level retries to do.")
 	flags.BoolVarP(flagSet, &ci.UpdateOlder, "update", "u", ci.UpdateOlder, "Skip files that are newer on the destination.")
 	flags.BoolVarP(flagSet, &ci.UseServerModTime, "use-server-modtime", "", ci.UseServerModTime, "Use server modified time instead of object metadata")
@@ -81,6 +94,7 @@ func AddFlags(ci *fs.ConfigInfo, flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &ci.NoCheckDest, "no-check-dest", "", ci.NoCheckDest, "Don't check the destination, copy regardless.")
 	flags.BoolVarP(flagSet, &ci.NoUnicodeNormalization, "no-unicode-normalization", "", ci.NoUnicodeNormalization, "Don't normalize unicode characters in filenames.")
 	flags.BoolVarP(flagSet, &ci.NoUpdateModTime, "no-update-modtime", "", ci.NoUpdateModTime, "Don't update destination mod-time if files identical.")
+	flags.BoolVarP(flagSet, &ci.NoUpdateDirModTime, "no-update-dir-modtime", "", ci.NoUpdateDirModTime, "Don't set the mod-time of destination directories to match the source.")
 	flags.StringArrayVarP(flagSet, &ci.CompareDest, "compare-dest", "", nil, "Include additional comma separated server-side paths during comparison.")
 	flags.StringArrayVarP(flagSet, &ci.CopyDest, "copy-dest", "", nil, "Implies --compare-dest but also copies files from paths into destination.")
 	flags.StringVarP(flagSet, &ci.BackupDir, "backup-dir", "", ci.BackupDir, "Make backups into hierarchy based in DIR.")
@@ -93,16 +107,20 @@ func AddFlags(ci *fs.ConfigInfo, flagSet *pflag.FlagSet) {
 	flags.StringVarP(flagSet, &disableFeatures, "disable", "", "", "Disable a comma separated list of features.  Use help to see a list.")
 	flags.StringVarP(flagSet, &ci.UserAgent, "user-agent", "", ci.UserAgent, "Set the user-agent to a specified string. The default is rclone/ version")
 	flags.BoolVarP(flagSet, &ci.Immutable, "immutable", "", ci.Immutable, "Do not modify files. Fail if existing files have been modified.")
+	flags.BoolVarP(flagSet, &ci.ImmutableVerify, "immutable-verify", "", ci.ImmutableVerify, "As --immutable but also check the hashes of existing files match the source.")
 	flags.BoolVarP(flagSet, &ci.AutoConfirm, "auto-confirm", "", ci.AutoConfirm, "If enabled, do not request console confirmation.")
 	flags.IntVarP(flagSet, &ci.StatsFileNameLength, "stats-file-name-length", "", ci.StatsFileNameLength, "Max file name length in stats. 0 for no limit")
 	flags.FVarP(flagSet, &ci.LogLevel, "log-level", "", "Log level DEBUG|INFO|NOTICE|ERROR")
+	flags.FVarP(flagSet, &ci.LogLevelRules, "log-level-rules", "", "Override the log level for some messages, e.g. backend=webdav:DEBUG,operation=delete:INFO")
 	flags.FVarP(flagSet, &ci.StatsLogLevel, "stats-log-level", "", "Log level to show --stats output DEBUG|INFO|NOTICE|ERROR")
 	flags.FVarP(flagSet, &ci.BwLimit, "bwlimit", "", "Bandwidth limit in kBytes/s, or use suffix b|k|M|G or a full timetable.")
 	flags.FVarP(flagSet, &ci.BwLimitFile, "bwlimit-file", "", "Bandwidth limit per file in kBytes/s, or use suffix b|k|M|G or a full timetable.")
 	flags.FVarP(flagSet, &ci.BufferSize, "buffer-size", "", "In memory buffer size when reading files for each --transfer.")
 	flags.FVarP(flagSet, &ci.StreamingUploadCutoff, "streaming-upload-cutoff", "", "Cutoff for switching to chunked upload if file size is unknown. Upload starts after reaching cutoff or when file ends.")
+	flags.FVarP(flagSet, &ci.StreamingChunkSize, "streaming-chunk-size", "", "Chunk size for uploads of unknown size to remotes which can't stream but support chunked uploads. 0 uses the remote's preferred size.")
 	flags.FVarP(flagSet, &ci.Dump, "dump", "", "List of items to dump from: "+fs.DumpFlagsList)
 	flags.FVarP(flagSet, &ci.MaxTransfer, "max-transfer", "", "Maximum size of data to transfer.")
+	flags.FVarP(flagSet, &ci.FastListSpill, "fast-list-spill", "", "Spill the directories (not the objects) found by --fast-list to disk when they use more memory than this.")
 	flags.DurationVarP(flagSet, &ci.MaxDuration, "max-duration", "", 0, "Maximum duration rclone will transfer data for.")
 	flags.FVarP(flagSet, &ci.CutoffMode, "cutoff-mode", "", "Mode to stop transfers when reaching the max transfer limit HARD|SOFT|CAUTIOUS")
 	flags.IntVarP(flagSet, &ci.MaxBacklog, "max-backlog", "", ci.MaxBacklog, "Maximum number of objects in sync or check backlog.")
@@ -110,6 +128,7 @@ func AddFlags(ci *fs.ConfigInfo, flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &ci.StatsOneLine, "stats-one-line", "", ci.StatsOneLine, "Make the stats fit on one line.")
 	flags.BoolVarP(flagSet, &ci.StatsOneLineDate, "stats-one-line-date", "", ci.StatsOneLineDate, "Enables --stats-one-line and add current date/time prefix.")
 	flags.StringVarP(flagSet, &ci.StatsOneLineDateFormat, "stats-one-line-date-format", "", ci.StatsOneLineDateFormat, "Enables --stats-one-line-date and uses custom formatted date. Enclose date string in double quotes (\"). See https://golang.org/pkg/time/#Time.Format")
+	flags.DurationVarP(flagSet, &ci.StatsAverageWindow, "stats-average-window", "", ci.StatsAverageWindow, "Time window to average the speed used to calculate the ETA over. 0 to use the average since the start.")
 	flags.BoolVarP(flagSet, &ci.ErrorOnNoTransfer, "error-on-no-transfer", "", ci.ErrorOnNoTransfer, "Sets exit code 9 if no files are transferred, useful in scripts")
 	flags.BoolVarP(flagSet, &ci.Progress, "progress", "P", ci.Progress, "Show progress during transfer.")
 	flags.BoolVarP(flagSet, &ci.ProgressTerminalTitle, "progress-terminal-title", "", ci.ProgressTerminalTitle, "Show progress on the terminal title. Requires -P/--progress.")
@@ -130,6 +149,11 @@ func AddFlags(ci *fs.ConfigInfo, flagSet *pflag.FlagSet) {
 	flags.StringVarP(flagSet, &dscp, "dscp", "", "", "Set DSCP value to connections. Can be value or names, eg. CS1, LE, DF, AF21.")
 	flags.DurationVarP(flagSet, &ci.FsCacheExpireDuration, "fs-cache-expire-duration", "", ci.FsCacheExpireDuration, "cache remotes for this long (0 to disable caching)")
 	flags.DurationVarP(flagSet, &ci.FsCacheExpireInterval, "fs-cache-expire-interval", "", ci.FsCacheExpireInterval, "interval to check for expired remotes")
+	flags.StringVarP(flagSet, &ci.PartialSuffix, "partial-suffix", "", ci.PartialSuffix, "Upload to a temporary name with this suffix then rename it into place, eg .partial")
+	flags.DurationVarP(flagSet, &ci.PartialMaxAge, "partial-max-age", "", ci.PartialMaxAge, "Remove partial uploads older than this when syncing")
+	flags.StringVarP(flagSet, &ci.TraceEndpoint, "trace-endpoint", "", ci.TraceEndpoint, "Send OpenTelemetry trace spans to this OTLP/HTTP endpoint, eg http://localhost:4318/v1/traces")
+	flags.StringVarP(flagSet, &ci.TraceParent, "trace-parent", "", ci.TraceParent, "Make the trace spans children of this W3C traceparent")
+	flags.StringVarP(flagSet, &ci.Socks5Proxy, "socks5-proxy", "", ci.Socks5Proxy, "Use this SOCKS5 proxy for HTTP connections, eg user:pass@host:port")
 }
 
 // ParseHeaders converts the strings passed in via the header flags into HTTPOptions
@@ -156,6 +180,9 @@ func SetFlags(ci *fs.ConfigInfo) {
 	} else if verbose >= 1 {
 		ci.LogLevel = fs.LogLevelInfo
 	}
+	if ci.ImmutableVerify {
+		ci.Immutable = true
+	}
 	if (ci.DryRun || ci.Interactive) && ci.StatsLogLevel > fs.LogLevelNotice {
 		ci.StatsLogLevel = fs.LogLevelNotice
 	}
@@ -180,7 +207,13 @@ func SetFlags(ci *fs.ConfigInfo) {
 			TimestampFormat: "2006-01-02T15:04:05.999999-07:00",
 		})
 		logrus.SetLevel(logrus.DebugLevel)
-		switch ci.LogLevel {
+		// Leave logrus at DEBUG if there are rules as they can
+		// make some messages more verbose than --log-level
+		level := ci.LogLevel
+		if len(ci.LogLevelRules) > 0 {
+			level = fs.LogLevelDebug
+		}
+		switch level {
 		case fs.LogLevelEmergency, fs.LogLevelAlert:
 			logrus.SetLevel(logrus.PanicLevel)
 		case fs.LogLevelCritical:
@@ -232,6 +265,16 @@ func SetFlags(ci *fs.ConfigInfo) {
 		ci.StatsOneLine = true
 	}
 
+	switch ci.TokenStore {
+	case "config", "keyring":
+	case "command":
+		if len(ci.TokenCommand) == 0 {
+			log.Fatalf(`--token-store command needs --token-command`)
+		}
+	default:
+		log.Fatalf(`--token-store: must be "config", "keyring" or "command" not %q`, ci.TokenStore)
+	}
+
 	if bindAddr != "" {
 		addrs, err := net.LookupIP(bindAddr)
 		if err != nil {
@@ -243,6 +286,12 @@ func SetFlags(ci *fs.ConfigInfo) {
 		ci.BindAddr = addrs[0]
 	}
 
+	if ci.Socks5Proxy != "" {
+		if _, err := fs.ParseSocks5Proxy(ci.Socks5Proxy); err != nil {
+			log.Fatalf("--socks5-proxy: %v", err)
+		}
+	}
+
 	if disableFeatures != "" {
 		if disableFeatures == "help" {
 			log.Fatalf("Possible backend features are: %s\n", strings.Join(new(fs.Features).List(), ", "))
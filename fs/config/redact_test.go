@@ -0,0 +1,58 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/artpar/rclone/fs/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedacted(t *testing.T) {
+	defer testConfigFile(t, "redacted.conf")()
+
+	config.FileSet("one", "type", "config_test_remote")
+	config.FileSet("one", "pass", "potato")
+	config.FileSet("one", "secret_access_key", "sausage")
+	config.FileSet("one", "access_key_id", "AKIA")
+	config.FileSet("one", "token", `{"access_token":"a","refresh_token":"r","expiry":"2021-05-01T12:00:00Z"}`)
+	config.FileSet("one", "unknown", "value")
+
+	config.FileSet("two", "type", "potato")
+	config.FileSet("two", "password", "potato")
+
+	require.NoError(t, os.Setenv("RCLONE_CONFIG_ONE_BOOL", "true"))
+	defer func() {
+		_ = os.Unsetenv("RCLONE_CONFIG_ONE_BOOL")
+	}()
+
+	remotes := config.Redacted()
+	require.Len(t, remotes, 2)
+
+	one := remotes[0]
+	assert.Equal(t, "one", one.Name)
+	assert.Equal(t, "config_test_remote", one.Type)
+	assert.Equal(t, map[string]string{
+		"type":              "config_test_remote",
+		"pass":              config.RedactedValue,
+		"secret_access_key": config.RedactedValue,
+		"access_key_id":     config.RedactedValue,
+		"token":             `{"access_token":"XXX","expiry":"2021-05-01T12:00:00Z","refresh_token":"XXX"}`,
+		"unknown":           config.RedactedValue,
+	}, one.Config)
+	assert.Equal(t, config.RedactedOption{Value: "true", Source: config.SourceOverride}, one.Options["bool"])
+	assert.Equal(t, config.RedactedOption{Value: config.RedactedValue, Source: config.SourceConfig}, one.Options["pass"])
+
+	two := remotes[1]
+	assert.Equal(t, "two", two.Name)
+	assert.Equal(t, map[string]string{
+		"type":     "potato",
+		"password": config.RedactedValue,
+	}, two.Config)
+	assert.Nil(t, two.Options)
+
+	remotes = config.Redacted("two")
+	require.Len(t, remotes, 1)
+	assert.Equal(t, "two", remotes[0].Name)
+}
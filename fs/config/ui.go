@@ -253,20 +253,59 @@ func ShowRemote(name string) {
 }
 
 // OkRemote prints the contents of the remote and ask if it is OK
-func OkRemote(name string) bool {
-	ShowRemote(name)
-	switch i := CommandDefault([]string{"yYes this is OK", "eEdit this remote", "dDelete this remote"}, 0); i {
-	case 'y':
-		return true
-	case 'e':
-		return false
-	case 'd':
-		Data.DeleteSection(name)
-		return true
+//
+// The user can also test the connection or edit single options here
+// before deciding.
+func OkRemote(ctx context.Context, name string) bool {
+	for {
+		ShowRemote(name)
+		switch i := CommandDefault([]string{"yYes this is OK", "eEdit this remote", "oEdit a single option", "tTest the connection", "dDelete this remote"}, 0); i {
+		case 'y':
+			return true
+		case 'e':
+			return false
+		case 'o':
+			editOption(mustFindByName(name), name)
+		case 't':
+			testRemote(ctx, name)
+		case 'd':
+			Data.DeleteSection(name)
+			return true
+		default:
+			fs.Errorf(nil, "Bad choice %c", i)
+			return false
+		}
+	}
+}
+
+// testRemote tries to connect to the remote and list its root,
+// telling the user what happened
+func testRemote(ctx context.Context, name string) {
+	ri := mustFindByName(name)
+	if ri.NewFs == nil {
+		fmt.Printf("Can't test %q remotes.\n", ri.Name)
+		return
+	}
+	fmt.Printf("Testing connection to %q\n", name)
+	f, err := fs.NewFs(ctx, name+":")
+	if err != nil {
+		fmt.Printf("Connection failed: %v\n", err)
+		return
+	}
+	if do := f.Features().Shutdown; do != nil {
+		defer func() {
+			_ = do(ctx)
+		}()
+	}
+	entries, err := f.List(ctx, "")
+	switch {
+	case err == fs.ErrorDirNotFound:
+		fmt.Printf("Connection OK but the root directory doesn't exist yet\n")
+	case err != nil:
+		fmt.Printf("Connection failed while listing the root: %v\n", err)
 	default:
-		fs.Errorf(nil, "Bad choice %c", i)
+		fmt.Printf("Connection OK - found %d entries in the root\n", len(entries))
 	}
-	return false
 }
 
 // RemoteConfig runs the config helper for the remote if needed
@@ -443,7 +482,7 @@ func editOptions(ri *fs.RegInfo, name string, isNew bool) {
 			subProvider := getWithDefault(name, fs.ConfigProvider, "")
 			if matchProvider(option.Provider, subProvider) && isVisible {
 				if !isNew {
-					fmt.Printf("Value %q = %q\n", option.Name, FileGet(name, option.Name))
+					showOptionValue(&option, name)
 					fmt.Printf("Edit? (y/n)>\n")
 					if !Confirm(false) {
 						continue
@@ -455,6 +494,64 @@ func editOptions(ri *fs.RegInfo, name string, isNew bool) {
 	}
 }
 
+// showOptionValue shows the value of the option in the remote or its
+// default if it isn't set
+func showOptionValue(option *fs.Option, name string) {
+	if value, found := FileGetFlag(name, option.Name); found {
+		fmt.Printf("Value %q = %q\n", option.Name, value)
+	} else {
+		fmt.Printf("Value %q is not set, default %q\n", option.Name, fmt.Sprint(option.Default))
+	}
+}
+
+// editOption asks the user to search for an option by its name or
+// help and then edits it.
+//
+// This includes the advanced options so any option can be changed
+// without going through the whole config.
+func editOption(ri *fs.RegInfo, name string) {
+	subProvider := getWithDefault(name, fs.ConfigProvider, "")
+	var matches []*fs.Option
+	for len(matches) == 0 {
+		fmt.Printf("Enter part of the name or help of the option to edit or press Enter to show them all\n")
+		fmt.Printf("search> ")
+		query := strings.ToLower(ReadLine())
+		for i := range ri.Options {
+			option := &ri.Options[i]
+			if option.Hide&fs.OptionHideConfigurator != 0 || !matchProvider(option.Provider, subProvider) {
+				continue
+			}
+			if strings.Contains(strings.ToLower(option.Name), query) || strings.Contains(strings.ToLower(option.Help), query) {
+				matches = append(matches, option)
+			}
+		}
+		if len(matches) == 0 {
+			if query == "" {
+				fmt.Printf("No options to edit\n")
+				return
+			}
+			fmt.Printf("No options found matching %q\n", query)
+		}
+	}
+	names := make([]string, len(matches))
+	help := make([]string, len(matches))
+	for i, option := range matches {
+		names[i] = option.Name
+		help[i] = strings.SplitN(option.Help, "\n", 2)[0]
+		if option.Advanced {
+			help[i] += " (advanced)"
+		}
+	}
+	chosen := Choose("option", names, help, false)
+	for _, option := range matches {
+		if option.Name == chosen {
+			showOptionValue(option, name)
+			FileSet(name, option.Name, ChooseOption(option, name))
+			return
+		}
+	}
+}
+
 // NewRemote make a new remote from its name
 func NewRemote(ctx context.Context, name string) {
 	var (
@@ -477,25 +574,48 @@ func NewRemote(ctx context.Context, name string) {
 
 	editOptions(ri, name, true)
 	RemoteConfig(ctx, name)
-	if OkRemote(name) {
+	if OkRemote(ctx, name) {
 		SaveConfig()
 		return
 	}
-	EditRemote(ctx, ri, name)
+	editRemote(ctx, ri, name)
 }
 
 // EditRemote gets the user to edit a remote
+//
+// This shows the remote first so the user can choose to edit single
+// options or test the connection rather than going through all the
+// options again.
 func EditRemote(ctx context.Context, ri *fs.RegInfo, name string) {
+	fmt.Printf("Edit remote\n")
+	if OkRemote(ctx, name) {
+		saveRemote(ctx, name)
+		return
+	}
+	editRemote(ctx, ri, name)
+}
+
+// editRemote gets the user to edit all the options of a remote until
+// they are happy with it
+func editRemote(ctx context.Context, ri *fs.RegInfo, name string) {
 	ShowRemote(name)
 	fmt.Printf("Edit remote\n")
 	for {
 		editOptions(ri, name, false)
-		if OkRemote(name) {
+		if OkRemote(ctx, name) {
 			break
 		}
 	}
+	saveRemote(ctx, name)
+}
+
+// saveRemote saves the config and runs the config helper for the
+// remote if it hasn't been deleted
+func saveRemote(ctx context.Context, name string) {
 	SaveConfig()
-	RemoteConfig(ctx, name)
+	if Data.HasSection(name) {
+		RemoteConfig(ctx, name)
+	}
 }
 
 // DeleteRemote gets the user to delete a remote
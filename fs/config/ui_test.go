@@ -11,6 +11,7 @@ import (
 	"os"
 	"testing"
 
+	_ "github.com/artpar/rclone/backend/local"
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/config"
 	"github.com/artpar/rclone/fs/config/configfile"
@@ -164,6 +165,59 @@ func TestChooseOption(t *testing.T) {
 	assert.Equal(t, "", config.FileGet("test", "pass"))
 }
 
+func TestEditOption(t *testing.T) {
+	defer testConfigFile(t, "crud.conf")()
+	ctx := context.Background()
+
+	// script for creating remote then editing an option before saving
+	config.ReadLine = makeReadLine([]string{
+		"config_test_remote", // type
+		"true",               // bool value
+		"n",                  // not required
+		"o",                  // edit a single option
+		"potato",             // search which doesn't match
+		"BOO",                // search which matches bool
+		"1",                  // choose bool
+		"false",              // new bool value
+		"t",                  // test the connection
+		"y",                  // looks good, save
+	})
+	config.NewRemote(ctx, "test")
+	assert.Equal(t, "false", config.FileGet("test", "bool"))
+
+	// script for editing the remote without going through all the options
+	config.ReadLine = makeReadLine([]string{
+		"o",    // edit a single option
+		"",     // show all the options
+		"bool", // choose bool
+		"true", // new bool value
+		"y",    // looks good, save
+	})
+	config.EditRemote(ctx, fs.MustFind("config_test_remote"), "test")
+	assert.Equal(t, "true", config.FileGet("test", "bool"))
+	assert.Equal(t, "", config.FileGet("test", "pass"))
+}
+
+func TestOkRemoteTestConnection(t *testing.T) {
+	defer testConfigFile(t, "crud.conf")()
+	ctx := context.Background()
+
+	config.FileSet("testlocal", "type", "local")
+
+	config.ReadLine = makeReadLine([]string{
+		"t", // test the connection
+		"y", // looks good
+	})
+	assert.True(t, config.OkRemote(ctx, "testlocal"))
+	assert.Equal(t, []string{"testlocal"}, config.Data.GetSectionList())
+
+	config.ReadLine = makeReadLine([]string{
+		"d", // delete
+	})
+	assert.True(t, config.OkRemote(ctx, "testlocal"))
+	assert.Equal(t, []string{}, config.Data.GetSectionList())
+}
+
 func TestNewRemoteName(t *testing.T) {
 	defer testConfigFile(t, "crud.conf")()
 	ctx := context.Background()
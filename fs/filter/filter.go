@@ -91,6 +91,7 @@ type Opt struct {
 	IncludeFrom    []string
 	FilesFrom      []string
 	FilesFromRaw   []string
+	ProtectRule    []string
 	MinAge         fs.Duration
 	MaxAge         fs.Duration
 	MinSize        fs.SizeSuffix
@@ -113,8 +114,9 @@ type Filter struct {
 	ModTimeTo   time.Time
 	fileRules   rules
 	dirRules    rules
-	files       FilesMap // files if filesFrom
-	dirs        FilesMap // dirs from filesFrom
+	files       *pathSet // files if filesFrom
+	dirs        *pathSet // dirs from filesFrom
+	protect     rules    // files which sync must never delete
 }
 
 // NewFilter parses the command line options and creates a Filter
@@ -231,6 +233,14 @@ func NewFilter(opt *Opt) (f *Filter, err error) {
 			return nil, err
 		}
 	}
+
+	for _, rule := range f.Opt.ProtectRule {
+		re, err := globToRegexp(rule, f.Opt.IgnoreCase)
+		if err != nil {
+			return nil, err
+		}
+		f.protect.add(true, re)
+	}
 	if fs.GetConfig(context.Background()).Dump&fs.DumpFilters != 0 {
 		fmt.Println("--- start filters ---")
 		fmt.Println(f.DumpFilters())
@@ -324,8 +334,8 @@ func (f *Filter) AddRule(rule string) error {
 // initAddFile creates f.files and f.dirs
 func (f *Filter) initAddFile() {
 	if f.files == nil {
-		f.files = make(FilesMap)
-		f.dirs = make(FilesMap)
+		f.files = newPathSet()
+		f.dirs = newPathSet()
 	}
 }
 
@@ -333,26 +343,31 @@ func (f *Filter) initAddFile() {
 func (f *Filter) AddFile(file string) error {
 	f.initAddFile()
 	file = strings.Trim(file, "/")
-	f.files[file] = struct{}{}
+	if !f.files.add(file) {
+		return nil
+	}
 	// Put all the parent directories into f.dirs
 	for {
 		file = path.Dir(file)
 		if file == "." {
 			break
 		}
-		if _, found := f.dirs[file]; found {
+		if !f.dirs.add(file) {
 			break
 		}
-		f.dirs[file] = struct{}{}
 	}
 	return nil
 }
 
 // Files returns all the files from the `--files-from` list
 //
-// It may be nil if the list is empty
+// It may be nil if the list is empty. This makes a copy of the list
+// so it may use a lot of memory if the list is large.
 func (f *Filter) Files() FilesMap {
-	return f.files
+	if f.files == nil {
+		return nil
+	}
+	return f.files.toMap()
 }
 
 // Clear clears all the filter rules
@@ -383,6 +398,20 @@ func (f *Filter) includeRemote(remote string) bool {
 	return true
 }
 
+// Protected returns whether remote matches one of the --protect
+// rules so must never be deleted by sync.
+//
+// This is independent of the filter rules so protected files which
+// are excluded aren't deleted with --delete-excluded.
+func (f *Filter) Protected(remote string) bool {
+	for _, rule := range f.protect.rules {
+		if rule.Match(remote) {
+			return true
+		}
+	}
+	return false
+}
+
 // ListContainsExcludeFile checks if exclude file is present in the list.
 func (f *Filter) ListContainsExcludeFile(entries fs.DirEntries) bool {
 	if len(f.Opt.ExcludeFile) == 0 {
@@ -417,8 +446,7 @@ func (f *Filter) IncludeDirectory(ctx context.Context, fs fs.Fs) func(string) (b
 
 		// filesFrom takes precedence
 		if f.files != nil {
-			_, include := f.dirs[remote]
-			return include, nil
+			return f.dirs.contains(remote), nil
 		}
 		remote += "/"
 		for _, rule := range f.dirRules.rules {
@@ -452,8 +480,7 @@ func (f *Filter) DirContainsExcludeFile(ctx context.Context, fremote fs.Fs, remo
 func (f *Filter) Include(remote string, size int64, modTime time.Time) bool {
 	// filesFrom takes precedence
 	if f.files != nil {
-		_, include := f.files[remote]
-		return include
+		return f.files.contains(remote)
 	}
 	if !f.ModTimeFrom.IsZero() && modTime.Before(f.ModTimeFrom) {
 		return false
@@ -533,6 +560,12 @@ func (f *Filter) DumpFilters() string {
 	for _, dirRule := range f.dirRules.rules {
 		rules = append(rules, dirRule.String())
 	}
+	if f.protect.len() > 0 {
+		rules = append(rules, "--- Protect rules ---")
+		for _, rule := range f.protect.rules {
+			rules = append(rules, rule.String())
+		}
+	}
 	return strings.Join(rules, "\n")
 }
 
@@ -574,9 +607,10 @@ func (f *Filter) MakeListR(ctx context.Context, NewObject func(ctx context.Conte
 				return nil
 			})
 		}
-		for remote := range f.files {
+		_ = f.files.forEach(func(remote string) error {
 			remotes <- remote
-		}
+			return nil
+		})
 		close(remotes)
 		return g.Wait()
 	}
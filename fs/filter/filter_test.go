@@ -107,10 +107,9 @@ func TestNewFilterWithFilesFromAlone(t *testing.T) {
 
 	f, err := NewFilter(&Opt)
 	require.NoError(t, err)
-	assert.Len(t, f.files, 2)
+	assert.Equal(t, 2, f.files.len())
 	for _, name := range []string{"files1", "files2"} {
-		_, ok := f.files[name]
-		if !ok {
+		if !f.files.contains(name) {
 			t.Errorf("Didn't find file %q in f.files", name)
 		}
 	}
@@ -135,10 +134,9 @@ func TestNewFilterWithFilesFromRaw(t *testing.T) {
 
 	f, err := NewFilter(&Opt)
 	require.NoError(t, err)
-	assert.Len(t, f.files, 3)
+	assert.Equal(t, 3, f.files.len())
 	for _, name := range []string{"#comment", "files1", "files2"} {
-		_, ok := f.files[name]
-		if !ok {
+		if !f.files.contains(name) {
 			t.Errorf("Didn't find file %q in f.files", name)
 		}
 	}
@@ -236,8 +234,8 @@ func TestNewFilterIncludeFiles(t *testing.T) {
 	assert.Equal(t, FilesMap{
 		"file1.jpg": {},
 		"file2.jpg": {},
-	}, f.files)
-	assert.Equal(t, FilesMap{}, f.dirs)
+	}, f.Files())
+	assert.Equal(t, FilesMap{}, f.dirs.toMap())
 	testInclude(t, f, []includeTest{
 		{"file1.jpg", 0, 0, true},
 		{"file2.jpg", 1, 0, true},
@@ -264,7 +262,7 @@ func TestNewFilterIncludeFilesDirs(t *testing.T) {
 		"path/to":      {},
 		"path/to/dir":  {},
 		"path/to/dir2": {},
-	}, f.dirs)
+	}, f.dirs.toMap())
 	testDirInclude(t, f, []includeDirTest{
 		{"path", true},
 		{"path/to", true},
@@ -311,7 +309,7 @@ func TestNewFilterMakeListR(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	assert.Equal(t, 5, len(f.files))
+	assert.Equal(t, 5, f.files.len())
 
 	// NewObject function for MakeListR
 	newObjects := FilesMap{}
@@ -503,6 +501,41 @@ func TestNewFilterMatchesIgnoreCase(t *testing.T) {
 	assert.False(t, f.InActive())
 }
 
+func TestNewFilterProtect(t *testing.T) {
+	opt := DefaultOpt
+	opt.ExcludeRule = []string{"*.bak"}
+	opt.ProtectRule = []string{"*.bak", "/keep/**"}
+	f, err := NewFilter(&opt)
+	require.NoError(t, err)
+	for _, test := range []struct {
+		remote    string
+		protected bool
+	}{
+		{"file.bak", true},
+		{"dir/file.bak", true},
+		{"file.txt", false},
+		{"keep/file.txt", true},
+		{"keep/dir/file.txt", true},
+		{"dir/keep/file.txt", false},
+	} {
+		assert.Equal(t, test.protected, f.Protected(test.remote), test.remote)
+	}
+	// Protecting doesn't change what is included
+	assert.False(t, f.Include("file.bak", 0, time.Unix(0, 0)))
+	assert.True(t, f.Include("keep/file.txt", 0, time.Unix(0, 0)))
+	assert.Contains(t, f.DumpFilters(), "--- Protect rules ---")
+
+	f, err = NewFilter(nil)
+	require.NoError(t, err)
+	assert.False(t, f.Protected("file.bak"))
+	assert.NotContains(t, f.DumpFilters(), "--- Protect rules ---")
+
+	opt = DefaultOpt
+	opt.ProtectRule = []string{"[bad"}
+	_, err = NewFilter(&opt)
+	assert.Error(t, err)
+}
+
 func TestFilterAddDirRuleOrFileRule(t *testing.T) {
 	for _, test := range []struct {
 		included bool
@@ -0,0 +1,137 @@
+package filter
+
+import (
+	"encoding/binary"
+)
+
+// pathSet is a set of exact paths used for --files-from and
+// --files-from-raw.
+//
+// It uses much less memory than a map[string]struct{} so it can hold
+// tens of millions of paths. The paths are stored one after another
+// in data, each preceded by its length as a uvarint, and the open
+// addressing hash table in slots only holds the offsets of the paths
+// in data.
+type pathSet struct {
+	data  []byte   // the paths each preceded by its length
+	slots []uint64 // offset+1 of the path in data or 0 if empty
+	n     int      // number of paths in the set
+}
+
+// newPathSet makes an empty pathSet
+func newPathSet() *pathSet {
+	return &pathSet{}
+}
+
+// hashPath returns the FNV-1a hash of p
+func hashPath(p string) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for i := 0; i < len(p); i++ {
+		h ^= uint64(p[i])
+		h *= prime64
+	}
+	return h
+}
+
+// at returns the path stored at offset in data and the offset of the
+// next path
+func (s *pathSet) at(offset uint64) (p []byte, next uint64) {
+	length, n := binary.Uvarint(s.data[offset:])
+	start := offset + uint64(n)
+	next = start + length
+	return s.data[start:next], next
+}
+
+// find returns the slot p is in or the empty slot it should go in
+func (s *pathSet) find(p string) (slot int, found bool) {
+	mask := uint64(len(s.slots) - 1)
+	for i := hashPath(p) & mask; ; i = (i + 1) & mask {
+		v := s.slots[i]
+		if v == 0 {
+			return int(i), false
+		}
+		if got, _ := s.at(v - 1); string(got) == p {
+			return int(i), true
+		}
+	}
+}
+
+// grow doubles the size of the hash table
+func (s *pathSet) grow() {
+	size := 2 * len(s.slots)
+	if size < 16 {
+		size = 16
+	}
+	s.slots = make([]uint64, size)
+	mask := uint64(size - 1)
+	for offset := uint64(0); offset < uint64(len(s.data)); {
+		p, next := s.at(offset)
+		i := hashPath(string(p)) & mask
+		for s.slots[i] != 0 {
+			i = (i + 1) & mask
+		}
+		s.slots[i] = offset + 1
+		offset = next
+	}
+}
+
+// add adds p to the set returning false if it was already there
+func (s *pathSet) add(p string) bool {
+	// Keep the load factor below 3/4
+	if (s.n+1)*4 > len(s.slots)*3 {
+		s.grow()
+	}
+	slot, found := s.find(p)
+	if found {
+		return false
+	}
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(p)))
+	s.slots[slot] = uint64(len(s.data)) + 1
+	s.data = append(s.data, buf[:n]...)
+	s.data = append(s.data, p...)
+	s.n++
+	return true
+}
+
+// contains returns true if p is in the set
+func (s *pathSet) contains(p string) bool {
+	if s.n == 0 {
+		return false
+	}
+	_, found := s.find(p)
+	return found
+}
+
+// len returns the number of paths in the set
+func (s *pathSet) len() int {
+	return s.n
+}
+
+// forEach calls fn for each path in the set in the order they were
+// added, stopping at the first error
+func (s *pathSet) forEach(fn func(p string) error) error {
+	for offset := uint64(0); offset < uint64(len(s.data)); {
+		p, next := s.at(offset)
+		err := fn(string(p))
+		if err != nil {
+			return err
+		}
+		offset = next
+	}
+	return nil
+}
+
+// toMap returns the paths in the set as a FilesMap
+func (s *pathSet) toMap() FilesMap {
+	m := make(FilesMap, s.n)
+	_ = s.forEach(func(p string) error {
+		m[p] = struct{}{}
+		return nil
+	})
+	return m
+}
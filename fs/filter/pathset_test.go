@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathSet(t *testing.T) {
+	s := newPathSet()
+	assert.Equal(t, 0, s.len())
+	assert.False(t, s.contains(""))
+	assert.False(t, s.contains("potato"))
+
+	// Add enough paths to make the table grow a few times
+	const n = 1000
+	var want []string
+	for i := 0; i < n; i++ {
+		p := fmt.Sprintf("dir%d/file%d.txt", i%7, i)
+		want = append(want, p)
+		assert.True(t, s.add(p), p)
+	}
+	assert.False(t, s.add("dir0/file0.txt"))
+	assert.True(t, s.add(""))
+	want = append(want, "")
+	assert.Equal(t, n+1, s.len())
+
+	for _, p := range want {
+		assert.True(t, s.contains(p), p)
+	}
+	for _, p := range []string{"dir0", "dir0/file1.txt", "file0.txt", "dir0/file0.txt2"} {
+		assert.False(t, s.contains(p), p)
+	}
+
+	// forEach returns the paths in the order they were added
+	var got []string
+	require.NoError(t, s.forEach(func(p string) error {
+		got = append(got, p)
+		return nil
+	}))
+	assert.Equal(t, want, got)
+
+	// forEach stops at the first error
+	calls := 0
+	err := s.forEach(func(p string) error {
+		calls++
+		return assert.AnError
+	})
+	assert.Equal(t, assert.AnError, err)
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, len(want), len(s.toMap()))
+}
+
+func TestPathSetLongPath(t *testing.T) {
+	s := newPathSet()
+	long := string(make([]byte, 1000))
+	assert.True(t, s.add(long))
+	assert.True(t, s.add("short"))
+	assert.True(t, s.contains(long))
+	assert.True(t, s.contains("short"))
+	assert.False(t, s.contains(long[1:]))
+}
@@ -53,6 +53,7 @@ var (
 	ErrorCantCopy                    = errors.New("can't copy object - incompatible remotes")
 	ErrorCantMove                    = errors.New("can't move object - incompatible remotes")
 	ErrorCantDirMove                 = errors.New("can't move directory - incompatible remotes")
+	ErrorCantHardLink                = errors.New("can't hard link object - incompatible remotes")
 	ErrorCantUploadEmptyFiles        = errors.New("can't upload empty files to this remote")
 	ErrorDirExists                   = errors.New("can't copy directory - destination already exists")
 	ErrorCantSetModTime              = errors.New("can't set modified time")
@@ -404,6 +405,13 @@ type ParentIDer interface {
 	ParentID() string
 }
 
+// HardLinkIDer is an optional interface for Object
+type HardLinkIDer interface {
+	// HardLinkID returns an identifier shared by all the names of
+	// a hard linked object, or "" if the object has only one name
+	HardLinkID() string
+}
+
 // ObjectUnWrapper is an optional interface for Object
 type ObjectUnWrapper interface {
 	// UnWrap returns the Object that this Object is wrapping or
@@ -418,6 +426,28 @@ type SetTierer interface {
 	SetTier(tier string) error
 }
 
+// Patcher is an optional interface for Object
+//
+// It is used by --write-changed-blocks which reads the whole of the existing Object
+// to compare it with the source, so it should only be implemented by
+// backends where that is cheap, ie local disks.
+type Patcher interface {
+	// OpenPatch opens the Object for writing in place without
+	// truncating it so that just the changed parts can be
+	// rewritten.
+	OpenPatch(ctx context.Context) (PatchWriter, error)
+}
+
+// PatchWriter is returned by Patcher.OpenPatch
+//
+// Close must be called when finished to update the Object's metadata.
+type PatchWriter interface {
+	io.WriterAt
+	// Truncate sets the size of the Object
+	Truncate(size int64) error
+	io.Closer
+}
+
 // GetTierer is an optional interface for Object
 type GetTierer interface {
 	// GetTier returns storage tier or class of the Object
@@ -510,6 +540,32 @@ type WriterAtCloser interface {
 	io.Closer
 }
 
+// ChunkWriterInfo describes how a backend would like to be sent chunks
+// by a ChunkWriter
+type ChunkWriterInfo struct {
+	ChunkSize    int64 // preferred size of each chunk
+	MinChunkSize int64 // minimum size of each chunk except the last
+	MaxChunks    int   // maximum number of chunks or 0 for no limit
+}
+
+// ChunkWriter is returned by OpenChunkWriter to upload an object in
+// chunks of a size which doesn't need to be known in advance
+type ChunkWriter interface {
+	// WriteChunk uploads chunk number chunkNumber (starting from 0)
+	// with the data in reader, returning the number of bytes written.
+	//
+	// Chunks will be written in order, one at a time. The reader is
+	// only valid until WriteChunk returns.
+	WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (bytesWritten int64, err error)
+
+	// Close completes the upload, after which the object should be
+	// readable with NewObject
+	Close(ctx context.Context) error
+
+	// Abort cancels the upload, discarding any chunks written
+	Abort(ctx context.Context) error
+}
+
 // Features describe the optional features of the Fs
 type Features struct {
 	// Feature flags, whether Fs
@@ -517,6 +573,8 @@ type Features struct {
 	DuplicateFiles          bool // allows duplicate files
 	ReadMimeType            bool // can read the mime type of objects
 	WriteMimeType           bool // can set the mime type of objects
+	ReadMetadata            bool // can read the metadata of objects
+	WriteMetadata           bool // can set the metadata of objects
 	CanHaveEmptyDirectories bool // can have empty directories
 	BucketBased             bool // is bucket based (like s3, swift, etc.)
 	BucketBasedRootOK       bool // is bucket based and can use from root
@@ -643,6 +701,14 @@ type Features struct {
 	// It truncates any existing object
 	OpenWriterAt func(ctx context.Context, remote string, size int64) (WriterAtCloser, error)
 
+	// OpenChunkWriter opens a handle to upload the object at remote
+	// in chunks, for example using a multipart upload.
+	//
+	// The size of src may be -1 if not known.
+	//
+	// It truncates any existing object
+	OpenChunkWriter func(ctx context.Context, remote string, src ObjectInfo, options ...OpenOption) (ChunkWriterInfo, ChunkWriter, error)
+
 	// UserInfo returns info about the connected user
 	UserInfo func(ctx context.Context) (map[string]string, error)
 
@@ -663,6 +729,25 @@ type Features struct {
 	// Shutdown the backend, closing any background tasks and any
 	// cached connections.
 	Shutdown func(ctx context.Context) error
+
+	// HardLink makes remote a hard link to the existing object src
+	// so that both names share the same content.
+	//
+	// Will only be called if src.Fs().Name() == f.Name()
+	//
+	// If it isn't possible then return fs.ErrorCantHardLink
+	HardLink func(ctx context.Context, src Object, remote string) (Object, error)
+
+	// DirSetModTime sets the modification time of the directory dir
+	//
+	// If the directory doesn't exist then return fs.ErrorDirNotFound
+	DirSetModTime func(ctx context.Context, dir string, modTime time.Time) error
+
+	// HealthCheck checks the credentials are valid and the remote
+	// can be reached using the cheapest call available.
+	//
+	// It should not list directories.
+	HealthCheck func(ctx context.Context) error
 }
 
 // Disable nil's out the named feature.  If it isn't found then it
@@ -778,6 +863,9 @@ func (ft *Features) Fill(ctx context.Context, f Fs) *Features {
 	if do, ok := f.(OpenWriterAter); ok {
 		ft.OpenWriterAt = do.OpenWriterAt
 	}
+	if do, ok := f.(OpenChunkWriterer); ok {
+		ft.OpenChunkWriter = do.OpenChunkWriter
+	}
 	if do, ok := f.(UserInfoer); ok {
 		ft.UserInfo = do.UserInfo
 	}
@@ -790,6 +878,15 @@ func (ft *Features) Fill(ctx context.Context, f Fs) *Features {
 	if do, ok := f.(Shutdowner); ok {
 		ft.Shutdown = do.Shutdown
 	}
+	if do, ok := f.(HardLinker); ok {
+		ft.HardLink = do.HardLink
+	}
+	if do, ok := f.(DirSetModTimer); ok {
+		ft.DirSetModTime = do.DirSetModTime
+	}
+	if do, ok := f.(HealthChecker); ok {
+		ft.HealthCheck = do.HealthCheck
+	}
 	return ft.DisableList(GetConfig(ctx).DisableFeatures)
 }
 
@@ -805,6 +902,8 @@ func (ft *Features) Mask(ctx context.Context, f Fs) *Features {
 	ft.DuplicateFiles = ft.DuplicateFiles && mask.DuplicateFiles
 	ft.ReadMimeType = ft.ReadMimeType && mask.ReadMimeType
 	ft.WriteMimeType = ft.WriteMimeType && mask.WriteMimeType
+	ft.ReadMetadata = ft.ReadMetadata && mask.ReadMetadata
+	ft.WriteMetadata = ft.WriteMetadata && mask.WriteMetadata
 	ft.CanHaveEmptyDirectories = ft.CanHaveEmptyDirectories && mask.CanHaveEmptyDirectories
 	ft.BucketBased = ft.BucketBased && mask.BucketBased
 	ft.BucketBasedRootOK = ft.BucketBasedRootOK && mask.BucketBasedRootOK
@@ -863,6 +962,9 @@ func (ft *Features) Mask(ctx context.Context, f Fs) *Features {
 	if mask.OpenWriterAt == nil {
 		ft.OpenWriterAt = nil
 	}
+	if mask.OpenChunkWriter == nil {
+		ft.OpenChunkWriter = nil
+	}
 	if mask.UserInfo == nil {
 		ft.UserInfo = nil
 	}
@@ -873,6 +975,15 @@ func (ft *Features) Mask(ctx context.Context, f Fs) *Features {
 	if mask.Shutdown == nil {
 		ft.Shutdown = nil
 	}
+	if mask.HardLink == nil {
+		ft.HardLink = nil
+	}
+	if mask.DirSetModTime == nil {
+		ft.DirSetModTime = nil
+	}
+	if mask.HealthCheck == nil {
+		ft.HealthCheck = nil
+	}
 	return ft.DisableList(GetConfig(ctx).DisableFeatures)
 }
 
@@ -1010,6 +1121,10 @@ type PutStreamer interface {
 	PutStream(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
 }
 
+// LinkExpireNever is the expire passed to PublicLink for a link
+// which shouldn't expire
+const LinkExpireNever = Duration(time.Hour * 24 * 365 * 100)
+
 // PublicLinker is an optional interface for Fs
 type PublicLinker interface {
 	// PublicLink generates a public link to the remote path (usually readable by anyone)
@@ -1082,6 +1197,17 @@ type OpenWriterAter interface {
 	OpenWriterAt(ctx context.Context, remote string, size int64) (WriterAtCloser, error)
 }
 
+// OpenChunkWriterer is an optional interface for Fs
+type OpenChunkWriterer interface {
+	// OpenChunkWriter opens a handle to upload the object at remote
+	// in chunks, for example using a multipart upload.
+	//
+	// The size of src may be -1 if not known.
+	//
+	// It truncates any existing object
+	OpenChunkWriter(ctx context.Context, remote string, src ObjectInfo, options ...OpenOption) (ChunkWriterInfo, ChunkWriter, error)
+}
+
 // UserInfoer is an optional interface for Fs
 type UserInfoer interface {
 	// UserInfo returns info about the connected user
@@ -1125,6 +1251,34 @@ type Shutdowner interface {
 	Shutdown(ctx context.Context) error
 }
 
+// HardLinker is an optional interface for Fs
+type HardLinker interface {
+	// HardLink makes remote a hard link to the existing object src
+	// so that both names share the same content.
+	//
+	// Will only be called if src.Fs().Name() == f.Name()
+	//
+	// If it isn't possible then return fs.ErrorCantHardLink
+	HardLink(ctx context.Context, src Object, remote string) (Object, error)
+}
+
+// DirSetModTimer is an optional interface for Fs
+type DirSetModTimer interface {
+	// DirSetModTime sets the modification time of the directory dir
+	//
+	// If the directory doesn't exist then return fs.ErrorDirNotFound
+	DirSetModTime(ctx context.Context, dir string, modTime time.Time) error
+}
+
+// HealthChecker is an optional interface for Fs
+type HealthChecker interface {
+	// HealthCheck checks the credentials are valid and the remote
+	// can be reached using the cheapest call available.
+	//
+	// It should not list directories.
+	HealthCheck(ctx context.Context) error
+}
+
 // ObjectsChan is a channel of Objects
 type ObjectsChan chan Object
 
@@ -1208,7 +1362,11 @@ func MustFind(name string) *RegInfo {
 // ParseRemote deconstructs a path into configName, fsPath, looking up
 // the fsName in the config file (returning NotFoundInConfigFile if not found)
 func ParseRemote(path string) (fsInfo *RegInfo, configName, fsPath string, connectionStringConfig configmap.Simple, err error) {
-	parsed, err := fspath.Parse(path)
+	parse := fspath.Parse
+	if GetConfig(context.TODO()).StrictPaths {
+		parse = fspath.ParseStrict
+	}
+	parsed, err := parse(path)
 	if err != nil {
 		return nil, "", "", nil, err
 	}
@@ -1376,11 +1534,33 @@ func ConfigFs(path string) (fsInfo *RegInfo, configName, fsPath string, config *
 // On Windows avoid single character remote names as they can be mixed
 // up with drive letters.
 func NewFs(ctx context.Context, path string) (Fs, error) {
+	return NewFsWithOptions(ctx, path, nil)
+}
+
+// NewFsWithOptions makes a new Fs object from the path as NewFs does
+// with options added to any in the connection string of the path.
+//
+// This passes options to the backend without putting them in the
+// path, so secrets in them aren't shown in the logs or the name of
+// the Fs.
+func NewFsWithOptions(ctx context.Context, path string, options configmap.Simple) (Fs, error) {
 	Debugf(nil, "Creating backend with remote %q", path)
-	fsInfo, configName, fsPath, config, err := ConfigFs(path)
+	fsInfo, configName, fsPath, connectionStringConfig, err := ParseRemote(path)
 	if err != nil {
 		return nil, err
 	}
+	if len(options) > 0 {
+		merged := make(configmap.Simple, len(connectionStringConfig)+len(options))
+		for key, value := range options {
+			merged[key] = value
+		}
+		for key, value := range connectionStringConfig {
+			merged[key] = value
+		}
+		connectionStringConfig = merged
+	}
+	config := ConfigMap(fsInfo, configName, connectionStringConfig)
+	remoteName := configName
 	// Now discover which config items have been overridden,
 	// either by the config string, command line flags or
 	// environment variables
@@ -1409,6 +1589,25 @@ func NewFs(ctx context.Context, path string) (Fs, error) {
 		// These need to work as filesystem names as the VFS cache will use them
 		configName += suffix
 	}
+	// Use the per remote proxy for HTTP connections if set
+	if proxy, ok := config.Get(ConfigProxy); ok && proxy != "" && fsInfo.Options.Get(ConfigProxy) != nil {
+		if _, err := ParseProxy(proxy); err != nil {
+			return nil, err
+		}
+		var ci *ConfigInfo
+		ctx, ci = AddConfig(ctx)
+		ci.Proxy = proxy
+	}
+	// Count the API calls if the remote has a budget
+	if value, ok := config.Get(ConfigAPIBudget); ok && value != "" && fsInfo.Options.Get(ConfigAPIBudget) != nil {
+		budget, err := parseAPIBudget(value)
+		if err != nil {
+			return nil, err
+		}
+		if budget > 0 {
+			ctx = withAPIBudget(ctx, remoteName, budget)
+		}
+	}
 	return fsInfo.NewFs(ctx, configName, fsPath, config)
 }
 
@@ -1496,7 +1695,7 @@ func NewPacer(ctx context.Context, c pacer.Calculator) *Pacer {
 	}
 	p := &Pacer{
 		Pacer: pacer.New(
-			pacer.InvokerOption(pacerInvoker),
+			pacer.InvokerOption(apiBudgetInvoker(ctx, pacerInvoker)),
 			pacer.MaxConnectionsOption(ci.Checkers+ci.Transfers),
 			pacer.RetriesOption(retries),
 			pacer.CalculatorOption(c),
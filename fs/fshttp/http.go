@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httputil"
+	"net/url"
 	"sync"
 	"time"
 
@@ -26,6 +27,10 @@ const (
 	separatorResp = "<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<"
 )
 
+// maxResponseHeaderBytes is the most response header the transport
+// will read so a misbehaving server can't use up all the memory
+const maxResponseHeaderBytes = 1024 * 1024
+
 var (
 	transport    http.RoundTripper
 	noTransport  = new(sync.Once)
@@ -37,6 +42,9 @@ var (
 // Should only be used for testing.
 func ResetTransport() {
 	noTransport = new(sync.Once)
+	proxyTransportsMu.Lock()
+	proxyTransports = map[string]http.RoundTripper{}
+	proxyTransportsMu.Unlock()
 }
 
 // NewTransportCustom returns an http.RoundTripper with the correct timeouts.
@@ -48,11 +56,12 @@ func NewTransportCustom(ctx context.Context, customize func(*http.Transport)) ht
 	// This also means we get new stuff when it gets added to go
 	t := new(http.Transport)
 	structs.SetDefaults(t, http.DefaultTransport.(*http.Transport))
-	t.Proxy = http.ProxyFromEnvironment
+	t.Proxy = proxyFunc(ci)
 	t.MaxIdleConnsPerHost = 2 * (ci.Checkers + ci.Transfers + 1)
 	t.MaxIdleConns = 2 * t.MaxIdleConnsPerHost
 	t.TLSHandshakeTimeout = ci.ConnectTimeout
 	t.ResponseHeaderTimeout = ci.Timeout
+	t.MaxResponseHeaderBytes = maxResponseHeaderBytes
 
 	// TLS Config
 	t.TLSClientConfig = &tls.Config{
@@ -109,8 +118,49 @@ func NewTransportCustom(ctx context.Context, customize func(*http.Transport)) ht
 	return newTransport(ci, t)
 }
 
+// proxyFunc returns the function to choose the proxy for each request.
+//
+// The per remote proxy takes precedence over --socks5-proxy which
+// takes precedence over the proxy environment variables.
+func proxyFunc(ci *fs.ConfigInfo) func(*http.Request) (*url.URL, error) {
+	var (
+		proxyURL *url.URL
+		err      error
+	)
+	switch {
+	case ci.Proxy != "":
+		proxyURL, err = fs.ParseProxy(ci.Proxy)
+	case ci.Socks5Proxy != "":
+		proxyURL, err = fs.ParseSocks5Proxy(ci.Socks5Proxy)
+	default:
+		return http.ProxyFromEnvironment
+	}
+	if err != nil {
+		log.Fatalf("Failed to configure proxy: %v", err)
+	}
+	return http.ProxyURL(proxyURL)
+}
+
+var (
+	proxyTransportsMu sync.Mutex
+	proxyTransports   = map[string]http.RoundTripper{}
+)
+
 // NewTransport returns an http.RoundTripper with the correct timeouts
+//
+// Remotes with their own proxy share a transport per proxy, all other
+// remotes share a single transport.
 func NewTransport(ctx context.Context) http.RoundTripper {
+	if proxy := fs.GetConfig(ctx).Proxy; proxy != "" {
+		proxyTransportsMu.Lock()
+		defer proxyTransportsMu.Unlock()
+		t := proxyTransports[proxy]
+		if t == nil {
+			t = NewTransportCustom(ctx, nil)
+			proxyTransports[proxy] = t
+		}
+		return t
+	}
 	(*noTransport).Do(func() {
 		transport = NewTransportCustom(ctx, nil)
 	})
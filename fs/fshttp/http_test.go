@@ -1,9 +1,13 @@
 package fshttp
 
 import (
+	"context"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/artpar/rclone/fs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCleanAuth(t *testing.T) {
@@ -45,3 +49,45 @@ func TestCleanAuths(t *testing.T) {
 		assert.Equal(t, test.want, got, test.in)
 	}
 }
+
+func TestProxyFunc(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://example.com/", nil)
+	for _, test := range []struct {
+		proxy       string
+		socks5Proxy string
+		want        string
+	}{
+		{"", "proxy:1080", "socks5://proxy:1080"},
+		{"http://remote-proxy:3128", "proxy:1080", "http://remote-proxy:3128"},
+		{"socks5://remote-proxy:1080", "", "socks5://remote-proxy:1080"},
+	} {
+		ci := &fs.ConfigInfo{Proxy: test.proxy, Socks5Proxy: test.socks5Proxy}
+		got, err := proxyFunc(ci)(req)
+		require.NoError(t, err)
+		assert.Equal(t, test.want, got.String())
+	}
+}
+
+func TestNewTransportProxy(t *testing.T) {
+	ResetTransport()
+	defer ResetTransport()
+	ctx := context.Background()
+	ctxA, ciA := fs.AddConfig(ctx)
+	ciA.Proxy = "http://proxy-a:3128"
+	ctxB, ciB := fs.AddConfig(ctx)
+	ciB.Proxy = "http://proxy-b:3128"
+
+	global := NewTransport(ctx)
+	a := NewTransport(ctxA)
+	b := NewTransport(ctxB)
+	assert.True(t, global != a)
+	assert.True(t, global != b)
+	assert.True(t, a != b)
+	assert.True(t, a == NewTransport(ctxA))
+	assert.True(t, global == NewTransport(ctx))
+
+	req := httptest.NewRequest("GET", "http://example.com/", nil)
+	got, err := a.(*Transport).Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy-a:3128", got.String())
+}
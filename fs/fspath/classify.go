@@ -0,0 +1,111 @@
+package fspath
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/artpar/rclone/fs/driveletter"
+	"github.com/pkg/errors"
+)
+
+// Kind describes what sort of path was passed to Classify
+type Kind int
+
+// Kinds of path returned by Classify
+const (
+	KindLocal    Kind = iota // a local path, eg "/path/to/dir" or "dir"
+	KindDrive                // a local path starting with a drive letter, eg "C:\dir" (Windows only)
+	KindUNC                  // a local UNC path, eg "\\server\share\dir" (Windows only)
+	KindRemote               // a remote from the config file, eg "remote:dir"
+	KindOnTheFly             // an on the fly remote, eg ":s3,provider=AWS:bucket"
+)
+
+// String turns a Kind into a string
+func (k Kind) String() string {
+	switch k {
+	case KindLocal:
+		return "local"
+	case KindDrive:
+		return "drive"
+	case KindUNC:
+		return "unc"
+	case KindRemote:
+		return "remote"
+	case KindOnTheFly:
+		return "on-the-fly"
+	}
+	return fmt.Sprintf("Unknown kind #%d", int(k))
+}
+
+// IsLocal returns true if the Kind refers to the local filesystem
+func (k Kind) IsLocal() bool {
+	return k == KindLocal || k == KindDrive || k == KindUNC
+}
+
+// isUNC returns true if path is a UNC path on this OS
+func isUNC(path string) bool {
+	return runtime.GOOS == "windows" && (strings.HasPrefix(path, `\\`) || strings.HasPrefix(path, `//`))
+}
+
+// Classify returns what kind of path path is using the same rules as
+// Parse, so tooling can tell reliably whether rclone will treat a path
+// as local or remote.
+//
+// Drive letters and UNC paths are only recognised on Windows.
+func Classify(path string) (kind Kind, err error) {
+	if isUNC(path) {
+		return KindUNC, nil
+	}
+	parsed, err := Parse(path)
+	if err != nil {
+		return KindLocal, err
+	}
+	switch {
+	case parsed.Name == "":
+		if len(path) >= 2 && path[1] == ':' && driveletter.IsDriveLetter(path[:1]) {
+			return KindDrive, nil
+		}
+		return KindLocal, nil
+	case strings.HasPrefix(parsed.Name, ":"):
+		return KindOnTheFly, nil
+	}
+	return KindRemote, nil
+}
+
+// isLetter returns true if c is an ASCII letter
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// checkAmbiguous returns an error if path could mean different
+// things on different OSes or depending on the current directory.
+func checkAmbiguous(path string) error {
+	if len(path) < 2 || path[1] != ':' || !isLetter(path[0]) {
+		return nil
+	}
+	letter, rest := path[:1], path[2:]
+	if runtime.GOOS != "windows" {
+		return errors.Errorf("%q is ambiguous: %q is a one letter remote name which would be read as a drive letter on Windows - rename the remote to something longer or use %q if you meant a local path", path, letter+":", "./"+path)
+	}
+	if rest != "" && (rest[0] == '\\' || rest[0] == '/') {
+		return nil
+	}
+	return errors.Errorf("%q is ambiguous: it is relative to the current directory on drive %s - use an absolute path such as %q instead", path, letter+":", letter+`:\`+rest)
+}
+
+// ParseStrict is like Parse but also rejects remote specs which are
+// ambiguous.
+//
+// On all OSes "x:path" with a one letter remote name is rejected as
+// it would be read as a drive letter on Windows. On Windows drive
+// relative paths such as "C:dir" are rejected too - only "C:\dir" is
+// accepted.
+//
+// The errors returned suggest how to write the path unambiguously.
+func ParseStrict(path string) (parsed Parsed, err error) {
+	if err = checkAmbiguous(path); err != nil {
+		return parsed, err
+	}
+	return Parse(path)
+}
@@ -0,0 +1,83 @@
+package fspath
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKindString(t *testing.T) {
+	assert.Equal(t, "local", KindLocal.String())
+	assert.Equal(t, "on-the-fly", KindOnTheFly.String())
+	assert.Equal(t, "Unknown kind #99", Kind(99).String())
+	assert.True(t, KindUNC.IsLocal())
+	assert.False(t, KindRemote.IsLocal())
+}
+
+func TestClassify(t *testing.T) {
+	windows := runtime.GOOS == "windows"
+	for _, test := range []struct {
+		in      string
+		want    Kind
+		wantWin Kind
+		wantErr bool
+	}{
+		{in: "", wantErr: true},
+		{in: "/path/to/dir", want: KindLocal, wantWin: KindLocal},
+		{in: "dir", want: KindLocal, wantWin: KindLocal},
+		{in: "./x:dir", want: KindLocal, wantWin: KindLocal},
+		{in: "remote:dir", want: KindRemote, wantWin: KindRemote},
+		{in: "remote,param=true:dir", want: KindRemote, wantWin: KindRemote},
+		{in: ":s3,provider=AWS:bucket", want: KindOnTheFly, wantWin: KindOnTheFly},
+		{in: "c:dir", want: KindRemote, wantWin: KindDrive},
+		{in: `C:\dir`, want: KindRemote, wantWin: KindDrive},
+		{in: `\\server\share\dir`, want: KindLocal, wantWin: KindUNC},
+		{in: `//server/share/dir`, want: KindLocal, wantWin: KindUNC},
+		{in: "rem*ote:dir", wantErr: true},
+	} {
+		got, err := Classify(test.in)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		want := test.want
+		if windows {
+			want = test.wantWin
+		}
+		assert.Equal(t, want, got, test.in)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	windows := runtime.GOOS == "windows"
+	for _, test := range []struct {
+		in      string
+		wantErr string
+		winErr  string
+	}{
+		{in: "/path/to/dir"},
+		{in: "remote:dir"},
+		{in: "ab:dir"},
+		{in: "./c:dir"},
+		{in: "1:dir"},
+		{in: "c:dir", wantErr: `"c:dir" is ambiguous: "c:" is a one letter remote name`, winErr: `"c:dir" is ambiguous: it is relative to the current directory on drive c: - use an absolute path such as "c:\\dir" instead`},
+		{in: "c:", wantErr: `"c:" is ambiguous`, winErr: `"c:" is ambiguous`},
+		{in: `C:\dir`, wantErr: `"C:\\dir" is ambiguous`},
+		{in: `C:/dir`, wantErr: `"C:/dir" is ambiguous`},
+	} {
+		_, err := ParseStrict(test.in)
+		wantErr := test.wantErr
+		if windows {
+			wantErr = test.winErr
+		}
+		if wantErr == "" {
+			assert.NoError(t, err, test.in)
+			continue
+		}
+		require.Error(t, err, test.in)
+		assert.Contains(t, err.Error(), wantErr, test.in)
+	}
+}
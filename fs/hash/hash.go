@@ -3,6 +3,7 @@ package hash
 import (
 	"crypto/md5"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"hash"
@@ -63,6 +64,9 @@ var (
 
 	// CRC32 indicates CRC-32 support
 	CRC32 Type
+
+	// SHA256 indicates SHA-256 support
+	SHA256 Type
 )
 
 func init() {
@@ -70,6 +74,7 @@ func init() {
 	SHA1 = RegisterHash("SHA-1", 40, sha1.New)
 	Whirlpool = RegisterHash("Whirlpool", 128, whirlpool.New)
 	CRC32 = RegisterHash("CRC-32", 8, func() hash.Hash { return crc32.NewIEEE() })
+	SHA256 = RegisterHash("SHA-256", 64, sha256.New)
 }
 
 // Supported returns a set of all the supported hashes by
@@ -138,6 +143,7 @@ func (h Type) String() string {
 func (h *Type) Set(s string) error {
 	if s == "None" {
 		*h = None
+		return nil
 	}
 
 	for _, v := range hashes {
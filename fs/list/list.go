@@ -8,6 +8,7 @@ import (
 
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/filter"
+	"github.com/artpar/rclone/fs/tracing"
 	"github.com/pkg/errors"
 )
 
@@ -21,7 +22,12 @@ import (
 // Files will be returned in sorted order
 func DirSorted(ctx context.Context, f fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err error) {
 	// Get unfiltered entries from the fs
-	entries, err = f.List(ctx, dir)
+	listCtx, span := tracing.Start(ctx, "list")
+	span.SetAttribute("fs", fs.ConfigString(f))
+	span.SetAttribute("dir", dir)
+	entries, err = f.List(listCtx, dir)
+	span.SetAttribute("entries", len(entries))
+	span.End(err)
 	if err != nil {
 		return nil, err
 	}
@@ -164,9 +164,16 @@ func LogPrintf(level LogLevel, o interface{}, text string, args ...interface{})
 	}
 }
 
+// logLevel returns the log level in force for a message about o with
+// args, taking account of --log-level-rules
+func logLevel(o interface{}, args []interface{}) LogLevel {
+	ci := GetConfig(context.TODO())
+	return ci.LogLevelRules.Level(ci.LogLevel, o, args)
+}
+
 // LogLevelPrintf writes logs at the given level
 func LogLevelPrintf(level LogLevel, o interface{}, text string, args ...interface{}) {
-	if GetConfig(context.TODO()).LogLevel >= level {
+	if logLevel(o, args) >= level {
 		LogPrintf(level, o, text, args...)
 	}
 }
@@ -174,7 +181,7 @@ func LogLevelPrintf(level LogLevel, o interface{}, text string, args ...interfac
 // Errorf writes error log output for this Object or Fs.  It
 // should always be seen by the user.
 func Errorf(o interface{}, text string, args ...interface{}) {
-	if GetConfig(context.TODO()).LogLevel >= LogLevelError {
+	if logLevel(o, args) >= LogLevelError {
 		LogPrintf(LogLevelError, o, text, args...)
 	}
 }
@@ -185,7 +192,7 @@ func Errorf(o interface{}, text string, args ...interface{}) {
 // important things the user should see.  The user can filter these
 // out with the -q flag.
 func Logf(o interface{}, text string, args ...interface{}) {
-	if GetConfig(context.TODO()).LogLevel >= LogLevelNotice {
+	if logLevel(o, args) >= LogLevelNotice {
 		LogPrintf(LogLevelNotice, o, text, args...)
 	}
 }
@@ -194,7 +201,7 @@ func Logf(o interface{}, text string, args ...interface{}) {
 // level for logging transfers, deletions and things which should
 // appear with the -v flag.
 func Infof(o interface{}, text string, args ...interface{}) {
-	if GetConfig(context.TODO()).LogLevel >= LogLevelInfo {
+	if logLevel(o, args) >= LogLevelInfo {
 		LogPrintf(LogLevelInfo, o, text, args...)
 	}
 }
@@ -202,7 +209,7 @@ func Infof(o interface{}, text string, args ...interface{}) {
 // Debugf writes debugging output for this Object or Fs.  Use this for
 // debug only.  The user must have to specify -vv to see this.
 func Debugf(o interface{}, text string, args ...interface{}) {
-	if GetConfig(context.TODO()).LogLevel >= LogLevelDebug {
+	if logLevel(o, args) >= LogLevelDebug {
 		LogPrintf(LogLevelDebug, o, text, args...)
 	}
 }
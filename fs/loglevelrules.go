@@ -0,0 +1,134 @@
+package fs
+
+import (
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Keys which can be used in a LogLevelRule
+const (
+	LogRuleBackend   = "backend"   // the type of the backend, e.g. webdav
+	LogRuleRemote    = "remote"    // the name of the remote in the config file
+	LogRuleOperation = "operation" // the operation, e.g. delete
+)
+
+// LogLevelRule sets the log level for log messages about the backend,
+// remote or operation matching Key=Value
+type LogLevelRule struct {
+	Key   string
+	Value string
+	Level LogLevel
+}
+
+// LogLevelRules is a list of rules set with --log-level-rules
+type LogLevelRules []LogLevelRule
+
+// String turns LogLevelRules into a string
+func (rules LogLevelRules) String() string {
+	out := make([]string, len(rules))
+	for i, rule := range rules {
+		out[i] = rule.Key + "=" + rule.Value + ":" + rule.Level.String()
+	}
+	return strings.Join(out, ",")
+}
+
+// Set LogLevelRules from a comma separated list of key=value:LEVEL
+func (rules *LogLevelRules) Set(s string) error {
+	var newRules LogLevelRules
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		colon := strings.LastIndex(part, ":")
+		equals := strings.Index(part, "=")
+		if colon < 0 || equals < 0 || equals > colon {
+			return errors.Errorf("log level rule %q must be in the form key=value:LEVEL", part)
+		}
+		rule := LogLevelRule{
+			Key:   strings.ToLower(part[:equals]),
+			Value: part[equals+1 : colon],
+		}
+		switch rule.Key {
+		case LogRuleBackend, LogRuleRemote, LogRuleOperation:
+		default:
+			return errors.Errorf("unknown key %q in log level rule %q - must be %s, %s or %s", rule.Key, part, LogRuleBackend, LogRuleRemote, LogRuleOperation)
+		}
+		if rule.Value == "" {
+			return errors.Errorf("empty value in log level rule %q", part)
+		}
+		if err := rule.Level.Set(strings.ToUpper(part[colon+1:])); err != nil {
+			return errors.Wrapf(err, "bad log level rule %q", part)
+		}
+		newRules = append(newRules, rule)
+	}
+	*rules = newRules
+	return nil
+}
+
+// Type of the value
+func (rules *LogLevelRules) Type() string {
+	return "string"
+}
+
+// Level returns the log level for a message about o with the args
+// passed to the logging call.
+//
+// If any rules match then the most verbose level of the matching
+// rules is returned, otherwise defaultLevel is.
+func (rules LogLevelRules) Level(defaultLevel LogLevel, o interface{}, args []interface{}) LogLevel {
+	if len(rules) == 0 {
+		return defaultLevel
+	}
+	var backend, remote, operation string
+	var info Info
+	switch x := o.(type) {
+	case Info:
+		info = x
+	case ObjectInfo:
+		info = x.Fs()
+	}
+	if info != nil {
+		backend, remote = backendType(info), info.Name()
+	}
+	for _, arg := range args {
+		if item, ok := arg.(LogValueItem); ok && item.key == LogRuleOperation {
+			operation, _ = item.value.(string)
+		}
+	}
+	level, matched := defaultLevel, false
+	for _, rule := range rules {
+		var value string
+		switch rule.Key {
+		case LogRuleBackend:
+			value = backend
+		case LogRuleRemote:
+			value = remote
+		case LogRuleOperation:
+			value = operation
+		}
+		if value == "" || value != rule.Value {
+			continue
+		}
+		if !matched || rule.Level > level {
+			level, matched = rule.Level, true
+		}
+	}
+	return level
+}
+
+// backendType returns the type of the backend info is from, e.g.
+// "webdav", derived from the name of the package implementing it
+func backendType(info Info) string {
+	t := reflect.TypeOf(info)
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return path.Base(t.PkgPath())
+}
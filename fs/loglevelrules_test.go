@@ -0,0 +1,93 @@
+package fs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fstest/mockfs"
+	"github.com/artpar/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLevelRulesSet(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    fs.LogLevelRules
+		wantErr string
+	}{
+		{"", nil, ""},
+		{"backend=webdav:DEBUG", fs.LogLevelRules{
+			{Key: "backend", Value: "webdav", Level: fs.LogLevelDebug},
+		}, ""},
+		{"backend=webdav:debug, operation=delete:INFO,remote=my:remote:ERROR", fs.LogLevelRules{
+			{Key: "backend", Value: "webdav", Level: fs.LogLevelDebug},
+			{Key: "operation", Value: "delete", Level: fs.LogLevelInfo},
+			{Key: "remote", Value: "my:remote", Level: fs.LogLevelError},
+		}, ""},
+		{"backend=webdav", nil, "must be in the form"},
+		{"webdav:DEBUG", nil, "must be in the form"},
+		{"potato=webdav:DEBUG", nil, "unknown key"},
+		{"backend=:DEBUG", nil, "empty value"},
+		{"backend=webdav:LOUD", nil, "Unknown log level"},
+	} {
+		var rules fs.LogLevelRules
+		err := rules.Set(test.in)
+		if test.wantErr != "" {
+			require.Error(t, err, test.in)
+			assert.Contains(t, err.Error(), test.wantErr, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.want, rules, test.in)
+	}
+}
+
+func TestLogLevelRulesString(t *testing.T) {
+	var rules fs.LogLevelRules
+	assert.Equal(t, "", rules.String())
+	require.NoError(t, rules.Set("backend=webdav:DEBUG,operation=delete:info"))
+	assert.Equal(t, "backend=webdav:DEBUG,operation=delete:INFO", rules.String())
+}
+
+// objectWithFs is a mock object which returns f from Fs()
+type objectWithFs struct {
+	mockobject.Object
+	f fs.Info
+}
+
+func (o objectWithFs) Fs() fs.Info {
+	return o.f
+}
+
+func TestLogLevelRulesLevel(t *testing.T) {
+	f := mockfs.NewFs(context.Background(), "myremote", "root")
+	o := objectWithFs{Object: mockobject.New("potato"), f: f}
+	deleting := []interface{}{fs.LogValueHide(fs.LogRuleOperation, "delete")}
+
+	var rules fs.LogLevelRules
+	assert.Equal(t, fs.LogLevelNotice, rules.Level(fs.LogLevelNotice, f, nil))
+
+	require.NoError(t, rules.Set("backend=mockfs:DEBUG,operation=delete:INFO,remote=other:ERROR"))
+	for _, test := range []struct {
+		o    interface{}
+		args []interface{}
+		want fs.LogLevel
+	}{
+		{nil, nil, fs.LogLevelNotice},
+		{"dir", nil, fs.LogLevelNotice},
+		{"dir", deleting, fs.LogLevelInfo},
+		{f, nil, fs.LogLevelDebug},
+		{o, nil, fs.LogLevelDebug},
+		{o, deleting, fs.LogLevelDebug},
+		{mockobject.New("nofs"), deleting, fs.LogLevelInfo},
+	} {
+		assert.Equal(t, test.want, rules.Level(fs.LogLevelNotice, test.o, test.args), "%v %v", test.o, test.args)
+	}
+
+	// rules can make messages quieter as well as louder
+	require.NoError(t, rules.Set("remote=myremote:ERROR"))
+	assert.Equal(t, fs.LogLevelError, rules.Level(fs.LogLevelDebug, f, nil))
+	assert.Equal(t, fs.LogLevelDebug, rules.Level(fs.LogLevelDebug, "dir", nil))
+}
@@ -0,0 +1,59 @@
+// Metadata support for objects
+
+package fs
+
+import "context"
+
+// Metadata is the metadata of an object as a map of lower case keys
+// to values.
+//
+// The keys and the format of the values depend on the backend. Keys
+// which a backend doesn't know about are ignored when setting it.
+type Metadata map[string]string
+
+// Metadataer is an optional interface for Object
+type Metadataer interface {
+	// Metadata returns the metadata of the Object or nil if it
+	// doesn't have any
+	Metadata(ctx context.Context) (Metadata, error)
+}
+
+// SetMetadataer is an optional interface for Object
+type SetMetadataer interface {
+	// SetMetadata sets the metadata of the Object from metadata,
+	// ignoring any keys it doesn't know about
+	SetMetadata(ctx context.Context, metadata Metadata) error
+}
+
+// ReadOnlyer is an optional interface for Object
+type ReadOnlyer interface {
+	// ReadOnly returns true if the backend's permission metadata
+	// says the Object can't be modified or deleted, for example
+	// because the user can't edit it or it is under a retention
+	// lock.
+	//
+	// If fetch is false then no calls are made to the backend and
+	// false is returned if this isn't known yet.
+	ReadOnly(ctx context.Context, fetch bool) (bool, error)
+}
+
+// IsReadOnly returns true if the backend says o is read only. It
+// returns false if the backend doesn't support ReadOnlyer. See
+// ReadOnlyer for the meaning of fetch.
+func IsReadOnly(ctx context.Context, o Object, fetch bool) (bool, error) {
+	do, ok := UnWrapObject(o).(ReadOnlyer)
+	if !ok {
+		return false, nil
+	}
+	return do.ReadOnly(ctx, fetch)
+}
+
+// GetMetadata returns the metadata of o, or nil if it doesn't
+// support reading metadata
+func GetMetadata(ctx context.Context, o Object) (Metadata, error) {
+	do, ok := UnWrapObject(o).(Metadataer)
+	if !ok {
+		return nil, nil
+	}
+	return do.Metadata(ctx)
+}
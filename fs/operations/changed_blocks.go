@@ -0,0 +1,133 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/accounting"
+	"github.com/pkg/errors"
+)
+
+const (
+	// changedBlockSize is the size of the blocks compared by --write-changed-blocks
+	changedBlockSize = 64 * 1024
+	// changedBlocksMinSize is the smallest file --write-changed-blocks is used for
+	changedBlocksMinSize = 4 * changedBlockSize
+)
+
+// changedBlocksPatcher returns the Patcher to use if src should be copied
+// over dst by rewriting only the changed blocks or nil if it shouldn't.
+func changedBlocksPatcher(ctx context.Context, dst fs.Object, src fs.Object) fs.Patcher {
+	ci := fs.GetConfig(ctx)
+	if !ci.WriteChangedBlocks || dst == nil {
+		return nil
+	}
+	// Not worth it for small or unknown size files
+	if src.Size() < changedBlocksMinSize || dst.Size() <= 0 {
+		return nil
+	}
+	patcher, ok := dst.(fs.Patcher)
+	if !ok {
+		return nil
+	}
+	return patcher
+}
+
+// changedBlocksCopy copies src over dst by reading both and writing only the
+// blocks of src which differ from dst at the same offset.
+//
+// Note that this isn't a delta transfer like rsync - there are no
+// rolling checksums and both files are read in full, so this is only
+// useful when dst is local and only saves writes to it.
+//
+// This works well for files which are modified in place, for example
+// VM images and databases. Data which has been inserted or removed
+// will shift the rest of the file which will then be rewritten.
+//
+// dst is modified in place so if the copy fails it will contain a
+// mixture of old and new data.
+//
+// It returns a description of what was done.
+func changedBlocksCopy(ctx context.Context, patcher fs.Patcher, dst fs.Object, src fs.Object, tr *accounting.Transfer) (actionTaken string, err error) {
+	ci := fs.GetConfig(ctx)
+	in0, err := NewReOpen(ctx, src, ci.LowLevelRetries)
+	if err != nil {
+		return "", errors.Wrap(err, "write changed blocks: failed to open source object")
+	}
+	in := tr.Account(ctx, in0).WithBuffer() // account and buffer the transfer
+	defer fs.CheckClose(in, &err)
+	old, err := NewReOpen(ctx, dst, ci.LowLevelRetries)
+	if err != nil {
+		return "", errors.Wrap(err, "write changed blocks: failed to open destination object")
+	}
+	defer fs.CheckClose(old, &err)
+	out, err := patcher.OpenPatch(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "write changed blocks: failed to open destination for patching")
+	}
+	written, size, err := patchChangedBlocks(out, in, old, dst.Size())
+	closeErr := out.Close()
+	if err != nil {
+		return "", err
+	}
+	if closeErr != nil {
+		return "", errors.Wrap(closeErr, "write changed blocks: failed to close destination")
+	}
+	if size != src.Size() {
+		return "", errors.Errorf("write changed blocks: read %d bytes from source expecting %d", size, src.Size())
+	}
+	err = dst.SetModTime(ctx, src.ModTime(ctx))
+	if err != nil && err != fs.ErrorCantSetModTime && err != fs.ErrorCantSetModTimeWithoutDelete {
+		return "", errors.Wrap(err, "write changed blocks: failed to set modification time")
+	}
+	return fmt.Sprintf("Copied (wrote %v changed of %v)", fs.SizeSuffix(written), fs.SizeSuffix(size)), nil
+}
+
+// patchChangedBlocks reads in and old a block at a time and writes the blocks
+// of in which differ from old to out, setting the size of out to the
+// size of in if it differs from oldSize.
+//
+// It returns the number of bytes written and the size of in.
+func patchChangedBlocks(out fs.PatchWriter, in, old io.Reader, oldSize int64) (written, size int64, err error) {
+	var (
+		newBuf = make([]byte, changedBlockSize)
+		oldBuf = make([]byte, changedBlockSize)
+		oldEOF bool
+	)
+	for {
+		n, err := io.ReadFull(in, newBuf)
+		if err == io.EOF {
+			break
+		} else if err != nil && err != io.ErrUnexpectedEOF {
+			return written, size, errors.Wrap(err, "write changed blocks: failed to read source")
+		}
+		block := newBuf[:n]
+		var m int
+		if !oldEOF {
+			m, err = io.ReadFull(old, oldBuf[:n])
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				oldEOF = true
+			} else if err != nil {
+				return written, size, errors.Wrap(err, "write changed blocks: failed to read destination")
+			}
+		}
+		if m != n || !bytes.Equal(block, oldBuf[:m]) {
+			_, err = out.WriteAt(block, size)
+			if err != nil {
+				return written, size, errors.Wrap(err, "write changed blocks: failed to write destination")
+			}
+			written += int64(n)
+		}
+		size += int64(n)
+	}
+	if size != oldSize {
+		err = out.Truncate(size)
+		if err != nil {
+			return written, size, errors.Wrap(err, "write changed blocks: failed to set destination size")
+		}
+	}
+	return written, size, nil
+}
@@ -1,19 +1,27 @@
 package operations
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"path"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"unicode"
 
 	"github.com/pkg/errors"
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/accounting"
+	"github.com/artpar/rclone/fs/filter"
 	"github.com/artpar/rclone/fs/fserrors"
 	"github.com/artpar/rclone/fs/hash"
 	"github.com/artpar/rclone/fs/march"
+	"github.com/artpar/rclone/fs/walk"
 	"github.com/artpar/rclone/lib/readers"
 )
 
@@ -55,7 +63,7 @@ type checkMarch struct {
 }
 
 // report outputs the fileName to out if required and to the combined log
-func (c *checkMarch) report(o fs.DirEntry, out io.Writer, sigil rune) {
+func (c *checkMarch) report(o fmt.Stringer, out io.Writer, sigil rune) {
 	if out != nil {
 		c.ioMu.Lock()
 		_, _ = fmt.Fprintf(out, "%v\n", o)
@@ -130,6 +138,40 @@ func (c *checkMarch) checkIdentical(ctx context.Context, dst, src fs.Object) (di
 	return c.opt.Check(ctx, dst, src)
 }
 
+// checkInBackground runs check in a go-routine, limiting the number
+// running at once to --checkers, and reports the result
+func (c *checkMarch) checkInBackground(src, dst fs.Object, check func() (differ bool, noHash bool, err error)) {
+	c.wg.Add(1)
+	c.tokens <- struct{}{} // put a token to limit concurrency
+	go func() {
+		defer func() {
+			<-c.tokens // get the token back to free up a slot
+			c.wg.Done()
+		}()
+		differ, noHash, err := check()
+		if err != nil {
+			fs.Errorf(src, "%v", err)
+			_ = fs.CountError(err)
+			c.report(src, c.opt.Error, '!')
+		} else if differ {
+			atomic.AddInt32(&c.differences, 1)
+			err := errors.New("files differ")
+			// the checkFn has already logged the reason
+			_ = fs.CountError(err)
+			c.report(src, c.opt.Differ, '*')
+		} else {
+			atomic.AddInt32(&c.matches, 1)
+			c.report(src, c.opt.Match, '=')
+			if noHash {
+				atomic.AddInt32(&c.noHashes, 1)
+				fs.Debugf(dst, "OK - could not check hash")
+			} else {
+				fs.Debugf(dst, "OK")
+			}
+		}
+	}()
+}
+
 // Match is called when src and dst are present, so sync src to dst
 func (c *checkMarch) Match(ctx context.Context, dst, src fs.DirEntry) (recurse bool) {
 	switch srcX := src.(type) {
@@ -139,35 +181,9 @@ func (c *checkMarch) Match(ctx context.Context, dst, src fs.DirEntry) (recurse b
 			if SkipDestructive(ctx, src, "check") {
 				return false
 			}
-			c.wg.Add(1)
-			c.tokens <- struct{}{} // put a token to limit concurrency
-			go func() {
-				defer func() {
-					<-c.tokens // get the token back to free up a slot
-					c.wg.Done()
-				}()
-				differ, noHash, err := c.checkIdentical(ctx, dstX, srcX)
-				if err != nil {
-					fs.Errorf(src, "%v", err)
-					_ = fs.CountError(err)
-					c.report(src, c.opt.Error, '!')
-				} else if differ {
-					atomic.AddInt32(&c.differences, 1)
-					err := errors.New("files differ")
-					// the checkFn has already logged the reason
-					_ = fs.CountError(err)
-					c.report(src, c.opt.Differ, '*')
-				} else {
-					atomic.AddInt32(&c.matches, 1)
-					c.report(src, c.opt.Match, '=')
-					if noHash {
-						atomic.AddInt32(&c.noHashes, 1)
-						fs.Debugf(dstX, "OK - could not check hash")
-					} else {
-						fs.Debugf(dstX, "OK")
-					}
-				}
-			}()
+			c.checkInBackground(srcX, dstX, func() (differ bool, noHash bool, err error) {
+				return c.checkIdentical(ctx, dstX, srcX)
+			})
 		} else {
 			err := errors.Errorf("is file on %v but directory on %v", c.opt.Fsrc, c.opt.Fdst)
 			fs.Errorf(src, "%v", err)
@@ -224,6 +240,12 @@ func CheckFn(ctx context.Context, opt *CheckOpt) error {
 	err := m.Run(ctx)
 	c.wg.Wait() // wait for background go-routines
 
+	return c.reportResults(ctx, err)
+}
+
+// reportResults logs a summary of the check and returns an error if
+// err is set or any differences were found
+func (c *checkMarch) reportResults(ctx context.Context, err error) error {
 	if c.dstFilesMissing > 0 {
 		fs.Logf(c.opt.Fdst, "%d files missing", c.dstFilesMissing)
 	}
@@ -358,3 +380,332 @@ func CheckDownload(ctx context.Context, opt *CheckOpt) error {
 	}
 	return CheckFn(ctx, &optCopy)
 }
+
+// manifestPath is the path of a file in the manifest for reporting
+type manifestPath string
+
+// String returns the path
+func (p manifestPath) String() string {
+	return string(p)
+}
+
+// readManifest reads the files from a manifest in the format written
+// by lsjson, either as a JSON array or as one JSON object per line as
+// written with --stream.
+func readManifest(in io.Reader) (items map[string]*ListJSONItem, err error) {
+	items = make(map[string]*ListJSONItem)
+	add := func(item *ListJSONItem) {
+		if !item.IsDir {
+			items[item.Path] = item
+		}
+	}
+	br := bufio.NewReader(in)
+	isArray := false
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return items, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to read manifest")
+		}
+		if !unicode.IsSpace(rune(b)) {
+			isArray = b == '['
+			_ = br.UnreadByte()
+			break
+		}
+	}
+	dec := json.NewDecoder(br)
+	if isArray {
+		var list []*ListJSONItem
+		err = dec.Decode(&list)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode manifest")
+		}
+		for _, item := range list {
+			add(item)
+		}
+		return items, nil
+	}
+	for {
+		item := new(ListJSONItem)
+		err = dec.Decode(item)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to decode manifest")
+		}
+		add(item)
+	}
+	return items, nil
+}
+
+// checkManifestItem checks o against the size, modification time and
+// hash recorded for it in the manifest
+func (c *checkMarch) checkManifestItem(ctx context.Context, item *ListJSONItem, o fs.Object) (differ bool, noHash bool, err error) {
+	ci := fs.GetConfig(ctx)
+	tr := accounting.Stats(ctx).NewCheckingTransfer(o)
+	defer func() {
+		tr.Done(ctx, err)
+	}()
+	if item.Size >= 0 && o.Size() >= 0 && item.Size != o.Size() {
+		fs.Errorf(o, "Sizes differ: %d in manifest, %d on remote", item.Size, o.Size())
+		return true, false, nil
+	}
+	if ci.SizeOnly {
+		return false, false, nil
+	}
+	if !item.ModTime.When.IsZero() {
+		modifyWindow := fs.GetModifyWindow(ctx, c.opt.Fdst)
+		if modifyWindow != fs.ModTimeNotSupported {
+			dt := o.ModTime(ctx).Sub(item.ModTime.When)
+			if dt < -modifyWindow || dt > modifyWindow {
+				fs.Errorf(o, "Modification times differ by %s: %v in manifest, %v on remote", dt, item.ModTime.When, o.ModTime(ctx))
+				return true, false, nil
+			}
+		}
+	}
+	// Use the first hash the remote supports which is in the manifest
+	for _, ht := range c.opt.Fdst.Hashes().Array() {
+		want, ok := item.Hashes[ht.String()]
+		if !ok {
+			continue
+		}
+		got, err := o.Hash(ctx, ht)
+		if err != nil {
+			return true, false, errors.Wrapf(err, "failed to read %v", ht)
+		}
+		if got == "" {
+			return false, true, nil
+		}
+		if !strings.EqualFold(got, want) {
+			fs.Errorf(o, "%v differ: %q in manifest, %q on remote", ht, want, got)
+			return true, false, nil
+		}
+		return false, false, nil
+	}
+	return false, true, nil
+}
+
+// CheckManifest checks the files in opt.Fdst against a manifest read
+// from in. The manifest should be in the format written by lsjson, so
+// can be made with "rclone lsjson -R --hash --files-only remote:".
+//
+// The manifest is used in place of opt.Fsrc, so files which are only
+// in the manifest are reported as missing on the destination and
+// files which are only on the remote are reported as missing on the
+// source.
+//
+// Sizes, modification times and hashes are compared where they are
+// present in the manifest and supported by the remote.
+func CheckManifest(ctx context.Context, opt *CheckOpt, in io.Reader) error {
+	ci := fs.GetConfig(ctx)
+	items, err := readManifest(in)
+	if err != nil {
+		return err
+	}
+	fs.Debugf(opt.Fdst, "Read %d files from manifest", len(items))
+	c := &checkMarch{
+		tokens: make(chan struct{}, ci.Checkers),
+		opt:    *opt,
+	}
+	return c.checkItems(ctx, items, "manifest", "", c.checkManifestItem)
+}
+
+// checkItems checks the files in c.opt.Fdst against items using
+// check on the files which are in both. what describes where the
+// items came from for the log. The file at ignore, if set, is left
+// out of the check.
+func (c *checkMarch) checkItems(ctx context.Context, items map[string]*ListJSONItem, what string, ignore string, check func(ctx context.Context, item *ListJSONItem, o fs.Object) (differ bool, noHash bool, err error)) error {
+	ci := fs.GetConfig(ctx)
+	fi := filter.GetConfig(ctx)
+	err := walk.ListR(ctx, c.opt.Fdst, "", false, ci.MaxDepth, walk.ListObjects, func(entries fs.DirEntries) error {
+		entries.ForObject(func(o fs.Object) {
+			if ignore != "" && o.Remote() == ignore {
+				return
+			}
+			item, ok := items[o.Remote()]
+			if !ok {
+				if c.opt.OneWay {
+					return
+				}
+				err := errors.Errorf("File not in %s", what)
+				fs.Errorf(o, "%v", err)
+				_ = fs.CountError(err)
+				atomic.AddInt32(&c.differences, 1)
+				atomic.AddInt32(&c.srcFilesMissing, 1)
+				c.report(o, c.opt.MissingOnSrc, '-')
+				return
+			}
+			delete(items, o.Remote())
+			if SkipDestructive(ctx, o, "check") {
+				return
+			}
+			c.checkInBackground(o, o, func() (differ bool, noHash bool, err error) {
+				return check(ctx, item, o)
+			})
+		})
+		return nil
+	})
+	c.wg.Wait() // wait for background go-routines
+
+	// Anything left in the items is missing from the remote
+	if err == nil {
+		remotes := make([]string, 0, len(items))
+		for remote, item := range items {
+			if ci.MaxDepth >= 0 && strings.Count(remote, "/") >= ci.MaxDepth {
+				continue
+			}
+			if !fi.Include(remote, item.Size, item.ModTime.When) {
+				continue
+			}
+			remotes = append(remotes, remote)
+		}
+		sort.Strings(remotes)
+		for _, remote := range remotes {
+			err := errors.Errorf("File not in %v", c.opt.Fdst)
+			fs.Errorf(remote, "%v", err)
+			_ = fs.CountError(err)
+			atomic.AddInt32(&c.differences, 1)
+			atomic.AddInt32(&c.dstFilesMissing, 1)
+			c.report(manifestPath(remote), c.opt.MissingOnDst, '+')
+		}
+	}
+	return c.reportResults(ctx, err)
+}
+
+// ParseSumFile reads the hashes from a SUM file in the format written
+// by md5sum, sha1sum, sha256sum, etc. and by rclone hashsum. Each line
+// is the hash followed by two spaces, or a space and a "*", and the
+// path of the file.
+//
+// The file is parsed as it is read, calling callback for each file, so
+// it can be streamed straight from a remote. Blank lines and lines
+// starting with "#" are ignored.
+func ParseSumFile(in io.Reader, callback func(remote, sum string) error) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, ' ')
+		if i <= 0 || i+2 > len(line) || (line[i+1] != ' ' && line[i+1] != '*') {
+			return errors.Errorf("failed to parse line %d of SUM file: %q", lineNumber, line)
+		}
+		sum, remote := line[:i], strings.TrimPrefix(line[i+2:], "./")
+		if remote == "" {
+			return errors.Errorf("no file name on line %d of SUM file", lineNumber)
+		}
+		err := callback(remote, strings.ToLower(sum))
+		if err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read SUM file")
+	}
+	return nil
+}
+
+// readSumFile reads the SUM file sumFile from fsum into a set of items
+// with hashes of type ht
+func readSumFile(ctx context.Context, fsum fs.Fs, sumFile string, ht hash.Type) (items map[string]*ListJSONItem, err error) {
+	o, err := fsum.NewObject(ctx, sumFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find SUM file")
+	}
+	tr := accounting.Stats(ctx).NewTransfer(o)
+	defer func() {
+		tr.Done(ctx, err)
+	}()
+	in, err := NewReOpen(ctx, o, fs.GetConfig(ctx).LowLevelRetries)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open SUM file")
+	}
+	in = tr.Account(ctx, in).WithBuffer()
+	defer fs.CheckClose(in, &err)
+	items = make(map[string]*ListJSONItem)
+	err = ParseSumFile(in, func(remote, sum string) error {
+		items[remote] = &ListJSONItem{
+			Path:   remote,
+			Name:   path.Base(remote),
+			Size:   -1,
+			Hashes: map[string]string{ht.String(): sum},
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// sumFileRemote returns the path of sumFile in fsum relative to fdst,
+// or "" if it isn't inside fdst
+func sumFileRemote(fsum fs.Fs, sumFile string, fdst fs.Fs) string {
+	if !SameConfig(fsum, fdst) {
+		return ""
+	}
+	p := path.Join(fsum.Root(), sumFile)
+	root := strings.TrimSuffix(fdst.Root(), "/")
+	if root == "" {
+		return strings.TrimPrefix(p, "/")
+	}
+	if strings.HasPrefix(p, root+"/") {
+		return p[len(root)+1:]
+	}
+	return ""
+}
+
+// CheckSum checks the files in opt.Fdst against the hashes of type ht
+// in the SUM file sumFile on fsum, as written by sha256sum, rclone
+// hashsum, etc.
+//
+// The SUM file can be on any remote, including opt.Fdst itself, in
+// which case it is left out of the check. It is streamed from the
+// remote and parsed as it is read.
+//
+// If download is set then the files are downloaded and hashed
+// locally, otherwise the hashes are read from the remote which must
+// support ht.
+//
+// The SUM file is used in place of opt.Fsrc, so files which are only
+// in the SUM file are reported as missing on the destination and files
+// which are only on the remote are reported as missing on the source.
+func CheckSum(ctx context.Context, fsum fs.Fs, sumFile string, ht hash.Type, opt *CheckOpt, download bool) error {
+	ci := fs.GetConfig(ctx)
+	if !download && !opt.Fdst.Hashes().Contains(ht) {
+		return errors.Errorf("%v doesn't support hash type %v - use --download", opt.Fdst, ht)
+	}
+	items, err := readSumFile(ctx, fsum, sumFile, ht)
+	if err != nil {
+		return err
+	}
+	fs.Debugf(opt.Fdst, "Read %d files from SUM file", len(items))
+	c := &checkMarch{
+		tokens: make(chan struct{}, ci.Checkers),
+		opt:    *opt,
+	}
+	ignore := sumFileRemote(fsum, sumFile, opt.Fdst)
+	return c.checkItems(ctx, items, "SUM file", ignore, func(ctx context.Context, item *ListJSONItem, o fs.Object) (differ bool, noHash bool, err error) {
+		want := item.Hashes[ht.String()]
+		got, err := hashSum(ctx, ht, download, o)
+		if err != nil {
+			return true, false, err
+		}
+		if got == "" {
+			return false, true, nil
+		}
+		if !strings.EqualFold(got, want) {
+			fs.Errorf(o, "%v differ: %q in SUM file, %q on remote", ht, want, got)
+			return true, false, nil
+		}
+		return false, false, nil
+	})
+}
@@ -3,6 +3,8 @@ package operations_test
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -10,10 +12,12 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/accounting"
+	"github.com/artpar/rclone/fs/hash"
 	"github.com/artpar/rclone/fs/operations"
 	"github.com/artpar/rclone/fstest"
 	"github.com/artpar/rclone/lib/readers"
@@ -183,6 +187,127 @@ func TestCheck(t *testing.T) {
 	testCheck(t, operations.Check)
 }
 
+func TestCheckManifest(t *testing.T) {
+	testCheck(t, func(ctx context.Context, opt *operations.CheckOpt) error {
+		// Make a manifest of the source then check against that
+		var items []*operations.ListJSONItem
+		err := operations.ListJSON(ctx, opt.Fsrc, "", &operations.ListJSONOpt{
+			Recurse:   true,
+			ShowHash:  true,
+			FilesOnly: true,
+		}, func(item *operations.ListJSONItem) error {
+			items = append(items, item)
+			return nil
+		})
+		require.NoError(t, err)
+		manifest, err := json.Marshal(items)
+		require.NoError(t, err)
+		optCopy := *opt
+		optCopy.Fsrc = nil
+		return operations.CheckManifest(ctx, &optCopy, bytes.NewReader(manifest))
+	})
+}
+
+func TestCheckManifestModTime(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	accounting.GlobalStats().ResetCounters()
+
+	r.WriteObject(ctx, "file1", "contents", t2)
+	r.WriteObject(ctx, "file2", "contents", t2)
+	// Manifest in --stream format with a different time for file2
+	manifest := fmt.Sprintf(`{"Path":"file1","Name":"file1","Size":8,"ModTime":%q,"IsDir":false}
+{"Path":"file2","Name":"file2","Size":8,"ModTime":%q,"IsDir":false}
+`, t2.Format(time.RFC3339Nano), t1.Format(time.RFC3339Nano))
+
+	var differ, match bytes.Buffer
+	opt := operations.CheckOpt{
+		Fdst:   r.Fremote,
+		Differ: &differ,
+		Match:  &match,
+	}
+	err := operations.CheckManifest(ctx, &opt, strings.NewReader(manifest))
+	require.Error(t, err)
+	assert.Equal(t, "file2\n", differ.String())
+	assert.Equal(t, "file1\n", match.String())
+}
+
+func TestParseSumFile(t *testing.T) {
+	in := "\uFEFF# comment\r\n" +
+		"0123456789ABCDEF  file1\r\n" +
+		"\n" +
+		"fedcba9876543210 *./dir/file 2\n"
+	var got []string
+	err := operations.ParseSumFile(strings.NewReader(in), func(remote, sum string) error {
+		got = append(got, sum+":"+remote)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0123456789abcdef:file1", "fedcba9876543210:dir/file 2"}, got)
+
+	for _, in := range []string{
+		"0123456789abcdef\n",
+		"0123456789abcdef file1\n",
+		"0123456789abcdef  \n",
+	} {
+		err = operations.ParseSumFile(strings.NewReader(in), func(remote, sum string) error {
+			return nil
+		})
+		assert.Error(t, err, in)
+	}
+}
+
+func TestCheckSum(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	md5sum := func(s string) string {
+		return fmt.Sprintf("%x", md5.Sum([]byte(s)))
+	}
+	r.WriteObject(ctx, "file1", "one", t1)
+	r.WriteObject(ctx, "dir/file2", "two", t1)
+	r.WriteObject(ctx, "file3", "three", t1)
+	// SUM file in the remote being checked which should be ignored
+	sums := md5sum("one") + "  file1\n" +
+		md5sum("wrong") + "  dir/file2\n" +
+		md5sum("four") + " *file4\n"
+	r.WriteObject(ctx, "MD5SUMS", sums, t1)
+
+	for _, download := range []bool{false, true} {
+		t.Run(fmt.Sprintf("download=%v", download), func(t *testing.T) {
+			if !download && !r.Fremote.Hashes().Contains(hash.MD5) {
+				t.Skip("remote doesn't support MD5")
+			}
+			accounting.GlobalStats().ResetCounters()
+			var combined bytes.Buffer
+			opt := operations.CheckOpt{
+				Fdst:     r.Fremote,
+				Combined: &combined,
+			}
+			err := operations.CheckSum(ctx, r.Fremote, "MD5SUMS", hash.MD5, &opt, download)
+			require.Error(t, err)
+			lines := strings.Split(strings.TrimSpace(combined.String()), "\n")
+			sort.Strings(lines)
+			assert.Equal(t, []string{"* dir/file2", "+ file4", "- file3", "= file1"}, lines)
+		})
+	}
+
+	// SUM file on a different remote
+	accounting.GlobalStats().ResetCounters()
+	r.WriteFile("sums/MD5SUMS", md5sum("one")+"  file1\n", t1)
+	opt := operations.CheckOpt{
+		Fdst:   r.Fremote,
+		OneWay: true,
+	}
+	err := operations.CheckSum(ctx, r.Flocal, "sums/MD5SUMS", hash.MD5, &opt, true)
+	require.NoError(t, err)
+
+	err = operations.CheckSum(ctx, r.Flocal, "sums/notfound", hash.MD5, &opt, true)
+	require.Error(t, err)
+}
+
 func TestCheckFsError(t *testing.T) {
 	ctx := context.Background()
 	dstFs, err := fs.NewFs(ctx, "non-existent")
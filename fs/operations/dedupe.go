@@ -73,6 +73,64 @@ func dedupeDeleteAllButOne(ctx context.Context, keep int, remote string, objs []
 	}
 }
 
+// dedupeLinkAllToOne replaces all but the one in keep with hard links
+// to it
+func dedupeLinkAllToOne(ctx context.Context, f fs.Fs, keep int, remote string, objs []fs.Object) {
+	doHardLink := f.Features().HardLink
+	if doHardLink == nil {
+		fs.Errorf(f, "Fs %v doesn't support hard links", f)
+		return
+	}
+	keepObj := objs[keep]
+	keepID := hardLinkID(keepObj)
+	count := 0
+	for i, o := range objs {
+		if i == keep {
+			continue
+		}
+		if keepID != "" && hardLinkID(o) == keepID {
+			fs.Debugf(o, "Already hard linked to %v", keepObj)
+			continue
+		}
+		if SkipDestructive(ctx, o, "replace with hard link") {
+			continue
+		}
+		_, err := doHardLink(ctx, keepObj, o.Remote())
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(o, "Failed to replace with hard link: %v", err)
+			continue
+		}
+		count++
+	}
+	if count > 0 {
+		fs.Logf(remote, "Replaced %d extra copies with hard links to %v", count, keepObj)
+	}
+}
+
+// hardLinkID returns the hard link ID of o or "" if it doesn't have one
+func hardLinkID(o fs.Object) string {
+	if do, ok := fs.UnWrapObject(o).(fs.HardLinkIDer); ok {
+		return do.HardLinkID()
+	}
+	return ""
+}
+
+// dedupeAllHardLinked returns true if all of objs are hard links to
+// the same file so deduping them would gain nothing
+func dedupeAllHardLinked(objs []fs.Object) bool {
+	firstID := hardLinkID(objs[0])
+	if firstID == "" {
+		return false
+	}
+	for _, o := range objs[1:] {
+		if hardLinkID(o) != firstID {
+			return false
+		}
+	}
+	return true
+}
+
 // dedupeDeleteIdentical deletes all but one of identical (by hash) copies
 func dedupeDeleteIdentical(ctx context.Context, ht hash.Type, remote string, objs []fs.Object) (remainingObjs []fs.Object) {
 	ci := fs.GetConfig(ctx)
@@ -164,6 +222,8 @@ func dedupeInteractive(ctx context.Context, f fs.Fs, ht hash.Type, remote string
 	commands := []string{"sSkip and do nothing", "kKeep just one (choose which in next step)"}
 	if !byHash {
 		commands = append(commands, "rRename all to be different (by changing file.jpg to file-1.jpg)")
+	} else if f.Features().HardLink != nil {
+		commands = append(commands, "lLink all to one (choose which in next step)")
 	}
 	switch config.Command(commands) {
 	case 's':
@@ -172,6 +232,9 @@ func dedupeInteractive(ctx context.Context, f fs.Fs, ht hash.Type, remote string
 		dedupeDeleteAllButOne(ctx, keep-1, remote, objs)
 	case 'r':
 		dedupeRename(ctx, f, remote, objs)
+	case 'l':
+		keep := config.ChooseNumber("Enter the number of the file to link the others to", 1, len(objs))
+		dedupeLinkAllToOne(ctx, f, keep-1, remote, objs)
 	}
 }
 
@@ -189,6 +252,7 @@ const (
 	DeduplicateLargest                            // choose the largest object
 	DeduplicateSmallest                           // choose the smallest object
 	DeduplicateList                               // list duplicates only
+	DeduplicateLink                               // hard link to the first object
 )
 
 func (x DeduplicateMode) String() string {
@@ -211,6 +275,8 @@ func (x DeduplicateMode) String() string {
 		return "smallest"
 	case DeduplicateList:
 		return "list"
+	case DeduplicateLink:
+		return "link"
 	}
 	return "unknown"
 }
@@ -236,6 +302,8 @@ func (x *DeduplicateMode) Set(s string) error {
 		*x = DeduplicateSmallest
 	case "list":
 		*x = DeduplicateList
+	case "link":
+		*x = DeduplicateLink
 	default:
 		return errors.Errorf("Unknown mode for dedupe %q.", s)
 	}
@@ -376,6 +444,35 @@ func dedupeMergeDuplicateDirs(ctx context.Context, f fs.Fs, duplicateDirs [][]*d
 	return nil
 }
 
+// MergeDuplicateDirs finds directories in f which have the same name
+// and merges the contents of each set into one of them server-side,
+// removing the others once they are empty. This is only useful on
+// remotes such as Google Drive which can have duplicate directories.
+//
+// With --dry-run the duplicates are reported but not merged.
+func MergeDuplicateDirs(ctx context.Context, f fs.Fs) error {
+	if f.Features().MergeDirs == nil {
+		return errors.Errorf("%v: can't merge directories", f)
+	}
+	duplicateDirs, err := dedupeFindDuplicateDirs(ctx, f)
+	if err != nil {
+		return err
+	}
+	if len(duplicateDirs) == 0 {
+		fs.Logf(f, "No duplicate directories found")
+		return nil
+	}
+	for _, dedupeDirs := range duplicateDirs {
+		var dirs []string
+		for _, d := range dedupeDirs {
+			dirs = append(dirs, fmt.Sprintf("ID %q with %d entries", d.dir.ID(), d.count))
+		}
+		fs.Logf(dedupeDirs[0].dir, "Found %d duplicate directories: %s", len(dedupeDirs), strings.Join(dirs, ", "))
+	}
+	fs.Logf(f, "Found %d directories with duplicates", len(duplicateDirs))
+	return dedupeMergeDuplicateDirs(ctx, f, duplicateDirs)
+}
+
 // sort oldest first
 func sortOldestFirst(objs []fs.Object) {
 	sort.Slice(objs, func(i, j int) bool {
@@ -404,6 +501,14 @@ func Deduplicate(ctx context.Context, f fs.Fs, mode DeduplicateMode, byHash bool
 		}
 		what = ht.String() + " hashes"
 	}
+	if mode == DeduplicateLink {
+		if !byHash {
+			return errors.New("dedupe mode link can only be used with --by-hash")
+		}
+		if f.Features().HardLink == nil {
+			return errors.Errorf("%v can't make hard links", f)
+		}
+	}
 	fs.Infof(f, "Looking for duplicate %s using %v mode.", what, mode)
 
 	// Find duplicate directories first and fix them
@@ -456,6 +561,10 @@ func Deduplicate(ctx context.Context, f fs.Fs, mode DeduplicateMode, byHash bool
 		if len(objs) <= 1 {
 			continue
 		}
+		if byHash && dedupeAllHardLinked(objs) {
+			fs.Debugf(remote, "Ignoring %d files with duplicate %s as they are all hard linked", len(objs), what)
+			continue
+		}
 		fs.Logf(remote, "Found %d files with duplicate %s", len(objs), what)
 		if !byHash && mode != DeduplicateList {
 			objs = dedupeDeleteIdentical(ctx, ht, remote, objs)
@@ -483,6 +592,8 @@ func Deduplicate(ctx context.Context, f fs.Fs, mode DeduplicateMode, byHash bool
 		case DeduplicateSmallest:
 			sortSmallestFirst(objs)
 			dedupeDeleteAllButOne(ctx, 0, remote, objs)
+		case DeduplicateLink:
+			dedupeLinkAllToOne(ctx, f, 0, remote, objs)
 		case DeduplicateSkip:
 			fs.Logf(remote, "Skipping %d files with duplicate %s", len(objs), what)
 		case DeduplicateList:
@@ -10,6 +10,8 @@ import (
 	"github.com/artpar/rclone/fs/operations"
 	"github.com/artpar/rclone/fs/walk"
 	"github.com/artpar/rclone/fstest"
+	"github.com/artpar/rclone/fstest/mockfs"
+	"github.com/artpar/rclone/fstest/mockobject"
 	"github.com/artpar/rclone/lib/random"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
@@ -170,6 +172,40 @@ func TestDeduplicateNewestByHash(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file3, file4)
 }
 
+func TestDeduplicateLinkByHash(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	skipIfNoHash(t, r.Fremote)
+	if r.Fremote.Features().HardLink == nil {
+		t.Skip("Can't test without hard links")
+	}
+	contents := random.String(100)
+
+	file1 := r.WriteObject(ctx, "one", contents, t1)
+	file2 := r.WriteObject(ctx, "also/one", contents, t2)
+	file3 := r.WriteObject(ctx, "not-one", "stuff", t3)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	err := operations.Deduplicate(ctx, r.Fremote, operations.DeduplicateLink, false)
+	assert.Error(t, err)
+
+	err = operations.Deduplicate(ctx, r.Fremote, operations.DeduplicateLink, true)
+	require.NoError(t, err)
+
+	// All the files remain but the duplicates share their content
+	file2.ModTime = t1
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+	o1, err := r.Fremote.NewObject(ctx, "one")
+	require.NoError(t, err)
+	o2, err := r.Fremote.NewObject(ctx, "also/one")
+	require.NoError(t, err)
+	linker, ok := o1.(fs.HardLinkIDer)
+	require.True(t, ok)
+	assert.NotEqual(t, "", linker.HardLinkID())
+	assert.Equal(t, linker.HardLinkID(), o2.(fs.HardLinkIDer).HardLinkID())
+}
+
 func TestDeduplicateOldest(t *testing.T) {
 	r := fstest.NewRun(t)
 	defer r.Finalise()
@@ -289,3 +325,130 @@ func TestMergeDirs(t *testing.T) {
 	assert.Equal(t, 0, len(objs))
 	assert.Equal(t, "dupe1", dirs[0].Remote())
 }
+
+func TestMergeDuplicateDirs(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	if r.Fremote.Features().MergeDirs == nil {
+		err := operations.MergeDuplicateDirs(ctx, r.Fremote)
+		assert.Error(t, err)
+		t.Skip("Can't merge directories")
+	}
+	skipIfCantDedupe(t, r.Fremote)
+
+	file1 := r.WriteObject(ctx, "dir/one.txt", "This is one", t1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	// No duplicates is OK
+	err := operations.MergeDuplicateDirs(ctx, r.Fremote)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
+// mergeDirsFs is a mock Fs with duplicate directories called "dir"
+// which can merge them
+type mergeDirsFs struct {
+	*mockfs.Fs
+	features *fs.Features
+	ids      []string            // IDs of the directories called "dir" in order
+	contents map[string][]string // names of the files in each directory by ID
+	merged   [][]string          // IDs passed to each MergeDirs call
+}
+
+func newMergeDirsFs(ctx context.Context) *mergeDirsFs {
+	f := &mergeDirsFs{
+		Fs:  mockfs.NewFs(ctx, "mergedirs", ""),
+		ids: []string{"id1", "id2", "id3"},
+		contents: map[string][]string{
+			"id1": {"one.txt"},
+			"id2": {"two.txt", "three.txt"},
+			"id3": nil,
+		},
+	}
+	f.features = (&fs.Features{}).Fill(ctx, f)
+	return f
+}
+
+// Features returns the optional features of this Fs
+func (f *mergeDirsFs) Features() *fs.Features {
+	return f.features
+}
+
+// List the root which contains all the duplicate directories or
+// "dir" which lists the first of them like Google Drive does
+func (f *mergeDirsFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	switch dir {
+	case "":
+		for _, id := range f.ids {
+			entries = append(entries, fs.NewDir("dir", t1).SetID(id))
+		}
+	case "dir":
+		for _, name := range f.contents[f.ids[0]] {
+			entries = append(entries, mockobject.New("dir/"+name))
+		}
+	default:
+		return nil, fs.ErrorDirNotFound
+	}
+	return entries, nil
+}
+
+// MergeDirs merges the contents of all the directories passed in
+// into the first one and removes the others
+func (f *mergeDirsFs) MergeDirs(ctx context.Context, dirs []fs.Directory) error {
+	var ids []string
+	for _, dir := range dirs {
+		ids = append(ids, dir.ID())
+	}
+	f.merged = append(f.merged, ids)
+	dst := ids[0]
+	for _, src := range ids[1:] {
+		f.contents[dst] = append(f.contents[dst], f.contents[src]...)
+		delete(f.contents, src)
+		for i, id := range f.ids {
+			if id == src {
+				f.ids = append(f.ids[:i], f.ids[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// DirCacheFlush does nothing
+func (f *mergeDirsFs) DirCacheFlush() {}
+
+func TestMergeDuplicateDirsMock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("DryRun", func(t *testing.T) {
+		ctx, ci := fs.AddConfig(ctx)
+		ci.DryRun = true
+		f := newMergeDirsFs(ctx)
+		require.NoError(t, operations.MergeDuplicateDirs(ctx, f))
+		assert.Nil(t, f.merged)
+		assert.Equal(t, []string{"id1", "id2", "id3"}, f.ids)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		f := newMergeDirsFs(ctx)
+		require.NoError(t, operations.MergeDuplicateDirs(ctx, f))
+		require.Equal(t, 1, len(f.merged))
+		assert.ElementsMatch(t, []string{"id1", "id2", "id3"}, f.merged[0])
+		require.Equal(t, 1, len(f.ids))
+		assert.ElementsMatch(t, []string{"one.txt", "two.txt", "three.txt"}, f.contents[f.ids[0]])
+
+		// Check the listing now only has one directory with all the files
+		entries, err := f.List(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(entries))
+		entries, err = f.List(ctx, "dir")
+		require.NoError(t, err)
+		assert.Equal(t, 3, len(entries))
+
+		// Merging again should do nothing
+		require.NoError(t, operations.MergeDuplicateDirs(ctx, f))
+		assert.Equal(t, 1, len(f.merged))
+	})
+}
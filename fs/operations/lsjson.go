@@ -2,7 +2,9 @@ package operations
 
 import (
 	"context"
+	"encoding/json"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -26,6 +28,7 @@ type ListJSONItem struct {
 	ID            string            `json:",omitempty"`
 	OrigID        string            `json:",omitempty"`
 	Tier          string            `json:",omitempty"`
+	Metadata      fs.Metadata       `json:",omitempty"`
 	IsBucket      bool              `json:",omitempty"`
 }
 
@@ -43,6 +46,22 @@ func (t Timestamp) MarshalJSON() (out []byte, err error) {
 	return []byte(`"` + t.When.Format(t.Format) + `"`), nil
 }
 
+// UnmarshalJSON turns JSON into a Timestamp
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	if s == "" {
+		t.When = time.Time{}
+		return nil
+	}
+	t.When, err = time.Parse(time.RFC3339Nano, s)
+	t.Format = time.RFC3339Nano
+	return err
+}
+
 // Returns a time format for the given precision
 func formatForPrecision(precision time.Duration) string {
 	switch {
@@ -78,118 +97,177 @@ type ListJSONOpt struct {
 	ShowHash      bool     `json:"showHash"`
 	DirsOnly      bool     `json:"dirsOnly"`
 	FilesOnly     bool     `json:"filesOnly"`
+	Metadata      bool     `json:"metadata"`  // show the metadata - also set by --metadata
 	HashTypes     []string `json:"hashTypes"` // hash types to show if ShowHash is set, e.g. "MD5", "SHA-1"
 }
 
-// ListJSON lists fsrc using the options in opt calling callback for each item
-func ListJSON(ctx context.Context, fsrc fs.Fs, remote string, opt *ListJSONOpt, callback func(*ListJSONItem) error) error {
-	var cipher *crypt.Cipher
+// listJSON holds the state for making ListJSONItems
+type listJSON struct {
+	fsrc       fs.Fs
+	remote     string
+	opt        *ListJSONOpt
+	cipher     *crypt.Cipher
+	canGetTier bool
+	format     string
+	isBucket   bool
+	showHash   bool
+	hashTypes  []hash.Type
+	metadata   bool
+}
+
+func newListJSON(ctx context.Context, fsrc fs.Fs, remote string, opt *ListJSONOpt) (*listJSON, error) {
+	lj := &listJSON{
+		fsrc:   fsrc,
+		remote: remote,
+		opt:    opt,
+	}
 	if opt.ShowEncrypted {
 		fsInfo, _, _, config, err := fs.ConfigFs(fsrc.Name() + ":" + fsrc.Root())
 		if err != nil {
-			return errors.Wrap(err, "ListJSON failed to load config for crypt remote")
+			return nil, errors.Wrap(err, "ListJSON failed to load config for crypt remote")
 		}
 		if fsInfo.Name != "crypt" {
-			return errors.New("The remote needs to be of type \"crypt\"")
+			return nil, errors.New("The remote needs to be of type \"crypt\"")
 		}
-		cipher, err = crypt.NewCipher(config)
+		lj.cipher, err = crypt.NewCipher(config)
 		if err != nil {
-			return errors.Wrap(err, "ListJSON failed to make new crypt remote")
+			return nil, errors.Wrap(err, "ListJSON failed to make new crypt remote")
 		}
 	}
 	features := fsrc.Features()
-	canGetTier := features.GetTier
-	format := formatForPrecision(fsrc.Precision())
-	isBucket := features.BucketBased && remote == "" && fsrc.Root() == "" // if bucket based remote listing the root mark directories as buckets
-	showHash := opt.ShowHash
-	hashTypes := fsrc.Hashes().Array()
+	lj.canGetTier = features.GetTier
+	lj.format = formatForPrecision(fsrc.Precision())
+	lj.isBucket = features.BucketBased && remote == "" && fsrc.Root() == "" // if bucket based remote listing the root mark directories as buckets
+	lj.showHash = opt.ShowHash
+	lj.metadata = opt.Metadata || fs.GetConfig(ctx).Metadata
+	lj.hashTypes = fsrc.Hashes().Array()
 	if len(opt.HashTypes) != 0 {
-		showHash = true
-		hashTypes = []hash.Type{}
+		lj.showHash = true
+		lj.hashTypes = []hash.Type{}
 		for _, hashType := range opt.HashTypes {
 			var ht hash.Type
 			err := ht.Set(hashType)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			hashTypes = append(hashTypes, ht)
+			lj.hashTypes = append(lj.hashTypes, ht)
 		}
 	}
-	err := walk.ListR(ctx, fsrc, remote, false, ConfigMaxDepth(ctx, opt.Recurse), walk.ListAll, func(entries fs.DirEntries) (err error) {
-		for _, entry := range entries {
-			switch entry.(type) {
-			case fs.Directory:
-				if opt.FilesOnly {
-					continue
-				}
-			case fs.Object:
-				if opt.DirsOnly {
-					continue
-				}
-			default:
-				fs.Errorf(nil, "Unknown type %T in listing", entry)
-			}
+	return lj, nil
+}
 
-			item := ListJSONItem{
-				Path: entry.Remote(),
-				Name: path.Base(entry.Remote()),
-				Size: entry.Size(),
-			}
-			if !opt.NoModTime {
-				item.ModTime = Timestamp{When: entry.ModTime(ctx), Format: format}
-			}
-			if !opt.NoMimeType {
-				item.MimeType = fs.MimeTypeDirEntry(ctx, entry)
-			}
-			if cipher != nil {
-				switch entry.(type) {
-				case fs.Directory:
-					item.EncryptedPath = cipher.EncryptDirName(entry.Remote())
-				case fs.Object:
-					item.EncryptedPath = cipher.EncryptFileName(entry.Remote())
-				default:
-					fs.Errorf(nil, "Unknown type %T in listing", entry)
+// listType returns the types of entry the listing should return
+func (lj *listJSON) listType() walk.ListType {
+	switch {
+	case lj.opt.FilesOnly && !lj.opt.DirsOnly:
+		return walk.ListObjects
+	case lj.opt.DirsOnly && !lj.opt.FilesOnly:
+		return walk.ListDirs
+	}
+	return walk.ListAll
+}
+
+// entry converts entry into a ListJSONItem or returns nil if it
+// should be skipped
+func (lj *listJSON) entry(ctx context.Context, entry fs.DirEntry) *ListJSONItem {
+	switch entry.(type) {
+	case fs.Directory:
+		if lj.opt.FilesOnly {
+			return nil
+		}
+	case fs.Object:
+		if lj.opt.DirsOnly {
+			return nil
+		}
+	default:
+		fs.Errorf(nil, "Unknown type %T in listing", entry)
+	}
+
+	item := &ListJSONItem{
+		Path: entry.Remote(),
+		Name: path.Base(entry.Remote()),
+		Size: entry.Size(),
+	}
+	if !lj.opt.NoModTime {
+		item.ModTime = Timestamp{When: entry.ModTime(ctx), Format: lj.format}
+	}
+	if !lj.opt.NoMimeType {
+		item.MimeType = fs.MimeTypeDirEntry(ctx, entry)
+	}
+	if lj.cipher != nil {
+		switch entry.(type) {
+		case fs.Directory:
+			item.EncryptedPath = lj.cipher.EncryptDirName(entry.Remote())
+		case fs.Object:
+			item.EncryptedPath = lj.cipher.EncryptFileName(entry.Remote())
+		default:
+			fs.Errorf(nil, "Unknown type %T in listing", entry)
+		}
+		item.Encrypted = path.Base(item.EncryptedPath)
+	}
+	if do, ok := entry.(fs.IDer); ok {
+		item.ID = do.ID()
+	}
+	if o, ok := entry.(fs.Object); lj.opt.ShowOrigIDs && ok {
+		if do, ok := fs.UnWrapObject(o).(fs.IDer); ok {
+			item.OrigID = do.ID()
+		}
+	}
+	switch x := entry.(type) {
+	case fs.Directory:
+		item.IsDir = true
+		item.IsBucket = lj.isBucket
+	case fs.Object:
+		item.IsDir = false
+		if lj.showHash {
+			item.Hashes = make(map[string]string)
+			for _, hashType := range lj.hashTypes {
+				hash, err := x.Hash(ctx, hashType)
+				if err != nil {
+					fs.Errorf(x, "Failed to read hash: %v", err)
+				} else if hash != "" {
+					item.Hashes[hashType.String()] = hash
 				}
-				item.Encrypted = path.Base(item.EncryptedPath)
 			}
-			if do, ok := entry.(fs.IDer); ok {
-				item.ID = do.ID()
+		}
+		if lj.canGetTier {
+			if do, ok := x.(fs.GetTierer); ok {
+				item.Tier = do.GetTier()
 			}
-			if o, ok := entry.(fs.Object); opt.ShowOrigIDs && ok {
-				if do, ok := fs.UnWrapObject(o).(fs.IDer); ok {
-					item.OrigID = do.ID()
-				}
+		}
+		if lj.metadata {
+			metadata, err := fs.GetMetadata(ctx, x)
+			if err != nil {
+				fs.Errorf(x, "Failed to read metadata: %v", err)
+			} else {
+				item.Metadata = metadata
 			}
-			switch x := entry.(type) {
-			case fs.Directory:
-				item.IsDir = true
-				item.IsBucket = isBucket
-			case fs.Object:
-				item.IsDir = false
-				if showHash {
-					item.Hashes = make(map[string]string)
-					for _, hashType := range hashTypes {
-						hash, err := x.Hash(ctx, hashType)
-						if err != nil {
-							fs.Errorf(x, "Failed to read hash: %v", err)
-						} else if hash != "" {
-							item.Hashes[hashType.String()] = hash
-						}
-					}
-				}
-				if canGetTier {
-					if do, ok := x.(fs.GetTierer); ok {
-						item.Tier = do.GetTier()
-					}
-				}
-			default:
-				fs.Errorf(nil, "Unknown type %T in listing in ListJSON", entry)
+		}
+	default:
+		fs.Errorf(nil, "Unknown type %T in listing in ListJSON", entry)
+	}
+	return item
+}
+
+// ListJSON lists fsrc using the options in opt calling callback for each item
+//
+// Items are passed to callback as soon as they are read so very large
+// listings don't need to be held in memory.
+func ListJSON(ctx context.Context, fsrc fs.Fs, remote string, opt *ListJSONOpt, callback func(*ListJSONItem) error) error {
+	lj, err := newListJSON(ctx, fsrc, remote, opt)
+	if err != nil {
+		return err
+	}
+	err = walk.ListR(ctx, fsrc, remote, false, ConfigMaxDepth(ctx, opt.Recurse), lj.listType(), func(entries fs.DirEntries) (err error) {
+		for _, entry := range entries {
+			item := lj.entry(ctx, entry)
+			if item == nil {
+				continue
 			}
-			err = callback(&item)
+			err = callback(item)
 			if err != nil {
 				return errors.Wrap(err, "callback failed in ListJSON")
 			}
-
 		}
 		return nil
 	})
@@ -198,3 +276,57 @@ func ListJSON(ctx context.Context, fsrc fs.Fs, remote string, opt *ListJSONOpt,
 	}
 	return nil
 }
+
+// StatJSON returns a ListJSONItem for remote in fsrc using the
+// options in opt or nil if it isn't found.
+//
+// It finds files with NewObject and checks directories by listing
+// them so it never needs to list the parent directory.
+func StatJSON(ctx context.Context, fsrc fs.Fs, remote string, opt *ListJSONOpt) (*ListJSONItem, error) {
+	lj, err := newListJSON(ctx, fsrc, remote, opt)
+	if err != nil {
+		return nil, err
+	}
+	// Check for a file first as that is cheap
+	if remote != "" {
+		o, err := fsrc.NewObject(ctx, remote)
+		if err == nil {
+			if opt.DirsOnly {
+				return nil, nil
+			}
+			return lj.entry(ctx, o), nil
+		}
+		if cause := errors.Cause(err); cause != fs.ErrorObjectNotFound && cause != fs.ErrorNotAFile {
+			return nil, err
+		}
+	}
+	if opt.FilesOnly {
+		return nil, nil
+	}
+	// Otherwise see if it is a directory by listing it - the root
+	// of the remote always exists
+	if remote != "" || fsrc.Root() != "" {
+		entries, err := fsrc.List(ctx, remote)
+		if err == fs.ErrorDirNotFound {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		// Directories on bucket based remotes only exist if they have something in
+		if len(entries) == 0 && fsrc.Features().BucketBased && !fsrc.Features().CanHaveEmptyDirectories {
+			return nil, nil
+		}
+	}
+	lj.isBucket = fsrc.Features().BucketBased && fsrc.Root() == "" && remote != "" && !strings.Contains(remote, "/")
+	item := lj.entry(ctx, fs.NewDir(remote, time.Time{}))
+	// The modification time of the directory isn't known without
+	// listing the parent
+	item.ModTime = Timestamp{}
+	if remote == "" {
+		item.Name = path.Base(fsrc.Root())
+		if item.Name == "." || item.Name == "/" {
+			item.Name = ""
+		}
+	}
+	return item, nil
+}
@@ -56,7 +56,7 @@ func CheckHashes(ctx context.Context, src fs.ObjectInfo, dst fs.Object) (equal b
 	common := src.Fs().Hashes().Overlap(dst.Fs().Hashes())
 	// fs.Debugf(nil, "Shared hashes: %v", common)
 	if common.Count() == 0 {
-		return true, hash.None, nil
+		return checkSidecar(ctx, src, dst)
 	}
 	equal, ht, _, _, err = checkHashes(ctx, src, dst, common.GetOne())
 	return equal, ht, err
@@ -124,6 +124,34 @@ func Equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object) bool {
 	return equal(ctx, src, dst, defaultEqualOpt(ctx))
 }
 
+var immutableVerifyWarning sync.Once
+
+// VerifyImmutable checks the contents of dst haven't been changed from
+// src by comparing their hashes. It is used by --immutable-verify on
+// existing files which would otherwise not be transferred.
+//
+// It returns fs.ErrorImmutableModified, already counted and logged,
+// if the hashes differ. If there is no common hash it can't check so
+// it logs a warning once and returns nil.
+func VerifyImmutable(ctx context.Context, src fs.ObjectInfo, dst fs.Object) error {
+	same, ht, err := CheckHashes(ctx, src, dst)
+	if err != nil {
+		return err
+	}
+	if ht == hash.None {
+		immutableVerifyWarning.Do(func() {
+			fs.Logf(dst.Fs(), "--immutable-verify is in use but the source and destination have no hashes in common; only checking size and modification time")
+		})
+		return nil
+	}
+	if !same {
+		err = fs.CountError(fserrors.NoRetryError(fs.ErrorImmutableModified))
+		fs.Errorf(dst, "Contents differ from source (%v differs): %v", ht, err)
+		return err
+	}
+	return nil
+}
+
 // sizeDiffers compare the size of src and dst taking into account the
 // various ways of ignoring sizes
 func sizeDiffers(ctx context.Context, src, dst fs.ObjectInfo) bool {
@@ -417,7 +445,9 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 		}
 		// If can't server-side copy, do it manually
 		if err == fs.ErrorCantCopy {
-			if doMultiThreadCopy(ctx, f, src) {
+			if patcher := changedBlocksPatcher(ctx, dst, src); patcher != nil {
+				actionTaken, err = changedBlocksCopy(ctx, patcher, dst, src, tr)
+			} else if doMultiThreadCopy(ctx, f, src) {
 				// Number of streams proportional to size
 				streams := src.Size() / int64(ci.MultiThreadCutoff)
 				// With maximum
@@ -464,7 +494,14 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 						for _, option := range ci.UploadHeaders {
 							options = append(options, option)
 						}
-						if doUpdate {
+						if canPutPartial(ctx, f) {
+							if doUpdate {
+								actionTaken = "Copied (partial upload, replaced existing)"
+							} else {
+								actionTaken = "Copied (partial upload, new)"
+							}
+							dst, err = putPartial(ctx, f, remote, in, wrappedSrc, options...)
+						} else if doUpdate {
 							actionTaken = "Copied (replaced existing)"
 							err = dst.Update(ctx, in, wrappedSrc, options...)
 						} else {
@@ -498,6 +535,7 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 		}
 		if retry {
 			fs.Debugf(src, "Received error: %v - low level retry %d/%d", err, tries, maxTries)
+			tr.Retry(err)
 			tr.Reset(ctx) // skip incomplete accounting - will be overwritten by retry
 			continue
 		}
@@ -506,7 +544,7 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 	}
 	if err != nil {
 		err = fs.CountError(err)
-		fs.Errorf(src, "Failed to copy: %v", err)
+		fs.Errorf(src, "Failed to copy: %v%v", err, fs.LogValueHide(fs.LogRuleOperation, "copy"))
 		return newDst, err
 	}
 
@@ -531,14 +569,68 @@ func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Obj
 			return newDst, err
 		}
 	}
+	// Copy the metadata if required
+	if ci.Metadata && newDst != nil {
+		err = copyMetadata(ctx, src, newDst)
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(newDst, "Failed to copy metadata: %v", err)
+			return newDst, err
+		}
+	}
+	// Write the checksum file if required
+	if ci.SidecarHash != hash.None && newDst != nil {
+		err = writeSidecar(ctx, f, src, newDst)
+		if err != nil {
+			err = fs.CountError(err)
+			fs.Errorf(newDst, "%v", err)
+			return newDst, err
+		}
+	}
 	if newDst != nil && src.String() != newDst.String() {
 		fs.Infof(src, "%s to: %s", actionTaken, newDst.String())
 	} else {
-		fs.Infof(src, actionTaken)
+		fs.Infof(src, "%s%v", actionTaken, fs.LogValueHide(fs.LogRuleOperation, "copy"))
 	}
 	return newDst, err
 }
 
+// copyMetadata copies the metadata of src to dst if src can read
+// metadata and dst can set it.
+func copyMetadata(ctx context.Context, src, dst fs.Object) error {
+	if _, ok := fs.UnWrapObject(dst).(fs.SetMetadataer); !ok {
+		return nil
+	}
+	metadata, err := fs.GetMetadata(ctx, src)
+	if err != nil {
+		return errors.Wrap(err, "failed to read metadata")
+	}
+	return setMetadata(ctx, dst, metadata)
+}
+
+// setMetadata sets metadata on dst if dst can set metadata, unless dst
+// already has all of it.
+func setMetadata(ctx context.Context, dst fs.Object, metadata fs.Metadata) error {
+	do, ok := fs.UnWrapObject(dst).(fs.SetMetadataer)
+	if !ok || len(metadata) == 0 {
+		return nil
+	}
+	current, err := fs.GetMetadata(ctx, dst)
+	if err == nil {
+		changed := false
+		for key, value := range metadata {
+			if currentValue, ok := current[key]; !ok || currentValue != value {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return nil
+		}
+	}
+	return do.SetMetadata(ctx, metadata)
+}
+
 // SameObject returns true if src and dst could be pointing to the
 // same object.
 func SameObject(src, dst fs.Object) bool {
@@ -575,6 +667,7 @@ func SameObject(src, dst fs.Object) bool {
 // It returns the destination object if possible.  Note that this may
 // be nil.
 func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
+	ci := fs.GetConfig(ctx)
 	tr := accounting.Stats(ctx).NewCheckingTransfer(src)
 	defer func() {
 		if err == nil {
@@ -597,14 +690,33 @@ func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.
 				return newDst, err
 			}
 		}
+		// Read the metadata now as src won't exist after the move
+		var metadata fs.Metadata
+		if ci.Metadata {
+			metadata, err = fs.GetMetadata(ctx, src)
+			if err != nil {
+				err = fs.CountError(err)
+				fs.Errorf(src, "Failed to read metadata: %v", err)
+				return newDst, err
+			}
+		}
 		// Move dst <- src
 		newDst, err = doMove(ctx, src, remote)
 		switch err {
 		case nil:
+			// Not all remotes keep the metadata on a server-side move
+			if newDst != nil {
+				err = setMetadata(ctx, newDst, metadata)
+				if err != nil {
+					err = fs.CountError(err)
+					fs.Errorf(newDst, "Failed to copy metadata: %v", err)
+					return newDst, err
+				}
+			}
 			if newDst != nil && src.String() != newDst.String() {
-				fs.Infof(src, "Moved (server-side) to: %s", newDst.String())
+				fs.Infof(src, "Moved (server-side) to: %s%v", newDst.String(), fs.LogValueHide(fs.LogRuleOperation, "move"))
 			} else {
-				fs.Infof(src, "Moved (server-side)")
+				fs.Infof(src, "Moved (server-side)%v", fs.LogValueHide(fs.LogRuleOperation, "move"))
 			}
 
 			return newDst, nil
@@ -612,7 +724,7 @@ func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.
 			fs.Debugf(src, "Can't move, switching to copy")
 		default:
 			err = fs.CountError(err)
-			fs.Errorf(src, "Couldn't move: %v", err)
+			fs.Errorf(src, "Couldn't move: %v%v", err, fs.LogValueHide(fs.LogRuleOperation, "move"))
 			return newDst, err
 		}
 	}
@@ -652,6 +764,32 @@ func SuffixName(ctx context.Context, remote string) string {
 	return remote + ci.Suffix
 }
 
+type maxDeleteCheckedKey struct{}
+
+// WithMaxDeleteChecked returns a context which says that --max-delete
+// as a percentage and --max-delete-size have been checked by the
+// caller before deleting anything.
+//
+// sync does this as it knows the totals for the destination. Deletes
+// with any other context return an error if these are set.
+func WithMaxDeleteChecked(ctx context.Context) context.Context {
+	return context.WithValue(ctx, maxDeleteCheckedKey{}, true)
+}
+
+// checkMaxDeleteSupported returns an error if --max-delete as a
+// percentage or --max-delete-size is set but can't be enforced for
+// the deletes done with ctx.
+func checkMaxDeleteSupported(ctx context.Context) error {
+	ci := fs.GetConfig(ctx)
+	if ci.MaxDelete.Percent == 0 && !ci.MaxDeleteSize.IsSet() {
+		return nil
+	}
+	if checked, _ := ctx.Value(maxDeleteCheckedKey{}).(bool); checked {
+		return nil
+	}
+	return fserrors.FatalError(errors.New("--max-delete as a percentage and --max-delete-size are only supported by sync"))
+}
+
 // DeleteFileWithBackupDir deletes a single file respecting --dry-run
 // and accumulating stats and errors.
 //
@@ -663,8 +801,11 @@ func DeleteFileWithBackupDir(ctx context.Context, dst fs.Object, backupDir fs.Fs
 	defer func() {
 		tr.Done(ctx, err)
 	}()
+	if err = checkMaxDeleteSupported(ctx); err != nil {
+		return err
+	}
 	numDeletes := accounting.Stats(ctx).Deletes(1)
-	if ci.MaxDelete != -1 && numDeletes > ci.MaxDelete {
+	if ci.MaxDelete.Percent == 0 && ci.MaxDelete.Count >= 0 && numDeletes > ci.MaxDelete.Count {
 		return fserrors.FatalError(errors.New("--max-delete threshold reached"))
 	}
 	action, actioned := "delete", "Deleted"
@@ -680,10 +821,11 @@ func DeleteFileWithBackupDir(ctx context.Context, dst fs.Object, backupDir fs.Fs
 		err = dst.Remove(ctx)
 	}
 	if err != nil {
-		fs.Errorf(dst, "Couldn't %s: %v", action, err)
+		fs.Errorf(dst, "Couldn't %s: %v%v", action, err, fs.LogValueHide(fs.LogRuleOperation, "delete"))
 		err = fs.CountError(err)
 	} else if !skip {
-		fs.Infof(dst, actioned)
+		fs.Infof(dst, "%s%v", actioned, fs.LogValueHide(fs.LogRuleOperation, "delete"))
+		removeSidecar(ctx, dst, backupDir)
 	}
 	return err
 }
@@ -887,6 +1029,55 @@ func ListLong(ctx context.Context, f fs.Fs, w io.Writer) error {
 	})
 }
 
+// PorcelainTimeFormat is the format used for modification times in
+// --porcelain output. It is always in UTC with nanosecond precision.
+const PorcelainTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// porcelainReplacer escapes the characters which would break up a
+// --porcelain line
+var porcelainReplacer = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// PorcelainEscape escapes s for use as a field in --porcelain output
+//
+// Backslash, tab, newline and carriage return are replaced with `\\`,
+// `\t`, `\n` and `\r` so each record is always one line with tab
+// separated fields.
+func PorcelainEscape(s string) string {
+	return porcelainReplacer.Replace(s)
+}
+
+// ListPorcelain lists the Fs to the supplied writer in --porcelain
+// format
+//
+// Each line is the size in bytes and the escaped path separated by a
+// tab - obeys includes and excludes
+//
+// Lists in parallel which may get them out of order
+func ListPorcelain(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return ListFn(ctx, f, func(o fs.Object) {
+		syncFprintf(w, "%d\t%s\n", o.Size(), PorcelainEscape(o.Remote()))
+	})
+}
+
+// ListLongPorcelain lists the Fs to the supplied writer in
+// --porcelain format
+//
+// Each line is the size in bytes, the modification time in
+// PorcelainTimeFormat and the escaped path separated by tabs - obeys
+// includes and excludes
+//
+// Lists in parallel which may get them out of order
+func ListLongPorcelain(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return ListFn(ctx, f, func(o fs.Object) {
+		tr := accounting.Stats(ctx).NewCheckingTransfer(o)
+		defer func() {
+			tr.Done(ctx, nil)
+		}()
+		modTime := o.ModTime(ctx)
+		syncFprintf(w, "%d\t%s\t%s\n", o.Size(), modTime.UTC().Format(PorcelainTimeFormat), PorcelainEscape(o.Remote()))
+	})
+}
+
 // hashSum returns the human readable hash for ht passed in.  This may
 // be UNSUPPORTED or ERROR. If it isn't returning a valid hash it will
 // return an error.
@@ -1000,6 +1191,65 @@ func Count(ctx context.Context, f fs.Fs) (objects int64, size int64, err error)
 	return
 }
 
+// DirSize is the number and total size of the objects in a directory
+// and all its subdirectories
+type DirSize struct {
+	Path  string `json:"path"`
+	Count int64  `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
+
+// CountDirs counts the objects in f and their total size like Count
+// but also returns the totals for each directory down to depth levels
+// below the root.
+//
+// The results are sorted by path with the root, which has the totals
+// for the whole of f, first as "". Directories with no objects in are
+// not included.
+func CountDirs(ctx context.Context, f fs.Fs, depth int) (dirs []DirSize, err error) {
+	totals := map[string]*DirSize{
+		"": {},
+	}
+	err = ListFn(ctx, f, func(o fs.Object) {
+		objectSize := o.Size()
+		if objectSize < 0 {
+			objectSize = 0
+		}
+		add := func(dir string) {
+			total := totals[dir]
+			if total == nil {
+				total = &DirSize{Path: dir}
+				totals[dir] = total
+			}
+			total.Count++
+			total.Bytes += objectSize
+		}
+		add("")
+		// add to each parent directory down to depth
+		remote := o.Remote()
+		for i, n := 0, 0; i < depth; i++ {
+			slash := strings.IndexRune(remote[n:], '/')
+			if slash < 0 {
+				break
+			}
+			n += slash
+			add(remote[:n])
+			n++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	dirs = make([]DirSize, 0, len(totals))
+	for _, total := range totals {
+		dirs = append(dirs, *total)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].Path < dirs[j].Path
+	})
+	return dirs, nil
+}
+
 // ConfigMaxDepth returns the depth to use for a recursive or non recursive listing.
 func ConfigMaxDepth(ctx context.Context, recursive bool) int {
 	ci := fs.GetConfig(ctx)
@@ -1027,7 +1277,7 @@ func Mkdir(ctx context.Context, f fs.Fs, dir string) error {
 	if SkipDestructive(ctx, fs.LogDirName(f, dir), "make directory") {
 		return nil
 	}
-	fs.Debugf(fs.LogDirName(f, dir), "Making directory")
+	fs.Debugf(fs.LogDirName(f, dir), "Making directory%v", fs.LogValueHide(fs.LogRuleOperation, "mkdir"))
 	err := f.Mkdir(ctx, dir)
 	if err != nil {
 		err = fs.CountError(err)
@@ -1043,7 +1293,7 @@ func TryRmdir(ctx context.Context, f fs.Fs, dir string) error {
 	if SkipDestructive(ctx, fs.LogDirName(f, dir), "remove directory") {
 		return nil
 	}
-	fs.Debugf(fs.LogDirName(f, dir), "Removing directory")
+	fs.Debugf(fs.LogDirName(f, dir), "Removing directory%v", fs.LogValueHide(fs.LogRuleOperation, "rmdir"))
 	return f.Rmdir(ctx, dir)
 }
 
@@ -1059,6 +1309,9 @@ func Rmdir(ctx context.Context, f fs.Fs, dir string) error {
 
 // Purge removes a directory and all of its contents
 func Purge(ctx context.Context, f fs.Fs, dir string) (err error) {
+	if err = checkMaxDeleteSupported(ctx); err != nil {
+		return err
+	}
 	doFallbackPurge := true
 	if doPurge := f.Features().Purge; doPurge != nil {
 		doFallbackPurge = false
@@ -1089,6 +1342,9 @@ func Purge(ctx context.Context, f fs.Fs, dir string) (err error) {
 // Delete removes all the contents of a container.  Unlike Purge, it
 // obeys includes and excludes.
 func Delete(ctx context.Context, f fs.Fs) error {
+	if err := checkMaxDeleteSupported(ctx); err != nil {
+		return err
+	}
 	ci := fs.GetConfig(ctx)
 	delChan := make(fs.ObjectsChan, ci.Transfers)
 	delErr := make(chan error, 1)
@@ -1244,7 +1500,7 @@ func Rcat(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadCloser,
 			fs.Errorf(dst, "%v", err)
 			return err
 		}
-		return nil
+		return checkRcatHashes(ctx, dst, sums)
 	}
 
 	// check if file small enough for direct upload
@@ -1263,7 +1519,8 @@ func Rcat(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadCloser,
 
 	fStreamTo := fdst
 	canStream := fdst.Features().PutStream != nil
-	if !canStream {
+	canChunk := !canStream && fdst.Features().OpenChunkWriter != nil
+	if !canStream && !canChunk {
 		fs.Debugf(fdst, "Target remote doesn't support streaming uploads, creating temporary local FS to spool file")
 		tmpLocalFs, err := fs.TemporaryLocalFs(ctx)
 		if err != nil {
@@ -1284,6 +1541,14 @@ func Rcat(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadCloser,
 		return nil, err
 	}
 
+	if canChunk {
+		fs.Debugf(fdst, "Target remote doesn't support streaming uploads, uploading in chunks")
+		if dst, err = rcatChunked(ctx, fdst, dstFileName, in, modTime, options); err != nil {
+			return dst, err
+		}
+		return dst, compare(dst)
+	}
+
 	objInfo := object.NewStaticObjectInfo(dstFileName, modTime, -1, false, nil, nil)
 	if dst, err = fStreamTo.Features().PutStream(ctx, in, objInfo, options...); err != nil {
 		return dst, err
@@ -1298,6 +1563,107 @@ func Rcat(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadCloser,
 	return dst, nil
 }
 
+// defaultRcatChunkSize is used for chunked uploads if neither the user
+// nor the backend suggest a chunk size
+const defaultRcatChunkSize = 64 * 1024 * 1024
+
+// rcatChunked uploads in to dstFileName on fdst using the
+// OpenChunkWriter feature.
+//
+// Only one chunk is held in memory at once so streams of any length
+// can be uploaded without spooling them to local disk.
+//
+// An empty stream is uploaded normally as it has no chunks to write.
+func rcatChunked(ctx context.Context, fdst fs.Fs, dstFileName string, in io.Reader, modTime time.Time, options []fs.OpenOption) (dst fs.Object, err error) {
+	var first [1]byte
+	n, err := io.ReadFull(in, first[:])
+	if err == io.EOF {
+		fs.Debugf(fdst, "Stream is empty, uploading instead of uploading in chunks")
+		return Copy(ctx, fdst, nil, dstFileName, object.NewMemoryObject(dstFileName, modTime, nil))
+	} else if err != nil {
+		return nil, err
+	}
+	in = io.MultiReader(bytes.NewReader(first[:n]), in)
+
+	src := object.NewStaticObjectInfo(dstFileName, modTime, -1, false, nil, fdst)
+	info, writer, err := fdst.Features().OpenChunkWriter(ctx, dstFileName, src, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open chunked upload")
+	}
+	defer func() {
+		if err != nil {
+			if abortErr := writer.Abort(ctx); abortErr != nil {
+				fs.Errorf(dstFileName, "Failed to abort chunked upload: %v", abortErr)
+			}
+		}
+	}()
+
+	chunkSize := int64(fs.GetConfig(ctx).StreamingChunkSize)
+	if chunkSize <= 0 {
+		chunkSize = info.ChunkSize
+	}
+	if chunkSize < info.MinChunkSize {
+		fs.Debugf(fdst, "Increasing chunk size from %v to the minimum %v", fs.SizeSuffix(chunkSize), fs.SizeSuffix(info.MinChunkSize))
+		chunkSize = info.MinChunkSize
+	}
+	if chunkSize <= 0 {
+		chunkSize = defaultRcatChunkSize
+	}
+
+	buf := make([]byte, chunkSize)
+	for chunkNumber := 0; ; chunkNumber++ {
+		n, readErr := io.ReadFull(in, buf)
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return nil, readErr
+		}
+		if info.MaxChunks > 0 && chunkNumber >= info.MaxChunks {
+			return nil, errors.Errorf("stream too large for %d chunks of %v - increase --streaming-chunk-size", info.MaxChunks, fs.SizeSuffix(chunkSize))
+		}
+		fs.Debugf(dstFileName, "Uploading chunk %d size %d", chunkNumber, n)
+		if _, err = writer.WriteChunk(ctx, chunkNumber, bytes.NewReader(buf[:n])); err != nil {
+			return nil, errors.Wrapf(err, "failed to upload chunk %d", chunkNumber)
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	if err = writer.Close(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to finalise chunked upload")
+	}
+	return fdst.NewObject(ctx, dstFileName)
+}
+
+// checkRcatHashes checks the hashes calculated while reading the input
+// to Rcat or RcatSize match the hashes of the uploaded object dst.
+//
+// Hashes the remote can't supply are skipped.
+func checkRcatHashes(ctx context.Context, dst fs.Object, sums map[hash.Type]string) error {
+	for ht, srcHash := range sums {
+		if ht == hash.None || srcHash == "" {
+			continue
+		}
+		dstHash, err := dst.Hash(ctx, ht)
+		if err != nil {
+			fs.Debugf(dst, "Failed to read %v hash to verify upload: %v", ht, err)
+			continue
+		}
+		if dstHash == "" {
+			continue
+		}
+		if srcHash != dstHash {
+			err = errors.Errorf("corrupted on transfer: %v hash differ %q vs %q", ht, srcHash, dstHash)
+			err = fs.CountError(err)
+			fs.Errorf(dst, "%v", err)
+			return err
+		}
+		fs.Debugf(dst, "%v = %s OK", ht, dstHash)
+	}
+	return nil
+}
+
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
 func PublicLink(ctx context.Context, f fs.Fs, remote string, expire fs.Duration, unlink bool) (string, error) {
 	doPublicLink := f.Features().PublicLink
@@ -1312,6 +1678,9 @@ func PublicLink(ctx context.Context, f fs.Fs, remote string, expire fs.Duration,
 //
 // Rmdirs obeys the filters
 func Rmdirs(ctx context.Context, f fs.Fs, dir string, leaveRoot bool) error {
+	if err := checkMaxDeleteSupported(ctx); err != nil {
+		return err
+	}
 	ci := fs.GetConfig(ctx)
 	fi := filter.GetConfig(ctx)
 	dirEmpty := make(map[string]bool)
@@ -1592,13 +1961,37 @@ func RcatSize(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadClo
 			return nil, err
 		}
 
+		// Hash the data as it is uploaded to check it afterwards
+		var hasher *hash.MultiHasher
+		var trackingIn io.Reader = in
+		if !fs.GetConfig(ctx).IgnoreChecksum {
+			hasher, err = hash.NewMultiHasherTypes(hash.NewHashSet(fdst.Hashes().GetOne()))
+			if err != nil {
+				return nil, err
+			}
+			trackingIn = io.TeeReader(in, hasher)
+		}
+
 		info := object.NewStaticObjectInfo(dstFileName, modTime, size, true, nil, fdst)
-		obj, err = fdst.Put(ctx, in, info)
+		obj, err = fdst.Put(ctx, trackingIn, info)
 		if err != nil {
 			fs.Errorf(dstFileName, "Post request put error: %v", err)
 
 			return nil, err
 		}
+		if obj.Size() >= 0 && obj.Size() != size {
+			err = errors.Errorf("corrupted on transfer: sizes differ %d vs %d", size, obj.Size())
+			err = fs.CountError(err)
+			fs.Errorf(obj, "%v", err)
+			removeFailedCopy(ctx, obj)
+			return nil, err
+		}
+		if hasher != nil {
+			if err = checkRcatHashes(ctx, obj, hasher.Sums()); err != nil {
+				removeFailedCopy(ctx, obj)
+				return nil, err
+			}
+		}
 	} else {
 		// Size unknown use Rcat
 		obj, err = Rcat(ctx, fdst, dstFileName, in, modTime)
@@ -1622,10 +2015,13 @@ func copyURLFn(ctx context.Context, dstFileName string, url string, dstFileNameF
 	if err != nil {
 		return err
 	}
-	defer fs.CheckClose(resp.Body, &err)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_ = resp.Body.Close()
 		return errors.Errorf("CopyURL failed: %s", resp.Status)
 	}
+	// Resume the download if it fails and check its length and MD5
+	in := NewReOpenURL(ctx, client, resp, fs.GetConfig(ctx).LowLevelRetries)
+	defer fs.CheckClose(in, &err)
 	modTime, err := http.ParseTime(resp.Header.Get("Last-Modified"))
 	if err != nil {
 		modTime = time.Now()
@@ -1637,13 +2033,18 @@ func copyURLFn(ctx context.Context, dstFileName string, url string, dstFileNameF
 		}
 		fs.Debugf(dstFileName, "File name found in url")
 	}
-	return fn(ctx, dstFileName, resp.Body, resp.ContentLength, modTime)
+	return fn(ctx, dstFileName, in, resp.ContentLength, modTime)
 }
 
 // CopyURL copies the data from the url to (fdst, dstFileName)
 func CopyURL(ctx context.Context, fdst fs.Fs, dstFileName string, url string, dstFileNameFromURL bool, noClobber bool) (dst fs.Object, err error) {
+	return copyURLToDir(ctx, fdst, "", dstFileName, url, dstFileNameFromURL, noClobber)
+}
 
+// copyURLToDir copies the data from the url to (fdst, dir/dstFileName)
+func copyURLToDir(ctx context.Context, fdst fs.Fs, dir, dstFileName string, url string, dstFileNameFromURL bool, noClobber bool) (dst fs.Object, err error) {
 	err = copyURLFn(ctx, dstFileName, url, dstFileNameFromURL, func(ctx context.Context, dstFileName string, in io.ReadCloser, size int64, modTime time.Time) (err error) {
+		dstFileName = path.Join(dir, dstFileName)
 		if noClobber {
 			_, err = fdst.NewObject(ctx, dstFileName)
 			if err == nil {
@@ -1656,6 +2057,92 @@ func CopyURL(ctx context.Context, fdst fs.Fs, dstFileName string, url string, ds
 	return dst, err
 }
 
+// CopyURLItem is a URL to be copied by CopyURLs
+type CopyURLItem struct {
+	URL string
+	// DstFileName is the path to copy the URL to in the
+	// destination. If it is empty or ends in "/" then the file name
+	// is read from the URL and put in that directory.
+	DstFileName string
+}
+
+// cleanCopyURLDst cleans the DstFileName of a CopyURLItem keeping any
+// trailing "/", returning an error if it is outside the destination.
+func cleanCopyURLDst(dstFileName string) (string, error) {
+	if dstFileName == "" {
+		return "", nil
+	}
+	cleaned := path.Clean(dstFileName)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.Errorf("destination %q is outside the destination directory", dstFileName)
+	}
+	if cleaned == "." {
+		return "", nil
+	}
+	if strings.HasSuffix(dstFileName, "/") {
+		cleaned += "/"
+	}
+	return cleaned, nil
+}
+
+// CopyURLs copies the data from each of the urls in items to fdst,
+// running --transfers copies at once.
+//
+// callback, if set, is called with each object copied and may be
+// called concurrently. Errors are logged and counted and the copies
+// carry on, returning an error at the end if any failed.
+func CopyURLs(ctx context.Context, fdst fs.Fs, items []CopyURLItem, noClobber bool, callback func(dst fs.Object)) error {
+	ci := fs.GetConfig(ctx)
+	var (
+		wg       sync.WaitGroup
+		tokens   = make(chan struct{}, ci.Transfers)
+		errCount int32
+		lastErr  atomic.Value
+	)
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		item := item
+		wg.Add(1)
+		tokens <- struct{}{} // put a token to limit concurrency
+		go func() {
+			defer func() {
+				<-tokens // get the token back to free up a slot
+				wg.Done()
+			}()
+			dstFileName, err := cleanCopyURLDst(item.DstFileName)
+			if err != nil {
+				err = fs.CountError(err)
+				fs.Errorf(item.URL, "Failed to copy URL: %v", err)
+				atomic.AddInt32(&errCount, 1)
+				lastErr.Store(err)
+				return
+			}
+			dir, leaf := path.Split(dstFileName)
+			dst, err := copyURLToDir(ctx, fdst, dir, leaf, item.URL, leaf == "", noClobber)
+			if err != nil {
+				err = fs.CountError(err)
+				fs.Errorf(item.URL, "Failed to copy URL: %v", err)
+				atomic.AddInt32(&errCount, 1)
+				lastErr.Store(err)
+				return
+			}
+			if callback != nil {
+				callback(dst)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if errCount > 0 {
+		return errors.Wrapf(lastErr.Load().(error), "failed to copy %d of %d URLs", errCount, len(items))
+	}
+	return nil
+}
+
 // CopyURLToWriter copies the data from the url to the io.Writer supplied
 func CopyURLToWriter(ctx context.Context, url string, out io.Writer) (err error) {
 	return copyURLFn(ctx, "", url, false, func(ctx context.Context, dstFileName string, in io.ReadCloser, size int64, modTime time.Time) (err error) {
@@ -2112,6 +2599,53 @@ func GetFsInfo(f fs.Fs) *FsInfo {
 	return info
 }
 
+// HealthStatus is the result of a health check of a remote
+type HealthStatus struct {
+	OK       bool    `json:"ok"`              // whether the remote is healthy
+	Method   string  `json:"method"`          // how it was checked: HealthCheck, About or List
+	Duration float64 `json:"duration"`        // time the check took in seconds
+	Error    string  `json:"error,omitempty"` // error if the check failed
+}
+
+// HealthCheck checks the credentials of f are valid and that it can
+// be reached.
+//
+// This uses the HealthCheck feature if the backend has it, otherwise
+// About, otherwise a listing of the root as a last resort. A root
+// directory which doesn't exist counts as healthy as the remote could
+// be reached to find that out.
+func HealthCheck(ctx context.Context, f fs.Fs) *HealthStatus {
+	var (
+		features = f.Features()
+		status   = &HealthStatus{}
+		start    = time.Now()
+		err      error
+	)
+	switch {
+	case features.HealthCheck != nil:
+		status.Method = "HealthCheck"
+		err = features.HealthCheck(ctx)
+	case features.About != nil:
+		status.Method = "About"
+		_, err = features.About(ctx)
+	default:
+		status.Method = "List"
+		_, err = f.List(ctx, "")
+	}
+	status.Duration = time.Since(start).Seconds()
+	if err == fs.ErrorDirNotFound {
+		err = nil
+	}
+	if err != nil {
+		status.Error = err.Error()
+		fs.Errorf(f, "Health check using %s failed: %v", status.Method, err)
+	} else {
+		status.OK = true
+		fs.Debugf(f, "Health check using %s succeeded", status.Method)
+	}
+	return status
+}
+
 var (
 	interactiveMu sync.Mutex
 	skipped       = map[string]bool{}
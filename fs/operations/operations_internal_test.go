@@ -3,14 +3,17 @@
 package operations
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/hash"
 	"github.com/artpar/rclone/fs/object"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSizeDiffers(t *testing.T) {
@@ -41,3 +44,87 @@ func TestSizeDiffers(t *testing.T) {
 		assert.Equal(t, test.want, got, fmt.Sprintf("ignoreSize=%v, srcSize=%v, dstSize=%v", test.ignoreSize, test.srcSize, test.dstSize))
 	}
 }
+
+// testPatchWriter records the writes made to a buffer
+type testPatchWriter struct {
+	buf     []byte
+	written []int64
+}
+
+func (p *testPatchWriter) WriteAt(b []byte, off int64) (int, error) {
+	if end := off + int64(len(b)); end > int64(len(p.buf)) {
+		p.buf = append(p.buf, make([]byte, end-int64(len(p.buf)))...)
+	}
+	copy(p.buf[off:], b)
+	p.written = append(p.written, off)
+	return len(b), nil
+}
+
+func (p *testPatchWriter) Truncate(size int64) error {
+	p.buf = p.buf[:size]
+	return nil
+}
+
+func (p *testPatchWriter) Close() error {
+	return nil
+}
+
+func TestPatchChangedBlocks(t *testing.T) {
+	const B = changedBlockSize
+	makeData := func(size int, seed byte) []byte {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i/7) + seed
+		}
+		return data
+	}
+	old := makeData(4*B, 0)
+	for _, test := range []struct {
+		name    string
+		new     []byte
+		written []int64
+	}{
+		{"identical", append([]byte(nil), old...), nil},
+		{"middle changed", func() []byte {
+			data := append([]byte(nil), old...)
+			data[2*B+10] ^= 0xFF
+			return data
+		}(), []int64{2 * B}},
+		{"extended", append(append([]byte(nil), old...), makeData(B/2, 3)...), []int64{4 * B}},
+		{"truncated", append([]byte(nil), old[:3*B+5]...), nil},
+		{"different", makeData(4*B, 1), []int64{0, B, 2 * B, 3 * B}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			out := &testPatchWriter{buf: append([]byte(nil), old...)}
+			written, size, err := patchChangedBlocks(out, bytes.NewReader(test.new), bytes.NewReader(old), int64(len(old)))
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(test.new)), size)
+			assert.Equal(t, test.written, out.written)
+			assert.Equal(t, test.new, out.buf)
+			var wantWritten int64
+			for _, off := range test.written {
+				end := off + B
+				if end > size {
+					end = size
+				}
+				wantWritten += end - off
+			}
+			assert.Equal(t, wantWritten, written)
+		})
+	}
+}
+
+func TestCheckRcatHashes(t *testing.T) {
+	ctx := context.Background()
+	o := object.NewMemoryObject("potato", time.Now(), []byte("hello"))
+	goodMD5 := "5d41402abc4b2a76b9719d911017c592"
+
+	assert.NoError(t, checkRcatHashes(ctx, o, nil))
+	assert.NoError(t, checkRcatHashes(ctx, o, map[hash.Type]string{hash.None: ""}))
+	assert.NoError(t, checkRcatHashes(ctx, o, map[hash.Type]string{hash.MD5: goodMD5}))
+	assert.NoError(t, checkRcatHashes(ctx, o, map[hash.Type]string{hash.MD5: ""}))
+
+	err := checkRcatHashes(ctx, o, map[hash.Type]string{hash.MD5: "00000000000000000000000000000000"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "corrupted on transfer: MD5 hash differ")
+}
@@ -29,7 +29,9 @@ import (
 	"net/http/httptest"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -40,9 +42,12 @@ import (
 	"github.com/artpar/rclone/fs/fserrors"
 	"github.com/artpar/rclone/fs/fshttp"
 	"github.com/artpar/rclone/fs/hash"
+	"github.com/artpar/rclone/fs/object"
 	"github.com/artpar/rclone/fs/operations"
 	"github.com/artpar/rclone/fstest"
+	"github.com/artpar/rclone/fstest/mockfs"
 	"github.com/artpar/rclone/lib/random"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -180,6 +185,75 @@ func TestLsLong(t *testing.T) {
 	}
 }
 
+func TestLsPorcelain(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteBoth(ctx, "potato2", "------------------------------------------------------------", t1)
+	file2 := r.WriteBoth(ctx, "empty space", "-", t2)
+
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	var buf bytes.Buffer
+	err := operations.ListPorcelain(ctx, r.Fremote, &buf)
+	require.NoError(t, err)
+	res := buf.String()
+	assert.Contains(t, res, "1\tempty space\n")
+	assert.Contains(t, res, "60\tpotato2\n")
+}
+
+func TestLsLongPorcelain(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteBoth(ctx, "potato2", "------------------------------------------------------------", t1)
+	file2 := r.WriteBoth(ctx, "empty space", "-", t2)
+
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	var buf bytes.Buffer
+	err := operations.ListLongPorcelain(ctx, r.Fremote, &buf)
+	require.NoError(t, err)
+	res := buf.String()
+	lines := strings.Split(strings.Trim(res, "\n"), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	precision := r.Fremote.Precision()
+	for _, test := range []struct {
+		size     string
+		name     string
+		expected time.Time
+	}{
+		{"1", "empty space", t2},
+		{"60", "potato2", t1},
+	} {
+		m := regexp.MustCompile(`(?m)^` + test.size + `\t(\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{9}Z)\t` + test.name + `$`)
+		ms := m.FindStringSubmatch(res)
+		if ms == nil {
+			t.Errorf("%s missing: %q", test.name, res)
+			continue
+		}
+		modTime, err := time.Parse(operations.PorcelainTimeFormat, ms[1])
+		require.NoError(t, err)
+		fstest.AssertTimeEqualWithPrecision(t, test.name, test.expected, modTime, precision)
+	}
+}
+
+func TestPorcelainEscape(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"potato", "potato"},
+		{"a\tb", `a\tb`},
+		{"a\nb\rc", `a\nb\rc`},
+		{`a\tb`, `a\\tb`},
+	} {
+		assert.Equal(t, test.want, operations.PorcelainEscape(test.in), test.in)
+	}
+}
+
 func TestHashSums(t *testing.T) {
 	ctx := context.Background()
 	r := fstest.NewRun(t)
@@ -375,6 +449,38 @@ func TestCount(t *testing.T) {
 	assert.Equal(t, int64(61), size)
 }
 
+func TestCountDirs(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteObject(ctx, "potato2", "------------------------------------------------------------", t1)
+	file2 := r.WriteObject(ctx, "sub dir/potato3", "hello", t2)
+	file3 := r.WriteObject(ctx, "sub dir/sub sub/potato4", "hello again", t2)
+	file4 := r.WriteObject(ctx, "dir2/potato5", "-", t2)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4)
+
+	dirs, err := operations.CountDirs(ctx, r.Fremote, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []operations.DirSize{{Path: "", Count: 4, Bytes: 77}}, dirs)
+
+	dirs, err = operations.CountDirs(ctx, r.Fremote, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []operations.DirSize{
+		{Path: "", Count: 4, Bytes: 77},
+		{Path: "dir2", Count: 1, Bytes: 1},
+		{Path: "sub dir", Count: 2, Bytes: 16},
+	}, dirs)
+
+	dirs, err = operations.CountDirs(ctx, r.Fremote, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []operations.DirSize{
+		{Path: "", Count: 4, Bytes: 77},
+		{Path: "dir2", Count: 1, Bytes: 1},
+		{Path: "sub dir", Count: 2, Bytes: 16},
+		{Path: "sub dir/sub sub", Count: 1, Bytes: 11},
+	}, dirs)
+}
+
 func TestDelete(t *testing.T) {
 	ctx := context.Background()
 	fi, err := filter.NewFilter(nil)
@@ -393,6 +499,28 @@ func TestDelete(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file3)
 }
 
+func TestDeleteMaxDeletePercent(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteObject(ctx, "dir/file1", "1234567890", t1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	ctx, ci := fs.AddConfig(ctx)
+	require.NoError(t, ci.MaxDelete.Set("50%"))
+	for _, fn := range []func() error{
+		func() error { return operations.Delete(ctx, r.Fremote) },
+		func() error { return operations.Purge(ctx, r.Fremote, "dir") },
+		func() error { return operations.Rmdirs(ctx, r.Fremote, "", false) },
+	} {
+		err := fn()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only supported by sync")
+		assert.True(t, fserrors.IsFatalError(err))
+	}
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
 func TestRetry(t *testing.T) {
 	ctx := context.Background()
 
@@ -749,6 +877,72 @@ func TestCopyURL(t *testing.T) {
 	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, file2, fstest.NewItem(urlFileName, contents, t1)}, nil, fs.ModTimeNotSupported)
 }
 
+func TestCopyURLs(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.Mkdir(ctx, r.Fremote)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte("contents of " + r.URL.Path))
+		assert.NoError(t, err)
+	})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var copied []string
+	err := operations.CopyURLs(ctx, r.Fremote, []operations.CopyURLItem{
+		{URL: ts.URL + "/a.txt"},
+		{URL: ts.URL + "/b.txt", DstFileName: "renamed.txt"},
+		{URL: ts.URL + "/c.txt", DstFileName: "dir/"},
+		{URL: ts.URL + "/d.txt", DstFileName: "dir/sub/d2.txt"},
+	}, false, func(dst fs.Object) {
+		mu.Lock()
+		copied = append(copied, dst.Remote())
+		mu.Unlock()
+	})
+	require.NoError(t, err)
+	sort.Strings(copied)
+	assert.Equal(t, []string{"a.txt", "dir/c.txt", "dir/sub/d2.txt", "renamed.txt"}, copied)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{
+		fstest.NewItem("a.txt", "contents of /a.txt", t1),
+		fstest.NewItem("renamed.txt", "contents of /b.txt", t1),
+		fstest.NewItem("dir/c.txt", "contents of /c.txt", t1),
+		fstest.NewItem("dir/sub/d2.txt", "contents of /d.txt", t1),
+	}, []string{"dir", "dir/sub"}, fs.ModTimeNotSupported)
+
+	// Check errors are returned but the other URLs are copied
+	err = operations.CopyURLs(ctx, r.Fremote, []operations.CopyURLItem{
+		{URL: ts.URL + "/missing"},
+		{URL: ts.URL + "/a.txt"},
+		{URL: ts.URL + "/e.txt"},
+	}, true, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to copy 2 of 3 URLs")
+	_, err = r.Fremote.NewObject(ctx, "e.txt")
+	require.NoError(t, err)
+
+	// Check destinations are cleaned and can't escape fdst
+	err = operations.CopyURLs(ctx, r.Fremote, []operations.CopyURLItem{
+		{URL: ts.URL + "/f.txt", DstFileName: "dir/../other//f.txt"},
+		{URL: ts.URL + "/g.txt", DstFileName: "./other/./"},
+		{URL: ts.URL + "/h.txt", DstFileName: "../h.txt"},
+		{URL: ts.URL + "/i.txt", DstFileName: "dir/../../i.txt"},
+	}, false, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to copy 2 of 4 URLs")
+	assert.Contains(t, err.Error(), "outside the destination directory")
+	for _, remote := range []string{"other/f.txt", "other/g.txt"} {
+		_, err = r.Fremote.NewObject(ctx, remote)
+		require.NoError(t, err, remote)
+	}
+}
+
 func TestCopyURLToWriter(t *testing.T) {
 	ctx := context.Background()
 	contents := "file contents\n"
@@ -898,6 +1092,133 @@ func TestCopyFile(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file2)
 }
 
+func TestCopyFileMetadata(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if !r.Fremote.Features().WriteMetadata {
+		t.Skip("Can't test metadata without WriteMetadata")
+	}
+	ci.Metadata = true
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	fstest.CheckItems(t, r.Flocal, file1)
+	require.NoError(t, os.Chmod(r.LocalName+"/"+file1.Path, 0600))
+	src, err := r.Flocal.NewObject(ctx, file1.Path)
+	require.NoError(t, err)
+	srcMetadata, err := fs.GetMetadata(ctx, src)
+	require.NoError(t, err)
+
+	err = operations.CopyFile(ctx, r.Fremote, r.Flocal, file1.Path, file1.Path)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+	dst, err := r.Fremote.NewObject(ctx, file1.Path)
+	require.NoError(t, err)
+	dstMetadata, err := fs.GetMetadata(ctx, dst)
+	require.NoError(t, err)
+	for key, value := range srcMetadata {
+		assert.Equal(t, value, dstMetadata[key], key)
+	}
+}
+
+func TestMoveFileMetadata(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if !r.Fremote.Features().WriteMetadata {
+		t.Skip("Can't test metadata without WriteMetadata")
+	}
+	ci.Metadata = true
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	fstest.CheckItems(t, r.Flocal, file1)
+	require.NoError(t, os.Chmod(r.LocalName+"/"+file1.Path, 0600))
+	src, err := r.Flocal.NewObject(ctx, file1.Path)
+	require.NoError(t, err)
+	srcMetadata, err := fs.GetMetadata(ctx, src)
+	require.NoError(t, err)
+
+	err = operations.MoveFile(ctx, r.Fremote, r.Flocal, file1.Path, file1.Path)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Flocal)
+	fstest.CheckItems(t, r.Fremote, file1)
+	dst, err := r.Fremote.NewObject(ctx, file1.Path)
+	require.NoError(t, err)
+	dstMetadata, err := fs.GetMetadata(ctx, dst)
+	require.NoError(t, err)
+	for key, value := range srcMetadata {
+		assert.Equal(t, value, dstMetadata[key], key)
+	}
+}
+
+func TestCopyFilePartial(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Fremote.Features().Move == nil {
+		t.Skip("Can't test partial uploads without Move")
+	}
+	ci.PartialSuffix = ".partial"
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	fstest.CheckItems(t, r.Flocal, file1)
+
+	err := operations.CopyFile(ctx, r.Fremote, r.Flocal, file1.Path, file1.Path)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	// Replace the existing file
+	file1b := r.WriteFile("file1", "file1 new contents", t2)
+	err = operations.CopyFile(ctx, r.Fremote, r.Flocal, file1b.Path, file1b.Path)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1b)
+}
+
+func TestCleanupPartials(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	stale := r.WriteObject(ctx, "dir/file1.1.partial", "stale", t1)
+	recent := r.WriteObject(ctx, fmt.Sprintf("dir/file2.%x.partial", time.Now().Unix()), "recent", t1)
+	other := r.WriteObject(ctx, "file3.partial", "not a partial upload", t1)
+	file4 := r.WriteObject(ctx, "file4", "file4", t1)
+	fstest.CheckItems(t, r.Fremote, stale, recent, other, file4)
+
+	err := operations.CleanupPartials(ctx, r.Fremote, time.Hour)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, recent, other, file4)
+}
+
+func TestCopyFileWriteChangedBlocks(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ci.WriteChangedBlocks = true
+
+	contents := random.String(1024 * 1024)
+	file1 := r.WriteObject(ctx, "file1", contents, t1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	// Change a block in the middle and extend the file
+	changed := contents[:500000] + "CHANGED" + contents[500007:] + "extra"
+	file2 := r.WriteFile("file1", changed, t2)
+
+	err := operations.CopyFile(ctx, r.Fremote, r.Flocal, file2.Path, file2.Path)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file2)
+
+	// Shrink the file
+	file3 := r.WriteFile("file1", changed[:300000], t3)
+	err = operations.CopyFile(ctx, r.Fremote, r.Flocal, file3.Path, file3.Path)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file3)
+}
+
 func TestCopyFileBackupDir(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
@@ -1413,6 +1734,33 @@ func TestGetFsInfo(t *testing.T) {
 	assert.Equal(t, f.Features().Enabled(), info.Features)
 }
 
+func TestHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	f := mockfs.NewFs(ctx, "mock", "")
+
+	// No features so falls back to listing
+	status := operations.HealthCheck(ctx, f)
+	assert.Equal(t, &operations.HealthStatus{OK: true, Method: "List", Duration: status.Duration}, status)
+
+	// About is used if available
+	f.Features().About = func(ctx context.Context) (*fs.Usage, error) {
+		return nil, errors.New("bad credentials")
+	}
+	status = operations.HealthCheck(ctx, f)
+	assert.False(t, status.OK)
+	assert.Equal(t, "About", status.Method)
+	assert.Equal(t, "bad credentials", status.Error)
+
+	// HealthCheck is preferred and a missing root is healthy
+	f.Features().HealthCheck = func(ctx context.Context) error {
+		return fs.ErrorDirNotFound
+	}
+	status = operations.HealthCheck(ctx, f)
+	assert.True(t, status.OK)
+	assert.Equal(t, "HealthCheck", status.Method)
+	assert.Equal(t, "", status.Error)
+}
+
 func TestRcat(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
@@ -1468,6 +1816,103 @@ func TestRcat(t *testing.T) {
 	}
 }
 
+// chunkedFs wraps an Fs hiding PutStream and adding OpenChunkWriter
+// which stores the chunks in memory until they are complete
+type chunkedFs struct {
+	fs.Fs
+	info       fs.ChunkWriterInfo
+	chunkSizes []int
+	aborted    bool
+}
+
+// Features returns the optional features of this Fs
+func (f *chunkedFs) Features() *fs.Features {
+	ft := *f.Fs.Features()
+	ft.PutStream = nil
+	ft.OpenChunkWriter = f.OpenChunkWriter
+	return &ft
+}
+
+// OpenChunkWriter opens an in memory chunk writer
+func (f *chunkedFs) OpenChunkWriter(ctx context.Context, remote string, src fs.ObjectInfo, options ...fs.OpenOption) (fs.ChunkWriterInfo, fs.ChunkWriter, error) {
+	return f.info, &chunkWriter{f: f, src: src}, nil
+}
+
+type chunkWriter struct {
+	f   *chunkedFs
+	src fs.ObjectInfo
+	buf bytes.Buffer
+}
+
+func (w *chunkWriter) WriteChunk(ctx context.Context, chunkNumber int, reader io.ReadSeeker) (int64, error) {
+	if chunkNumber != len(w.f.chunkSizes) {
+		return 0, errors.Errorf("chunk %d written out of order", chunkNumber)
+	}
+	n, err := io.Copy(&w.buf, reader)
+	w.f.chunkSizes = append(w.f.chunkSizes, int(n))
+	return n, err
+}
+
+func (w *chunkWriter) Close(ctx context.Context) error {
+	src := object.NewStaticObjectInfo(w.src.Remote(), w.src.ModTime(ctx), int64(w.buf.Len()), true, nil, nil)
+	_, err := w.f.Fs.Put(ctx, &w.buf, src)
+	return err
+}
+
+func (w *chunkWriter) Abort(ctx context.Context) error {
+	w.f.aborted = true
+	return nil
+}
+
+func TestRcatChunked(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	ci.StreamingUploadCutoff = 10
+	f := &chunkedFs{
+		Fs:   r.Fremote,
+		info: fs.ChunkWriterInfo{ChunkSize: 16, MinChunkSize: 8, MaxChunks: 4},
+	}
+
+	// uses the chunk size from the backend
+	data := strings.Repeat("0123456789", 5)
+	obj, err := operations.Rcat(ctx, f, "chunked", ioutil.NopCloser(strings.NewReader(data)), t1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), obj.Size())
+	assert.Equal(t, []int{16, 16, 16, 2}, f.chunkSizes)
+
+	// uses the chunk size from the config, but no smaller than the minimum
+	f.chunkSizes = nil
+	ci.StreamingChunkSize = 4
+	data = strings.Repeat("0123456789", 3)
+	_, err = operations.Rcat(ctx, f, "chunked2", ioutil.NopCloser(strings.NewReader(data)), t2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{8, 8, 8, 6}, f.chunkSizes)
+
+	fstest.CheckItems(t, r.Fremote,
+		fstest.NewItem("chunked", strings.Repeat("0123456789", 5), t1),
+		fstest.NewItem("chunked2", data, t2),
+	)
+
+	// too many chunks aborts the upload
+	f.chunkSizes = nil
+	data = strings.Repeat("0123456789", 4)
+	_, err = operations.Rcat(ctx, f, "chunked3", ioutil.NopCloser(strings.NewReader(data)), t2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stream too large")
+	assert.True(t, f.aborted)
+
+	// an empty stream is uploaded without writing any chunks
+	f.chunkSizes = nil
+	ci.StreamingUploadCutoff = 0
+	obj, err = operations.Rcat(ctx, f, "empty", ioutil.NopCloser(strings.NewReader("")), t1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), obj.Size())
+	assert.Nil(t, f.chunkSizes)
+}
+
 func TestRcatSize(t *testing.T) {
 	ctx := context.Background()
 	r := fstest.NewRun(t)
@@ -1495,6 +1940,21 @@ func TestRcatSize(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1, file2)
 }
 
+func TestRcatSizeMismatch(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	const body = "------------------------------------------------------------"
+	bodyReader := ioutil.NopCloser(strings.NewReader(body))
+	obj, err := operations.RcatSize(ctx, r.Fremote, "potato", bodyReader, int64(len(body))-1, t1)
+	require.Error(t, err)
+	assert.Nil(t, obj)
+
+	// Check the corrupted file was removed
+	fstest.CheckItems(t, r.Fremote)
+}
+
 func TestCopyFileMaxTransfer(t *testing.T) {
 	ctx := context.Background()
 	ctx, ci := fs.AddConfig(ctx)
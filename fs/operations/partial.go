@@ -0,0 +1,137 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/walk"
+	"github.com/pkg/errors"
+)
+
+// DefaultPartialSuffix is the suffix CleanupPartials looks for if
+// --partial-suffix isn't set
+const DefaultPartialSuffix = ".partial"
+
+// partialName returns the name to upload remote to before renaming it
+// into place.
+//
+// The time the upload started is encoded in the name so stale partial
+// uploads can be found without relying on the modification time which
+// is set from the source.
+func partialName(remote string, suffix string, now time.Time) string {
+	return fmt.Sprintf("%s.%x%s", remote, now.Unix(), suffix)
+}
+
+// partialStartTime returns the time the upload of the partial upload
+// remote started or false if remote isn't a partial upload
+func partialStartTime(remote string, suffix string) (t time.Time, ok bool) {
+	if suffix == "" || !strings.HasSuffix(remote, suffix) {
+		return t, false
+	}
+	remote = strings.TrimSuffix(remote, suffix)
+	dot := strings.LastIndex(remote, ".")
+	if dot < 0 {
+		return t, false
+	}
+	unix, err := strconv.ParseInt(remote[dot+1:], 16, 64)
+	if err != nil {
+		return t, false
+	}
+	return time.Unix(unix, 0), true
+}
+
+// IsStalePartial returns true if o is a partial upload made with the
+// --partial-suffix which was started longer than --partial-max-age ago
+func IsStalePartial(ctx context.Context, o fs.Object) bool {
+	ci := fs.GetConfig(ctx)
+	started, ok := partialStartTime(o.Remote(), ci.PartialSuffix)
+	return ok && time.Since(started) > ci.PartialMaxAge
+}
+
+// canPutPartial returns true if uploads to f should go via a partial
+// upload.
+//
+// This needs Move to replace the existing file, so backends which can
+// have duplicate files are excluded.
+func canPutPartial(ctx context.Context, f fs.Fs) bool {
+	features := f.Features()
+	return fs.GetConfig(ctx).PartialSuffix != "" && features.Move != nil && !features.DuplicateFiles
+}
+
+// putPartial uploads in to a partial upload name made from remote
+// then renames it to remote, replacing any existing file there.
+//
+// This means an interrupted upload leaves a partial upload rather than
+// a truncated file at remote.
+//
+// Not all backends overwrite on Move so if the rename fails and there
+// is an existing file at remote, that is deleted and the rename
+// retried. If the rename still fails the partial upload is left for
+// cleanup-partials to remove.
+func putPartial(ctx context.Context, f fs.Fs, remote string, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (newDst fs.Object, err error) {
+	partialRemote := partialName(remote, fs.GetConfig(ctx).PartialSuffix, time.Now())
+	partial, err := f.Put(ctx, in, NewOverrideRemote(src, partialRemote), options...)
+	if err != nil {
+		if partial != nil {
+			_ = partial.Remove(ctx)
+		}
+		return nil, err
+	}
+	doMove := f.Features().Move
+	newDst, err = doMove(ctx, partial, remote)
+	if err != nil {
+		dst, findErr := f.NewObject(ctx, remote)
+		if findErr == nil && !SameObject(partial, dst) {
+			fs.Debugf(dst, "Removing existing file as rename of partial upload failed: %v", err)
+			err = dst.Remove(ctx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to remove existing file to rename partial upload %q", partialRemote)
+			}
+			newDst, err = doMove(ctx, partial, remote)
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to rename partial upload %q", partialRemote)
+	}
+	return newDst, nil
+}
+
+// CleanupPartials removes partial uploads made with --partial-suffix
+// (or DefaultPartialSuffix if not set) in f which were started more
+// than maxAge ago.
+func CleanupPartials(ctx context.Context, f fs.Fs, maxAge time.Duration) error {
+	suffix := fs.GetConfig(ctx).PartialSuffix
+	if suffix == "" {
+		suffix = DefaultPartialSuffix
+	}
+	var (
+		deleted   int
+		deleteErr error
+	)
+	err := walk.ListR(ctx, f, "", true, -1, walk.ListObjects, func(entries fs.DirEntries) error {
+		entries.ForObject(func(o fs.Object) {
+			started, ok := partialStartTime(o.Remote(), suffix)
+			if !ok || time.Since(started) <= maxAge {
+				return
+			}
+			fs.Debugf(o, "Removing partial upload started at %v", started)
+			err := DeleteFile(ctx, o)
+			if err != nil {
+				deleteErr = err
+				return
+			}
+			deleted++
+		})
+		return nil
+	})
+	fs.Infof(f, "Removed %d stale partial uploads", deleted)
+	if err != nil {
+		return err
+	}
+	return deleteErr
+}
@@ -0,0 +1,110 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/object"
+	"github.com/artpar/rclone/fstest"
+	"github.com/artpar/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartialName(t *testing.T) {
+	now := time.Unix(0x5f8a1b2c, 0)
+	name := partialName("dir/file.txt", ".partial", now)
+	assert.Equal(t, "dir/file.txt.5f8a1b2c.partial", name)
+
+	started, ok := partialStartTime(name, ".partial")
+	assert.True(t, ok)
+	assert.True(t, started.Equal(now))
+
+	for _, remote := range []string{
+		"dir/file.txt",
+		"dir/file.txt.partial",
+		"dir/file.txt.potato.partial",
+		"file.5f8a1b2c.part",
+	} {
+		_, ok := partialStartTime(remote, ".partial")
+		assert.False(t, ok, remote)
+	}
+	_, ok = partialStartTime(name, "")
+	assert.False(t, ok)
+}
+
+func TestIsStalePartial(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	ci.PartialMaxAge = time.Hour
+	old := mockobject.Object(partialName("file.txt", ".partial", time.Now().Add(-2*time.Hour)))
+	recent := mockobject.Object(partialName("file.txt", ".partial", time.Now()))
+
+	// Not stale if --partial-suffix isn't set
+	assert.False(t, IsStalePartial(ctx, old))
+
+	ci.PartialSuffix = ".partial"
+	assert.True(t, IsStalePartial(ctx, old))
+	assert.False(t, IsStalePartial(ctx, recent))
+	assert.False(t, IsStalePartial(ctx, mockobject.Object("file.txt")))
+}
+
+// failMoveFs is an Fs whose Move always fails
+type failMoveFs struct {
+	fs.Fs
+	features *fs.Features
+}
+
+func (f *failMoveFs) Features() *fs.Features {
+	return f.features
+}
+
+func TestPutPartialMoveFails(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Fremote.Features().Move == nil {
+		t.Skip("Can't test partial uploads without Move")
+	}
+	ci.PartialSuffix = ".partial"
+
+	f := &failMoveFs{Fs: r.Fremote}
+	features := *r.Fremote.Features()
+	f.features = &features
+	contents := "new contents"
+	src := object.NewStaticObjectInfo("file.txt", time.Now(), int64(len(contents)), true, nil, nil)
+
+	// A backend which won't overwrite an existing file with Move
+	r.WriteObject(ctx, "file.txt", "old contents", fstest.Time("2001-02-03T04:05:06.499999999Z"))
+	features.Move = func(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+		if _, err := r.Fremote.NewObject(ctx, remote); err == nil {
+			return nil, errors.New("file exists")
+		}
+		return r.Fremote.Features().Move(ctx, src, remote)
+	}
+	newDst, err := putPartial(ctx, f, "file.txt", strings.NewReader(contents), src)
+	require.NoError(t, err)
+	assert.Equal(t, "file.txt", newDst.Remote())
+	assert.Equal(t, int64(len(contents)), newDst.Size())
+	entries, err := r.Fremote.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+
+	// A backend where Move always fails leaves the partial upload
+	features.Move = func(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+		return nil, errors.New("move failed")
+	}
+	_, err = putPartial(ctx, f, "file.txt", strings.NewReader(contents), src)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "move failed")
+	entries, err = r.Fremote.List(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	_, ok := partialStartTime(entries[0].Remote(), ci.PartialSuffix)
+	assert.True(t, ok, entries[0].Remote())
+}
@@ -2,6 +2,7 @@ package operations
 
 import (
 	"context"
+	"encoding/base64"
 	"io"
 	"mime"
 	"mime/multipart"
@@ -66,6 +67,51 @@ func rcList(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	return out, nil
 }
 
+func init() {
+	rc.Add(rc.Call{
+		Path:         "operations/stat",
+		AuthRequired: true,
+		Fn:           rcStat,
+		Title:        "Give information about the supplied file or directory",
+		Help: `This takes the following parameters
+
+- fs - a remote name string eg "drive:"
+- remote - a path within that remote eg "dir"
+- opt - a dictionary of options to control the listing (optional)
+    - see operations/list for the options
+
+The result is
+
+- item - an object as described in the lsjson command. Will be null if not found.
+
+Note that if you are only interested in files then it is much more
+efficient to set the filesOnly flag in the options.
+
+See the [lsjson command](/commands/rclone_lsjson/) for more information on the above and examples.
+`,
+	})
+}
+
+// Stat a file or directory
+func rcStat(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, remote, err := rc.GetFsAndRemote(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	var opt ListJSONOpt
+	err = in.GetStruct("opt", &opt)
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	item, err := StatJSON(ctx, f, remote, &opt)
+	if err != nil {
+		return nil, err
+	}
+	out = make(rc.Params)
+	out["item"] = item
+	return out, nil
+}
+
 func init() {
 	rc.Add(rc.Call{
 		Path:         "operations/about",
@@ -269,6 +315,37 @@ func rcSingleCommand(ctx context.Context, in rc.Params, name string, noRemote bo
 	panic("unknown rcSingleCommand type")
 }
 
+func init() {
+	rc.Add(rc.Call{
+		Path:         "operations/cleanuppartials",
+		AuthRequired: true,
+		Fn:           rcCleanupPartials,
+		Title:        "Remove stale partial uploads in the remote or path",
+		Help: `This takes the following parameters
+
+- fs - a remote name string e.g. "drive:path/to/dir"
+- maxAge - remove partial uploads started longer ago than this (default --partial-max-age)
+
+See the [cleanup-partials command](/commands/rclone_cleanup-partials/) command for more information on the above.
+`,
+	})
+}
+
+// Remove stale partial uploads
+func rcCleanupPartials(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, err := rc.GetFs(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	maxAge, err := in.GetDuration("maxAge")
+	if rc.IsErrParamNotFound(err) {
+		maxAge = fs.GetConfig(ctx).PartialMaxAge
+	} else if err != nil {
+		return nil, err
+	}
+	return nil, CleanupPartials(ctx, f, maxAge)
+}
+
 func init() {
 	rc.Add(rc.Call{
 		Path:         "operations/size",
@@ -334,11 +411,14 @@ func rcPublicLink(ctx context.Context, in rc.Params) (out rc.Params, err error)
 		return nil, err
 	}
 	unlink, _ := in.GetBool("unlink")
-	expire, err := in.GetDuration("expire")
-	if err != nil && !rc.IsErrParamNotFound(err) {
+	expire := fs.LinkExpireNever
+	expireDuration, err := in.GetDuration("expire")
+	if err == nil {
+		expire = fs.Duration(expireDuration)
+	} else if !rc.IsErrParamNotFound(err) {
 		return nil, err
 	}
-	url, err := PublicLink(ctx, f, remote, fs.Duration(expire), unlink)
+	url, err := PublicLink(ctx, f, remote, expire, unlink)
 	if err != nil {
 		return nil, err
 	}
@@ -347,6 +427,64 @@ func rcPublicLink(ctx context.Context, in rc.Params) (out rc.Params, err error)
 	return out, nil
 }
 
+func init() {
+	rc.Add(rc.Call{
+		Path:         "operations/thumbnail",
+		AuthRequired: true,
+		Fn:           rcThumbnail,
+		Title:        "Make a thumbnail of an image file",
+		Help: `This takes the following parameters
+
+- fs - a remote name string e.g. "drive:"
+- remote - a path within that remote e.g. "dir/photo.jpg"
+- size - maximum width or height of the thumbnail in pixels (optional, default 128, max 1024)
+
+The image must be a JPEG, PNG or GIF. PNG images give PNG thumbnails,
+everything else gives JPEG thumbnails. Images larger than 64 MiB or
+with more than 64 megapixels are refused.
+
+Returns
+
+- mimeType - the mime type of the thumbnail
+- width - the width of the thumbnail in pixels
+- height - the height of the thumbnail in pixels
+- data - the thumbnail image, base64 encoded
+
+Thumbnails are cached by rclone so asking for the same thumbnail again
+is cheap unless the file has changed. This is intended for file
+browsers like the web GUI so they can show previews of images without
+downloading the whole file into the browser.
+`,
+	})
+}
+
+// Make a thumbnail of an image
+func rcThumbnail(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, remote, err := rc.GetFsAndRemote(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	size, err := in.GetInt64("size")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	o, err := f.NewObject(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := MakeThumbnail(ctx, o, int(size))
+	if err != nil {
+		return nil, err
+	}
+	out = rc.Params{
+		"mimeType": thumb.MimeType,
+		"width":    thumb.Width,
+		"height":   thumb.Height,
+		"data":     base64.StdEncoding.EncodeToString(thumb.Data),
+	}
+	return out, nil
+}
+
 func init() {
 	rc.Add(rc.Call{
 		Path:  "operations/fsinfo",
@@ -425,6 +563,47 @@ func rcFsInfo(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	return out, nil
 }
 
+func init() {
+	rc.Add(rc.Call{
+		Path:         "operations/health",
+		AuthRequired: true,
+		Fn:           rcHealth,
+		Title:        "Check the credentials of a remote are valid and it can be reached",
+		Help: `This takes the following parameters
+
+- fs - a remote name string e.g. "drive:"
+
+This uses the cheapest call the backend has to check the remote
+without listing it where possible, so is suitable for monitoring.
+
+Returns
+
+- ok - true if the remote is healthy
+- method - how it was checked: "HealthCheck", "About" or "List"
+- duration - time the check took in seconds
+- error - the error if the check failed
+
+Note that a failed check isn't an error for this call - check "ok".
+
+See the [backend health](/commands/rclone_backend/) command for more
+information on the above.
+`,
+	})
+}
+
+// Check the health of the remote
+func rcHealth(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, err := rc.GetFs(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	err = rc.Reshape(&out, HealthCheck(ctx, f))
+	if err != nil {
+		return nil, errors.Wrap(err, "health Reshape failed")
+	}
+	return out, nil
+}
+
 func init() {
 	rc.Add(rc.Call{
 		Path:         "backend/command",
@@ -1,7 +1,12 @@
 package operations_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -261,6 +266,52 @@ func TestRcList(t *testing.T) {
 	checkFile2(list[2])
 }
 
+// operations/stat: Stat the given remote and path in JSON format.
+func TestRcStat(t *testing.T) {
+	ctx := context.Background()
+	r, call := rcNewRun(t, "operations/stat")
+	defer r.Finalise()
+
+	file1 := r.WriteObject(ctx, "subdir/a", "a", t1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	stat := func(remote string, opt rc.Params) *operations.ListJSONItem {
+		in := rc.Params{
+			"fs":     r.FremoteName,
+			"remote": remote,
+		}
+		if opt != nil {
+			in["opt"] = opt
+		}
+		out, err := call.Fn(ctx, in)
+		require.NoError(t, err)
+		return out["item"].(*operations.ListJSONItem)
+	}
+
+	item := stat("subdir/a", nil)
+	require.NotNil(t, item)
+	assert.WithinDuration(t, t1, item.ModTime.When, time.Second)
+	assert.Equal(t, "subdir/a", item.Path)
+	assert.Equal(t, "a", item.Name)
+	assert.Equal(t, int64(1), item.Size)
+	assert.Equal(t, false, item.IsDir)
+
+	item = stat("subdir", nil)
+	require.NotNil(t, item)
+	assert.Equal(t, "subdir", item.Path)
+	assert.Equal(t, "subdir", item.Name)
+	assert.Equal(t, true, item.IsDir)
+
+	assert.Nil(t, stat("subdir", rc.Params{"filesOnly": true}))
+	assert.Nil(t, stat("subdir/a", rc.Params{"dirsOnly": true}))
+	assert.Nil(t, stat("notfound", nil))
+	assert.Nil(t, stat("subdir/notfound", nil))
+
+	item = stat("", nil)
+	require.NotNil(t, item)
+	assert.Equal(t, true, item.IsDir)
+}
+
 // operations/mkdir: Make a destination directory or container
 func TestRcMkdir(t *testing.T) {
 	ctx := context.Background()
@@ -502,6 +553,49 @@ func TestUploadFile(t *testing.T) {
 
 }
 
+// operations/thumbnail: Make a thumbnail of an image file
+func TestRcThumbnail(t *testing.T) {
+	r, call := rcNewRun(t, "operations/thumbnail")
+	defer r.Finalise()
+	ctx := context.Background()
+
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xFF})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	r.WriteObject(ctx, "image.png", buf.String(), t1)
+	r.WriteObject(ctx, "text.txt", "not an image", t1)
+
+	in := rc.Params{
+		"fs":     r.FremoteName,
+		"remote": "image.png",
+		"size":   100,
+	}
+	out, err := call.Fn(ctx, in)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", out["mimeType"])
+	assert.Equal(t, 100, out["width"])
+	assert.Equal(t, 50, out["height"])
+	data, err := base64.StdEncoding.DecodeString(out["data"].(string))
+	require.NoError(t, err)
+	thumb, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), thumb.Bounds())
+
+	in["remote"] = "text.txt"
+	_, err = call.Fn(ctx, in)
+	assert.Equal(t, operations.ErrorThumbnailNotImage, err)
+
+	in["remote"] = "image.png"
+	in["size"] = operations.MaxThumbnailSize + 1
+	_, err = call.Fn(ctx, in)
+	assert.Error(t, err)
+}
+
 // operations/command: Runs a backend command
 func TestRcCommand(t *testing.T) {
 	r, call := rcNewRun(t, "backend/command")
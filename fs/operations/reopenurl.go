@@ -0,0 +1,176 @@
+package operations
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	gohash "hash"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/fserrors"
+	"github.com/pkg/errors"
+)
+
+// ReOpenURL is a wrapper for the body of a GET request for a URL which
+// resumes the download with a Range request if it fails part way
+// through.
+//
+// When the body has been read it checks that the data read matches
+// the Content-Length and the Content-MD5 if sent, so truncated or
+// corrupted downloads return an error rather than io.EOF.
+//
+// If the ETag looks like an MD5, as it does on S3 and many other
+// servers, then it is checked too, but as there is no guarantee it is
+// an MD5 a mismatch is only logged.
+type ReOpenURL struct {
+	ctx      context.Context
+	client   *http.Client
+	url      string        // URL to fetch after any redirects
+	body     io.ReadCloser // current response body
+	size     int64         // expected size or -1 if not known
+	ifRange  string        // validator for If-Range or "" if can't resume
+	md5      string        // expected MD5 or "" if not known
+	md5Known bool          // set if md5 is known to be an MD5 of the data
+	hasher   gohash.Hash   // hashing the data read if md5 is set
+	read     int64         // number of bytes read
+	maxTries int           // maximum number of retries
+	tries    int           // number of retries we've had so far
+	err      error         // if this is set then Read/Close calls will return it
+}
+
+// matchMD5ETag matches ETags which are an MD5 of the contents
+var matchMD5ETag = regexp.MustCompile(`^"?([0-9a-fA-F]{32})"?$`)
+
+// NewReOpenURL makes a ReOpenURL to read the body of resp which should
+// be the successful response to a GET request made with client.
+//
+// It will retry reading maxTries times.
+func NewReOpenURL(ctx context.Context, client *http.Client, resp *http.Response, maxTries int) *ReOpenURL {
+	h := &ReOpenURL{
+		ctx:      ctx,
+		client:   client,
+		url:      resp.Request.URL.String(),
+		body:     resp.Body,
+		size:     resp.ContentLength,
+		maxTries: maxTries,
+	}
+	if resp.Uncompressed {
+		// the length and ETag are of the compressed data
+		h.size = -1
+		return h
+	}
+	identity := resp.Header.Get("Content-Encoding") == ""
+	if contentMD5, err := base64.StdEncoding.DecodeString(resp.Header.Get("Content-MD5")); err == nil && len(contentMD5) == md5.Size && identity {
+		h.md5 = hex.EncodeToString(contentMD5)
+		h.md5Known = true
+	}
+	// Only strong ETags can be used with If-Range
+	etag := resp.Header.Get("ETag")
+	if etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.ifRange = etag
+		if match := matchMD5ETag.FindStringSubmatch(etag); match != nil && identity && h.md5 == "" {
+			h.md5 = strings.ToLower(match[1])
+		}
+	} else {
+		h.ifRange = resp.Header.Get("Last-Modified")
+	}
+	if resp.Header.Get("Accept-Ranges") == "none" {
+		h.ifRange = ""
+	}
+	if h.md5 != "" {
+		h.hasher = md5.New()
+	}
+	return h
+}
+
+// reopen the body at the read point
+func (h *ReOpenURL) reopen() error {
+	h.tries++
+	if h.tries > h.maxTries {
+		return errorTooManyTries
+	}
+	req, err := http.NewRequest("GET", h.url, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(h.ctx)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", h.read))
+	req.Header.Set("If-Range", h.ifRange)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// If the file has changed the server will send all of it
+	if resp.StatusCode != http.StatusPartialContent || !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", h.read)) {
+		_ = resp.Body.Close()
+		return errors.Errorf("server returned %s", resp.Status)
+	}
+	h.body = resp.Body
+	return nil
+}
+
+// check the data read is complete once the body returns io.EOF
+func (h *ReOpenURL) check() error {
+	if h.size >= 0 && h.read != h.size {
+		return errors.Errorf("download truncated: read %d bytes but Content-Length was %d", h.read, h.size)
+	}
+	if h.hasher != nil {
+		got := hex.EncodeToString(h.hasher.Sum(nil))
+		if got != h.md5 {
+			if h.md5Known {
+				return errors.Errorf("corrupted on transfer: md5 hash differ %q vs Content-MD5 %q", got, h.md5)
+			}
+			fs.Logf(h.url, "MD5 of the data %q differs from the ETag %q - ignoring as the ETag may not be an MD5", got, h.md5)
+		}
+	}
+	return io.EOF
+}
+
+// Read bytes retrying as necessary
+func (h *ReOpenURL) Read(p []byte) (n int, err error) {
+	if h.err != nil {
+		// return a previous error if there is one
+		return 0, h.err
+	}
+	n, err = h.body.Read(p)
+	h.read += int64(n)
+	if h.hasher != nil {
+		_, _ = h.hasher.Write(p[:n])
+	}
+	if h.size >= 0 && h.read > h.size {
+		err = errors.Errorf("download too long: read %d bytes but Content-Length was %d", h.read, h.size)
+	} else if err == io.EOF && h.size >= 0 && h.read < h.size {
+		err = io.ErrUnexpectedEOF
+	}
+	if err == io.EOF {
+		err = h.check()
+	} else if err != nil && h.ifRange != "" && !fserrors.IsNoLowLevelRetryError(err) {
+		_ = h.body.Close()
+		fs.Debugf(h.url, "Resuming download on read failure after %d bytes: retry %d/%d: %v", h.read, h.tries+1, h.maxTries, err)
+		reopenErr := h.reopen()
+		if reopenErr == nil {
+			err = nil
+		} else {
+			err = errors.Wrapf(err, "download failed after %d bytes and couldn't resume: %v", h.read, reopenErr)
+		}
+	}
+	if err != nil {
+		h.err = err
+	}
+	return n, err
+}
+
+// Close the stream
+func (h *ReOpenURL) Close() error {
+	if h.err == errorFileClosed {
+		return errorFileClosed
+	}
+	h.err = errorFileClosed
+	return h.body.Close()
+}
@@ -0,0 +1,144 @@
+package operations
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// check interface
+var _ io.ReadCloser = (*ReOpenURL)(nil)
+
+// reOpenURLServer serves contents, cutting off the first response
+// after cut bytes if cut >= 0
+type reOpenURLServer struct {
+	contents   []byte
+	etag       string
+	contentMD5 string
+	cut        int
+	ranges     bool // whether Range requests are supported
+	requests   int
+}
+
+func (s *reOpenURLServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests++
+	if s.contentMD5 != "" {
+		w.Header().Set("Content-MD5", s.contentMD5)
+	}
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	if !s.ranges {
+		w.Header().Set("Accept-Ranges", "none")
+	}
+	rangeHeader := r.Header.Get("Range")
+	if rangeHeader != "" && s.ranges && r.Header.Get("If-Range") == s.etag {
+		start, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(s.contents)-1, len(s.contents)))
+		w.Header().Set("Content-Length", strconv.Itoa(len(s.contents)-start))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(s.contents[start:])
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(s.contents)))
+	w.WriteHeader(http.StatusOK)
+	if s.requests == 1 && s.cut >= 0 {
+		// the server closes the connection as the body is short
+		_, _ = w.Write(s.contents[:s.cut])
+		return
+	}
+	_, _ = w.Write(s.contents)
+}
+
+func TestReOpenURL(t *testing.T) {
+	contents := []byte("0123456789")
+	sum := md5.Sum(contents)
+	goodETag := `"` + hex.EncodeToString(sum[:]) + `"`
+	badETag := `"00000000000000000000000000000000"`
+	goodContentMD5 := base64.StdEncoding.EncodeToString(sum[:])
+	badContentMD5 := base64.StdEncoding.EncodeToString(make([]byte, md5.Size))
+
+	read := func(s *reOpenURLServer, maxTries int) ([]byte, error) {
+		ts := httptest.NewServer(s)
+		defer ts.Close()
+		ctx := context.Background()
+		client := ts.Client()
+		resp, err := client.Get(ts.URL)
+		require.NoError(t, err)
+		in := NewReOpenURL(ctx, client, resp, maxTries)
+		data, err := ioutil.ReadAll(in)
+		assert.NoError(t, in.Close())
+		return data, err
+	}
+
+	t.Run("Basics", func(t *testing.T) {
+		data, err := read(&reOpenURLServer{contents: contents, cut: -1, ranges: true}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, contents, data)
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		s := &reOpenURLServer{contents: contents, etag: goodETag, cut: 4, ranges: true}
+		data, err := read(s, 10)
+		require.NoError(t, err)
+		assert.Equal(t, contents, data)
+		assert.Equal(t, 2, s.requests)
+	})
+
+	t.Run("ResumeTooManyTries", func(t *testing.T) {
+		s := &reOpenURLServer{contents: contents, etag: goodETag, cut: 4, ranges: true}
+		_, err := read(s, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "couldn't resume")
+	})
+
+	t.Run("NoRanges", func(t *testing.T) {
+		s := &reOpenURLServer{contents: contents, etag: goodETag, cut: 4}
+		_, err := read(s, 10)
+		require.Error(t, err)
+		assert.Equal(t, 1, s.requests)
+	})
+
+	t.Run("BadMD5ETag", func(t *testing.T) {
+		// ETags aren't always MD5s so this should only warn
+		data, err := read(&reOpenURLServer{contents: contents, etag: badETag, cut: -1, ranges: true}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, contents, data)
+	})
+
+	t.Run("BadContentMD5", func(t *testing.T) {
+		_, err := read(&reOpenURLServer{contents: contents, etag: goodETag, contentMD5: badContentMD5, cut: -1, ranges: true}, 10)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "md5 hash differ")
+	})
+
+	t.Run("GoodContentMD5", func(t *testing.T) {
+		s := &reOpenURLServer{contents: contents, etag: badETag, contentMD5: goodContentMD5, cut: 4, ranges: true}
+		data, err := read(s, 10)
+		require.NoError(t, err)
+		assert.Equal(t, contents, data)
+		assert.Equal(t, 2, s.requests)
+	})
+
+	t.Run("GoodMD5", func(t *testing.T) {
+		data, err := read(&reOpenURLServer{contents: contents, etag: goodETag, cut: -1, ranges: true}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, contents, data)
+	})
+}
@@ -0,0 +1,215 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/hash"
+	"github.com/artpar/rclone/fs/object"
+	"github.com/pkg/errors"
+)
+
+// maxSidecarSize is the most of a sidecar checksum file which is read
+const maxSidecarSize = 4096
+
+// sidecarSuffix returns the suffix of the checksum files written with
+// --sidecar-hash, e.g. ".sha256", or "" if it isn't set
+func sidecarSuffix(ctx context.Context) string {
+	ht := fs.GetConfig(ctx).SidecarHash
+	if ht == hash.None {
+		return ""
+	}
+	return "." + strings.ToLower(strings.Replace(ht.String(), "-", "", -1))
+}
+
+// IsSidecar returns true if remote is a checksum file written with
+// --sidecar-hash
+func IsSidecar(ctx context.Context, remote string) bool {
+	suffix := sidecarSuffix(ctx)
+	return suffix != "" && strings.HasSuffix(remote, suffix)
+}
+
+// IsManagedSidecar returns true if o in f is a checksum file written
+// with --sidecar-hash for a file which exists in f.
+//
+// Checksum files without the file they are for, or which just happen
+// to have the same suffix, aren't managed by rclone.
+func IsManagedSidecar(ctx context.Context, f fs.Fs, o fs.Object) bool {
+	if !IsSidecar(ctx, o.Remote()) {
+		return false
+	}
+	_, err := f.NewObject(ctx, strings.TrimSuffix(o.Remote(), sidecarSuffix(ctx)))
+	return err == nil
+}
+
+// formatSidecar returns the contents of the checksum file for remote
+// with checksum sum in the format used by the sha256sum family of
+// tools
+func formatSidecar(sum, remote string) string {
+	return fmt.Sprintf("%s  %s\n", sum, path.Base(remote))
+}
+
+// parseSidecar returns the checksum from the contents of a checksum
+// file
+func parseSidecar(data []byte) (sum string, err error) {
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", errors.New("checksum file is empty")
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// sidecarFs returns the Fs dst is in or nil if it isn't known
+func sidecarFs(dst fs.Object) fs.Fs {
+	f, _ := dst.Fs().(fs.Fs)
+	return f
+}
+
+// readSidecar returns the checksum stored in the checksum file for
+// dst or "" if there isn't one
+func readSidecar(ctx context.Context, dst fs.Object) (sum string, err error) {
+	f := sidecarFs(dst)
+	if f == nil {
+		return "", nil
+	}
+	o, err := f.NewObject(ctx, dst.Remote()+sidecarSuffix(ctx))
+	if err == fs.ErrorObjectNotFound || err == fs.ErrorNotAFile {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	in, err := o.Open(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer fs.CheckClose(in, &err)
+	data, err := ioutil.ReadAll(io.LimitReader(in, maxSidecarSize))
+	if err != nil {
+		return "", err
+	}
+	return parseSidecar(data)
+}
+
+// writeSidecar writes the checksum file for dst in f using the hash
+// of src.
+//
+// It does nothing if --sidecar-hash isn't set or src can't provide
+// the hash.
+func writeSidecar(ctx context.Context, f fs.Fs, src fs.ObjectInfo, dst fs.Object) error {
+	ht := fs.GetConfig(ctx).SidecarHash
+	if ht == hash.None || IsSidecar(ctx, dst.Remote()) {
+		return nil
+	}
+	sum, err := src.Hash(ctx, ht)
+	if err == hash.ErrUnsupported || (err == nil && sum == "") {
+		fs.Debugf(src, "Not writing checksum file as the source has no %v hash", ht)
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to read hash for checksum file")
+	}
+	contents := formatSidecar(sum, dst.Remote())
+	remote := dst.Remote() + sidecarSuffix(ctx)
+	info := object.NewStaticObjectInfo(remote, dst.ModTime(ctx), int64(len(contents)), true, nil, f)
+	// Update an existing checksum file rather than making a
+	// duplicate on backends which allow them
+	o, err := f.NewObject(ctx, remote)
+	if err == nil {
+		err = o.Update(ctx, strings.NewReader(contents), info)
+	} else if err == fs.ErrorObjectNotFound {
+		_, err = f.Put(ctx, strings.NewReader(contents), info)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write checksum file")
+	}
+	fs.Debugf(dst, "Wrote %v checksum file", ht)
+	return nil
+}
+
+// checkSidecar compares the hash of src with the one in the checksum
+// file for dst written with --sidecar-hash.
+//
+// It returns the same values as CheckHashes and returns hash.None if
+// --sidecar-hash isn't set or either hash is missing.
+func checkSidecar(ctx context.Context, src fs.ObjectInfo, dst fs.Object) (equal bool, ht hash.Type, err error) {
+	ht = fs.GetConfig(ctx).SidecarHash
+	if ht == hash.None || !src.Fs().Hashes().Contains(ht) || IsSidecar(ctx, dst.Remote()) {
+		return true, hash.None, nil
+	}
+	dstSum, err := readSidecar(ctx, dst)
+	if err != nil {
+		err = fs.CountError(err)
+		fs.Errorf(dst, "Failed to read checksum file: %v", err)
+		return false, ht, err
+	}
+	if dstSum == "" {
+		return true, hash.None, nil
+	}
+	srcSum, err := src.Hash(ctx, ht)
+	if err != nil {
+		err = fs.CountError(err)
+		fs.Errorf(src, "Failed to calculate src hash: %v", err)
+		return false, ht, err
+	}
+	if srcSum == "" {
+		return true, hash.None, nil
+	}
+	if srcSum != dstSum {
+		fs.Debugf(src, "%v = %s (%v)", ht, srcSum, src.Fs())
+		fs.Debugf(dst, "%v = %s (checksum file)", ht, dstSum)
+	} else {
+		fs.Debugf(src, "%v = %s OK (checksum file)", ht, srcSum)
+	}
+	return srcSum == dstSum, ht, nil
+}
+
+// RefreshSidecar writes the checksum file for dst from the hash of
+// src if --sidecar-hash is set and it is missing.
+//
+// This is used for files which didn't need transferring so checksum
+// files are added to existing files.
+func RefreshSidecar(ctx context.Context, f fs.Fs, src fs.Object, dst fs.Object) error {
+	if sidecarSuffix(ctx) == "" || IsSidecar(ctx, dst.Remote()) {
+		return nil
+	}
+	sum, err := readSidecar(ctx, dst)
+	if err != nil {
+		err = fs.CountError(err)
+		fs.Errorf(dst, "Failed to read checksum file: %v", err)
+		return err
+	}
+	if sum != "" || SkipDestructive(ctx, dst, "write checksum file") {
+		return nil
+	}
+	err = writeSidecar(ctx, f, src, dst)
+	if err != nil {
+		err = fs.CountError(err)
+		fs.Errorf(dst, "%v", err)
+	}
+	return err
+}
+
+// removeSidecar removes the checksum file for dst, moving it into
+// backupDir if set, logging any errors.
+func removeSidecar(ctx context.Context, dst fs.Object, backupDir fs.Fs) {
+	f := sidecarFs(dst)
+	if f == nil || sidecarSuffix(ctx) == "" || IsSidecar(ctx, dst.Remote()) {
+		return
+	}
+	o, err := f.NewObject(ctx, dst.Remote()+sidecarSuffix(ctx))
+	if err != nil {
+		return
+	}
+	if backupDir != nil {
+		err = MoveBackupDir(ctx, backupDir, o)
+	} else {
+		err = o.Remove(ctx)
+	}
+	if err != nil {
+		fs.Errorf(o, "Failed to remove checksum file: %v", err)
+	}
+}
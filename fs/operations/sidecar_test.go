@@ -0,0 +1,104 @@
+package operations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/hash"
+	"github.com/artpar/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSidecarName(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	assert.Equal(t, "", sidecarSuffix(ctx))
+	assert.False(t, IsSidecar(ctx, "file.txt.sha256"))
+
+	ci.SidecarHash = hash.SHA256
+	assert.Equal(t, ".sha256", sidecarSuffix(ctx))
+	assert.True(t, IsSidecar(ctx, "file.txt.sha256"))
+	assert.False(t, IsSidecar(ctx, "file.txt"))
+
+	ci.SidecarHash = hash.MD5
+	assert.Equal(t, ".md5", sidecarSuffix(ctx))
+}
+
+func TestParseSidecar(t *testing.T) {
+	contents := formatSidecar("ABC123", "dir/file.txt")
+	assert.Equal(t, "ABC123  file.txt\n", contents)
+	sum, err := parseSidecar([]byte(contents))
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", sum)
+
+	_, err = parseSidecar([]byte(" \n"))
+	assert.Error(t, err)
+}
+
+func TestSidecar(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if !r.Flocal.Hashes().Contains(hash.SHA256) {
+		t.Skip("Can't test checksum files without SHA-256 on the source")
+	}
+	ci.SidecarHash = hash.SHA256
+	t1 := fstest.Time("2001-02-03T04:05:06.499999999Z")
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	src, err := r.Flocal.NewObject(ctx, file1.Path)
+	require.NoError(t, err)
+	srcSum, err := src.Hash(ctx, hash.SHA256)
+	require.NoError(t, err)
+
+	// Copying writes the checksum file
+	dst, err := Copy(ctx, r.Fremote, nil, file1.Path, src)
+	require.NoError(t, err)
+	sidecar := fstest.NewItem("file1.sha256", formatSidecar(srcSum, "file1"), t1)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, sidecar}, nil, fs.ModTimeNotSupported)
+
+	equal, ht, err := checkSidecar(ctx, src, dst)
+	require.NoError(t, err)
+	assert.True(t, equal)
+	assert.Equal(t, hash.SHA256, ht)
+
+	// A corrupted file is detected
+	r.WriteObject(ctx, "file1.sha256", formatSidecar("0000", "file1"), t1)
+	equal, ht, err = checkSidecar(ctx, src, dst)
+	require.NoError(t, err)
+	assert.False(t, equal)
+	assert.Equal(t, hash.SHA256, ht)
+
+	// Writing the checksum file again updates the existing one
+	require.NoError(t, writeSidecar(ctx, r.Fremote, src, dst))
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, sidecar}, nil, fs.ModTimeNotSupported)
+
+	// Only checksum files for existing files are managed by rclone
+	o, err := r.Fremote.NewObject(ctx, "file1.sha256")
+	require.NoError(t, err)
+	assert.True(t, IsManagedSidecar(ctx, r.Fremote, o))
+	assert.False(t, IsManagedSidecar(ctx, r.Fremote, dst))
+	orphan := r.WriteObject(ctx, "orphan.sha256", formatSidecar(srcSum, "orphan"), t1)
+	o, err = r.Fremote.NewObject(ctx, orphan.Path)
+	require.NoError(t, err)
+	assert.False(t, IsManagedSidecar(ctx, r.Fremote, o))
+	require.NoError(t, o.Remove(ctx))
+
+	// A missing checksum file is written by RefreshSidecar
+	o, err = r.Fremote.NewObject(ctx, "file1.sha256")
+	require.NoError(t, err)
+	require.NoError(t, o.Remove(ctx))
+	equal, ht, err = checkSidecar(ctx, src, dst)
+	require.NoError(t, err)
+	assert.True(t, equal)
+	assert.Equal(t, hash.None, ht)
+	require.NoError(t, RefreshSidecar(ctx, r.Fremote, src, dst))
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, sidecar}, nil, fs.ModTimeNotSupported)
+
+	// Deleting the file removes its checksum file
+	require.NoError(t, DeleteFile(ctx, dst))
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{}, nil, fs.ModTimeNotSupported)
+}
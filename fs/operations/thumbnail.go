@@ -0,0 +1,225 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/lib/cache"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultThumbnailSize is the default maximum width or height
+	// of a thumbnail in pixels
+	DefaultThumbnailSize = 128
+	// MaxThumbnailSize is the largest thumbnail which can be made
+	MaxThumbnailSize = 1024
+	// maxThumbnailSourceSize is the largest object we will read
+	// to make a thumbnail from
+	maxThumbnailSourceSize = 64 * 1024 * 1024
+	// maxThumbnailSourcePixels is the largest number of pixels in
+	// an image we will decode. This stops small compressed images
+	// with huge dimensions using lots of memory.
+	maxThumbnailSourcePixels = 64 * 1024 * 1024
+)
+
+// Thumbnails are cached until they haven't been used for a while
+var thumbnailCache = cache.New()
+
+// ErrorThumbnailNotImage is returned if a thumbnail is requested
+// for an object which isn't in a supported image format
+var ErrorThumbnailNotImage = errors.New("can't make thumbnail: not a JPEG, PNG or GIF image")
+
+// Thumbnail is a scaled down copy of an image
+type Thumbnail struct {
+	MimeType string // mime type of Data
+	Width    int    // width in pixels
+	Height   int    // height in pixels
+	Data     []byte // encoded image
+}
+
+// MakeThumbnail reads the image in o and returns a copy scaled so
+// that neither its width nor its height is greater than size pixels.
+//
+// PNG images are returned as PNG to preserve transparency, all other
+// formats are returned as JPEG.
+//
+// The result is cached keyed on the object's path, size and
+// modification time so repeated requests are cheap.
+func MakeThumbnail(ctx context.Context, o fs.Object, size int) (*Thumbnail, error) {
+	if size <= 0 {
+		size = DefaultThumbnailSize
+	}
+	if size > MaxThumbnailSize {
+		return nil, errors.Errorf("thumbnail size %d is larger than the maximum %d", size, MaxThumbnailSize)
+	}
+	if o.Size() > maxThumbnailSourceSize {
+		return nil, errors.Errorf("can't make thumbnail: image is larger than %v", fs.SizeSuffix(maxThumbnailSourceSize))
+	}
+	f := o.Fs()
+	key := fmt.Sprintf("%s:%s/%s\x00%d\x00%d\x00%d", f.Name(), f.Root(), o.Remote(), o.Size(), o.ModTime(ctx).UnixNano(), size)
+	value, err := thumbnailCache.Get(key, func(key string) (interface{}, bool, error) {
+		thumb, err := makeThumbnail(ctx, o, size)
+		return thumb, err == nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Thumbnail), nil
+}
+
+// makeThumbnail does the work for MakeThumbnail without the cache
+func makeThumbnail(ctx context.Context, o fs.Object, size int) (thumb *Thumbnail, err error) {
+	in, err := o.Open(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open image")
+	}
+	defer fs.CheckClose(in, &err)
+	data, err := ioutil.ReadAll(io.LimitReader(in, maxThumbnailSourceSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read image")
+	}
+	if len(data) > maxThumbnailSourceSize {
+		return nil, errors.Errorf("can't make thumbnail: image is larger than %v", fs.SizeSuffix(maxThumbnailSourceSize))
+	}
+	// Check the dimensions before decoding the pixels
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err == image.ErrFormat {
+		return nil, ErrorThumbnailNotImage
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to decode image")
+	}
+	if int64(config.Width)*int64(config.Height) > maxThumbnailSourcePixels {
+		return nil, errors.Errorf("can't make thumbnail: image is %dx%d which is more than %d pixels", config.Width, config.Height, maxThumbnailSourcePixels)
+	}
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode image")
+	}
+	dst := scaleImage(src, size)
+	var buf bytes.Buffer
+	thumb = &Thumbnail{
+		Width:  dst.Bounds().Dx(),
+		Height: dst.Bounds().Dy(),
+	}
+	if format == "png" {
+		thumb.MimeType = "image/png"
+		err = png.Encode(&buf, dst)
+	} else {
+		thumb.MimeType = "image/jpeg"
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode thumbnail")
+	}
+	thumb.Data = buf.Bytes()
+	return thumb, nil
+}
+
+// scaleImage returns src scaled down so that neither dimension is
+// greater than size, keeping the aspect ratio.
+//
+// Each destination pixel is the average of the source pixels it
+// covers which gives good results when shrinking.
+func scaleImage(src image.Image, size int) image.Image {
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	if sw <= size && sh <= size {
+		return src
+	}
+	dw, dh := size, size
+	if sw > sh {
+		dh = (sh*size + sw/2) / sw
+	} else {
+		dw = (sw*size + sh/2) / sh
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+	pixel := pixelReader(src)
+	dst := image.NewRGBA64(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0 := sb.Min.Y + dy*sh/dh
+		y1 := sb.Min.Y + (dy+1)*sh/dh
+		for dx := 0; dx < dw; dx++ {
+			x0 := sb.Min.X + dx*sw/dw
+			x1 := sb.Min.X + (dx+1)*sw/dw
+			var r, g, b, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					cr, cg, cb, ca := pixel(x, y)
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.SetRGBA64(dx, dy, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// pixelReader returns a function to read the alpha premultiplied 16
+// bit color of the pixel at x, y in src.
+//
+// This reads the pixels of the image types the decoders produce
+// without going through At which allocates a color.Color for each
+// pixel.
+func pixelReader(src image.Image) func(x, y int) (r, g, b, a uint32) {
+	switch img := src.(type) {
+	case *image.RGBA:
+		return func(x, y int) (r, g, b, a uint32) {
+			return img.RGBAAt(x, y).RGBA()
+		}
+	case *image.NRGBA:
+		return func(x, y int) (r, g, b, a uint32) {
+			return img.NRGBAAt(x, y).RGBA()
+		}
+	case *image.Gray:
+		return func(x, y int) (r, g, b, a uint32) {
+			return img.GrayAt(x, y).RGBA()
+		}
+	case *image.YCbCr:
+		return func(x, y int) (r, g, b, a uint32) {
+			return img.YCbCrAt(x, y).RGBA()
+		}
+	case *image.Paletted:
+		// Convert the palette once rather than for every pixel
+		palette := make([]color.RGBA64, 256)
+		for i, c := range img.Palette {
+			if i >= len(palette) {
+				break
+			}
+			r, g, b, a := c.RGBA()
+			palette[i] = color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: uint16(a)}
+		}
+		return func(x, y int) (r, g, b, a uint32) {
+			return palette[img.ColorIndexAt(x, y)].RGBA()
+		}
+	}
+	return func(x, y int) (r, g, b, a uint32) {
+		return src.At(x, y).RGBA()
+	}
+}
@@ -0,0 +1,80 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/png"
+	"testing"
+
+	"github.com/artpar/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPixelReader(t *testing.T) {
+	rect := image.Rect(1, 2, 9, 7)
+	for _, img := range []image.Image{
+		image.NewRGBA(rect),
+		image.NewNRGBA(rect),
+		image.NewGray(rect),
+		image.NewYCbCr(rect, image.YCbCrSubsampleRatio420),
+		image.NewPaletted(rect, palette.Plan9),
+		image.NewRGBA64(rect),
+	} {
+		// Fill the image with a pattern
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				c := color.NRGBA{R: uint8(x * 30), G: uint8(y * 40), B: uint8(x * y), A: uint8(255 - x*20)}
+				switch img := img.(type) {
+				case *image.YCbCr:
+					yy, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+					img.Y[img.YOffset(x, y)] = yy
+					img.Cb[img.COffset(x, y)] = cb
+					img.Cr[img.COffset(x, y)] = cr
+				case interface{ Set(x, y int, c color.Color) }:
+					img.Set(x, y, c)
+				}
+			}
+		}
+		pixel := pixelReader(img)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				wr, wg, wb, wa := img.At(x, y).RGBA()
+				r, g, b, a := pixel(x, y)
+				assert.Equal(t, [4]uint32{wr, wg, wb, wa}, [4]uint32{r, g, b, a}, "%T at %d,%d", img, x, y)
+			}
+		}
+	}
+}
+
+// makePNGHeader makes a PNG which claims to be width x height
+func makePNGHeader(t *testing.T, width, height uint32) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	data := buf.Bytes()
+	// The IHDR chunk follows the 8 byte signature - its length
+	// and type are followed by the width and height then the CRC
+	// of the type and data
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestMakeThumbnailTooManyPixels(t *testing.T) {
+	ctx := context.Background()
+	data := makePNGHeader(t, 100000, 100000)
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 100000, config.Width)
+
+	o := mockobject.New("bomb.png").WithContent(data, mockobject.SeekModeNone)
+	_, err = makeThumbnail(ctx, o, DefaultThumbnailSize)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than")
+}
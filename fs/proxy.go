@@ -0,0 +1,60 @@
+package fs
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ConfigProxy is the config key for the per remote proxy
+const ConfigProxy = "proxy"
+
+// ProxyOption is the option for the per remote proxy. Backends which
+// make their connections with fshttp should add it to their options.
+var ProxyOption = Option{
+	Name: ConfigProxy,
+	Help: `Proxy to use for the HTTP connections of this remote
+
+This should be a URL such as http://proxy:3128 or socks5://proxy:1080
+and overrides --socks5-proxy and the HTTP_PROXY environment variables
+for this remote.`,
+	Advanced: true,
+}
+
+// ParseProxy parses a proxy URL as used in the per remote "proxy"
+// config option.
+//
+// The scheme should be one of http, https or socks5. If it is missing
+// then http is assumed, as with the HTTP_PROXY environment variable.
+//
+// socks5h isn't supported as the Go versions rclone can be built with
+// don't support it.
+func ParseProxy(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proxy %q", proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, errors.Errorf("invalid proxy %q: scheme must be http, https or socks5", proxy)
+	}
+	if u.Host == "" {
+		return nil, errors.Errorf("invalid proxy %q: no host", proxy)
+	}
+	return u, nil
+}
+
+// ParseSocks5Proxy parses the argument to --socks5-proxy which should
+// be host:port optionally preceded by user:pass@ and returns it as a
+// proxy URL.
+func ParseSocks5Proxy(proxy string) (*url.URL, error) {
+	if strings.Contains(proxy, "://") {
+		return nil, errors.Errorf("invalid SOCKS5 proxy %q: should be [user:pass@]host:port", proxy)
+	}
+	return ParseProxy("socks5://" + proxy)
+}
@@ -239,6 +239,49 @@ func rcObscure(ctx context.Context, in Params) (out Params, err error) {
 	return out, nil
 }
 
+func init() {
+	Add(Call{
+		Path:  "core/transfers",
+		Fn:    rcTransfers,
+		Title: "Set the number of file transfers to run in parallel.",
+		Help: `
+This sets the global --transfers value, the number of file transfers
+which are run in parallel, without restarting rclone. It returns the
+current value if no parameters are passed in.
+
+- transfers - int - number of transfers to set
+
+Returns
+- transfers - int - the number of transfers in use
+
+Operations which are already running carry on with the number of
+transfers they started with. The new value is used by operations
+started afterwards.
+
+    rclone rc core/transfers transfers=8
+`,
+	})
+}
+
+// Read and set the number of transfers
+func rcTransfers(ctx context.Context, in Params) (out Params, err error) {
+	ci := fs.GetConfig(context.Background())
+	transfers, err := in.GetInt64("transfers")
+	if err == nil {
+		if transfers < 1 {
+			return nil, errors.Errorf("transfers must be at least 1, got %d", transfers)
+		}
+		ci.Transfers = int(transfers)
+		fs.Logf(nil, "Transfers set to %d", ci.Transfers)
+	} else if !IsErrParamNotFound(err) {
+		return nil, err
+	}
+	out = Params{
+		"transfers": ci.Transfers,
+	}
+	return out, nil
+}
+
 func init() {
 	Add(Call{
 		Path:  "core/quit",
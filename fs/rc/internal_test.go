@@ -128,6 +128,34 @@ func TestCoreObscure(t *testing.T) {
 	assert.Equal(t, in["clear"], obscure.MustReveal(out["obscured"].(string)))
 }
 
+func TestCoreTransfers(t *testing.T) {
+	call := Calls.Get("core/transfers")
+	assert.NotNil(t, call)
+	ci := fs.GetConfig(context.Background())
+	oldTransfers := ci.Transfers
+	defer func() {
+		ci.Transfers = oldTransfers
+	}()
+
+	// Query
+	out, err := call.Fn(context.Background(), Params{})
+	require.NoError(t, err)
+	assert.Equal(t, Params{"transfers": oldTransfers}, out)
+
+	// Set
+	out, err = call.Fn(context.Background(), Params{"transfers": 17})
+	require.NoError(t, err)
+	assert.Equal(t, Params{"transfers": 17}, out)
+	assert.Equal(t, 17, ci.Transfers)
+
+	// Errors
+	_, err = call.Fn(context.Background(), Params{"transfers": 0})
+	assert.Error(t, err)
+	_, err = call.Fn(context.Background(), Params{"transfers": "potato"})
+	assert.Error(t, err)
+	assert.Equal(t, 17, ci.Transfers)
+}
+
 func TestCoreQuit(t *testing.T) {
 	//The call should return an error if param exitCode is not parsed to int
 	call := Calls.Get("core/quit")
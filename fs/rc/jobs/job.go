@@ -5,6 +5,7 @@ package jobs
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"runtime/debug"
 	"sync"
 	"sync/atomic"
@@ -208,12 +209,19 @@ func getConfig(ctx context.Context, in rc.Params) (context.Context, error) {
 	if _, ok := in["_config"]; !ok {
 		return ctx, nil
 	}
+	parentBwLimit := fs.GetConfig(ctx).BwLimit
 	ctx, ci := fs.AddConfig(ctx)
 	err := in.GetStruct("_config", ci)
 	if err != nil {
 		return ctx, err
 	}
 	delete(in, "_config") // remove the parameter
+	// The global --bwlimit can't be changed per call so give the
+	// job its own limit if BwLimit was set
+	if !reflect.DeepEqual(ci.BwLimit, parentBwLimit) {
+		bw := ci.BwLimit.LimitAt(time.Now())
+		ctx = accounting.WithBwLimit(ctx, bw.Bandwidth)
+	}
 	return ctx, nil
 }
 
@@ -1,7 +1,10 @@
 package jobs
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"io/ioutil"
 	"runtime"
 	"testing"
 	"time"
@@ -281,6 +284,36 @@ func TestExecuteJobWithConfig(t *testing.T) {
 	assert.NotEqual(t, 42*fs.MebiByte, ci.BufferSize)
 }
 
+func TestExecuteJobWithBwLimit(t *testing.T) {
+	ctx := context.Background()
+	jobID = 0
+	const bwLimit = 16 * fs.MebiByte
+	var elapsed time.Duration
+	jobFn := func(ctx context.Context, in rc.Params) (rc.Params, error) {
+		ci := fs.GetConfig(ctx)
+		assert.Equal(t, bwLimit, ci.BwLimit.LimitAt(time.Now()).Bandwidth.Tx)
+		// Read through an account which should be limited
+		data := make([]byte, bwLimit/2)
+		tr := accounting.Stats(ctx).NewTransferRemoteSize("test", int64(len(data)))
+		defer tr.Done(ctx, nil)
+		acc := tr.Account(ctx, ioutil.NopCloser(bytes.NewReader(data)))
+		start := time.Now()
+		_, err := io.Copy(ioutil.Discard, acc)
+		elapsed = time.Since(start)
+		return nil, err
+	}
+	_, _, err := NewJob(ctx, jobFn, rc.Params{
+		"_config": rc.Params{
+			"BwLimit": "16M",
+		},
+	})
+	require.NoError(t, err)
+	// 8M at 16M/s should take about 500ms
+	assert.True(t, elapsed > 350*time.Millisecond, "too quick: %v", elapsed)
+	// Check that wasn't the default
+	assert.Equal(t, 0, len(fs.GetConfig(ctx).BwLimit))
+}
+
 func TestExecuteJobWithFilter(t *testing.T) {
 	ctx := context.Background()
 	called := false
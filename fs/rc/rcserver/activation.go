@@ -0,0 +1,44 @@
+package rcserver
+
+import (
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// listenFdsStart is the first file descriptor systemd passes sockets
+// on - a variable so it can be changed in the tests
+var listenFdsStart = 3
+
+// activationListener returns the socket passed in by systemd socket
+// activation, or nil if rclone wasn't started that way.
+//
+// See sd_listen_fds(3) for the protocol.
+func activationListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	nfds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || nfds < 1 {
+		return nil, nil
+	}
+	// Don't pass the sockets on to any child processes
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+	if nfds > 1 {
+		fs.Logf(nil, "Socket activation passed %d sockets - only using the first", nfds)
+	}
+	f := os.NewFile(uintptr(listenFdsStart), "LISTEN_FD_"+strconv.Itoa(listenFdsStart))
+	ln, err := net.FileListener(f)
+	// FileListener makes its own copy of the file descriptor
+	_ = f.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to use socket passed by systemd socket activation")
+	}
+	return ln, nil
+}
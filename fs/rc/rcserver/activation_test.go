@@ -0,0 +1,64 @@
+package rcserver
+
+import (
+	"net"
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivationListenerNotActivated(t *testing.T) {
+	for _, env := range [][2]string{
+		{"", ""},
+		{strconv.Itoa(os.Getpid() + 1), "1"},
+		{strconv.Itoa(os.Getpid()), "0"},
+		{strconv.Itoa(os.Getpid()), "potato"},
+	} {
+		require.NoError(t, os.Setenv("LISTEN_PID", env[0]))
+		require.NoError(t, os.Setenv("LISTEN_FDS", env[1]))
+		ln, err := activationListener()
+		assert.NoError(t, err)
+		assert.Nil(t, ln)
+	}
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+}
+
+func TestActivationListener(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket activation not supported on Windows")
+	}
+	orig, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = orig.Close()
+	}()
+	f, err := orig.(*net.TCPListener).File()
+	require.NoError(t, err)
+	oldListenFdsStart := listenFdsStart
+	listenFdsStart = int(f.Fd())
+	defer func() {
+		listenFdsStart = oldListenFdsStart
+	}()
+
+	require.NoError(t, os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid())))
+	require.NoError(t, os.Setenv("LISTEN_FDS", "1"))
+	ln, err := activationListener()
+	require.NoError(t, err)
+	require.NotNil(t, ln)
+	defer func() {
+		_ = ln.Close()
+	}()
+	assert.Equal(t, orig.Addr().String(), ln.Addr().String())
+
+	// The environment is cleared so it is only used once
+	assert.Equal(t, "", os.Getenv("LISTEN_PID"))
+	assert.Equal(t, "", os.Getenv("LISTEN_FDS"))
+	ln2, err := activationListener()
+	assert.NoError(t, err)
+	assert.Nil(t, ln2)
+}
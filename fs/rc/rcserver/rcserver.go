@@ -41,6 +41,12 @@ import (
 var promHandler http.Handler
 var onlyOnceWarningAllowOrigin sync.Once
 
+// The running server, if any, for rc/auth
+var (
+	runningMu sync.Mutex
+	running   *Server
+)
+
 func init() {
 	rcloneCollector := accounting.NewRcloneCollector(context.Background())
 	prometheus.MustRegister(rcloneCollector)
@@ -55,8 +61,75 @@ func Start(ctx context.Context, opt *rc.Options) (*Server, error) {
 	if opt.Enabled {
 		// Serve on the DefaultServeMux so can have global registrations appear
 		s := newServer(ctx, opt, http.DefaultServeMux)
-		return s, s.Serve()
+		ln, err := activationListener()
+		if err != nil {
+			return nil, err
+		}
+		if ln != nil {
+			fs.Infof(nil, "Using socket passed by systemd socket activation")
+			s.Server.UseListener(ln)
+		}
+		err = s.Serve()
+		if err == nil {
+			setRunning(s)
+		}
+		return s, err
+	}
+	return nil, nil
+}
+
+// setRunning sets the server rc/auth changes the credentials of
+func setRunning(s *Server) {
+	runningMu.Lock()
+	running = s
+	runningMu.Unlock()
+}
+
+func init() {
+	rc.Add(rc.Call{
+		Path:         "rc/auth",
+		AuthRequired: true,
+		Fn:           rcAuth,
+		Title:        "Change the user and password of the remote control server.",
+		Help: `
+This changes the user and password needed to access the remote
+control server without restarting it. Requests which are already
+authenticated carry on, but subsequent requests must use the new
+credentials.
+
+- user - string - the new user name
+- pass - string - the new password
+
+This only works if the server was started with --rc-user and
+--rc-pass. If --rc-htpasswd was used then edit the htpasswd file
+instead - it is re-read when it changes.
+
+    rclone rc rc/auth user=admin pass=secret
+`,
+	})
+}
+
+// Change the credentials of the running server
+func rcAuth(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	user, err := in.GetString("user")
+	if err != nil {
+		return nil, err
+	}
+	pass, err := in.GetString("pass")
+	if err != nil {
+		return nil, err
+	}
+	runningMu.Lock()
+	s := running
+	runningMu.Unlock()
+	if s == nil {
+		return nil, errors.New("remote control server isn't running")
+	}
+	err = s.Server.SetBasicAuth(user, pass)
+	if err != nil {
+		return nil, err
 	}
+	s.opt.HTTPOptions.BasicUser, s.opt.HTTPOptions.BasicPass = user, pass
 	return nil, nil
 }
 
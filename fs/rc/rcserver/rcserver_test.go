@@ -781,3 +781,55 @@ func TestRCAsync(t *testing.T) {
 	opt.Files = ""
 	testServer(t, tests, &opt)
 }
+
+func TestRCAuth(t *testing.T) {
+	call := rc.Calls.Get("rc/auth")
+	require.NotNil(t, call)
+	assert.True(t, call.AuthRequired)
+
+	// Not running
+	setRunning(nil)
+	_, err := call.Fn(context.Background(), rc.Params{"user": "new", "pass": "secret"})
+	assert.EqualError(t, err, "remote control server isn't running")
+
+	opt := newTestOpt()
+	opt.HTTPOptions.ListenAddr = testBindAddress
+	opt.HTTPOptions.BasicUser = "user"
+	opt.HTTPOptions.BasicPass = "pass"
+	mux := http.NewServeMux()
+	rcServer := newServer(context.Background(), &opt, mux)
+	require.NoError(t, rcServer.Serve())
+	setRunning(rcServer)
+	defer func() {
+		setRunning(nil)
+		rcServer.Close()
+		rcServer.Wait()
+	}()
+	testURL := rcServer.Server.URL() + "rc/noopauth"
+
+	status := func(user, pass string) int {
+		req, err := http.NewRequest("POST", testURL, nil)
+		require.NoError(t, err)
+		req.SetBasicAuth(user, pass)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, status("user", "pass"))
+
+	// Missing and empty parameters
+	_, err = call.Fn(context.Background(), rc.Params{"user": "new"})
+	assert.Error(t, err)
+	_, err = call.Fn(context.Background(), rc.Params{"user": "new", "pass": ""})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusOK, status("user", "pass"))
+
+	// Change the credentials
+	_, err = call.Fn(context.Background(), rc.Params{"user": "new", "pass": "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status("user", "pass"))
+	assert.Equal(t, http.StatusOK, status("new", "secret"))
+	assert.Equal(t, "new", opt.HTTPOptions.BasicUser)
+	assert.Equal(t, "secret", opt.HTTPOptions.BasicPass)
+}
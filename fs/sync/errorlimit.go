@@ -0,0 +1,80 @@
+package sync
+
+import (
+	"sync"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/fserrors"
+	"github.com/pkg/errors"
+)
+
+// errorLimit aborts a sync if there are too many errors as set by
+// --max-errors or too high a proportion of errors as set by
+// --max-error-rate
+type errorLimit struct {
+	maxErrors int64   // abort if there are more errors than this, -1 for off
+	maxRate   float64 // abort if the error rate in the window is more than this, 0 for off
+
+	mu      sync.Mutex
+	errors  int64  // total errors seen
+	window  []bool // ring buffer of the most recent results, true for failed
+	next    int    // next slot in window to write
+	full    bool   // set if the window has been filled
+	failed  int    // number of failures in window
+	tripped bool   // set once the limit has been reached
+}
+
+// newErrorLimit makes an errorLimit from the config
+func newErrorLimit(ci *fs.ConfigInfo) *errorLimit {
+	l := &errorLimit{
+		maxErrors: ci.MaxErrors,
+		maxRate:   ci.MaxErrorRate,
+	}
+	if l.maxRate > 0 {
+		window := ci.MaxErrorWindow
+		if window < 1 {
+			window = 1
+		}
+		l.window = make([]bool, window)
+	}
+	return l
+}
+
+// add records the result of an operation, returning a fatal error
+// the first time a limit is exceeded.
+func (l *errorLimit) add(failed bool) error {
+	if l.maxErrors < 0 && l.window == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.tripped {
+		return nil
+	}
+	if failed {
+		l.errors++
+	}
+	if l.window != nil {
+		if l.window[l.next] {
+			l.failed--
+		}
+		l.window[l.next] = failed
+		if failed {
+			l.failed++
+		}
+		l.next++
+		if l.next >= len(l.window) {
+			l.next = 0
+			l.full = true
+		}
+	}
+	switch {
+	case l.maxErrors >= 0 && l.errors > l.maxErrors:
+		l.tripped = true
+		return fserrors.FatalError(errors.Errorf("too many errors: %d errors is more than --max-errors %d", l.errors, l.maxErrors))
+	case l.full && float64(l.failed)/float64(len(l.window)) > l.maxRate:
+		l.tripped = true
+		return fserrors.FatalError(errors.Errorf("error rate too high: %d of the last %d operations failed which is more than --max-error-rate %g", l.failed, len(l.window), l.maxRate))
+	}
+	return nil
+}
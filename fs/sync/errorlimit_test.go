@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/fserrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorLimitOff(t *testing.T) {
+	l := newErrorLimit(fs.GetConfig(context.Background()))
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, l.add(true))
+	}
+}
+
+func TestErrorLimitMaxErrors(t *testing.T) {
+	_, ci := fs.AddConfig(context.Background())
+	ci.MaxErrors = 3
+	l := newErrorLimit(ci)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, l.add(false))
+	}
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, l.add(true))
+	}
+	err := l.add(true)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+	assert.Contains(t, err.Error(), "--max-errors 3")
+
+	// only returns the error once
+	assert.NoError(t, l.add(true))
+}
+
+func TestErrorLimitMaxErrorsZero(t *testing.T) {
+	_, ci := fs.AddConfig(context.Background())
+	ci.MaxErrors = 0
+	l := newErrorLimit(ci)
+	assert.NoError(t, l.add(false))
+	assert.Error(t, l.add(true))
+}
+
+func TestErrorLimitMaxErrorRate(t *testing.T) {
+	_, ci := fs.AddConfig(context.Background())
+	ci.MaxErrorRate = 0.5
+	ci.MaxErrorWindow = 4
+	l := newErrorLimit(ci)
+
+	// doesn't trigger until the window is full
+	assert.NoError(t, l.add(true))
+	assert.NoError(t, l.add(true))
+	assert.NoError(t, l.add(true))
+
+	// 3 out of 4 failed
+	err := l.add(false)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+	assert.Contains(t, err.Error(), "3 of the last 4")
+
+	// a rate at the limit is OK and old results drop out of the window
+	l = newErrorLimit(ci)
+	for _, failed := range []bool{true, false, true, false, false, false, true, true, false, false} {
+		assert.NoError(t, l.add(failed))
+	}
+	assert.Equal(t, 2, l.failed)
+	assert.NoError(t, l.add(true))
+	assert.NoError(t, l.add(true))
+	err = l.add(true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3 of the last 4")
+}
@@ -12,9 +12,12 @@ import (
 
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/accounting"
+	"github.com/artpar/rclone/fs/cache"
 	"github.com/artpar/rclone/fs/filter"
 	"github.com/artpar/rclone/fs/fserrors"
+	"github.com/artpar/rclone/fs/fspath"
 	"github.com/artpar/rclone/fs/hash"
+	"github.com/artpar/rclone/fs/list"
 	"github.com/artpar/rclone/fs/march"
 	"github.com/artpar/rclone/fs/operations"
 	"github.com/pkg/errors"
@@ -45,6 +48,9 @@ type syncCopyMove struct {
 	trackRenamesStrategy   trackRenamesStrategy   // strategies used for tracking renames
 	dstFilesMu             sync.Mutex             // protect dstFiles
 	dstFiles               map[string]fs.Object   // dst files, always filled
+	dstTotalMu             sync.Mutex             // protect dstTotalCount and dstTotalSize
+	dstTotalCount          int64                  // number of dst files seen for --max-delete percentages
+	dstTotalSize           int64                  // size of dst files seen for --max-delete-size
 	srcFiles               map[string]fs.Object   // src files, only used if deleteBefore
 	srcFilesChan           chan fs.Object         // passes src objects
 	srcFilesResult         chan error             // error result of src listing
@@ -61,6 +67,7 @@ type syncCopyMove struct {
 	err                    error                  // normal error from copy process
 	noRetryErr             error                  // error with NoRetry set
 	fatalErr               error                  // fatal error
+	errorLimit             *errorLimit            // abort if too many errors
 	commonHash             hash.Type              // common hash type between src and dst
 	modifyWindow           time.Duration          // modify window between fsrc, fdst
 	renameMapMu            sync.Mutex             // mutex to protect the below
@@ -73,6 +80,37 @@ type syncCopyMove struct {
 	compareCopyDest        []fs.Fs                // place to check for files to server side copy
 	backupDir              fs.Fs                  // place to store overwrites/deletes
 	checkFirst             bool                   // if set run all the checkers before starting transfers
+	hardLinks              bool                   // set if we should preserve hard links
+	hardLinkMu             sync.Mutex             // mutex to protect the below
+	hardLinkMap            map[string]*hardLink   // first dst made for each src hard link ID
+	preMkdir               bool                   // set if we should make dst directories before the transfers
+	mkdirMu                sync.Mutex             // mutex to protect the below
+	mkdirMap               map[string]*mkdirJob   // src only dirs, nil if not started
+	mkdirWg                sync.WaitGroup         // wait for directory makers
+	mkdirCh                chan string            // directories to make are pumped in here
+	setDirModTime          bool                   // set if we should set the modtime of dst directories
+	dirModTimeMu           sync.Mutex             // protect dirModTimes and dirsModified
+	dirModTimes            map[string]dirModTime  // src directories to set the dst modtime from
+	dirsModified           map[string]struct{}    // dst directories which may have been written to
+}
+
+// dirModTime is a src directory whose modification time should be set
+// on the dst directory
+type dirModTime struct {
+	src        fs.DirEntry // the src directory
+	dstModTime time.Time   // modtime of the existing dst directory or zero if unknown
+}
+
+// mkdirJob tracks the making of a directory on the destination
+type mkdirJob struct {
+	done chan struct{} // closed when the directory has been made
+}
+
+// hardLink holds the destination object made for the first name of a
+// hard linked source file so that the other names can link to it
+type hardLink struct {
+	done chan struct{} // closed when dst is ready
+	dst  fs.Object     // the first dst object or nil if the copy failed
 }
 
 type trackRenamesStrategy byte
@@ -125,6 +163,14 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 		modifyWindow:           fs.GetModifyWindow(ctx, fsrc, fdst),
 		trackRenamesCh:         make(chan fs.Object, ci.Checkers),
 		checkFirst:             ci.CheckFirst,
+		hardLinks:              ci.HardLinks,
+		hardLinkMap:            make(map[string]*hardLink),
+		mkdirMap:               make(map[string]*mkdirJob),
+		mkdirCh:                make(chan string, ci.Checkers),
+		errorLimit:             newErrorLimit(ci),
+		setDirModTime:          fdst.Features().DirSetModTime != nil && !ci.NoUpdateDirModTime && deleteMode != fs.DeleteModeOnly,
+		dirModTimes:            make(map[string]dirModTime),
+		dirsModified:           make(map[string]struct{}),
 	}
 	backlog := ci.MaxBacklog
 	if s.checkFirst {
@@ -144,6 +190,8 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 	if err != nil {
 		return nil, err
 	}
+	// The --max-delete thresholds are checked by checkMaxDelete
+	ctx = operations.WithMaxDeleteChecked(ctx)
 	// If a max session duration has been defined add a deadline to the context
 	if ci.MaxDuration > 0 {
 		endTime := time.Now().Add(ci.MaxDuration)
@@ -196,6 +244,22 @@ func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 			s.trackRenames = false
 		}
 	}
+	if s.hardLinks {
+		if fdst.Features().HardLink == nil {
+			fs.Errorf(fdst, "Ignoring --hard-links as the destination does not support hard links")
+			s.hardLinks = false
+		} else if DoMove {
+			fs.Errorf(fdst, "Ignoring --hard-links as it doesn't work with move, only sync or copy")
+			s.hardLinks = false
+		}
+	}
+	// Make the destination directories in parallel ahead of the
+	// transfers if the destination has real directories
+	s.preMkdir = fdst.Features().CanHaveEmptyDirectories && !ci.DryRun && !ci.Interactive && deleteMode != fs.DeleteModeOnly
+	if s.deleteMode == fs.DeleteModeDuring && needDeleteTotals(ci) {
+		fs.Logf(nil, "Using --delete-after as --max-delete with a percentage or --max-delete-size is in use")
+		s.deleteMode = fs.DeleteModeAfter
+	}
 	if s.trackRenames {
 		// track renames needs delete after
 		if s.deleteMode != fs.DeleteModeOff {
@@ -256,6 +320,7 @@ outer:
 // This checks the types of errors returned while copying files
 func (s *syncCopyMove) processError(err error) {
 	if err == nil {
+		_ = s.errorLimit.add(false)
 		return
 	}
 	if err == context.DeadlineExceeded {
@@ -270,6 +335,10 @@ func (s *syncCopyMove) processError(err error) {
 		// Ignore context Canceled if we have called s.inCancel()
 		return
 	}
+	if limitErr := s.errorLimit.add(true); limitErr != nil {
+		// err has already been logged where it happened
+		err = limitErr
+	}
 	s.errorMu.Lock()
 	defer s.errorMu.Unlock()
 	switch {
@@ -349,6 +418,20 @@ func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, fraction int, wg *sync.W
 					}
 				}
 			} else {
+				// If verifying immutable files check the existing
+				// destination still has the same contents. This was
+				// done already by NeedTransfer if using --checksum.
+				if s.ci.ImmutableVerify && !s.ci.CheckSum && pair.Dst != nil {
+					err := operations.VerifyImmutable(s.ctx, src, pair.Dst)
+					if err != nil {
+						s.processError(err)
+						tr.Done(s.ctx, err)
+						continue
+					}
+				}
+				if pair.Dst != nil {
+					s.processError(operations.RefreshSidecar(s.ctx, s.fdst, src, pair.Dst))
+				}
 				// If moving need to delete the files we don't need to copy
 				if s.DoMove {
 					// Delete src if no error on copy
@@ -394,8 +477,12 @@ func (s *syncCopyMove) pairCopyOrMove(ctx context.Context, in *pipe, fdst fs.Fs,
 			return
 		}
 		src := pair.Src
+		s.waitMkdirParent(ctx, src.Remote())
+		s.markDirModified(src.Remote())
 		if s.DoMove {
 			_, err = operations.Move(ctx, fdst, pair.Dst, src.Remote(), src)
+		} else if s.hardLinks {
+			err = s.copyOrHardLink(ctx, fdst, pair)
 		} else {
 			_, err = operations.Copy(ctx, fdst, pair.Dst, src.Remote(), src)
 		}
@@ -502,6 +589,145 @@ func (s *syncCopyMove) stopDeleters() {
 	s.deletersWg.Wait()
 }
 
+// This starts the background directory makers
+func (s *syncCopyMove) startMkdirs() {
+	if !s.preMkdir {
+		return
+	}
+	s.mkdirWg.Add(s.ci.Checkers)
+	for i := 0; i < s.ci.Checkers; i++ {
+		go func() {
+			defer s.mkdirWg.Done()
+			for dir := range s.mkdirCh {
+				fs.Debugf(fs.LogDirName(s.fdst, dir), "Making directory before transfers")
+				err := s.fdst.Mkdir(s.ctx, dir)
+				if err != nil {
+					// Not fatal - the transfer will make the directory if it can
+					fs.Debugf(fs.LogDirName(s.fdst, dir), "Failed to make directory before transfers: %v", err)
+				}
+				s.mkdirMu.Lock()
+				close(s.mkdirMap[dir].done)
+				s.mkdirMu.Unlock()
+			}
+		}()
+	}
+}
+
+// This stops the background directory makers
+func (s *syncCopyMove) stopMkdirs() {
+	if !s.preMkdir {
+		return
+	}
+	close(s.mkdirCh)
+	s.mkdirWg.Wait()
+}
+
+// srcOnlyDir records that dir is missing from the destination so
+// needs making before any files can be transferred into it
+func (s *syncCopyMove) srcOnlyDir(dir string) {
+	if !s.preMkdir {
+		return
+	}
+	s.mkdirMu.Lock()
+	if _, found := s.mkdirMap[dir]; !found {
+		s.mkdirMap[dir] = nil
+	}
+	s.mkdirMu.Unlock()
+}
+
+// mkdirParent starts making the parent directory of remote if it is
+// missing from the destination and hasn't been started already.
+//
+// Only directories which will have files transferred into them are
+// made so empty source directories aren't copied.
+func (s *syncCopyMove) mkdirParent(remote string) {
+	if !s.preMkdir {
+		return
+	}
+	dir := path.Dir(remote)
+	s.mkdirMu.Lock()
+	job, found := s.mkdirMap[dir]
+	if !found || job != nil {
+		s.mkdirMu.Unlock()
+		return
+	}
+	s.mkdirMap[dir] = &mkdirJob{done: make(chan struct{})}
+	s.mkdirMu.Unlock()
+	select {
+	case <-s.ctx.Done():
+	case s.mkdirCh <- dir:
+	}
+}
+
+// waitMkdirParent waits for the parent directory of remote to be
+// made if it is being made
+func (s *syncCopyMove) waitMkdirParent(ctx context.Context, remote string) {
+	if !s.preMkdir {
+		return
+	}
+	s.mkdirMu.Lock()
+	job := s.mkdirMap[path.Dir(remote)]
+	s.mkdirMu.Unlock()
+	if job == nil {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-job.done:
+	}
+}
+
+// needDeleteTotals returns true if the --max-delete thresholds need
+// the totals for the destination so can only be checked once all the
+// files to delete are known.
+func needDeleteTotals(ci *fs.ConfigInfo) bool {
+	return ci.MaxDelete.Percent > 0 || ci.MaxDeleteSize.IsSet()
+}
+
+// addDstTotal records dst as one of the files in the destination
+func (s *syncCopyMove) addDstTotal(dst fs.Object) {
+	size := dst.Size()
+	if size < 0 {
+		size = 0
+	}
+	s.dstTotalMu.Lock()
+	s.dstTotalCount++
+	s.dstTotalSize += size
+	s.dstTotalMu.Unlock()
+}
+
+// checkMaxDelete checks that deleting the files in the dstFiles map
+// won't exceed --max-delete as a percentage or --max-delete-size.
+//
+// These are checked before anything is deleted so a sync which would
+// delete too much is aborted without deleting anything.
+func (s *syncCopyMove) checkMaxDelete() error {
+	if !needDeleteTotals(s.ci) {
+		return nil
+	}
+	var count, size int64
+	for _, o := range s.dstFiles {
+		count++
+		if o.Size() > 0 {
+			size += o.Size()
+		}
+	}
+	s.dstTotalMu.Lock()
+	totalCount, totalSize := s.dstTotalCount, s.dstTotalSize
+	s.dstTotalMu.Unlock()
+	if s.ci.MaxDelete.Percent > 0 && count > s.ci.MaxDelete.Limit(totalCount) {
+		err := errors.Errorf("--max-delete %v threshold reached: would delete %d of %d files", s.ci.MaxDelete, count, totalCount)
+		fs.Errorf(s.fdst, "%v", err)
+		return fserrors.FatalError(err)
+	}
+	if s.ci.MaxDeleteSize.IsSet() && size > s.ci.MaxDeleteSize.Limit(totalSize) {
+		err := errors.Errorf("--max-delete-size %v threshold reached: would delete %v of %v", s.ci.MaxDeleteSize, fs.SizeSuffix(size), fs.SizeSuffix(totalSize))
+		fs.Errorf(s.fdst, "%v", err)
+		return fserrors.FatalError(err)
+	}
+	return nil
+}
+
 // This deletes the files in the dstFiles map.  If checkSrcMap is set
 // then it checks to see if they exist first in srcFiles the source
 // file map, otherwise it unconditionally deletes them.  If
@@ -582,6 +808,127 @@ func (s *syncCopyMove) deleteEmptyDirectories(ctx context.Context, f fs.Fs, entr
 	return nil
 }
 
+// recordDirModTime records the src directory so its modification time
+// can be set on the dst directory when the sync has finished.
+//
+// dst should be the existing dst directory or nil if there isn't one.
+func (s *syncCopyMove) recordDirModTime(src, dst fs.DirEntry) {
+	if !s.setDirModTime {
+		return
+	}
+	var dstModTime time.Time
+	if dst != nil {
+		dstModTime = dst.ModTime(s.ctx)
+	}
+	s.dirModTimeMu.Lock()
+	s.dirModTimes[src.Remote()] = dirModTime{src: src, dstModTime: dstModTime}
+	s.dirModTimeMu.Unlock()
+}
+
+// markDirModified records that the dst directory containing remote may
+// have been written to, which changes its modification time
+func (s *syncCopyMove) markDirModified(remote string) {
+	if !s.setDirModTime {
+		return
+	}
+	dir := path.Dir(remote)
+	if dir == "." {
+		dir = ""
+	}
+	s.dirModTimeMu.Lock()
+	s.dirsModified[dir] = struct{}{}
+	s.dirModTimeMu.Unlock()
+}
+
+// rootDirEntry returns the directory entry for the root of f by
+// listing its parent, or nil if it can't be found
+func rootDirEntry(ctx context.Context, f fs.Fs) fs.DirEntry {
+	parent, leaf, err := fspath.Split(fs.ConfigString(f))
+	if err != nil || leaf == "" {
+		return nil
+	}
+	fparent, err := cache.Get(ctx, parent)
+	if err != nil && err != fs.ErrorIsFile {
+		fs.Debugf(f, "Can't read the modification time of the root: %v", err)
+		return nil
+	}
+	entries, err := list.DirSorted(ctx, fparent, true, "")
+	if err != nil {
+		fs.Debugf(f, "Can't read the modification time of the root: %v", err)
+		return nil
+	}
+	for _, entry := range entries {
+		if _, ok := entry.(fs.Directory); ok && entry.Remote() == leaf {
+			return fs.NewDirCopy(ctx, entry.(fs.Directory)).SetRemote("")
+		}
+	}
+	return nil
+}
+
+// setDirModTimes sets the modification times of the dst directories
+// to those of the src directories recorded during the sync.
+//
+// This is done at the end as writing to a directory changes its
+// modification time.
+func (s *syncCopyMove) setDirModTimes(ctx context.Context) error {
+	if len(s.dirModTimes) == 0 || s.ci.DryRun {
+		return nil
+	}
+	do := s.fdst.Features().DirSetModTime
+	// Set the root too unless only some files are being copied
+	if _, ok := s.dirModTimes[""]; !ok && s.fi.Files() == nil {
+		if root := rootDirEntry(ctx, s.fsrc); root != nil {
+			s.dirModTimes[""] = dirModTime{src: root}
+		}
+	}
+	remotes := make([]string, 0, len(s.dirModTimes))
+	for remote := range s.dirModTimes {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	window := fs.GetModifyWindow(ctx, s.fsrc, s.fdst)
+	var lastErr error
+	var errorCount int
+	var okCount int
+	for _, remote := range remotes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := s.dirModTimes[remote]
+		modTime := entry.src.ModTime(ctx)
+		if modTime.IsZero() {
+			continue
+		}
+		// Skip directories which already have the right modtime
+		// and haven't been written to
+		if _, modified := s.dirsModified[remote]; !modified && !entry.dstModTime.IsZero() {
+			dt := entry.dstModTime.Sub(modTime)
+			if dt >= -window && dt <= window {
+				continue
+			}
+		}
+		err := do(ctx, remote, modTime)
+		if err == fs.ErrorDirNotFound {
+			// The directory wasn't created on the dst
+			continue
+		}
+		if err != nil {
+			fs.Errorf(fs.LogDirName(s.fdst, remote), "Failed to set directory modification time: %v", err)
+			lastErr = err
+			errorCount++
+		} else {
+			okCount++
+		}
+	}
+	if errorCount > 0 {
+		fs.Debugf(s.fdst, "failed to set the modification time of %d directories", errorCount)
+	}
+	if okCount > 0 {
+		fs.Debugf(s.fdst, "set the modification time of %d directories", okCount)
+	}
+	return lastErr
+}
+
 // This copies the empty directories in the slice passed in and logs
 // any errors copying the directories
 func copyEmptyDirectories(ctx context.Context, f fs.Fs, entries map[string]fs.DirEntry) error {
@@ -809,6 +1156,59 @@ func (s *syncCopyMove) tryRename(src fs.Object) bool {
 	return true
 }
 
+// copyOrHardLink copies pair.Src to fdst unless it is a hard link to
+// a file which has already been copied, in which case it hard links
+// the destination to that copy instead.
+func (s *syncCopyMove) copyOrHardLink(ctx context.Context, fdst fs.Fs, pair fs.ObjectPair) (err error) {
+	src := pair.Src
+	var id string
+	if do, ok := fs.UnWrapObject(src).(fs.HardLinkIDer); ok {
+		id = do.HardLinkID()
+	}
+	if id == "" {
+		_, err = operations.Copy(ctx, fdst, pair.Dst, src.Remote(), src)
+		return err
+	}
+
+	s.hardLinkMu.Lock()
+	link, found := s.hardLinkMap[id]
+	if !found {
+		link = &hardLink{done: make(chan struct{})}
+		s.hardLinkMap[id] = link
+	}
+	s.hardLinkMu.Unlock()
+
+	// First name seen for this file so copy it for the others to use
+	if !found {
+		link.dst, err = operations.Copy(ctx, fdst, pair.Dst, src.Remote(), src)
+		if err != nil {
+			link.dst = nil
+		}
+		close(link.done)
+		return err
+	}
+
+	// Wait for the first name to be copied
+	select {
+	case <-link.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if link.dst != nil {
+		if operations.SkipDestructive(ctx, src, "hard link") {
+			return nil
+		}
+		_, err = fdst.Features().HardLink(ctx, link.dst, src.Remote())
+		if err == nil {
+			fs.Infof(src, "Hard linked to %q", link.dst.Remote())
+			return nil
+		}
+		fs.Debugf(src, "Failed to hard link to %q: %v", link.dst.Remote(), err)
+	}
+	_, err = operations.Copy(ctx, fdst, pair.Dst, src.Remote(), src)
+	return err
+}
+
 // Syncs fsrc into fdst
 //
 // If Delete is true then it deletes any files in fdst that aren't in fsrc
@@ -829,6 +1229,7 @@ func (s *syncCopyMove) run() error {
 		s.startTransfers()
 	}
 	s.startDeleters()
+	s.startMkdirs()
 	s.dstFiles = make(map[string]fs.Object)
 
 	s.startTrackRenames()
@@ -867,6 +1268,7 @@ func (s *syncCopyMove) run() error {
 		s.startTransfers()
 	}
 	s.stopRenamers()
+	s.stopMkdirs()
 	s.stopTransfers()
 	s.stopDeleters()
 
@@ -878,6 +1280,8 @@ func (s *syncCopyMove) run() error {
 	if s.deleteMode == fs.DeleteModeAfter {
 		if s.currentError() != nil && !s.ci.IgnoreErrors {
 			fs.Errorf(s.fdst, "%v", fs.ErrorNotDeleting)
+		} else if err := s.checkMaxDelete(); err != nil {
+			s.processError(err)
 		} else {
 			s.processError(s.deleteFiles(false))
 		}
@@ -899,6 +1303,9 @@ func (s *syncCopyMove) run() error {
 		s.processError(s.deleteEmptyDirectories(s.ctx, s.fsrc, s.srcEmptyDirs))
 	}
 
+	// Set the modification times of the dst directories
+	s.processError(s.setDirModTimes(s.ctx))
+
 	// Read the error out of the context if there is one
 	s.processError(s.ctx.Err())
 
@@ -914,11 +1321,31 @@ func (s *syncCopyMove) run() error {
 
 // DstOnly have an object which is in the destination only
 func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
+	if o, ok := dst.(fs.Object); ok && operations.IsStalePartial(s.ctx, o) {
+		// Remove partial uploads left behind by previous runs
+		fs.Debugf(o, "Removing stale partial upload")
+		s.markDirModified(o.Remote())
+		err := operations.DeleteFile(s.ctx, o)
+		if err != nil {
+			s.processError(err)
+		}
+		return false
+	}
 	if s.deleteMode == fs.DeleteModeOff {
 		return false
 	}
+	s.markDirModified(dst.Remote())
+	if o, ok := dst.(fs.Object); ok && operations.IsManagedSidecar(s.ctx, s.fdst, o) {
+		// Checksum files are removed with the file they are for
+		return false
+	}
 	switch x := dst.(type) {
 	case fs.Object:
+		s.addDstTotal(x)
+		if s.fi.Protected(x.Remote()) {
+			fs.Infof(x, "Not deleting as protected by --protect")
+			return false
+		}
 		switch s.deleteMode {
 		case fs.DeleteModeAfter:
 			// record object as needs deleting
@@ -937,7 +1364,7 @@ func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
 	case fs.Directory:
 		// Do the same thing to the entire contents of the directory
 		// Record directory as it is potentially empty and needs deleting
-		if s.fdst.Features().CanHaveEmptyDirectories {
+		if s.fdst.Features().CanHaveEmptyDirectories && !s.fi.Protected(dst.Remote()) {
 			s.dstEmptyDirsMu.Lock()
 			s.dstEmptyDirs[dst.Remote()] = dst
 			s.dstEmptyDirsMu.Unlock()
@@ -955,6 +1382,7 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 	if s.deleteMode == fs.DeleteModeOnly {
 		return false
 	}
+	s.markDirModified(src.Remote())
 	switch x := src.(type) {
 	case fs.Object:
 		// If it's a copy operation,
@@ -964,6 +1392,7 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 		s.srcParentDirCheck(src)
 		s.srcEmptyDirsMu.Unlock()
 
+		s.mkdirParent(x.Remote())
 		if s.trackRenames {
 			// Save object to check for a rename later
 			select {
@@ -992,6 +1421,8 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 		s.srcParentDirCheck(src)
 		s.srcEmptyDirs[src.Remote()] = src
 		s.srcEmptyDirsMu.Unlock()
+		s.srcOnlyDir(src.Remote())
+		s.recordDirModTime(src, nil)
 		return true
 	default:
 		panic("Bad object in DirEntries")
@@ -1012,6 +1443,7 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 		}
 		dstX, ok := dst.(fs.Object)
 		if ok {
+			s.addDstTotal(dstX)
 			ok = s.toBeChecked.Put(s.ctx, fs.ObjectPair{Src: srcX, Dst: dstX})
 			if !ok {
 				return false
@@ -1034,7 +1466,7 @@ func (s *syncCopyMove) Match(ctx context.Context, dst, src fs.DirEntry) (recurse
 				s.srcEmptyDirs[src.Remote()] = src
 				s.srcEmptyDirsMu.Unlock()
 			}
-
+			s.recordDirModTime(src, dst)
 			return true
 		}
 		// FIXME src is dir, dst is file
@@ -1059,6 +1491,10 @@ func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.Delete
 	if deleteMode != fs.DeleteModeOff && DoMove {
 		return fserrors.FatalError(errors.New("can't delete and move at the same time"))
 	}
+	if deleteMode == fs.DeleteModeBefore && needDeleteTotals(ci) {
+		fs.Logf(nil, "Using --delete-after as --max-delete with a percentage or --max-delete-size is in use")
+		deleteMode = fs.DeleteModeAfter
+	}
 	// Run an extra pass to delete only
 	if deleteMode == fs.DeleteModeBefore {
 		if ci.TrackRenames {
@@ -1130,3 +1566,20 @@ func MoveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, cop
 	// Otherwise move the files one by one
 	return moveDir(ctx, fdst, fsrc, deleteEmptySrcDirs, copyEmptySrcDirs)
 }
+
+// MoveDirTo moves fsrc to fdst as used by moveto, so fsrc is renamed
+// to fdst rather than moved into it.
+//
+// A server-side directory move is only used if fdst doesn't exist.
+// Otherwise the contents of fsrc are moved into fdst file by file so
+// fdst is never removed.
+func MoveDirTo(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
+	fi := filter.GetConfig(ctx)
+	if fdst.Features().DirMove != nil && operations.SameConfig(fsrc, fdst) && fi.InActive() && !operations.Same(fdst, fsrc) {
+		if _, err := fdst.List(ctx, ""); err != fs.ErrorDirNotFound {
+			fs.Debugf(fdst, "Not using server-side directory move as the destination exists")
+			return moveDir(ctx, fdst, fsrc, deleteEmptySrcDirs, copyEmptySrcDirs)
+		}
+	}
+	return MoveDir(ctx, fdst, fsrc, deleteEmptySrcDirs, copyEmptySrcDirs)
+}
@@ -5,6 +5,8 @@ package sync
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -17,6 +19,7 @@ import (
 	"github.com/artpar/rclone/fs/fserrors"
 	"github.com/artpar/rclone/fs/hash"
 	"github.com/artpar/rclone/fs/operations"
+	"github.com/artpar/rclone/fs/walk"
 	"github.com/artpar/rclone/fstest"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -53,6 +56,27 @@ func TestCopyWithDryRun(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote)
 }
 
+// Check stale partial uploads are removed when copying
+func TestCopyRemovesStalePartials(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Fremote.Features().Move == nil {
+		t.Skip("Can't test partial uploads without Move")
+	}
+	ci.PartialSuffix = ".partial"
+	file1 := r.WriteFile("sub dir/hello world", "hello world", t1)
+	r.WriteObject(ctx, "sub dir/hello world.1.partial", "hello", t1) // stale
+	recent := r.WriteObject(ctx, fmt.Sprintf("sub dir/other.%x.partial", time.Now().Unix()), "other", t1)
+
+	err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote, file1, recent)
+}
+
 // Now without dry run
 func TestCopy(t *testing.T) {
 	ctx := context.Background()
@@ -83,6 +107,74 @@ func TestCopyMissingDirectory(t *testing.T) {
 	require.Error(t, err)
 }
 
+// Now with --hard-links
+func TestCopyHardLinks(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Fremote.Features().HardLink == nil {
+		t.Skip("Can't hard link on this remote")
+	}
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("hard link IDs not supported on " + runtime.GOOS)
+	}
+
+	ci.HardLinks = true
+
+	file1 := r.WriteFile("sub dir/hello world", "hello world", t1)
+	require.NoError(t, os.Link(filepath.Join(r.LocalName, "sub dir", "hello world"), filepath.Join(r.LocalName, "hello link")))
+	file2 := fstest.NewItem("hello link", "hello world", t1)
+	file3 := r.WriteFile("potato", "not linked", t1)
+
+	err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	hardLinkID := func(remote string) string {
+		o, err := r.Fremote.NewObject(ctx, remote)
+		require.NoError(t, err)
+		do, ok := o.(fs.HardLinkIDer)
+		require.True(t, ok)
+		return do.HardLinkID()
+	}
+	id := hardLinkID(file1.Path)
+	assert.NotEqual(t, "", id)
+	assert.Equal(t, id, hardLinkID(file2.Path))
+	assert.Equal(t, "", hardLinkID(file3.Path))
+}
+
+// Now with --hard-links and --dry-run
+func TestCopyHardLinksDryRun(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Fremote.Features().HardLink == nil {
+		t.Skip("Can't hard link on this remote")
+	}
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("hard link IDs not supported on " + runtime.GOOS)
+	}
+
+	ci.HardLinks = true
+	ci.DryRun = true
+
+	file1 := r.WriteFile("sub dir/hello world", "hello world", t2)
+	require.NoError(t, os.Link(filepath.Join(r.LocalName, "sub dir", "hello world"), filepath.Join(r.LocalName, "hello link")))
+	file2 := fstest.NewItem("hello link", "hello world", t2)
+	dst1 := r.WriteObject(ctx, "sub dir/hello world", "old", t1)
+	dst2 := r.WriteObject(ctx, "hello link", "older", t1)
+
+	err := CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+	fstest.CheckItems(t, r.Fremote, dst1, dst2)
+}
+
 // Now with --no-traverse
 func TestCopyNoTraverse(t *testing.T) {
 	ctx := context.Background()
@@ -213,6 +305,108 @@ func TestCopyEmptyDirectories(t *testing.T) {
 	)
 }
 
+// Test copying a deep tree only makes the directories with files in
+func TestCopyDeepDirectories(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("a/b/c/one", "one", t1)
+	file2 := r.WriteFile("a/b/c/two", "two", t1)
+	file3 := r.WriteFile("a/three", "three", t2)
+	file4 := r.WriteFile("x/y/z/four", "four", t2)
+	err := operations.Mkdir(ctx, r.Flocal, "a/empty")
+	require.NoError(t, err)
+	r.Mkdir(ctx, r.Fremote)
+
+	err = CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	fstest.CheckListingWithPrecision(
+		t,
+		r.Fremote,
+		[]fstest.Item{
+			file1,
+			file2,
+			file3,
+			file4,
+		},
+		[]string{
+			"a",
+			"a/b",
+			"a/b/c",
+			"x",
+			"x/y",
+			"x/y/z",
+		},
+		fs.GetModifyWindow(ctx, r.Fremote),
+	)
+}
+
+// Test copying sets the modification times of the directories
+func TestCopyDirModTime(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Fremote.Features().DirSetModTime == nil {
+		t.Skip("Can't set directory modification times on the remote")
+	}
+	file1 := r.WriteFile("a/b/one", "one", t1)
+	err := operations.Mkdir(ctx, r.Flocal, "a/empty")
+	require.NoError(t, err)
+	setDirModTime := r.Flocal.Features().DirSetModTime
+	require.NoError(t, setDirModTime(ctx, "a/b", t1))
+	require.NoError(t, setDirModTime(ctx, "a/empty", t2))
+	require.NoError(t, setDirModTime(ctx, "a", t3))
+	require.NoError(t, setDirModTime(ctx, "", t2))
+	r.Mkdir(ctx, r.Fremote)
+
+	// read the directory modification times from the remote
+	dirModTimes := func() map[string]time.Time {
+		got := map[string]time.Time{}
+		err := walk.ListR(ctx, r.Fremote, "", true, -1, walk.ListDirs, func(entries fs.DirEntries) error {
+			for _, entry := range entries {
+				got[entry.Remote()] = entry.ModTime(ctx)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return got
+	}
+	precision := fs.GetModifyWindow(ctx, r.Fremote)
+
+	// Check --no-update-dir-modtime doesn't set them
+	ci.NoUpdateDirModTime = true
+	err = CopyDir(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+	got := dirModTimes()
+	_, equal := fstest.CheckTimeEqualWithPrecision(t1, got["a/b"], precision)
+	assert.False(t, equal)
+
+	ci.NoUpdateDirModTime = false
+	err = CopyDir(ctx, r.Fremote, r.Flocal, true)
+	require.NoError(t, err)
+	got = dirModTimes()
+	assert.Equal(t, 3, len(got))
+	fstest.AssertTimeEqualWithPrecision(t, "a", t3, got["a"], precision)
+	fstest.AssertTimeEqualWithPrecision(t, "a/b", t1, got["a/b"], precision)
+	fstest.AssertTimeEqualWithPrecision(t, "a/empty", t2, got["a/empty"], precision)
+	if root := rootDirEntry(ctx, r.Fremote); root != nil {
+		fstest.AssertTimeEqualWithPrecision(t, "root", t2, root.ModTime(ctx), precision)
+	}
+
+	// Check a directory which already has the right modtime is set
+	// again if a file is copied into it
+	file2 := r.WriteFile("a/b/two", "two", t1)
+	require.NoError(t, setDirModTime(ctx, "a/b", t1))
+	err = CopyDir(ctx, r.Fremote, r.Flocal, true)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+	got = dirModTimes()
+	fstest.AssertTimeEqualWithPrecision(t, "a/b", t1, got["a/b"], precision)
+}
+
 // Test move empty directories
 func TestMoveEmptyDirectories(t *testing.T) {
 	ctx := context.Background()
@@ -356,6 +550,32 @@ func TestSyncBasedOnCheckSum(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 }
 
+// Test that checksum files written with --sidecar-hash are kept when
+// syncing but ones without their file are deleted
+func TestSyncSidecarHash(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if !r.Flocal.Hashes().Contains(hash.SHA256) {
+		t.Skip("Can't test checksum files without SHA-256 on the source")
+	}
+	ci.SidecarHash = hash.SHA256
+
+	r.WriteFile("file1", "file1 contents", t1)
+	r.WriteObject(ctx, "orphan.sha256", "0000  orphan\n", t1)
+
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	_, err = r.Fremote.NewObject(ctx, "file1")
+	require.NoError(t, err)
+	_, err = r.Fremote.NewObject(ctx, "file1.sha256")
+	require.NoError(t, err)
+	_, err = r.Fremote.NewObject(ctx, "orphan.sha256")
+	assert.Equal(t, fs.ErrorObjectNotFound, err)
+}
+
 // Create a file and sync it. Change the last modified date and the
 // file contents but not the size.  If we're only doing sync by size
 // only, we expect nothing to to be transferred on the second sync.
@@ -988,6 +1208,79 @@ func TestSyncWithExcludeAndDeleteExcluded(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 }
 
+// Test with exclude and delete excluded and protect
+func TestSyncWithExcludeAndDeleteExcludedProtect(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteBoth(ctx, "potato2", "------------------------------------------------------------", t1) // 60 bytes
+	file2 := r.WriteBoth(ctx, "empty space", "-", t2)
+	file3 := r.WriteBoth(ctx, "enormous", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", t1) // 100 bytes
+	file4 := r.WriteObject(ctx, "dest only", "gone", t1)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3)
+
+	opt := filter.DefaultOpt
+	opt.MaxSize = 40
+	opt.DeleteExcluded = true
+	opt.ProtectRule = []string{"enormous", "dest*"}
+	fi, err := filter.NewFilter(&opt)
+	require.NoError(t, err)
+	ctx = filter.ReplaceConfig(ctx, fi)
+
+	// potato2 is excluded and deleted but enormous is excluded
+	// and protected and dest only is protected so neither are
+	accounting.GlobalStats().ResetCounters()
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file2, file3, file4)
+}
+
+// Test --max-delete with a percentage and --max-delete-size
+func TestSyncMaxDeleteThresholds(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteBoth(ctx, "keep", "keep", t1)
+	file2 := r.WriteObject(ctx, "delete1", "1234567890", t1)
+	file3 := r.WriteObject(ctx, "delete2", "1234567890", t1)
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	for _, test := range []struct {
+		maxDelete     string
+		maxDeleteSize string
+		deleteMode    fs.DeleteMode
+		errStr        string
+	}{
+		{"50%", "off", fs.DeleteModeAfter, "--max-delete 50% threshold reached: would delete 2 of 3 files"},
+		{"50%", "off", fs.DeleteModeDuring, "--max-delete 50% threshold reached"},
+		{"50%", "off", fs.DeleteModeBefore, "--max-delete 50% threshold reached"},
+		{"off", "10b", fs.DeleteModeAfter, "--max-delete-size 10 threshold reached: would delete 20 of 24"},
+		{"off", "80%", fs.DeleteModeAfter, "--max-delete-size 80% threshold reached"},
+	} {
+		ctx, ci := fs.AddConfig(ctx)
+		require.NoError(t, ci.MaxDelete.Set(test.maxDelete))
+		require.NoError(t, ci.MaxDeleteSize.Set(test.maxDeleteSize))
+		ci.DeleteMode = test.deleteMode
+		accounting.GlobalStats().ResetCounters()
+		err := Sync(ctx, r.Fremote, r.Flocal, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), test.errStr)
+		assert.True(t, fserrors.IsFatalError(err))
+		// Nothing should have been deleted
+		fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+	}
+
+	ctx, ci := fs.AddConfig(ctx)
+	require.NoError(t, ci.MaxDelete.Set("70%"))
+	require.NoError(t, ci.MaxDeleteSize.Set("90%"))
+	accounting.GlobalStats().ResetCounters()
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
 // Test with UpdateOlder set
 func TestSyncWithUpdateOlder(t *testing.T) {
 	ctx := context.Background()
@@ -1372,6 +1665,43 @@ func TestServerSideMoveDeleteEmptySourceDirs(t *testing.T) {
 	testServerSideMove(ctx, t, r, false, true)
 }
 
+// Test MoveDirTo only uses a server-side directory move if the
+// destination doesn't exist
+func TestMoveDirTo(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	if r.Fremote.Features().DirMove == nil {
+		t.Skip("Skipping test as remote doesn't support DirMove")
+	}
+
+	file1 := r.WriteObject(ctx, "potato", "hello", t1)
+	file2 := r.WriteObject(ctx, "sub dir/potato2", "world", t2)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	// The destination doesn't exist so it is renamed
+	FremoteMove, _, finaliseMove, err := fstest.RandomRemote()
+	require.NoError(t, err)
+	defer finaliseMove()
+	accounting.GlobalStats().ResetCounters()
+	err = MoveDirTo(ctx, FremoteMove, r.Fremote, false, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, FremoteMove, file1, file2)
+	assert.Equal(t, int64(0), accounting.GlobalStats().Renames(0), "expecting a directory move not file moves")
+
+	// The destination exists so the files are moved into it
+	FremoteMove2, _, finaliseMove2, err := fstest.RandomRemote()
+	require.NoError(t, err)
+	defer finaliseMove2()
+	require.NoError(t, operations.Mkdir(ctx, FremoteMove2, ""))
+	accounting.GlobalStats().ResetCounters()
+	err = MoveDirTo(ctx, FremoteMove2, FremoteMove, false, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, FremoteMove2, file1, file2)
+	assert.Equal(t, int64(2), accounting.GlobalStats().Renames(0), "expecting file moves not a directory move")
+}
+
 // Test a server-side move with overlap
 func TestServerSideMoveOverlap(t *testing.T) {
 	ctx := context.Background()
@@ -1895,6 +2225,56 @@ func TestSyncImmutable(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 }
 
+// Test --immutable-verify
+func TestSyncImmutableVerify(t *testing.T) {
+	ctx := context.Background()
+	ctx, ci := fs.AddConfig(ctx)
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	ci.Immutable = true
+	ci.ImmutableVerify = true
+
+	// Create file on source
+	file1 := r.WriteFile("existing", "potato", t1)
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote)
+
+	// Should succeed
+	accounting.GlobalStats().ResetCounters()
+	err := Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	// Should succeed again as the contents are the same
+	accounting.GlobalStats().ResetCounters()
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	if r.Fremote.Hashes().Overlap(r.Flocal.Hashes()).Count() == 0 {
+		t.Skip("Can't check contents with no common hash")
+	}
+
+	// Modify file data on source keeping the size and timestamp
+	file2 := r.WriteFile("existing", "tomato", t1)
+	fstest.CheckItems(t, r.Flocal, file2)
+
+	// Should fail with ErrorImmutableModified and not modify local or remote files
+	accounting.GlobalStats().ResetCounters()
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	assert.EqualError(t, err, fs.ErrorImmutableModified.Error())
+	fstest.CheckItems(t, r.Flocal, file2)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	// Without verifying the change isn't noticed
+	ci.ImmutableVerify = false
+	accounting.GlobalStats().ResetCounters()
+	err = Sync(ctx, r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
 // Test --ignore-case-sync
 func TestSyncIgnoreCase(t *testing.T) {
 	ctx := context.Background()
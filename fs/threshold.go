@@ -0,0 +1,197 @@
+package fs
+
+// Thresholds which may be absolute or a percentage of a total
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parsePercent parses s if it is a percentage like "5%" returning
+// ok as false if it isn't
+func parsePercent(s string) (percent float64, ok bool, err error) {
+	if !strings.HasSuffix(s, "%") {
+		return 0, false, nil
+	}
+	percent, err = strconv.ParseFloat(strings.TrimSpace(s[:len(s)-1]), 64)
+	if err != nil {
+		return 0, true, errors.Wrapf(err, "bad percentage %q", s)
+	}
+	if percent <= 0 || percent > 100 {
+		return 0, true, errors.Errorf("percentage %q must be more than 0%% and at most 100%%", s)
+	}
+	return percent, true, nil
+}
+
+// formatPercent formats percent as a string like "5%"
+func formatPercent(percent float64) string {
+	return strconv.FormatFloat(percent, 'f', -1, 64) + "%"
+}
+
+// percentOf returns percent of total rounded down
+func percentOf(percent float64, total int64) int64 {
+	return int64(float64(total) * percent / 100)
+}
+
+// CountThreshold is a limit on a number of things which can either
+// be absolute, eg "1000", or a percentage of a total, eg "5%".
+//
+// It is off if Count is negative and Percent is 0.
+type CountThreshold struct {
+	Count   int64   // absolute limit if Percent is 0
+	Percent float64 // if > 0 the limit is this percentage of the total
+}
+
+// CountThresholdOff is a CountThreshold which isn't set
+var CountThresholdOff = CountThreshold{Count: -1}
+
+// IsSet returns true if the threshold is in use
+func (x CountThreshold) IsSet() bool {
+	return x.Percent > 0 || x.Count >= 0
+}
+
+// Limit returns the limit for a total of total, or -1 if the
+// threshold isn't in use
+func (x CountThreshold) Limit(total int64) int64 {
+	if x.Percent > 0 {
+		return percentOf(x.Percent, total)
+	}
+	if x.Count < 0 {
+		return -1
+	}
+	return x.Count
+}
+
+// String turns the CountThreshold into a string
+func (x CountThreshold) String() string {
+	if x.Percent > 0 {
+		return formatPercent(x.Percent)
+	}
+	if x.Count < 0 {
+		return "off"
+	}
+	return strconv.FormatInt(x.Count, 10)
+}
+
+// Set a CountThreshold
+func (x *CountThreshold) Set(s string) error {
+	s = strings.TrimSpace(s)
+	percent, ok, err := parsePercent(s)
+	if err != nil {
+		return err
+	}
+	if ok {
+		*x = CountThreshold{Count: -1, Percent: percent}
+		return nil
+	}
+	if s == "off" {
+		*x = CountThresholdOff
+		return nil
+	}
+	count, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "bad count or percentage %q", s)
+	}
+	if count < 0 {
+		count = -1
+	}
+	*x = CountThreshold{Count: count}
+	return nil
+}
+
+// Type of the value
+func (x *CountThreshold) Type() string {
+	return "CountThreshold"
+}
+
+// MarshalJSON encodes the CountThreshold as a string
+func (x CountThreshold) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// UnmarshalJSON makes sure the value can be parsed as a string or integer in JSON
+func (x *CountThreshold) UnmarshalJSON(in []byte) error {
+	return UnmarshalJSONFlag(in, x, func(i int64) error {
+		return x.Set(strconv.FormatInt(i, 10))
+	})
+}
+
+// SizeThreshold is a limit on a size which can either be absolute,
+// eg "10G", or a percentage of a total, eg "5%".
+//
+// It is off if Size is negative and Percent is 0.
+type SizeThreshold struct {
+	Size    SizeSuffix // absolute limit if Percent is 0
+	Percent float64    // if > 0 the limit is this percentage of the total
+}
+
+// SizeThresholdOff is a SizeThreshold which isn't set
+var SizeThresholdOff = SizeThreshold{Size: -1}
+
+// IsSet returns true if the threshold is in use
+func (x SizeThreshold) IsSet() bool {
+	return x.Percent > 0 || x.Size >= 0
+}
+
+// Limit returns the limit for a total of total, or -1 if the
+// threshold isn't in use
+func (x SizeThreshold) Limit(total int64) int64 {
+	if x.Percent > 0 {
+		return percentOf(x.Percent, total)
+	}
+	if x.Size < 0 {
+		return -1
+	}
+	return int64(x.Size)
+}
+
+// String turns the SizeThreshold into a string
+func (x SizeThreshold) String() string {
+	if x.Percent > 0 {
+		return formatPercent(x.Percent)
+	}
+	return x.Size.String()
+}
+
+// Set a SizeThreshold
+func (x *SizeThreshold) Set(s string) error {
+	s = strings.TrimSpace(s)
+	percent, ok, err := parsePercent(s)
+	if err != nil {
+		return err
+	}
+	if ok {
+		*x = SizeThreshold{Size: -1, Percent: percent}
+		return nil
+	}
+	var size SizeSuffix
+	err = size.Set(s)
+	if err != nil {
+		return err
+	}
+	*x = SizeThreshold{Size: size}
+	return nil
+}
+
+// Type of the value
+func (x *SizeThreshold) Type() string {
+	return "SizeThreshold"
+}
+
+// MarshalJSON encodes the SizeThreshold as a string
+func (x SizeThreshold) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// UnmarshalJSON makes sure the value can be parsed as a string or integer in JSON
+func (x *SizeThreshold) UnmarshalJSON(in []byte) error {
+	return UnmarshalJSONFlag(in, x, func(i int64) error {
+		if i < 0 {
+			i = -1
+		}
+		*x = SizeThreshold{Size: SizeSuffix(i)}
+		return nil
+	})
+}
@@ -0,0 +1,100 @@
+package fs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Check it satisfies the interface
+var (
+	_ flagger = (*CountThreshold)(nil)
+	_ flagger = (*SizeThreshold)(nil)
+)
+
+func TestCountThresholdSet(t *testing.T) {
+	for _, test := range []struct {
+		in     string
+		want   CountThreshold
+		str    string
+		limit  int64
+		errStr string
+	}{
+		{"0", CountThreshold{Count: 0}, "0", 0, ""},
+		{"100", CountThreshold{Count: 100}, "100", 100, ""},
+		{"-1", CountThresholdOff, "off", -1, ""},
+		{"-10", CountThresholdOff, "off", -1, ""},
+		{"off", CountThresholdOff, "off", -1, ""},
+		{"5%", CountThreshold{Count: -1, Percent: 5}, "5%", 50, ""},
+		{"2.5%", CountThreshold{Count: -1, Percent: 2.5}, "2.5%", 25, ""},
+		{"100%", CountThreshold{Count: -1, Percent: 100}, "100%", 1000, ""},
+		{"0%", CountThreshold{}, "", 0, "must be more than 0%"},
+		{"101%", CountThreshold{}, "", 0, "at most 100%"},
+		{"x%", CountThreshold{}, "", 0, "bad percentage"},
+		{"1k", CountThreshold{}, "", 0, "bad count or percentage"},
+	} {
+		var got CountThreshold
+		err := got.Set(test.in)
+		if test.errStr != "" {
+			require.Error(t, err, test.in)
+			assert.Contains(t, err.Error(), test.errStr, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.want, got, test.in)
+		assert.Equal(t, test.str, got.String(), test.in)
+		assert.Equal(t, test.limit, got.Limit(1000), test.in)
+		assert.Equal(t, test.limit >= 0, got.IsSet(), test.in)
+	}
+}
+
+func TestSizeThresholdSet(t *testing.T) {
+	for _, test := range []struct {
+		in     string
+		want   SizeThreshold
+		str    string
+		limit  int64
+		errStr string
+	}{
+		{"0", SizeThreshold{Size: 0}, "0", 0, ""},
+		{"10M", SizeThreshold{Size: 10 * MebiByte}, "10M", 10 * 1024 * 1024, ""},
+		{"off", SizeThresholdOff, "off", -1, ""},
+		{"10%", SizeThreshold{Size: -1, Percent: 10}, "10%", 100 * 1024 * 1024, ""},
+		{"200%", SizeThreshold{}, "", 0, "at most 100%"},
+		{"potato", SizeThreshold{}, "", 0, "bad"},
+	} {
+		var got SizeThreshold
+		err := got.Set(test.in)
+		if test.errStr != "" {
+			require.Error(t, err, test.in)
+			assert.Contains(t, err.Error(), test.errStr, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.want, got, test.in)
+		assert.Equal(t, test.str, got.String(), test.in)
+		assert.Equal(t, test.limit, got.Limit(1000*1024*1024), test.in)
+	}
+}
+
+func TestThresholdJSON(t *testing.T) {
+	var c CountThreshold
+	require.NoError(t, json.Unmarshal([]byte(`100`), &c))
+	assert.Equal(t, CountThreshold{Count: 100}, c)
+	require.NoError(t, json.Unmarshal([]byte(`"5%"`), &c))
+	assert.Equal(t, CountThreshold{Count: -1, Percent: 5}, c)
+	out, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Equal(t, `"5%"`, string(out))
+
+	var s SizeThreshold
+	require.NoError(t, json.Unmarshal([]byte(`1024`), &s))
+	assert.Equal(t, SizeThreshold{Size: 1024}, s)
+	require.NoError(t, json.Unmarshal([]byte(`"1M"`), &s))
+	assert.Equal(t, SizeThreshold{Size: MebiByte}, s)
+	out, err = json.Marshal(s)
+	require.NoError(t, err)
+	assert.Equal(t, `"1M"`, string(out))
+}
@@ -0,0 +1,380 @@
+// Package tracing records spans for rclone operations such as
+// listings, transfers and chunk downloads and exports them to an
+// OpenTelemetry collector.
+//
+// Spans are sent in batches using OTLP over HTTP with the JSON
+// encoding to the --trace-endpoint, so they can be correlated with
+// application traces using --trace-parent.
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/lib/atexit"
+	"github.com/pkg/errors"
+)
+
+// Span is a single timed operation in a trace.
+//
+// A nil *Span is valid and does nothing, which is what Start returns
+// when tracing isn't enabled.
+type Span struct {
+	exporter *exporter
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	start    time.Time
+
+	mu    sync.Mutex // protects the below
+	attrs []attribute
+	ended bool
+}
+
+// ctxKey is the context key for the current span
+type ctxKey struct{}
+
+// Start starts a span called name as a child of the span in ctx (or
+// of the --trace-parent if none) and returns a context containing it.
+//
+// If --trace-endpoint isn't set then it returns ctx and a nil span.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	ci := fs.GetConfig(ctx)
+	if ci.TraceEndpoint == "" {
+		return ctx, nil
+	}
+	s := &Span{
+		exporter: getExporter(ci.TraceEndpoint),
+		spanID:   newID(8),
+		name:     name,
+		start:    time.Now(),
+	}
+	if parent := FromContext(ctx); parent != nil {
+		s.traceID, s.parentID = parent.traceID, parent.spanID
+	} else if traceID, parentID, err := ParseTraceParent(ci.TraceParent); err == nil {
+		s.traceID, s.parentID = traceID, parentID
+	} else {
+		if ci.TraceParent != "" {
+			fs.Debugf(nil, "Ignoring --trace-parent: %v", err)
+		}
+		s.traceID = newID(16)
+	}
+	return context.WithValue(ctx, ctxKey{}, s), s
+}
+
+// FromContext returns the current span in ctx or nil if there isn't
+// one
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(ctxKey{}).(*Span)
+	return s
+}
+
+// SetAttribute sets the attribute key on the span to value which
+// should be a string, bool, int, int64 or float64.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, newAttribute(key, value))
+	s.mu.Unlock()
+}
+
+// End finishes the span marking it as failed if err is set and queues
+// it to be exported.
+//
+// Calling End more than once does nothing.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	span := otlpSpan{
+		TraceID:           s.traceID,
+		SpanID:            s.spanID,
+		ParentSpanID:      s.parentID,
+		Name:              s.name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+		Attributes:        s.attrs,
+	}
+	s.mu.Unlock()
+	if err != nil {
+		span.Status = otlpStatus{Code: statusCodeError, Message: err.Error()}
+	}
+	s.exporter.add(span)
+}
+
+// TraceParent returns the span in W3C traceparent format so it can be
+// passed on to other processes.
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", s.traceID, s.spanID)
+}
+
+// ParseTraceParent parses a W3C traceparent header, eg
+// "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01" returning
+// the trace ID and the parent span ID.
+func ParseTraceParent(traceParent string) (traceID, parentID string, err error) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", errors.Errorf("invalid traceparent %q", traceParent)
+	}
+	traceID, parentID = strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	if !isHexID(traceID, 16) || !isHexID(parentID, 8) {
+		return "", "", errors.Errorf("invalid IDs in traceparent %q", traceParent)
+	}
+	return traceID, parentID, nil
+}
+
+// isHexID returns true if id is a valid non zero hex ID of n bytes
+func isHexID(id string, n int) bool {
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) != n {
+		return false
+	}
+	for _, c := range b {
+		if c != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// newID returns a random hex ID of n bytes
+func newID(n int) string {
+	b := make([]byte, n)
+	_, err := io.ReadFull(rand.Reader, b)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// OTLP constants
+const (
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+// These structures are the OTLP/JSON encoding of the spans
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []attribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpSpan struct {
+		TraceID           string      `json:"traceId"`
+		SpanID            string      `json:"spanId"`
+		ParentSpanID      string      `json:"parentSpanId,omitempty"`
+		Name              string      `json:"name"`
+		Kind              int         `json:"kind"`
+		StartTimeUnixNano string      `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+		Attributes        []attribute `json:"attributes,omitempty"`
+		Status            otlpStatus  `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	attribute struct {
+		Key   string         `json:"key"`
+		Value attributeValue `json:"value"`
+	}
+	attributeValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// newAttribute makes an OTLP attribute from key and value
+func newAttribute(key string, value interface{}) attribute {
+	a := attribute{Key: key}
+	switch x := value.(type) {
+	case bool:
+		a.Value.BoolValue = &x
+	case int:
+		s := strconv.Itoa(x)
+		a.Value.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(x, 10)
+		a.Value.IntValue = &s
+	case float64:
+		a.Value.DoubleValue = &x
+	case string:
+		a.Value.StringValue = &x
+	default:
+		s := fmt.Sprint(x)
+		a.Value.StringValue = &s
+	}
+	return a
+}
+
+// Exporter tuning
+var (
+	exportInterval  = 5 * time.Second // how often to send spans
+	exportBatchSize = 512             // send spans immediately if there are this many
+	exportTimeout   = 10 * time.Second
+)
+
+// exporter sends spans to an OTLP/HTTP endpoint in batches
+type exporter struct {
+	endpoint string
+	client   *http.Client
+	mu       sync.Mutex // protects spans
+	spans    []otlpSpan
+	sendMu   sync.Mutex // only send one batch at once
+	timer    *time.Timer
+}
+
+var (
+	exportersMu sync.Mutex
+	exporters   = map[string]*exporter{}
+)
+
+// getExporter returns the exporter for endpoint, making it if
+// necessary
+func getExporter(endpoint string) *exporter {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+	e := exporters[endpoint]
+	if e == nil {
+		e = &exporter{
+			endpoint: endpoint,
+			client:   &http.Client{Timeout: exportTimeout},
+		}
+		exporters[endpoint] = e
+		atexit.Register(func() {
+			if err := e.Flush(); err != nil {
+				fs.Errorf(nil, "Failed to export trace spans: %v", err)
+			}
+		})
+	}
+	return e
+}
+
+// Flush sends any outstanding spans to all the configured endpoints
+func Flush() (err error) {
+	exportersMu.Lock()
+	es := make([]*exporter, 0, len(exporters))
+	for _, e := range exporters {
+		es = append(es, e)
+	}
+	exportersMu.Unlock()
+	for _, e := range es {
+		if flushErr := e.Flush(); flushErr != nil {
+			err = flushErr
+		}
+	}
+	return err
+}
+
+// add span to the queue sending them if there are enough
+func (e *exporter) add(span otlpSpan) {
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	n := len(e.spans)
+	if n == 1 {
+		e.timer = time.AfterFunc(exportInterval, e.flushInBackground)
+	}
+	e.mu.Unlock()
+	if n >= exportBatchSize {
+		go e.flushInBackground()
+	}
+}
+
+// flushInBackground sends the spans logging any errors
+func (e *exporter) flushInBackground() {
+	if err := e.Flush(); err != nil {
+		fs.Errorf(nil, "Failed to export trace spans: %v", err)
+	}
+}
+
+// Flush sends the queued spans to the endpoint
+func (e *exporter) Flush() error {
+	e.sendMu.Lock()
+	defer e.sendMu.Unlock()
+	e.mu.Lock()
+	spans := e.spans
+	e.spans = nil
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	e.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	return e.send(spans)
+}
+
+// send spans to the endpoint
+func (e *exporter) send(spans []otlpSpan) error {
+	serviceName := "rclone"
+	body, err := json.Marshal(otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []attribute{newAttribute("service.name", serviceName)},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: serviceName, Version: fs.Version},
+				Spans: spans,
+			}},
+		}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode spans")
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to send spans")
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("failed to send %d spans: %s", len(spans), resp.Status)
+	}
+	fs.Debugf(nil, "Exported %d trace spans", len(spans))
+	return nil
+}
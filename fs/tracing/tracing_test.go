@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	for _, test := range []struct {
+		in       string
+		traceID  string
+		parentID string
+		wantErr  bool
+	}{
+		{"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331", false},
+		{"00-0AF7651916CD43DD8448EB211C80319C-B7AD6B7169203331-00", "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331", false},
+		{"", "", "", true},
+		{"potato", "", "", true},
+		{"ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", "", "", true},
+		{"00-00000000000000000000000000000000-b7ad6b7169203331-01", "", "", true},
+		{"00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01", "", "", true},
+		{"00-0af7651916cd43dd-b7ad6b7169203331-01", "", "", true},
+	} {
+		traceID, parentID, err := ParseTraceParent(test.in)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.traceID, traceID, test.in)
+		assert.Equal(t, test.parentID, parentID, test.in)
+	}
+}
+
+func TestStartDisabled(t *testing.T) {
+	ctx := context.Background()
+	newCtx, span := Start(ctx, "potato")
+	assert.Nil(t, span)
+	assert.Equal(t, ctx, newCtx)
+	// check the methods on a nil span are safe
+	span.SetAttribute("key", "value")
+	span.End(nil)
+	assert.Equal(t, "", span.TraceParent())
+}
+
+func TestExport(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []otlpRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var req otlpRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.TraceEndpoint = server.URL
+	ci.TraceParent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+	parentCtx, parent := Start(ctx, "parent")
+	require.NotNil(t, parent)
+	assert.Equal(t, parent, FromContext(parentCtx))
+	_, child := Start(parentCtx, "child")
+	child.SetAttribute("string", "potato")
+	child.SetAttribute("int", 42)
+	child.SetAttribute("int64", int64(43))
+	child.SetAttribute("bool", true)
+	child.SetAttribute("float", 1.5)
+	child.End(errors.New("boom"))
+	child.End(nil) // should be ignored
+	parent.End(nil)
+
+	require.NoError(t, Flush())
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 1, len(requests))
+	require.Equal(t, 1, len(requests[0].ResourceSpans))
+	rs := requests[0].ResourceSpans[0]
+	require.Equal(t, 1, len(rs.Resource.Attributes))
+	assert.Equal(t, "service.name", rs.Resource.Attributes[0].Key)
+	require.Equal(t, 1, len(rs.ScopeSpans))
+	spans := rs.ScopeSpans[0].Spans
+	require.Equal(t, 2, len(spans))
+
+	gotChild, gotParent := spans[0], spans[1]
+	assert.Equal(t, "child", gotChild.Name)
+	assert.Equal(t, "parent", gotParent.Name)
+
+	// parent should be linked to the --trace-parent
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", gotParent.TraceID)
+	assert.Equal(t, "b7ad6b7169203331", gotParent.ParentSpanID)
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-"+gotParent.SpanID+"-01", parent.TraceParent())
+	assert.Equal(t, 0, gotParent.Status.Code)
+
+	// child should be linked to the parent
+	assert.Equal(t, gotParent.TraceID, gotChild.TraceID)
+	assert.Equal(t, gotParent.SpanID, gotChild.ParentSpanID)
+	assert.Equal(t, statusCodeError, gotChild.Status.Code)
+	assert.Equal(t, "boom", gotChild.Status.Message)
+	require.Equal(t, 5, len(gotChild.Attributes))
+	assert.Equal(t, "potato", *gotChild.Attributes[0].Value.StringValue)
+	assert.Equal(t, "42", *gotChild.Attributes[1].Value.IntValue)
+	assert.Equal(t, "43", *gotChild.Attributes[2].Value.IntValue)
+	assert.Equal(t, true, *gotChild.Attributes[3].Value.BoolValue)
+	assert.Equal(t, 1.5, *gotChild.Attributes[4].Value.DoubleValue)
+
+	// nothing left to send
+	require.NoError(t, Flush())
+	assert.Equal(t, 1, len(requests))
+}
+
+func TestExportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	ctx, ci := fs.AddConfig(context.Background())
+	ci.TraceEndpoint = server.URL
+	_, span := Start(ctx, "span")
+	span.End(nil)
+	err := Flush()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+}
@@ -0,0 +1,166 @@
+// Spill the directories found by ListR to disk
+
+package walk
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/gob"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// dirMapEntryOverhead is an estimate of the memory used by each
+// directory in a dirMap not counting its name
+const dirMapEntryOverhead = 64
+
+// spillRecord is a directory as stored in a spill file
+type spillRecord struct {
+	Dir  string
+	Sent bool
+}
+
+// spill writes the directories in the dirMap sorted by name to a new
+// temporary file and empties the map. Call with the mutex held.
+func (dm *dirMap) spill() (err error) {
+	out, err := ioutil.TempFile("", "rclone-fast-list-spill-")
+	if err != nil {
+		return errors.Wrap(err, "failed to make --fast-list spill file")
+	}
+	dm.spills = append(dm.spills, out.Name())
+	defer fs.CheckClose(out, &err)
+	dirs := make([]string, 0, len(dm.m))
+	for dir := range dm.m {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	buf := bufio.NewWriter(out)
+	enc := gob.NewEncoder(buf)
+	for _, dir := range dirs {
+		err = enc.Encode(spillRecord{Dir: dir, Sent: dm.m[dir]})
+		if err != nil {
+			return errors.Wrap(err, "failed to write --fast-list spill file")
+		}
+	}
+	err = buf.Flush()
+	if err != nil {
+		return errors.Wrap(err, "failed to write --fast-list spill file")
+	}
+	fs.Debugf(nil, "Spilled %d directories using about %v to %q", len(dirs), fs.SizeSuffix(dm.size), out.Name())
+	dm.m = make(map[string]bool)
+	dm.size = 0
+	return nil
+}
+
+// removeSpills removes any spill files
+func (dm *dirMap) removeSpills() {
+	for _, name := range dm.spills {
+		err := os.Remove(name)
+		if err != nil {
+			fs.Errorf(nil, "Failed to remove --fast-list spill file: %v", err)
+		}
+	}
+	dm.spills = nil
+}
+
+// spillReader reads the records of a spill file in order
+type spillReader struct {
+	in  *os.File
+	dec *gob.Decoder
+	rec spillRecord // the current record
+}
+
+// next reads the next record into sr.rec returning io.EOF at the end
+func (sr *spillReader) next() error {
+	// gob doesn't send zero values so clear the record first
+	sr.rec = spillRecord{}
+	return sr.dec.Decode(&sr.rec)
+}
+
+// spillHeap is a heap of spillReaders ordered by their current record
+type spillHeap []*spillReader
+
+func (h spillHeap) Len() int            { return len(h) }
+func (h spillHeap) Less(i, j int) bool  { return h[i].rec.Dir < h[j].rec.Dir }
+func (h spillHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *spillHeap) Push(x interface{}) { *h = append(*h, x.(*spillReader)) }
+func (h *spillHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// sendSpilledEntries merges the spill files and the directories in
+// memory and sends any missing parents to fn in sorted order.
+func (dm *dirMap) sendSpilledEntries(fn fs.ListRCallback) (err error) {
+	if len(dm.m) > 0 {
+		err = dm.spill()
+		if err != nil {
+			return err
+		}
+	}
+	h := make(spillHeap, 0, len(dm.spills))
+	defer func() {
+		for _, sr := range h {
+			fs.CheckClose(sr.in, &err)
+		}
+	}()
+	for _, name := range dm.spills {
+		in, err := os.Open(name)
+		if err != nil {
+			return errors.Wrap(err, "failed to read --fast-list spill file")
+		}
+		sr := &spillReader{
+			in:  in,
+			dec: gob.NewDecoder(bufio.NewReader(in)),
+		}
+		err = sr.next()
+		if err == io.EOF {
+			_ = in.Close()
+			continue
+		} else if err != nil {
+			_ = in.Close()
+			return errors.Wrap(err, "failed to read --fast-list spill file")
+		}
+		h = append(h, sr)
+	}
+	heap.Init(&h)
+	now := time.Now()
+	list := NewListRHelper(fn)
+	for len(h) > 0 {
+		// Merge all the records for the smallest directory
+		dir := h[0].rec.Dir
+		sent := false
+		for len(h) > 0 && h[0].rec.Dir == dir {
+			sr := h[0]
+			sent = sent || sr.rec.Sent
+			err = sr.next()
+			if err == io.EOF {
+				heap.Pop(&h)
+				err = sr.in.Close()
+				if err != nil {
+					return err
+				}
+			} else if err != nil {
+				return errors.Wrap(err, "failed to read --fast-list spill file")
+			} else {
+				heap.Fix(&h, 0)
+			}
+		}
+		if !sent {
+			err = list.Add(fs.NewDir(dir, now))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return list.Flush()
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/artpar/rclone/fs/dirtree"
 	"github.com/artpar/rclone/fs/filter"
 	"github.com/artpar/rclone/fs/list"
+	"github.com/artpar/rclone/fs/tracing"
 	"github.com/pkg/errors"
 )
 
@@ -178,17 +179,24 @@ func listRwalk(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLe
 // dirMap keeps track of directories made for bucket based remotes.
 // true => directory has been sent
 // false => directory has been seen but not sent
+//
+// If spillAt is >= 0 then the directories are spilled to disk when
+// they use more than spillAt bytes of memory.
 type dirMap struct {
-	mu   sync.Mutex
-	m    map[string]bool
-	root string
+	mu      sync.Mutex
+	m       map[string]bool
+	root    string
+	size    int64    // estimate of the memory used by m
+	spillAt int64    // spill m to disk when size is bigger than this if >= 0
+	spills  []string // names of the spill files in the order written
 }
 
 // make a new dirMap
 func newDirMap(root string) *dirMap {
 	return &dirMap{
-		m:    make(map[string]bool),
-		root: root,
+		m:       make(map[string]bool),
+		root:    root,
+		spillAt: -1,
 	}
 }
 
@@ -209,6 +217,8 @@ func (dm *dirMap) add(dir string, sent bool) {
 				return
 			}
 			// currentSent == false && sent == true so needs overriding
+		} else {
+			dm.size += int64(len(dir)) + dirMapEntryOverhead
 		}
 		dm.m[dir] = sent
 		// Add parents in as unsent
@@ -240,11 +250,17 @@ func (dm *dirMap) addEntries(entries fs.DirEntries) error {
 			return errors.Errorf("unknown object type %T", entry)
 		}
 	}
+	if dm.spillAt >= 0 && dm.size > dm.spillAt {
+		return dm.spill()
+	}
 	return nil
 }
 
 // send any missing parents to fn
 func (dm *dirMap) sendEntries(fn fs.ListRCallback) (err error) {
+	if len(dm.spills) > 0 {
+		return dm.sendSpilledEntries(fn)
+	}
 	// Count the strings first so we allocate the minimum memory
 	n := 0
 	for _, sent := range dm.m {
@@ -276,7 +292,7 @@ func (dm *dirMap) sendEntries(fn fs.ListRCallback) (err error) {
 }
 
 // listR walks the file tree using ListR
-func listR(ctx context.Context, f fs.Fs, path string, includeAll bool, listType ListType, fn fs.ListRCallback, doListR fs.ListRFn, synthesizeDirs bool) error {
+func listR(ctx context.Context, f fs.Fs, path string, includeAll bool, listType ListType, fn fs.ListRCallback, doListR fs.ListRFn, synthesizeDirs bool) (err error) {
 	fi := filter.GetConfig(ctx)
 	includeDirectory := fi.IncludeDirectory(ctx, f)
 	if !includeAll {
@@ -285,9 +301,18 @@ func listR(ctx context.Context, f fs.Fs, path string, includeAll bool, listType
 	var dm *dirMap
 	if synthesizeDirs {
 		dm = newDirMap(path)
+		dm.spillAt = int64(fs.GetConfig(ctx).FastListSpill)
+		defer dm.removeSpills()
 	}
 	var mu sync.Mutex
-	err := doListR(ctx, path, func(entries fs.DirEntries) (err error) {
+	listCtx, span := tracing.Start(ctx, "list")
+	span.SetAttribute("fs", fs.ConfigString(f))
+	span.SetAttribute("dir", path)
+	span.SetAttribute("recursive", true)
+	defer func() {
+		span.End(err)
+	}()
+	err = doListR(listCtx, path, func(entries fs.DirEntries) (err error) {
 		if synthesizeDirs {
 			err = dm.addEntries(entries)
 			if err != nil {
@@ -450,8 +475,16 @@ func walk(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel i
 	return <-errs
 }
 
+// walkRDirTree lists f with listR into a DirTree.
+//
+// This keeps all the entries in memory. They aren't spilled to disk
+// with --fast-list-spill as they can't be serialized, so only the
+// directories found by listR are.
 func walkRDirTree(ctx context.Context, f fs.Fs, startPath string, includeAll bool, maxLevel int, listR fs.ListRFn) (dirtree.DirTree, error) {
 	fi := filter.GetConfig(ctx)
+	if fs.GetConfig(ctx).FastListSpill >= 0 {
+		fs.Debugf(f, "--fast-list-spill only spills the directories - the objects are kept in memory to build the directory tree")
+	}
 	dirs := dirtree.New()
 	// Entries can come in arbitrary order. We use toPrune to keep
 	// all directories to exclude later.
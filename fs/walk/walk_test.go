@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"sync"
 	"testing"
@@ -959,3 +960,47 @@ func TestDirMapSendEntries(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []string(nil), got)
 }
+
+func TestDirMapSpill(t *testing.T) {
+	var got []string
+	callback := func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			got = append(got, entry.Remote())
+		}
+		return nil
+	}
+
+	dm := newDirMap("")
+	dm.spillAt = 0
+	defer dm.removeSpills()
+	require.NoError(t, dm.addEntries(fs.DirEntries{
+		mockobject.Object("dir/a"),
+		mockobject.Object("dir3/sub/b"),
+	}))
+	require.NoError(t, dm.addEntries(fs.DirEntries{
+		mockdir.New("dir"),
+		mockobject.Object("dir1/a"),
+	}))
+	dm.spillAt = -1
+	require.NoError(t, dm.addEntries(fs.DirEntries{
+		mockobject.Object("dir2/a"),
+		mockobject.Object("dir3/c"),
+	}))
+	assert.Equal(t, 2, len(dm.spills))
+	assert.Equal(t, map[string]bool{"dir2": false, "dir3": false}, dm.m)
+
+	require.NoError(t, dm.sendEntries(callback))
+	assert.Equal(t, []string{
+		"dir1",
+		"dir2",
+		"dir3",
+		"dir3/sub",
+	}, got)
+
+	spills := dm.spills
+	dm.removeSpills()
+	for _, name := range spills {
+		_, err := os.Stat(name)
+		assert.True(t, os.IsNotExist(err))
+	}
+}
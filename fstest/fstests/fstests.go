@@ -290,11 +290,12 @@ type Opt struct {
 	SkipFsMatch                  bool     // if set skip exact matching of Fs value
 	TiersToTest                  []string // List of tiers which can be tested in setTier test
 	ChunkedUpload                ChunkedUploadConfig
-	UnimplementableFsMethods     []string // List of methods which can't be implemented in this wrapping Fs
-	UnimplementableObjectMethods []string // List of methods which can't be implemented in this wrapping Fs
-	SkipFsCheckWrap              bool     // if set skip FsCheckWrap
-	SkipObjectCheckWrap          bool     // if set skip ObjectCheckWrap
-	SkipInvalidUTF8              bool     // if set skip invalid UTF-8 checks
+	UnimplementableFsMethods     []string          // List of methods which can't be implemented in this wrapping Fs
+	UnimplementableObjectMethods []string          // List of methods which can't be implemented in this wrapping Fs
+	SkipFsCheckWrap              bool              // if set skip FsCheckWrap
+	SkipObjectCheckWrap          bool              // if set skip ObjectCheckWrap
+	SkipInvalidUTF8              bool              // if set skip invalid UTF-8 checks
+	Quirks                       map[string]string // known quirks of the backend, name to description, for the conformance report
 }
 
 // returns true if x is found in ss
@@ -356,7 +357,7 @@ func Run(t *testing.T, opt *Opt) {
 		purged               bool // whether the dir has been purged or not
 		ctx                  = context.Background()
 		ci                   = fs.GetConfig(ctx)
-		unwrappableFsMethods = []string{"Command"} // these Fs methods don't need to be wrapped ever
+		unwrappableFsMethods = []string{"Command", "HardLink"} // these Fs methods don't need to be wrapped ever
 	)
 
 	if strings.HasSuffix(os.Getenv("RCLONE_CONFIG"), "/notfound") && *fstest.RemoteName == "" {
@@ -444,8 +445,11 @@ func Run(t *testing.T, opt *Opt) {
 	// Skip the rest if it failed
 	skipIfNotOk(t)
 
+	// Make the conformance report if required
+	report := newReport(t, f, remoteName, opt)
+
 	// Check to see if Fs that wrap other Fs implement all the optional methods
-	t.Run("FsCheckWrap", func(t *testing.T) {
+	report.run(t, "FsCheckWrap", func(t *testing.T) {
 		skipIfNotOk(t)
 		if opt.SkipFsCheckWrap {
 			t.Skip("Skipping FsCheckWrap on this Fs")
@@ -476,7 +480,7 @@ func Run(t *testing.T, opt *Opt) {
 	})
 
 	// Check to see if Fs advertises commands and they work and have docs
-	t.Run("FsCommand", func(t *testing.T) {
+	report.run(t, "FsCommand", func(t *testing.T) {
 		skipIfNotOk(t)
 		doCommand := f.Features().Command
 		if doCommand == nil {
@@ -492,7 +496,7 @@ func Run(t *testing.T, opt *Opt) {
 	})
 
 	// TestFsRmdirNotFound tests deleting a non existent directory
-	t.Run("FsRmdirNotFound", func(t *testing.T) {
+	report.run(t, "FsRmdirNotFound", func(t *testing.T) {
 		skipIfNotOk(t)
 		if isBucketBasedButNotRoot(f) {
 			t.Skip("Skipping test as non root bucket based remote")
@@ -507,14 +511,14 @@ func Run(t *testing.T, opt *Opt) {
 	fstest.CheckListing(t, f, []fstest.Item{})
 
 	// TestFsString tests the String method
-	t.Run("FsString", func(t *testing.T) {
+	report.run(t, "FsString", func(t *testing.T) {
 		skipIfNotOk(t)
 		str := f.String()
 		require.NotEqual(t, "", str)
 	})
 
 	// TestFsName tests the Name method
-	t.Run("FsName", func(t *testing.T) {
+	report.run(t, "FsName", func(t *testing.T) {
 		skipIfNotOk(t)
 		got := removeConfigID(f.Name())
 		want := remoteName[:strings.LastIndex(remoteName, ":")+1]
@@ -525,7 +529,7 @@ func Run(t *testing.T, opt *Opt) {
 	})
 
 	// TestFsRoot tests the Root method
-	t.Run("FsRoot", func(t *testing.T) {
+	report.run(t, "FsRoot", func(t *testing.T) {
 		skipIfNotOk(t)
 		name := removeConfigID(f.Name()) + ":"
 		root := f.Root()
@@ -538,7 +542,7 @@ func Run(t *testing.T, opt *Opt) {
 	})
 
 	// TestFsRmdirEmpty tests deleting an empty directory
-	t.Run("FsRmdirEmpty", func(t *testing.T) {
+	report.run(t, "FsRmdirEmpty", func(t *testing.T) {
 		skipIfNotOk(t)
 		err := f.Rmdir(ctx, "")
 		require.NoError(t, err)
@@ -547,7 +551,7 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsMkdir tests making a directory
 	//
 	// Tests that require the directory to be made are within this
-	t.Run("FsMkdir", func(t *testing.T) {
+	report.run(t, "FsMkdir", func(t *testing.T) {
 		skipIfNotOk(t)
 
 		err := f.Mkdir(ctx, "")
@@ -558,7 +562,7 @@ func Run(t *testing.T, opt *Opt) {
 		require.NoError(t, err)
 
 		// TestFsMkdirRmdirSubdir tests making and removing a sub directory
-		t.Run("FsMkdirRmdirSubdir", func(t *testing.T) {
+		report.run(t, "FsMkdirRmdirSubdir", func(t *testing.T) {
 			skipIfNotOk(t)
 			dir := "dir/subdir"
 			err := operations.Mkdir(ctx, f, dir)
@@ -575,7 +579,7 @@ func Run(t *testing.T, opt *Opt) {
 		})
 
 		// TestFsListEmpty tests listing an empty directory
-		t.Run("FsListEmpty", func(t *testing.T) {
+		report.run(t, "FsListEmpty", func(t *testing.T) {
 			skipIfNotOk(t)
 			fstest.CheckListing(t, f, []fstest.Item{})
 		})
@@ -594,10 +598,10 @@ func Run(t *testing.T, opt *Opt) {
 			assert.Equal(t, []string{}, objsToNames(objs))
 			assert.Equal(t, []string{}, dirsToNames(dirs))
 		}
-		t.Run("FsListDirEmpty", TestFsListDirEmpty)
+		report.run(t, "FsListDirEmpty", TestFsListDirEmpty)
 
 		// TestFsListRDirEmpty tests listing the directories from an empty directory using ListR
-		t.Run("FsListRDirEmpty", func(t *testing.T) {
+		report.run(t, "FsListRDirEmpty", func(t *testing.T) {
 			defer skipIfNotListR(t)()
 			TestFsListDirEmpty(t)
 		})
@@ -615,10 +619,10 @@ func Run(t *testing.T, opt *Opt) {
 				assert.Equal(t, fs.ErrorDirNotFound, err)
 			}
 		}
-		t.Run("FsListDirNotFound", TestFsListDirNotFound)
+		report.run(t, "FsListDirNotFound", TestFsListDirNotFound)
 
 		// TestFsListRDirNotFound tests listing the directories from an empty directory using ListR
-		t.Run("FsListRDirNotFound", func(t *testing.T) {
+		report.run(t, "FsListRDirNotFound", func(t *testing.T) {
 			defer skipIfNotListR(t)()
 			TestFsListDirNotFound(t)
 		})
@@ -626,7 +630,7 @@ func Run(t *testing.T, opt *Opt) {
 		// FsEncoding tests that file name encodings are
 		// working by uploading a series of unusual files
 		// Must be run in an empty directory
-		t.Run("FsEncoding", func(t *testing.T) {
+		report.run(t, "FsEncoding", func(t *testing.T) {
 			skipIfNotOk(t)
 			if testing.Short() {
 				t.Skip("not running with -short")
@@ -682,7 +686,7 @@ func Run(t *testing.T, opt *Opt) {
 		})
 
 		// TestFsNewObjectNotFound tests not finding an object
-		t.Run("FsNewObjectNotFound", func(t *testing.T) {
+		report.run(t, "FsNewObjectNotFound", func(t *testing.T) {
 			skipIfNotOk(t)
 			// Object in an existing directory
 			o, err := f.NewObject(ctx, "potato")
@@ -700,7 +704,7 @@ func Run(t *testing.T, opt *Opt) {
 		// a file on the remote.
 		//
 		// go test -v -run 'TestIntegration/Test(Setup|Init|FsMkdir|FsPutError)$'
-		t.Run("FsPutError", func(t *testing.T) {
+		report.run(t, "FsPutError", func(t *testing.T) {
 			skipIfNotOk(t)
 
 			var N int64 = 5 * 1024
@@ -725,7 +729,7 @@ func Run(t *testing.T, opt *Opt) {
 			assert.Equal(t, fs.ErrorObjectNotFound, err)
 		})
 
-		t.Run("FsPutZeroLength", func(t *testing.T) {
+		report.run(t, "FsPutZeroLength", func(t *testing.T) {
 			skipIfNotOk(t)
 
 			TestPutLarge(ctx, t, f, &fstest.Item{
@@ -735,7 +739,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 		})
 
-		t.Run("FsOpenWriterAt", func(t *testing.T) {
+		report.run(t, "FsOpenWriterAt", func(t *testing.T) {
 			skipIfNotOk(t)
 			openWriterAt := f.Features().OpenWriterAt
 			if openWriterAt == nil {
@@ -769,7 +773,7 @@ func Run(t *testing.T, opt *Opt) {
 		// propagated
 		//
 		// go test -v -remote TestDrive: -run '^Test(Setup|Init|FsChangeNotify)$' -verbose
-		t.Run("FsChangeNotify", func(t *testing.T) {
+		report.run(t, "FsChangeNotify", func(t *testing.T) {
 			skipIfNotOk(t)
 
 			// Check have ChangeNotify
@@ -858,7 +862,7 @@ func Run(t *testing.T, opt *Opt) {
 		// TestFsPut files writes file1, file2 and tests an update
 		//
 		// Tests that require file1, file2 are within this
-		t.Run("FsPutFiles", func(t *testing.T) {
+		report.run(t, "FsPutFiles", func(t *testing.T) {
 			skipIfNotOk(t)
 			file1Contents, _ = testPut(ctx, t, f, &file1)
 			/* file2Contents = */ testPut(ctx, t, f, &file2)
@@ -908,17 +912,17 @@ func Run(t *testing.T, opt *Opt) {
 					list(dir, expectedDirNames, expectedObjNames)
 				}
 			}
-			t.Run("FsListDirFile2", TestFsListDirFile2)
+			report.run(t, "FsListDirFile2", TestFsListDirFile2)
 
 			// TestFsListRDirFile2 tests the files are correctly uploaded by doing
 			// Depth 1 directory listings using ListR
-			t.Run("FsListRDirFile2", func(t *testing.T) {
+			report.run(t, "FsListRDirFile2", func(t *testing.T) {
 				defer skipIfNotListR(t)()
 				TestFsListDirFile2(t)
 			})
 
 			// Test the files are all there with walk.ListR recursive listings
-			t.Run("FsListR", func(t *testing.T) {
+			report.run(t, "FsListR", func(t *testing.T) {
 				skipIfNotOk(t)
 				objs, dirs, err := walk.GetAll(ctx, f, "", true, -1)
 				require.NoError(t, err)
@@ -936,7 +940,7 @@ func Run(t *testing.T, opt *Opt) {
 
 			// Test the files are all there with
 			// walk.ListR recursive listings on a sub dir
-			t.Run("FsListRSubdir", func(t *testing.T) {
+			report.run(t, "FsListRSubdir", func(t *testing.T) {
 				skipIfNotOk(t)
 				objs, dirs, err := walk.GetAll(ctx, f, path.Dir(path.Dir(path.Dir(path.Dir(file2.Path)))), true, -1)
 				require.NoError(t, err)
@@ -959,10 +963,10 @@ func Run(t *testing.T, opt *Opt) {
 				require.NoError(t, err)
 				assert.Contains(t, dirsToNames(dirs), subRemoteLeaf, "Remote leaf not found")
 			}
-			t.Run("FsListDirRoot", TestFsListDirRoot)
+			report.run(t, "FsListDirRoot", TestFsListDirRoot)
 
 			// TestFsListRDirRoot tests that DirList works in the root using ListR
-			t.Run("FsListRDirRoot", func(t *testing.T) {
+			report.run(t, "FsListRDirRoot", func(t *testing.T) {
 				defer skipIfNotListR(t)()
 				TestFsListDirRoot(t)
 			})
@@ -984,10 +988,10 @@ func Run(t *testing.T, opt *Opt) {
 				assert.Equal(t, fileName, objs[0].Remote())
 				require.Len(t, dirs, 0)
 			}
-			t.Run("FsListSubdir", TestFsListSubdir)
+			report.run(t, "FsListSubdir", TestFsListSubdir)
 
 			// TestFsListRSubdir tests List works for a subdirectory using ListR
-			t.Run("FsListRSubdir", func(t *testing.T) {
+			report.run(t, "FsListRSubdir", func(t *testing.T) {
 				defer skipIfNotListR(t)()
 				TestFsListSubdir(t)
 			})
@@ -1003,49 +1007,49 @@ func Run(t *testing.T, opt *Opt) {
 				assert.Equal(t, []string{file1.Path}, objsToNames(objs))
 				assert.Equal(t, []string{"hello? sausage", "hello? sausage/êé"}, dirsToNames(dirs))
 			}
-			t.Run("FsListLevel2", TestFsListLevel2)
+			report.run(t, "FsListLevel2", TestFsListLevel2)
 
 			// TestFsListRLevel2 tests List works for 2 levels using ListR
-			t.Run("FsListRLevel2", func(t *testing.T) {
+			report.run(t, "FsListRLevel2", func(t *testing.T) {
 				defer skipIfNotListR(t)()
 				TestFsListLevel2(t)
 			})
 
 			// TestFsListFile1 tests file present
-			t.Run("FsListFile1", func(t *testing.T) {
+			report.run(t, "FsListFile1", func(t *testing.T) {
 				skipIfNotOk(t)
 				fstest.CheckListing(t, f, []fstest.Item{file1, file2})
 			})
 
 			// TestFsNewObject tests NewObject
-			t.Run("FsNewObject", func(t *testing.T) {
+			report.run(t, "FsNewObject", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				file1.Check(t, obj, f.Precision())
 			})
 
 			// FsNewObjectCaseInsensitive tests NewObject on a case insensitive file system
-			t.Run("FsNewObjectCaseInsensitive", func(t *testing.T) {
+			report.run(t, "FsNewObjectCaseInsensitive", func(t *testing.T) {
 				skipIfNotOk(t)
 				if !f.Features().CaseInsensitive {
 					t.Skip("Not Case Insensitive")
 				}
 				obj := findObject(ctx, t, f, toUpperASCII(file1.Path))
 				file1.Check(t, obj, f.Precision())
-				t.Run("Dir", func(t *testing.T) {
+				report.run(t, "Dir", func(t *testing.T) {
 					obj := findObject(ctx, t, f, toUpperASCII(file2.Path))
 					file2.Check(t, obj, f.Precision())
 				})
 			})
 
 			// TestFsListFile1and2 tests two files present
-			t.Run("FsListFile1and2", func(t *testing.T) {
+			report.run(t, "FsListFile1and2", func(t *testing.T) {
 				skipIfNotOk(t)
 				fstest.CheckListing(t, f, []fstest.Item{file1, file2})
 			})
 
 			// TestFsNewObjectDir tests NewObject on a directory which should produce an error
-			t.Run("FsNewObjectDir", func(t *testing.T) {
+			report.run(t, "FsNewObjectDir", func(t *testing.T) {
 				skipIfNotOk(t)
 				dir := path.Dir(file2.Path)
 				obj, err := f.NewObject(ctx, dir)
@@ -1054,7 +1058,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestFsPurge tests Purge
-			t.Run("FsPurge", func(t *testing.T) {
+			report.run(t, "FsPurge", func(t *testing.T) {
 				skipIfNotOk(t)
 
 				// Check have Purge
@@ -1091,7 +1095,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestFsCopy tests Copy
-			t.Run("FsCopy", func(t *testing.T) {
+			report.run(t, "FsCopy", func(t *testing.T) {
 				skipIfNotOk(t)
 
 				// Check have Copy
@@ -1125,7 +1129,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestFsMove tests Move
-			t.Run("FsMove", func(t *testing.T) {
+			report.run(t, "FsMove", func(t *testing.T) {
 				skipIfNotOk(t)
 
 				// Check have Move
@@ -1196,7 +1200,7 @@ func Run(t *testing.T, opt *Opt) {
 			// TestFsDirMove tests DirMove
 			//
 			// go test -v -run 'TestIntegration/Test(Setup|Init|FsMkdir|FsPutFile1|FsPutFile2|FsUpdateFile1|FsDirMove)$
-			t.Run("FsDirMove", func(t *testing.T) {
+			report.run(t, "FsDirMove", func(t *testing.T) {
 				skipIfNotOk(t)
 
 				// Check have DirMove
@@ -1254,7 +1258,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestFsRmdirFull tests removing a non empty directory
-			t.Run("FsRmdirFull", func(t *testing.T) {
+			report.run(t, "FsRmdirFull", func(t *testing.T) {
 				skipIfNotOk(t)
 				if isBucketBasedButNotRoot(f) {
 					t.Skip("Skipping test as non root bucket based remote")
@@ -1264,7 +1268,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestFsPrecision tests the Precision of the Fs
-			t.Run("FsPrecision", func(t *testing.T) {
+			report.run(t, "FsPrecision", func(t *testing.T) {
 				skipIfNotOk(t)
 				precision := f.Precision()
 				if precision == fs.ModTimeNotSupported {
@@ -1277,7 +1281,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestObjectString tests the Object String method
-			t.Run("ObjectString", func(t *testing.T) {
+			report.run(t, "ObjectString", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				assert.Equal(t, file1.Path, obj.String())
@@ -1287,7 +1291,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestObjectFs tests the object can be found
-			t.Run("ObjectFs", func(t *testing.T) {
+			report.run(t, "ObjectFs", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				// If this is set we don't do the direct comparison of
@@ -1306,14 +1310,14 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestObjectRemote tests the Remote is correct
-			t.Run("ObjectRemote", func(t *testing.T) {
+			report.run(t, "ObjectRemote", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				assert.Equal(t, file1.Path, obj.Remote())
 			})
 
 			// TestObjectHashes checks all the hashes the object supports
-			t.Run("ObjectHashes", func(t *testing.T) {
+			report.run(t, "ObjectHashes", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				file1.CheckHashes(t, obj)
@@ -1325,10 +1329,10 @@ func Run(t *testing.T, opt *Opt) {
 				obj := findObject(ctx, t, f, file1.Path)
 				file1.CheckModTime(t, obj, obj.ModTime(ctx), f.Precision())
 			}
-			t.Run("ObjectModTime", TestObjectModTime)
+			report.run(t, "ObjectModTime", TestObjectModTime)
 
 			// TestObjectMimeType tests the MimeType of the object is correct
-			t.Run("ObjectMimeType", func(t *testing.T) {
+			report.run(t, "ObjectMimeType", func(t *testing.T) {
 				skipIfNotOk(t)
 				features := f.Features()
 				obj := findObject(ctx, t, f, file1.Path)
@@ -1350,7 +1354,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestObjectSetModTime tests that SetModTime works
-			t.Run("ObjectSetModTime", func(t *testing.T) {
+			report.run(t, "ObjectSetModTime", func(t *testing.T) {
 				skipIfNotOk(t)
 				newModTime := fstest.Time("2011-12-13T14:15:16.999999999Z")
 				obj := findObject(ctx, t, f, file1.Path)
@@ -1367,21 +1371,21 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestObjectSize tests that Size works
-			t.Run("ObjectSize", func(t *testing.T) {
+			report.run(t, "ObjectSize", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				assert.Equal(t, file1.Size, obj.Size())
 			})
 
 			// TestObjectOpen tests that Open works
-			t.Run("ObjectOpen", func(t *testing.T) {
+			report.run(t, "ObjectOpen", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				assert.Equal(t, file1Contents, readObject(ctx, t, obj, -1), "contents of file1 differ")
 			})
 
 			// TestObjectOpenSeek tests that Open works with SeekOption
-			t.Run("ObjectOpenSeek", func(t *testing.T) {
+			report.run(t, "ObjectOpenSeek", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				assert.Equal(t, file1Contents[50:], readObject(ctx, t, obj, -1, &fs.SeekOption{Offset: 50}), "contents of file1 differ after seek")
@@ -1390,7 +1394,7 @@ func Run(t *testing.T, opt *Opt) {
 			// TestObjectOpenRange tests that Open works with RangeOption
 			//
 			// go test -v -run 'TestIntegration/Test(Setup|Init|FsMkdir|FsPutFile1|FsPutFile2|FsUpdateFile1|ObjectOpenRange)$'
-			t.Run("ObjectOpenRange", func(t *testing.T) {
+			report.run(t, "ObjectOpenRange", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				for _, test := range []struct {
@@ -1411,14 +1415,14 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestObjectPartialRead tests that reading only part of the object does the correct thing
-			t.Run("ObjectPartialRead", func(t *testing.T) {
+			report.run(t, "ObjectPartialRead", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				assert.Equal(t, file1Contents[:50], readObject(ctx, t, obj, 50), "contents of file1 differ after limited read")
 			})
 
 			// TestObjectUpdate tests that Update works
-			t.Run("ObjectUpdate", func(t *testing.T) {
+			report.run(t, "ObjectUpdate", func(t *testing.T) {
 				skipIfNotOk(t)
 				contents := random.String(200)
 				buf := bytes.NewBufferString(contents)
@@ -1445,7 +1449,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestObjectStorable tests that Storable works
-			t.Run("ObjectStorable", func(t *testing.T) {
+			report.run(t, "ObjectStorable", func(t *testing.T) {
 				skipIfNotOk(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				require.NotNil(t, !obj.Storable(), "Expecting object to be storable")
@@ -1453,7 +1457,7 @@ func Run(t *testing.T, opt *Opt) {
 
 			// TestFsIsFile tests that an error is returned along with a valid fs
 			// which points to the parent directory.
-			t.Run("FsIsFile", func(t *testing.T) {
+			report.run(t, "FsIsFile", func(t *testing.T) {
 				skipIfNotOk(t)
 				remoteName := subRemoteName + "/" + file2.Path
 				file2Copy := file2
@@ -1471,7 +1475,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestFsIsFileNotFound tests that an error is not returned if no object is found
-			t.Run("FsIsFileNotFound", func(t *testing.T) {
+			report.run(t, "FsIsFileNotFound", func(t *testing.T) {
 				skipIfNotOk(t)
 				remoteName := subRemoteName + "/not found.txt"
 				fileRemote, err := fs.NewFs(context.Background(), remoteName)
@@ -1480,7 +1484,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// Test that things work from the root
-			t.Run("FromRoot", func(t *testing.T) {
+			report.run(t, "FromRoot", func(t *testing.T) {
 				if features := f.Features(); features.BucketBased && !features.BucketBasedRootOK {
 					t.Skip("Can't list from root on this remote")
 				}
@@ -1512,19 +1516,19 @@ func Run(t *testing.T, opt *Opt) {
 				}
 
 				// Check that we can see file1 and file2 from the root
-				t.Run("List", func(t *testing.T) {
+				report.run(t, "List", func(t *testing.T) {
 					fstest.CheckListingWithRoot(t, rootRemote, configLeaf, []fstest.Item{file1Root, file2Root}, dirs, rootRemote.Precision())
 				})
 
 				// Check that that listing the entries is OK
-				t.Run("ListEntries", func(t *testing.T) {
+				report.run(t, "ListEntries", func(t *testing.T) {
 					entries, err := rootRemote.List(context.Background(), configLeaf)
 					require.NoError(t, err)
 					fstest.CompareItems(t, entries, []fstest.Item{file1Root}, dirs[len(dirs)-1:], rootRemote.Precision(), "ListEntries")
 				})
 
 				// List the root with ListR
-				t.Run("ListR", func(t *testing.T) {
+				report.run(t, "ListR", func(t *testing.T) {
 					doListR := rootRemote.Features().ListR
 					if doListR == nil {
 						t.Skip("FS has no ListR interface")
@@ -1563,7 +1567,7 @@ func Run(t *testing.T, opt *Opt) {
 				})
 
 				// Create a new file
-				t.Run("Put", func(t *testing.T) {
+				report.run(t, "Put", func(t *testing.T) {
 					file3Root := fstest.Item{
 						ModTime: time.Now(),
 						Path:    path.Join(configLeaf, "created from root.txt"),
@@ -1572,7 +1576,7 @@ func Run(t *testing.T, opt *Opt) {
 					fstest.CheckListingWithRoot(t, rootRemote, configLeaf, []fstest.Item{file1Root, file2Root, file3Root}, nil, rootRemote.Precision())
 
 					// And then remove it
-					t.Run("Remove", func(t *testing.T) {
+					report.run(t, "Remove", func(t *testing.T) {
 						require.NoError(t, file3Obj.Remove(context.Background()))
 						fstest.CheckListingWithRoot(t, rootRemote, configLeaf, []fstest.Item{file1Root, file2Root}, nil, rootRemote.Precision())
 					})
@@ -1581,7 +1585,7 @@ func Run(t *testing.T, opt *Opt) {
 
 			// TestPublicLink tests creation of sharable, public links
 			// go test -v -run 'TestIntegration/Test(Setup|Init|FsMkdir|FsPutFile1|FsPutFile2|FsUpdateFile1|PublicLink)$'
-			t.Run("PublicLink", func(t *testing.T) {
+			report.run(t, "PublicLink", func(t *testing.T) {
 				skipIfNotOk(t)
 
 				doPublicLink := f.Features().PublicLink
@@ -1643,7 +1647,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestSetTier tests SetTier and GetTier functionality
-			t.Run("SetTier", func(t *testing.T) {
+			report.run(t, "SetTier", func(t *testing.T) {
 				skipIfNotSetTier(t)
 				obj := findObject(ctx, t, f, file1.Path)
 				setter, ok := obj.(fs.SetTierer)
@@ -1664,7 +1668,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// Check to see if Fs that wrap other Objects implement all the optional methods
-			t.Run("ObjectCheckWrap", func(t *testing.T) {
+			report.run(t, "ObjectCheckWrap", func(t *testing.T) {
 				skipIfNotOk(t)
 				if opt.SkipObjectCheckWrap {
 					t.Skip("Skipping FsCheckWrap on this Fs")
@@ -1683,7 +1687,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestObjectRemove tests Remove
-			t.Run("ObjectRemove", func(t *testing.T) {
+			report.run(t, "ObjectRemove", func(t *testing.T) {
 				skipIfNotOk(t)
 				// remove file1
 				obj := findObject(ctx, t, f, file1.Path)
@@ -1694,7 +1698,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestAbout tests the About optional interface
-			t.Run("ObjectAbout", func(t *testing.T) {
+			report.run(t, "ObjectAbout", func(t *testing.T) {
 				skipIfNotOk(t)
 
 				// Check have About
@@ -1715,7 +1719,7 @@ func Run(t *testing.T, opt *Opt) {
 			// TestFsPutStream tests uploading files when size isn't known in advance.
 			// This may trigger large buffer allocation in some backends, keep it
 			// close to the end of suite. (See fs/operations/xtra_operations_test.go)
-			t.Run("FsPutStream", func(t *testing.T) {
+			report.run(t, "FsPutStream", func(t *testing.T) {
 				skipIfNotOk(t)
 				if f.Features().PutStream == nil {
 					t.Skip("FS has no PutStream interface")
@@ -1757,7 +1761,7 @@ func Run(t *testing.T, opt *Opt) {
 			})
 
 			// TestInternal calls InternalTest() on the Fs
-			t.Run("Internal", func(t *testing.T) {
+			report.run(t, "Internal", func(t *testing.T) {
 				skipIfNotOk(t)
 				if it, ok := f.(InternalTester); ok {
 					it.InternalTest(t)
@@ -1771,7 +1775,7 @@ func Run(t *testing.T, opt *Opt) {
 		// TestFsPutChunked may trigger large buffer allocation with
 		// some backends (see fs/operations/xtra_operations_test.go),
 		// keep it closer to the end of suite.
-		t.Run("FsPutChunked", func(t *testing.T) {
+		report.run(t, "FsPutChunked", func(t *testing.T) {
 			skipIfNotOk(t)
 			if testing.Short() {
 				t.Skip("not running with -short")
@@ -1882,10 +1886,10 @@ func Run(t *testing.T, opt *Opt) {
 		//
 		// This may trigger large buffer allocation in some backends, keep it
 		// closer to the suite end. (See fs/operations/xtra_operations_test.go)
-		t.Run("FsUploadUnknownSize", func(t *testing.T) {
+		report.run(t, "FsUploadUnknownSize", func(t *testing.T) {
 			skipIfNotOk(t)
 
-			t.Run("FsPutUnknownSize", func(t *testing.T) {
+			report.run(t, "FsPutUnknownSize", func(t *testing.T) {
 				defer func() {
 					assert.Nil(t, recover(), "Fs.Put() should not panic when src.Size() == -1")
 				}()
@@ -1901,7 +1905,7 @@ func Run(t *testing.T, opt *Opt) {
 				// if err != nil: it's okay as long as no panic
 			})
 
-			t.Run("FsUpdateUnknownSize", func(t *testing.T) {
+			report.run(t, "FsUpdateUnknownSize", func(t *testing.T) {
 				unknownSizeUpdateFile := fstest.Item{
 					ModTime: fstest.Time("2002-02-03T04:05:06.499999999Z"),
 					Path:    "unknown-size-update.txt",
@@ -1934,7 +1938,7 @@ func Run(t *testing.T, opt *Opt) {
 		// exists in the absolute root.
 		// This test is added after
 		// https://github.com/artpar/rclone/issues/3164.
-		t.Run("FsRootCollapse", func(t *testing.T) {
+		report.run(t, "FsRootCollapse", func(t *testing.T) {
 			deepRemoteName := subRemoteName + "/deeper/nonexisting/directory"
 			deepRemote, err := fs.NewFs(context.Background(), deepRemoteName)
 			require.NoError(t, err)
@@ -1972,7 +1976,7 @@ func Run(t *testing.T, opt *Opt) {
 		_ = operations.Purge(ctx, f, "")
 	}
 
-	t.Run("FsShutdown", func(t *testing.T) {
+	report.run(t, "FsShutdown", func(t *testing.T) {
 		do := f.Features().Shutdown
 		if do == nil {
 			t.Skip("Shutdown method not supported")
@@ -0,0 +1,175 @@
+// Conformance report for the integration tests
+
+package fstests
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/artpar/rclone/fs"
+)
+
+// ConformanceReport is the file to write the conformance report to
+var ConformanceReport = flag.String("conformance-report", "", "Write a JSON report of which integration tests passed to this file")
+
+// Results of a test in the report
+const (
+	ResultPass = "pass"
+	ResultFail = "fail"
+	ResultSkip = "skip"
+)
+
+// Report is the conformance report for one remote written when the
+// -conformance-report flag is set.
+//
+// It records the optional features the Fs has, the quirks declared
+// in the Opt and the result of each of the integration tests.
+type Report struct {
+	mu                           sync.Mutex
+	prefix                       string            // prefix to remove from the test names
+	Remote                       string            `json:"remote"`
+	Backend                      string            `json:"backend"`
+	Features                     map[string]bool   `json:"features"`
+	Hashes                       []string          `json:"hashes"`
+	Precision                    string            `json:"precision"`
+	Quirks                       map[string]string `json:"quirks"`
+	UnimplementableFsMethods     []string          `json:"unimplementableFsMethods,omitempty"`
+	UnimplementableObjectMethods []string          `json:"unimplementableObjectMethods,omitempty"`
+	Tests                        map[string]string `json:"tests"`
+	Passed                       int               `json:"passed"`
+	Failed                       int               `json:"failed"`
+	Skipped                      int               `json:"skipped"`
+}
+
+var (
+	reportsMu sync.Mutex
+	reports   []*Report // all the reports made in this test run
+)
+
+// newReport makes a Report for the tests run by t against f or
+// returns nil if the -conformance-report flag isn't set
+func newReport(t *testing.T, f fs.Fs, remoteName string, opt *Opt) *Report {
+	if *ConformanceReport == "" {
+		return nil
+	}
+	r := &Report{
+		prefix:                       t.Name() + "/",
+		Remote:                       remoteName,
+		Features:                     f.Features().Enabled(),
+		Precision:                    f.Precision().String(),
+		Quirks:                       opt.quirks(),
+		UnimplementableFsMethods:     opt.UnimplementableFsMethods,
+		UnimplementableObjectMethods: opt.UnimplementableObjectMethods,
+		Tests:                        map[string]string{},
+	}
+	if fsInfo, _, _, _, err := fs.ParseRemote(remoteName); err == nil {
+		r.Backend = fsInfo.Name
+	}
+	for _, ht := range f.Hashes().Array() {
+		r.Hashes = append(r.Hashes, ht.String())
+	}
+	reportsMu.Lock()
+	reports = append(reports, r)
+	reportsMu.Unlock()
+	t.Cleanup(func() {
+		if err := writeReports(*ConformanceReport); err != nil {
+			t.Errorf("failed to write conformance report: %v", err)
+		}
+	})
+	return r
+}
+
+// quirks returns the quirks declared in opt including the ones
+// implied by the other options
+func (opt *Opt) quirks() map[string]string {
+	quirks := map[string]string{}
+	for name, description := range opt.Quirks {
+		quirks[name] = description
+	}
+	if opt.SkipBadWindowsCharacters {
+		quirks["BadWindowsCharacters"] = "characters which are invalid on Windows are not tested"
+	}
+	if opt.SkipInvalidUTF8 {
+		quirks["InvalidUTF8"] = "invalid UTF-8 is not tested"
+	}
+	if opt.SkipFsMatch {
+		quirks["FsMatch"] = "the Fs value is not matched exactly"
+	}
+	return quirks
+}
+
+// run runs fn as the subtest name of t like t.Run, recording its
+// result in the report. r may be nil.
+func (r *Report) run(t *testing.T, name string, fn func(t *testing.T)) bool {
+	return t.Run(name, func(t *testing.T) {
+		r.record(t)
+		fn(t)
+	})
+}
+
+// record records the result of the test t in the report when it has
+// finished. It does nothing if r is nil.
+func (r *Report) record(t *testing.T) {
+	if r == nil {
+		return
+	}
+	t.Cleanup(func() {
+		result := ResultPass
+		switch {
+		case t.Failed():
+			result = ResultFail
+		case t.Skipped():
+			result = ResultSkip
+		}
+		name := strings.TrimPrefix(t.Name(), r.prefix)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if old, found := r.Tests[name]; found {
+			if old == ResultFail || result != ResultFail {
+				return
+			}
+			r.count(old, -1)
+		}
+		r.Tests[name] = result
+		r.count(result, 1)
+	})
+}
+
+// count adds delta to the total for result - call with the mutex held
+func (r *Report) count(result string, delta int) {
+	switch result {
+	case ResultPass:
+		r.Passed += delta
+	case ResultFail:
+		r.Failed += delta
+	case ResultSkip:
+		r.Skipped += delta
+	}
+}
+
+// writeReports writes all the reports made so far to fileName
+func writeReports(fileName string) error {
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Remote < reports[j].Remote
+	})
+	for _, r := range reports {
+		r.mu.Lock()
+	}
+	out, err := json.MarshalIndent(map[string]interface{}{
+		"remotes": reports,
+	}, "", "\t")
+	for _, r := range reports {
+		r.mu.Unlock()
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, out, 0666)
+}
@@ -104,14 +104,18 @@ type oldToken struct {
 }
 
 // GetToken returns the token saved in the config file under
-// section name.
+// section name, or in the keyring or token command if --token-store
+// is set.
 func GetToken(name string, m configmap.Mapper) (*oauth2.Token, error) {
-	tokenString, ok := m.Get(config.ConfigToken)
-	if !ok || tokenString == "" {
+	tokenString, err := getTokenString(name, m)
+	if err != nil {
+		return nil, err
+	}
+	if tokenString == "" {
 		return nil, errors.Errorf("empty token found - please run \"rclone config reconnect %s:\"", name)
 	}
 	token := new(oauth2.Token)
-	err := json.Unmarshal([]byte(tokenString), token)
+	err = json.Unmarshal([]byte(tokenString), token)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +141,8 @@ func GetToken(name string, m configmap.Mapper) (*oauth2.Token, error) {
 	return token, nil
 }
 
-// PutToken stores the token in the config file
+// PutToken stores the token in the config file, or in the keyring or
+// token command if --token-store is set.
 //
 // This saves the config file if it changes
 func PutToken(name string, m configmap.Mapper, token *oauth2.Token, newSection bool) error {
@@ -146,6 +151,14 @@ func PutToken(name string, m configmap.Mapper, token *oauth2.Token, newSection b
 		return err
 	}
 	tokenString := string(tokenBytes)
+	if fs.GetConfig(context.TODO()).TokenStore != "config" {
+		err = putTokenString(name, m, tokenString)
+		if err != nil {
+			return err
+		}
+		fs.Debugf(name, "Saved new token in --token-store")
+		return nil
+	}
 	old, ok := m.Get(config.ConfigToken)
 	if !ok || tokenString != old {
 		m.Set(config.ConfigToken, tokenString)
@@ -167,15 +180,16 @@ type TokenSource struct {
 }
 
 // If token has expired then first try re-reading it from the config
-// file in case a concurrently running rclone has updated it already
+// file (or --token-store) in case a concurrently running rclone has
+// updated it already
 func (ts *TokenSource) reReadToken() bool {
-	tokenString, found := ts.m.Get(config.ConfigToken)
-	if !found || tokenString == "" {
-		fs.Debugf(ts.name, "Failed to read token out of config file")
+	tokenString, err := getTokenString(ts.name, ts.m)
+	if err != nil || tokenString == "" {
+		fs.Debugf(ts.name, "Failed to read token out of config file: %v", err)
 		return false
 	}
 	newToken := new(oauth2.Token)
-	err := json.Unmarshal([]byte(tokenString), newToken)
+	err = json.Unmarshal([]byte(tokenString), newToken)
 	if err != nil {
 		fs.Debugf(ts.name, "Failed to parse token out of config file: %v", err)
 		return false
@@ -0,0 +1,176 @@
+package oauthutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config"
+	"github.com/artpar/rclone/fs/config/configmap"
+	"github.com/pkg/errors"
+)
+
+// keyringService is the service name tokens are stored under in the
+// OS keyring
+const keyringService = "rclone"
+
+// runTokenCommand runs the command in args with stdin as its input
+// returning what it wrote to stdout.
+//
+// If the command fails notFound is called with its stderr and if it
+// returns true the error is ignored and an empty string is returned.
+func runTokenCommand(args []string, stdin string, notFound func(stderr string) bool) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		ers := strings.TrimSpace(stderr.String())
+		if notFound != nil && notFound(ers) {
+			return "", nil
+		}
+		if ers != "" {
+			err = errors.Wrap(err, ers)
+		}
+		return "", errors.Wrapf(err, "%q failed", args[0])
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// keyringConfigPath returns the config file path the tokens in the
+// keyring are stored under so that remotes with the same name in
+// different config files don't share a token
+func keyringConfigPath() string {
+	configPath, err := filepath.Abs(config.ConfigPath)
+	if err != nil {
+		return config.ConfigPath
+	}
+	return configPath
+}
+
+// keyringAccount returns the macOS keychain account name for remote
+// name
+func keyringAccount(name string) string {
+	return keyringConfigPath() + ":" + name
+}
+
+// securityQuote quotes s for use in a `security -i` command line
+func securityQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
+
+// securitySetCommand returns the `security -i` command to store token
+// for account.
+//
+// The token is passed hex encoded on stdin so that it doesn't appear
+// on the command line of the security process.
+func securitySetCommand(account, token string) string {
+	return "add-generic-password -U -s " + securityQuote(keyringService) + " -a " + securityQuote(account) + " -X " + hex.EncodeToString([]byte(token)) + "\n"
+}
+
+// keyringGet reads the token for remote name from the OS keyring
+func keyringGet(name string) (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return runTokenCommand([]string{"security", "find-generic-password", "-s", keyringService, "-a", keyringAccount(name), "-w"}, "", func(stderr string) bool {
+			return strings.Contains(stderr, "could not be found")
+		})
+	case "windows", "plan9", "js":
+		return "", errors.Errorf("--token-store keyring isn't supported on %s - use --token-store command", runtime.GOOS)
+	}
+	// secret-tool returns an error with no output if the token isn't found
+	return runTokenCommand([]string{"secret-tool", "lookup", "service", keyringService, "config", keyringConfigPath(), "remote", name}, "", func(stderr string) bool {
+		return stderr == ""
+	})
+}
+
+// keyringSet stores token for remote name in the OS keyring
+func keyringSet(name, token string) (err error) {
+	switch runtime.GOOS {
+	case "darwin":
+		// use interactive mode so the token is read from stdin
+		_, err = runTokenCommand([]string{"security", "-i"}, securitySetCommand(keyringAccount(name), token), nil)
+		if err == nil {
+			// security -i doesn't set its exit status if the
+			// command fails so check the token was stored
+			var got string
+			got, err = keyringGet(name)
+			if err == nil && got != token {
+				err = errors.New("token wasn't stored in the keychain")
+			}
+		}
+	case "windows", "plan9", "js":
+		err = errors.Errorf("--token-store keyring isn't supported on %s - use --token-store command", runtime.GOOS)
+	default:
+		_, err = runTokenCommand([]string{"secret-tool", "store", "--label=rclone token for " + name, "service", keyringService, "config", keyringConfigPath(), "remote", name}, token, nil)
+	}
+	return err
+}
+
+// getTokenString reads the token for remote name from where
+// --token-store says to keep it, returning an empty string if there
+// isn't one.
+//
+// If the token isn't in the keyring or command store but is in the
+// config file it is moved out of the config file.
+func getTokenString(name string, m configmap.Mapper) (string, error) {
+	ci := fs.GetConfig(context.TODO())
+	var (
+		tokenString string
+		err         error
+	)
+	switch ci.TokenStore {
+	case "keyring":
+		tokenString, err = keyringGet(name)
+	case "command":
+		tokenString, err = runTokenCommand(append(ci.TokenCommand[:len(ci.TokenCommand):len(ci.TokenCommand)], "get", name), "", nil)
+	default:
+		tokenString, _ = m.Get(config.ConfigToken)
+		return tokenString, nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read token from --token-store %s", ci.TokenStore)
+	}
+	if tokenString != "" {
+		return tokenString, nil
+	}
+	// Migrate a token from the config file if there is one
+	tokenString, ok := m.Get(config.ConfigToken)
+	if !ok || tokenString == "" {
+		return "", nil
+	}
+	err = putTokenString(name, m, tokenString)
+	if err != nil {
+		return "", err
+	}
+	m.Set(config.ConfigToken, "")
+	fs.Infof(name, "Moved token from the config file to --token-store %s", ci.TokenStore)
+	return tokenString, nil
+}
+
+// putTokenString stores the token for remote name where --token-store
+// says to keep it.
+func putTokenString(name string, m configmap.Mapper, tokenString string) (err error) {
+	ci := fs.GetConfig(context.TODO())
+	switch ci.TokenStore {
+	case "keyring":
+		err = keyringSet(name, tokenString)
+	case "command":
+		_, err = runTokenCommand(append(ci.TokenCommand[:len(ci.TokenCommand):len(ci.TokenCommand)], "set", name), tokenString, nil)
+	default:
+		m.Set(config.ConfigToken, tokenString)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to store token in --token-store %s", ci.TokenStore)
+	}
+	return nil
+}
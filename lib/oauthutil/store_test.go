@@ -0,0 +1,85 @@
+package oauthutil
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config"
+	"github.com/artpar/rclone/fs/config/configmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenStoreCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test needs a POSIX shell")
+	}
+	dir, err := ioutil.TempDir("", "rclone-token-store")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	ci := fs.GetConfig(context.TODO())
+	oldStore, oldCommand := ci.TokenStore, ci.TokenCommand
+	defer func() {
+		ci.TokenStore, ci.TokenCommand = oldStore, oldCommand
+	}()
+	ci.TokenStore = "command"
+	// Store the tokens in files in dir named after the remote
+	ci.TokenCommand = fs.SpaceSepList{"sh", "-c", `cd "$0" && if [ "$1" = get ]; then cat "$2" 2>/dev/null || true; else cat > "$2"; fi`, dir}
+
+	m := configmap.Simple{}
+	_, err = GetToken("remote", m)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty token")
+
+	token := &oauth2.Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		Expiry:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	require.NoError(t, PutToken("remote", m, token, true))
+	_, found := m.Get(config.ConfigToken)
+	assert.False(t, found, "token shouldn't be in the config")
+	_, err = os.Stat(filepath.Join(dir, "remote"))
+	require.NoError(t, err)
+
+	got, err := GetToken("remote", m)
+	require.NoError(t, err)
+	assert.Equal(t, token.AccessToken, got.AccessToken)
+	assert.Equal(t, token.RefreshToken, got.RefreshToken)
+	assert.True(t, token.Expiry.Equal(got.Expiry))
+
+	// A token in the config file is moved into the store
+	m2 := configmap.Simple{config.ConfigToken: `{"access_token":"access2","token_type":"Bearer","refresh_token":"refresh2","expiry":"2030-01-02T03:04:05Z"}`}
+	got, err = GetToken("remote2", m2)
+	require.NoError(t, err)
+	assert.Equal(t, "access2", got.AccessToken)
+	assert.Equal(t, "", m2[config.ConfigToken])
+	stored, err := ioutil.ReadFile(filepath.Join(dir, "remote2"))
+	require.NoError(t, err)
+	assert.Contains(t, string(stored), "access2")
+
+	// Errors from the command are returned
+	ci.TokenCommand = fs.SpaceSepList{"sh", "-c", "echo potato >&2; exit 1"}
+	_, err = GetToken("remote", m)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "potato")
+	assert.Error(t, PutToken("remote", m, token, false))
+}
+
+func TestSecuritySetCommand(t *testing.T) {
+	assert.Equal(t, `"potato"`, securityQuote("potato"))
+	assert.Equal(t, `"/home/a \"b\"\\c:remote"`, securityQuote(`/home/a "b"\c:remote`))
+	assert.Equal(t,
+		"add-generic-password -U -s \"rclone\" -a \"/config:remote\" -X 7b2261223a317d\n",
+		securitySetCommand("/config:remote", `{"a":1}`))
+}
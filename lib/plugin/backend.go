@@ -0,0 +1,436 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config"
+	"github.com/artpar/rclone/fs/config/configmap"
+	"github.com/artpar/rclone/fs/hash"
+	"github.com/artpar/rclone/lib/encoder"
+	"github.com/artpar/rclone/lib/readers"
+	"github.com/pkg/errors"
+)
+
+// chunkSize is the amount of data sent in each Read or Write call
+const chunkSize = 1024 * 1024
+
+// Fs represents a remote served by an exec plugin
+type Fs struct {
+	name      string       // name of this remote
+	root      string       // the path we are working on
+	features  *fs.Features // optional features
+	conn      *conn        // connection to the plugin
+	precision time.Duration
+	hashes    hash.Set
+	enc       encoder.MultiEncoder // encoding of paths sent to the plugin
+}
+
+// Object describes a file served by an exec plugin
+type Object struct {
+	fs      *Fs               // what this object is part of
+	remote  string            // the remote path
+	size    int64             // size of the object
+	modTime time.Time         // modification time of the object
+	hashes  map[string]string // hashes by name
+	id      string            // ID of the object if known
+}
+
+// newFsFunc returns a NewFs function which starts the plugin in args
+// for each remote passing it the values of options
+func newFsFunc(args []string, options []fs.Option) func(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, error) {
+	return func(ctx context.Context, name, root string, m configmap.Mapper) (fs.Fs, error) {
+		pluginConfig := make(map[string]string, len(options))
+		for _, opt := range options {
+			if value, ok := m.Get(opt.Name); ok {
+				pluginConfig[opt.Name] = value
+			} else {
+				pluginConfig[opt.Name] = fmt.Sprint(opt.Default)
+			}
+		}
+		enc := defaultEnc
+		if value, ok := m.Get(config.ConfigEncoding); ok {
+			err := enc.Set(value)
+			if err != nil {
+				return nil, errors.Wrap(err, "bad encoding")
+			}
+		}
+		c, err := startPlugin(args)
+		if err != nil {
+			return nil, err
+		}
+		root = strings.Trim(root, "/")
+		var info NewFsResponse
+		err = c.call(ctx, "NewFs", NewFsRequest{Name: name, Root: enc.FromStandardPath(root), Config: pluginConfig}, &info)
+		if err != nil {
+			_ = c.close()
+			return nil, errors.Wrap(err, "plugin failed to make remote")
+		}
+		f := &Fs{
+			name:      name,
+			root:      root,
+			conn:      c,
+			precision: info.Precision,
+			enc:       enc,
+		}
+		if f.precision <= 0 {
+			f.precision = time.Nanosecond
+		}
+		for _, name := range info.Hashes {
+			var ht hash.Type
+			if err := ht.Set(name); err != nil {
+				fs.Debugf(f, "Ignoring unknown hash %q", name)
+				continue
+			}
+			f.hashes.Add(ht)
+		}
+		f.features = (&fs.Features{
+			CanHaveEmptyDirectories: info.CanHaveEmptyDirectories,
+			CaseInsensitive:         info.CaseInsensitive,
+		}).Fill(ctx, f)
+		if f.root != "" {
+			// Check to see if the root is a file
+			var entry Entry
+			err = c.call(ctx, "Stat", PathRequest{Path: f.absPath("")}, &entry)
+			if err == nil && !entry.IsDir {
+				f.root = path.Dir(f.root)
+				if f.root == "." {
+					f.root = ""
+				}
+				// return an error with an fs which points to the parent
+				return f, fs.ErrorIsFile
+			}
+		}
+		return f, nil
+	}
+}
+
+// Name of the remote (as passed into NewFs)
+func (f *Fs) Name() string {
+	return f.name
+}
+
+// Root of the remote (as passed into NewFs)
+func (f *Fs) Root() string {
+	return f.root
+}
+
+// String converts this Fs to a string
+func (f *Fs) String() string {
+	return fmt.Sprintf("Plugin %s root '%s'", f.name, f.root)
+}
+
+// Features returns the optional features of this Fs
+func (f *Fs) Features() *fs.Features {
+	return f.features
+}
+
+// Precision of the remote
+func (f *Fs) Precision() time.Duration {
+	return f.precision
+}
+
+// Hashes returns the supported hash sets.
+func (f *Fs) Hashes() hash.Set {
+	return f.hashes
+}
+
+// absPath returns the path on the plugin of remote
+func (f *Fs) absPath(remote string) string {
+	return f.enc.FromStandardPath(path.Join(f.root, remote))
+}
+
+// relPath returns the remote of absPath
+func (f *Fs) relPath(absPath string) string {
+	absPath = f.enc.ToStandardPath(absPath)
+	if f.root == "" {
+		return absPath
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(absPath, f.root), "/")
+}
+
+// newObject makes an object from an Entry
+func (f *Fs) newObject(entry *Entry) *Object {
+	o := &Object{
+		fs:     f,
+		remote: f.relPath(entry.Path),
+	}
+	o.setEntry(entry)
+	return o
+}
+
+// List the objects and directories in dir into entries.  The
+// entries can be returned in any order but should be for a
+// complete directory.
+//
+// dir should be "" to list the root, and should not have
+// trailing slashes.
+//
+// This should return ErrDirNotFound if the directory isn't
+// found.
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	var resp ListResponse
+	err = f.conn.call(ctx, "List", PathRequest{Path: f.absPath(dir)}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Entries {
+		entry := &resp.Entries[i]
+		if entry.IsDir {
+			d := fs.NewDir(f.relPath(entry.Path), entry.ModTime).SetSize(entry.Size).SetID(entry.ID)
+			entries = append(entries, d)
+		} else {
+			entries = append(entries, f.newObject(entry))
+		}
+	}
+	return entries, nil
+}
+
+// NewObject finds the Object at remote.  If it can't be found
+// it returns the error fs.ErrorObjectNotFound.
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	var entry Entry
+	err := f.conn.call(ctx, "Stat", PathRequest{Path: f.absPath(remote)}, &entry)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir {
+		return nil, fs.ErrorObjectNotFound
+	}
+	return f.newObject(&entry), nil
+}
+
+// Put the object
+//
+// Copy the reader in to the new object which is returned.
+//
+// The new object may have been created if an error is returned
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	o := &Object{
+		fs:     f,
+		remote: src.Remote(),
+	}
+	return o, o.Update(ctx, in, src, options...)
+}
+
+// PutStream uploads to the remote path with the modTime given of indeterminate size
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.Put(ctx, in, src, options...)
+}
+
+// Mkdir makes the directory (container, bucket)
+//
+// Shouldn't return an error if it already exists
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
+	return f.conn.call(ctx, "Mkdir", PathRequest{Path: f.absPath(dir)}, &Empty{})
+}
+
+// Rmdir removes the directory (container, bucket) if empty
+//
+// Return an error if it doesn't exist or isn't empty
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
+	return f.conn.call(ctx, "Rmdir", PathRequest{Path: f.absPath(dir)}, &Empty{})
+}
+
+// Shutdown the plugin
+func (f *Fs) Shutdown(ctx context.Context) error {
+	return f.conn.close()
+}
+
+// ------------------------------------------------------------
+
+// setEntry sets the metadata of the object from entry
+func (o *Object) setEntry(entry *Entry) {
+	o.size = entry.Size
+	o.modTime = entry.ModTime
+	o.hashes = entry.Hashes
+	o.id = entry.ID
+}
+
+// Fs returns the parent Fs
+func (o *Object) Fs() fs.Info {
+	return o.fs
+}
+
+// Return a string version
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.remote
+}
+
+// Remote returns the remote path
+func (o *Object) Remote() string {
+	return o.remote
+}
+
+// Hash returns the hash of an object returning a lowercase hex string
+func (o *Object) Hash(ctx context.Context, t hash.Type) (string, error) {
+	if !o.fs.hashes.Contains(t) {
+		return "", hash.ErrUnsupported
+	}
+	return o.hashes[t.String()], nil
+}
+
+// Size returns the size of an object in bytes
+func (o *Object) Size() int64 {
+	return o.size
+}
+
+// ModTime returns the modification time of the object
+func (o *Object) ModTime(ctx context.Context) time.Time {
+	return o.modTime
+}
+
+// SetModTime sets the modification time of the object
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	err := o.fs.conn.call(ctx, "SetModTime", SetModTimeRequest{Path: o.fs.absPath(o.remote), ModTime: modTime}, &Empty{})
+	if err != nil {
+		return err
+	}
+	o.modTime = modTime
+	return nil
+}
+
+// Storable returns if this object is storable
+func (o *Object) Storable() bool {
+	return true
+}
+
+// ID returns the ID of the Object if known, or "" if not
+func (o *Object) ID() string {
+	return o.id
+}
+
+// objectReader reads an object a chunk at a time
+type objectReader struct {
+	ctx       context.Context
+	o         *Object
+	offset    int64  // offset of the next read
+	remaining int64  // bytes left to read or -1 for all
+	buf       []byte // data read but not returned yet
+	eof       bool   // set if the end of the object has been read
+}
+
+// Read data from the object
+func (r *objectReader) Read(p []byte) (n int, err error) {
+	if len(r.buf) == 0 {
+		if r.eof || r.remaining == 0 {
+			return 0, io.EOF
+		}
+		count := int64(chunkSize)
+		if r.remaining >= 0 && r.remaining < count {
+			count = r.remaining
+		}
+		var resp ReadResponse
+		err = r.o.fs.conn.call(r.ctx, "Read", ReadRequest{Path: r.o.fs.absPath(r.o.remote), Offset: r.offset, Count: count}, &resp)
+		if err != nil {
+			return 0, err
+		}
+		n := int64(len(resp.Data))
+		if n < count {
+			r.eof = true
+		}
+		r.offset += n
+		if r.remaining >= 0 {
+			r.remaining -= n
+		}
+		r.buf = resp.Data
+		if n == 0 {
+			return 0, io.EOF
+		}
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close the reader
+func (r *objectReader) Close() error {
+	return nil
+}
+
+// Open an object for read
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.ReadCloser, err error) {
+	var offset, limit int64 = 0, -1
+	for _, option := range options {
+		switch x := option.(type) {
+		case *fs.RangeOption:
+			offset, limit = x.Decode(o.size)
+		case *fs.SeekOption:
+			offset = x.Offset
+		default:
+			if option.Mandatory() {
+				fs.Logf(o, "Unsupported mandatory option: %v", option)
+			}
+		}
+	}
+	return &objectReader{
+		ctx:       ctx,
+		o:         o,
+		offset:    offset,
+		remaining: limit,
+	}, nil
+}
+
+// Update the object with the contents of the io.Reader, modTime and size
+//
+// The new object may have been created if an error is returned
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (err error) {
+	c := o.fs.conn
+	var create CreateResponse
+	err = c.call(ctx, "Create", CreateRequest{Path: o.fs.absPath(o.remote), Size: src.Size(), ModTime: src.ModTime(ctx)}, &create)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			abortErr := c.call(ctx, "Abort", HandleRequest{Handle: create.Handle}, &Empty{})
+			if abortErr != nil {
+				fs.Debugf(o, "Failed to abort upload: %v", abortErr)
+			}
+		}
+	}()
+	buf := make([]byte, chunkSize)
+	for {
+		n, readErr := readers.ReadFill(in, buf)
+		if n > 0 {
+			err = c.call(ctx, "Write", WriteRequest{Handle: create.Handle, Data: buf[:n]}, &Empty{})
+			if err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		} else if readErr != nil {
+			return readErr
+		}
+	}
+	var entry Entry
+	err = c.call(ctx, "Commit", HandleRequest{Handle: create.Handle}, &entry)
+	if err != nil {
+		return err
+	}
+	o.setEntry(&entry)
+	return nil
+}
+
+// Remove an object
+func (o *Object) Remove(ctx context.Context) error {
+	return o.fs.conn.call(ctx, "Remove", PathRequest{Path: o.fs.absPath(o.remote)}, &Empty{})
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs          = &Fs{}
+	_ fs.PutStreamer = &Fs{}
+	_ fs.Shutdowner  = &Fs{}
+	_ fs.Object      = &Object{}
+	_ fs.IDer        = &Object{}
+)
@@ -0,0 +1,309 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/config"
+	"github.com/artpar/rclone/lib/encoder"
+	"github.com/pkg/errors"
+)
+
+// ExecPrefix is the prefix of the file names of exec plugins
+const ExecPrefix = "rclone-backend-"
+
+// defaultEnc is the encoding of paths sent to plugins unless the
+// plugin has its own encoding option. JSON can't carry invalid UTF-8
+// so that is always encoded.
+const defaultEnc = encoder.Base | encoder.EncodeInvalidUtf8
+
+// handshakeTimeout is how long a plugin has to start up and describe
+// itself before it is killed
+var handshakeTimeout = 10 * time.Second
+
+// closeTimeout is how long a plugin has to exit after its stdin is
+// closed before it is killed
+var closeTimeout = 10 * time.Second
+
+func init() {
+	dir := os.Getenv("RCLONE_PLUGIN_PATH")
+	if dir == "" {
+		return
+	}
+	// Errors reading the directory are reported by the Go plugin loader
+	listing, _ := ioutil.ReadDir(dir)
+	// Start the plugins in parallel so that the startup is delayed
+	// by at most handshakeTimeout however many there are
+	type result struct {
+		fileName string
+		info     *fs.RegInfo
+		err      error
+	}
+	var results []*result
+	var wg sync.WaitGroup
+	for _, file := range listing {
+		fileName := file.Name()
+		if file.IsDir() || !strings.HasPrefix(fileName, ExecPrefix) {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(fileName, ExecPrefix), ".exe")
+		res := &result{fileName: fileName}
+		results = append(results, res)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res.info, res.err = handshake(name, []string{filepath.Join(dir, fileName)})
+		}()
+	}
+	wg.Wait()
+	// fs.Register isn't safe for concurrent use so register the
+	// plugins one at a time
+	for _, res := range results {
+		err := res.err
+		if err == nil {
+			err = register(res.info)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load plugin %s: %s\n", res.fileName, err)
+		}
+	}
+}
+
+// Register registers the exec plugin run by the command line in args
+// as a backend called name.
+//
+// The plugin is run once to read its description and options and then
+// once for each remote made from it.
+func Register(name string, args ...string) error {
+	if _, err := fs.Find(name); err == nil {
+		return errors.Errorf("backend %q already exists", name)
+	}
+	info, err := handshake(name, args)
+	if err != nil {
+		return err
+	}
+	return register(info)
+}
+
+// register info as a backend unless one with the same name exists
+func register(info *fs.RegInfo) error {
+	if _, err := fs.Find(info.Name); err == nil {
+		return errors.Errorf("backend %q already exists", info.Name)
+	}
+	fs.Register(info)
+	return nil
+}
+
+// handshake runs the exec plugin in args to read its description and
+// options, returning the backend called name to register.
+//
+// This doesn't touch the backend registry so is safe to call
+// concurrently.
+func handshake(name string, args []string) (*fs.RegInfo, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no command supplied")
+	}
+	c, err := startPlugin(args)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+	var info InfoResponse
+	err = c.call(ctx, "Info", Empty{}, &info)
+	if err != nil {
+		_ = c.kill()
+		return nil, errors.Wrap(err, "failed to read plugin info")
+	}
+	closeErr := c.close()
+	if closeErr != nil {
+		return nil, errors.Wrap(closeErr, "plugin failed")
+	}
+	options := make([]fs.Option, 0, len(info.Options)+1)
+	hasEncoding := false
+	for _, opt := range info.Options {
+		hasEncoding = hasEncoding || opt.Name == config.ConfigEncoding
+		options = append(options, fs.Option{
+			Name:       opt.Name,
+			Help:       opt.Help,
+			Default:    opt.Default,
+			Required:   opt.Required,
+			IsPassword: opt.IsPassword,
+			Advanced:   opt.Advanced,
+		})
+	}
+	pluginOptions := options
+	if !hasEncoding {
+		options = append(options, fs.Option{
+			Name:     config.ConfigEncoding,
+			Help:     config.ConfigEncodingHelp,
+			Advanced: true,
+			Default:  defaultEnc,
+		})
+	}
+	description := info.Description
+	if description == "" {
+		description = fmt.Sprintf("External plugin %q", name)
+	}
+	return &fs.RegInfo{
+		Name:        name,
+		Description: description,
+		NewFs:       newFsFunc(args, pluginOptions),
+		Options:     options,
+	}, nil
+}
+
+// conn is a connection to a running plugin
+type conn struct {
+	cmd       *exec.Cmd
+	client    *rpc.Client
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// stdio joins the stdout and stdin of the plugin together
+type stdio struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+// Close stdin which tells the plugin to exit
+func (s stdio) Close() error {
+	return s.WriteCloser.Close()
+}
+
+// startPlugin runs the plugin and connects to it
+func startPlugin(args []string) (*conn, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start plugin")
+	}
+	return &conn{
+		cmd:    cmd,
+		client: jsonrpc.NewClient(stdio{ReadCloser: stdout, WriteCloser: stdin}),
+	}, nil
+}
+
+// call the method on the plugin, returning early if ctx is cancelled
+//
+// reply must be a pointer.
+func (c *conn) call(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	// Decode into a new reply so that a response arriving after
+	// ctx is cancelled doesn't write to reply
+	replyType := reflect.TypeOf(reply).Elem()
+	newReply := reflect.New(replyType)
+	call := c.client.Go(ServiceName+"."+method, args, newReply.Interface(), make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if call.Error != nil {
+		return translateError(call.Error)
+	}
+	reflect.ValueOf(reply).Elem().Set(newReply.Elem())
+	return nil
+}
+
+// kill the plugin and wait for it to exit
+func (c *conn) kill() error {
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	return c.close()
+}
+
+// close the connection and wait for the plugin to exit, killing it if
+// it doesn't exit within closeTimeout
+func (c *conn) close() error {
+	c.closeOnce.Do(func() {
+		_ = c.client.Close()
+		done := make(chan error, 1)
+		go func() {
+			done <- c.cmd.Wait()
+		}()
+		select {
+		case c.closeErr = <-done:
+		case <-time.After(closeTimeout):
+			_ = c.cmd.Process.Kill()
+			<-done
+			c.closeErr = errors.Errorf("plugin didn't exit within %v so was killed", closeTimeout)
+		}
+	})
+	return c.closeErr
+}
+
+// knownErrors are the errors which can be returned from plugins by
+// using the same text
+var knownErrors = []error{
+	fs.ErrorObjectNotFound,
+	fs.ErrorDirNotFound,
+	fs.ErrorDirectoryNotEmpty,
+	fs.ErrorIsFile,
+	fs.ErrorNotAFile,
+	fs.ErrorCantSetModTime,
+	fs.ErrorPermissionDenied,
+	fs.ErrorFileNameTooLong,
+}
+
+// translateError turns errors returned from the plugin into fs errors
+// where possible
+func translateError(err error) error {
+	switch x := err.(type) {
+	case nil:
+		return nil
+	case rpc.ServerError:
+		for _, knownErr := range knownErrors {
+			if string(x) == knownErr.Error() {
+				return knownErr
+			}
+		}
+		return errors.New(string(x))
+	}
+	if err == rpc.ErrShutdown || err == io.ErrUnexpectedEOF {
+		return errors.Wrap(err, "plugin exited")
+	}
+	return err
+}
+
+// Serve serves rcvr as an exec plugin on stdin and stdout, returning
+// when stdin is closed.
+//
+// rcvr should have methods for each call in the protocol in the form
+// required by net/rpc, for example
+//
+//	func (b *MyBackend) List(req plugin.PathRequest, resp *plugin.ListResponse) error
+//
+// This is for writing plugins in Go. Plugins can be written in any
+// language which can serve JSON-RPC 1.0 over stdio.
+func Serve(rcvr interface{}) error {
+	server := rpc.NewServer()
+	err := server.RegisterName(ServiceName, rcvr)
+	if err != nil {
+		return err
+	}
+	server.ServeCodec(jsonrpc.NewServerCodec(stdio{ReadCloser: os.Stdin, WriteCloser: os.Stdout}))
+	return nil
+}
@@ -0,0 +1,314 @@
+package plugin
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fstest/fstests"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPluginDirEnv is set to the directory to serve when the test
+// binary is run as a plugin
+const testPluginDirEnv = "RCLONE_TEST_PLUGIN_DIR"
+
+// testPluginHangEnv is set to make the test binary run as a plugin
+// which never replies
+const testPluginHangEnv = "RCLONE_TEST_PLUGIN_HANG"
+
+// TestMain runs the tests or serves the test plugin
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginHangEnv) != "" {
+		time.Sleep(time.Hour)
+		os.Exit(0)
+	}
+	if dir := os.Getenv(testPluginDirEnv); dir != "" {
+		err := Serve(newTestBackend(dir))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testBackend is a plugin which serves a local directory
+type testBackend struct {
+	dir     string
+	mu      sync.Mutex
+	uploads map[string]*testUpload
+	n       int
+}
+
+// testUpload is an upload in progress
+type testUpload struct {
+	path string
+	req  CreateRequest
+	file *os.File
+}
+
+func newTestBackend(dir string) *testBackend {
+	return &testBackend{
+		dir:     dir,
+		uploads: make(map[string]*testUpload),
+	}
+}
+
+// localPath returns the local path of p
+func (b *testBackend) localPath(p string) string {
+	return filepath.Join(b.dir, filepath.FromSlash(p))
+}
+
+// entry returns the Entry for p
+func (b *testBackend) entry(p string, fi os.FileInfo) (entry Entry, err error) {
+	entry = Entry{
+		Path:    p,
+		IsDir:   fi.IsDir(),
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+	}
+	if entry.IsDir {
+		entry.Size = -1
+		return entry, nil
+	}
+	data, err := ioutil.ReadFile(b.localPath(p))
+	if err != nil {
+		return entry, err
+	}
+	sum := md5.Sum(data)
+	entry.Hashes = map[string]string{"MD5": hex.EncodeToString(sum[:])}
+	return entry, nil
+}
+
+func (b *testBackend) Info(req Empty, resp *InfoResponse) error {
+	resp.Description = "Test plugin"
+	resp.Options = []Option{{Name: "potato", Help: "A test option", Default: "mashed"}}
+	return nil
+}
+
+func (b *testBackend) NewFs(req NewFsRequest, resp *NewFsResponse) error {
+	if req.Config["potato"] != "mashed" {
+		return fmt.Errorf("bad config %v", req.Config)
+	}
+	resp.Hashes = []string{"MD5"}
+	resp.CanHaveEmptyDirectories = true
+	return nil
+}
+
+func (b *testBackend) Stat(req PathRequest, resp *Entry) (err error) {
+	fi, err := os.Stat(b.localPath(req.Path))
+	if os.IsNotExist(err) {
+		return fs.ErrorObjectNotFound
+	} else if err != nil {
+		return err
+	}
+	*resp, err = b.entry(req.Path, fi)
+	return err
+}
+
+func (b *testBackend) List(req PathRequest, resp *ListResponse) error {
+	fis, err := ioutil.ReadDir(b.localPath(req.Path))
+	if err != nil {
+		return fs.ErrorDirNotFound
+	}
+	for _, fi := range fis {
+		entry, err := b.entry(path.Join(req.Path, fi.Name()), fi)
+		if err != nil {
+			return err
+		}
+		resp.Entries = append(resp.Entries, entry)
+	}
+	return nil
+}
+
+func (b *testBackend) Read(req ReadRequest, resp *ReadResponse) error {
+	in, err := os.Open(b.localPath(req.Path))
+	if err != nil {
+		return fs.ErrorObjectNotFound
+	}
+	defer func() { _ = in.Close() }()
+	resp.Data = make([]byte, req.Count)
+	n, err := in.ReadAt(resp.Data, req.Offset)
+	resp.Data = resp.Data[:n]
+	if err == io.EOF {
+		err = nil
+	}
+	return err
+}
+
+func (b *testBackend) Create(req CreateRequest, resp *CreateResponse) error {
+	file, err := ioutil.TempFile("", "rclone-plugin-upload")
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.n++
+	resp.Handle = strconv.Itoa(b.n)
+	b.uploads[resp.Handle] = &testUpload{path: req.Path, req: req, file: file}
+	return nil
+}
+
+// getUpload gets the upload for handle
+func (b *testBackend) getUpload(handle string, remove bool) (*testUpload, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	upload := b.uploads[handle]
+	if upload == nil {
+		return nil, fmt.Errorf("unknown handle %q", handle)
+	}
+	if remove {
+		delete(b.uploads, handle)
+	}
+	return upload, nil
+}
+
+func (b *testBackend) Write(req WriteRequest, resp *Empty) error {
+	upload, err := b.getUpload(req.Handle, false)
+	if err != nil {
+		return err
+	}
+	_, err = upload.file.Write(req.Data)
+	return err
+}
+
+func (b *testBackend) Commit(req HandleRequest, resp *Entry) error {
+	upload, err := b.getUpload(req.Handle, true)
+	if err != nil {
+		return err
+	}
+	err = upload.file.Close()
+	if err != nil {
+		return err
+	}
+	localPath := b.localPath(upload.path)
+	err = os.MkdirAll(filepath.Dir(localPath), 0777)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(upload.file.Name(), localPath)
+	if err != nil {
+		return err
+	}
+	err = os.Chtimes(localPath, upload.req.ModTime, upload.req.ModTime)
+	if err != nil {
+		return err
+	}
+	return b.Stat(PathRequest{Path: upload.path}, resp)
+}
+
+func (b *testBackend) Abort(req HandleRequest, resp *Empty) error {
+	upload, err := b.getUpload(req.Handle, true)
+	if err != nil {
+		return err
+	}
+	_ = upload.file.Close()
+	return os.Remove(upload.file.Name())
+}
+
+func (b *testBackend) Remove(req PathRequest, resp *Empty) error {
+	localPath := b.localPath(req.Path)
+	fi, err := os.Stat(localPath)
+	if err != nil || fi.IsDir() {
+		return fs.ErrorObjectNotFound
+	}
+	return os.Remove(localPath)
+}
+
+func (b *testBackend) Mkdir(req PathRequest, resp *Empty) error {
+	return os.MkdirAll(b.localPath(req.Path), 0777)
+}
+
+func (b *testBackend) Rmdir(req PathRequest, resp *Empty) error {
+	fis, err := ioutil.ReadDir(b.localPath(req.Path))
+	if err != nil {
+		return fs.ErrorDirNotFound
+	}
+	if len(fis) != 0 {
+		return fs.ErrorDirectoryNotEmpty
+	}
+	return os.Remove(b.localPath(req.Path))
+}
+
+func (b *testBackend) SetModTime(req SetModTimeRequest, resp *Empty) error {
+	err := os.Chtimes(b.localPath(req.Path), req.ModTime, req.ModTime)
+	if os.IsNotExist(err) {
+		return fs.ErrorObjectNotFound
+	}
+	return err
+}
+
+// TestIntegration runs integration tests against the test plugin
+func TestIntegration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-plugin-test")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	require.NoError(t, os.Setenv(testPluginDirEnv, dir))
+	defer func() {
+		_ = os.Unsetenv(testPluginDirEnv)
+	}()
+	require.NoError(t, Register("testplugin", os.Args[0]))
+	require.Error(t, Register("testplugin", os.Args[0]))
+	fstests.Run(t, &fstests.Opt{
+		RemoteName: ":testplugin:",
+		NilObject:  (*Object)(nil),
+	})
+}
+
+// TestRegisterTimeout checks a plugin which doesn't reply is killed
+func TestRegisterTimeout(t *testing.T) {
+	require.NoError(t, os.Setenv(testPluginHangEnv, "1"))
+	defer func() {
+		_ = os.Unsetenv(testPluginHangEnv)
+	}()
+	oldTimeout := handshakeTimeout
+	handshakeTimeout = 100 * time.Millisecond
+	defer func() {
+		handshakeTimeout = oldTimeout
+	}()
+	start := time.Now()
+	err := Register("hangplugin", os.Args[0])
+	require.Error(t, err)
+	assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+	assert.True(t, time.Since(start) < 10*time.Second)
+	_, err = fs.Find("hangplugin")
+	assert.Error(t, err)
+}
+
+// TestCallContext checks calls return when the context is cancelled
+func TestCallContext(t *testing.T) {
+	require.NoError(t, os.Setenv(testPluginHangEnv, "1"))
+	defer func() {
+		_ = os.Unsetenv(testPluginHangEnv)
+	}()
+	c, err := startPlugin([]string{os.Args[0]})
+	require.NoError(t, err)
+	defer func() {
+		_ = c.kill()
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	var info InfoResponse
+	err = c.call(ctx, "Info", Empty{}, &info)
+	assert.Equal(t, context.Canceled, err)
+}
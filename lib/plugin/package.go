@@ -7,9 +7,14 @@
 // To create a plugin, write the backend package like it was in-tree
 // but set the package name to "main". Then, build the plugin with
 //
-//     go build -buildmode=plugin -o librcloneplugin_NAME.so
+//	go build -buildmode=plugin -o librcloneplugin_NAME.so
 //
 // where NAME equals the plugin's fs.RegInfo.Name.
+//
+// Any executables in that dir named like rclone-backend-NAME are
+// registered as backends called NAME on all platforms. These are run
+// by rclone and speak JSON-RPC over stdin and stdout as described in
+// protocol.go so can be written in any language.
 package plugin
 
 // Build for plugin for unsupported platforms to stop go complaining
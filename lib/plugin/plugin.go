@@ -1,3 +1,4 @@
+//go:build (darwin || linux) && !gccgo
 // +build darwin linux
 // +build !gccgo
 
@@ -0,0 +1,133 @@
+package plugin
+
+import "time"
+
+// The types in this file define the protocol spoken between rclone
+// and an exec plugin.
+//
+// The plugin is started with its stdin and stdout connected to
+// rclone and serves JSON-RPC 1.0 requests (as implemented by
+// net/rpc/jsonrpc) for the service named by ServiceName, for example
+// "Backend.List". Anything written to stderr is passed through to
+// rclone's stderr.
+//
+// All paths are relative to the root of the storage system with no
+// leading or trailing "/" so "" is the root. Errors are returned as
+// JSON-RPC error strings. Use the text of the fs.Error* errors, for
+// example "object not found" or "directory not found", where
+// appropriate so rclone can recognise them.
+
+// ServiceName is the name of the JSON-RPC service a plugin provides
+const ServiceName = "Backend"
+
+// Option describes a config option the plugin takes
+type Option struct {
+	Name       string
+	Help       string
+	Default    string
+	Required   bool
+	IsPassword bool
+	Advanced   bool
+}
+
+// Empty is used for methods which don't have parameters or results
+type Empty struct{}
+
+// InfoResponse is returned from Backend.Info which is called with
+// Empty to describe the plugin
+type InfoResponse struct {
+	Description string   // one line description of the backend
+	Options     []Option // config options the backend takes
+}
+
+// NewFsRequest is passed to Backend.NewFs to configure the plugin
+//
+// This is called once when the plugin is started before any other
+// methods apart from Backend.Info.
+type NewFsRequest struct {
+	Name   string            // name of the remote
+	Root   string            // root of the remote for information only
+	Config map[string]string // values of the config options
+}
+
+// NewFsResponse is returned from Backend.NewFs to describe the
+// capabilities of the storage system
+type NewFsResponse struct {
+	Precision               time.Duration // precision of modification times, 0 for 1ns or 100 years if not supported
+	Hashes                  []string      // names of the hashes supported, e.g. "MD5"
+	CanHaveEmptyDirectories bool          // set if directories can exist without files in
+	CaseInsensitive         bool          // set if file names are case insensitive
+}
+
+// Entry describes a file or a directory
+type Entry struct {
+	Path    string            // path of the entry
+	IsDir   bool              // set if this is a directory
+	Size    int64             // size in bytes, -1 if unknown
+	ModTime time.Time         // modification time
+	Hashes  map[string]string // hashes of the file contents by hash name
+	ID      string            // optional ID of the entry
+}
+
+// PathRequest is passed to methods which operate on a single path:
+// Backend.Stat, Backend.List, Backend.Mkdir, Backend.Rmdir and
+// Backend.Remove
+//
+// Backend.Stat returns an Entry, Backend.List returns a ListResponse
+// and the others return Empty.
+type PathRequest struct {
+	Path string
+}
+
+// ListResponse is returned from Backend.List with the contents of
+// the directory
+type ListResponse struct {
+	Entries []Entry
+}
+
+// ReadRequest is passed to Backend.Read to read up to Count bytes
+// from Offset in the file at Path
+type ReadRequest struct {
+	Path   string
+	Offset int64
+	Count  int64
+}
+
+// ReadResponse is returned from Backend.Read
+//
+// It should return fewer bytes than requested only at the end of the
+// file.
+type ReadResponse struct {
+	Data []byte
+}
+
+// CreateRequest is passed to Backend.Create to start uploading a file
+type CreateRequest struct {
+	Path    string
+	Size    int64 // size of the upload or -1 if unknown
+	ModTime time.Time
+}
+
+// CreateResponse is returned from Backend.Create
+type CreateResponse struct {
+	Handle string // identifies the upload in subsequent calls
+}
+
+// WriteRequest is passed to Backend.Write to append data to an upload
+type WriteRequest struct {
+	Handle string
+	Data   []byte
+}
+
+// HandleRequest is passed to Backend.Commit to finish an upload, which
+// returns the Entry for the new file, or to Backend.Abort to cancel
+// it, which returns Empty
+type HandleRequest struct {
+	Handle string
+}
+
+// SetModTimeRequest is passed to Backend.SetModTime which returns Empty
+type SetModTimeRequest struct {
+	Path    string
+	ModTime time.Time
+}
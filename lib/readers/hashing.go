@@ -0,0 +1,107 @@
+package readers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/artpar/rclone/fs/hash"
+)
+
+// HashMismatchError is returned when the hash of the data read doesn't
+// match the hash expected
+type HashMismatchError struct {
+	Type hash.Type // type of the hash which didn't match
+	Got  string    // hash of the data read
+	Want string    // hash expected
+}
+
+// Error satisfies the error interface
+func (e *HashMismatchError) Error() string {
+	return fmt.Sprintf("%v hash mismatch: got %q, want %q", e.Type, e.Got, e.Want)
+}
+
+// HashingReader calculates hashes of the data as it is read from a
+// reader. Create one with NewHashingReader or NewVerifyingReader.
+type HashingReader struct {
+	in     io.Reader
+	hasher *hash.MultiHasher
+	want   map[hash.Type]string // hashes to check at EOF, if any
+}
+
+// NewHashingReader returns a HashingReader which calculates the hashes
+// in set of the data read from in.
+func NewHashingReader(in io.Reader, set hash.Set) (*HashingReader, error) {
+	hasher, err := hash.NewMultiHasherTypes(set)
+	if err != nil {
+		return nil, err
+	}
+	return &HashingReader{
+		in:     in,
+		hasher: hasher,
+	}, nil
+}
+
+// NewVerifyingReader returns a HashingReader which checks the data
+// read from in has the hashes in want.
+//
+// When in reaches EOF, Read returns a *HashMismatchError instead of
+// io.EOF if any of the hashes differ. Empty hashes in want are ignored.
+func NewVerifyingReader(in io.Reader, want map[hash.Type]string) (*HashingReader, error) {
+	var set hash.Set
+	for ht, sum := range want {
+		if sum != "" {
+			set.Add(ht)
+		}
+	}
+	hr, err := NewHashingReader(in, set)
+	if err != nil {
+		return nil, err
+	}
+	hr.want = want
+	return hr, nil
+}
+
+// Read bytes as per io.Reader interface
+func (hr *HashingReader) Read(p []byte) (n int, err error) {
+	n, err = hr.in.Read(p)
+	_, _ = hr.hasher.Write(p[:n])
+	if err == io.EOF && hr.want != nil {
+		if verifyErr := hr.Verify(hr.want); verifyErr != nil {
+			err = verifyErr
+		}
+	}
+	return n, err
+}
+
+// Sums returns the hashes of the data read so far as lowercase hex
+// strings
+func (hr *HashingReader) Sums() map[hash.Type]string {
+	return hr.hasher.Sums()
+}
+
+// Size returns the number of bytes read so far
+func (hr *HashingReader) Size() int64 {
+	return hr.hasher.Size()
+}
+
+// Verify checks the hashes of the data read so far against want,
+// returning a *HashMismatchError for the first one which differs.
+//
+// Empty hashes in want and hashes which aren't being calculated are
+// ignored.
+func (hr *HashingReader) Verify(want map[hash.Type]string) error {
+	sums := hr.hasher.Sums()
+	for ht, wantSum := range want {
+		got, ok := sums[ht]
+		if !ok || wantSum == "" {
+			continue
+		}
+		if got != strings.ToLower(wantSum) {
+			return &HashMismatchError{Type: ht, Got: got, Want: wantSum}
+		}
+	}
+	return nil
+}
+
+var _ io.Reader = (*HashingReader)(nil)
@@ -0,0 +1,65 @@
+package readers
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/artpar/rclone/fs/hash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	hashingData = "hello world"
+	hashingMD5  = "5eb63bbbe01eeed093cb22bb8f5acdc3"
+	hashingSHA1 = "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"
+)
+
+func TestHashingReader(t *testing.T) {
+	hr, err := NewHashingReader(bytes.NewBufferString(hashingData), hash.NewHashSet(hash.MD5, hash.SHA1))
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(hr)
+	require.NoError(t, err)
+	assert.Equal(t, hashingData, string(data))
+	assert.Equal(t, int64(len(hashingData)), hr.Size())
+	assert.Equal(t, map[hash.Type]string{
+		hash.MD5:  hashingMD5,
+		hash.SHA1: hashingSHA1,
+	}, hr.Sums())
+
+	// matching, upper case, empty and uncalculated hashes are OK
+	assert.NoError(t, hr.Verify(map[hash.Type]string{
+		hash.MD5:   strings.ToUpper(hashingMD5),
+		hash.SHA1:  "",
+		hash.CRC32: "potato",
+	}))
+
+	err = hr.Verify(map[hash.Type]string{hash.MD5: "potato"})
+	require.Error(t, err)
+	mismatch, ok := err.(*HashMismatchError)
+	require.True(t, ok)
+	assert.Equal(t, hash.MD5, mismatch.Type)
+	assert.Equal(t, hashingMD5, mismatch.Got)
+	assert.Equal(t, "potato", mismatch.Want)
+	assert.Equal(t, `MD5 hash mismatch: got "5eb63bbbe01eeed093cb22bb8f5acdc3", want "potato"`, err.Error())
+}
+
+func TestVerifyingReader(t *testing.T) {
+	hr, err := NewVerifyingReader(bytes.NewBufferString(hashingData), map[hash.Type]string{hash.MD5: hashingMD5})
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(hr)
+	require.NoError(t, err)
+	assert.Equal(t, hashingData, string(data))
+
+	hr, err = NewVerifyingReader(bytes.NewBufferString(hashingData), map[hash.Type]string{hash.SHA1: hashingMD5})
+	require.NoError(t, err)
+	buf := make([]byte, 100)
+	n, err := io.ReadFull(hr, buf)
+	assert.Equal(t, len(hashingData), n)
+	_, ok := err.(*HashMismatchError)
+	assert.True(t, ok, "want HashMismatchError got %v", err)
+}
@@ -0,0 +1,58 @@
+package readers
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// maxRateLimitBurst is the most which a RateLimitedReader will read in
+// one go
+const maxRateLimitBurst = 1024 * 1024
+
+// RateLimitedReader reads from a reader no faster than a given number
+// of bytes per second. Create one with NewRateLimitedReader.
+type RateLimitedReader struct {
+	ctx     context.Context
+	in      io.Reader
+	limiter *rate.Limiter
+}
+
+// NewRateLimitedReader returns a reader which reads from in at no more
+// than bytesPerSecond on average. Waiting for the limit is cancelled
+// if ctx is.
+//
+// If bytesPerSecond <= 0 then in is returned unwrapped.
+func NewRateLimitedReader(ctx context.Context, in io.Reader, bytesPerSecond int64) io.Reader {
+	if bytesPerSecond <= 0 {
+		return in
+	}
+	burst := bytesPerSecond
+	if burst > maxRateLimitBurst {
+		burst = maxRateLimitBurst
+	}
+	return &RateLimitedReader{
+		ctx:     ctx,
+		in:      in,
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSecond), int(burst)),
+	}
+}
+
+// Read bytes as per io.Reader interface
+func (r *RateLimitedReader) Read(p []byte) (n int, err error) {
+	// never read more than the limiter will allow at once
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err = r.in.Read(p)
+	if n > 0 {
+		waitErr := r.limiter.WaitN(r.ctx, n)
+		if err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
+
+var _ io.Reader = (*RateLimitedReader)(nil)
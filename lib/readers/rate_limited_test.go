@@ -0,0 +1,39 @@
+package readers
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitedReader(t *testing.T) {
+	ctx := context.Background()
+	data := make([]byte, 1500)
+
+	// no limit returns the reader unwrapped
+	in := bytes.NewBuffer(data)
+	assert.Equal(t, in, NewRateLimitedReader(ctx, in, 0))
+
+	// 1000 bytes are allowed at once, then the next 500 take 0.5s
+	start := time.Now()
+	out, err := ioutil.ReadAll(NewRateLimitedReader(ctx, bytes.NewBuffer(data), 1000))
+	require.NoError(t, err)
+	assert.Equal(t, data, out)
+	assert.True(t, time.Since(start) >= 400*time.Millisecond, "read too quickly: %v", time.Since(start))
+}
+
+func TestRateLimitedReaderCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewRateLimitedReader(ctx, bytes.NewBuffer(make([]byte, 1500)), 1000)
+	buf := make([]byte, 1000)
+	_, err := r.Read(buf)
+	require.NoError(t, err)
+	cancel()
+	_, err = r.Read(buf)
+	assert.Error(t, err)
+}
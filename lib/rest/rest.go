@@ -8,12 +8,14 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"sync"
+	"time"
 
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/lib/readers"
@@ -22,20 +24,59 @@ import (
 
 // Client contains the info to sustain the API
 type Client struct {
-	mu           sync.RWMutex
-	c            *http.Client
-	rootURL      string
-	errorHandler func(resp *http.Response) error
-	headers      map[string]string
-	signer       SignerFn
+	mu               sync.RWMutex
+	c                *http.Client
+	rootURL          string
+	errorHandler     func(resp *http.Response) error
+	headers          map[string]string
+	signer           SignerFn
+	maxBodySize      int64         // max size of a body to decode or read an error from, 0 for unlimited
+	firstByteTimeout time.Duration // cancel requests with no response in this time, 0 for off
+}
+
+// FirstByteTimeoutError is returned when a request is cancelled as
+// the response didn't start within the first byte timeout.
+//
+// It is a timeout error so will be retried by fserrors.ShouldRetry.
+type FirstByteTimeoutError struct {
+	After time.Duration // the timeout which expired
+}
+
+// Error returns the error as a string
+func (e FirstByteTimeoutError) Error() string {
+	return fmt.Sprintf("no response received within %v", e.After)
+}
+
+// Timeout marks the error as a timeout
+func (e FirstByteTimeoutError) Timeout() bool {
+	return true
+}
+
+// BodyTooLargeError is returned when reading a body larger than the
+// maximum body size
+type BodyTooLargeError struct {
+	MaxBodySize int64
+}
+
+// Error returns the error as a string
+func (e BodyTooLargeError) Error() string {
+	return fmt.Sprintf("response body is larger than the limit of %d bytes", e.MaxBodySize)
 }
 
 // NewClient takes an oauth http.Client and makes a new api instance
+//
+// The maximum body size and first byte timeout are set from
+// --max-response-body-size and --first-byte-timeout.
 func NewClient(c *http.Client) *Client {
+	ci := fs.GetConfig(context.TODO())
 	api := &Client{
-		c:            c,
-		errorHandler: defaultErrorHandler,
-		headers:      make(map[string]string),
+		c:                c,
+		errorHandler:     defaultErrorHandler,
+		headers:          make(map[string]string),
+		firstByteTimeout: ci.FirstByteTimeout,
+	}
+	if ci.MaxResponseBodySize > 0 {
+		api.maxBodySize = int64(ci.MaxResponseBodySize)
 	}
 	return api
 }
@@ -102,6 +143,30 @@ func (api *Client) SetSigner(signer SignerFn) *Client {
 	return api
 }
 
+// SetMaxBodySize sets the maximum size of a response body which will
+// be decoded by CallJSON and CallXML or read by the error handler.
+// Reading more than this returns a BodyTooLargeError. 0 means
+// unlimited. This can be overridden for a call with Opts.MaxBodySize.
+func (api *Client) SetMaxBodySize(maxBodySize int64) *Client {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.maxBodySize = maxBodySize
+	return api
+}
+
+// SetFirstByteTimeout sets the time to wait for the response to a
+// request to start once the request body has been sent. If it
+// doesn't start in this time the request is
+// cancelled and a FirstByteTimeoutError returned so the call can be
+// retried. 0 means no timeout. This can be overridden for a call with
+// Opts.FirstByteTimeout.
+func (api *Client) SetFirstByteTimeout(timeout time.Duration) *Client {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.firstByteTimeout = timeout
+	return api
+}
+
 // SetUserPass creates an Authorization header for all requests with
 // the UserName and Password passed in
 func (api *Client) SetUserPass(UserName, Password string) *Client {
@@ -147,6 +212,10 @@ type Opts struct {
 	TransferEncoding      []string   // transfer encoding, set to "identity" to disable chunked encoding
 	Close                 bool       // set to close the connection after this transaction
 	NoRedirect            bool       // if this is set then the client won't follow redirects
+
+	// Limits which override the ones set on the Client if set
+	MaxBodySize      int64         // max body size, -1 for unlimited
+	FirstByteTimeout time.Duration // first byte timeout, -1 for off
 }
 
 // Copy creates a copy of the options
@@ -169,6 +238,157 @@ func DecodeXML(resp *http.Response, result interface{}) (err error) {
 	return decoder.Decode(result)
 }
 
+// limitedBody wraps a response body returning a BodyTooLargeError
+// if more than maxBodySize bytes are read from it
+type limitedBody struct {
+	io.ReadCloser
+	maxBodySize int64
+	remaining   int64
+}
+
+// newLimitedBody wraps body to limit it to maxBodySize bytes if
+// maxBodySize > 0
+func newLimitedBody(body io.ReadCloser, maxBodySize int64) io.ReadCloser {
+	if maxBodySize <= 0 {
+		return body
+	}
+	return &limitedBody{ReadCloser: body, maxBodySize: maxBodySize, remaining: maxBodySize}
+}
+
+// Read reads up to len(p) bytes into p
+func (l *limitedBody) Read(p []byte) (n int, err error) {
+	if l.remaining <= 0 {
+		// Check there is no more data before returning an error
+		var one [1]byte
+		n, err = l.ReadCloser.Read(one[:])
+		if n > 0 {
+			return 0, BodyTooLargeError{MaxBodySize: l.maxBodySize}
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err = l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// cancelBody wraps a response body cancelling the context of the
+// request when it is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context
+func (c *cancelBody) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// maxBodySizeFor returns the max body size for opts - call with the
+// mutex held
+func (api *Client) maxBodySizeFor(opts *Opts) int64 {
+	if opts.MaxBodySize != 0 {
+		return opts.MaxBodySize
+	}
+	return api.maxBodySize
+}
+
+// firstByteTimer cancels a request if the response doesn't start
+// within timeout of it being started
+type firstByteTimer struct {
+	mu       sync.Mutex
+	timeout  time.Duration
+	cancel   context.CancelFunc
+	timer    *time.Timer
+	done     bool // set when the response has arrived
+	timedOut bool // set if the request was cancelled
+}
+
+// start starts the timer if it isn't already running
+func (t *firstByteTimer) start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil || t.done {
+		return
+	}
+	t.timer = time.AfterFunc(t.timeout, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if !t.done {
+			t.timedOut = true
+			t.cancel()
+		}
+	})
+}
+
+// stop stops the timer returning whether it cancelled the request
+func (t *firstByteTimer) stop() (timedOut bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done = true
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	return t.timedOut
+}
+
+// sentBody wraps a request body starting the timer once the body has
+// been read to the end or closed so the time taken to upload it
+// doesn't count against the first byte timeout
+type sentBody struct {
+	io.ReadCloser
+	timer *firstByteTimer
+}
+
+// Read reads up to len(p) bytes into p
+func (b *sentBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.timer.start()
+	}
+	return n, err
+}
+
+// Close closes the body and starts the timer
+func (b *sentBody) Close() error {
+	b.timer.start()
+	return b.ReadCloser.Close()
+}
+
+// doWithTimeout does the request with c cancelling it if the response
+// doesn't start within timeout of the request body being sent
+func doWithTimeout(ctx context.Context, c *http.Client, req *http.Request, timeout time.Duration) (*http.Response, error) {
+	if timeout <= 0 {
+		return c.Do(req)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	timer := &firstByteTimer{timeout: timeout, cancel: cancel}
+	req = req.WithContext(ctx)
+	if req.Body == nil || req.Body == http.NoBody {
+		timer.start()
+	} else {
+		req.Body = &sentBody{ReadCloser: req.Body, timer: timer}
+	}
+	resp, err := c.Do(req)
+	if timer.stop() {
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		cancel()
+		return nil, FirstByteTimeoutError{After: timeout}
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
 // ClientWithNoRedirects makes a new http client which won't follow redirects
 func ClientWithNoRedirects(c *http.Client) *http.Client {
 	clientCopy := *c
@@ -275,14 +495,19 @@ func (api *Client) Call(ctx context.Context, opts *Opts) (resp *http.Response, e
 			return nil, errors.Wrap(err, "signer failed")
 		}
 	}
+	timeout := api.firstByteTimeout
+	if opts.FirstByteTimeout != 0 {
+		timeout = opts.FirstByteTimeout
+	}
 	api.mu.RUnlock()
-	resp, err = c.Do(req)
+	resp, err = doWithTimeout(ctx, c, req, timeout)
 	api.mu.RLock()
 	if err != nil {
 		return nil, err
 	}
 	if !opts.IgnoreStatus {
 		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			resp.Body = newLimitedBody(resp.Body, api.maxBodySizeFor(opts))
 			err = api.errorHandler(resp)
 			if err.Error() == "" {
 				// replace empty errors with something
@@ -481,6 +706,9 @@ func (api *Client) callCodec(ctx context.Context, opts *Opts, request interface{
 	if response == nil || opts.NoResponse {
 		return resp, nil
 	}
+	api.mu.RLock()
+	resp.Body = newLimitedBody(resp.Body, api.maxBodySizeFor(opts))
+	api.mu.RUnlock()
 	err = decode(resp, response)
 	return resp, err
 }
@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/fserrors"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	ctx := context.Background()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_, _ = w.Write([]byte(`{"name":"` + strings.Repeat("x", 100) + `"}`))
+	}))
+	defer ts.Close()
+	api := NewClient(http.DefaultClient).SetRoot(ts.URL)
+
+	var result struct {
+		Name string `json:"name"`
+	}
+	_, err := api.CallJSON(ctx, &Opts{Method: "GET", Path: "/"}, nil, &result)
+	require.NoError(t, err)
+	assert.Equal(t, 100, len(result.Name))
+
+	api.SetMaxBodySize(50)
+	_, err = api.CallJSON(ctx, &Opts{Method: "GET", Path: "/"}, nil, &result)
+	require.Error(t, err)
+	assert.Equal(t, BodyTooLargeError{MaxBodySize: 50}, errors.Cause(err))
+
+	_, err = api.CallJSON(ctx, &Opts{Method: "GET", Path: "/error"}, nil, &result)
+	require.Error(t, err)
+	assert.Equal(t, BodyTooLargeError{MaxBodySize: 50}, errors.Cause(err))
+
+	// A body exactly at the limit is OK
+	api.SetMaxBodySize(111)
+	_, err = api.CallJSON(ctx, &Opts{Method: "GET", Path: "/"}, nil, &result)
+	require.NoError(t, err)
+
+	// Opts override the Client
+	api.SetMaxBodySize(50)
+	_, err = api.CallJSON(ctx, &Opts{Method: "GET", Path: "/", MaxBodySize: -1}, nil, &result)
+	require.NoError(t, err)
+}
+
+func TestFirstByteTimeout(t *testing.T) {
+	ctx := context.Background()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+		_, _ = w.Write([]byte(`{"name":"potato"}`))
+	}))
+	defer ts.Close()
+	api := NewClient(http.DefaultClient).SetRoot(ts.URL).SetFirstByteTimeout(100 * time.Millisecond)
+
+	var result struct {
+		Name string `json:"name"`
+	}
+	_, err := api.CallJSON(ctx, &Opts{Method: "GET", Path: "/"}, nil, &result)
+	require.NoError(t, err)
+	assert.Equal(t, "potato", result.Name)
+
+	start := time.Now()
+	_, err = api.CallJSON(ctx, &Opts{Method: "GET", Path: "/slow"}, nil, &result)
+	require.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	assert.Equal(t, FirstByteTimeoutError{After: 100 * time.Millisecond}, err)
+	assert.True(t, fserrors.ShouldRetry(err))
+
+	// Body can be read after the timeout would have expired
+	resp, err := api.Call(ctx, &Opts{Method: "GET", Path: "/"})
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	body, err := ReadBody(resp)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"potato"}`, string(body))
+
+	// Time taken sending the body doesn't count against the timeout
+	_, err = api.CallJSON(ctx, &Opts{Method: "POST", Path: "/", Body: &slowReader{r: strings.NewReader("potato"), delay: 200 * time.Millisecond}}, nil, &result)
+	require.NoError(t, err)
+}
+
+// slowReader waits for delay before each read
+type slowReader struct {
+	r     *strings.Reader
+	delay time.Duration
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(s.delay)
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return s.r.Read(p)
+}
+
+func TestNewClientConfig(t *testing.T) {
+	ci := fs.GetConfig(context.Background())
+	oldSize, oldTimeout := ci.MaxResponseBodySize, ci.FirstByteTimeout
+	defer func() {
+		ci.MaxResponseBodySize, ci.FirstByteTimeout = oldSize, oldTimeout
+	}()
+
+	ci.MaxResponseBodySize = 1024
+	ci.FirstByteTimeout = time.Second
+	api := NewClient(http.DefaultClient)
+	assert.Equal(t, int64(1024), api.maxBodySize)
+	assert.Equal(t, time.Second, api.firstByteTimeout)
+
+	ci.MaxResponseBodySize = -1
+	api = NewClient(http.DefaultClient)
+	assert.Equal(t, int64(0), api.maxBodySize)
+}
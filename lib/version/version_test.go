@@ -0,0 +1,61 @@
+package version
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var emptyT time.Time
+
+var versionTests = []struct {
+	in      string
+	t       time.Time
+	want    string
+	matched bool
+}{
+	{"potato.txt", time.Date(2002, 1, 31, 12, 1, 1, 0, time.UTC), "potato-v2002-01-31-120101-000.txt", true},
+	{"potato.txt", time.Date(2002, 1, 31, 12, 1, 1, 123000000, time.UTC), "potato-v2002-01-31-120101-123.txt", true},
+	{"potato", time.Date(2002, 1, 31, 12, 1, 1, 0, time.UTC), "potato-v2002-01-31-120101-000", true},
+	{"dir/potato.txt", time.Date(2002, 1, 31, 12, 1, 1, 0, time.UTC), "dir/potato-v2002-01-31-120101-000.txt", true},
+	{".potato", time.Date(2002, 1, 31, 12, 1, 1, 0, time.UTC), "-v2002-01-31-120101-000.potato", true},
+	{"potato.tar.gz", time.Date(2002, 1, 31, 12, 1, 1, 0, time.UTC), "potato.tar-v2002-01-31-120101-000.gz", true},
+}
+
+func TestAdd(t *testing.T) {
+	for _, test := range versionTests {
+		got := Add(test.in, test.t)
+		assert.Equal(t, test.want, got, test.in)
+	}
+	// Times are converted to UTC
+	got := Add("potato.txt", time.Date(2002, 1, 31, 13, 1, 1, 0, time.FixedZone("CET", 3600)))
+	assert.Equal(t, "potato-v2002-01-31-120101-000.txt", got)
+}
+
+func TestRemove(t *testing.T) {
+	for _, test := range versionTests {
+		gotT, got := Remove(test.want)
+		assert.Equal(t, test.in, got, test.want)
+		assert.True(t, test.t.Equal(gotT), test.want)
+	}
+	for _, in := range []string{
+		"potato.txt",
+		"potato-v2002-01-31-120101-00.txt",
+		"potato-v2002-13-31-120101-000.txt",
+		"potato-v2002-01-31-120101.000.txt",
+		"",
+	} {
+		gotT, got := Remove(in)
+		assert.Equal(t, in, got, in)
+		assert.Equal(t, emptyT, gotT, in)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for _, test := range versionTests {
+		assert.Equal(t, test.matched, Match(test.want), test.want)
+	}
+	assert.False(t, Match("potato.txt"))
+	assert.False(t, Match("potato-v2002-01-31-120101.txt"))
+}
@@ -978,13 +978,28 @@ func (d *Dir) Rename(oldName, newName string, destDir *Dir) error {
 		return EROFS
 	}
 	oldPath := path.Join(d.path, oldName)
-	newPath := path.Join(destDir.path, newName)
 	// fs.Debugf(oldPath, "Dir.Rename to %q", newPath)
 	oldNode, err := d.stat(oldName)
 	if err != nil {
 		fs.Errorf(oldPath, "Dir.Rename error: %v", err)
 		return err
 	}
+	// Use the name as stored as oldName may differ in case
+	oldName = oldNode.Name()
+	if d.vfs.Opt.CaseInsensitive {
+		// If newName matches a different existing entry ignoring
+		// case then overwrite that rather than making a name
+		// which differs from it only by case
+		newNode, err := destDir.stat(newName)
+		if err == nil && newNode != oldNode && newNode.Name() != newName {
+			fs.Debugf(oldPath, "Dir.Rename using existing name %q for %q", newNode.Name(), newName)
+			newName = newNode.Name()
+		} else if err != nil && err != ENOENT {
+			fs.Errorf(oldPath, "Dir.Rename error: %v", err)
+			return err
+		}
+	}
+	newPath := path.Join(destDir.path, newName)
 	switch x := oldNode.DirEntry().(type) {
 	case nil:
 		if oldFile, ok := oldNode.(*File); ok {
@@ -0,0 +1,253 @@
+package vfs
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/fs/operations"
+	"github.com/artpar/rclone/lib/encoder"
+)
+
+// windowsEncoding is used to translate names for --vfs-windows-names.
+//
+// It replaces the characters and patterns which can't be used in file
+// names on Windows with their FULLWIDTH or SYMBOL FOR equivalents.
+const windowsEncoding = (encoder.EncodeWin |
+	encoder.EncodeBackSlash |
+	encoder.EncodeCtl |
+	encoder.EncodeRightSpace |
+	encoder.EncodeRightPeriod |
+	encoder.EncodeInvalidUtf8)
+
+// encodedFs wraps an Fs translating the names in it with enc so the
+// VFS shows the encoded names and translates them back when
+// accessing the Fs.
+type encodedFs struct {
+	fs.Fs
+	enc      encoder.MultiEncoder
+	features *fs.Features
+}
+
+// newEncodedFs wraps f so the names in it are encoded with enc
+func newEncodedFs(f fs.Fs, enc encoder.MultiEncoder) *encodedFs {
+	e := &encodedFs{
+		Fs:  f,
+		enc: enc,
+	}
+	e.features = (&fs.Features{
+		CaseInsensitive:         true,
+		DuplicateFiles:          true,
+		ReadMimeType:            true,
+		WriteMimeType:           true,
+		CanHaveEmptyDirectories: true,
+		BucketBased:             true,
+		BucketBasedRootOK:       true,
+		SetTier:                 true,
+		GetTier:                 true,
+		ServerSideAcrossConfigs: true,
+		IsLocal:                 true,
+		SlowModTime:             true,
+		SlowHash:                true,
+	}).Fill(context.TODO(), e).Mask(context.TODO(), f).WrapsFs(e, f)
+	return e
+}
+
+// toFs translates a path in the VFS to one in the wrapped Fs
+func (e *encodedFs) toFs(remote string) string {
+	return e.enc.ToStandardPath(remote)
+}
+
+// fromFs translates a path in the wrapped Fs to one in the VFS
+func (e *encodedFs) fromFs(remote string) string {
+	return e.enc.FromStandardPath(remote)
+}
+
+// newObject wraps o if it isn't nil
+func (e *encodedFs) newObject(o fs.Object) fs.Object {
+	if o == nil {
+		return nil
+	}
+	return &encodedObject{Object: o, f: e}
+}
+
+// Features returns the optional features of this Fs
+func (e *encodedFs) Features() *fs.Features {
+	return e.features
+}
+
+// UnWrap returns the Fs that this Fs is wrapping
+func (e *encodedFs) UnWrap() fs.Fs {
+	return e.Fs
+}
+
+// List the objects and directories in dir into entries
+func (e *encodedFs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	entries, err = e.Fs.List(ctx, e.toFs(dir))
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		switch x := entry.(type) {
+		case fs.Object:
+			entries[i] = e.newObject(x)
+		case fs.Directory:
+			entries[i] = fs.NewDirCopy(ctx, x).SetRemote(e.fromFs(x.Remote()))
+		}
+	}
+	return entries, nil
+}
+
+// NewObject finds the Object at remote
+func (e *encodedFs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	o, err := e.Fs.NewObject(ctx, e.toFs(remote))
+	return e.newObject(o), err
+}
+
+// Put in to the remote path with the modTime given of the given size
+func (e *encodedFs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	o, err := e.Fs.Put(ctx, in, operations.NewOverrideRemote(src, e.toFs(src.Remote())), options...)
+	return e.newObject(o), err
+}
+
+// PutStream uploads to the remote path with the modTime given of indeterminate size
+func (e *encodedFs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	do := e.Fs.Features().PutStream
+	if do == nil {
+		return nil, fs.ErrorNotImplemented
+	}
+	o, err := do(ctx, in, operations.NewOverrideRemote(src, e.toFs(src.Remote())), options...)
+	return e.newObject(o), err
+}
+
+// Mkdir makes the directory
+func (e *encodedFs) Mkdir(ctx context.Context, dir string) error {
+	return e.Fs.Mkdir(ctx, e.toFs(dir))
+}
+
+// Rmdir removes the directory if empty
+func (e *encodedFs) Rmdir(ctx context.Context, dir string) error {
+	return e.Fs.Rmdir(ctx, e.toFs(dir))
+}
+
+// Purge deletes all the files in the directory
+func (e *encodedFs) Purge(ctx context.Context, dir string) error {
+	do := e.Fs.Features().Purge
+	if do == nil {
+		return fs.ErrorCantPurge
+	}
+	return do(ctx, e.toFs(dir))
+}
+
+// unwrapObject returns the wrapped object of src if it is from this Fs
+func (e *encodedFs) unwrapObject(src fs.Object) (fs.Object, bool) {
+	o, ok := src.(*encodedObject)
+	if !ok || o.f != e {
+		return nil, false
+	}
+	return o.Object, true
+}
+
+// Copy src to this remote using server-side copy operations.
+func (e *encodedFs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	do := e.Fs.Features().Copy
+	srcObj, ok := e.unwrapObject(src)
+	if do == nil || !ok {
+		return nil, fs.ErrorCantCopy
+	}
+	o, err := do(ctx, srcObj, e.toFs(remote))
+	return e.newObject(o), err
+}
+
+// Move src to this remote using server-side move operations.
+func (e *encodedFs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	do := e.Fs.Features().Move
+	srcObj, ok := e.unwrapObject(src)
+	if do == nil || !ok {
+		return nil, fs.ErrorCantMove
+	}
+	o, err := do(ctx, srcObj, e.toFs(remote))
+	return e.newObject(o), err
+}
+
+// DirMove moves src, srcRemote to this remote at dstRemote using
+// server-side move operations.
+func (e *encodedFs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string) error {
+	do := e.Fs.Features().DirMove
+	srcFs, ok := src.(*encodedFs)
+	if do == nil || !ok {
+		return fs.ErrorCantDirMove
+	}
+	return do(ctx, srcFs.Fs, srcFs.toFs(srcRemote), e.toFs(dstRemote))
+}
+
+// About gets quota information from the Fs
+func (e *encodedFs) About(ctx context.Context) (*fs.Usage, error) {
+	do := e.Fs.Features().About
+	if do == nil {
+		return nil, fs.ErrorNotImplemented
+	}
+	return do(ctx)
+}
+
+// ChangeNotify calls the passed function with a path that has had
+// changes, translating the path
+func (e *encodedFs) ChangeNotify(ctx context.Context, notifyFunc func(string, fs.EntryType), pollIntervalChan <-chan time.Duration) {
+	do := e.Fs.Features().ChangeNotify
+	if do == nil {
+		return
+	}
+	do(ctx, func(path string, entryType fs.EntryType) {
+		notifyFunc(e.fromFs(path), entryType)
+	}, pollIntervalChan)
+}
+
+// encodedObject is an object in an encodedFs
+type encodedObject struct {
+	fs.Object
+	f *encodedFs
+}
+
+// Fs returns the Fs the object is in
+func (o *encodedObject) Fs() fs.Info {
+	return o.f
+}
+
+// Remote returns the translated remote path
+func (o *encodedObject) Remote() string {
+	return o.f.fromFs(o.Object.Remote())
+}
+
+// String returns a description of the Object
+func (o *encodedObject) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.Remote()
+}
+
+// Update the object with the contents of in
+func (o *encodedObject) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	return o.Object.Update(ctx, in, operations.NewOverrideRemote(src, o.Object.Remote()), options...)
+}
+
+// UnWrap returns the wrapped Object
+func (o *encodedObject) UnWrap() fs.Object {
+	return o.Object
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs              = (*encodedFs)(nil)
+	_ fs.PutStreamer     = (*encodedFs)(nil)
+	_ fs.Purger          = (*encodedFs)(nil)
+	_ fs.Copier          = (*encodedFs)(nil)
+	_ fs.Mover           = (*encodedFs)(nil)
+	_ fs.DirMover        = (*encodedFs)(nil)
+	_ fs.Abouter         = (*encodedFs)(nil)
+	_ fs.ChangeNotifier  = (*encodedFs)(nil)
+	_ fs.UnWrapper       = (*encodedFs)(nil)
+	_ fs.Object          = (*encodedObject)(nil)
+	_ fs.ObjectUnWrapper = (*encodedObject)(nil)
+)
@@ -0,0 +1,64 @@
+package vfs
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/artpar/rclone/vfs/vfscommon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVFSWindowsNames(t *testing.T) {
+	ctx := context.Background()
+	opt := vfscommon.DefaultOpt
+	opt.WindowsNames = true
+	r, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	r.WriteObject(ctx, "dir:1/file?1.txt", "file1", t1)
+
+	// Names are shown translated
+	node, err := vfs.Stat("dir：1/file？1.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "file？1.txt", node.Name())
+	assert.Equal(t, "dir：1/file？1.txt", node.Path())
+
+	fis, err := vfs.ReadDir("dir：1")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(fis))
+	assert.Equal(t, "file？1.txt", fis[0].Name())
+
+	data, err := vfs.ReadFile("dir：1/file？1.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "file1", string(data))
+
+	// The untranslated names aren't found
+	_, err = vfs.Stat("dir:1/file?1.txt")
+	assert.Equal(t, ENOENT, err)
+
+	// New files are created with the original names
+	fd, err := vfs.OpenFile("dir：1/new＊file.txt", os.O_WRONLY|os.O_CREATE, 0777)
+	require.NoError(t, err)
+	_, err = fd.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	_, err = r.Fremote.NewObject(ctx, "dir:1/new*file.txt")
+	require.NoError(t, err)
+
+	// And renamed to them
+	require.NoError(t, vfs.Rename("dir：1/new＊file.txt", "dir：1/renamed｜.txt"))
+	_, err = r.Fremote.NewObject(ctx, "dir:1/renamed|.txt")
+	require.NoError(t, err)
+
+	// As are directories
+	require.NoError(t, vfs.Mkdir("new＜dir＞", 0777))
+	entries, err := r.Fremote.List(ctx, "")
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Remote())
+	}
+	assert.Contains(t, names, "new<dir>")
+}
@@ -96,9 +96,38 @@ func (f *File) Mode() (mode os.FileMode) {
 	if f.appendMode {
 		mode |= os.ModeAppend
 	}
+	if f._readOnly(false) {
+		mode &^= 0222
+	}
 	return mode
 }
 
+// _readOnly returns true if the backend's permission metadata says
+// the file can't be modified or deleted. If fetch is set the backend
+// may be asked if it isn't known already.
+//
+// call with f.mu held
+func (f *File) _readOnly(fetch bool) bool {
+	if f.o == nil {
+		return false
+	}
+	readOnly, err := fs.IsReadOnly(context.TODO(), f.o, fetch)
+	if err != nil {
+		fs.Debugf(f._path(), "Failed to read permissions: %v", err)
+		return false
+	}
+	return readOnly
+}
+
+// readOnly returns true if the backend's permission metadata says
+// the file can't be modified or deleted, asking the backend if
+// necessary.
+func (f *File) readOnly() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f._readOnly(true)
+}
+
 // Name (base) of the directory - satisfies Node interface
 func (f *File) Name() (name string) {
 	f.mu.RLock()
@@ -165,6 +194,10 @@ func (f *File) rename(ctx context.Context, destDir *Dir, newName string) error {
 	oldPendingRenameFun := f.pendingRenameFun
 	f.mu.RUnlock()
 
+	if f.readOnly() {
+		return EACCES
+	}
+
 	if features := d.Fs().Features(); features.Move == nil && features.Copy == nil {
 		err := errors.Errorf("Fs %q can't rename files (no server-side Move or Copy)", d.Fs())
 		fs.Errorf(f.Path(), "Dir.Rename error: %v", err)
@@ -349,6 +382,13 @@ func (f *File) Size() int64 {
 			if err != nil {
 				fs.Errorf(f._path(), "Size: Item GetSize failed: %v", err)
 			} else {
+				// writers may have data in their write buffers
+				// which isn't in the item yet
+				if f._writingInProgress() && f.d.vfs.Opt.WriteBufferSize > 0 {
+					if bufferedSize := atomic.LoadInt64(&f.size); bufferedSize > size {
+						size = bufferedSize
+					}
+				}
 				return size
 			}
 		}
@@ -579,6 +619,9 @@ func (f *File) Remove() (err error) {
 	if d.vfs.Opt.ReadOnly {
 		return EROFS
 	}
+	if f.readOnly() {
+		return EACCES
+	}
 
 	// Remove the object from the cache
 	wasWriting := false
@@ -642,6 +685,37 @@ func (f *File) Fs() fs.Fs {
 	return f.d.Fs()
 }
 
+// bypassCache returns true if a file opened read only with flags
+// should be read straight from the remote rather than through the
+// cache. This is so big files which are read once, for example by
+// backup programs, don't push everything else out of the cache.
+//
+// This is the case if it was opened with O_DIRECT or it matches
+// --vfs-cache-bypass, unless the file is being written or the cache
+// has changes to it which haven't been uploaded yet as the remote
+// doesn't have the latest data.
+func (f *File) bypassCache(flags int) bool {
+	f.mu.RLock()
+	cacheBypass := f.d.vfs.cacheBypass
+	f.mu.RUnlock()
+	bypass := oDirect != 0 && flags&oDirect != 0
+	if !bypass && cacheBypass != nil {
+		bypass = cacheBypass.Include(f.Path(), f.Size(), time.Time{})
+	}
+	if !bypass {
+		return false
+	}
+	if f.writingInProgress() {
+		fs.Debugf(f.Path(), "Not bypassing the cache as the file is being written")
+		return false
+	}
+	if f.d.vfs.cache != nil && f.d.vfs.cache.DirtyItem(f.Path()) != nil {
+		fs.Debugf(f.Path(), "Not bypassing the cache as it has changes which haven't been uploaded")
+		return false
+	}
+	return true
+}
+
 // Open a file according to the flags provided
 //
 //   O_RDONLY open the file read-only.
@@ -653,6 +727,7 @@ func (f *File) Fs() fs.Fs {
 //   O_EXCL   used with O_CREATE, file must not exist
 //   O_SYNC   open for synchronous I/O.
 //   O_TRUNC  if possible, truncate file when opened
+//   O_DIRECT read the file without using the cache
 //
 // We ignore O_SYNC and O_EXCL
 func (f *File) Open(flags int) (fd Handle, err error) {
@@ -684,6 +759,12 @@ func (f *File) Open(flags int) (fd Handle, err error) {
 		return nil, EPERM
 	}
 
+	// Refuse to write files the backend says are read only rather
+	// than failing to upload them when they are closed
+	if (write || flags&os.O_TRUNC != 0) && f.readOnly() {
+		return nil, EACCES
+	}
+
 	// If append is set then set read to force openRW
 	if flags&os.O_APPEND != 0 {
 		read = true
@@ -720,7 +801,7 @@ func (f *File) Open(flags int) (fd Handle, err error) {
 			fd, err = f.openWrite(flags)
 		}
 	} else if read {
-		if CacheMode >= vfscommon.CacheModeFull {
+		if CacheMode >= vfscommon.CacheModeFull && !f.bypassCache(flags) {
 			fd, err = f.openRW(flags)
 		} else {
 			fd, err = f.openRead()
@@ -248,6 +248,68 @@ func TestFileOpenWrite(t *testing.T) {
 	assert.Equal(t, EROFS, err)
 }
 
+func TestFileOpenBypassCache(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeFull
+	opt.CacheBypass = "*.{tar,img}"
+	r, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	r.WriteObject(context.Background(), "backup.tar", "archive contents", t1)
+	r.WriteObject(context.Background(), "file.txt", "text contents", t1)
+	r.WriteObject(context.Background(), "direct.txt", "direct contents", t1)
+
+	open := func(name string, flags int) Handle {
+		node, err := vfs.Stat(name)
+		require.NoError(t, err)
+		fd, err := node.Open(flags)
+		require.NoError(t, err)
+		return fd
+	}
+
+	for _, test := range []struct {
+		name     string
+		flags    int
+		bypassed bool
+	}{
+		{"file.txt", os.O_RDONLY, false},
+		{"backup.tar", os.O_RDONLY, true},
+		{"direct.txt", os.O_RDONLY | oDirect, oDirect != 0},
+	} {
+		fd := open(test.name, test.flags)
+		_, isRead := fd.(*ReadFileHandle)
+		assert.Equal(t, test.bypassed, isRead, fmt.Sprintf("%s %s", test.name, decodeOpenFlags(test.flags)))
+		require.NoError(t, fd.Close())
+	}
+
+	// The bypassed file isn't in the cache
+	assert.False(t, vfs.cache.Exists("backup.tar"))
+
+	// Files opened for writing still use the cache
+	fd := open("backup.tar", os.O_RDWR)
+	_, isRW := fd.(*RWFileHandle)
+	assert.True(t, isRW)
+
+	// and so do reads while the file is being written
+	_, err := fd.WriteAt([]byte("ARCHIVE"), 0)
+	require.NoError(t, err)
+	readFd := open("backup.tar", os.O_RDONLY)
+	_, isRW = readFd.(*RWFileHandle)
+	assert.True(t, isRW, "open writer")
+	require.NoError(t, readFd.Close())
+	require.NoError(t, fd.Close())
+
+	// or before the changes have been uploaded
+	readFd = open("backup.tar", os.O_RDONLY)
+	_, isRW = readFd.(*RWFileHandle)
+	assert.True(t, isRW, "not uploaded")
+	buf := make([]byte, 7)
+	_, err = readFd.ReadAt(buf, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "ARCHIVE", string(buf))
+	require.NoError(t, readFd.Close())
+}
+
 func TestFileRemove(t *testing.T) {
 	r, vfs, file, _, cleanup := fileCreate(t, vfscommon.CacheModeOff)
 	defer cleanup()
@@ -262,6 +324,39 @@ func TestFileRemove(t *testing.T) {
 	assert.Equal(t, EROFS, err)
 }
 
+// readOnlyObject is an fs.Object the backend says is read only
+type readOnlyObject struct {
+	fs.Object
+}
+
+// ReadOnly returns true as the object is read only
+func (o readOnlyObject) ReadOnly(ctx context.Context, fetch bool) (bool, error) {
+	return true, nil
+}
+
+func TestFileReadOnlyObject(t *testing.T) {
+	r, vfs, file, file1, cleanup := fileCreate(t, vfscommon.CacheModeWrites)
+	defer cleanup()
+
+	file.setObjectNoUpdate(readOnlyObject{Object: file.getObject()})
+
+	assert.Equal(t, vfs.Opt.FilePerms&^0222, file.Mode())
+
+	fd, err := file.Open(os.O_RDONLY)
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	for _, flags := range []int{os.O_WRONLY, os.O_RDWR, os.O_WRONLY | os.O_APPEND, os.O_RDWR | os.O_TRUNC} {
+		_, err = file.Open(flags)
+		assert.Equal(t, EACCES, err, decodeOpenFlags(flags))
+	}
+
+	assert.Equal(t, EACCES, file.Remove())
+	assert.Equal(t, EACCES, vfs.Rename("dir/file1", "dir/file2"))
+
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
 func TestFileRemoveAll(t *testing.T) {
 	r, vfs, file, _, cleanup := fileCreate(t, vfscommon.CacheModeOff)
 	defer cleanup()
@@ -54,6 +54,16 @@ Or individual files or directories:
 
     rclone rc vfs/forget file=path/to/file dir=path/to/dir
 
+If you use !--vfs-prefetch-tree! then rclone reads the whole directory
+tree into the directory cache in the background at startup, so the
+first listing of each directory is instant. This is best used with
+!--fast-list! on remotes which support it, !--read-only! and a long
+!--dir-cache-time!, e.g. for a mount of a media library which isn't
+changed elsewhere, as the cache expires as normal after
+!--dir-cache-time!.
+
+    --vfs-prefetch-tree    Read the whole directory tree in the background at startup.
+
 ### VFS File Buffering
 
 The !--buffer-size! flag determines the amount of memory,
@@ -110,6 +120,23 @@ for two reasons.  Firstly because it is only checked every
 !--vfs-cache-poll-interval!.  Secondly because open files cannot be
 evicted from the cache.
 
+When the cache is over !--vfs-cache-max-size! the files which have
+only been opened once are removed first, least recently used first.
+After that, the files which have been opened more than once are removed
+in order of the time they were opened before the last time. This means
+files which are used over and over again stay in the cache rather than
+being pushed out by files which are only read once.
+
+Files and directories can be pinned in the cache so they are never
+removed by !--vfs-cache-max-age! or !--vfs-cache-max-size!, for
+example with
+
+    rclone rc vfs/pin path=dir/file path2=otherdir
+
+Pinning a directory pins everything in it, including files read later.
+Use !rclone rc vfs/unpin! to unpin them again. The pins are kept when
+rclone is restarted.
+
 You **should not** run two copies of rclone using the same VFS cache
 with the same or overlapping remotes if using !--vfs-cache-mode > off!.
 This can potentially cause data corruption if you do. You can work
@@ -184,6 +211,44 @@ FAT/exFAT do not. Rclone will perform very badly if the cache
 directory is on a filesystem which doesn't support sparse files and it
 will log an ERROR message if one is detected.
 
+#### Write buffering
+
+Some applications, such as databases like SQLite, make lots of small
+writes to their files. With !--vfs-cache-mode writes! or !full! each
+of these is written to the file in the cache straight away. Setting
+!--vfs-write-buffer-size! makes each open file collect consecutive
+small writes in a buffer of that size in memory and write them to the
+cache in one go.
+
+    --vfs-write-buffer-size SizeSuffix      Coalesce small writes to each open file in a buffer of this size when using cache. 0 to disable.
+    --vfs-write-buffer-time duration        Max time written data stays in the --vfs-write-buffer-size buffer. 0 to only flush on fsync, close or when full. (default 1s)
+
+The buffer is written to the cache when a write doesn't follow on from
+the data in it or won't fit in it, when the file is read, truncated,
+flushed, closed or fsync-ed and after !--vfs-write-buffer-time!. Writes
+as big as the buffer bypass it.
+
+Data in the buffer is only visible through the file handle which wrote
+it until it has been written to the cache, so don't use this if
+several processes write and read the same file at the same time.
+
+#### Bypassing the cache
+
+Reading big files which are only read once, such as backup archives,
+through the cache can push everything else out of it. To stop this,
+files opened read only with the O_DIRECT flag (on Linux) or whose
+names match the glob given with !--vfs-cache-bypass! are read
+straight from the remote as with !--vfs-cache-mode off!, whatever the
+cache mode.
+
+    --vfs-cache-bypass string   Read files matching this glob straight from the remote without caching them.
+
+For example !--vfs-cache-bypass "*.{tar,zip,img}"!. The glob uses the
+same syntax as the [filters](/filtering/).
+
+Files which are open for writing, or which have changes in the cache
+which haven't been uploaded yet, still use the cache.
+
 ### VFS Performance
 
 These flags may be used to enable/disable features of the VFS for
@@ -208,8 +273,15 @@ Rclone will keep doubling the chunk size requested starting at
 --vfs-read-chunk-size with a maximum of --vfs-read-chunk-size-limit
 unless it is set to "off" in which case there will be no limit.
 
+Each time the file is read from somewhere other than where the last
+read finished the chunk size is halved, down to a minimum of
+--vfs-read-chunk-size-min, so random access reads don't fetch more
+data than they need. Reading sequentially afterwards will double it
+again.
+
     --vfs-read-chunk-size SizeSuffix        Read the source objects in chunks. (default 128M)
     --vfs-read-chunk-size-limit SizeSuffix  Max chunk doubling size (default "off")
+    --vfs-read-chunk-size-min SizeSuffix    Min chunk halving size (default same as --vfs-read-chunk-size)
 
 Sometimes rclone is delivered reads or writes out of order. Rather
 than seeking rclone will wait a short time for the in sequence read or
@@ -225,6 +297,15 @@ modified files from cache (the related global flag --checkers have no effect on
 
     --transfers int  Number of file transfers to run in parallel. (default 4)
 
+The bandwidth used by the VFS can be limited separately for uploads
+and downloads. This is useful to stop the background upload of
+modified files from the cache saturating the uplink while files are
+being read interactively from the same mount. These limits are shared
+by all the files in the VFS and apply on top of the global --bwlimit.
+
+    --vfs-upload-bwlimit SizeSuffix    Bandwidth limit for uploading files to the remote. 0 is unlimited.
+    --vfs-download-bwlimit SizeSuffix  Bandwidth limit for downloading files from the remote. 0 is unlimited.
+
 ### VFS Case Sensitivity
 
 Linux file systems are case-sensitive: two files can differ only
@@ -252,6 +333,13 @@ transparently fixup the name. This fixup happens only when an existing file
 is requested. Case sensitivity of file names created anew by rclone is
 controlled by an underlying mounted file system.
 
+If a directory contains more than one name differing only by case and
+none of them matches exactly, rclone can't tell which one is meant so
+it returns an error rather than picking one. Renaming a file onto a name
+which differs only by case from a different existing file replaces
+that file, as it would on Windows, rather than creating a second name
+differing only by case.
+
 Note that case sensitivity of the operating system running rclone (the target)
 may differ from case sensitivity of a file system mounted by rclone (the source).
 The flag controls whether "fixup" is performed to satisfy the target.
@@ -260,6 +348,34 @@ If the flag is not provided on the command line, then its default value depends
 on the operating system where rclone runs: "true" on Windows and macOS, "false"
 otherwise. If the flag is provided without a value, then it is "true".
 
+### VFS Windows Names
+
+Files with names which can't be used on Windows, e.g. ones containing
+!:!, !?! or !*! or ending in a space or a period, are often invisible or
+unusable to Windows clients when served with !rclone serve! or a mount
+shared over SMB.
+
+The !--vfs-windows-names! flag makes rclone show these characters as
+their FULLWIDTH equivalents, e.g. !file?.txt! is shown as !file？.txt!,
+in the same way as the local backend does on Windows. Names are
+translated back when they are used, so creating !file？.txt! creates
+!file?.txt! on the remote. Names on the remote which already contain
+FULLWIDTH characters are shown with a !‛! in front of them so they can
+be told apart.
+
+### VFS Read Only Files
+
+Some backends say when a file can't be changed by the user. On these
+backends files which can't be changed are shown without write
+permissions and opening them for writing, truncating, renaming or
+deleting them fails with a "permission denied" error straight away
+rather than failing when the file is uploaded after it is closed.
+
+- Google Drive - files the user doesn't have permission to edit.
+- S3 - objects under an object lock retention period or legal hold.
+  These are only known after the object's metadata has been read,
+  which rclone does when the file is opened for writing.
+
 ### Alternate report of used bytes
 
 Some backends, most notably S3, do not report the amount of bytes used.
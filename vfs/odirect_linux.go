@@ -0,0 +1,9 @@
+// +build linux
+
+package vfs
+
+import "syscall"
+
+// oDirect is the O_DIRECT open flag which asks for the file to be
+// read without caching
+const oDirect = syscall.O_DIRECT
@@ -0,0 +1,6 @@
+// +build !linux
+
+package vfs
+
+// oDirect is the O_DIRECT open flag which isn't supported on this OS
+const oDirect = 0
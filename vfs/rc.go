@@ -389,3 +389,87 @@ func rcList(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	out["vfses"] = names
 	return out, nil
 }
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "vfs/pin",
+		Fn:    rcPin,
+		Title: "Pin files or directories in the VFS cache.",
+		Help: `
+This stops the files and directories passed in, and everything in
+the directories, from being removed from the VFS cache by the cache
+cleaner because of --vfs-cache-max-age or --vfs-cache-max-size. Files
+which aren't in the cache yet are pinned when they are read. This
+needs --vfs-cache-mode minimal or above.
+
+Pass the paths in as path=path. Any parameter key starting with path
+will pin that path, e.g.
+
+    rclone rc vfs/pin path=films/favourite.mkv path2=music
+
+It returns the list of all the pinned paths under the key "pinned".
+Use it with no paths to list them. The pins are kept when rclone is
+restarted. They can be edited without a running rclone with the pin
+and unpin commands.
+` + getVFSHelp,
+	})
+	rc.Add(rc.Call{
+		Path:  "vfs/unpin",
+		Fn:    rcUnpin,
+		Title: "Unpin files or directories in the VFS cache.",
+		Help: `
+This allows the files and directories pinned with vfs/pin to be
+removed from the VFS cache again.
+
+Pass the paths in as path=path in the same way as vfs/pin, e.g.
+
+    rclone rc vfs/unpin path=films/favourite.mkv
+
+It is an error to unpin a path which isn't pinned. Note that a file in
+a pinned directory can't be unpinned on its own.
+
+It returns the list of the paths which are still pinned under the key
+"pinned".
+` + getVFSHelp,
+	})
+}
+
+// rcPinPaths calls fn on each of the paths passed to vfs/pin or
+// vfs/unpin and returns the paths which are pinned afterwards
+func rcPinPaths(in rc.Params, fn func(vfs *VFS, path string) error) (out rc.Params, err error) {
+	vfs, err := getVFS(in)
+	if err != nil {
+		return nil, err
+	}
+	if vfs.cache == nil {
+		return nil, errors.New("need --vfs-cache-mode minimal or above to pin files in the cache")
+	}
+	for k, v := range in {
+		if !strings.HasPrefix(k, "path") {
+			return nil, errors.Errorf("unknown key %q", k)
+		}
+		path, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("value must be string %q=%v", k, v)
+		}
+		err = fn(vfs, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rc.Params{
+		"pinned": vfs.cache.Pins(),
+	}, nil
+}
+
+func rcPin(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	return rcPinPaths(in, func(vfs *VFS, path string) error {
+		return vfs.cache.Pin(path)
+	})
+}
+
+func rcUnpin(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	return rcPinPaths(in, func(vfs *VFS, path string) error {
+		return vfs.cache.Unpin(path)
+	})
+}
@@ -119,3 +119,36 @@ func TestRcList(t *testing.T) {
 		},
 	}, out)
 }
+
+func TestRcPin(t *testing.T) {
+	r, vfs, cleanup, pin := rcNewRun(t, "vfs/pin")
+	defer cleanup()
+	_ = r
+	unpin := rc.Calls.Get("vfs/unpin")
+	require.NotNil(t, unpin)
+
+	// No cache
+	_, err := pin.Fn(context.Background(), rc.Params{"path": "dir"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "need --vfs-cache-mode")
+
+	vfs.SetCacheMode(vfscommon.CacheModeMinimal)
+
+	out, err := pin.Fn(context.Background(), rc.Params{"path": "dir", "path2": "/file.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"pinned": []string{"dir", "file.txt"}}, out)
+
+	out, err = pin.Fn(context.Background(), rc.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"pinned": []string{"dir", "file.txt"}}, out)
+
+	_, err = pin.Fn(context.Background(), rc.Params{"file": "dir"})
+	assert.EqualError(t, err, `unknown key "file"`)
+
+	out, err = unpin.Fn(context.Background(), rc.Params{"path": "dir"})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{"pinned": []string{"file.txt"}}, out)
+
+	_, err = unpin.Fn(context.Background(), rc.Params{"path": "dir"})
+	assert.EqualError(t, err, `"dir" is not pinned`)
+}
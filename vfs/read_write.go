@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/artpar/rclone/fs"
@@ -29,6 +30,12 @@ type RWFileHandle struct {
 	closed      bool  // set if handle has been closed
 	opened      bool
 	writeCalled bool // if any Write() methods have been called
+
+	// write buffer protected by mutex - see Opt.WriteBufferSize
+	wbuf      []byte      // data written but not yet passed to the cache item
+	wbufOff   int64       // offset in the file of the start of wbuf
+	wbufTimer *time.Timer // flushes wbuf after Opt.WriteBufferTime
+	wbufErr   error       // error from a flush done by wbufTimer
 }
 
 func newRWFileHandle(d *Dir, f *File, flags int) (fh *RWFileHandle, err error) {
@@ -153,11 +160,15 @@ func (fh *RWFileHandle) close() (err error) {
 		return ECLOSED
 	}
 
+	flushErr := fh._flushWriteBuffer()
 	fh.closed = true
 	fh.updateSize()
 	if fh.opened {
 		err = fh.item.Close(fh.file.setObject)
 		fh.opened = false
+		if err == nil {
+			err = flushErr
+		}
 	} else {
 		// apply any pending mod times if any
 		_ = fh.file.applyPendingModTime()
@@ -183,9 +194,10 @@ func (fh *RWFileHandle) Close() error {
 func (fh *RWFileHandle) Flush() error {
 	fh.mu.Lock()
 	fs.Debugf(fh.logPrefix(), "RWFileHandle.Flush")
+	err := fh._flushWriteBuffer()
 	fh.updateSize()
 	fh.mu.Unlock()
-	return nil
+	return err
 }
 
 // Release is called when we are finished with the file handle
@@ -222,6 +234,9 @@ func (fh *RWFileHandle) _size() int64 {
 			size = 0
 		}
 	}
+	if end := fh.wbufOff + int64(len(fh.wbuf)); len(fh.wbuf) > 0 && end > size {
+		size = end
+	}
 	fh.file.setSize(size)
 	return size
 }
@@ -253,6 +268,9 @@ func (fh *RWFileHandle) _readAt(b []byte, off int64, release bool) (n int, err e
 	if fh.writeOnly() {
 		return n, EBADF
 	}
+	if err = fh._flushWriteBuffer(); err != nil {
+		return n, err
+	}
 	if off >= fh._size() {
 		return n, io.EOF
 	}
@@ -336,6 +354,9 @@ func (fh *RWFileHandle) _writeAt(b []byte, off int64, release bool) (n int, err
 		off = fh.offset
 	}
 	fh.writeCalled = true
+	if bufSize := int(fh.d.vfs.Opt.WriteBufferSize); bufSize > 0 {
+		return fh._bufferWrite(b, off, bufSize)
+	}
 	if release {
 		// Do the writing with fh.mu unlocked
 		fh.mu.Unlock()
@@ -352,6 +373,82 @@ func (fh *RWFileHandle) _writeAt(b []byte, off int64, release bool) (n int, err
 	return n, err
 }
 
+// _bufferWrite adds b at off to the write buffer, flushing the buffer
+// first if b doesn't follow on from it or won't fit. Writes of
+// bufSize or more are passed straight to the cache item.
+//
+// call with lock held
+func (fh *RWFileHandle) _bufferWrite(b []byte, off int64, bufSize int) (n int, err error) {
+	if fh.wbufErr != nil {
+		err, fh.wbufErr = fh.wbufErr, nil
+		return 0, err
+	}
+	if len(fh.wbuf) > 0 && (off != fh.wbufOff+int64(len(fh.wbuf)) || len(fh.wbuf)+len(b) > bufSize) {
+		if err = fh._flushWriteBuffer(); err != nil {
+			return 0, err
+		}
+	}
+	if len(b) >= bufSize {
+		n, err = fh.item.WriteAt(b, off)
+		if err != nil {
+			return n, err
+		}
+		_ = fh._size()
+		return n, nil
+	}
+	if len(fh.wbuf) == 0 {
+		if fh.wbuf == nil {
+			fh.wbuf = make([]byte, 0, bufSize)
+		}
+		fh.wbufOff = off
+		if delay := fh.d.vfs.Opt.WriteBufferTime; delay > 0 {
+			fh.wbufTimer = time.AfterFunc(delay, fh.flushWriteBufferTimer)
+		}
+	}
+	fh.wbuf = append(fh.wbuf, b...)
+	_ = fh._size()
+	return len(b), nil
+}
+
+// _flushWriteBuffer writes any buffered data to the cache item. It
+// returns any error from writing it or from an earlier flush done
+// by the timer.
+//
+// call with lock held
+func (fh *RWFileHandle) _flushWriteBuffer() (err error) {
+	if fh.wbufTimer != nil {
+		fh.wbufTimer.Stop()
+		fh.wbufTimer = nil
+	}
+	err, fh.wbufErr = fh.wbufErr, nil
+	if len(fh.wbuf) == 0 {
+		return err
+	}
+	n, writeErr := fh.item.WriteAt(fh.wbuf, fh.wbufOff)
+	if writeErr == nil && n != len(fh.wbuf) {
+		writeErr = io.ErrShortWrite
+	}
+	fh.wbuf = fh.wbuf[:0]
+	if writeErr != nil {
+		return errors.Wrap(writeErr, "failed to flush write buffer")
+	}
+	return err
+}
+
+// flushWriteBufferTimer is called by wbufTimer to flush the write
+// buffer after Opt.WriteBufferTime
+func (fh *RWFileHandle) flushWriteBufferTimer() {
+	fh.mu.Lock()
+	defer fh.mu.Unlock()
+	if fh.closed || len(fh.wbuf) == 0 {
+		return
+	}
+	if err := fh._flushWriteBuffer(); err != nil {
+		fs.Errorf(fh.logPrefix(), "%v", err)
+		fh.wbufErr = err
+	}
+}
+
 // WriteAt bytes to the file at off
 func (fh *RWFileHandle) WriteAt(b []byte, off int64) (n int, err error) {
 	fh.mu.Lock()
@@ -381,6 +478,9 @@ func (fh *RWFileHandle) WriteString(s string) (n int, err error) {
 //
 // Call with mutex held
 func (fh *RWFileHandle) _truncate(size int64) (err error) {
+	if err = fh._flushWriteBuffer(); err != nil {
+		return err
+	}
 	if size == fh._size() {
 		return nil
 	}
@@ -416,6 +516,9 @@ func (fh *RWFileHandle) Sync() error {
 	if fh.readOnly() {
 		return nil
 	}
+	if err := fh._flushWriteBuffer(); err != nil {
+		return err
+	}
 	return fh.item.Sync()
 }
 
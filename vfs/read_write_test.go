@@ -724,3 +724,104 @@ func TestRWCacheRename(t *testing.T) {
 	assert.False(t, vfs.cache.Exists("rename_me"))
 	assert.True(t, vfs.cache.Exists("i_was_renamed"))
 }
+
+// Open a file for read and write with a write buffer
+func rwHandleCreateWriteBuffer(t *testing.T, bufferTime time.Duration) (r *fstest.Run, vfs *VFS, fh *RWFileHandle, cleanup func()) {
+	opt := vfscommon.DefaultOpt
+	opt.CacheMode = vfscommon.CacheModeWrites
+	opt.WriteBack = writeBackDelay
+	opt.WriteBufferSize = 8
+	opt.WriteBufferTime = bufferTime
+	r, vfs, cleanup = newTestVFSOpt(t, &opt)
+
+	h, err := vfs.OpenFile("file1", os.O_RDWR|os.O_CREATE, 0777)
+	require.NoError(t, err)
+	fh, ok := h.(*RWFileHandle)
+	require.True(t, ok)
+
+	return r, vfs, fh, cleanup
+}
+
+func TestRWFileHandleWriteBuffer(t *testing.T) {
+	r, vfs, fh, cleanup := rwHandleCreateWriteBuffer(t, 0)
+	defer cleanup()
+
+	itemSize := func() int64 {
+		size, err := fh.item.GetSize()
+		require.NoError(t, err)
+		return size
+	}
+
+	// Small sequential writes are buffered
+	_, err := fh.Write([]byte("hel"))
+	require.NoError(t, err)
+	_, err = fh.Write([]byte("lo"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), itemSize())
+	assert.Equal(t, int64(5), fh.Size())
+	assert.Equal(t, int64(5), fh.Node().Size())
+
+	// Reading flushes the buffer
+	buf := make([]byte, 5)
+	n, err := fh.ReadAt(buf, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf[:n]))
+	assert.Equal(t, int64(5), itemSize())
+
+	// A write which doesn't fit flushes the buffer first
+	_, err = fh.Write([]byte(" wor"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), itemSize())
+	_, err = fh.Write([]byte("ld!!!"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), itemSize())
+	assert.Equal(t, int64(14), fh.Size())
+
+	// Sync flushes the buffer
+	require.NoError(t, fh.Sync())
+	assert.Equal(t, int64(14), itemSize())
+
+	// A write which isn't contiguous flushes the buffer first
+	_, err = fh.WriteAt([]byte("!"), 12)
+	require.NoError(t, err)
+	_, err = fh.WriteAt([]byte("?"), 13)
+	require.NoError(t, err)
+	_, err = fh.WriteAt([]byte("W"), 6)
+	require.NoError(t, err)
+	n, err = fh.ReadAt(buf, 9)
+	require.NoError(t, err)
+	assert.Equal(t, "ld!!?", string(buf[:n]))
+
+	// Writes as big as the buffer go straight through
+	_, err = fh.WriteAt([]byte("01234567"), 14)
+	require.NoError(t, err)
+	assert.Equal(t, int64(22), itemSize())
+
+	// Truncate flushes the buffer
+	_, err = fh.WriteAt([]byte("H"), 0)
+	require.NoError(t, err)
+	require.NoError(t, fh.Truncate(14))
+
+	// Close flushes the buffer
+	_, err = fh.WriteAt([]byte("."), 14)
+	require.NoError(t, err)
+	require.NoError(t, fh.Close())
+
+	file1 := fstest.NewItem("file1", "Hello World!!?.", t1)
+	vfs.WaitForWriters(waitForWritersDelay)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1}, []string{}, fs.ModTimeNotSupported)
+}
+
+func TestRWFileHandleWriteBufferTime(t *testing.T) {
+	_, _, fh, cleanup := rwHandleCreateWriteBuffer(t, 10*time.Millisecond)
+	defer cleanup()
+
+	_, err := fh.Write([]byte("hello"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		size, err := fh.item.GetSize()
+		return err == nil && size == 5
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, fh.Close())
+}
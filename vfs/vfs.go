@@ -35,6 +35,7 @@ import (
 
 	"github.com/artpar/rclone/fs"
 	"github.com/artpar/rclone/fs/cache"
+	"github.com/artpar/rclone/fs/filter"
 	"github.com/artpar/rclone/fs/log"
 	"github.com/artpar/rclone/fs/walk"
 	"github.com/artpar/rclone/vfs/vfscache"
@@ -167,7 +168,10 @@ type VFS struct {
 	usageTime   time.Time
 	usage       *fs.Usage
 	pollChan    chan time.Duration
-	inUse       int32 // count of number of opens accessed with atomic
+	inUse       int32              // count of number of opens accessed with atomic
+	prefetchWg  sync.WaitGroup     // wait for the directory tree prefetch to finish
+	cacheBypass *filter.Filter     // files matching this are read without the cache
+	bwLimits    vfscommon.BwLimits // --vfs-upload-bwlimit and --vfs-download-bwlimit
 }
 
 // Keep track of active VFS keyed on fs.ConfigString(f)
@@ -199,6 +203,11 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 	// Make sure directories are returned as directories
 	vfs.Opt.DirPerms |= os.ModeDir
 
+	// Translate names which can't be used on Windows if required
+	if vfs.Opt.WindowsNames {
+		vfs.f = newEncodedFs(f, windowsEncoding)
+	}
+
 	// Find a VFS with the same name and options and return it if possible
 	activeMu.Lock()
 	defer activeMu.Unlock()
@@ -213,8 +222,23 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 	// Put the VFS into the active cache
 	active[configName] = append(active[configName], vfs)
 
+	// Make the filter for the files which bypass the cache
+	if vfs.Opt.CacheBypass != "" {
+		opt := filter.DefaultOpt
+		opt.IncludeRule = []string{vfs.Opt.CacheBypass}
+		var err error
+		vfs.cacheBypass, err = filter.NewFilter(&opt)
+		if err != nil {
+			fs.Errorf(f, "Ignoring bad --vfs-cache-bypass %q: %v", vfs.Opt.CacheBypass, err)
+			vfs.cacheBypass = nil
+		}
+	}
+
+	// Make the bandwidth limits shared by the cache and the file handles
+	vfs.bwLimits = vfscommon.NewBwLimits(&vfs.Opt)
+
 	// Create root directory
-	vfs.root = newDir(vfs, f, nil, fsDir)
+	vfs.root = newDir(vfs, vfs.f, nil, fsDir)
 
 	// Start polling function
 	features := vfs.f.Features()
@@ -233,6 +257,12 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 
 	vfs.SetCacheMode(vfs.Opt.CacheMode)
 
+	// Read the whole directory tree in the background if required
+	if vfs.Opt.PrefetchTree {
+		vfs.prefetchWg.Add(1)
+		go vfs.prefetchTree()
+	}
+
 	// Pin the Fs into the cache so that when we use cache.NewFs
 	// with the same remote string we get this one. The Pin is
 	// removed when the vfs is finalized
@@ -241,6 +271,24 @@ func New(f fs.Fs, opt *vfscommon.Options) *VFS {
 	return vfs
 }
 
+// prefetchTree reads the whole directory tree into the directory cache
+// so the first listing of each directory doesn't need to wait for
+// the remote
+func (vfs *VFS) prefetchTree() {
+	defer vfs.prefetchWg.Done()
+	if !vfs.Opt.ReadOnly {
+		fs.Logf(vfs.f, "--vfs-prefetch-tree works best with --read-only as changes made elsewhere won't be seen until --dir-cache-time expires")
+	}
+	start := time.Now()
+	fs.Infof(vfs.f, "Prefetching directory tree")
+	err := vfs.root.readDirTree()
+	if err != nil {
+		fs.Errorf(vfs.f, "Failed to prefetch directory tree: %v", err)
+		return
+	}
+	fs.Infof(vfs.f, "Prefetched directory tree in %v", time.Since(start))
+}
+
 // Return the number of active cache entries and a VFS if any are in
 // the cache.
 func activeCacheEntries() (vfs *VFS, count int) {
@@ -266,7 +314,7 @@ func (vfs *VFS) SetCacheMode(cacheMode vfscommon.CacheMode) {
 	vfs.cache = nil
 	if cacheMode > vfscommon.CacheModeOff {
 		ctx, cancel := context.WithCancel(context.Background())
-		cache, err := vfscache.New(ctx, vfs.f, &vfs.Opt, vfs.AddVirtual) // FIXME pass on context or get from Opt?
+		cache, err := vfscache.New(ctx, vfs.f, &vfs.Opt, vfs.bwLimits, vfs.AddVirtual) // FIXME pass on context or get from Opt?
 		if err != nil {
 			fs.Errorf(nil, "Failed to create vfs cache - disabling: %v", err)
 			vfs.Opt.CacheMode = vfscommon.CacheModeOff
@@ -446,7 +494,10 @@ func decodeOpenFlags(flags int) string {
 	if flags&os.O_TRUNC != 0 {
 		out = append(out, "O_TRUNC")
 	}
-	flags &^= accessModeMask | os.O_APPEND | os.O_CREATE | os.O_EXCL | os.O_SYNC | os.O_TRUNC
+	if oDirect != 0 && flags&oDirect != 0 {
+		out = append(out, "O_DIRECT")
+	}
+	flags &^= accessModeMask | os.O_APPEND | os.O_CREATE | os.O_EXCL | os.O_SYNC | os.O_TRUNC | oDirect
 	if flags != 0 {
 		out = append(out, fmt.Sprintf("0x%X", flags))
 	}
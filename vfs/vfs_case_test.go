@@ -100,6 +100,43 @@ func TestCaseSensitivity(t *testing.T) {
 	assertFileAbsentVFS(t, vfsCS, "FILEB")
 }
 
+func TestCaseInsensitiveRename(t *testing.T) {
+	opt := vfscommon.DefaultOpt
+	opt.CaseInsensitive = true
+	r, vfs, cleanup := newTestVFSOpt(t, &opt)
+	defer cleanup()
+
+	if r.Fremote.Features().CaseInsensitive {
+		t.Skip("Can't test case sensitivity - this remote is officially not case-sensitive")
+	}
+
+	ctx := context.Background()
+	file1 := r.WriteObject(ctx, "FiLeA", "data1", t1)
+	file2 := r.WriteObject(ctx, "FiLeB", "data2", t2)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	// Rename using a name which differs from the stored one by case
+	require.NoError(t, vfs.Rename("filea", "FileC"))
+	assertFileAbsentVFS(t, vfs, "FiLeA")
+	assertFileDataVFS(t, vfs, "filec", "data1")
+	file1.Path = "FileC"
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	// Renaming onto a name which matches another file ignoring
+	// case replaces that file rather than making a duplicate
+	require.NoError(t, vfs.Rename("FileC", "FILEB"))
+	assertFileAbsentVFS(t, vfs, "FileC")
+	assertFileDataVFS(t, vfs, "fileb", "data1")
+	file1.Path = "FiLeB"
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	// Renaming a file to a different case of its own name works
+	require.NoError(t, vfs.Rename("FiLeB", "FILEB"))
+	assertFileDataVFS(t, vfs, "FILEB", "data1")
+	file1.Path = "FILEB"
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
 func checkFileDataVFS(t *testing.T, vfs *VFS, name string, expect string) bool {
 	fd, err := vfs.OpenFile(name, os.O_RDONLY, 0777)
 	if fd == nil || err != nil {
@@ -419,3 +419,37 @@ func TestFillInMissingSizes(t *testing.T) {
 		})
 	}
 }
+
+func TestVFSPrefetchTree(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.WriteObject(ctx, "dir/sub/file1", "file1 contents", t1)
+	r.WriteObject(ctx, "dir/file2", "file2 contents", t1)
+
+	opt := vfscommon.DefaultOpt
+	opt.ReadOnly = true
+	opt.PrefetchTree = true
+	vfs := New(r.Fremote, &opt)
+	defer cleanupVFS(t, vfs)
+	vfs.prefetchWg.Wait()
+
+	// Check the directories were read without listing them
+	getDir := func(parent *Dir, leaf string) *Dir {
+		parent.mu.RLock()
+		defer parent.mu.RUnlock()
+		assert.False(t, parent.read.IsZero(), "%q not read", parent.path)
+		node, ok := parent.items[leaf]
+		require.True(t, ok, "%q not found in %q", leaf, parent.path)
+		dir, ok := node.(*Dir)
+		require.True(t, ok)
+		return dir
+	}
+	dir := getDir(vfs.root, "dir")
+	sub := getDir(dir, "sub")
+	sub.mu.RLock()
+	assert.False(t, sub.read.IsZero())
+	_, ok := sub.items["file1"]
+	assert.True(t, ok)
+	sub.mu.RUnlock()
+}
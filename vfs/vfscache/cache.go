@@ -44,20 +44,24 @@ type Cache struct {
 	opt        *vfscommon.Options   // vfs Options
 	root       string               // root of the cache directory
 	metaRoot   string               // root of the cache metadata directory
+	pinsPath   string               // file with the pinned paths in
 	hashType   hash.Type            // hash to use locally and remotely
 	hashOption *fs.HashesOption     // corresponding OpenOption
 	writeback  *writeback.WriteBack // holds Items for writeback
 	avFn       AddVirtualFn         // if set, can be called to add dir entries
+	bwLimits   vfscommon.BwLimits   // bandwidth limits for uploads and downloads
 
-	mu            sync.Mutex       // protects the following variables
-	cond          *sync.Cond       // cond lock for synchronous cache cleaning
-	item          map[string]*Item // files/directories in the cache
-	errItems      map[string]error // items in error state
-	used          int64            // total size of files in the cache
-	outOfSpace    bool             // out of space
-	cleanerKicked bool             // some thread kicked the cleaner upon out of space
-	kickerMu      sync.Mutex       // mutex for cleanerKicked
-	kick          chan struct{}    // channel for kicking clear to start
+	mu            sync.Mutex          // protects the following variables
+	cond          *sync.Cond          // cond lock for synchronous cache cleaning
+	item          map[string]*Item    // files/directories in the cache
+	errItems      map[string]error    // items in error state
+	pins          map[string]struct{} // pinned files and directories
+	pinsModTime   time.Time           // modification time of the pins file when last read or written
+	used          int64               // total size of files in the cache
+	outOfSpace    bool                // out of space
+	cleanerKicked bool                // some thread kicked the cleaner upon out of space
+	kickerMu      sync.Mutex          // mutex for cleanerKicked
+	kick          chan struct{}       // channel for kicking clear to start
 
 }
 
@@ -73,28 +77,18 @@ type AddVirtualFn func(remote string, size int64, isDir bool) error
 //
 // This starts background goroutines which can be cancelled with the
 // context passed in.
-func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVirtualFn) (*Cache, error) {
-	fName := fremote.Name()
-	fRoot := filepath.FromSlash(fremote.Root())
-	if runtime.GOOS == "windows" {
-		if strings.HasPrefix(fRoot, `\\?`) {
-			fRoot = fRoot[3:]
-		}
-		fRoot = strings.Replace(fRoot, ":", "", -1)
-		// Replace leading ':' if remote was created on the fly as ":backend:/path" as it is illegal in Windows
-		if fName[0] == ':' {
-			fName = "^" + fName[1:]
-		}
-	}
-	cacheDir := config.CacheDir
-	cacheDir, err := filepath.Abs(cacheDir)
+//
+// Uploads and downloads made by the cache are limited by bwLimits.
+func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, bwLimits vfscommon.BwLimits, avFn AddVirtualFn) (*Cache, error) {
+	cacheDir, fName, fRoot, err := cacheDirs(fremote)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to make --cache-dir absolute")
+		return nil, err
 	}
 	root := file.UNCPath(filepath.Join(cacheDir, "vfs", fName, fRoot))
 	fs.Debugf(nil, "vfs cache: root is %q", root)
 	metaRoot := file.UNCPath(filepath.Join(cacheDir, "vfsMeta", fName, fRoot))
 	fs.Debugf(nil, "vfs cache: metadata root is %q", root)
+	pinsPath := file.UNCPath(filepath.Join(cacheDir, "vfsPins", fName, fRoot, "pins.json"))
 
 	fcache, err := fscache.Get(ctx, root)
 	if err != nil {
@@ -114,12 +108,14 @@ func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVir
 		opt:        opt,
 		root:       root,
 		metaRoot:   metaRoot,
+		pinsPath:   pinsPath,
 		item:       make(map[string]*Item),
 		errItems:   make(map[string]error),
 		hashType:   hashType,
 		hashOption: hashOption,
 		writeback:  writeback.New(ctx, opt),
 		avFn:       avFn,
+		bwLimits:   bwLimits,
 	}
 
 	// Make sure cache directories exist
@@ -128,6 +124,12 @@ func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVir
 		return nil, errors.Wrap(err, "failed to make cache directory")
 	}
 
+	// load in the pinned paths
+	err = c.loadPins()
+	if err != nil {
+		return nil, err
+	}
+
 	// load in the cache and metadata off disk
 	err = c.reload(ctx)
 	if err != nil {
@@ -146,6 +148,28 @@ func New(ctx context.Context, fremote fs.Fs, opt *vfscommon.Options, avFn AddVir
 	return c, nil
 }
 
+// cacheDirs returns the absolute cache directory along with the names
+// of the directories within it used for fremote
+func cacheDirs(fremote fs.Fs) (cacheDir, fName, fRoot string, err error) {
+	fName = fremote.Name()
+	fRoot = filepath.FromSlash(fremote.Root())
+	if runtime.GOOS == "windows" {
+		if strings.HasPrefix(fRoot, `\\?`) {
+			fRoot = fRoot[3:]
+		}
+		fRoot = strings.Replace(fRoot, ":", "", -1)
+		// Replace leading ':' if remote was created on the fly as ":backend:/path" as it is illegal in Windows
+		if fName[0] == ':' {
+			fName = "^" + fName[1:]
+		}
+	}
+	cacheDir, err = filepath.Abs(config.CacheDir)
+	if err != nil {
+		return "", "", "", errors.Wrap(err, "failed to make --cache-dir absolute")
+	}
+	return cacheDir, fName, fRoot, nil
+}
+
 // clean returns the cleaned version of name for use in the index map
 //
 // name should be a remote path not an osPath
@@ -338,6 +362,7 @@ func (c *Cache) Rename(name string, newName string, newObj fs.Object) (err error
 		c.item[newName] = item
 		delete(c.item, name)
 	}
+	c._renamePins(name, newName)
 	c.mu.Unlock()
 
 	fs.Infof(name, "vfs cache: renamed in cache to %q", newName)
@@ -380,6 +405,11 @@ func (c *Cache) DirRename(oldDirName string, newDirName string) (err error) {
 		}
 	}
 
+	// Move any pins on the directory
+	c.mu.Lock()
+	c._renamePins(oldDirName[:len(oldDirName)-1], newDirName[:len(newDirName)-1])
+	c.mu.Unlock()
+
 	// Old path should be empty now so remove it
 	c.purgeEmptyDirs(oldDirName[:len(oldDirName)-1], false)
 
@@ -415,10 +445,14 @@ func (c *Cache) SetModTime(name string, modTime time.Time) {
 func (c *Cache) CleanUp() error {
 	err1 := os.RemoveAll(c.root)
 	err2 := os.RemoveAll(c.metaRoot)
+	err3 := os.RemoveAll(c.pinsPath)
 	if err1 != nil {
 		return err1
 	}
-	return err2
+	if err2 != nil {
+		return err2
+	}
+	return err3
 }
 
 // walk walks the cache calling the function
@@ -547,9 +581,9 @@ func (c *Cache) purgeClean(quota int64) {
 		return
 	}
 
-	// Make a slice of clean cache files
-	for _, item := range c.item {
-		if !item.IsDataDirty() {
+	// Make a slice of clean cache files which aren't pinned
+	for name, item := range c.item {
+		if !item.IsDataDirty() && !c._isPinned(name) {
 			items = append(items, item)
 		}
 	}
@@ -588,8 +622,10 @@ func (c *Cache) purgeOld(maxAge time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	// cutoff := time.Now().Add(-maxAge)
-	for _, item := range c.item {
-		c.removeNotInUse(item, maxAge, false)
+	for name, item := range c.item {
+		if !c._isPinned(name) {
+			c.removeNotInUse(item, maxAge, false)
+		}
 	}
 	if c.used < int64(c.opt.CacheMaxSize) {
 		c.outOfSpace = false
@@ -638,9 +674,9 @@ func (c *Cache) purgeOverQuota(quota int64) {
 
 	var items Items
 
-	// Make a slice of unused files
-	for _, item := range c.item {
-		if !item.inUse() {
+	// Make a slice of unused files which aren't pinned
+	for name, item := range c.item {
+		if !item.inUse() && !c._isPinned(name) {
 			items = append(items, item)
 		}
 	}
@@ -666,6 +702,7 @@ func (c *Cache) clean(removeCleanFiles bool) {
 	}
 	c.updateUsed()
 	c.mu.Lock()
+	c._reloadPins()
 	oldItems, oldUsed := len(c.item), fs.SizeSuffix(c.used)
 	c.mu.Unlock()
 
@@ -691,6 +728,15 @@ func (c *Cache) clean(removeCleanFiles bool) {
 		if used <= int64(c.opt.CacheMaxSize) && len(c.errItems) == 0 {
 			break
 		}
+
+		// Give up if the pinned files won't fit
+		c.mu.Lock()
+		pinnedSize := c._pinnedSize()
+		c.mu.Unlock()
+		if pinnedSize >= int64(c.opt.CacheMaxSize) {
+			fs.Errorf(nil, "vfs cache: can't reduce cache below --vfs-cache-max-size as pinned files use %v", fs.SizeSuffix(pinnedSize))
+			break
+		}
 	}
 
 	// Was kicked?
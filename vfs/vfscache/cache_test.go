@@ -87,7 +87,7 @@ func newTestCacheOpt(t *testing.T, opt vfscommon.Options) (r *fstest.Run, c *Cac
 	ctx, cancel := context.WithCancel(context.Background())
 
 	avInfos = nil
-	c, err := New(ctx, r.Fremote, &opt, addVirtual)
+	c, err := New(ctx, r.Fremote, &opt, vfscommon.NewBwLimits(&opt), addVirtual)
 	require.NoError(t, err)
 
 	cleanup = func() {
@@ -426,6 +426,99 @@ func TestCachePurgeOverQuota(t *testing.T) {
 	assert.Equal(t, []string(nil), itemAsString(c))
 }
 
+func TestCachePurgePinned(t *testing.T) {
+	_, c, cleanup := newTestCache(t)
+	defer cleanup()
+
+	potato := c.Item("sub/dir/potato")
+	itemWrite(t, potato, "hello")
+	require.NoError(t, potato.Close(nil))
+
+	potato2 := c.Item("sub/dir2/potato2")
+	itemWrite(t, potato2, "hello2")
+	require.NoError(t, potato2.Close(nil))
+
+	require.NoError(t, c.Pin("sub/dir"))
+	require.NoError(t, c.Pin("/sub/dir/"))
+	assert.Equal(t, []string{"sub/dir"}, c.Pins())
+
+	// Check pinned files survive the cleaners
+	c.purgeOld(-10 * time.Second)
+	c.purgeOverQuota(1)
+	c.purgeClean(1)
+	assert.Equal(t, []string{
+		`name="sub/dir/potato" opens=0 size=5`,
+	}, itemAsString(c))
+
+	// Check the pins are saved
+	require.NoError(t, c.loadPins())
+	assert.Equal(t, []string{"sub/dir"}, c.Pins())
+
+	// Check pins move with renames
+	require.NoError(t, c.DirRename("sub/dir", "sub/moved"))
+	assert.Equal(t, []string{"sub/moved"}, c.Pins())
+	assert.Error(t, c.Unpin("sub/dir"))
+
+	// Unpin and check the file can be removed
+	require.NoError(t, c.Unpin("sub/moved"))
+	assert.Equal(t, []string{}, c.Pins())
+	c.purgeOverQuota(1)
+	assert.Equal(t, []string(nil), itemAsString(c))
+	assertPathNotExist(t, c.pinsPath)
+}
+
+func TestCacheEditPins(t *testing.T) {
+	r, c, cleanup := newTestCache(t)
+	defer cleanup()
+
+	// Pins made without the Cache are picked up by it
+	pinned, err := EditPins(r.Fremote, []string{"one", "two/"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two"}, pinned)
+	assert.Equal(t, []string{"one", "two"}, c.Pins())
+
+	pinned, err = EditPins(r.Fremote, []string{"one"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"two"}, pinned)
+	assert.Equal(t, []string{"two"}, c.Pins())
+
+	_, err = EditPins(r.Fremote, []string{"one"}, false)
+	assert.Error(t, err)
+
+	// and the other way round
+	require.NoError(t, c.Unpin("two"))
+	pinned, err = EditPins(r.Fremote, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, pinned)
+}
+
+func TestCacheItemsEvictionOrder(t *testing.T) {
+	t0 := time.Now()
+	item := func(name string, accesses int64, prevATime, aTime time.Duration) *Item {
+		return &Item{
+			name: name,
+			info: Info{
+				Accesses:  accesses,
+				PrevATime: t0.Add(prevATime),
+				ATime:     t0.Add(aTime),
+			},
+		}
+	}
+	items := Items{
+		item("frequent-recent", 5, -time.Minute, 0),
+		item("once-old", 1, -3*time.Hour, -2*time.Hour),
+		item("frequent-old", 2, -4*time.Hour, -time.Minute),
+		item("once-recent", 1, -time.Hour, 0),
+		item("never", 0, 0, -time.Hour),
+	}
+	sort.Sort(items)
+	var got []string
+	for _, item := range items {
+		got = append(got, item.name)
+	}
+	assert.Equal(t, []string{"once-old", "never", "once-recent", "frequent-old", "frequent-recent"}, got)
+}
+
 // test reset clean files
 func TestCachePurgeClean(t *testing.T) {
 	r, c, cleanup := newItemTestCache(t)
@@ -79,9 +79,18 @@ type Info struct {
 	Rs          ranges.Ranges // which parts of the file are present
 	Fingerprint string        // fingerprint of remote object
 	Dirty       bool          // set if the backing file has been modified
+	Accesses    int64         // number of times the file has been opened
+	PrevATime   time.Time     // last time file was accessed before ATime
 }
 
-// Items are a slice of *Item ordered by ATime
+// Items are a slice of *Item ordered by which should be removed from
+// the cache first.
+//
+// This uses LRU-2 so items which have only been opened once are
+// removed first, oldest ATime first, followed by the rest ordered by
+// the time of the access before the last one. This means files which
+// are used over and over again stay in the cache rather than being
+// pushed out by files which are read once.
 type Items []*Item
 
 // ResetResult reports the actual action taken in the Reset function and reason
@@ -115,6 +124,13 @@ func (v Items) Less(i, j int) bool {
 	jItem.mu.Lock()
 	defer jItem.mu.Unlock()
 
+	iFrequent, jFrequent := iItem.info.Accesses >= 2, jItem.info.Accesses >= 2
+	if iFrequent != jFrequent {
+		return jFrequent
+	}
+	if iFrequent {
+		return iItem.info.PrevATime.Before(jItem.info.PrevATime)
+	}
 	return iItem.info.ATime.Before(jItem.info.ATime)
 }
 
@@ -496,6 +512,10 @@ func (item *Item) _createFile(osPath string) (err error) {
 // Open the local file from the object passed in.  Wraps open()
 // to provide recovery from out of space error.
 func (item *Item) Open(o fs.Object) (err error) {
+	item.mu.Lock()
+	item.info.PrevATime = item.info.ATime
+	item.info.Accesses++
+	item.mu.Unlock()
 	for retries := 0; retries < fs.GetConfig(context.TODO()).LowLevelRetries; retries++ {
 		item.preAccess()
 		err = item.open(o)
@@ -569,7 +589,7 @@ func (item *Item) open(o fs.Object) (err error) {
 
 	// Create the downloaders
 	if item.o != nil {
-		item.downloaders = downloaders.New(item, item.c.opt, item.name, item.o)
+		item.downloaders = downloaders.New(item, item.c.opt, item.name, item.c.bwLimits.Download.Object(item.o))
 	}
 
 	return err
@@ -592,7 +612,7 @@ func (item *Item) _store(ctx context.Context, storeFn StoreFn) (err error) {
 	if cacheObj != nil {
 		o, name := item.o, item.name
 		item.mu.Unlock()
-		o, err := operations.Copy(ctx, item.c.fremote, o, name, cacheObj)
+		o, err := operations.Copy(ctx, item.c.fremote, o, name, item.c.bwLimits.Upload.Object(cacheObj))
 		item.mu.Lock()
 		if err != nil {
 			return errors.Wrap(err, "vfs cache: failed to transfer file from cache to remote")
@@ -1032,7 +1052,7 @@ func (item *Item) Reset() (rr ResetResult, spaceFreed int64, err error) {
 
 	// Create the downloaders
 	if item.o != nil {
-		item.downloaders = downloaders.New(item, item.c.opt, item.name, item.o)
+		item.downloaders = downloaders.New(item, item.c.opt, item.name, item.c.bwLimits.Download.Object(item.o))
 	}
 
 	/* The item will stay in the beingReset state if we get an error that prevents us from
@@ -0,0 +1,242 @@
+// Pinning files and directories so the cache cleaner never removes them
+
+package vfscache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/artpar/rclone/lib/file"
+	"github.com/artpar/rclone/vfs/vfscommon"
+	"github.com/pkg/errors"
+)
+
+// loadPins reads the pinned paths from c.pinsPath
+func (c *Cache) loadPins() error {
+	c.pins = make(map[string]struct{})
+	c.pinsModTime = time.Time{}
+	fi, err := os.Stat(c.pinsPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to read pinned paths")
+	}
+	data, err := ioutil.ReadFile(c.pinsPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to read pinned paths")
+	}
+	var pins []string
+	err = json.Unmarshal(data, &pins)
+	if err != nil {
+		return errors.Wrap(err, "corrupt pinned paths")
+	}
+	for _, name := range pins {
+		c.pins[clean(name)] = struct{}{}
+	}
+	c.pinsModTime = fi.ModTime()
+	return nil
+}
+
+// _reloadPins reads the pinned paths again if c.pinsPath has been
+// changed by something else, such as the pin command
+//
+// call with c.mu held
+func (c *Cache) _reloadPins() {
+	var modTime time.Time
+	if fi, err := os.Stat(c.pinsPath); err == nil {
+		modTime = fi.ModTime()
+	}
+	if modTime.Equal(c.pinsModTime) {
+		return
+	}
+	pins := c.pins
+	err := c.loadPins()
+	if err != nil {
+		fs.Errorf(nil, "vfs cache: failed to reload pins - using old pins: %v", err)
+		c.pins = pins
+	}
+}
+
+// _pins returns the pinned paths sorted
+//
+// call with c.mu held
+func (c *Cache) _pins() []string {
+	pins := make([]string, 0, len(c.pins))
+	for name := range c.pins {
+		pins = append(pins, name)
+	}
+	sort.Strings(pins)
+	return pins
+}
+
+// _savePins writes the pinned paths to c.pinsPath
+//
+// call with c.mu held
+func (c *Cache) _savePins() error {
+	if len(c.pins) == 0 {
+		err := os.Remove(c.pinsPath)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "failed to remove pinned paths")
+		}
+		c.pinsModTime = time.Time{}
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(c.pinsPath), 0700)
+	if err != nil {
+		return errors.Wrap(err, "failed to make pinned paths directory")
+	}
+	data, err := json.MarshalIndent(c._pins(), "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode pinned paths")
+	}
+	err = ioutil.WriteFile(c.pinsPath, data, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write pinned paths")
+	}
+	if fi, err := os.Stat(c.pinsPath); err == nil {
+		c.pinsModTime = fi.ModTime()
+	}
+	return nil
+}
+
+// _isPinned returns true if name or any of the directories it is in
+// are pinned
+//
+// call with c.mu held
+func (c *Cache) _isPinned(name string) bool {
+	if len(c.pins) == 0 {
+		return false
+	}
+	for {
+		if _, found := c.pins[name]; found {
+			return true
+		}
+		if name == "" {
+			return false
+		}
+		name = vfscommon.FindParent(name)
+	}
+}
+
+// _renamePins moves any pins on name or under it to newName
+//
+// call with c.mu held
+func (c *Cache) _renamePins(name, newName string) {
+	changed := false
+	for pin := range c.pins {
+		if pin == name {
+			delete(c.pins, pin)
+			c.pins[newName] = struct{}{}
+			changed = true
+		} else if strings.HasPrefix(pin, name+"/") {
+			delete(c.pins, pin)
+			c.pins[newName+pin[len(name):]] = struct{}{}
+			changed = true
+		}
+	}
+	if changed {
+		err := c._savePins()
+		if err != nil {
+			fs.Errorf(name, "vfs cache: failed to save pins after rename: %v", err)
+		}
+	}
+}
+
+// _pinnedSize returns the space used by pinned items
+//
+// call with c.mu held
+func (c *Cache) _pinnedSize() (size int64) {
+	for name, item := range c.item {
+		if c._isPinned(name) {
+			size += item.getDiskSize()
+		}
+	}
+	return size
+}
+
+// Pin stops name, which may be a file or a directory, and everything
+// under it from being removed from the cache by the cache cleaner.
+//
+// Files which aren't in the cache yet are pinned when they are read.
+func (c *Cache) Pin(name string) error {
+	name = clean(name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c._reloadPins()
+	if _, found := c.pins[name]; found {
+		return nil
+	}
+	c.pins[name] = struct{}{}
+	err := c._savePins()
+	if err != nil {
+		delete(c.pins, name)
+		return err
+	}
+	fs.Infof(name, "vfs cache: pinned")
+	return nil
+}
+
+// Unpin allows name to be removed from the cache again. It returns an
+// error if name isn't pinned.
+func (c *Cache) Unpin(name string) error {
+	name = clean(name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c._reloadPins()
+	if _, found := c.pins[name]; !found {
+		return errors.Errorf("%q is not pinned", name)
+	}
+	delete(c.pins, name)
+	err := c._savePins()
+	if err != nil {
+		c.pins[name] = struct{}{}
+		return err
+	}
+	fs.Infof(name, "vfs cache: unpinned")
+	return nil
+}
+
+// Pins returns the pinned paths sorted
+func (c *Cache) Pins() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c._reloadPins()
+	return c._pins()
+}
+
+// EditPins pins names in the VFS cache of fremote, or unpins them if
+// pin is false, without making a Cache. It returns the pinned paths
+// afterwards. Names are relative to the root of fremote.
+//
+// A Cache which is running picks up the changes the next time it is
+// cleaned.
+func EditPins(fremote fs.Fs, names []string, pin bool) (pinned []string, err error) {
+	cacheDir, fName, fRoot, err := cacheDirs(fremote)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		pinsPath: file.UNCPath(filepath.Join(cacheDir, "vfsPins", fName, fRoot, "pins.json")),
+	}
+	err = c.loadPins()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if pin {
+			err = c.Pin(name)
+		} else {
+			err = c.Unpin(name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Pins(), nil
+}
@@ -0,0 +1,134 @@
+package vfscommon
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"golang.org/x/time/rate"
+)
+
+// maxBwBurst is the most data which can be read in one go through a
+// BwLimit
+const maxBwBurst = 1024 * 1024
+
+// BwLimit limits the bandwidth used by the VFS in one direction.
+//
+// A nil *BwLimit is unlimited.
+type BwLimit struct {
+	limiter *rate.Limiter
+}
+
+// NewBwLimit makes a BwLimit for bandwidth bytes per second. It
+// returns nil, which is unlimited, if bandwidth isn't positive.
+func NewBwLimit(bandwidth fs.SizeSuffix) *BwLimit {
+	if bandwidth <= 0 {
+		return nil
+	}
+	limiter := rate.NewLimiter(rate.Limit(bandwidth), maxBwBurst)
+	// empty the bucket so we don't start with a burst
+	limiter.AllowN(time.Now(), maxBwBurst)
+	return &BwLimit{limiter: limiter}
+}
+
+// BwLimits are the limits for uploads and downloads made by a VFS
+type BwLimits struct {
+	Upload   *BwLimit // limit for --vfs-upload-bwlimit
+	Download *BwLimit // limit for --vfs-download-bwlimit
+}
+
+// NewBwLimits makes the BwLimits from the options
+func NewBwLimits(opt *Options) BwLimits {
+	return BwLimits{
+		Upload:   NewBwLimit(opt.UploadBwLimit),
+		Download: NewBwLimit(opt.DownloadBwLimit),
+	}
+}
+
+// limitedReader is an io.ReadCloser limited by a BwLimit
+type limitedReader struct {
+	ctx context.Context
+	in  io.Reader
+	bl  *BwLimit
+}
+
+// Read reads up to len(p) bytes waiting for the BwLimit
+func (r *limitedReader) Read(p []byte) (n int, err error) {
+	if len(p) > maxBwBurst {
+		p = p[:maxBwBurst]
+	}
+	n, err = r.in.Read(p)
+	if n > 0 {
+		waitErr := r.bl.limiter.WaitN(r.ctx, n)
+		if waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
+
+// Close closes the underlying reader if it is an io.Closer
+func (r *limitedReader) Close() error {
+	if c, ok := r.in.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Reader returns in wrapped so reading from it is limited by bl.
+//
+// If bl is nil it returns in unchanged.
+func (bl *BwLimit) Reader(ctx context.Context, in io.Reader) io.Reader {
+	if bl == nil {
+		return in
+	}
+	return &limitedReader{ctx: ctx, in: in, bl: bl}
+}
+
+// ReadCloser returns in wrapped so reading from it is limited by bl.
+//
+// If bl is nil it returns in unchanged.
+func (bl *BwLimit) ReadCloser(ctx context.Context, in io.ReadCloser) io.ReadCloser {
+	if bl == nil {
+		return in
+	}
+	return &limitedReader{ctx: ctx, in: in, bl: bl}
+}
+
+// limitedObject is an fs.Object whose data is read through a BwLimit
+type limitedObject struct {
+	fs.Object
+	bl *BwLimit
+}
+
+// Open opens the object limiting the reads from it
+func (o *limitedObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	in, err := o.Object.Open(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	return o.bl.ReadCloser(ctx, in), nil
+}
+
+// UnWrap returns the wrapped Object
+func (o *limitedObject) UnWrap() fs.Object {
+	return o.Object
+}
+
+// Object returns o wrapped so reading its data is limited by bl.
+//
+// If bl or o is nil it returns o unchanged.
+func (bl *BwLimit) Object(o fs.Object) fs.Object {
+	if bl == nil || o == nil {
+		return o
+	}
+	return &limitedObject{Object: o, bl: bl}
+}
+
+// Check the interfaces are satisfied
+var (
+	_ io.ReadCloser      = (*limitedReader)(nil)
+	_ fs.Object          = (*limitedObject)(nil)
+	_ fs.ObjectUnWrapper = (*limitedObject)(nil)
+)
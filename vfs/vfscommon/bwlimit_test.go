@@ -0,0 +1,54 @@
+package vfscommon
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artpar/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBwLimit(t *testing.T) {
+	assert.Nil(t, NewBwLimit(0))
+	assert.Nil(t, NewBwLimit(-1))
+	assert.NotNil(t, NewBwLimit(fs.MebiByte))
+
+	bls := NewBwLimits(&Options{UploadBwLimit: fs.MebiByte})
+	assert.NotNil(t, bls.Upload)
+	assert.Nil(t, bls.Download)
+}
+
+func TestBwLimitNil(t *testing.T) {
+	var bl *BwLimit
+	in := strings.NewReader("hello")
+	assert.True(t, in == bl.Reader(context.Background(), in))
+	rc := ioutil.NopCloser(in)
+	assert.Equal(t, rc, bl.ReadCloser(context.Background(), rc))
+	assert.Nil(t, bl.Object(nil))
+}
+
+func TestBwLimitReader(t *testing.T) {
+	const bandwidth = 4 * fs.MebiByte
+	data := bytes.Repeat([]byte{'x'}, 2*int(fs.MebiByte))
+	bl := NewBwLimit(bandwidth)
+
+	start := time.Now()
+	got, err := ioutil.ReadAll(bl.Reader(context.Background(), bytes.NewReader(data)))
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+	// 2 MiB at 4 MiB/s should take about 500ms
+	assert.True(t, time.Since(start) >= 400*time.Millisecond, "too quick: %v", time.Since(start))
+}
+
+func TestBwLimitReaderCancel(t *testing.T) {
+	bl := NewBwLimit(fs.SizeSuffix(1024))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ioutil.ReadAll(bl.Reader(ctx, bytes.NewReader(make([]byte, 4096))))
+	assert.Error(t, err)
+}
@@ -23,6 +23,7 @@ type Options struct {
 	FilePerms         os.FileMode
 	ChunkSize         fs.SizeSuffix // if > 0 read files in chunks
 	ChunkSizeLimit    fs.SizeSuffix // if > ChunkSize double the chunk size after each chunk until reached
+	ChunkSizeMin      fs.SizeSuffix // if > 0 and < ChunkSize halve the chunk size after each seek until reached
 	CacheMode         CacheMode
 	CacheMaxAge       time.Duration
 	CacheMaxSize      fs.SizeSuffix
@@ -33,6 +34,13 @@ type Options struct {
 	WriteBack         time.Duration // time to wait before writing back dirty files
 	ReadAhead         fs.SizeSuffix // bytes to read ahead in cache mode "full"
 	UsedIsSize        bool          // if true, use the `rclone size` algorithm for Used size
+	WindowsNames      bool          // if true, translate names which can't be used on Windows
+	PrefetchTree      bool          // if true, read the whole directory tree when the VFS is created
+	CacheBypass       string        // read files matching this glob straight from the remote
+	UploadBwLimit     fs.SizeSuffix // bandwidth limit for uploads, 0 for unlimited
+	DownloadBwLimit   fs.SizeSuffix // bandwidth limit for downloads, 0 for unlimited
+	WriteBufferSize   fs.SizeSuffix // coalesce small writes to a file handle in a buffer of this size, 0 to disable
+	WriteBufferTime   time.Duration // max time data stays in the write buffer, 0 to only flush when needed
 }
 
 // DefaultOpt is the default values uses for Opt
@@ -60,4 +68,7 @@ var DefaultOpt = Options{
 	WriteBack:         5 * time.Second,
 	ReadAhead:         0 * fs.MebiByte,
 	UsedIsSize:        false,
+	WindowsNames:      false,
+	WriteBufferSize:   0,
+	WriteBufferTime:   time.Second,
 }
@@ -30,13 +30,21 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.FVarP(flagSet, &Opt.CacheMaxSize, "vfs-cache-max-size", "", "Max total size of objects in the cache.")
 	flags.FVarP(flagSet, &Opt.ChunkSize, "vfs-read-chunk-size", "", "Read the source objects in chunks.")
 	flags.FVarP(flagSet, &Opt.ChunkSizeLimit, "vfs-read-chunk-size-limit", "", "If greater than --vfs-read-chunk-size, double the chunk size after each chunk read, until the limit is reached. 'off' is unlimited.")
+	flags.FVarP(flagSet, &Opt.ChunkSizeMin, "vfs-read-chunk-size-min", "", "If less than --vfs-read-chunk-size, halve the chunk size after each seek, until the minimum is reached. 0 uses --vfs-read-chunk-size.")
 	flags.FVarP(flagSet, DirPerms, "dir-perms", "", "Directory permissions")
 	flags.FVarP(flagSet, FilePerms, "file-perms", "", "File permissions")
 	flags.BoolVarP(flagSet, &Opt.CaseInsensitive, "vfs-case-insensitive", "", Opt.CaseInsensitive, "If a file name not found, find a case insensitive match.")
+	flags.BoolVarP(flagSet, &Opt.WindowsNames, "vfs-windows-names", "", Opt.WindowsNames, "Translate characters which can't be used in file names on Windows to their FULLWIDTH equivalents.")
 	flags.DurationVarP(flagSet, &Opt.WriteWait, "vfs-write-wait", "", Opt.WriteWait, "Time to wait for in-sequence write before giving error.")
 	flags.DurationVarP(flagSet, &Opt.ReadWait, "vfs-read-wait", "", Opt.ReadWait, "Time to wait for in-sequence read before seeking.")
 	flags.DurationVarP(flagSet, &Opt.WriteBack, "vfs-write-back", "", Opt.WriteBack, "Time to writeback files after last use when using cache.")
+	flags.FVarP(flagSet, &Opt.WriteBufferSize, "vfs-write-buffer-size", "", "Coalesce small writes to each open file in a buffer of this size when using cache. 0 to disable.")
+	flags.DurationVarP(flagSet, &Opt.WriteBufferTime, "vfs-write-buffer-time", "", Opt.WriteBufferTime, "Max time written data stays in the --vfs-write-buffer-size buffer. 0 to only flush on fsync, close or when full.")
 	flags.FVarP(flagSet, &Opt.ReadAhead, "vfs-read-ahead", "", "Extra read ahead over --buffer-size when using cache-mode full.")
+	flags.FVarP(flagSet, &Opt.UploadBwLimit, "vfs-upload-bwlimit", "", "Bandwidth limit for uploading files to the remote. 0 is unlimited.")
+	flags.FVarP(flagSet, &Opt.DownloadBwLimit, "vfs-download-bwlimit", "", "Bandwidth limit for downloading files from the remote. 0 is unlimited.")
+	flags.StringVarP(flagSet, &Opt.CacheBypass, "vfs-cache-bypass", "", Opt.CacheBypass, "Read files matching this glob straight from the remote without caching them.")
+	flags.BoolVarP(flagSet, &Opt.PrefetchTree, "vfs-prefetch-tree", "", Opt.PrefetchTree, "Read the whole directory tree in the background at startup. Use with --fast-list.")
 	flags.BoolVarP(flagSet, &Opt.UsedIsSize, "vfs-used-is-size", "", Opt.UsedIsSize, "Use the `rclone size` algorithm for Used size.")
 	platformFlags(flagSet)
 }
@@ -68,7 +68,8 @@ func (fh *WriteFileHandle) openPending() (err error) {
 	pipeReader, fh.pipeWriter = io.Pipe()
 	go func() {
 		// NB Rcat deals with Stats.Transferring, etc.
-		o, err := operations.Rcat(context.TODO(), fh.file.Fs(), fh.remote, pipeReader, time.Now())
+		in := fh.file.VFS().bwLimits.Upload.ReadCloser(context.TODO(), pipeReader)
+		o, err := operations.Rcat(context.TODO(), fh.file.Fs(), fh.remote, in, time.Now())
 		if err != nil {
 			fs.Errorf(fh.remote, "WriteFileHandle.New Rcat failed: %v", err)
 		}